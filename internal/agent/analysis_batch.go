@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"brale/internal/agent/health"
+	"brale/internal/analysis/screen"
+	"brale/internal/decision"
+	"brale/internal/pipeline"
+	"brale/internal/profile"
+	livehttp "brale/internal/transport/http/live"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const batchAnalysisConcurrency = 4
+
+// BatchAnalyze 并发运行各 symbol 的 profile pipeline，仅基于本地K线给出紧凑结论，不触发 LLM。
+func (s *LiveService) BatchAnalyze(ctx context.Context, req livehttp.BatchAnalysisRequest) ([]livehttp.BatchAnalysisResult, error) {
+	if s == nil || s.profileMgr == nil {
+		return nil, fmt.Errorf("profile manager 未初始化")
+	}
+	var forced *profile.Runtime
+	if req.Profile != "" {
+		forced = s.findProfile(req.Profile)
+		if forced == nil {
+			return nil, fmt.Errorf("profile %s 不存在", req.Profile)
+		}
+	}
+	results := make([]livehttp.BatchAnalysisResult, len(req.Symbols))
+	var eg errgroup.Group
+	eg.SetLimit(batchAnalysisConcurrency)
+	for i, sym := range req.Symbols {
+		i, sym := i, sym
+		eg.Go(func() error {
+			rt := forced
+			if rt == nil {
+				rt, _ = s.profileMgr.Resolve(sym)
+			}
+			results[i] = s.analyzeSymbol(ctx, sym, rt, req.Interval)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	return results, nil
+}
+
+func (s *LiveService) ScreeningStats() screen.Stats {
+	if s == nil || s.liveEngine == nil {
+		return screen.Stats{}
+	}
+	return s.liveEngine.ScreeningStats()
+}
+
+func (s *LiveService) IndicatorCacheStats() (decision.IndicatorCacheStats, bool) {
+	if s == nil || s.liveEngine == nil {
+		return decision.IndicatorCacheStats{}, false
+	}
+	return s.liveEngine.IndicatorCacheStats()
+}
+
+func (s *LiveService) PipelineRunReport() (pipeline.RunReport, bool) {
+	if s == nil || s.liveEngine == nil {
+		return pipeline.RunReport{}, false
+	}
+	return s.liveEngine.PipelineRunReport()
+}
+
+func (s *LiveService) ProfileHealth() []health.Status {
+	if s == nil || s.liveEngine == nil {
+		return nil
+	}
+	return s.liveEngine.ProfileHealth()
+}
+
+func (s *LiveService) findProfile(name string) *profile.Runtime {
+	for _, rt := range s.profileMgr.Profiles() {
+		if rt != nil && strings.EqualFold(rt.Definition.Name, name) {
+			return rt
+		}
+	}
+	return nil
+}
+
+func (s *LiveService) analyzeSymbol(ctx context.Context, symbol string, rt *profile.Runtime, interval string) livehttp.BatchAnalysisResult {
+	res := livehttp.BatchAnalysisResult{Symbol: symbol, Interval: interval}
+	if rt == nil || rt.Pipeline == nil {
+		res.Error = "未匹配到 profile"
+		return res
+	}
+	res.Profile = rt.Definition.Name
+	if res.Interval == "" {
+		if ivs := rt.Definition.IntervalsLower(); len(ivs) > 0 {
+			res.Interval = ivs[0]
+		}
+	}
+	ac := pipeline.NewContext(symbol)
+	ac.Profile = rt.Definition.Name
+	if err := rt.Pipeline.Run(ctx, ac); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Warnings = ac.Warnings()
+	candles := ac.Candles(res.Interval)
+	if len(candles) == 0 {
+		res.Error = fmt.Sprintf("%s 无K线数据", res.Interval)
+		return res
+	}
+	scoring := screen.DivergenceScoring(rt.Definition.DivergenceScoring)
+	res.Summary = screen.SummarizeWith(candles, screen.TrendFromFeatures(ac.Features(), res.Interval), scoring)
+	composite := screen.CompositeFromCandles(rt.Definition.IntervalsLower(), ac.Candles, rt.Definition.Composite.Weights, scoring)
+	res.Score = composite.Score
+	res.Composite = &composite
+	return res
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"brale/internal/agent/gate"
+	"brale/internal/agent/risk"
+	"brale/internal/gateway/exchange"
+)
+
+// RefreshAccountBalance 从执行器拉取最新余额。
+func (s *LiveService) RefreshAccountBalance(ctx context.Context) (exchange.Balance, error) {
+	if s == nil || s.execManager == nil {
+		return exchange.Balance{}, fmt.Errorf("执行器未启用")
+	}
+	return s.execManager.RefreshBalance(ctx)
+}
+
+// PauseSymbol 手动暂停 symbol 的新开仓，持仓的平仓与退出计划不受影响。
+func (s *LiveService) PauseSymbol(symbol string) {
+	if s == nil || s.liveEngine == nil {
+		return
+	}
+	s.liveEngine.PauseSymbol(symbol)
+}
+
+// ResumeSymbols 恢复指定 symbol（未指定时恢复全部）的开仓，返回实际恢复的 symbol。
+func (s *LiveService) ResumeSymbols(symbols ...string) []string {
+	if s == nil || s.liveEngine == nil {
+		return nil
+	}
+	return s.liveEngine.ResumeSymbols(symbols...)
+}
+
+func (s *LiveService) PausedSymbols() map[string]time.Time {
+	if s == nil || s.liveEngine == nil {
+		return nil
+	}
+	return s.liveEngine.PausedSymbols()
+}
+
+// SwitchProfile 运行时把 symbol 切换到指定 profile，name 为空时恢复按 targets 匹配。
+func (s *LiveService) SwitchProfile(symbol, name string) error {
+	if s == nil || s.profileMgr == nil {
+		return fmt.Errorf("profile manager 未初始化")
+	}
+	return s.profileMgr.SwitchProfile(symbol, name)
+}
+
+// ProfileTargets 返回各 profile 当前实际负责的 symbol（已应用手动切换）。
+func (s *LiveService) ProfileTargets() map[string][]string {
+	if s == nil || s.profileMgr == nil {
+		return nil
+	}
+	out := make(map[string][]string)
+	for _, rt := range s.profileMgr.Profiles() {
+		name := rt.Definition.Name
+		if _, ok := out[name]; !ok {
+			out[name] = nil
+		}
+		for _, sym := range rt.Definition.TargetsUpper() {
+			if cur, ok := s.profileMgr.Resolve(sym); ok && cur != nil {
+				out[cur.Definition.Name] = append(out[cur.Definition.Name], sym)
+			}
+		}
+	}
+	for name, syms := range out {
+		slices.Sort(syms)
+		out[name] = slices.Compact(syms)
+	}
+	return out
+}
+
+// BlacklistSymbol 手动把 symbol 加入分析黑名单。
+func (s *LiveService) BlacklistSymbol(symbol, reason string) (gate.Entry, error) {
+	if s == nil || s.liveEngine == nil {
+		return gate.Entry{}, fmt.Errorf("live engine 未初始化")
+	}
+	return s.liveEngine.BlacklistSymbol(symbol, reason), nil
+}
+
+func (s *LiveService) UnblacklistSymbol(symbol string) bool {
+	if s == nil || s.liveEngine == nil {
+		return false
+	}
+	return s.liveEngine.UnblacklistSymbol(symbol)
+}
+
+// SymbolGateStatus 返回手动黑名单与亏损冷却状态。
+func (s *LiveService) SymbolGateStatus() gate.Status {
+	if s == nil || s.liveEngine == nil {
+		return gate.Status{}
+	}
+	return s.liveEngine.SymbolGateStatus()
+}
+
+// CorrelationMatrix 返回相关性闸门最近一次计算的相关矩阵；未启用时返回 false。
+func (s *LiveService) CorrelationMatrix() (risk.CorrelationMatrix, bool) {
+	if s == nil || s.correlation == nil {
+		return risk.CorrelationMatrix{}, false
+	}
+	return s.correlation.Matrix(), true
+}
@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/analysis/screen"
+	"brale/internal/gateway/database"
+	"brale/internal/gateway/exchange"
+	"brale/internal/logger"
+	"brale/internal/market"
+	"brale/internal/store"
+	"brale/internal/strategy/exit"
+	livehttp "brale/internal/transport/http/live"
+)
+
+var dashboardOverlayKeys = []struct {
+	key  string
+	pane string
+}{
+	{key: "ema_fast", pane: "price"},
+	{key: "ema_mid", pane: "price"},
+	{key: "ema_slow", pane: "price"},
+	{key: "rsi", pane: "rsi"},
+	{key: "macd", pane: "macd"},
+	{key: "atr", pane: "atr"},
+}
+
+func (s *LiveService) DashboardChart(ctx context.Context, symbol, interval string, limit int) (livehttp.DashboardChart, error) {
+	chart := livehttp.DashboardChart{
+		Symbol:   strings.ToUpper(strings.TrimSpace(symbol)),
+		Interval: strings.ToLower(strings.TrimSpace(interval)),
+	}
+	if s == nil || s.klineStore == nil {
+		return chart, fmt.Errorf("kline store 未启用")
+	}
+	exporter, ok := s.klineStore.(store.SnapshotExporter)
+	if !ok {
+		return chart, fmt.Errorf("kline store 不支持快照导出")
+	}
+	candles, err := exporter.Export(ctx, chart.Symbol, chart.Interval, limit)
+	if err != nil {
+		return chart, err
+	}
+	if len(candles) == 0 {
+		return chart, fmt.Errorf("%s %s 暂无K线数据", chart.Symbol, chart.Interval)
+	}
+	chart.Candles = candles
+	chart.UpdatedAt = time.Now().UnixMilli()
+
+	rep, err := indicator.ComputeAll(candles, indicator.Settings{Symbol: chart.Symbol, Interval: chart.Interval})
+	if err != nil {
+		chart.Warnings = append(chart.Warnings, fmt.Sprintf("indicator 计算失败: %v", err))
+		return chart, nil
+	}
+	chart.Warnings = append(chart.Warnings, rep.Warnings...)
+	for _, item := range dashboardOverlayKeys {
+		val, ok := rep.Values[item.key]
+		if !ok || len(val.Series) == 0 {
+			continue
+		}
+		chart.Overlays = append(chart.Overlays, livehttp.DashboardOverlay{
+			Key:    item.key,
+			Label:  dashboardOverlayLabel(item.key, val),
+			Pane:   item.pane,
+			Latest: val.Latest,
+			State:  val.State,
+			Points: alignSeriesToCandles(candles, val.Series),
+		})
+	}
+	chart.Overlays = append(chart.Overlays, dashboardOscillators(candles)...)
+	chart.Divergences = dashboardDivergences(candles, s.divergenceScoring(chart.Symbol))
+	return chart, nil
+}
+
+// dashboardOscillators 返回与 vision 图表副图一致的 WT(wt1) 与 MFI 序列；MFI 前导 0 为预热期，不输出。
+func dashboardOscillators(candles []market.Candle) []livehttp.DashboardOverlay {
+	var out []livehttp.DashboardOverlay
+	if wt := screen.WaveTrendSeries(candles); len(wt) > 0 {
+		out = append(out, livehttp.DashboardOverlay{
+			Key:    "wt",
+			Label:  "WT",
+			Pane:   "wt_mfi",
+			Latest: wt[len(wt)-1],
+			Points: alignSeriesToCandles(candles, wt),
+		})
+	}
+	mfi := screen.MFISeries(candles)
+	for len(mfi) > 0 && (mfi[0] == 0 || math.IsNaN(mfi[0])) {
+		mfi = mfi[1:]
+	}
+	if len(mfi) > 0 {
+		out = append(out, livehttp.DashboardOverlay{
+			Key:    "mfi",
+			Label:  "MFI",
+			Pane:   "wt_mfi",
+			Latest: mfi[len(mfi)-1],
+			Points: alignSeriesToCandles(candles, mfi),
+		})
+	}
+	return out
+}
+
+// dashboardDivergences 复用 screen.DivergenceMarkers 的判定，把枢轴下标换成K线时间与高/低点价格。
+func dashboardDivergences(candles []market.Candle, scoring screen.DivergenceScoring) []livehttp.DashboardDivergence {
+	markers := screen.DivergenceMarkers(candles, scoring)
+	out := make([]livehttp.DashboardDivergence, 0, len(markers))
+	for _, m := range markers {
+		if m.PrevIndex < 0 || m.CurIndex >= len(candles) || m.PrevIndex > m.CurIndex {
+			continue
+		}
+		prev, cur := candles[m.PrevIndex], candles[m.CurIndex]
+		item := livehttp.DashboardDivergence{
+			Indicator: m.Indicator,
+			Direction: m.Direction,
+			FromTime:  prev.OpenTime,
+			FromPrice: prev.Low,
+			ToTime:    cur.OpenTime,
+			ToPrice:   cur.Low,
+		}
+		if m.Direction == "bearish" {
+			item.FromPrice, item.ToPrice = prev.High, cur.High
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// divergenceScoring 取 symbol 所属 profile 的背离打分配置；未匹配时用默认（仅 RSI）。
+func (s *LiveService) divergenceScoring(symbol string) screen.DivergenceScoring {
+	if s.profileMgr == nil {
+		return screen.DivergenceScoring{}
+	}
+	rt, ok := s.profileMgr.Resolve(symbol)
+	if !ok || rt == nil {
+		return screen.DivergenceScoring{}
+	}
+	return screen.DivergenceScoring(rt.Definition.DivergenceScoring)
+}
+
+func dashboardOverlayLabel(key string, val indicator.IndicatorValue) string {
+	if strings.HasPrefix(key, "ema_") && strings.TrimSpace(val.Note) != "" {
+		return strings.TrimSuffix(val.Note, " vs price")
+	}
+	if key == "macd" {
+		return "MACD hist"
+	}
+	return strings.ToUpper(key)
+}
+
+// alignSeriesToCandles 指标序列已剔除前导无效值，按尾部对齐到K线开盘时间。
+func alignSeriesToCandles(candles []market.Candle, series []float64) []livehttp.DashboardPoint {
+	offset := len(candles) - len(series)
+	if offset < 0 {
+		series = series[-offset:]
+		offset = 0
+	}
+	points := make([]livehttp.DashboardPoint, 0, len(series))
+	for i, v := range series {
+		points = append(points, livehttp.DashboardPoint{Time: candles[offset+i].OpenTime, Value: v})
+	}
+	return points
+}
+
+func buildDashboardTierLines(recs []database.StrategyInstanceRecord) []livehttp.DashboardTierLine {
+	lines := make([]livehttp.DashboardTierLine, 0, len(recs))
+	for _, rec := range recs {
+		if strings.TrimSpace(rec.PlanComponent) == "" {
+			continue
+		}
+		state, err := exit.DecodeTierComponentState(rec.StateJSON)
+		if err != nil || state.TargetPrice <= 0 {
+			continue
+		}
+		lines = append(lines, livehttp.DashboardTierLine{
+			PlanID:    rec.PlanID,
+			Component: rec.PlanComponent,
+			Mode:      state.Mode,
+			Price:     state.TargetPrice,
+			Ratio:     state.Ratio,
+			Status:    state.Status,
+		})
+	}
+	return lines
+}
+
+func (s *LiveService) DashboardPositions(ctx context.Context) ([]livehttp.DashboardPosition, error) {
+	if s == nil || s.execManager == nil {
+		return nil, nil
+	}
+	res, err := s.execManager.PositionsForAPI(ctx, exchange.PositionListOptions{
+		Page:     1,
+		PageSize: 500,
+		Status:   "active",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var pending map[int]string
+	if pm, ok := s.execManager.(interface{ PendingStages() map[int]string }); ok {
+		pending = pm.PendingStages()
+	}
+	out := make([]livehttp.DashboardPosition, 0, len(res.Positions))
+	for _, pos := range res.Positions {
+		item := livehttp.DashboardPosition{APIPosition: pos, PendingStage: pending[pos.TradeID]}
+		if s.strategyStore != nil && pos.TradeID > 0 {
+			recs, err := s.strategyStore.ListStrategyInstances(ctx, pos.TradeID)
+			if err != nil {
+				logger.Warnf("dashboard 加载 tier 失败 trade=%d err=%v", pos.TradeID, err)
+			} else {
+				item.TierLines = buildDashboardTierLines(recs)
+			}
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (s *LiveService) LastPrices() map[string]livehttp.PriceTick {
+	if s == nil || s.monitor == nil {
+		return nil
+	}
+	quotes := s.monitor.LastPrices()
+	out := make(map[string]livehttp.PriceTick, len(quotes))
+	for sym, q := range quotes {
+		out[sym] = livehttp.PriceTick{Price: q.Last, TS: q.UpdatedAt.UnixMilli()}
+	}
+	return out
+}
+
+func (s *LiveService) DashboardProfiles() []livehttp.DashboardProfileStatus {
+	if s == nil || s.profileMgr == nil {
+		return nil
+	}
+	runtimes := s.profileMgr.Profiles()
+	out := make([]livehttp.DashboardProfileStatus, 0, len(runtimes))
+	for _, rt := range runtimes {
+		if rt == nil {
+			continue
+		}
+		def := rt.Definition
+		mws := make([]string, 0, len(def.Middlewares))
+		for _, mw := range def.Middlewares {
+			mws = append(mws, mw.Name)
+		}
+		out = append(out, livehttp.DashboardProfileStatus{
+			Name:          def.Name,
+			Default:       def.Default,
+			Targets:       def.TargetsUpper(),
+			Intervals:     def.IntervalsLower(),
+			Middlewares:   mws,
+			ExitCombos:    def.ExitPlanCombos(),
+			AgentEnabled:  rt.AgentEnabled,
+			AnalysisSlice: rt.AnalysisSlice,
+			IndicatorBars: rt.IndicatorBars,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *LiveService) KlineConsistency(ctx context.Context, symbol, interval string) ([]store.KlineConsistencyReport, error) {
+	if s == nil || s.klineStore == nil {
+		return nil, fmt.Errorf("kline store 未启用")
+	}
+	checker, ok := s.klineStore.(interface {
+		CheckConsistency(ctx context.Context, symbol, interval string) []store.KlineConsistencyReport
+	})
+	if !ok {
+		return nil, fmt.Errorf("kline store 不支持一致性检查")
+	}
+	return checker.CheckConsistency(ctx, symbol, interval), nil
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"brale/internal/agent/engine"
+	"brale/internal/decision"
+	livehttp "brale/internal/transport/http/live"
+)
+
+// DecisionDryRun 对单个 symbol 运行完整决策链路并返回本轮会产出的决策，不执行、不持久化。
+func (s *LiveService) DecisionDryRun(ctx context.Context, req livehttp.DecisionDryRunRequest) (livehttp.DecisionDryRunResult, error) {
+	if s == nil || s.liveEngine == nil {
+		return livehttp.DecisionDryRunResult{}, fmt.Errorf("决策引擎未初始化")
+	}
+	report, err := s.liveEngine.DryRun(ctx, req.Symbol, req.Profile)
+	return dryRunResult(report), err
+}
+
+func dryRunResult(report engine.DryRunReport) livehttp.DecisionDryRunResult {
+	out := livehttp.DecisionDryRunResult{
+		Symbol:       report.Symbol,
+		Profile:      report.Profile,
+		Warnings:     report.Warnings,
+		SystemPrompt: report.Input.Prompt.System,
+		UserPrompt:   report.Input.Prompt.User,
+		Decisions:    report.Result.Decisions,
+		RawOutput:    report.Result.RawOutput,
+		MetaSummary:  report.Result.MetaSummary,
+	}
+	if out.Decisions == nil {
+		out.Decisions = []decision.Decision{}
+	}
+	for _, f := range report.Features {
+		line := strings.TrimSpace(f.Description)
+		if line == "" {
+			line = fmt.Sprintf("%s=%.4f", f.Key, f.Value)
+		}
+		out.Features = append(out.Features, line)
+	}
+	if len(report.Traces) == 0 {
+		return out
+	}
+	// 以最终模型实际使用的 prompt 为准（DecisionEngine 会按 profile/模型重新组装）。
+	trace := report.Traces[len(report.Traces)-1]
+	if trace.SystemPrompt != "" {
+		out.SystemPrompt = trace.SystemPrompt
+	}
+	if trace.UserPrompt != "" {
+		out.UserPrompt = trace.UserPrompt
+	}
+	for _, o := range trace.Outputs {
+		item := livehttp.DecisionDryRunOutput{
+			Provider:  o.ProviderID,
+			Raw:       o.Raw,
+			LatencyMs: o.Latency.Milliseconds(),
+		}
+		if o.Err != nil {
+			item.Error = o.Err.Error()
+		}
+		out.Outputs = append(out.Outputs, item)
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	brcfg "brale/internal/config"
+	"brale/internal/decision"
+	"brale/internal/logger"
+	"brale/internal/pkg/contract"
+	"brale/internal/types"
+)
+
+func newContractCalendar(cfg *brcfg.Config) *contract.Calendar {
+	if cfg == nil {
+		return contract.NewCalendar(0, 0, nil)
+	}
+	cc := cfg.Advanced.ContractCalendar
+	overrides := make(map[string]time.Time, len(cc.Expiries))
+	for sym, raw := range cc.Expiries {
+		ts, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+		if err != nil {
+			logger.Warnf("contract_calendar: 忽略无效到期时间 %s=%s: %v", sym, raw, err)
+			continue
+		}
+		overrides[sym] = ts
+	}
+	return contract.NewCalendar(
+		time.Duration(cc.EntryCutoffHours)*time.Hour,
+		time.Duration(cc.CloseBeforeHours)*time.Hour,
+		overrides,
+	)
+}
+
+// daysToExpiry 返回候选与持仓中交割合约的剩余天数，供 prompt 元信息标注；全为永续时返回 nil。
+func (e *LiveEngine) daysToExpiry(now time.Time, symbols []string, positions []types.PositionSnapshot) map[string]float64 {
+	var out map[string]float64
+	add := func(sym string) {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		days, ok := e.Contracts.DaysToExpiry(sym, now)
+		if !ok {
+			return
+		}
+		if out == nil {
+			out = make(map[string]float64)
+		}
+		out[sym] = days
+	}
+	for _, sym := range symbols {
+		add(sym)
+	}
+	for _, pos := range positions {
+		add(pos.Symbol)
+	}
+	return out
+}
+
+// closeExpiringPositions 在交割前窗口内直接平掉交割合约持仓（不经过模型），返回已处理的 symbol，
+// 本轮决策中这些 symbol 的其他动作会被忽略。
+func (e *LiveEngine) closeExpiringPositions(ctx context.Context, now time.Time, positions []types.PositionSnapshot) map[string]bool {
+	var done map[string]bool
+	for _, pos := range positions {
+		if !e.Contracts.ShouldClose(pos.Symbol, now) {
+			continue
+		}
+		action := "close_long"
+		if strings.EqualFold(strings.TrimSpace(pos.Side), "short") {
+			action = "close_short"
+		}
+		left, _ := e.Contracts.TimeToExpiry(pos.Symbol, now)
+		d := decision.Decision{
+			Symbol:     strings.ToUpper(strings.TrimSpace(pos.Symbol)),
+			Action:     action,
+			CloseRatio: 1,
+			Reasoning:  fmt.Sprintf("交割合约距结算 %.1fh，交割前强制平仓", left.Hours()),
+		}
+		traceID := fmt.Sprintf("expiry-%d", now.UnixNano())
+		if err := e.execute(ctx, traceID, d); err != nil {
+			logger.Errorf("LiveEngine: %s 交割前强制平仓失败: %v", d.Symbol, err)
+			continue
+		}
+		logger.Infof("LiveEngine: %s 临近交割已强制平仓 trace=%s", d.Symbol, traceID)
+		e.publishDecision(traceID, d, 0)
+		if done == nil {
+			done = make(map[string]bool)
+		}
+		done[d.Symbol] = true
+	}
+	return done
+}
+
+func dropSymbols(items []decision.Decision, symbols map[string]bool) []decision.Decision {
+	if len(symbols) == 0 {
+		return items
+	}
+	out := make([]decision.Decision, 0, len(items))
+	for _, d := range items {
+		if symbols[strings.ToUpper(strings.TrimSpace(d.Symbol))] {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
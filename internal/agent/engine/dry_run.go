@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"brale/internal/decision"
+	"brale/internal/pipeline"
+	"brale/internal/profile"
+)
+
+// DryRunReport 为一次 dry-run 的完整产出：pipeline 特征、决策上下文与模型结果，均未执行、未落库。
+type DryRunReport struct {
+	Symbol   string
+	Profile  string
+	Features []pipeline.Feature
+	Warnings []string
+	Input    decision.Context
+	Result   decision.DecisionResult
+	Traces   []decision.DecisionTrace
+}
+
+// DryRun 对单个 symbol 走完 pipeline → 快照 → prompt → 模型调用，返回本轮会产出的决策。
+// profileName 非空时仅在本次调用内改用该 profile；不下单、不写决策日志、不更新指标缓存。
+func (e *LiveEngine) DryRun(ctx context.Context, symbol, profileName string) (DryRunReport, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return DryRunReport{}, fmt.Errorf("symbol 不能为空")
+	}
+	if e == nil || e.ProfileMgr == nil || e.Decider == nil {
+		return DryRunReport{}, fmt.Errorf("决策引擎未初始化")
+	}
+	if name := strings.TrimSpace(profileName); name != "" {
+		if _, ok := e.ProfileMgr.Lookup(name); !ok {
+			return DryRunReport{}, fmt.Errorf("profile %s 不存在", name)
+		}
+		ctx = profile.WithProfile(ctx, name)
+	}
+	rt, ok := e.ProfileMgr.ResolveContext(ctx, symbol)
+	if !ok || rt == nil {
+		return DryRunReport{}, fmt.Errorf("%s 未匹配到 profile", symbol)
+	}
+	report := DryRunReport{Symbol: symbol, Profile: rt.Definition.Name}
+	if rt.Pipeline != nil {
+		ac := pipeline.NewContext(symbol)
+		ac.Profile = rt.Definition.Name
+		if err := rt.Pipeline.Run(ctx, ac); err != nil {
+			report.Warnings = append(report.Warnings, "pipeline: "+err.Error())
+		}
+		report.Features = ac.Features()
+		report.Warnings = append(report.Warnings, ac.Warnings()...)
+	}
+
+	ctx, capture := decision.WithDryRun(ctx)
+	input, err := e.sense(ctx, []string{symbol})
+	if err != nil {
+		return report, fmt.Errorf("sense failed: %w", err)
+	}
+	if stale := e.checkStaleData(input); len(stale) > 0 {
+		input.HardFlags.DataStaleFlag = true
+		report.Warnings = append(report.Warnings, "stale_data: "+joinStale(stale))
+	}
+	report.Input = input
+	res, err := e.decide(ctx, input)
+	report.Traces = capture.Traces()
+	if err != nil {
+		return report, fmt.Errorf("decide failed: %w", err)
+	}
+	report.Result = res
+	return report, nil
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/logger"
+)
+
+// stampEntryReferences 为开仓决策记下 sense 快照的行情价与时间：参考价即模型决策所依据的价格，
+// 延迟因此从快照时刻起算，包含模型决策耗时，而非从收到决策时起算。
+func stampEntryReferences(decisions []decision.Decision, input decision.Context) {
+	for i := range decisions {
+		d := &decisions[i]
+		if d.Action != "open_long" && d.Action != "open_short" {
+			continue
+		}
+		md, ok := input.Market[strings.ToUpper(strings.TrimSpace(d.Symbol))]
+		if !ok || md.Price <= 0 {
+			continue
+		}
+		d.EntryDrift = &decision.EntryDrift{ReferencePrice: md.Price, DecidedAt: input.TimestampNow}
+	}
+}
+
+// checkEntryDrift 比较决策参考价与下单前实时价：耗时超出预算或 action=abort 下漂移超限时返回拒绝原因；
+// action=resize 时把 d 的保证金按比例缩减后放行（返回空字符串）。漂移结果写回 d.EntryDrift，随开仓记入操作记录。
+func (e *LiveEngine) checkEntryDrift(d *decision.Decision, marketPrice float64, now time.Time) string {
+	if d == nil || d.EntryDrift == nil || e.Config == nil || !e.Config.Advanced.EntryDrift.Enabled {
+		return ""
+	}
+	cfg := e.Config.Advanced.EntryDrift
+	drift := *d.EntryDrift
+	latency := now.Sub(drift.DecidedAt)
+	drift.LatencyMs = latency.Milliseconds()
+	drift.Action = "pass"
+	d.EntryDrift = &drift
+	if cfg.MaxLatencySeconds > 0 && latency > time.Duration(cfg.MaxLatencySeconds)*time.Second {
+		return fmt.Sprintf("决策到执行耗时 %s 超过预算 %ds", latency.Round(time.Second), cfg.MaxLatencySeconds)
+	}
+	if marketPrice <= 0 || drift.ReferencePrice <= 0 {
+		return ""
+	}
+	move := marketPrice - drift.ReferencePrice
+	if d.Action == "open_short" {
+		move = -move
+	}
+	drift.MarketPrice = marketPrice
+	drift.DriftPct = move / drift.ReferencePrice
+	if e.MktService != nil {
+		if atr, ok := e.MktService.GetATR(d.Symbol); ok && atr > 0 {
+			drift.DriftATR = move / atr
+		}
+	}
+	var exceeded []string
+	if cfg.MaxDriftPct > 0 && drift.DriftPct > cfg.MaxDriftPct {
+		exceeded = append(exceeded, fmt.Sprintf("%.2f%% > %.2f%%", drift.DriftPct*100, cfg.MaxDriftPct*100))
+	}
+	if cfg.MaxDriftATR > 0 && drift.DriftATR > cfg.MaxDriftATR {
+		exceeded = append(exceeded, fmt.Sprintf("%.2f ATR > %.2f ATR", drift.DriftATR, cfg.MaxDriftATR))
+	}
+	if len(exceeded) == 0 {
+		return ""
+	}
+	reason := fmt.Sprintf("决策后价格不利漂移 %s（参考价 %.6f → %.6f）", strings.Join(exceeded, "，"), drift.ReferencePrice, marketPrice)
+	if cfg.Action != "resize" {
+		return reason
+	}
+	before := d.PositionSizeUSD
+	d.PositionSizeUSD = before * cfg.ResizeRatio
+	drift.Action = "resize"
+	logger.Infof("漂移守卫: %s %s %s，保证金 %.2f → %.2f", d.Symbol, d.Action, reason, before, d.PositionSizeUSD)
+	return ""
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"brale/internal/agent/risk"
+	"brale/internal/decision"
+	"brale/internal/logger"
+)
+
+// 非风控闸门拒绝开仓时写入决策日志的 stage。
+const (
+	stageCorrelation = "correlation"
+	stageTradingMode = "trading_mode"
+	stageEntryDrift  = "entry_drift"
+)
+
+// entryGates 为一轮开仓共用的闸门状态：相关性比较的持仓与风控敞口在第一笔开仓时加载，放行的开仓随后累加进去。
+type entryGates struct {
+	exposure   *risk.Exposure
+	held       []decision.PositionSnapshot
+	heldLoaded bool
+}
+
+// entryBlockReason 依次执行熔断、手动暂停、临近交割与相关性闸门，返回拦截原因；非开仓决策直接放行。
+// 相关性闸门可能按 downsize 缩减 d 的保证金。
+func (e *LiveEngine) entryBlockReason(ctx context.Context, traceID string, d *decision.Decision, g *entryGates) string {
+	if d.Action != "open_long" && d.Action != "open_short" {
+		return ""
+	}
+	if e.EntryGate != nil {
+		if halted, reason := e.EntryGate.EntriesHalted(); halted {
+			logger.Infof("开仓已熔断，跳过 %s %s: %s", d.Symbol, d.Action, reason)
+			return "开仓已熔断: " + reason
+		}
+	}
+	if e.SymbolPaused(d.Symbol) {
+		logger.Infof("交易对已手动暂停开仓，跳过 %s %s", d.Symbol, d.Action)
+		return "交易对已手动暂停开仓"
+	}
+	if blocked, reason := e.Contracts.EntryBlocked(d.Symbol, time.Now()); blocked {
+		logger.Infof("临近交割，跳过 %s %s: %s", d.Symbol, d.Action, reason)
+		return "临近交割: " + reason
+	}
+	if e.Correlation != nil {
+		if !g.heldLoaded {
+			g.held, g.heldLoaded = e.correlationPositions(ctx), true
+		}
+		if reason := e.Correlation.Check(d, g.held); reason != "" {
+			e.rejectEntry(ctx, traceID, *d, stageCorrelation, "correlation_limit", reason)
+			return reason
+		}
+	}
+	return ""
+}
+
+// riskBlockReason 执行组合风控闸门，返回拦截原因；持仓读取失败时拒绝开仓。
+func (e *LiveEngine) riskBlockReason(ctx context.Context, traceID string, d decision.Decision, g *entryGates) string {
+	if (d.Action != "open_long" && d.Action != "open_short") || !e.Risk.Enabled() {
+		return ""
+	}
+	if g.exposure == nil {
+		g.exposure = e.riskExposure(ctx)
+	}
+	reason := "风控无法获取当前持仓"
+	if g.exposure != nil {
+		reason = e.Risk.Check(g.exposure, d)
+	}
+	if reason != "" {
+		e.rejectEntry(ctx, traceID, d, risk.Stage, risk.Note, reason)
+	}
+	return reason
+}
+
+// rejectEntry 记录被闸门拒绝的开仓，并把降级后的 skip 决策推送出去，便于订阅方看到拒绝原因。
+func (e *LiveEngine) rejectEntry(ctx context.Context, traceID string, d decision.Decision, stage, note, reason string) {
+	skipped := e.Risk.Reject(ctx, traceID, d, stage, note, reason)
+	e.publishDecision(traceID, skipped, 0)
+}
+
+// commitEntry 把已执行的开仓计入本轮闸门状态。
+func (g *entryGates) commitEntry(e *LiveEngine, d decision.Decision) {
+	e.Risk.Commit(g.exposure, d)
+	if (d.Action == "open_long" || d.Action == "open_short") && g.heldLoaded {
+		g.held = append(g.held, decision.PositionSnapshot{Symbol: d.Symbol, Side: strings.TrimPrefix(d.Action, "open_")})
+	}
+}
+
+// AdmitScaleIn 让分批加仓经过与新开仓相同的闸门（熔断、暂停、临近交割、相关性、组合风控），
+// 返回放行后的保证金（相关性 downsize 可能缩减）；被拦截时返回错误。
+func (e *LiveEngine) AdmitScaleIn(ctx context.Context, tradeID int, symbol, side string, stake, leverage float64) (float64, error) {
+	if e == nil {
+		return stake, nil
+	}
+	d := decision.Decision{
+		Symbol:          strings.ToUpper(strings.TrimSpace(symbol)),
+		Action:          "open_" + strings.ToLower(strings.TrimSpace(side)),
+		PositionSizeUSD: stake,
+		Leverage:        int(math.Round(leverage)),
+		Reasoning:       fmt.Sprintf("scale_in trade=%d", tradeID),
+	}
+	traceID := fmt.Sprintf("scale_in-%d-%d", tradeID, time.Now().UnixMilli())
+	g := &entryGates{}
+	if reason := e.entryBlockReason(ctx, traceID, &d, g); reason != "" {
+		return 0, fmt.Errorf("加仓被拦截: %s", reason)
+	}
+	if reason := e.riskBlockReason(ctx, traceID, d, g); reason != "" {
+		return 0, fmt.Errorf("加仓被风控拒绝: %s", reason)
+	}
+	return d.PositionSizeUSD, nil
+}
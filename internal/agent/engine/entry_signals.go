@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/attribution"
+	"brale/internal/analysis/screen"
+	"brale/internal/decision"
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+	"brale/internal/market"
+)
+
+const entrySignalTimeout = 30 * time.Second
+
+// EntrySignalRecorder 持久化开仓时刻的背离快照，供事后按指标做绩效归因。
+type EntrySignalRecorder interface {
+	InsertEntrySignals(ctx context.Context, rec database.EntrySignalRecord) error
+}
+
+// recordEntrySignals 为本轮成功开仓的 symbol 落库各周期的 RSI/CVD 背离，背离取自本轮决策所用的分析上下文，
+// 与触发开仓的K线一致；写库异步进行，不阻塞后续决策。
+func (e *LiveEngine) recordEntrySignals(ctx context.Context, traceID string, accepted []decision.Decision, analysis []decision.AnalysisContext) {
+	if e == nil || e.EntrySignals == nil {
+		return
+	}
+	var records []database.EntrySignalRecord
+	for _, d := range accepted {
+		if d.Action != "open_long" && d.Action != "open_short" {
+			continue
+		}
+		symbol := strings.ToUpper(strings.TrimSpace(d.Symbol))
+		signals := entrySignalsFromAnalysis(symbol, analysis)
+		if len(signals) == 0 {
+			continue
+		}
+		records = append(records, database.EntrySignalRecord{
+			TraceID: traceID,
+			Symbol:  symbol,
+			Side:    strings.TrimPrefix(d.Action, "open_"),
+			Signals: signals,
+		})
+	}
+	if len(records) == 0 {
+		return
+	}
+	go func() {
+		runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), entrySignalTimeout)
+		defer cancel()
+		for _, rec := range records {
+			if err := e.EntrySignals.InsertEntrySignals(runCtx, rec); err != nil {
+				logger.Warnf("开仓信号快照写入失败 %s: %v", rec.Symbol, err)
+			}
+		}
+	}()
+}
+
+// entrySignalsFromAnalysis 在 symbol 各周期的分析K线上判定 RSI/CVD 背离，键为 rsi_div@<interval>/cvd_div@<interval>。
+func entrySignalsFromAnalysis(symbol string, analysis []decision.AnalysisContext) map[string]string {
+	signals := make(map[string]string)
+	for _, ac := range analysis {
+		if !strings.EqualFold(strings.TrimSpace(ac.Symbol), symbol) {
+			continue
+		}
+		iv := strings.ToLower(strings.TrimSpace(ac.Interval))
+		candles := decodeCandles(ac.KlineJSON)
+		if iv == "" || len(candles) == 0 {
+			continue
+		}
+		signals["rsi_div@"+iv] = screen.Summarize(candles, "").Divergence
+		if cvd, ok := market.ComputeCVD(candles); ok {
+			signals["cvd_div@"+iv] = cvdSignal(cvd.Divergence)
+		}
+	}
+	return signals
+}
+
+// decodeCandles 解析分析上下文中的K线 JSON，为空或解析失败时返回 nil。
+func decodeCandles(raw string) []market.Candle {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	var candles []market.Candle
+	if err := json.Unmarshal([]byte(raw), &candles); err != nil {
+		return nil
+	}
+	return candles
+}
+
+// cvdSignal 把 ComputeCVD 的 up/down/neutral 统一为 bullish/bearish/none。
+func cvdSignal(div string) string {
+	switch strings.ToLower(div) {
+	case "up":
+		return attribution.SignalBullish
+	case "down":
+		return attribution.SignalBearish
+	default:
+		return attribution.SignalNone
+	}
+}
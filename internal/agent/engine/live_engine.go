@@ -2,20 +2,29 @@ package engine
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"brale/internal/agent/gate"
+	"brale/internal/agent/health"
 	"brale/internal/agent/interfaces"
 	"brale/internal/agent/prompt"
+	"brale/internal/agent/risk"
+	"brale/internal/analysis/screen"
 	brcfg "brale/internal/config"
+	"brale/internal/config/loader"
 	"brale/internal/decision"
 	"brale/internal/exitplan"
+	"brale/internal/gateway/webhook"
 	"brale/internal/logger"
-	"brale/internal/market"
+	"brale/internal/pipeline"
 	"brale/internal/pkg/circuit"
+	"brale/internal/pkg/contract"
 	"brale/internal/profile"
 	promptkit "brale/internal/prompt"
 	"brale/internal/scheduler"
@@ -42,6 +51,50 @@ type LiveEngine struct {
 	Notifier        Notifier
 	PromptStrategy  *prompt.StandardStrategy
 	Candidates      []string
+	Screening       *screen.Counter
+	EntryGate       EntryGate
+	Webhooks        *webhook.Dispatcher
+	EntrySignals    EntrySignalRecorder
+	Health          *health.Tracker
+	Contracts       *contract.Calendar
+	Risk            *risk.Manager
+	Gate            *gate.Registry
+	LossHistory     LossHistory
+	Backfill        KlineBackfill
+	SymbolRules     SymbolRules
+	Correlation     CorrelationGate
+
+	divergenceMu   sync.Mutex
+	lastDivergence map[string]string
+
+	supertrendMu       sync.Mutex
+	lastSupertrendFlip map[string]int64
+
+	staleMu      sync.Mutex
+	staleAlerted map[string]bool
+
+	pipelineRunMu   sync.RWMutex
+	lastPipelineRun *pipeline.RunReport
+
+	candidatesMu sync.RWMutex
+
+	pausedMu sync.RWMutex
+	paused   map[string]time.Time
+}
+
+// KlineBackfill 在行情断线重连后的补数据期间阻塞分析，避免基于残缺 K 线决策。
+type KlineBackfill interface {
+	WaitBackfill(ctx context.Context) error
+}
+
+// EntryGate 为全局开仓闸门（如波动熔断）；返回 true 时暂停所有新开仓，平仓照常执行。
+type EntryGate interface {
+	EntriesHalted() (bool, string)
+}
+
+// CorrelationGate 为相关性闸门：返回非空原因时拒绝开仓，也可能直接缩减 d 的仓位后放行。
+type CorrelationGate interface {
+	Check(d *decision.Decision, positions []decision.PositionSnapshot) string
 }
 
 type EngineParams struct {
@@ -58,6 +111,14 @@ type EngineParams struct {
 	Candidates      []string
 	ExitPlanPrompts map[string]promptkit.ExitPlanPrompt
 	Notifier        Notifier
+	EntryGate       EntryGate
+	Webhooks        *webhook.Dispatcher
+	EntrySignals    EntrySignalRecorder
+	RiskStore       risk.Store
+	LossHistory     LossHistory
+	Backfill        KlineBackfill
+	SymbolRules     SymbolRules
+	Correlation     CorrelationGate
 }
 
 func NewLiveEngine(p EngineParams) *LiveEngine {
@@ -65,7 +126,7 @@ func NewLiveEngine(p EngineParams) *LiveEngine {
 	cb := circuit.NewCircuitBreaker("LiveEngine", 5, 2*time.Minute)
 	promptStrategy := prompt.NewStandardStrategy(p.ExitPlans, p.ExitPlanPrompts)
 
-	return &LiveEngine{
+	e := &LiveEngine{
 		Config:          p.Config,
 		PosService:      p.PosService,
 		MktService:      p.MktService,
@@ -79,7 +140,69 @@ func NewLiveEngine(p EngineParams) *LiveEngine {
 		ExitPlanPrompts: p.ExitPlanPrompts,
 		Notifier:        p.Notifier,
 		PromptStrategy:  promptStrategy,
+		Screening:       screen.NewCounter(),
+		EntryGate:       p.EntryGate,
+		Webhooks:        p.Webhooks,
+		EntrySignals:    p.EntrySignals,
+		Gate:            gate.NewRegistry(),
+		LossHistory:     p.LossHistory,
+		Backfill:        p.Backfill,
+		SymbolRules:     p.SymbolRules,
+		Correlation:     p.Correlation,
+	}
+	staleCycles := 0
+	if p.Config != nil {
+		staleCycles = p.Config.AI.StaleProfileCycles
+	}
+	e.Health = health.NewTracker(staleCycles, e.notifyProfileStale, e.notifyProfileRecovered)
+	e.Contracts = newContractCalendar(p.Config)
+	if p.Config != nil {
+		e.Risk = risk.NewManager(p.Config.Advanced.Risk, p.RiskStore)
+	}
+	return e
+}
+
+// decide 在单轮决策时限内调用 Decider；超时或停机时取消未完成的模型调用与重试退避，执行阶段仍使用外层 ctx。
+// 时限为负数时不设 deadline，仅随外层 ctx 取消。
+// decide 先由规则处理 rules.mode=replace 的 symbol，其余交给模型；模型失败时 rules.mode=fallback 的 symbol 改用规则兜底。
+func (e *LiveEngine) decide(ctx context.Context, input decision.Context) (decision.DecisionResult, error) {
+	ruled, llm := e.ruleSymbols(ctx, input.Candidates, loader.RulesModeReplace)
+	var res decision.DecisionResult
+	if len(llm) > 0 {
+		llmInput := input
+		llmInput.Candidates = llm
+		var err error
+		res, err = e.decideLLM(ctx, llmInput)
+		if err != nil {
+			fallback, _ := e.ruleSymbols(ctx, llm, loader.RulesModeFallback)
+			if len(fallback) == 0 {
+				return res, err
+			}
+			logger.Warnf("LiveEngine: 模型决策失败，规则兜底 symbols=%v: %v", fallback, err)
+			res = decision.DecisionResult{}
+			ruled = append(ruled, fallback...)
+		}
 	}
+	if len(ruled) > 0 {
+		res.Decisions = append(res.Decisions, e.ruleDecisions(ctx, ruled, input.Positions)...)
+		if res.TraceID == "" {
+			res.TraceID = "rules-" + input.RunID
+		}
+	}
+	return res, nil
+}
+
+func (e *LiveEngine) decideLLM(ctx context.Context, input decision.Context) (decision.DecisionResult, error) {
+	if e.Config != nil && e.Config.AI.DecisionTimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(e.Config.AI.DecisionTimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+	res, err := e.Decider.Decide(ctx, input)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return res, fmt.Errorf("决策超时 (%ds): %w", e.Config.AI.DecisionTimeoutSeconds, err)
+	}
+	return res, err
 }
 
 func (e *LiveEngine) Run(ctx context.Context) error {
@@ -99,6 +222,7 @@ func (e *LiveEngine) Run(ctx context.Context) error {
 	}
 
 	logger.Infof("LiveEngine: Starting per-symbol aligned loops symbols=%d offset=%s run_immediately=%v", len(symbols), offset, runImmediately)
+	offsets := e.symbolOffsets(symbols, offset)
 
 	group, gctx := errgroup.WithContext(ctx)
 	for _, sym := range symbols {
@@ -111,12 +235,13 @@ func (e *LiveEngine) Run(ctx context.Context) error {
 				return gctx.Err()
 			}
 			cb := circuit.NewCircuitBreaker("LiveEngine."+sym, 5, 2*time.Minute)
-			sched := scheduler.NewAlignedOnceScheduler(gctx, align, interval, offset)
+			sched := scheduler.NewAlignedOnceScheduler(gctx, align, interval, offsets[sym])
 			sched.Name = fmt.Sprintf("%s x%d", sym, multiple)
 			sched.RunImmediately = runImmediately
 			sched.Start(func() {
 				if cb != nil && !cb.Allow() {
 					logger.Warnf("LiveEngine: Circuit breaker open, skipping tick symbol=%s", sym)
+					e.recordTickFailure(sym, "熔断中，跳过本轮决策")
 					return
 				}
 				if err := e.tickSymbols(gctx, []string{sym}); err != nil {
@@ -124,11 +249,15 @@ func (e *LiveEngine) Run(ctx context.Context) error {
 					if cb != nil {
 						cb.RecordFailure()
 					}
+					if gctx.Err() == nil {
+						e.recordTickFailure(sym, err.Error())
+					}
 					return
 				}
 				if cb != nil {
 					cb.RecordSuccess()
 				}
+				e.recordTickSuccess(sym)
 			})
 			return nil
 		})
@@ -136,13 +265,26 @@ func (e *LiveEngine) Run(ctx context.Context) error {
 	return group.Wait()
 }
 
+// SetCandidates 替换候选交易对（profile 热更新时调用），下一轮决策生效。
+func (e *LiveEngine) SetCandidates(symbols []string) {
+	if e == nil {
+		return
+	}
+	e.candidatesMu.Lock()
+	e.Candidates = append([]string(nil), symbols...)
+	e.candidatesMu.Unlock()
+}
+
 func (e *LiveEngine) resolveCandidates() []string {
 	if e == nil {
 		return nil
 	}
-	if len(e.Candidates) > 0 {
-		out := make([]string, 0, len(e.Candidates))
-		for _, sym := range e.Candidates {
+	e.candidatesMu.RLock()
+	candidates := e.Candidates
+	e.candidatesMu.RUnlock()
+	if len(candidates) > 0 {
+		out := make([]string, 0, len(candidates))
+		for _, sym := range candidates {
 			s := strings.ToUpper(strings.TrimSpace(sym))
 			if s == "" {
 				continue
@@ -212,22 +354,85 @@ func (e *LiveEngine) symbolSchedule(symbol string) (align time.Duration, interva
 	return align, interval, multiple, true
 }
 
+// symbolOffsets 计算每个 symbol 的触发延后：全局 offset 叠加所属 profile 的 schedule
+// （整体延后、按批次在窗口内均匀错开、按 symbol 散列的抖动），结果对 interval 取模。
+func (e *LiveEngine) symbolOffsets(symbols []string, base time.Duration) map[string]time.Duration {
+	out := make(map[string]time.Duration, len(symbols))
+	groups := make(map[string][]string)
+	for _, sym := range symbols {
+		out[sym] = base
+		if e.ProfileMgr == nil {
+			continue
+		}
+		if rt, ok := e.ProfileMgr.Resolve(sym); ok && rt != nil {
+			groups[rt.Definition.Name] = append(groups[rt.Definition.Name], sym)
+		}
+	}
+	for _, syms := range groups {
+		rt, _ := e.ProfileMgr.Resolve(syms[0])
+		cfg := rt.Definition.Schedule
+		align, interval, _, ok := e.symbolSchedule(syms[0])
+		if !ok {
+			continue
+		}
+		batchSize := max(cfg.BatchSize, 1)
+		var spacing time.Duration
+		if cfg.BatchSize > 0 && len(syms) > cfg.BatchSize {
+			spread := time.Duration(cfg.SpreadSeconds) * time.Second
+			if spread <= 0 {
+				spread = align / 4
+			}
+			batches := (len(syms) + batchSize - 1) / batchSize
+			spacing = spread / time.Duration(batches)
+		}
+		for i, sym := range syms {
+			d := base + time.Duration(cfg.OffsetSeconds)*time.Second + time.Duration(i/batchSize)*spacing
+			if cfg.JitterSeconds > 0 {
+				h := fnv.New32a()
+				_, _ = h.Write([]byte(sym))
+				d += time.Duration(h.Sum32()%uint32(cfg.JitterSeconds)) * time.Second
+			}
+			out[sym] = d % interval
+		}
+	}
+	return out
+}
+
 func (e *LiveEngine) tickSymbols(ctx context.Context, candidates []string) error {
 
 	if len(candidates) == 0 {
 		return nil
 	}
 
+	if e.Backfill != nil {
+		if err := e.Backfill.WaitBackfill(ctx); err != nil {
+			return err
+		}
+	}
+
 	start := time.Now()
 
-	input, err := e.sense(ctx, candidates)
+	candidates = e.sessionCandidates(ctx, candidates, start)
+	candidates = e.gateCandidates(ctx, candidates)
+	runs := e.runPipelines(ctx, candidates)
+	e.checkSupertrendFlips(ctx, candidates, runs)
+
+	input := e.snapshot(ctx, candidates)
+	e.screenSnapshot(&input)
+	if len(input.Candidates) == 0 {
+		return nil
+	}
+	e.completeContext(ctx, &input)
+	stale, err := e.applyStaleDataGate(&input)
 	if err != nil {
 		return err
 	}
 
+	expired := e.closeExpiringPositions(ctx, input.TimestampNow, input.Positions)
+
 	logger.Infof("AI Decision Loop Start candidates=%d symbols=%v positions=%d", len(input.Candidates), input.Candidates, len(input.Positions))
 
-	res, err := e.Decider.Decide(ctx, input)
+	res, err := e.decide(ctx, input)
 	if err != nil {
 		return err
 	}
@@ -242,9 +447,12 @@ func (e *LiveEngine) tickSymbols(ctx context.Context, candidates []string) error
 		return nil
 	}
 
-	prepared := e.prepareDecisions(res.Decisions, len(input.Positions) > 0)
+	prepared := e.prepareDecisions(dropSymbols(res.Decisions, expired), len(input.Positions) > 0)
+	prepared = dropStaleDecisions(prepared, stale)
+	stampEntryReferences(prepared, input)
 
 	accepted := e.executeDecisions(ctx, prepared, traceID)
+	e.recordEntrySignals(ctx, traceID, accepted, input.Analysis)
 
 	e.notifyMetaSummary(res)
 
@@ -263,7 +471,7 @@ func (e *LiveEngine) RunCycle(ctx context.Context, symbols []string) error {
 	if err != nil {
 		return fmt.Errorf("sense failed: %w", err)
 	}
-	result, err := e.Decider.Decide(ctx, input)
+	result, err := e.decide(ctx, input)
 	if err != nil {
 		return fmt.Errorf("decide failed: %w", err)
 	}
@@ -314,6 +522,7 @@ func (e *LiveEngine) executeDecisions(ctx context.Context, decisions []decision.
 	}
 	accepted := make([]decision.Decision, 0, len(decisions))
 	newOpens := 0
+	gates := &entryGates{}
 
 	for _, d := range decisions {
 		e.applyTradingDefaults(&d)
@@ -322,23 +531,55 @@ func (e *LiveEngine) executeDecisions(ctx context.Context, decisions []decision.
 			logger.Warnf("Decision invalid: %v | %+v", err, d)
 			continue
 		}
+		if d.Action == "open_long" || d.Action == "open_short" {
+			if err := decision.CheckOutputContract(d, e.outputContract(d.Symbol)); err != nil {
+				logger.Warnf("Decision violates output contract: %v | %+v", err, d)
+				continue
+			}
+		}
+
+		info, hasRules := e.symbolInfo(d.Symbol)
+		if hasRules {
+			applySymbolPriceRules(&d, info)
+		}
 
 		if d.Action == "update_exit_plan" {
 			if err := e.handleUpdateExitPlan(ctx, traceID, d); err != nil {
 				logger.Warnf("Update plan failed: %v", err)
 			} else {
 				accepted = append(accepted, d)
+				e.publishDecision(traceID, d, 0)
 			}
 			continue
 		}
+		if reason := e.checkTradingMode(&d); reason != "" {
+			e.rejectEntry(ctx, traceID, d, stageTradingMode, "trading_mode", reason)
+			continue
+		}
+		if reason := e.entryBlockReason(ctx, traceID, &d, gates); reason != "" {
+			continue
+		}
 
 		marketPrice := e.MktService.LatestPrice(ctx, d.Symbol)
+		if reason := e.checkEntryDrift(&d, marketPrice, time.Now()); reason != "" {
+			e.rejectEntry(ctx, traceID, d, stageEntryDrift, "entry_drift", reason)
+			continue
+		}
+		if hasRules && (d.Action == "open_long" || d.Action == "open_short") {
+			if err := applySymbolSizeRules(&d, info, marketPrice); err != nil {
+				logger.Warnf("交易规则不满足，跳过开仓: %v", err)
+				continue
+			}
+		}
 		if marketPrice > 0 {
 			if err := decision.ValidateWithPrice(&d, marketPrice, e.Config.Advanced.MinRiskReward); err != nil {
 				logger.Warnf("Decision RR check failed: %v", err)
 				continue
 			}
 		}
+		if reason := e.riskBlockReason(ctx, traceID, d, gates); reason != "" {
+			continue
+		}
 
 		if exec, ok := e.PosService.(interface {
 			ExecuteDecision(ctx context.Context, traceID string, d decision.Decision, price float64) error
@@ -353,6 +594,8 @@ func (e *LiveEngine) executeDecisions(ctx context.Context, decisions []decision.
 		}
 
 		accepted = append(accepted, d)
+		e.publishDecision(traceID, d, marketPrice)
+		gates.commitEntry(e, d)
 
 		if e.Notifier != nil && e.PosService != nil {
 			if d.Action == "open_long" || d.Action == "open_short" {
@@ -371,6 +614,43 @@ func (e *LiveEngine) executeDecisions(ctx context.Context, decisions []decision.
 	return accepted
 }
 
+// riskExposure 在本轮第一笔开仓前读取持仓构建风控敞口；读取失败返回 nil，本轮开仓全部拒绝。
+func (e *LiveEngine) riskExposure(ctx context.Context) *risk.Exposure {
+	if e.PosService == nil {
+		return nil
+	}
+	positions, err := e.PosService.ListPositions(ctx)
+	if err != nil {
+		logger.Warnf("风控读取持仓失败: %v", err)
+		return nil
+	}
+	return e.Risk.Snapshot(ctx, positions, time.Now())
+}
+
+// correlationPositions 读取当前持仓供相关性闸门比较；读取失败时放行本轮开仓。
+func (e *LiveEngine) correlationPositions(ctx context.Context) []decision.PositionSnapshot {
+	if e.PosService == nil {
+		return nil
+	}
+	positions, err := e.PosService.ListPositions(ctx)
+	if err != nil {
+		logger.Warnf("相关性闸门读取持仓失败，跳过校验: %v", err)
+		return nil
+	}
+	return positions
+}
+
+func (e *LiveEngine) outputContract(symbol string) decision.OutputContract {
+	if e.ProfileMgr == nil {
+		return decision.OutputContract{}
+	}
+	rt, ok := e.ProfileMgr.Resolve(symbol)
+	if !ok {
+		return decision.OutputContract{}
+	}
+	return prompt.OutputContractFor(rt)
+}
+
 func (e *LiveEngine) applyTradingDefaults(d *decision.Decision) {
 	if d.Action != "open_long" && d.Action != "open_short" {
 		return
@@ -401,6 +681,13 @@ func (e *LiveEngine) handleUpdateExitPlan(ctx context.Context, traceID string, d
 }
 
 func (e *LiveEngine) sense(ctx context.Context, symbols []string) (decision.Context, error) {
+	input := e.snapshot(ctx, symbols)
+	e.completeContext(ctx, &input)
+	return input, nil
+}
+
+// snapshot 采集账户、持仓、K 线分析与最新价；前置筛选直接作用于这份快照。
+func (e *LiveEngine) snapshot(ctx context.Context, symbols []string) decision.Context {
 	acct, err := e.PosService.GetAccountSnapshot(ctx)
 	if err != nil {
 		logger.Warnf("GetAccountSnapshot failed: %v", err)
@@ -414,7 +701,9 @@ func (e *LiveEngine) sense(ctx context.Context, symbols []string) (decision.Cont
 	if err != nil {
 		logger.Warnf("GetAnalysisContexts failed: %v", err)
 	}
-	e.MktService.CaptureIndicators(analysis)
+	if !decision.IsDryRun(ctx) {
+		e.MktService.CaptureIndicators(analysis)
+	}
 	market := make(map[string]decision.MarketData)
 	for _, sym := range symbols {
 		symbol := strings.ToUpper(strings.TrimSpace(sym))
@@ -427,7 +716,7 @@ func (e *LiveEngine) sense(ctx context.Context, symbols []string) (decision.Cont
 		}
 		market[symbol] = decision.MarketData{Symbol: symbol, Price: price}
 	}
-	input := decision.Context{
+	return decision.Context{
 		RunID:        uuid.NewString(),
 		TimestampNow: time.Now().UTC(),
 		Candidates:   symbols,
@@ -436,26 +725,30 @@ func (e *LiveEngine) sense(ctx context.Context, symbols []string) (decision.Cont
 		Analysis:     analysis,
 		Market:       market,
 	}
-	input.DataAgeSec, input.HardFlags = computeDataAgeSec(input.TimestampNow, analysis)
-	input.Directives = e.buildProfileDirectives(symbols)
+}
+
+// completeContext 基于（筛选后的）候选补齐数据时效、交割天数、profile 指令与提示词。
+func (e *LiveEngine) completeContext(ctx context.Context, input *decision.Context) {
+	symbols := input.Candidates
+	input.DataAgeSec, input.HardFlags = computeDataAgeSec(input.TimestampNow, input.Analysis)
+	input.DaysToExpiry = e.daysToExpiry(input.TimestampNow, symbols, input.Positions)
+	input.Directives = e.buildProfileDirectives(ctx, symbols)
 	if e.ProfileMgr != nil && e.PromptStrategy != nil {
 		activeProfiles := make(map[string]*profile.Runtime)
 		allProfiles := make([]*profile.Runtime, 0)
 		for _, sym := range symbols {
 			s := strings.ToUpper(strings.TrimSpace(sym))
-			rt, ok := e.ProfileMgr.Resolve(s)
+			rt, ok := e.ProfileMgr.ResolveContext(ctx, s)
 			if !ok || rt == nil {
 				continue
 			}
 			activeProfiles[rt.Definition.Name] = rt
 			allProfiles = append(allProfiles, rt)
 		}
-		if err := e.PromptStrategy.Build(&input, activeProfiles, nil, allProfiles); err != nil {
+		if err := e.PromptStrategy.Build(input, activeProfiles, nil, allProfiles); err != nil {
 			logger.Warnf("PromptStrategy.Build failed: %v", err)
 		}
 	}
-
-	return input, nil
 }
 
 func computeDataAgeSec(now time.Time, ctxs []decision.AnalysisContext) (map[string]int64, decision.HardFlags) {
@@ -464,22 +757,7 @@ func computeDataAgeSec(now time.Time, ctxs []decision.AnalysisContext) (map[stri
 	}
 	var latest int64
 	for _, ac := range ctxs {
-		raw := strings.TrimSpace(ac.KlineJSON)
-		if raw == "" {
-			continue
-		}
-		var candles []market.Candle
-		if err := json.Unmarshal([]byte(raw), &candles); err != nil {
-			continue
-		}
-		if len(candles) == 0 {
-			continue
-		}
-		ts := candles[len(candles)-1].CloseTime
-		if ts <= 0 {
-			continue
-		}
-		if ts > latest {
+		if ts := latestCloseTime(ac.KlineJSON); ts > latest {
 			latest = ts
 		}
 	}
@@ -515,14 +793,14 @@ func (e *LiveEngine) execute(ctx context.Context, traceID string, d decision.Dec
 	return fmt.Errorf("PositionService does not support execution")
 }
 
-func (e *LiveEngine) buildProfileDirectives(symbols []string) map[string]decision.ProfileDirective {
+func (e *LiveEngine) buildProfileDirectives(ctx context.Context, symbols []string) map[string]decision.ProfileDirective {
 	if e.ProfileMgr == nil {
 		return nil
 	}
 	directives := make(map[string]decision.ProfileDirective)
 	for _, sym := range symbols {
 		s := strings.ToUpper(strings.TrimSpace(sym))
-		rt, ok := e.ProfileMgr.Resolve(s)
+		rt, ok := e.ProfileMgr.ResolveContext(ctx, s)
 		if !ok || rt == nil {
 			continue
 		}
@@ -286,12 +286,34 @@ func (e *LiveEngine) buildOpenSections(d decision.Decision, entryPrice, rrVal fl
 		sections = append(sections, notifier.MessageSection{Title: "策略", Lines: planLines})
 		logger.Infof("策略详情：\n%s", plan)
 	}
+	if lines := buildRationaleLines(d.Rationale, entryPrice); len(lines) > 0 {
+		sections = append(sections, notifier.MessageSection{Title: "决策依据", Lines: lines})
+	}
 	if lines := buildReasonLines(d.Reasoning); len(lines) > 0 {
 		sections = append(sections, notifier.MessageSection{Title: "触发理由", Lines: lines})
 	}
 	return sections
 }
 
+// buildRationaleLines 渲染结构化理由；信心已在仓位段展示，这里只列驱动指标与失效价位。
+func buildRationaleLines(r *decision.Rationale, entryPrice float64) []string {
+	if r == nil {
+		return nil
+	}
+	lines := make([]string, 0, 2)
+	if len(r.Drivers) > 0 {
+		lines = append(lines, "驱动指标 "+strings.Join(r.Drivers, " / "))
+	}
+	if r.Invalidation > 0 {
+		line := fmt.Sprintf("失效价位 %.4f", r.Invalidation)
+		if entryPrice > 0 {
+			line += fmt.Sprintf("（距现价 %.2f%%）", (r.Invalidation-entryPrice)/entryPrice*100)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 func buildPriceLines(entryPrice, rrVal float64, validateIv string) []string {
 	lines := make([]string, 0, 3)
 	if entryPrice > 0 {
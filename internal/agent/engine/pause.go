@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// PauseSymbol 手动暂停 symbol 的新开仓；平仓与退出计划照常执行。
+func (e *LiveEngine) PauseSymbol(symbol string) {
+	sym := strings.ToUpper(strings.TrimSpace(symbol))
+	if e == nil || sym == "" {
+		return
+	}
+	e.pausedMu.Lock()
+	defer e.pausedMu.Unlock()
+	if e.paused == nil {
+		e.paused = make(map[string]time.Time)
+	}
+	if _, ok := e.paused[sym]; !ok {
+		e.paused[sym] = time.Now()
+	}
+}
+
+// ResumeSymbols 恢复指定 symbol 的开仓，未指定时恢复全部；返回实际恢复的 symbol。
+func (e *LiveEngine) ResumeSymbols(symbols ...string) []string {
+	if e == nil {
+		return nil
+	}
+	e.pausedMu.Lock()
+	defer e.pausedMu.Unlock()
+	var resumed []string
+	if len(symbols) == 0 {
+		for sym := range e.paused {
+			resumed = append(resumed, sym)
+		}
+		e.paused = nil
+	} else {
+		for _, raw := range symbols {
+			sym := strings.ToUpper(strings.TrimSpace(raw))
+			if _, ok := e.paused[sym]; ok {
+				delete(e.paused, sym)
+				resumed = append(resumed, sym)
+			}
+		}
+	}
+	sort.Strings(resumed)
+	return resumed
+}
+
+// PausedSymbols 返回当前暂停开仓的 symbol 及暂停时间。
+func (e *LiveEngine) PausedSymbols() map[string]time.Time {
+	if e == nil {
+		return nil
+	}
+	e.pausedMu.RLock()
+	defer e.pausedMu.RUnlock()
+	out := make(map[string]time.Time, len(e.paused))
+	for sym, ts := range e.paused {
+		out[sym] = ts
+	}
+	return out
+}
+
+func (e *LiveEngine) SymbolPaused(symbol string) bool {
+	if e == nil {
+		return false
+	}
+	e.pausedMu.RLock()
+	defer e.pausedMu.RUnlock()
+	_, ok := e.paused[strings.ToUpper(strings.TrimSpace(symbol))]
+	return ok
+}
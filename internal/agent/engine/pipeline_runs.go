@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"strings"
+
+	"brale/internal/logger"
+	"brale/internal/pipeline"
+	"brale/internal/profile"
+)
+
+// runPipelines 以 worker pool 并发执行本轮需要本地管道结果的 symbol（supertrend 提醒），
+// 结果按 symbol 返回供同一轮复用，并记录运行报告。
+func (e *LiveEngine) runPipelines(ctx context.Context, candidates []string) map[string]pipeline.JobRun {
+	if e == nil || e.ProfileMgr == nil || len(candidates) == 0 {
+		return nil
+	}
+	jobs := make([]pipeline.Job, 0, len(candidates))
+	for _, sym := range candidates {
+		symbol := strings.ToUpper(strings.TrimSpace(sym))
+		rt, ok := e.ProfileMgr.Resolve(symbol)
+		if !ok || rt == nil || rt.Pipeline == nil {
+			continue
+		}
+		if e.Notifier == nil || !supertrendAlertEnabled(rt) {
+			continue
+		}
+		jobs = append(jobs, pipeline.Job{Symbol: symbol, Profile: rt.Definition.Name, Pipeline: rt.Pipeline})
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+	concurrency := pipeline.DefaultConcurrency
+	if e.Config != nil && e.Config.Advanced.PipelineConcurrency > 0 {
+		concurrency = e.Config.Advanced.PipelineConcurrency
+	}
+	runs, report := pipeline.RunAll(ctx, jobs, concurrency)
+	e.pipelineRunMu.Lock()
+	e.lastPipelineRun = &report
+	e.pipelineRunMu.Unlock()
+
+	out := make(map[string]pipeline.JobRun, len(runs))
+	var slowest pipeline.SymbolRunReport
+	for i, run := range runs {
+		out[run.Symbol] = run
+		if item := report.Symbols[i]; item.DurationMs > slowest.DurationMs {
+			slowest = item
+		}
+		if run.Err != nil {
+			logger.Warnf("Pipeline: %s 执行失败 profile=%s duration=%s: %v", run.Symbol, run.Profile, run.Duration, run.Err)
+		}
+	}
+	logger.Infof("Pipeline: 并发执行 %d 个 symbol concurrency=%d failed=%d duration=%dms slowest=%s(%dms)",
+		report.Total, report.Concurrency, report.Failed, report.DurationMs, slowest.Symbol, slowest.DurationMs)
+	return out
+}
+
+// pipelineContext 优先复用本轮并发执行的结果；缺失或 profile 已切换时现场执行。
+func (e *LiveEngine) pipelineContext(ctx context.Context, runs map[string]pipeline.JobRun, symbol string, rt *profile.Runtime) (*pipeline.AnalysisContext, error) {
+	if run, ok := runs[symbol]; ok && run.Context != nil && run.Profile == rt.Definition.Name {
+		return run.Context, run.Err
+	}
+	ac := pipeline.NewContext(symbol)
+	ac.Profile = rt.Definition.Name
+	err := rt.Pipeline.Run(ctx, ac)
+	return ac, err
+}
+
+// PipelineRunReport 返回最近一轮并发管道执行的报告。
+func (e *LiveEngine) PipelineRunReport() (pipeline.RunReport, bool) {
+	if e == nil {
+		return pipeline.RunReport{}, false
+	}
+	e.pipelineRunMu.RLock()
+	defer e.pipelineRunMu.RUnlock()
+	if e.lastPipelineRun == nil {
+		return pipeline.RunReport{}, false
+	}
+	return *e.lastPipelineRun, true
+}
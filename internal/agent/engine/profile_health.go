@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/agent/health"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+)
+
+// ProfileHealth 返回各 profile 的决策健康度（连续失败轮次、聚合失败原因、是否已告警）。
+func (e *LiveEngine) ProfileHealth() []health.Status {
+	if e == nil {
+		return nil
+	}
+	return e.Health.Snapshot()
+}
+
+func (e *LiveEngine) profileOf(symbol string) string {
+	if e == nil || e.ProfileMgr == nil {
+		return ""
+	}
+	if rt, ok := e.ProfileMgr.Resolve(symbol); ok && rt != nil {
+		return rt.Definition.Name
+	}
+	return ""
+}
+
+func (e *LiveEngine) recordTickSuccess(symbol string) {
+	e.Health.RecordSuccess(e.profileOf(symbol))
+}
+
+func (e *LiveEngine) recordTickFailure(symbol, reason string) {
+	e.Health.RecordFailure(e.profileOf(symbol), symbol, reason)
+}
+
+func (e *LiveEngine) notifyProfileStale(st health.Status) {
+	lines := make([]string, 0, len(st.Reasons))
+	for _, r := range st.Reasons {
+		lines = append(lines, fmt.Sprintf("x%d [%s] %s", r.Count, strings.Join(r.Symbols, ","), r.Reason))
+	}
+	logger.Errorf("LiveEngine: profile=%s 连续 %d 轮无成功决策，失败原因: %s", st.Profile, st.ConsecutiveFailures, strings.Join(lines, " | "))
+	if e.Notifier == nil {
+		return
+	}
+	since := "从未成功"
+	if !st.LastSuccessAt.IsZero() {
+		since = st.LastSuccessAt.UTC().Format(time.RFC3339)
+	}
+	msg := notifier.StructuredMessage{
+		Icon:  "🚨",
+		Title: "Profile 决策停滞",
+		Sections: []notifier.MessageSection{
+			{Title: "概况", Lines: []string{
+				fmt.Sprintf("profile: %s", st.Profile),
+				fmt.Sprintf("连续失败: %d 轮", st.ConsecutiveFailures),
+				fmt.Sprintf("上次成功: %s", since),
+			}},
+			{Title: "近期失败原因", Lines: lines},
+		},
+		Footer:    "恢复成功决策前不会重复告警，可通过 /api/live/profiles/health 查看",
+		Timestamp: time.Now().UTC(),
+	}
+	if err := e.Notifier.SendStructured(msg); err != nil {
+		logger.Warnf("Telegram push failed (stale profile): %v", err)
+	}
+}
+
+func (e *LiveEngine) notifyProfileRecovered(st health.Status) {
+	logger.Infof("LiveEngine: profile=%s 已恢复成功决策（此前连续失败 %d 轮）", st.Profile, st.ConsecutiveFailures)
+	if e.Notifier == nil {
+		return
+	}
+	msg := notifier.StructuredMessage{
+		Icon:  "✅",
+		Title: "Profile 决策恢复",
+		Sections: []notifier.MessageSection{
+			{Lines: []string{
+				fmt.Sprintf("profile: %s", st.Profile),
+				fmt.Sprintf("此前连续失败: %d 轮", st.ConsecutiveFailures),
+			}},
+		},
+		Timestamp: time.Now().UTC(),
+	}
+	if err := e.Notifier.SendStructured(msg); err != nil {
+		logger.Warnf("Telegram push failed (profile recovered): %v", err)
+	}
+}
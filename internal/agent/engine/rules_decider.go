@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/analysis/rules"
+	"brale/internal/analysis/screen"
+	"brale/internal/config/loader"
+	"brale/internal/decision"
+	"brale/internal/logger"
+	"brale/internal/pipeline"
+	"brale/internal/profile"
+	"brale/internal/types"
+)
+
+// ruleSymbols 按 profile 的 rules.mode 拆分候选：replace 的 symbol 直接由规则决策，其余交给模型。
+func (e *LiveEngine) ruleSymbols(ctx context.Context, symbols []string, mode string) (matched, rest []string) {
+	for _, sym := range symbols {
+		if cfg, ok := e.rulesConfig(ctx, sym); ok && cfg.Mode == mode {
+			matched = append(matched, sym)
+			continue
+		}
+		rest = append(rest, sym)
+	}
+	return matched, rest
+}
+
+func (e *LiveEngine) rulesConfig(ctx context.Context, symbol string) (loader.RulesConfig, bool) {
+	if e == nil || e.ProfileMgr == nil {
+		return loader.RulesConfig{}, false
+	}
+	rt, ok := e.ProfileMgr.ResolveContext(ctx, strings.ToUpper(strings.TrimSpace(symbol)))
+	if !ok || rt == nil || rt.Pipeline == nil || !rt.Definition.Rules.Enabled {
+		return loader.RulesConfig{}, false
+	}
+	return rt.Definition.Rules, true
+}
+
+// ruleDecisions 对每个 symbol 确定性求值 profile 规则；未命中任何规则的 symbol 不产出决策（等同 hold）。
+func (e *LiveEngine) ruleDecisions(ctx context.Context, symbols []string, positions []types.PositionSnapshot) []decision.Decision {
+	held := make(map[string]types.PositionSnapshot, len(positions))
+	for _, p := range positions {
+		held[strings.ToUpper(strings.TrimSpace(p.Symbol))] = p
+	}
+	var out []decision.Decision
+	for _, sym := range symbols {
+		symbol := strings.ToUpper(strings.TrimSpace(sym))
+		rt, ok := e.ProfileMgr.ResolveContext(ctx, symbol)
+		if !ok || rt == nil || rt.Pipeline == nil {
+			continue
+		}
+		pos, hasPos := held[symbol]
+		d, ok, err := e.evaluateRules(ctx, symbol, rt, pos, hasPos)
+		if err != nil {
+			logger.Warnf("Rules: %s 求值失败 profile=%s: %v", symbol, rt.Definition.Name, err)
+			continue
+		}
+		if !ok {
+			logger.Debugf("Rules: %s 未命中规则 profile=%s", symbol, rt.Definition.Name)
+			continue
+		}
+		logger.Infof("Rules: %s %s profile=%s %s", symbol, d.Action, rt.Definition.Name, d.Reasoning)
+		out = append(out, d)
+	}
+	return out
+}
+
+func (e *LiveEngine) evaluateRules(ctx context.Context, symbol string, rt *profile.Runtime, pos types.PositionSnapshot, hasPos bool) (decision.Decision, bool, error) {
+	cfg := rt.Definition.Rules
+	intervals := rt.Definition.IntervalsLower()
+	primary := cfg.Interval
+	if primary == "" && len(intervals) > 0 {
+		primary = intervals[0]
+	}
+	ac := pipeline.NewContext(symbol)
+	ac.Profile = rt.Definition.Name
+	if err := rt.Pipeline.Run(ctx, ac); err != nil {
+		return decision.Decision{}, false, err
+	}
+	candles := ac.Candles(primary)
+	if len(candles) == 0 {
+		return decision.Decision{}, false, fmt.Errorf("主周期 %s 无 K 线", primary)
+	}
+
+	env := rules.Env{}
+	features := ac.Features()
+	scoring := screen.DivergenceScoring(rt.Definition.DivergenceScoring)
+	for _, iv := range intervals {
+		if cs := ac.Candles(iv); len(cs) > 0 {
+			env.AddSummary(iv, screen.SummarizeWith(cs, screen.TrendFromFeatures(features, iv), scoring), iv == primary)
+		}
+	}
+	env.AddFeatures(features, primary)
+	env["score"] = screen.CompositeFromCandles(intervals, ac.Candles, rt.Definition.Composite.Weights, scoring).Score
+	atr := 0.0
+	if series, err := indicator.ComputeATRSeries(candles, 14); err == nil && len(series) > 0 {
+		atr = series[len(series)-1]
+		env["atr"] = atr
+	}
+	if hasPos {
+		env.SetPosition(pos.Side, pos.UnrealizedPnPct)
+	} else {
+		env.SetPosition("", 0)
+	}
+
+	d := decision.Decision{Symbol: symbol, Profile: rt.Definition.Name, ContextTag: "rules"}
+	if hasPos {
+		exitExpr, action := cfg.LongExit, "close_long"
+		if strings.EqualFold(strings.TrimSpace(pos.Side), "short") {
+			exitExpr, action = cfg.ShortExit, "close_short"
+		}
+		hit, matched, err := matchRule(exitExpr, cfg.Vars, env)
+		if err != nil || !hit {
+			return d, false, err
+		}
+		d.Action = action
+		d.CloseRatio = 1
+		d.Reasoning = e.ruleReasoning(symbol, matched)
+		return d, true, nil
+	}
+
+	for _, entry := range []struct{ expr, action string }{
+		{cfg.LongEntry, "open_long"},
+		{cfg.ShortEntry, "open_short"},
+	} {
+		hit, matched, err := matchRule(entry.expr, cfg.Vars, env)
+		if err != nil {
+			return d, false, err
+		}
+		if !hit {
+			continue
+		}
+		price := e.MktService.LatestPrice(ctx, symbol)
+		if price <= 0 {
+			price = candles[len(candles)-1].Close
+		}
+		if atr <= 0 || price <= 0 {
+			return d, false, fmt.Errorf("ATR/价格不可用，无法生成止盈止损")
+		}
+		d.Action = entry.action
+		d.Leverage = cfg.Leverage
+		d.PositionSizeUSD = cfg.PositionSizeUSD
+		if entry.action == "open_long" {
+			d.StopLoss = price - cfg.StopATR*atr
+			d.TakeProfit = price + cfg.TakeProfitATR*atr
+		} else {
+			d.StopLoss = price + cfg.StopATR*atr
+			d.TakeProfit = price - cfg.TakeProfitATR*atr
+		}
+		if d.StopLoss <= 0 || d.TakeProfit <= 0 {
+			return d, false, fmt.Errorf("ATR 倍数过大，止盈止损越界 sl=%.6f tp=%.6f", d.StopLoss, d.TakeProfit)
+		}
+		d.ExitPlan = ruleExitPlan(d.TakeProfit, d.StopLoss)
+		d.Reasoning = e.ruleReasoning(symbol, matched)
+		return d, true, nil
+	}
+	return d, false, nil
+}
+
+func matchRule(src string, vars map[string]float64, env rules.Env) (bool, []string, error) {
+	expr, err := rules.Compile(src, vars)
+	if err != nil || expr.Empty() {
+		return false, nil, err
+	}
+	hit, matched := expr.Match(env)
+	return hit, matched, nil
+}
+
+// ruleReasoning 按 profile 输出语言与长度限制生成理由，保证规则决策同样通过输出契约校验。
+func (e *LiveEngine) ruleReasoning(symbol string, matched []string) string {
+	contract := e.outputContract(symbol)
+	reasoning := "规则命中: " + strings.Join(matched, " AND ")
+	if strings.EqualFold(contract.Language, "en") {
+		reasoning = "rules matched: " + strings.Join(matched, " AND ")
+	}
+	if limit := contract.ReasoningMaxChars; limit > 0 {
+		if runes := []rune(reasoning); len(runes) > limit {
+			reasoning = string(runes[:limit])
+		}
+	}
+	return reasoning
+}
+
+// ruleExitPlan 生成 plan_combo_main 的单段止盈 + 单段止损。
+func ruleExitPlan(takeProfit, stopLoss float64) *decision.ExitPlanSpec {
+	single := func(component, handler string, target float64) map[string]any {
+		return map[string]any{
+			"component": component,
+			"handler":   handler,
+			"params": map[string]any{
+				"tiers": []any{map[string]any{"target_price": target, "ratio": 1.0}},
+			},
+		}
+	}
+	return &decision.ExitPlanSpec{
+		ID: "plan_combo_main",
+		Params: map[string]any{
+			"children": []any{
+				single("tp_single", "tier_take_profit", takeProfit),
+				single("sl_single", "tier_stop_loss", stopLoss),
+			},
+		},
+	}
+}
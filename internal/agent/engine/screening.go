@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"strings"
+
+	"brale/internal/analysis/screen"
+	"brale/internal/decision"
+	"brale/internal/logger"
+	"brale/internal/market"
+	"brale/internal/profile"
+)
+
+// screenSnapshot 在调用 LLM 前按 profile.screening 规则过滤候选，直接复用 sense 快照中的 K 线，
+// 不再额外执行管道；持仓中的 symbol 始终放行。规则在 profile 加载时已编译。
+func (e *LiveEngine) screenSnapshot(input *decision.Context) {
+	if e == nil || e.ProfileMgr == nil || input == nil || len(input.Candidates) == 0 {
+		return
+	}
+	bySymbol := make(map[string][]decision.AnalysisContext)
+	for _, ac := range input.Analysis {
+		key := strings.ToUpper(strings.TrimSpace(ac.Symbol))
+		bySymbol[key] = append(bySymbol[key], ac)
+	}
+	held := make(map[string]bool, len(input.Positions))
+	for _, p := range input.Positions {
+		held[strings.ToUpper(strings.TrimSpace(p.Symbol))] = true
+	}
+	keep := make([]string, 0, len(input.Candidates))
+	for _, sym := range input.Candidates {
+		symbol := strings.ToUpper(strings.TrimSpace(sym))
+		rt, ok := e.ProfileMgr.Resolve(symbol)
+		if !ok || rt == nil || !rt.Definition.Screening.Enabled {
+			keep = append(keep, sym)
+			continue
+		}
+		pass, reasons := e.evaluateScreening(symbol, rt, bySymbol[symbol])
+		if pass || held[symbol] {
+			e.Screening.RecordPass()
+			keep = append(keep, sym)
+			continue
+		}
+		e.Screening.RecordReject(symbol, reasons)
+		logger.Infof("Screening: %s 未通过前置筛选，跳过 LLM profile=%s reasons=%v", symbol, rt.Definition.Name, reasons)
+	}
+	if len(keep) < len(input.Candidates) {
+		retainSymbols(input, keep)
+	}
+}
+
+// evaluateScreening 以快照中筛选周期的 K 线汇总指标；该周期缺数据时放行。
+func (e *LiveEngine) evaluateScreening(symbol string, rt *profile.Runtime, analysis []decision.AnalysisContext) (bool, []string) {
+	cfg := rt.Definition.Screening
+	gate := cfg.Gate()
+	if len(gate.Rules) == 0 {
+		return true, nil
+	}
+	interval := cfg.Interval
+	if interval == "" {
+		if ivs := rt.Definition.IntervalsLower(); len(ivs) > 0 {
+			interval = ivs[0]
+		}
+	}
+	series := make(map[string][]market.Candle, len(analysis))
+	var composite *screen.Composite
+	for _, ac := range analysis {
+		series[strings.ToLower(ac.Interval)] = decodeCandles(ac.KlineJSON)
+		if ac.Composite != nil {
+			composite = ac.Composite
+		}
+	}
+	candles := series[interval]
+	if len(candles) == 0 {
+		return true, nil
+	}
+	scoring := screen.DivergenceScoring(rt.Definition.DivergenceScoring)
+	summary := screen.SummarizeWith(candles, "", scoring)
+	if composite != nil {
+		summary.Score = composite.Score
+	} else {
+		lookup := func(iv string) []market.Candle { return series[iv] }
+		summary.Score = screen.CompositeFromCandles(rt.Definition.IntervalsLower(), lookup, rt.Definition.Composite.Weights, scoring).Score
+	}
+	e.publishDivergence(symbol, rt.Definition.Name, interval, summary)
+	return gate.Evaluate(summary)
+}
+
+// retainSymbols 把快照收窄到 keep 中的 symbol。
+func retainSymbols(input *decision.Context, keep []string) {
+	allowed := make(map[string]bool, len(keep))
+	for _, sym := range keep {
+		allowed[strings.ToUpper(strings.TrimSpace(sym))] = true
+	}
+	input.Candidates = keep
+	analysis := input.Analysis[:0]
+	for _, ac := range input.Analysis {
+		if allowed[strings.ToUpper(strings.TrimSpace(ac.Symbol))] {
+			analysis = append(analysis, ac)
+		}
+	}
+	input.Analysis = analysis
+	for sym := range input.Market {
+		if !allowed[sym] {
+			delete(input.Market, sym)
+		}
+	}
+}
+
+func (e *LiveEngine) heldSymbols(ctx context.Context) map[string]bool {
+	held := make(map[string]bool)
+	if e.PosService == nil {
+		return held
+	}
+	positions, err := e.PosService.ListPositions(ctx)
+	if err != nil {
+		logger.Warnf("Screening: ListPositions failed: %v", err)
+		return held
+	}
+	for _, p := range positions {
+		held[strings.ToUpper(strings.TrimSpace(p.Symbol))] = true
+	}
+	return held
+}
+
+type indicatorCacheReporter interface {
+	IndicatorCacheStats() decision.IndicatorCacheStats
+}
+
+// IndicatorCacheStats 返回行情服务的指标缓存命中统计；行情服务未启用缓存时返回 false。
+func (e *LiveEngine) IndicatorCacheStats() (decision.IndicatorCacheStats, bool) {
+	if e == nil {
+		return decision.IndicatorCacheStats{}, false
+	}
+	provider, ok := e.MktService.(indicatorCacheReporter)
+	if !ok {
+		return decision.IndicatorCacheStats{}, false
+	}
+	return provider.IndicatorCacheStats(), true
+}
+
+// ScreeningStats 返回前置筛选累计计数。
+func (e *LiveEngine) ScreeningStats() screen.Stats {
+	if e == nil {
+		return screen.Stats{}
+	}
+	return e.Screening.Snapshot()
+}
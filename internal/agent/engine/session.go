@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/logger"
+)
+
+// sessionCandidates 按 profile.session 剔除交易时段外的 symbol；持仓中的 symbol 始终放行。
+// 被跳过的 symbol 按原因汇总，通过 Decider 的 SkipRecorder 写入决策日志。
+func (e *LiveEngine) sessionCandidates(ctx context.Context, candidates []string, now time.Time) []string {
+	if e == nil || e.ProfileMgr == nil || len(candidates) == 0 {
+		return candidates
+	}
+	var held map[string]bool
+	skipped := make(map[string][]string)
+	out := make([]string, 0, len(candidates))
+	for _, sym := range candidates {
+		symbol := strings.ToUpper(strings.TrimSpace(sym))
+		rt, ok := e.ProfileMgr.Resolve(symbol)
+		if !ok || rt == nil {
+			out = append(out, sym)
+			continue
+		}
+		allowed, reason := rt.Definition.Session.Check(now)
+		if allowed {
+			out = append(out, sym)
+			continue
+		}
+		if held == nil {
+			held = e.heldSymbols(ctx)
+		}
+		if held[symbol] {
+			out = append(out, sym)
+			continue
+		}
+		reason = "profile " + rt.Definition.Name + " " + reason
+		skipped[reason] = append(skipped[reason], symbol)
+	}
+	if len(skipped) == 0 {
+		return out
+	}
+	reasons := make([]string, 0, len(skipped))
+	for reason := range skipped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	recorder, _ := e.Decider.(decision.SkipRecorder)
+	for _, reason := range reasons {
+		logger.Infof("Session: 跳过 %v 的分析: %s", skipped[reason], reason)
+		if recorder != nil {
+			recorder.RecordSkipped(ctx, skipped[reason], "session: "+reason)
+		}
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"brale/internal/analysis/screen"
+	"brale/internal/decision"
+	"brale/internal/gateway/webhook"
+)
+
+// publishDecision 把已执行的决策推送给外部 webhook。
+func (e *LiveEngine) publishDecision(traceID string, d decision.Decision, price float64) {
+	if e == nil || e.Webhooks == nil {
+		return
+	}
+	e.Webhooks.Publish(webhook.Event{
+		Type:   webhook.EventDecision,
+		Symbol: d.Symbol,
+		Data: map[string]any{
+			"trace_id": traceID,
+			"price":    price,
+			"decision": d,
+		},
+	})
+}
+
+// publishDivergence 在前置筛选检测到背离时推送；同一 symbol 背离方向不变时不重复推送。
+func (e *LiveEngine) publishDivergence(symbol, profileName, interval string, sum screen.Summary) {
+	if e == nil || e.Webhooks == nil {
+		return
+	}
+	e.divergenceMu.Lock()
+	if e.lastDivergence == nil {
+		e.lastDivergence = make(map[string]string)
+	}
+	prev := e.lastDivergence[symbol]
+	e.lastDivergence[symbol] = sum.Divergence
+	e.divergenceMu.Unlock()
+	if sum.Divergence == "" || sum.Divergence == "none" || sum.Divergence == prev {
+		return
+	}
+	e.Webhooks.Publish(webhook.Event{
+		Type:   webhook.EventDivergence,
+		Symbol: symbol,
+		Data: map[string]any{
+			"profile":    profileName,
+			"interval":   interval,
+			"divergence": sum.Divergence,
+			"price":      sum.Price,
+			"rsi":        sum.RSI,
+			"trend":      sum.Trend,
+			"regime":     sum.Regime,
+		},
+	})
+}
@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	brcfg "brale/internal/config"
+	"brale/internal/decision"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/scheduler"
+)
+
+// staleInterval 为一个陈旧的 symbol 周期；Missing 表示本轮该周期没有可用 K 线。
+type staleInterval struct {
+	Symbol   string
+	Interval string
+	Age      time.Duration
+	MaxAge   time.Duration
+	Missing  bool
+}
+
+func (s staleInterval) String() string {
+	if s.Missing {
+		return fmt.Sprintf("%s %s 无 K 线", s.Symbol, s.Interval)
+	}
+	return fmt.Sprintf("%s %s 最新收盘距今 %s（阈值 %s）", s.Symbol, s.Interval, s.Age.Round(time.Second), s.MaxAge.Round(time.Second))
+}
+
+func (e *LiveEngine) staleDataConfig() (brcfg.StaleDataConfig, bool) {
+	if e == nil || e.Config == nil || !e.Config.AI.StaleData.Enabled {
+		return brcfg.StaleDataConfig{}, false
+	}
+	return e.Config.AI.StaleData, true
+}
+
+// checkStaleData 检查每个候选 symbol 所属 profile 的各周期：最新收盘 K 线距 input.TimestampNow 超过阈值或缺失即为陈旧。
+func (e *LiveEngine) checkStaleData(input decision.Context) []staleInterval {
+	cfg, ok := e.staleDataConfig()
+	if !ok {
+		return nil
+	}
+	now := input.TimestampNow
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	latest := make(map[string]int64)
+	for _, ac := range input.Analysis {
+		key := strings.ToUpper(strings.TrimSpace(ac.Symbol)) + "/" + strings.ToLower(strings.TrimSpace(ac.Interval))
+		if ts := latestCloseTime(ac.KlineJSON); ts > latest[key] {
+			latest[key] = ts
+		}
+	}
+	var out []staleInterval
+	for _, raw := range input.Candidates {
+		sym := strings.ToUpper(strings.TrimSpace(raw))
+		if sym == "" {
+			continue
+		}
+		for _, iv := range e.requiredIntervals(sym, input.Analysis) {
+			dur, ok := scheduler.ParseIntervalDuration(iv)
+			if !ok {
+				continue
+			}
+			item := staleInterval{Symbol: sym, Interval: iv, MaxAge: cfg.MaxAge(iv, dur)}
+			ts := latest[sym+"/"+iv]
+			if ts <= 0 {
+				item.Missing = true
+				out = append(out, item)
+				continue
+			}
+			item.Age = max(now.Sub(time.UnixMilli(ts)), 0)
+			if item.Age > item.MaxAge {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// requiredIntervals 返回 symbol 所属 profile 声明的周期；未匹配到 profile 时退回本轮实际拿到的周期。
+func (e *LiveEngine) requiredIntervals(symbol string, analysis []decision.AnalysisContext) []string {
+	if e.ProfileMgr != nil {
+		if rt, ok := e.ProfileMgr.Resolve(symbol); ok && rt != nil {
+			if ivs := rt.Definition.IntervalsLower(); len(ivs) > 0 {
+				return ivs
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for _, ac := range analysis {
+		iv := strings.ToLower(strings.TrimSpace(ac.Interval))
+		if iv == "" || seen[iv] || !strings.EqualFold(strings.TrimSpace(ac.Symbol), symbol) {
+			continue
+		}
+		seen[iv] = true
+		out = append(out, iv)
+	}
+	return out
+}
+
+// applyStaleDataGate 为决策前的行情新鲜度闸门：abort 模式下存在陈旧周期即返回错误中止本轮；
+// degrade 模式下标记 data_stale_flag 并返回陈旧 symbol 及原因，供执行阶段拦截。
+func (e *LiveEngine) applyStaleDataGate(input *decision.Context) (map[string]string, error) {
+	cfg, ok := e.staleDataConfig()
+	if !ok || input == nil {
+		return nil, nil
+	}
+	stale := e.checkStaleData(*input)
+	e.trackStaleData(input.Candidates, stale)
+	if len(stale) == 0 {
+		return nil, nil
+	}
+	reasons := make(map[string]string)
+	for _, s := range stale {
+		if prev := reasons[s.Symbol]; prev != "" {
+			reasons[s.Symbol] = prev + "；" + s.String()
+		} else {
+			reasons[s.Symbol] = s.String()
+		}
+	}
+	if cfg.Mode == brcfg.StaleDataModeAbort {
+		return nil, fmt.Errorf("行情数据陈旧，中止本轮决策: %s", joinStale(stale))
+	}
+	input.HardFlags.DataStaleFlag = true
+	logger.Warnf("LiveEngine: 行情数据陈旧，本轮降级决策（拦截开仓与退出计划调整）: %s", joinStale(stale))
+	return reasons, nil
+}
+
+// dropStaleDecisions 拦截基于陈旧快照的开仓与退出计划调整；平仓照常执行，避免陈旧数据阻断离场。
+func dropStaleDecisions(items []decision.Decision, stale map[string]string) []decision.Decision {
+	if len(stale) == 0 || len(items) == 0 {
+		return items
+	}
+	out := items[:0]
+	for _, d := range items {
+		reason, ok := stale[strings.ToUpper(strings.TrimSpace(d.Symbol))]
+		if ok && (d.Action == "open_long" || d.Action == "open_short" || d.Action == "update_exit_plan") {
+			logger.Infof("行情数据陈旧，跳过 %s %s: %s", d.Symbol, d.Action, reason)
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// trackStaleData 记录各 symbol 周期的陈旧状态：新进入陈旧时推送告警，恢复时只记日志，持续陈旧不重复告警。
+func (e *LiveEngine) trackStaleData(candidates []string, stale []staleInterval) {
+	current := make(map[string]bool, len(stale))
+	for _, s := range stale {
+		current[s.Symbol+"/"+s.Interval] = true
+	}
+	e.staleMu.Lock()
+	if e.staleAlerted == nil {
+		e.staleAlerted = make(map[string]bool)
+	}
+	var fresh []staleInterval
+	for _, s := range stale {
+		key := s.Symbol + "/" + s.Interval
+		if !e.staleAlerted[key] {
+			e.staleAlerted[key] = true
+			fresh = append(fresh, s)
+		}
+	}
+	var recovered []string
+	for _, raw := range candidates {
+		prefix := strings.ToUpper(strings.TrimSpace(raw)) + "/"
+		for key := range e.staleAlerted {
+			if strings.HasPrefix(key, prefix) && !current[key] {
+				delete(e.staleAlerted, key)
+				recovered = append(recovered, key)
+			}
+		}
+	}
+	e.staleMu.Unlock()
+	if len(recovered) > 0 {
+		sort.Strings(recovered)
+		logger.Infof("LiveEngine: 行情数据已恢复新鲜: %s", strings.Join(recovered, ", "))
+	}
+	if len(fresh) > 0 {
+		e.notifyStaleData(fresh)
+	}
+}
+
+func (e *LiveEngine) notifyStaleData(stale []staleInterval) {
+	if e.Notifier == nil {
+		return
+	}
+	cfg, _ := e.staleDataConfig()
+	action := "本轮照常决策，但拦截陈旧 symbol 的开仓与退出计划调整"
+	if cfg.Mode == brcfg.StaleDataModeAbort {
+		action = "已中止本轮决策"
+	}
+	lines := make([]string, 0, len(stale))
+	for _, s := range stale {
+		lines = append(lines, s.String())
+	}
+	msg := notifier.StructuredMessage{
+		Icon:  "⏱️",
+		Title: "行情数据陈旧",
+		Sections: []notifier.MessageSection{
+			{Title: "陈旧周期", Lines: lines},
+			{Title: "处理", Lines: []string{action}},
+		},
+		Footer:    "数据恢复前不会重复告警",
+		Timestamp: time.Now().UTC(),
+	}
+	if err := e.Notifier.SendStructured(msg); err != nil {
+		logger.Warnf("Telegram push failed (stale data): %v", err)
+	}
+}
+
+func joinStale(stale []staleInterval) string {
+	parts := make([]string, 0, len(stale))
+	for _, s := range stale {
+		parts = append(parts, s.String())
+	}
+	return strings.Join(parts, "；")
+}
+
+// latestCloseTime 返回 K 线 JSON 中最后一根的收盘时间（毫秒），解析失败或为空时返回 0。
+func latestCloseTime(raw string) int64 {
+	candles := decodeCandles(raw)
+	if len(candles) == 0 {
+		return 0
+	}
+	return candles[len(candles)-1].CloseTime
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/pipeline"
+	"brale/internal/pkg/maputil"
+	"brale/internal/profile"
+)
+
+// checkSupertrendFlips 对配置了 supertrend 且 alert: true 的 profile 运行管道，方向翻转时推送提醒。
+// 同一 symbol/周期按翻转所在 K 线去重；首次观察只记录基线，除非最新一根恰好翻转。
+func (e *LiveEngine) checkSupertrendFlips(ctx context.Context, candidates []string, runs map[string]pipeline.JobRun) {
+	if e == nil || e.ProfileMgr == nil || e.Notifier == nil {
+		return
+	}
+	for _, sym := range candidates {
+		symbol := strings.ToUpper(strings.TrimSpace(sym))
+		rt, ok := e.ProfileMgr.Resolve(symbol)
+		if !ok || rt == nil || rt.Pipeline == nil || !supertrendAlertEnabled(rt) {
+			continue
+		}
+		ac, err := e.pipelineContext(ctx, runs, symbol, rt)
+		if err != nil {
+			logger.Warnf("Supertrend: %s 管道执行失败，跳过翻转检查: %v", symbol, err)
+			continue
+		}
+		for _, f := range ac.Features() {
+			if f.Key != "supertrend" || f.Metadata["alert"] != true {
+				continue
+			}
+			e.maybeNotifySupertrendFlip(symbol, rt.Definition.Name, f)
+		}
+	}
+}
+
+func (e *LiveEngine) maybeNotifySupertrendFlip(symbol, profileName string, f pipeline.Feature) {
+	interval := maputil.String(f.Metadata, "interval")
+	flipAt, _ := f.Metadata["flip_open_time"].(int64)
+	flipped, _ := f.Metadata["flipped"].(bool)
+	key := symbol + "@" + interval
+	e.supertrendMu.Lock()
+	if e.lastSupertrendFlip == nil {
+		e.lastSupertrendFlip = make(map[string]int64)
+	}
+	prev, seen := e.lastSupertrendFlip[key]
+	e.lastSupertrendFlip[key] = flipAt
+	e.supertrendMu.Unlock()
+	if seen && prev == flipAt {
+		return
+	}
+	if !seen && !flipped {
+		return
+	}
+	direction := maputil.String(f.Metadata, "direction")
+	band := maputil.Float(f.Metadata, "band")
+	bars := maputil.Int(f.Metadata, "bars_since_flip")
+	logger.Infof("Supertrend: %s %s 方向翻转为 %s band=%.4f profile=%s", symbol, interval, direction, band, profileName)
+	icon := "📈"
+	if direction == "down" {
+		icon = "📉"
+	}
+	msg := notifier.StructuredMessage{
+		Icon:  icon,
+		Title: "Supertrend 翻转",
+		Sections: []notifier.MessageSection{
+			{Lines: []string{
+				fmt.Sprintf("symbol: %s", symbol),
+				fmt.Sprintf("profile: %s", profileName),
+				fmt.Sprintf("周期: %s", strings.ToUpper(interval)),
+				fmt.Sprintf("方向: %s", direction),
+				fmt.Sprintf("轨道: %.4f", band),
+				fmt.Sprintf("距翻转: %d 根", bars),
+			}},
+		},
+		Timestamp: time.Now().UTC(),
+	}
+	if err := e.Notifier.SendStructured(msg); err != nil {
+		logger.Warnf("Telegram push failed (supertrend flip): %v", err)
+	}
+}
+
+func supertrendAlertEnabled(rt *profile.Runtime) bool {
+	for _, mw := range rt.Definition.Middlewares {
+		if strings.EqualFold(strings.TrimSpace(mw.Name), "supertrend") && strings.EqualFold(maputil.String(mw.Params, "alert"), "true") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"brale/internal/agent/gate"
+	"brale/internal/gateway/database"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/profile"
+)
+
+// LossHistory 提供 symbol 最近的已平仓交易，用于判断连续亏损冷却。
+type LossHistory interface {
+	ListRecentClosedTrades(ctx context.Context, symbol string, limit int) ([]database.ClosedTradeStat, error)
+}
+
+// BlacklistSymbol 手动把 symbol 加入黑名单，之后的决策轮次跳过其分析（持仓中的 symbol 除外）。
+func (e *LiveEngine) BlacklistSymbol(symbol, reason string) gate.Entry {
+	entry := e.Gate.Add(symbol, reason, time.Now().UTC())
+	logger.Infof("交易对已加入黑名单 %s: %s", entry.Symbol, entry.Reason)
+	return entry
+}
+
+// UnblacklistSymbol 从手动黑名单移除 symbol，返回是否存在。
+func (e *LiveEngine) UnblacklistSymbol(symbol string) bool {
+	ok := e.Gate.Remove(symbol)
+	if ok {
+		logger.Infof("交易对已移出黑名单 %s", strings.ToUpper(strings.TrimSpace(symbol)))
+	}
+	return ok
+}
+
+// SymbolGateStatus 返回手动黑名单与生效中的亏损冷却。
+func (e *LiveEngine) SymbolGateStatus() gate.Status {
+	return e.Gate.Snapshot(time.Now())
+}
+
+// gateCandidates 在分析前剔除黑名单、白名单外与亏损冷却中的 symbol；持仓中的 symbol 始终放行。
+func (e *LiveEngine) gateCandidates(ctx context.Context, candidates []string) []string {
+	if e == nil || e.Gate == nil || len(candidates) == 0 {
+		return candidates
+	}
+	var held map[string]bool
+	out := make([]string, 0, len(candidates))
+	for _, sym := range candidates {
+		symbol := strings.ToUpper(strings.TrimSpace(sym))
+		reason := e.symbolGateReason(ctx, symbol)
+		if reason == "" {
+			out = append(out, sym)
+			continue
+		}
+		if held == nil {
+			held = e.heldSymbols(ctx)
+		}
+		if held[symbol] {
+			out = append(out, sym)
+			continue
+		}
+		logger.Infof("SymbolGate: 跳过 %s 的分析: %s", symbol, reason)
+	}
+	return out
+}
+
+func (e *LiveEngine) symbolGateReason(ctx context.Context, symbol string) string {
+	if entry, ok := e.Gate.Blacklisted(symbol); ok {
+		if entry.Reason != "" {
+			return "手动黑名单（" + entry.Reason + "）"
+		}
+		return "手动黑名单"
+	}
+	if e.ProfileMgr == nil {
+		return ""
+	}
+	rt, ok := e.ProfileMgr.Resolve(symbol)
+	if !ok || rt == nil {
+		return ""
+	}
+	cfg := rt.Definition.SymbolGate
+	if slices.Contains(cfg.Blacklist, symbol) {
+		return fmt.Sprintf("profile %s 黑名单", rt.Definition.Name)
+	}
+	if len(cfg.Whitelist) > 0 && !slices.Contains(cfg.Whitelist, symbol) {
+		return fmt.Sprintf("不在 profile %s 白名单内", rt.Definition.Name)
+	}
+	if cd, ok := e.lossCooldown(ctx, symbol, rt); ok {
+		return fmt.Sprintf("连续亏损 %d 笔，冷却至 %s", cd.Losses, cd.Until.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// lossCooldown 检查最近 loss_streak 笔平仓是否全部亏损且最后一笔仍在冷却窗口内；新进入冷却时推送通知。
+func (e *LiveEngine) lossCooldown(ctx context.Context, symbol string, rt *profile.Runtime) (gate.Cooldown, bool) {
+	cfg := rt.Definition.SymbolGate
+	if cfg.LossStreak <= 0 || e.LossHistory == nil {
+		return gate.Cooldown{}, false
+	}
+	trades, err := e.LossHistory.ListRecentClosedTrades(ctx, symbol, cfg.LossStreak)
+	if err != nil {
+		logger.Warnf("SymbolGate: 查询 %s 平仓记录失败，按放行处理: %v", symbol, err)
+		return gate.Cooldown{}, false
+	}
+	if len(trades) < cfg.LossStreak {
+		e.Gate.ClearCooldown(symbol)
+		return gate.Cooldown{}, false
+	}
+	for _, tr := range trades {
+		if tr.PnLUSD >= 0 {
+			e.Gate.ClearCooldown(symbol)
+			return gate.Cooldown{}, false
+		}
+	}
+	lastLoss := trades[0].ClosedAt.UTC()
+	cd := gate.Cooldown{
+		Symbol:     symbol,
+		Profile:    rt.Definition.Name,
+		Losses:     len(trades),
+		LastLossAt: lastLoss,
+		Until:      lastLoss.Add(time.Duration(cfg.CooldownHours * float64(time.Hour))),
+	}
+	if !time.Now().Before(cd.Until) {
+		e.Gate.ClearCooldown(symbol)
+		return gate.Cooldown{}, false
+	}
+	if e.Gate.SetCooldown(cd) {
+		e.notifyCooldown(cd)
+	}
+	return cd, true
+}
+
+func (e *LiveEngine) notifyCooldown(cd gate.Cooldown) {
+	logger.Warnf("SymbolGate: %s 连续亏损 %d 笔，profile=%s 冷却至 %s", cd.Symbol, cd.Losses, cd.Profile, cd.Until.Format(time.RFC3339))
+	if e.Notifier == nil {
+		return
+	}
+	msg := notifier.StructuredMessage{
+		Icon:  "🧊",
+		Title: "交易对冷却",
+		Sections: []notifier.MessageSection{
+			{Lines: []string{
+				fmt.Sprintf("symbol: %s", cd.Symbol),
+				fmt.Sprintf("profile: %s", cd.Profile),
+				fmt.Sprintf("连续亏损: %d 笔", cd.Losses),
+				fmt.Sprintf("冷却至: %s", cd.Until.Format(time.RFC3339)),
+			}},
+		},
+		Footer:    "冷却期间跳过该交易对的分析，持仓管理不受影响",
+		Timestamp: time.Now().UTC(),
+	}
+	if err := e.Notifier.SendStructured(msg); err != nil {
+		logger.Warnf("Telegram push failed (symbol cooldown): %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+
+	"brale/internal/decision"
+	"brale/internal/logger"
+	"brale/internal/market"
+)
+
+// SymbolRules 返回 symbol 的交易所下单规则；未命中时执行前不做取整。
+type SymbolRules interface {
+	SymbolInfo(symbol string) (market.SymbolInfo, bool)
+}
+
+func (e *LiveEngine) symbolInfo(symbol string) (market.SymbolInfo, bool) {
+	if e == nil || e.SymbolRules == nil {
+		return market.SymbolInfo{}, false
+	}
+	return e.SymbolRules.SymbolInfo(symbol)
+}
+
+// applySymbolPriceRules 把止损/止盈、加仓价与退出计划中的 *_price 参数取整到价格步长；退出计划按副本改写。
+func applySymbolPriceRules(d *decision.Decision, info market.SymbolInfo) {
+	if d == nil || info.TickSize <= 0 {
+		return
+	}
+	d.StopLoss = info.RoundPrice(d.StopLoss)
+	d.TakeProfit = info.RoundPrice(d.TakeProfit)
+	if len(d.ScaleIn) > 0 {
+		levels := make([]decision.ScaleInLevel, len(d.ScaleIn))
+		for i, lvl := range d.ScaleIn {
+			lvl.Price = info.RoundPrice(lvl.Price)
+			levels[i] = lvl
+		}
+		d.ScaleIn = levels
+	}
+	if d.ExitPlan != nil {
+		plan := roundPlanPrices(*d.ExitPlan, info)
+		d.ExitPlan = &plan
+	}
+}
+
+func roundPlanPrices(spec decision.ExitPlanSpec, info market.SymbolInfo) decision.ExitPlanSpec {
+	spec.Params = roundPriceParams(spec.Params, info)
+	if len(spec.Components) > 0 {
+		comps := make([]decision.ExitPlanSpec, len(spec.Components))
+		for i, c := range spec.Components {
+			comps[i] = roundPlanPrices(c, info)
+		}
+		spec.Components = comps
+	}
+	return spec
+}
+
+// roundPriceParams 只改写键名以 _price 结尾的数值（含 tiers 等数组内的对象），百分比类参数保持原样。
+func roundPriceParams(params map[string]any, info market.SymbolInfo) map[string]any {
+	if params == nil {
+		return nil
+	}
+	out := make(map[string]any, len(params))
+	for k, v := range params {
+		switch val := v.(type) {
+		case map[string]any:
+			out[k] = roundPriceParams(val, info)
+		case []any:
+			items := make([]any, len(val))
+			for i, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					items[i] = roundPriceParams(m, info)
+				} else {
+					items[i] = item
+				}
+			}
+			out[k] = items
+		default:
+			if price, ok := paramFloat(v); ok && strings.HasSuffix(k, "_price") {
+				out[k] = info.RoundPrice(price)
+			} else {
+				out[k] = v
+			}
+		}
+	}
+	return out
+}
+
+func paramFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// applySymbolSizeRules 按最大杠杆封顶杠杆，按数量步长向下取整开仓数量并反推保证金；
+// 取整后低于最小数量或最小名义价值时返回错误，调用方放弃该开仓。
+func applySymbolSizeRules(d *decision.Decision, info market.SymbolInfo, price float64) error {
+	if d == nil || price <= 0 || d.PositionSizeUSD <= 0 {
+		return nil
+	}
+	stake, lev, err := info.SizeOpen(d.PositionSizeUSD, float64(d.Leverage), price)
+	if err != nil {
+		return err
+	}
+	if int(lev) < d.Leverage {
+		logger.Infof("交易规则: %s 杠杆 x%d 超过上限，调整为 x%.0f", d.Symbol, d.Leverage, lev)
+		d.Leverage = int(lev)
+	}
+	d.PositionSizeUSD = stake
+	return nil
+}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"fmt"
+
+	brcfg "brale/internal/config"
+	"brale/internal/decision"
+)
+
+// tradingMode 返回 symbol 生效的交易模式：profile 的 trading_mode 优先，其次 freqtrade.trading_mode。
+func (e *LiveEngine) tradingMode(symbol string) string {
+	override := ""
+	if e.ProfileMgr != nil {
+		if rt, ok := e.ProfileMgr.Resolve(symbol); ok && rt != nil {
+			override = rt.Definition.TradingMode
+		}
+	}
+	if e.Config == nil {
+		return brcfg.FreqtradeConfig{}.ResolveTradingMode(override)
+	}
+	return e.Config.Freqtrade.ResolveTradingMode(override)
+}
+
+// checkTradingMode 按交易模式约束开仓决策：spot 拒绝开空，并把杠杆固定为 1（仓位金额即现货名义价值）。
+func (e *LiveEngine) checkTradingMode(d *decision.Decision) string {
+	if d == nil || (d.Action != "open_long" && d.Action != "open_short") {
+		return ""
+	}
+	mode := e.tradingMode(d.Symbol)
+	if d.Action == "open_short" && !brcfg.TradingModeAllowsShort(mode) {
+		return fmt.Sprintf("%s 模式不支持开空", mode)
+	}
+	if !brcfg.TradingModeUsesLeverage(mode) {
+		d.Leverage = 1
+	}
+	return ""
+}
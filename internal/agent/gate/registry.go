@@ -0,0 +1,112 @@
+package gate
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry 为手动加入黑名单的 symbol。
+type Entry struct {
+	Symbol  string    `json:"symbol"`
+	Reason  string    `json:"reason,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Cooldown 为连续亏损触发的冷却：Until 之前跳过该 symbol 的分析。
+type Cooldown struct {
+	Symbol     string    `json:"symbol"`
+	Profile    string    `json:"profile"`
+	Losses     int       `json:"losses"`
+	LastLossAt time.Time `json:"last_loss_at"`
+	Until      time.Time `json:"until"`
+}
+
+// Status 为闸门当前状态，供 API 展示。
+type Status struct {
+	Blacklist []Entry    `json:"blacklist"`
+	Cooldowns []Cooldown `json:"cooldowns"`
+}
+
+// Registry 保存运行时黑名单与冷却状态（进程内，重启后由配置黑名单与成交记录重新推导）。
+type Registry struct {
+	mu        sync.Mutex
+	blacklist map[string]Entry
+	cooldowns map[string]Cooldown
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		blacklist: make(map[string]Entry),
+		cooldowns: make(map[string]Cooldown),
+	}
+}
+
+// Add 把 symbol 加入手动黑名单；已存在时更新原因。
+func (r *Registry) Add(symbol, reason string, now time.Time) Entry {
+	entry := Entry{Symbol: normalize(symbol), Reason: strings.TrimSpace(reason), AddedAt: now}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prev, ok := r.blacklist[entry.Symbol]; ok {
+		entry.AddedAt = prev.AddedAt
+	}
+	r.blacklist[entry.Symbol] = entry
+	return entry
+}
+
+// Remove 从手动黑名单移除 symbol，返回是否存在。
+func (r *Registry) Remove(symbol string) bool {
+	sym := normalize(symbol)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.blacklist[sym]
+	delete(r.blacklist, sym)
+	return ok
+}
+
+func (r *Registry) Blacklisted(symbol string) (Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.blacklist[normalize(symbol)]
+	return entry, ok
+}
+
+// SetCooldown 记录冷却，返回是否为新的冷却（同一 symbol 的 Until 变化视为新冷却），调用方据此只通知一次。
+func (r *Registry) SetCooldown(c Cooldown) bool {
+	c.Symbol = normalize(c.Symbol)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.cooldowns[c.Symbol]
+	r.cooldowns[c.Symbol] = c
+	return !ok || !prev.Until.Equal(c.Until)
+}
+
+// ClearCooldown 在冷却到期或条件不再满足时移除记录。
+func (r *Registry) ClearCooldown(symbol string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cooldowns, normalize(symbol))
+}
+
+// Snapshot 返回黑名单与未过期的冷却，按 symbol 排序。
+func (r *Registry) Snapshot(now time.Time) Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := Status{Blacklist: make([]Entry, 0, len(r.blacklist)), Cooldowns: make([]Cooldown, 0, len(r.cooldowns))}
+	for _, entry := range r.blacklist {
+		st.Blacklist = append(st.Blacklist, entry)
+	}
+	for _, c := range r.cooldowns {
+		if now.Before(c.Until) {
+			st.Cooldowns = append(st.Cooldowns, c)
+		}
+	}
+	sort.Slice(st.Blacklist, func(i, j int) bool { return st.Blacklist[i].Symbol < st.Blacklist[j].Symbol })
+	sort.Slice(st.Cooldowns, func(i, j int) bool { return st.Cooldowns[i].Symbol < st.Cooldowns[j].Symbol })
+	return st
+}
+
+func normalize(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,183 @@
+package health
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxReasonsPerProfile = 5
+	maxReasonLen         = 300
+)
+
+// FailureReason 为同一失败原因的聚合计数。
+type FailureReason struct {
+	Reason  string    `json:"reason"`
+	Symbols []string  `json:"symbols"`
+	Count   int       `json:"count"`
+	LastAt  time.Time `json:"last_at"`
+}
+
+// Status 为单个 profile 的决策健康度；Stale=true 表示已连续 threshold 轮无成功决策且告警未解除。
+type Status struct {
+	Profile             string          `json:"profile"`
+	ConsecutiveFailures int             `json:"consecutive_failures"`
+	LastSuccessAt       time.Time       `json:"last_success_at,omitempty"`
+	LastFailureAt       time.Time       `json:"last_failure_at,omitempty"`
+	Stale               bool            `json:"stale"`
+	AlertedAt           time.Time       `json:"alerted_at,omitempty"`
+	Reasons             []FailureReason `json:"reasons,omitempty"`
+}
+
+// Tracker 按 profile 统计连续失败的决策轮次（配置错误、数据缺失、模型全部失败等），
+// 达到阈值时触发一次告警并保持 stale 状态，直到该 profile 再次成功决策后回调恢复。
+type Tracker struct {
+	threshold int
+	onStale   func(Status)
+	onRecover func(Status)
+
+	mu       sync.Mutex
+	profiles map[string]*Status
+}
+
+// NewTracker 创建跟踪器；threshold<=0 时只统计不告警。
+func NewTracker(threshold int, onStale, onRecover func(Status)) *Tracker {
+	return &Tracker{
+		threshold: threshold,
+		onStale:   onStale,
+		onRecover: onRecover,
+		profiles:  make(map[string]*Status),
+	}
+}
+
+func (t *Tracker) state(profile string) *Status {
+	st, ok := t.profiles[profile]
+	if !ok {
+		st = &Status{Profile: profile}
+		t.profiles[profile] = st
+	}
+	return st
+}
+
+// RecordSuccess 记录一次成功决策，清空失败计数；若此前处于 stale 则触发恢复回调。
+func (t *Tracker) RecordSuccess(profile string) {
+	if t == nil || strings.TrimSpace(profile) == "" {
+		return
+	}
+	t.mu.Lock()
+	st := t.state(profile)
+	recovered := st.Stale
+	var snap Status
+	if recovered {
+		snap = cloneStatus(st)
+	}
+	st.ConsecutiveFailures = 0
+	st.LastSuccessAt = time.Now()
+	st.Stale = false
+	st.AlertedAt = time.Time{}
+	st.Reasons = nil
+	t.mu.Unlock()
+	if recovered && t.onRecover != nil {
+		t.onRecover(snap)
+	}
+}
+
+// RecordFailure 记录一次失败轮次并聚合原因；首次达到阈值时触发告警回调。
+func (t *Tracker) RecordFailure(profile, symbol, reason string) {
+	if t == nil || strings.TrimSpace(profile) == "" {
+		return
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxReasonLen {
+		reason = reason[:maxReasonLen] + "..."
+	}
+	now := time.Now()
+	t.mu.Lock()
+	st := t.state(profile)
+	st.ConsecutiveFailures++
+	st.LastFailureAt = now
+	addReason(st, reason, strings.ToUpper(strings.TrimSpace(symbol)), now)
+	fire := t.threshold > 0 && !st.Stale && st.ConsecutiveFailures >= t.threshold
+	var snap Status
+	if fire {
+		st.Stale = true
+		st.AlertedAt = now
+		snap = cloneStatus(st)
+	}
+	t.mu.Unlock()
+	if fire && t.onStale != nil {
+		t.onStale(snap)
+	}
+}
+
+func addReason(st *Status, reason, symbol string, now time.Time) {
+	for i := range st.Reasons {
+		r := &st.Reasons[i]
+		if r.Reason != reason {
+			continue
+		}
+		r.Count++
+		r.LastAt = now
+		if symbol != "" && !containsString(r.Symbols, symbol) {
+			r.Symbols = append(r.Symbols, symbol)
+		}
+		return
+	}
+	entry := FailureReason{Reason: reason, Count: 1, LastAt: now}
+	if symbol != "" {
+		entry.Symbols = []string{symbol}
+	}
+	st.Reasons = append(st.Reasons, entry)
+	if len(st.Reasons) > maxReasonsPerProfile {
+		// 淘汰最久未出现的原因
+		oldest := 0
+		for i := range st.Reasons {
+			if st.Reasons[i].LastAt.Before(st.Reasons[oldest].LastAt) {
+				oldest = i
+			}
+		}
+		st.Reasons = append(st.Reasons[:oldest], st.Reasons[oldest+1:]...)
+	}
+}
+
+// Snapshot 返回全部 profile 的健康度，stale 在前、其余按名称排序。
+func (t *Tracker) Snapshot() []Status {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	out := make([]Status, 0, len(t.profiles))
+	for _, st := range t.profiles {
+		out = append(out, cloneStatus(st))
+	}
+	t.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Stale != out[j].Stale {
+			return out[i].Stale
+		}
+		return out[i].Profile < out[j].Profile
+	})
+	return out
+}
+
+func cloneStatus(st *Status) Status {
+	out := *st
+	out.Reasons = make([]FailureReason, len(st.Reasons))
+	for i, r := range st.Reasons {
+		r.Symbols = append([]string(nil), r.Symbols...)
+		out.Reasons[i] = r
+	}
+	sort.Slice(out.Reasons, func(i, j int) bool { return out.Reasons[i].Count > out.Reasons[j].Count })
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
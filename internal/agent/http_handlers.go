@@ -6,9 +6,12 @@ import (
 	"strings"
 
 	"brale/internal/agent/interfaces"
+	cfgloader "brale/internal/config/loader"
 	"brale/internal/gateway/database"
 	"brale/internal/gateway/exchange"
 	"brale/internal/logger"
+	"brale/internal/store/archive"
+	"brale/internal/strategy"
 	livehttp "brale/internal/transport/http/live"
 )
 
@@ -115,10 +118,47 @@ func (s *LiveService) ManualOpenPosition(ctx context.Context, req exchange.Manua
 	if s == nil || s.execManager == nil {
 		return fmt.Errorf("freqtrade 执行器未启用")
 	}
-
+	if rt, ok := s.profileMgr.Resolve(req.Symbol); ok && rt != nil {
+		applyProfileTierRatios(&req, rt.Definition.ExitPlans.TierRatios.ForSide(req.Side))
+	}
 	return s.execManager.ManualOpenPosition(ctx, req)
 }
 
+// applyProfileTierRatios 在人工开仓只填了分段目标价、未填比例时，按 profile 的多/空默认比例补齐；
+// 目标段数少于默认段数时取前几段并按比例归一。已填写任一比例的一组保持原样。
+func applyProfileTierRatios(req *exchange.ManualOpenRequest, ratios []float64) {
+	fill := func(targets []float64, out []*float64) {
+		n := 0
+		for i, t := range targets {
+			if t <= 0 {
+				continue
+			}
+			if *out[i] > 0 {
+				return
+			}
+			n = i + 1
+		}
+		if n == 0 || n > len(ratios) {
+			return
+		}
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			if targets[i] > 0 {
+				sum += ratios[i]
+			}
+		}
+		for i := 0; i < n; i++ {
+			if targets[i] > 0 {
+				*out[i] = ratios[i] / sum
+			}
+		}
+	}
+	fill([]float64{req.Tier1Target, req.Tier2Target, req.Tier3Target},
+		[]*float64{&req.Tier1Ratio, &req.Tier2Ratio, &req.Tier3Ratio})
+	fill([]float64{req.SLTier1Target, req.SLTier2Target, req.SLTier3Target},
+		[]*float64{&req.SLTier1Ratio, &req.SLTier2Ratio, &req.SLTier3Ratio})
+}
+
 func (s *LiveService) AdjustPlan(ctx context.Context, req livehttp.PlanAdjustRequest) error {
 	if s == nil || s.planScheduler == nil {
 		return fmt.Errorf("plan scheduler 未初始化")
@@ -133,6 +173,66 @@ func (s *LiveService) AdjustPlan(ctx context.Context, req livehttp.PlanAdjustReq
 	return s.planScheduler.AdjustPlan(ctx, spec)
 }
 
+// CompleteTiers 先强制从 freqtrade 对账仓位，再以回报的剩余比例修正本地 tier 状态。
+func (s *LiveService) CompleteTiers(ctx context.Context, tradeID int, req livehttp.TierCompletionRequest) (livehttp.TierCompletionResult, error) {
+	res := livehttp.TierCompletionResult{TradeID: tradeID, RemainingRatio: -1}
+	if s == nil || s.planScheduler == nil {
+		return res, fmt.Errorf("plan scheduler 未初始化")
+	}
+	pos, err := s.RefreshFreqtradePosition(ctx, tradeID)
+	if err != nil {
+		if req.RemainingRatio == nil {
+			return res, fmt.Errorf("freqtrade 对账失败: %w", err)
+		}
+		logger.Warnf("tier 人工对账: freqtrade 刷新失败 trade=%d err=%v，使用请求中的 remaining_ratio", tradeID, err)
+	}
+	if pos != nil {
+		res.Amount = pos.Amount
+		res.InitialAmount = pos.InitialAmount
+		if pos.InitialAmount > 0 {
+			res.RemainingRatio = pos.Amount / pos.InitialAmount
+		}
+	}
+	if req.RemainingRatio != nil {
+		res.Overridden = res.RemainingRatio < 0 || *req.RemainingRatio != res.RemainingRatio
+		res.RemainingRatio = *req.RemainingRatio
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if res.Overridden {
+		reason = strings.TrimSpace(fmt.Sprintf("%s（人工指定剩余比例 %.4f，freqtrade amount=%.6f/%.6f）", reason, res.RemainingRatio, res.Amount, res.InitialAmount))
+	}
+	changes, err := s.planScheduler.CompleteTiers(ctx, interfaces.TierCompletionSpec{
+		TradeID:    tradeID,
+		PlanID:     strings.TrimSpace(req.PlanID),
+		Components: req.Components,
+		OpenRatio:  res.RemainingRatio,
+		Reason:     reason,
+		Source:     "operator:" + strings.TrimSpace(req.Operator),
+	})
+	for _, c := range changes {
+		res.Changes = append(res.Changes, livehttp.TierCompletionChange(c))
+	}
+	return res, err
+}
+
+// EditTiers 人工修改持仓 tier 价位与比例，止损段位沿用 freqtrade.min_stop_distance_pct 校验。
+func (s *LiveService) EditTiers(ctx context.Context, tradeID int, req livehttp.TierEditRequest) ([]interfaces.TierEditChange, error) {
+	if s == nil || s.planScheduler == nil {
+		return nil, fmt.Errorf("plan scheduler 未初始化")
+	}
+	spec := interfaces.TierEditSpec{
+		TradeID: tradeID,
+		PlanID:  strings.TrimSpace(req.PlanID),
+		Levels:  req.Levels,
+		Reason:  strings.TrimSpace(req.Reason),
+		Source:  "operator:" + strings.TrimSpace(req.Operator),
+	}
+	if s.cfg != nil {
+		spec.MinStopDistancePct = s.cfg.Freqtrade.MinStopDistancePct
+	}
+	return s.planScheduler.EditTiers(ctx, spec)
+}
+
 func (s *LiveService) ListStrategyInstances(ctx context.Context, tradeID int) ([]database.StrategyInstanceRecord, error) {
 	if s == nil {
 		return nil, fmt.Errorf("live service 未初始化")
@@ -157,3 +257,93 @@ func (s *LiveService) ListStrategyChangeLogs(ctx context.Context, tradeID int, l
 	}
 	return nil, fmt.Errorf("strategy log store 未启用")
 }
+
+func (s *LiveService) QueryArchive(ctx context.Context, table string, q archive.Query) ([]map[string]any, error) {
+	if s == nil || s.archiver == nil {
+		return nil, fmt.Errorf("归档未启用")
+	}
+	return s.archiver.Query(ctx, table, q)
+}
+
+func (s *LiveService) ListWebhookDeliveries(ctx context.Context, q database.WebhookDeliveryQuery) ([]database.WebhookDeliveryRecord, error) {
+	if s == nil || s.webhooks == nil {
+		return nil, fmt.Errorf("webhook 未启用")
+	}
+	return s.webhooks.Deliveries(ctx, q)
+}
+
+func (s *LiveService) AddAnnotation(ctx context.Context, rec database.AnnotationRecord) (database.AnnotationRecord, error) {
+	if s == nil || s.annotations == nil {
+		return rec, fmt.Errorf("备注存储未启用")
+	}
+	return s.annotations.AddAnnotation(ctx, rec)
+}
+
+func (s *LiveService) ListAnnotations(ctx context.Context, q database.AnnotationQuery) ([]database.AnnotationRecord, error) {
+	if s == nil || s.annotations == nil {
+		return nil, fmt.Errorf("备注存储未启用")
+	}
+	return s.annotations.ListAnnotations(ctx, q)
+}
+
+func (s *LiveService) DeleteAnnotation(ctx context.Context, id int64) error {
+	if s == nil || s.annotations == nil {
+		return fmt.Errorf("备注存储未启用")
+	}
+	return s.annotations.DeleteAnnotation(ctx, id)
+}
+
+func (s *LiveService) ListProfiles(includeDeleted bool) ([]cfgloader.ProfileEntry, error) {
+	if s == nil || s.profileLoader == nil {
+		return nil, fmt.Errorf("profile loader 未初始化")
+	}
+	return s.profileLoader.ListProfiles(includeDeleted)
+}
+
+func (s *LiveService) DeleteProfile(name string) error {
+	if s == nil || s.profileLoader == nil {
+		return fmt.Errorf("profile loader 未初始化")
+	}
+	return s.profileLoader.SoftDelete(name)
+}
+
+func (s *LiveService) RestoreProfile(name string) error {
+	if s == nil || s.profileLoader == nil {
+		return fmt.Errorf("profile loader 未初始化")
+	}
+	return s.profileLoader.Restore(name)
+}
+
+// ListPrompts 返回提示词目录下全部模板的版本信息。
+func (s *LiveService) ListPrompts() ([]strategy.PromptFile, error) {
+	if s == nil || s.prompts == nil {
+		return nil, fmt.Errorf("提示词管理未初始化")
+	}
+	return s.prompts.Files(), nil
+}
+
+func (s *LiveService) GetPrompt(name string) (strategy.PromptFile, error) {
+	if s == nil || s.prompts == nil {
+		return strategy.PromptFile{}, fmt.Errorf("提示词管理未初始化")
+	}
+	file, ok := s.prompts.File(strings.TrimSuffix(strings.TrimSpace(name), ".txt"))
+	if !ok {
+		return strategy.PromptFile{}, fmt.Errorf("提示词 %s 不存在", name)
+	}
+	return file, nil
+}
+
+// SavePrompt 写回提示词文件（保留 .bak 备份），引用它的 profile 随即热更新。
+func (s *LiveService) SavePrompt(name, content string) (strategy.PromptFile, error) {
+	if s == nil || s.prompts == nil {
+		return strategy.PromptFile{}, fmt.Errorf("提示词管理未初始化")
+	}
+	return s.prompts.Save(name, content)
+}
+
+func (s *LiveService) SetProfileTierRatios(name string, cfg cfgloader.TierRatioConfig) error {
+	if s == nil || s.profileLoader == nil {
+		return fmt.Errorf("profile loader 未初始化")
+	}
+	return s.profileLoader.SetTierRatios(name, cfg)
+}
@@ -49,3 +49,54 @@ type PlanAdjustSpec struct {
 	Params    map[string]any
 	Source    string
 }
+
+// TierCompletionSpec describes an operator-driven reconciliation of tier components
+// whose fills happened outside brale (e.g. a manual partial close in freqtrade).
+// Components lists tiers to force-complete; when empty, tiers are completed in order
+// until they cover 1-OpenRatio. OpenRatio < 0 means the exchange-side ratio is unknown.
+type TierCompletionSpec struct {
+	TradeID    int
+	PlanID     string
+	Components []string
+	OpenRatio  float64
+	Reason     string
+	Source     string
+}
+
+// TierCompletionChange is the resulting state of one reconciled tier component.
+type TierCompletionChange struct {
+	Component      string  `json:"component"`
+	Status         string  `json:"status"`
+	ExecutedRatio  float64 `json:"executed_ratio"`
+	RemainingRatio float64 `json:"remaining_ratio"`
+}
+
+// TierEditSpec describes an operator edit of open-position tier levels. Each level
+// targets one tier component (take-profit or stop-loss, e.g. "tp_tiers.tier1");
+// zero fields keep the current value. MinStopDistancePct mirrors the entry guard
+// and is enforced on stop-loss components.
+type TierEditSpec struct {
+	TradeID            int
+	PlanID             string
+	Levels             []TierEditLevel
+	MinStopDistancePct float64
+	Reason             string
+	Source             string
+}
+
+// TierEditLevel is the requested target price and/or ratio for one tier component.
+type TierEditLevel struct {
+	Component   string  `json:"component"`
+	TargetPrice float64 `json:"target_price"`
+	Ratio       float64 `json:"ratio"`
+}
+
+// TierEditChange records the old and new levels of one edited tier component.
+type TierEditChange struct {
+	Component      string  `json:"component"`
+	Mode           string  `json:"mode"`
+	OldTargetPrice float64 `json:"old_target_price"`
+	TargetPrice    float64 `json:"target_price"`
+	OldRatio       float64 `json:"old_ratio"`
+	Ratio          float64 `json:"ratio"`
+}
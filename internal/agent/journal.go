@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/attribution"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	symbolpkg "brale/internal/pkg/symbol"
+	livehttp "brale/internal/transport/http/live"
+)
+
+// TradeJournal 返回 [from, to) 内平仓交易的日志；q.Profile/q.Symbol 非空时只返回匹配的交易。
+// 滑点按方向换算为不利偏移：多头成交高于参考价、空头成交低于参考价为正。
+func (s *LiveService) TradeJournal(ctx context.Context, q livehttp.JournalQuery) ([]attribution.JournalEntry, error) {
+	if s == nil || s.decLogs == nil {
+		return nil, fmt.Errorf("决策日志未启用")
+	}
+	outcomes, err := s.decLogs.ListTradeOutcomes(ctx, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	wantSymbol := symbolpkg.Normalize(q.Symbol)
+	entries := make([]attribution.JournalEntry, 0, len(outcomes))
+	tradeIDs := make([]int, 0, len(outcomes))
+	for _, o := range outcomes {
+		if wantSymbol != "" && symbolpkg.Normalize(o.Symbol) != wantSymbol {
+			continue
+		}
+		recorded := ""
+		if o.Entry != nil {
+			recorded = o.Entry.Profile
+		}
+		e := attribution.JournalEntry{
+			TradeID:    o.TradeID,
+			Profile:    s.profileForSymbol(o.Symbol, recorded),
+			Symbol:     symbolpkg.Normalize(o.Symbol),
+			Side:       o.Side,
+			EntryPrice: o.EntryPrice,
+			ExitPrice:  o.ExitPrice,
+			Amount:     o.InitialAmount,
+			OpenedAt:   o.OpenedAt,
+			ClosedAt:   o.ClosedAt,
+			PnLUSD:     o.PnLUSD,
+			PnLRatio:   o.PnLRatio,
+			FeeUSD:     o.FeeUSD,
+		}
+		if q.Profile != "" && !strings.EqualFold(e.Profile, q.Profile) {
+			continue
+		}
+		entries = append(entries, e)
+		tradeIDs = append(tradeIDs, o.TradeID)
+	}
+	if len(tradeIDs) == 0 {
+		return entries, nil
+	}
+	extras, err := s.decLogs.TradeJournalExtras(ctx, tradeIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		e := &entries[i]
+		ex, ok := extras[e.TradeID]
+		if !ok {
+			continue
+		}
+		e.TPTiers, e.TPTiersHit, e.SLTiersHit, e.HitTiers = ex.TPTiers, ex.TPTiersHit, ex.SLTiersHit, ex.HitTiers
+		if ex.HasEntryDrift {
+			e.HasSlippage = true
+			e.EntrySlippagePct = ex.EntryDriftPct
+			if strings.EqualFold(e.Side, "short") {
+				e.EntrySlippagePct = -ex.EntryDriftPct
+			}
+		}
+	}
+	return entries, nil
+}
+
+// startDailySummary 每天 UTC hour 点推送此前 24 小时的交易日报。
+func (s *LiveService) startDailySummary(ctx context.Context, hour int) {
+	if s == nil || s.decLogs == nil || s.tg == nil {
+		return
+	}
+	go func() {
+		for {
+			next := nextDailyRun(time.Now(), hour)
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			if err := s.sendDailySummary(ctx, next.Add(-24*time.Hour), next); err != nil {
+				logger.Warnf("LiveService: 每日交易总结发送失败: %v", err)
+			}
+		}
+	}()
+}
+
+func (s *LiveService) sendDailySummary(ctx context.Context, from, to time.Time) error {
+	entries, err := s.TradeJournal(ctx, livehttp.JournalQuery{From: from, To: to})
+	if err != nil {
+		return err
+	}
+	msg := attribution.RenderJournalMarkdown(attribution.SummarizeJournal(from, to, entries))
+	return notifier.SendCategoryText(s.tg, notifier.CategoryGeneral, msg)
+}
+
+// nextDailyRun 返回 now 之后最近的 UTC hour:00。
+func nextDailyRun(now time.Time, hour int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -5,35 +5,60 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"brale/internal/agent/engine"
 	"brale/internal/agent/ports"
+	"brale/internal/agent/risk"
 	mktsvc "brale/internal/agent/service/market"
 	"brale/internal/agent/service/position"
 	brcfg "brale/internal/config"
+	cfgloader "brale/internal/config/loader"
 	"brale/internal/decision"
 	"brale/internal/exitplan"
 	"brale/internal/gateway/database"
 	"brale/internal/gateway/notifier"
+	"brale/internal/gateway/webhook"
+	"brale/internal/logger"
 	"brale/internal/market"
 	"brale/internal/profile"
 	promptkit "brale/internal/prompt"
+	"brale/internal/store"
+	"brale/internal/store/archive"
+	"brale/internal/strategy"
 	"brale/internal/strategy/exit"
 
 	"golang.org/x/sync/errgroup"
 )
 
+// FillStream 为直连执行器的成交推送（user-data stream），随服务启动。
+type FillStream interface {
+	StartUserStream(ctx context.Context)
+}
+
+// closingWatchdog 由执行管理器可选实现，巡检卡在 closing_* 状态的订单。
+type closingWatchdog interface {
+	StartClosingWatchdog(ctx context.Context)
+}
+
+// pendingExitRecovery 由执行管理器可选实现：启动时与 freqtrade 对账重启前未完成的平仓，关闭时停止等待计时器。
+type pendingExitRecovery interface {
+	RecoverPendingExits(ctx context.Context) error
+	StopPendingTimers() int
+}
+
 type LiveServiceParams struct {
 	Config          *brcfg.Config
 	KlineStore      market.KlineStore
 	Updater         *market.WSUpdater
 	Metrics         *market.MetricsService
 	Engine          decision.Decider
-	Telegram        *notifier.Telegram
+	Notifier        notifier.Notifier
 	DecisionLogs    *database.DecisionLogStore
 	Symbols         []string
 	Intervals       []string
+	Lookbacks       map[string]int
 	HorizonName     string
 	HorizonSummary  string
 	WarmupSummary   string
@@ -44,15 +69,30 @@ type LiveServiceParams struct {
 	PlanHandlers    *exit.HandlerRegistry
 	StrategyStore   exit.StrategyStore
 	ExitPlanPrompts map[string]promptkit.ExitPlanPrompt
+	PriceGuard      *PriceGuard
+	VolBreaker      *VolatilityBreaker
+	SymbolInfo      *market.SymbolInfoService
+	Correlation     *risk.Correlation
+	Webhooks        *webhook.Dispatcher
+	Annotations     database.AnnotationStore
+	Archiver        *archive.Service
+	FillStream      FillStream
+	ProfileLoader   *cfgloader.ProfileLoader
+	OrderBook       *market.OrderBookTracker
+	Funding         *market.FundingTracker
+	Prompts         *strategy.Manager
+	KlineCache      *store.KlineDiskCache
 }
 
 type LiveService struct {
 	cfg        *brcfg.Config
 	monitor    *PriceMonitor
+	klineStore market.KlineStore
 	liveEngine *engine.LiveEngine
-	tg         *notifier.Telegram
+	tg         notifier.Notifier
 	decLogs    *database.DecisionLogStore
 
+	targetsMu     sync.Mutex
 	symbols       []string
 	hIntervals    []string
 	horizonName   string
@@ -72,15 +112,29 @@ type LiveService struct {
 
 	circuitBreaker *circuit.CircuitBreaker
 
-	metrics *market.MetricsService
+	metrics       *market.MetricsService
+	archiver      *archive.Service
+	fillStream    FillStream
+	profileLoader *cfgloader.ProfileLoader
+	volBreaker    *VolatilityBreaker
+	priceGuard    *PriceGuard
+	symbolInfo    *market.SymbolInfoService
+	correlation   *risk.Correlation
+	orderBook     *market.OrderBookTracker
+	funding       *market.FundingTracker
+	prompts       *strategy.Manager
+	klineCache    *store.KlineDiskCache
+	webhooks      *webhook.Dispatcher
+	annotations   database.AnnotationStore
+	mode          *runMode
 }
 
 func NewLiveService(p LiveServiceParams) *LiveService {
 	var textNotifier notifier.TextNotifier
 	var structuredNotifier engine.Notifier
-	if p.Telegram != nil {
-		textNotifier = p.Telegram
-		structuredNotifier = p.Telegram
+	if p.Notifier != nil {
+		textNotifier = p.Notifier
+		structuredNotifier = p.Notifier
 	}
 
 	var planScheduler *PlanScheduler
@@ -91,6 +145,8 @@ func NewLiveService(p LiveServiceParams) *LiveService {
 			Handlers:    p.PlanHandlers,
 			ExecManager: p.ExecManager,
 			Notifier:    textNotifier,
+			PriceGuard:  p.PriceGuard,
+			Webhooks:    p.Webhooks,
 		})
 	}
 
@@ -110,14 +166,20 @@ func NewLiveService(p LiveServiceParams) *LiveService {
 			KlineStore:     p.KlineStore,
 			Symbols:        symbols,
 			Intervals:      intervals,
+			Lookbacks:      p.Lookbacks,
 			HorizonSummary: p.HorizonSummary,
 			WarmupSummary:  p.WarmupSummary,
-			Telegram:       p.Telegram,
+			Notifier:       p.Notifier,
 			ExecManager:    p.ExecManager,
 			Observer:       planScheduler,
+			Priority:       p.ProfileManager.StreamPriority,
 		})
 	}
 
+	if p.PriceGuard != nil && monitor != nil {
+		p.PriceGuard.SetPrimary(monitor.freshLastPrice)
+	}
+
 	posSvc := position.NewService(p.ExecManager)
 
 	mktParams := mktsvc.ServiceParams{
@@ -129,7 +191,15 @@ func NewLiveService(p LiveServiceParams) *LiveService {
 		HorizonName: p.HorizonName,
 		VisionReady: p.VisionReady,
 	}
+	if p.OrderBook != nil {
+		mktParams.OrderBook = p.OrderBook
+	}
 	mktSvc := mktsvc.NewService(mktParams)
+	if planScheduler != nil && p.Config != nil {
+		planScheduler.SetTrailingStop(p.Config.Advanced.TrailingStop, mktSvc.GetATR)
+		planScheduler.SetScaleIn(p.Config.Advanced.ScaleIn)
+		planScheduler.SetAutoBreakeven(p.Config.Advanced.AutoBreakeven)
+	}
 
 	engParams := engine.EngineParams{
 		Config:          p.Config,
@@ -143,13 +213,36 @@ func NewLiveService(p LiveServiceParams) *LiveService {
 		PlanScheduler:   planScheduler,
 		ExitPlanPrompts: p.ExitPlanPrompts,
 		Notifier:        structuredNotifier,
+		Webhooks:        p.Webhooks,
+	}
+	if p.VolBreaker != nil {
+		engParams.EntryGate = p.VolBreaker
+	}
+	if p.SymbolInfo != nil && (p.Config.Advanced.SymbolRules.Enabled || p.Config.Execution.Binance.LeverageBrackets) {
+		// 仅为直连执行器构建的规则缓存不参与决策执行前的取整；杠杆档位封顶在此处完成，对 freqtrade 与直连执行器同样生效。
+		engParams.SymbolRules = p.SymbolInfo
+	}
+	if p.Correlation != nil {
+		engParams.Correlation = p.Correlation
+	}
+	if p.Updater != nil {
+		engParams.Backfill = p.Updater
+	}
+	if p.DecisionLogs != nil {
+		engParams.EntrySignals = p.DecisionLogs
+		engParams.RiskStore = p.DecisionLogs
+		engParams.LossHistory = p.DecisionLogs
 	}
 	liveEngine := engine.NewLiveEngine(engParams)
+	if planScheduler != nil {
+		planScheduler.SetScaleInGate(liveEngine)
+	}
 
 	svc := &LiveService{
 		cfg:            p.Config,
 		liveEngine:     liveEngine,
-		tg:             p.Telegram,
+		klineStore:     p.KlineStore,
+		tg:             p.Notifier,
 		decLogs:        p.DecisionLogs,
 		metrics:        p.Metrics,
 		horizonName:    p.HorizonName,
@@ -165,6 +258,20 @@ func NewLiveService(p LiveServiceParams) *LiveService {
 		hIntervals:     intervals,
 		planScheduler:  planScheduler,
 		monitor:        monitor,
+		archiver:       p.Archiver,
+		fillStream:     p.FillStream,
+		profileLoader:  p.ProfileLoader,
+		volBreaker:     p.VolBreaker,
+		priceGuard:     p.PriceGuard,
+		symbolInfo:     p.SymbolInfo,
+		correlation:    p.Correlation,
+		orderBook:      p.OrderBook,
+		funding:        p.Funding,
+		prompts:        p.Prompts,
+		klineCache:     p.KlineCache,
+		webhooks:       p.Webhooks,
+		annotations:    p.Annotations,
+		mode:           newRunMode(p.Config != nil && p.Config.App.IsStandby()),
 	}
 
 	if planStore := p.StrategyStore; planStore != nil {
@@ -172,6 +279,9 @@ func NewLiveService(p LiveServiceParams) *LiveService {
 			svc.strategyCloser = closable
 		}
 	}
+	if svc.prompts != nil && svc.profileMgr != nil {
+		svc.prompts.Subscribe(func([]string) { svc.profileMgr.RefreshPrompts() })
+	}
 	if svc.planScheduler != nil && svc.execManager != nil {
 		svc.execManager.SetPlanUpdateHook(svc.planScheduler)
 
@@ -184,13 +294,68 @@ func (s *LiveService) Run(ctx context.Context) error {
 		go s.metrics.Start(ctx)
 	}
 	s.prewarmDerivatives(ctx)
-	if s.planScheduler != nil {
-		s.planScheduler.Start(ctx)
+	if s.webhooks != nil {
+		s.webhooks.Start(ctx)
+	}
+	if s.fillStream != nil {
+		s.fillStream.StartUserStream(ctx)
+	}
+	if s.volBreaker != nil {
+		s.volBreaker.Start(ctx)
+	}
+	if s.priceGuard != nil {
+		s.priceGuard.Start(ctx, s.targets())
+	}
+	if s.orderBook != nil {
+		s.orderBook.Start(ctx)
+	}
+	if s.funding != nil {
+		s.funding.Start(ctx)
+	}
+	if s.prompts != nil {
+		s.prompts.Watch(ctx, strategy.DefaultPromptWatchInterval)
+	}
+	if s.klineCache != nil && s.cfg != nil {
+		s.klineCache.Start(ctx, time.Duration(s.cfg.Kline.Persist.FlushMinutes)*time.Minute)
+	}
+	if s.symbolInfo != nil {
+		s.symbolInfo.Start(ctx)
+	}
+	if s.correlation != nil {
+		s.correlation.Start(ctx)
 	}
 	if s.monitor != nil {
 		s.monitor.Start(ctx)
 	}
 
+	// standby 只维护行情缓存与持仓对账；执行器、LLM 与会写库的后台任务在 promote 后才启动。
+	if s.mode != nil && s.mode.isStandby() {
+		logger.Infof("LiveService: standby 模式运行，等待 promote")
+		if !s.mode.waitPromoted(ctx) {
+			return ctx.Err()
+		}
+	}
+	if s.archiver != nil {
+		s.archiver.Start(ctx)
+	}
+	if r, ok := s.execManager.(pendingExitRecovery); ok {
+		if err := r.RecoverPendingExits(ctx); err != nil {
+			logger.Warnf("LiveService: 平仓等待恢复失败: %v", err)
+		}
+	}
+	if w, ok := s.execManager.(closingWatchdog); ok {
+		w.StartClosingWatchdog(ctx)
+	}
+	if s.profileLoader != nil && s.cfg != nil {
+		s.profileLoader.StartPurge(ctx, time.Duration(s.cfg.AI.ProfileTrashDays)*24*time.Hour, time.Hour)
+	}
+	if s.planScheduler != nil {
+		s.planScheduler.Start(ctx)
+	}
+	if s.cfg != nil && s.cfg.Notify.DailySummary.Enabled {
+		s.startDailySummary(ctx, s.cfg.Notify.DailySummary.Hour)
+	}
+
 	if s.liveEngine != nil {
 		return s.liveEngine.Run(ctx)
 	}
@@ -206,11 +371,11 @@ func (s *LiveService) prewarmDerivatives(ctx context.Context) {
 	if base == nil {
 		base = context.Background()
 	}
-	if s.metrics != nil && len(s.symbols) > 0 {
+	if symbols := s.targets(); s.metrics != nil && len(symbols) > 0 {
 		go func() {
 			var eg errgroup.Group
 			eg.SetLimit(4)
-			for _, sym := range s.symbols {
+			for _, sym := range symbols {
 				sym := strings.TrimSpace(sym)
 				if sym == "" {
 					continue
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/decision"
+	"brale/internal/market"
+	"brale/internal/store"
+	livehttp "brale/internal/transport/http/live"
+)
+
+// exportMaxBars 为单次导出读取缓存的上限；指标在整段缓存上计算后再按时间区间裁剪，避免区间开头缺少预热。
+const exportMaxBars = 100000
+
+func (s *LiveService) ExportMarketData(ctx context.Context, req livehttp.MarketExportRequest) (livehttp.MarketExportTable, error) {
+	var table livehttp.MarketExportTable
+	if s == nil || s.klineStore == nil {
+		return table, fmt.Errorf("kline store 未启用")
+	}
+	exporter, ok := s.klineStore.(store.SnapshotExporter)
+	if !ok {
+		return table, fmt.Errorf("kline store 不支持快照导出")
+	}
+	candles, err := exporter.Export(ctx, req.Symbol, req.Interval, exportMaxBars)
+	if err != nil {
+		return table, err
+	}
+	if !req.To.IsZero() {
+		end := len(candles)
+		for end > 0 && candles[end-1].OpenTime > req.To.UnixMilli() {
+			end--
+		}
+		candles = candles[:end]
+	}
+	if len(candles) == 0 {
+		return table, fmt.Errorf("%s %s 暂无K线数据", req.Symbol, req.Interval)
+	}
+	start := 0
+	if !req.From.IsZero() {
+		for start < len(candles) && candles[start].OpenTime < req.From.UnixMilli() {
+			start++
+		}
+		if start == len(candles) {
+			return table, fmt.Errorf("%s %s 区间内无K线", req.Symbol, req.Interval)
+		}
+	}
+
+	switch req.Kind {
+	case "", "candles":
+		return exportCandleTable(candles[start:]), nil
+	case "indicators":
+		rep, err := indicator.ComputeAll(candles, indicator.Settings{Symbol: req.Symbol, Interval: req.Interval})
+		if err != nil {
+			return table, fmt.Errorf("indicator 计算失败: %w", err)
+		}
+		return exportIndicatorTable(candles, start, rep), nil
+	case "snapshot":
+		rep, err := indicator.ComputeAll(candles, indicator.Settings{Symbol: req.Symbol, Interval: req.Interval})
+		if err != nil {
+			return table, fmt.Errorf("indicator 计算失败: %w", err)
+		}
+		raw, err := decision.BuildIndicatorSnapshot(candles, rep)
+		if err != nil {
+			return table, err
+		}
+		return exportSnapshotTable(raw)
+	default:
+		return table, fmt.Errorf("未知导出类型: %s（candles/indicators/snapshot）", req.Kind)
+	}
+}
+
+func exportCandleTable(candles []market.Candle) livehttp.MarketExportTable {
+	table := livehttp.MarketExportTable{
+		Columns: []string{"open_time", "open_time_ms", "open", "high", "low", "close", "volume", "close_time_ms"},
+		Rows:    make([][]string, 0, len(candles)),
+	}
+	for _, c := range candles {
+		table.Rows = append(table.Rows, []string{
+			time.UnixMilli(c.OpenTime).UTC().Format(time.RFC3339),
+			strconv.FormatInt(c.OpenTime, 10),
+			formatExportFloat(c.Open),
+			formatExportFloat(c.High),
+			formatExportFloat(c.Low),
+			formatExportFloat(c.Close),
+			formatExportFloat(c.Volume),
+			strconv.FormatInt(c.CloseTime, 10),
+		})
+	}
+	return table
+}
+
+// exportIndicatorTable 把各指标序列按尾部对齐到 K 线，序列未覆盖的预热段留空。
+func exportIndicatorTable(candles []market.Candle, start int, rep indicator.Report) livehttp.MarketExportTable {
+	keys := make([]string, 0, len(rep.Values))
+	for key, val := range rep.Values {
+		if len(val.Series) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	table := livehttp.MarketExportTable{
+		Columns: append([]string{"open_time", "open_time_ms", "close"}, keys...),
+		Rows:    make([][]string, 0, len(candles)-start),
+	}
+	for i := start; i < len(candles); i++ {
+		c := candles[i]
+		row := []string{
+			time.UnixMilli(c.OpenTime).UTC().Format(time.RFC3339),
+			strconv.FormatInt(c.OpenTime, 10),
+			formatExportFloat(c.Close),
+		}
+		for _, key := range keys {
+			series := rep.Values[key].Series
+			idx := i - (len(candles) - len(series))
+			if idx < 0 || idx >= len(series) {
+				row = append(row, "")
+				continue
+			}
+			row = append(row, formatExportFloat(series[idx]))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// exportSnapshotTable 把快照 JSON 展开为 path,value 两列，数组下标写作 path[i]。
+func exportSnapshotTable(raw []byte) (livehttp.MarketExportTable, error) {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return livehttp.MarketExportTable{}, fmt.Errorf("解析快照失败: %w", err)
+	}
+	table := livehttp.MarketExportTable{Columns: []string{"path", "value"}}
+	flattenExportJSON("", doc, &table.Rows)
+	return table, nil
+}
+
+func flattenExportJSON(prefix string, v any, rows *[][]string) {
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			flattenExportJSON(path, val[k], rows)
+		}
+	case []any:
+		for i, item := range val {
+			flattenExportJSON(fmt.Sprintf("%s[%d]", prefix, i), item, rows)
+		}
+	case nil:
+		*rows = append(*rows, []string{prefix, ""})
+	case float64:
+		*rows = append(*rows, []string{prefix, formatExportFloat(val)})
+	default:
+		*rows = append(*rows, []string{prefix, strings.TrimSpace(fmt.Sprint(val))})
+	}
+}
+
+func formatExportFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
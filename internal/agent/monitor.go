@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"fmt"
+	"maps"
 	"strings"
 	"sync"
 	"time"
@@ -23,11 +24,13 @@ type MonitorParams struct {
 	KlineStore     market.KlineStore
 	Symbols        []string
 	Intervals      []string
+	Lookbacks      map[string]int
 	HorizonSummary string
 	WarmupSummary  string
-	Telegram       *notifier.Telegram
+	Notifier       notifier.Notifier
 	ExecManager    ports.ExecutionManager
 	Observer       PriceObserver
+	Priority       market.StreamPriority
 }
 
 type PriceMonitor struct {
@@ -37,9 +40,10 @@ type PriceMonitor struct {
 	intervals      []string
 	horizonSummary string
 	warmupSummary  string
-	tg             *notifier.Telegram
+	tg             notifier.Notifier
 	execManager    ports.ExecutionManager
 	observer       PriceObserver
+	priority       market.StreamPriority
 
 	priceCache   map[string]cachedQuote
 	priceCacheMu sync.RWMutex
@@ -48,6 +52,12 @@ type PriceMonitor struct {
 
 	tradeStreamMu sync.Mutex
 	tradeStreamUp bool
+	tradeSymbols  []string
+
+	// universeMu 保护 symbols/intervals/lookbacks/runCtx；profile 热更新会替换订阅范围。
+	universeMu sync.RWMutex
+	lookbacks  map[string]int
+	runCtx     context.Context
 }
 
 type cachedQuote struct {
@@ -71,11 +81,13 @@ func NewPriceMonitor(p MonitorParams) *PriceMonitor {
 		ks:             p.KlineStore,
 		symbols:        append([]string(nil), p.Symbols...),
 		intervals:      append([]string(nil), p.Intervals...),
+		lookbacks:      maps.Clone(p.Lookbacks),
 		horizonSummary: p.HorizonSummary,
 		warmupSummary:  p.WarmupSummary,
-		tg:             p.Telegram,
+		tg:             p.Notifier,
 		execManager:    p.ExecManager,
 		observer:       p.Observer,
+		priority:       p.Priority,
 		priceCache:     make(map[string]cachedQuote),
 		lastPrice:      make(map[string]lastPriceEntry),
 	}
@@ -85,9 +97,14 @@ func (m *PriceMonitor) Start(ctx context.Context) {
 	if m == nil {
 		return
 	}
+	m.universeMu.Lock()
+	m.runCtx = ctx
+	m.universeMu.Unlock()
 	if m.updater != nil {
 		firstWSConnected := false
 		m.updater.OnEvent = m.onCandleEvent
+		m.updater.Priority = m.priority
+		m.updater.HeldSymbols = m.heldSymbols
 		m.updater.OnConnected = func() {
 			m.clearWSLastError()
 			if m.tg == nil {
@@ -118,15 +135,30 @@ func (m *PriceMonitor) Start(ctx context.Context) {
 			if err != nil {
 				msg = msg + ": " + err.Error()
 			}
-			_ = m.tg.SendText(msg)
+			_ = notifier.SendCategoryText(m.tg, notifier.CategoryWSStatus, msg)
 		}
+		m.updater.OnBackfill = m.notifyBackfill
 		go func() {
-			if err := m.updater.Start(ctx, m.symbols, m.intervals); err != nil {
+			symbols, intervals := m.universe()
+			if err := m.updater.Start(ctx, symbols, intervals); err != nil {
 				logger.Errorf("启动行情订阅失败: %v", err)
 			}
 		}()
 	}
 	m.startTradePriceStream(ctx)
+	m.watchStreamPriority(ctx)
+}
+
+func (m *PriceMonitor) notifyBackfill(sum market.GapFillSummary) {
+	if m.tg == nil {
+		return
+	}
+	msg := fmt.Sprintf("WS 重连补数据完成 ♻️\n缺口 stream: %d/%d\n缺失: %d 根，补回: %d 根\n耗时: %s",
+		sum.Gapped, sum.Streams, sum.Missing, sum.Recovered, sum.Duration.Round(time.Millisecond))
+	if len(sum.Failed) > 0 {
+		msg += "\n失败: " + strings.Join(sum.Failed, ", ")
+	}
+	_ = notifier.SendCategoryText(m.tg, notifier.CategoryWSStatus, msg)
 }
 
 func (m *PriceMonitor) Close() {
@@ -167,7 +199,7 @@ func (m *PriceMonitor) startTradePriceStream(ctx context.Context) {
 				if wasUp {
 					msg = "实时成交价流已恢复 ✅"
 				}
-				_ = m.tg.SendText(msg)
+				_ = notifier.SendCategoryText(m.tg, notifier.CategoryWSStatus, msg)
 			}
 		},
 		OnDisconnect: func(err error) {
@@ -182,15 +214,23 @@ func (m *PriceMonitor) startTradePriceStream(ctx context.Context) {
 				if err != nil && err.Error() != "" {
 					reason = err.Error()
 				}
-				_ = m.tg.SendText(fmt.Sprintf("实时成交价流断线 ⚠️\n错误: %s", reason))
+				_ = notifier.SendCategoryText(m.tg, notifier.CategoryWSStatus, fmt.Sprintf("实时成交价流断线 ⚠️\n错误: %s", reason))
 			}
 		},
 	}
-	stream, err := m.updater.Source.SubscribeTrades(ctx, m.symbols, opts)
+	universe, _ := m.universe()
+	symbols, evicted := market.PrioritizeSymbols(universe, m.heldSymbols(ctx), m.priority, 1, m.updater.MaxStreams)
+	if len(evicted) > 0 {
+		logger.Warnf("实时成交价订阅超出上限 %d，淘汰低优先级 symbol: %v", m.updater.MaxStreams, evicted)
+	}
+	stream, err := m.updater.Source.SubscribeTrades(ctx, symbols, opts)
 	if err != nil {
 		logger.Warnf("订阅实时成交价失败: %v", err)
 		return
 	}
+	m.tradeStreamMu.Lock()
+	m.tradeSymbols = symbols
+	m.tradeStreamMu.Unlock()
 	logger.Infof("✓ 实时成交价订阅已启动 (aggTrade)")
 	go func() {
 		for {
@@ -260,6 +300,29 @@ func (m *PriceMonitor) freshLastPrice(symbol string) (float64, bool) {
 	return entry.price, true
 }
 
+// LastPrices 返回各 symbol 最近的成交价（无成交流时退回最近 K 线收盘价）及其时间戳。
+func (m *PriceMonitor) LastPrices() map[string]exchange.PriceQuote {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]exchange.PriceQuote)
+	m.priceCacheMu.RLock()
+	for sym, cq := range m.priceCache {
+		if cq.quote.Last > 0 {
+			out[sym] = exchange.PriceQuote{Symbol: sym, Last: cq.quote.Last, UpdatedAt: time.UnixMilli(cq.ts)}
+		}
+	}
+	m.priceCacheMu.RUnlock()
+	m.lastPriceMu.RLock()
+	for sym, entry := range m.lastPrice {
+		if entry.price > 0 && entry.ts >= out[sym].UpdatedAt.UnixMilli() {
+			out[sym] = exchange.PriceQuote{Symbol: sym, Last: entry.price, UpdatedAt: time.UnixMilli(entry.ts)}
+		}
+	}
+	m.lastPriceMu.RUnlock()
+	return out
+}
+
 func (m *PriceMonitor) LatestPrice(ctx context.Context, symbol string) float64 {
 	if m == nil {
 		return 0
@@ -287,8 +350,8 @@ func (m *PriceMonitor) LatestPriceQuote(ctx context.Context, symbol string) exch
 		return quote
 	}
 	interval := "1m"
-	if len(m.intervals) > 0 {
-		interval = m.intervals[0]
+	if _, intervals := m.universe(); len(intervals) > 0 {
+		interval = intervals[0]
 	}
 	klines, err := m.ks.Get(ctx, symbol, interval)
 	if err != nil || len(klines) == 0 {
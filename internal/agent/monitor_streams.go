@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/market"
+)
+
+const streamPriorityCheckInterval = time.Minute
+
+// heldSymbols 返回当前持仓 symbol（market.StreamKey 写法）。
+func (m *PriceMonitor) heldSymbols(ctx context.Context) map[string]bool {
+	held := make(map[string]bool)
+	if m == nil || m.execManager == nil {
+		return held
+	}
+	positions, err := m.execManager.ListOpenPositions(ctx)
+	if err != nil {
+		logger.Warnf("订阅优先级: 获取持仓失败: %v", err)
+		return held
+	}
+	for _, p := range positions {
+		held[market.StreamKey(p.Symbol)] = true
+	}
+	return held
+}
+
+// watchStreamPriority 在 stream 数超出上限时周期检查持仓，
+// 若有持仓 symbol 被淘汰则重新订阅，保证持仓 symbol 的 kline/成交流不缺失。
+func (m *PriceMonitor) watchStreamPriority(ctx context.Context) {
+	if m == nil || m.updater == nil || m.updater.Source == nil || m.updater.MaxStreams <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(streamPriorityCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			symbols, intervals := m.universe()
+			if len(symbols)*max(len(intervals), 1) <= m.updater.MaxStreams {
+				continue
+			}
+			universe := make(map[string]bool, len(symbols))
+			for _, sym := range symbols {
+				universe[market.StreamKey(sym)] = true
+			}
+			held := m.heldSymbols(ctx)
+			// 只关心订阅范围内的持仓，范围外的持仓不会触发重订阅。
+			for sym := range held {
+				if !universe[sym] {
+					delete(held, sym)
+				}
+			}
+			if len(held) == 0 {
+				continue
+			}
+			if len(intervals) > 0 && missingHeld(held, m.updater.ActiveSymbols()) {
+				logger.Infof("订阅优先级: 持仓 symbol 未订阅 kline，重新订阅")
+				if err := m.updater.Start(ctx, symbols, intervals); err != nil {
+					logger.Errorf("重新订阅行情失败: %v", err)
+				}
+			}
+			m.tradeStreamMu.Lock()
+			tradeSymbols := append([]string(nil), m.tradeSymbols...)
+			m.tradeStreamMu.Unlock()
+			if missingHeld(held, tradeSymbols) {
+				logger.Infof("订阅优先级: 持仓 symbol 未订阅成交流，重新订阅")
+				m.startTradePriceStream(ctx)
+			}
+		}
+	}()
+}
+
+func (m *PriceMonitor) universe() ([]string, []string) {
+	m.universeMu.RLock()
+	defer m.universeMu.RUnlock()
+	return append([]string(nil), m.symbols...), append([]string(nil), m.intervals...)
+}
+
+// UpdateUniverse 在 profile 热更新后调整订阅范围：仍有持仓的旧 symbol 保留，新增的 symbol/周期先按 lookbacks 预热，
+// 范围有变化时才重新订阅 kline 与成交流；范围不变但某周期 lookback 提高时只对该周期补拉历史。监控尚未启动时只记录新范围。
+func (m *PriceMonitor) UpdateUniverse(symbols, intervals []string, lookbacks map[string]int) {
+	if m == nil || len(symbols) == 0 || len(intervals) == 0 {
+		return
+	}
+	m.universeMu.RLock()
+	ctx := m.runCtx
+	m.universeMu.RUnlock()
+	oldSymbols, oldIntervals := m.universe()
+	next := append([]string(nil), symbols...)
+	if ctx != nil {
+		wanted := make(map[string]bool, len(symbols))
+		for _, sym := range symbols {
+			wanted[market.StreamKey(sym)] = true
+		}
+		held := m.heldSymbols(ctx)
+		for _, sym := range oldSymbols {
+			if key := market.StreamKey(sym); held[key] && !wanted[key] {
+				logger.Infof("profile 热更新: %s 已移出 targets 但仍有持仓，保留订阅", sym)
+				next = append(next, sym)
+			}
+		}
+	}
+	grown := m.swapLookbacks(lookbacks)
+	live := ctx != nil && ctx.Err() == nil && m.updater != nil
+	symbolsChanged := !sameSet(oldSymbols, next, market.StreamKey)
+	if !symbolsChanged && sameSet(oldIntervals, intervals, strings.ToLower) {
+		// 订阅范围不变但 limit/预热需求提高：只对需求变大的周期补拉历史
+		if live && len(grown) > 0 && m.updater.Source != nil {
+			logger.Infof("profile 热更新: 预热需求提高 %v，补拉历史", grown)
+			market.NewPreheater(m.ks, m.updater.Max, m.updater.Source).Warmup(ctx, next, grown)
+		}
+		return
+	}
+	if !live {
+		m.universeMu.Lock()
+		m.symbols, m.intervals = next, append([]string(nil), intervals...)
+		m.universeMu.Unlock()
+		return
+	}
+	logger.Infof("profile 热更新: 订阅范围变化 symbols=%v→%v intervals=%v→%v", oldSymbols, next, oldIntervals, intervals)
+	if m.updater.Source != nil {
+		market.NewPreheater(m.ks, m.updater.Max, m.updater.Source).Warmup(ctx, next, lookbacks)
+	}
+	m.universeMu.Lock()
+	m.symbols, m.intervals = next, append([]string(nil), intervals...)
+	m.universeMu.Unlock()
+	if err := m.updater.Start(ctx, next, intervals); err != nil {
+		logger.Errorf("profile 热更新: 重新订阅行情失败: %v", err)
+	}
+	if symbolsChanged {
+		m.startTradePriceStream(ctx)
+	}
+}
+
+// swapLookbacks 记录新的各周期预热条数，返回比上次记录更大的周期及其条数。
+func (m *PriceMonitor) swapLookbacks(lookbacks map[string]int) map[string]int {
+	m.universeMu.Lock()
+	defer m.universeMu.Unlock()
+	grown := make(map[string]int)
+	for iv, need := range lookbacks {
+		if need > m.lookbacks[strings.ToLower(iv)] {
+			grown[iv] = need
+		}
+	}
+	m.lookbacks = make(map[string]int, len(lookbacks))
+	for iv, need := range lookbacks {
+		m.lookbacks[strings.ToLower(iv)] = need
+	}
+	return grown
+}
+
+func sameSet(a, b []string, key func(string) string) bool {
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[key(v)] = true
+	}
+	other := make(map[string]bool, len(b))
+	for _, v := range b {
+		other[key(v)] = true
+	}
+	if len(set) != len(other) {
+		return false
+	}
+	for k := range other {
+		if !set[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func missingHeld(held map[string]bool, active []string) bool {
+	subscribed := make(map[string]bool, len(active))
+	for _, sym := range active {
+		subscribed[market.StreamKey(sym)] = true
+	}
+	for sym := range held {
+		if !subscribed[sym] {
+			return true
+		}
+	}
+	return false
+}
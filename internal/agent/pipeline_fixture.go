@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"brale/internal/pipeline"
+	"brale/internal/pipeline/testkit"
+	"brale/internal/profile"
+)
+
+// RecordPipelineFixture 用当前 KlineStore 数据运行 symbol 的 profile pipeline 并录制为 testkit fixture，
+// 供 middleware 回归测试回放。full=false 时只保留 K 线；trim>0 时每个周期只保留最后 trim 根。
+func (s *LiveService) RecordPipelineFixture(ctx context.Context, symbol, profileName string, trim int, full bool) (testkit.Fixture, error) {
+	if s == nil || s.profileMgr == nil {
+		return testkit.Fixture{}, fmt.Errorf("profile manager 未初始化")
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	var rt *profile.Runtime
+	if name := strings.TrimSpace(profileName); name != "" {
+		if rt = s.findProfile(name); rt == nil {
+			return testkit.Fixture{}, fmt.Errorf("profile %s 不存在", name)
+		}
+	} else {
+		rt, _ = s.profileMgr.Resolve(symbol)
+	}
+	if rt == nil || rt.Pipeline == nil {
+		return testkit.Fixture{}, fmt.Errorf("%s 未匹配到 profile", symbol)
+	}
+	ac := pipeline.NewContext(symbol)
+	ac.Profile = rt.Definition.Name
+	if err := rt.Pipeline.Run(ctx, ac); err != nil {
+		return testkit.Fixture{}, fmt.Errorf("运行 profile %s pipeline 失败: %w", rt.Definition.Name, err)
+	}
+	if len(ac.Intervals()) == 0 {
+		return testkit.Fixture{}, fmt.Errorf("%s 无K线数据", symbol)
+	}
+	fx := testkit.Record(ac).Trim(trim)
+	if !full {
+		fx = fx.CandlesOnly()
+	}
+	return fx, nil
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	brcfg "brale/internal/config"
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+	"brale/internal/strategy/exit"
+)
+
+const autoBreakevenSource = "auto_breakeven"
+
+// SetAutoBreakeven 启用保本+自动化；ATR 条件沿用 SetTrailingStop 注入的 ATR 来源。
+func (s *PlanScheduler) SetAutoBreakeven(cfg brcfg.AutoBreakevenConfig) {
+	if s == nil {
+		return
+	}
+	s.breakeven = cfg
+	if cfg.Enabled {
+		logger.Infof("PlanScheduler: 保本+自动化已启用 profit_pct=%.4f atr_multiplier=%.2f fee_buffer_pct=%.4f",
+			cfg.ProfitPct, cfg.ATRMultiplier, cfg.FeeBufferPct)
+	}
+}
+
+// autoBreakeven 在 tier 评估之后、移动止损之前运行：浮盈达到阈值的持仓把仍在等待的止损段移到保本+价位，
+// 每笔交易只触发一次，调整以 auto_breakeven 为来源写入 strategy_change_log。
+func (s *PlanScheduler) autoBreakeven(ctx context.Context, watchers []*planWatcher, price float64) {
+	if !s.breakeven.Enabled || price <= 0 || s.executor == nil {
+		return
+	}
+	adjusted := make(map[int]bool)
+	for _, w := range watchers {
+		if w == nil || watcherHasPending(w) || s.breakevenApplied(w.tradeID) {
+			continue
+		}
+		done, changed := s.breakevenWatcher(ctx, w, price)
+		if done {
+			s.markBreakeven(w.tradeID)
+		}
+		if changed {
+			adjusted[w.tradeID] = true
+		}
+	}
+	for tradeID := range adjusted {
+		s.rebuildTrade(ctx, tradeID)
+	}
+}
+
+// breakevenWatcher 返回该交易是否已完成保本（本次移动成功，或止损已不劣于保本+价位）以及是否发生了调整。
+func (s *PlanScheduler) breakevenWatcher(ctx context.Context, w *planWatcher, price float64) (bool, bool) {
+	keys := make([]string, 0, len(w.components))
+	for k := range w.components {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var atr float64
+	if s.breakeven.ATRMultiplier > 0 {
+		atr = s.trailingATR(w)
+	}
+	done, changed := false, false
+	for _, comp := range keys {
+		inst := w.components[comp]
+		if inst == nil || inst.Record.Status != database.StrategyStatusWaiting {
+			continue
+		}
+		state, err := exit.DecodeTierComponentState(inst.Record.StateJSON)
+		if err != nil || !strings.EqualFold(state.Mode, "stop_loss") || state.TargetPrice <= 0 || state.EntryPrice <= 0 {
+			continue
+		}
+		side := strings.ToLower(strings.TrimSpace(state.Side))
+		if side == "" {
+			side = w.side
+		}
+		short := side == "short"
+		if !s.breakevenTriggered(short, state.EntryPrice, price, atr) {
+			continue
+		}
+		target := state.EntryPrice * (1 + s.breakeven.FeeBufferPct)
+		if short {
+			target = state.EntryPrice * (1 - s.breakeven.FeeBufferPct)
+		}
+		if (!short && state.TargetPrice >= target) || (short && state.TargetPrice <= target) {
+			done = true
+			continue
+		}
+		// 保本+价位越过现价会立即触发，等价格继续朝有利方向移动
+		if (!short && target >= price) || (short && target <= price) {
+			continue
+		}
+		_, err = s.executor.HandleAdjust(ctx, w, comp, map[string]any{"target_price": target}, autoBreakevenSource)
+		if err != nil {
+			logger.Warnf("PlanScheduler: 保本+调整失败 trade=%d plan=%s component=%s err=%v", w.tradeID, w.planID, comp, err)
+			continue
+		}
+		logger.Infof("PlanScheduler: 保本+ trade=%d %s component=%s %.6f -> %.6f entry=%.6f price=%.6f atr=%.6f",
+			w.tradeID, w.symbol, comp, state.TargetPrice, target, state.EntryPrice, price, atr)
+		done, changed = true, true
+	}
+	return done, changed
+}
+
+// breakevenTriggered 判断价格相对开仓价的有利涨跌幅达到 ProfitPct，或有利偏移达到 ATRMultiplier 倍 ATR。
+func (s *PlanScheduler) breakevenTriggered(short bool, entry, price, atr float64) bool {
+	move := price - entry
+	if short {
+		move = entry - price
+	}
+	if move <= 0 {
+		return false
+	}
+	cfg := s.breakeven
+	if cfg.ProfitPct > 0 && move/entry >= cfg.ProfitPct {
+		return true
+	}
+	return cfg.ATRMultiplier > 0 && atr > 0 && move >= cfg.ATRMultiplier*atr
+}
+
+func (s *PlanScheduler) breakevenApplied(tradeID int) bool {
+	s.breakevenMu.Lock()
+	defer s.breakevenMu.Unlock()
+	return s.breakevenDone[tradeID]
+}
+
+func (s *PlanScheduler) markBreakeven(tradeID int) {
+	s.breakevenMu.Lock()
+	defer s.breakevenMu.Unlock()
+	if s.breakevenDone == nil {
+		s.breakevenDone = make(map[int]bool)
+	}
+	s.breakevenDone[tradeID] = true
+}
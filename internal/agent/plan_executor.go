@@ -10,6 +10,7 @@ import (
 
 	"brale/internal/agent/ports"
 	"brale/internal/gateway/database"
+	"brale/internal/gateway/webhook"
 	"brale/internal/logger"
 	"brale/internal/strategy/exit"
 )
@@ -18,6 +19,8 @@ type PlanExecutor struct {
 	repo            *PlanRepository
 	execManager     ports.ExecutionManager
 	onPlanTriggered func(ctx context.Context, tradeID int)
+	priceGuard      *PriceGuard
+	webhooks        *webhook.Dispatcher
 }
 
 func NewPlanExecutor(repo *PlanRepository, execManager ports.ExecutionManager, onTriggered func(ctx context.Context, tradeID int)) *PlanExecutor {
@@ -73,6 +76,52 @@ func (e *PlanExecutor) EvaluateWatcher(ctx context.Context, watcher *planWatcher
 	}
 }
 
+// EvaluateTime 对实现了 exit.TimeHandler 的计划做时间检查；price 仅用于记录触发价，可为 0。
+func (e *PlanExecutor) EvaluateTime(ctx context.Context, watcher *planWatcher, now time.Time, price float64) {
+	if watcher == nil || watcher.handler == nil || watcherHasPending(watcher) {
+		return
+	}
+	timed, ok := watcher.handler.(exit.TimeHandler)
+	if !ok {
+		return
+	}
+	keys := make([]string, 0, len(watcher.components))
+	for k := range watcher.components {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		inst := watcher.components[k]
+		if inst == nil || inst.Record.Status != database.StrategyStatusWaiting {
+			continue
+		}
+		evt, err := timed.OnTime(ctx, *inst, now)
+		if err != nil {
+			logger.Warnf("PlanExecutor: plan=%s trade=%d component=%s 时间检查失败: %v", watcher.planID, watcher.tradeID, inst.Record.PlanComponent, err)
+			continue
+		}
+		if evt == nil {
+			continue
+		}
+		logger.Infof("PlanExecutor: trade=%d %s component=%s 到达持仓时限(%v)，整仓平出", watcher.tradeID, watcher.symbol, inst.Record.PlanComponent, evt.Details["reason"])
+		e.HandlePlanEvent(ctx, watcher, inst, evt, price)
+		if isCloseEventType(evt.Type) {
+			return
+		}
+	}
+}
+
+// retriggers 返回用其他价格重新评估同一实例的函数，供 PriceGuard 判断参考价是否同样触发 evtType。
+func retriggers(ctx context.Context, watcher *planWatcher, inst *exit.PlanInstance, evtType string) func(float64) bool {
+	if watcher == nil || watcher.handler == nil || inst == nil {
+		return nil
+	}
+	return func(price float64) bool {
+		evt, err := watcher.handler.OnPrice(ctx, *inst, price)
+		return err == nil && evt != nil && evt.Type == evtType
+	}
+}
+
 func watcherHasPending(watcher *planWatcher) bool {
 	if watcher == nil {
 		return false
@@ -92,19 +141,41 @@ func isCloseEventType(t string) bool {
 	switch t {
 	case exit.PlanEventTypeTierHit,
 		exit.PlanEventTypeStopLoss, exit.PlanEventTypeTakeProfit,
-		exit.PlanEventTypeFinalStopLoss, exit.PlanEventTypeFinalTakeProfit:
+		exit.PlanEventTypeFinalStopLoss, exit.PlanEventTypeFinalTakeProfit,
+		exit.PlanEventTypeTimeExit:
 		return true
 	default:
 		return false
 	}
 }
 
+func (e *PlanExecutor) SetPriceGuard(guard *PriceGuard) {
+	if e == nil {
+		return
+	}
+	e.priceGuard = guard
+}
+
+func (e *PlanExecutor) SetWebhooks(d *webhook.Dispatcher) {
+	if e == nil {
+		return
+	}
+	e.webhooks = d
+}
+
 func (e *PlanExecutor) HandlePlanEvent(ctx context.Context, watcher *planWatcher, inst *exit.PlanInstance, evt *exit.PlanEvent, price float64) {
+	// 时间退出与价格无关，不经过价格校验。
+	if isCloseEventType(evt.Type) && evt.Type != exit.PlanEventTypeTimeExit && e.priceGuard != nil {
+		if ok, reason := e.priceGuard.Confirm(ctx, watcher.symbol, price, retriggers(ctx, watcher, inst, evt.Type)); !ok {
+			logger.Warnf("PlanExecutor: trade=%d plan=%s type=%s 触发被价格校验拦截: %s", watcher.tradeID, watcher.planID, evt.Type, reason)
+			return
+		}
+	}
 	prevState := inst.Record.StateJSON
 	prevStatus := inst.Record.Status
 	updated := false
 	switch evt.Type {
-	case exit.PlanEventTypeTierHit:
+	case exit.PlanEventTypeTierHit, exit.PlanEventTypeTimeExit:
 		updated = e.markTierTriggered(ctx, inst, evt, price)
 	case exit.PlanEventTypeStopLoss, exit.PlanEventTypeTakeProfit,
 		exit.PlanEventTypeFinalStopLoss, exit.PlanEventTypeFinalTakeProfit:
@@ -128,7 +199,8 @@ func (e *PlanExecutor) HandlePlanEvent(ctx context.Context, watcher *planWatcher
 				doClose = true
 			}
 		case exit.PlanEventTypeStopLoss, exit.PlanEventTypeTakeProfit,
-			exit.PlanEventTypeFinalStopLoss, exit.PlanEventTypeFinalTakeProfit:
+			exit.PlanEventTypeFinalStopLoss, exit.PlanEventTypeFinalTakeProfit,
+			exit.PlanEventTypeTimeExit:
 			ratio = 1.0
 			doClose = true
 		}
@@ -149,12 +221,42 @@ func (e *PlanExecutor) HandlePlanEvent(ctx context.Context, watcher *planWatcher
 		e.repo.LogStateChange(ctx, inst, prevState, prevStatus, evt.Type, "", changeDetails)
 		e.repo.LogTradeOperation(ctx, inst, evt)
 	}
+	e.publishPlanEvent(watcher, inst, evt, price)
 
 	if e.onPlanTriggered != nil {
 		e.onPlanTriggered(ctx, watcher.tradeID)
 	}
 }
 
+// publishPlanEvent 把 tier 触发与止损触发推送给外部 webhook。
+func (e *PlanExecutor) publishPlanEvent(watcher *planWatcher, inst *exit.PlanInstance, evt *exit.PlanEvent, price float64) {
+	if e.webhooks == nil {
+		return
+	}
+	var eventType string
+	switch evt.Type {
+	case exit.PlanEventTypeTierHit:
+		eventType = webhook.EventTierHit
+	case exit.PlanEventTypeStopLoss, exit.PlanEventTypeFinalStopLoss:
+		eventType = webhook.EventStopHit
+	default:
+		return
+	}
+	e.webhooks.Publish(webhook.Event{
+		Type:    eventType,
+		Symbol:  watcher.symbol,
+		TradeID: watcher.tradeID,
+		Data: map[string]any{
+			"side":      watcher.side,
+			"plan_id":   inst.Record.PlanID,
+			"component": inst.Record.PlanComponent,
+			"event":     evt.Type,
+			"price":     price,
+			"details":   evt.Details,
+		},
+	})
+}
+
 func (e *PlanExecutor) markTierTriggered(ctx context.Context, inst *exit.PlanInstance, evt *exit.PlanEvent, price float64) bool {
 	state, err := exit.DecodeTierComponentState(inst.Record.StateJSON)
 	if err != nil {
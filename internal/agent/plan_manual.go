@@ -0,0 +1,297 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/agent/interfaces"
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+	"brale/internal/strategy/exit"
+)
+
+const (
+	tierRatioTolerance       = 1e-6
+	changedFieldManualTier   = "manual_tier_complete"
+	changedFieldManualRemain = "manual_remaining_ratio"
+)
+
+type manualTier struct {
+	rec   database.StrategyInstanceRecord
+	state exit.TierComponentState
+	order int
+}
+
+// CompleteTiers 人工将 tier 标记为完成（成交发生在 brale 之外），并把根状态的剩余比例对齐到交易所回报。
+// 每个被修改的组件都会写一条 strategy_change_log，随后刷新内存中的 watcher，避免监控重复平仓。
+func (s *PlanScheduler) CompleteTiers(ctx context.Context, spec interfaces.TierCompletionSpec) ([]interfaces.TierCompletionChange, error) {
+	if s == nil || s.repo == nil {
+		return nil, fmt.Errorf("plan scheduler 未初始化")
+	}
+	if spec.TradeID <= 0 {
+		return nil, fmt.Errorf("trade_id 必填")
+	}
+	if len(spec.Components) == 0 && spec.OpenRatio < 0 {
+		return nil, fmt.Errorf("需指定 components 或提供交易所剩余仓位比例")
+	}
+	if spec.OpenRatio > 1+tierRatioTolerance {
+		return nil, fmt.Errorf("剩余仓位比例无效: %.4f", spec.OpenRatio)
+	}
+	recs, err := s.repo.ListStrategyInstances(ctx, spec.TradeID)
+	if err != nil {
+		return nil, fmt.Errorf("读取策略实例失败: %w", err)
+	}
+	planID, err := resolveTierPlanID(recs, strings.TrimSpace(spec.PlanID))
+	if err != nil {
+		return nil, err
+	}
+	var root *database.StrategyInstanceRecord
+	var tiers []manualTier
+	for i := range recs {
+		rec := recs[i]
+		if strings.TrimSpace(rec.PlanID) != planID {
+			continue
+		}
+		comp := strings.TrimSpace(rec.PlanComponent)
+		if comp == "" {
+			root = &recs[i]
+			continue
+		}
+		order, ok := tierIndex(comp)
+		if !ok {
+			continue
+		}
+		state, err := exit.DecodeTierComponentState(rec.StateJSON)
+		if err != nil {
+			return nil, fmt.Errorf("解析组件 %s 状态失败: %w", comp, err)
+		}
+		tiers = append(tiers, manualTier{rec: rec, state: state, order: order})
+	}
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("plan %s 无 tier 组件", planID)
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		if tiers[i].order != tiers[j].order {
+			return tiers[i].order < tiers[j].order
+		}
+		return tiers[i].rec.PlanComponent < tiers[j].rec.PlanComponent
+	})
+
+	var targets map[string]float64
+	if len(spec.Components) > 0 {
+		targets, err = explicitTierTargets(tiers, spec.Components)
+	} else {
+		targets = ratioTierTargets(tiers, 1-math.Max(spec.OpenRatio, 0))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := strings.TrimSpace(spec.Source)
+	if source == "" {
+		source = "operator"
+	}
+	reason := strings.TrimSpace(spec.Reason)
+	var changes []interfaces.TierCompletionChange
+	for _, t := range tiers {
+		executed, ok := targets[t.rec.PlanComponent]
+		if !ok {
+			continue
+		}
+		oldState := t.rec.StateJSON
+		state := t.state
+		status := t.rec.Status
+		state.ExecutedRatio = math.Min(state.Ratio, state.ExecutedRatio+executed)
+		state.RemainingRatio = math.Max(0, state.Ratio-state.ExecutedRatio)
+		state.LastEvent = changedFieldManualTier
+		state.PendingOrderID = ""
+		state.PendingSince = 0
+		if state.RemainingRatio <= tierRatioTolerance {
+			state.RemainingRatio = 0
+			state.Status = "done"
+			status = database.StrategyStatusDone
+		} else {
+			state.Status = "waiting"
+			status = database.StrategyStatusWaiting
+		}
+		inst := &exit.PlanInstance{Record: t.rec}
+		newState := exit.EncodeTierComponentState(state)
+		if !s.repo.PersistPlanState(ctx, inst, newState, status) {
+			return changes, fmt.Errorf("更新组件 %s 失败", t.rec.PlanComponent)
+		}
+		s.repo.LogManualChange(ctx, inst, changedFieldManualTier, oldState, source,
+			manualTierReason(t.rec.PlanComponent, state, reason))
+		changes = append(changes, interfaces.TierCompletionChange{
+			Component:      t.rec.PlanComponent,
+			Status:         state.Status,
+			ExecutedRatio:  state.ExecutedRatio,
+			RemainingRatio: state.RemainingRatio,
+		})
+	}
+	if root != nil && spec.OpenRatio >= 0 {
+		s.alignRootRemaining(ctx, *root, spec.OpenRatio, source, reason)
+	}
+	if len(changes) > 0 {
+		logger.Infof("PlanScheduler: 人工标记 tier 完成 trade=%d plan=%s changes=%d source=%s", spec.TradeID, planID, len(changes), source)
+		s.notifyManualTiers(spec.TradeID, planID, changes, source, reason)
+	}
+	s.rebuildTrade(ctx, spec.TradeID)
+	return changes, nil
+}
+
+// alignRootRemaining 将根状态的 remaining_ratio 覆盖为交易所回报值。
+func (s *PlanScheduler) alignRootRemaining(ctx context.Context, rec database.StrategyInstanceRecord, openRatio float64, source, reason string) {
+	state, err := exit.DecodeTierPlanState(rec.StateJSON)
+	if err != nil {
+		logger.Warnf("PlanScheduler: 解析根状态失败 trade=%d plan=%s err=%v", rec.TradeID, rec.PlanID, err)
+		return
+	}
+	if math.Abs(state.RemainingRatio-openRatio) <= tierRatioTolerance {
+		return
+	}
+	old := state.RemainingRatio
+	oldState := rec.StateJSON
+	state.RemainingRatio = openRatio
+	state.LastUpdatedAt = time.Now().Unix()
+	inst := &exit.PlanInstance{Record: rec}
+	if !s.repo.PersistPlanState(ctx, inst, exit.EncodeTierPlanState(state), rec.Status) {
+		return
+	}
+	msg := fmt.Sprintf("剩余仓位比例 %.4f → %.4f（对齐交易所回报）", old, openRatio)
+	if reason != "" {
+		msg += "；" + reason
+	}
+	s.repo.LogManualChange(ctx, inst, changedFieldManualRemain, oldState, source, msg)
+}
+
+func (s *PlanScheduler) notifyManualTiers(tradeID int, planID string, changes []interfaces.TierCompletionChange, source, reason string) {
+	if s.notifier == nil {
+		return
+	}
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("%s → %s (executed=%.4f remain=%.4f)", c.Component, c.Status, c.ExecutedRatio, c.RemainingRatio))
+	}
+	msg := fmt.Sprintf("🛠 人工标记 tier 完成 (TradeID %d)\nPlan %s\n来源: %s\n\n%s", tradeID, planID, source, strings.Join(lines, "\n"))
+	if reason != "" {
+		msg += "\n原因: " + reason
+	}
+	if err := s.notifier.SendText(msg); err != nil {
+		logger.Warnf("Telegram 推送失败(manual_tier): %v", err)
+	}
+}
+
+// resolveTierPlanID 未指定 plan_id 时，仅当该交易恰有一个含 tier 组件的 plan 才自动选择。
+func resolveTierPlanID(recs []database.StrategyInstanceRecord, planID string) (string, error) {
+	if planID != "" {
+		return planID, nil
+	}
+	seen := make(map[string]struct{})
+	for _, rec := range recs {
+		if _, ok := tierIndex(rec.PlanComponent); ok {
+			seen[strings.TrimSpace(rec.PlanID)] = struct{}{}
+		}
+	}
+	switch len(seen) {
+	case 0:
+		return "", fmt.Errorf("该交易无 tier 组件")
+	case 1:
+		for id := range seen {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("存在多个 tier plan，需指定 plan_id")
+}
+
+// explicitTierTargets 指定组件整段完成；已完成的组件视为错误，避免重复操作被静默吞掉。
+func explicitTierTargets(tiers []manualTier, components []string) (map[string]float64, error) {
+	byName := make(map[string]manualTier, len(tiers))
+	for _, t := range tiers {
+		byName[strings.ToLower(t.rec.PlanComponent)] = t
+	}
+	out := make(map[string]float64, len(components))
+	for _, raw := range components {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		if name == "" {
+			continue
+		}
+		t, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("未找到组件: %s", raw)
+		}
+		if t.rec.Status == database.StrategyStatusDone {
+			return nil, fmt.Errorf("组件 %s 已完成", t.rec.PlanComponent)
+		}
+		out[t.rec.PlanComponent] = tierOutstanding(t)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("components 为空")
+	}
+	return out, nil
+}
+
+// ratioTierTargets 按段位顺序分摊 closed 中尚未被本地记录的部分，最后一段允许部分完成。
+func ratioTierTargets(tiers []manualTier, closed float64) map[string]float64 {
+	recorded := 0.0
+	for _, t := range tiers {
+		recorded += tierExecuted(t)
+	}
+	need := closed - recorded
+	out := make(map[string]float64)
+	for _, t := range tiers {
+		if need <= tierRatioTolerance {
+			break
+		}
+		if t.rec.Status == database.StrategyStatusDone {
+			continue
+		}
+		portion := math.Min(tierOutstanding(t), need)
+		if portion <= 0 {
+			continue
+		}
+		out[t.rec.PlanComponent] = portion
+		need -= portion
+	}
+	return out
+}
+
+// tierExecuted 正常触发的段位只把记录置为 done 而不写 executed_ratio，此时按整段计。
+func tierExecuted(t manualTier) float64 {
+	if t.rec.Status == database.StrategyStatusDone {
+		return t.state.Ratio
+	}
+	return t.state.ExecutedRatio
+}
+
+func tierOutstanding(t manualTier) float64 {
+	return math.Max(0, t.state.Ratio-t.state.ExecutedRatio)
+}
+
+// tierIndex 解析 tierN / xxx.tierN 形式的组件名，返回段位序号。
+func tierIndex(component string) (int, bool) {
+	name := strings.TrimSpace(component)
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		name = name[idx+1:]
+	}
+	if !strings.HasPrefix(strings.ToLower(name), "tier") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(name[len("tier"):])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func manualTierReason(component string, state exit.TierComponentState, reason string) string {
+	msg := fmt.Sprintf("人工标记 %s %s：已执行 %.4f / %.4f", component, state.Status, state.ExecutedRatio, state.Ratio)
+	if reason != "" {
+		msg += "；" + reason
+	}
+	return msg
+}
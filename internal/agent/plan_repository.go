@@ -190,6 +190,28 @@ func (r *PlanRepository) LogStateChange(ctx context.Context, inst *exit.PlanInst
 	}
 }
 
+// LogManualChange 记录人工操作导致的状态变更，reason 原样写入，不走自动 diff 描述。
+func (r *PlanRepository) LogManualChange(ctx context.Context, inst *exit.PlanInstance, field, oldState, source, reason string) {
+	if r == nil || r.store == nil || inst == nil {
+		return
+	}
+	rec := database.StrategyChangeLogRecord{
+		TradeID:         inst.Record.TradeID,
+		InstanceID:      inst.Record.ID,
+		PlanID:          inst.Record.PlanID,
+		PlanComponent:   inst.Record.PlanComponent,
+		ChangedField:    field,
+		OldValue:        normalizeStateJSON(oldState),
+		NewValue:        normalizeStateJSON(inst.Record.StateJSON),
+		TriggerSource:   source,
+		Reason:          reason,
+		DecisionTraceID: inst.Record.DecisionTraceID,
+	}
+	if err := r.store.InsertStrategyChangeLog(ctx, rec); err != nil {
+		logger.Warnf("PlanRepository: 写 strategy_change_log 失败 trade=%d plan=%s err=%v", inst.Record.TradeID, inst.Record.PlanID, err)
+	}
+}
+
 func (r *PlanRepository) LogTradeOperation(ctx context.Context, inst *exit.PlanInstance, evt *exit.PlanEvent) {
 	if r == nil || r.store == nil || inst == nil || evt == nil {
 		return
@@ -531,6 +553,8 @@ func operationFromEvent(inst *exit.PlanInstance, evt *exit.PlanEvent) database.O
 		return database.OperationStopLoss
 	case exit.PlanEventTypeFinalStopLoss:
 		return database.OperationFinalStop
+	case exit.PlanEventTypeTimeExit:
+		return database.OperationTimeExit
 	default:
 		return 0
 	}
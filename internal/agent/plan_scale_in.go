@@ -0,0 +1,452 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	brcfg "brale/internal/config"
+	"brale/internal/gateway/database"
+	"brale/internal/gateway/exchange"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/strategy/exit"
+)
+
+const (
+	scaleInSource = "scale_in"
+
+	scaleInEventPlan   = "scale_in_plan"
+	scaleInEventFill   = "scale_in_fill"
+	scaleInEventFailed = "scale_in_failed"
+
+	scaleInRestoreLimit = 200
+)
+
+var _ exchange.ScaleInHook = (*PlanScheduler)(nil)
+
+// scaleInExecutor 由执行管理器实现：对已有交易追加入场。
+type scaleInExecutor interface {
+	ScaleInFreqtradePosition(ctx context.Context, tradeID int, symbol, side string, stake, leverage float64) error
+}
+
+// scaleInGate 由 LiveEngine 实现：加仓与新开仓经过同一组闸门，返回放行后的保证金。
+type scaleInGate interface {
+	AdmitScaleIn(ctx context.Context, tradeID int, symbol, side string, stake, leverage float64) (float64, error)
+}
+
+type tradeOperationReader interface {
+	ListTradeOperations(ctx context.Context, freqtradeID int, limit int) ([]database.TradeOperationRecord, error)
+}
+
+// scaleInState 为一笔交易的加仓进度；Stake 为累计保证金，AvgEntry 为按成交价加权的均价。
+type scaleInState struct {
+	symbol   string
+	side     string
+	leverage float64
+	stake    float64
+	avgEntry float64
+	levels   []scaleInLevel
+}
+
+type scaleInLevel struct {
+	Price  float64 `json:"price"`
+	Stake  float64 `json:"stake_usd"`
+	Status string  `json:"status,omitempty"`
+}
+
+func (l scaleInLevel) done() bool {
+	return l.Status != ""
+}
+
+// SetScaleIn 启用分批加仓监控。
+func (s *PlanScheduler) SetScaleIn(cfg brcfg.ScaleInConfig) {
+	if s == nil {
+		return
+	}
+	s.scaleInCfg = cfg
+	if cfg.Enabled {
+		logger.Infof("PlanScheduler: 分批加仓已启用 max_entries=%d", cfg.MaxEntries)
+	}
+}
+
+// SetScaleInGate 设置加仓前的开仓闸门（熔断、暂停、临近交割、相关性、组合风控）；被拦截的档位记为失败，不再重试。
+func (s *PlanScheduler) SetScaleInGate(g scaleInGate) {
+	if s == nil {
+		return
+	}
+	s.scaleInGate = g
+}
+
+// RegisterScaleIn 在开仓成交后登记加仓价位：ATR 偏移换算为绝对价格，丢弃方向错误的档位，按距开仓价由近到远保留 MaxEntries 档，
+// 计划写入 trade_operation_log 以便重启后恢复。
+func (s *PlanScheduler) RegisterScaleIn(ctx context.Context, reg exchange.ScaleInRegistration) {
+	if s == nil || reg.TradeID <= 0 || len(reg.Levels) == 0 {
+		return
+	}
+	if !s.scaleInCfg.Enabled {
+		logger.Infof("PlanScheduler: 分批加仓未启用，忽略 scale_in trade=%d %s", reg.TradeID, reg.Symbol)
+		return
+	}
+	side := strings.ToLower(strings.TrimSpace(reg.Side))
+	symbol := strings.ToUpper(strings.TrimSpace(reg.Symbol))
+	if reg.EntryPrice <= 0 || reg.Stake <= 0 || (side != "long" && side != "short") {
+		logger.Warnf("PlanScheduler: 加仓登记参数无效 trade=%d %s side=%s entry=%.6f stake=%.4f", reg.TradeID, symbol, side, reg.EntryPrice, reg.Stake)
+		return
+	}
+	atr := s.scaleInATR(reg.TradeID, symbol)
+	levels := make([]scaleInLevel, 0, len(reg.Levels))
+	for _, lv := range reg.Levels {
+		price := lv.Price
+		if price <= 0 && lv.ATROffset > 0 && atr > 0 {
+			if side == "short" {
+				price = reg.EntryPrice + lv.ATROffset*atr
+			} else {
+				price = reg.EntryPrice - lv.ATROffset*atr
+			}
+		}
+		if price <= 0 || (side == "long" && price >= reg.EntryPrice) || (side == "short" && price <= reg.EntryPrice) {
+			logger.Warnf("PlanScheduler: 忽略无效加仓价位 trade=%d %s side=%s entry=%.6f price=%.6f atr_offset=%.2f atr=%.6f",
+				reg.TradeID, symbol, side, reg.EntryPrice, lv.Price, lv.ATROffset, atr)
+			continue
+		}
+		stake := lv.SizeUSD
+		if stake <= 0 {
+			stake = reg.Stake
+		}
+		levels = append(levels, scaleInLevel{Price: price, Stake: stake})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return math.Abs(levels[i].Price-reg.EntryPrice) < math.Abs(levels[j].Price-reg.EntryPrice)
+	})
+	if max := s.scaleInCfg.MaxEntries; max > 0 && len(levels) > max {
+		levels = levels[:max]
+	}
+	if len(levels) == 0 {
+		return
+	}
+	state := &scaleInState{
+		symbol:   symbol,
+		side:     side,
+		leverage: reg.Leverage,
+		stake:    reg.Stake,
+		avgEntry: reg.EntryPrice,
+		levels:   levels,
+	}
+	s.scaleInMu.Lock()
+	if s.scaleIns == nil {
+		s.scaleIns = make(map[int]*scaleInState)
+	}
+	s.scaleIns[reg.TradeID] = state
+	s.scaleInMu.Unlock()
+
+	s.appendScaleInOperation(ctx, reg.TradeID, symbol, database.OperationScaleIn, map[string]any{
+		"event_type":  scaleInEventPlan,
+		"side":        side,
+		"entry_price": reg.EntryPrice,
+		"stake":       reg.Stake,
+		"leverage":    reg.Leverage,
+		"trace_id":    reg.TraceID,
+		"levels":      levels,
+	})
+	logger.Infof("PlanScheduler: 登记加仓 trade=%d %s side=%s entry=%.6f levels=%d", reg.TradeID, symbol, side, reg.EntryPrice, len(levels))
+}
+
+// checkScaleIns 在 tier 评估之后运行：价格到达加仓价位时追加入场，重算均价并按比例平移仍在等待的分段价位。
+// 每个 tick 每笔交易最多执行一档；有 pending 的计划先等待其完成。
+func (s *PlanScheduler) checkScaleIns(ctx context.Context, watchers []*planWatcher, price float64) {
+	if !s.scaleInCfg.Enabled || price <= 0 {
+		return
+	}
+	byTrade := make(map[int][]*planWatcher)
+	for _, w := range watchers {
+		if w != nil {
+			byTrade[w.tradeID] = append(byTrade[w.tradeID], w)
+		}
+	}
+	for tradeID, list := range byTrade {
+		pending := false
+		for _, w := range list {
+			if watcherHasPending(w) {
+				pending = true
+				break
+			}
+		}
+		if pending {
+			continue
+		}
+		state := s.scaleInStateFor(ctx, tradeID)
+		if state == nil {
+			continue
+		}
+		if s.executeScaleIn(ctx, tradeID, state, list, price) {
+			s.rebuildTrade(ctx, tradeID)
+		}
+	}
+}
+
+func (s *PlanScheduler) executeScaleIn(ctx context.Context, tradeID int, state *scaleInState, watchers []*planWatcher, price float64) bool {
+	s.scaleInMu.Lock()
+	idx := -1
+	for i, lv := range state.levels {
+		if lv.done() {
+			continue
+		}
+		if (state.side == "long" && price <= lv.Price) || (state.side == "short" && price >= lv.Price) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		s.scaleInMu.Unlock()
+		return false
+	}
+	// 先标记再下单，避免下单期间的后续 tick 重复触发。
+	level := state.levels[idx]
+	state.levels[idx].Status = "submitted"
+	s.scaleInMu.Unlock()
+
+	execMgr, ok := s.execManager.(scaleInExecutor)
+	if !ok {
+		s.failScaleIn(ctx, tradeID, state, idx, price, fmt.Errorf("执行端不支持加仓"))
+		return false
+	}
+	if s.scaleInGate != nil {
+		stake, err := s.scaleInGate.AdmitScaleIn(ctx, tradeID, state.symbol, state.side, level.Stake, state.leverage)
+		if err != nil {
+			s.failScaleIn(ctx, tradeID, state, idx, price, err)
+			return false
+		}
+		level.Stake = stake
+	}
+	if err := execMgr.ScaleInFreqtradePosition(ctx, tradeID, state.symbol, state.side, level.Stake, state.leverage); err != nil {
+		s.failScaleIn(ctx, tradeID, state, idx, price, err)
+		return false
+	}
+
+	s.scaleInMu.Lock()
+	prevAvg := state.avgEntry
+	qty := state.stake/prevAvg + level.Stake/price
+	state.stake += level.Stake
+	state.avgEntry = state.stake / qty
+	state.levels[idx].Status = "filled"
+	newAvg := state.avgEntry
+	totalStake := state.stake
+	s.scaleInMu.Unlock()
+
+	s.appendScaleInOperation(ctx, tradeID, state.symbol, database.OperationScaleIn, map[string]any{
+		"event_type":     scaleInEventFill,
+		"level":          idx + 1,
+		"level_price":    level.Price,
+		"price":          price,
+		"stake_usd":      level.Stake,
+		"total_stake":    totalStake,
+		"prev_avg_entry": prevAvg,
+		"avg_entry":      newAvg,
+	})
+	logger.Infof("PlanScheduler: 加仓 trade=%d %s level=%d price=%.6f stake=%.4f avg %.6f -> %.6f",
+		tradeID, state.symbol, idx+1, price, level.Stake, prevAvg, newAvg)
+	adjusted := s.shiftTierTargets(ctx, watchers, prevAvg, newAvg)
+	if s.notifier != nil {
+		msg := fmt.Sprintf("➕ 加仓：%s %s (TradeID %d)\n第 %d 档 价格 %.6f 保证金 %.2f\n均价 %.6f → %.6f，调整分段 %d 个",
+			state.symbol, strings.ToUpper(state.side), tradeID, idx+1, price, level.Stake, prevAvg, newAvg, adjusted)
+		if err := notifier.SendCategoryText(s.notifier, notifier.CategoryOpen, msg); err != nil {
+			logger.Warnf("Telegram 推送失败(scale_in): %v", err)
+		}
+	}
+	return adjusted > 0
+}
+
+func (s *PlanScheduler) failScaleIn(ctx context.Context, tradeID int, state *scaleInState, idx int, price float64, err error) {
+	s.scaleInMu.Lock()
+	state.levels[idx].Status = "failed"
+	level := state.levels[idx]
+	s.scaleInMu.Unlock()
+	logger.Warnf("PlanScheduler: 加仓失败 trade=%d %s level=%d price=%.6f err=%v", tradeID, state.symbol, idx+1, price, err)
+	s.appendScaleInOperation(ctx, tradeID, state.symbol, database.OperationFailed, map[string]any{
+		"event_type":  scaleInEventFailed,
+		"level":       idx + 1,
+		"level_price": level.Price,
+		"price":       price,
+		"stake_usd":   level.Stake,
+		"error":       err.Error(),
+	})
+}
+
+// shiftTierTargets 把仍在等待的分段价位按 新均价/旧均价 等比平移，并同步组件记录的开仓价，返回调整的组件数。
+func (s *PlanScheduler) shiftTierTargets(ctx context.Context, watchers []*planWatcher, prevAvg, newAvg float64) int {
+	if s.executor == nil || prevAvg <= 0 || newAvg <= 0 {
+		return 0
+	}
+	ratio := newAvg / prevAvg
+	adjusted := 0
+	for _, w := range watchers {
+		keys := make([]string, 0, len(w.components))
+		for k := range w.components {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, comp := range keys {
+			inst := w.components[comp]
+			if inst == nil || inst.Record.Status != database.StrategyStatusWaiting {
+				continue
+			}
+			state, err := exit.DecodeTierComponentState(inst.Record.StateJSON)
+			if err != nil || state.TargetPrice <= 0 {
+				continue
+			}
+			params := map[string]any{
+				"target_price": state.TargetPrice * ratio,
+				"entry_price":  newAvg,
+			}
+			if _, err := s.executor.HandleAdjust(ctx, w, comp, params, scaleInSource); err != nil {
+				logger.Warnf("PlanScheduler: 加仓后调整分段失败 trade=%d plan=%s component=%s err=%v", w.tradeID, w.planID, comp, err)
+				continue
+			}
+			adjusted++
+		}
+	}
+	return adjusted
+}
+
+// scaleInStateFor 返回交易的加仓进度；内存中没有时从 trade_operation_log 恢复一次（无计划也会记录，避免重复查询）。
+func (s *PlanScheduler) scaleInStateFor(ctx context.Context, tradeID int) *scaleInState {
+	s.scaleInMu.Lock()
+	state, ok := s.scaleIns[tradeID]
+	s.scaleInMu.Unlock()
+	if ok {
+		return state
+	}
+	state = s.restoreScaleIn(ctx, tradeID)
+	s.scaleInMu.Lock()
+	defer s.scaleInMu.Unlock()
+	if s.scaleIns == nil {
+		s.scaleIns = make(map[int]*scaleInState)
+	}
+	if existing, ok := s.scaleIns[tradeID]; ok {
+		return existing
+	}
+	s.scaleIns[tradeID] = state
+	return state
+}
+
+func (s *PlanScheduler) restoreScaleIn(ctx context.Context, tradeID int) *scaleInState {
+	if s.repo == nil {
+		return nil
+	}
+	reader, ok := s.repo.store.(tradeOperationReader)
+	if !ok {
+		return nil
+	}
+	recs, err := reader.ListTradeOperations(ctx, tradeID, scaleInRestoreLimit)
+	if err != nil {
+		logger.Warnf("PlanScheduler: 读取加仓记录失败 trade=%d err=%v", tradeID, err)
+		return nil
+	}
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].Timestamp.Before(recs[j].Timestamp) })
+	var state *scaleInState
+	for _, rec := range recs {
+		eventType, _ := rec.Details["event_type"].(string)
+		switch eventType {
+		case scaleInEventPlan:
+			state = decodeScaleInPlan(rec)
+		case scaleInEventFill, scaleInEventFailed:
+			if state == nil {
+				continue
+			}
+			idx, _ := extractExecutorFloat(rec.Details, "level")
+			if i := int(idx) - 1; i >= 0 && i < len(state.levels) {
+				state.levels[i].Status = "failed"
+				if eventType == scaleInEventFill {
+					state.levels[i].Status = "filled"
+				}
+			}
+			if avg, ok := extractExecutorFloat(rec.Details, "avg_entry"); ok && avg > 0 {
+				state.avgEntry = avg
+			}
+			if total, ok := extractExecutorFloat(rec.Details, "total_stake"); ok && total > 0 {
+				state.stake = total
+			}
+		}
+	}
+	if state != nil {
+		logger.Infof("PlanScheduler: 恢复加仓进度 trade=%d %s levels=%d avg=%.6f", tradeID, state.symbol, len(state.levels), state.avgEntry)
+	}
+	return state
+}
+
+func decodeScaleInPlan(rec database.TradeOperationRecord) *scaleInState {
+	state := &scaleInState{symbol: strings.ToUpper(strings.TrimSpace(rec.Symbol))}
+	state.side, _ = rec.Details["side"].(string)
+	state.avgEntry, _ = extractExecutorFloat(rec.Details, "entry_price")
+	state.stake, _ = extractExecutorFloat(rec.Details, "stake")
+	state.leverage, _ = extractExecutorFloat(rec.Details, "leverage")
+	raw, _ := rec.Details["levels"].([]any)
+	for _, item := range raw {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		price, _ := extractExecutorFloat(m, "price")
+		stake, _ := extractExecutorFloat(m, "stake_usd")
+		if price > 0 && stake > 0 {
+			state.levels = append(state.levels, scaleInLevel{Price: price, Stake: stake})
+		}
+	}
+	if state.avgEntry <= 0 || state.stake <= 0 || len(state.levels) == 0 {
+		return nil
+	}
+	return state
+}
+
+func (s *PlanScheduler) appendScaleInOperation(ctx context.Context, tradeID int, symbol string, op database.OperationType, details map[string]any) {
+	if s.repo == nil {
+		return
+	}
+	appender, ok := s.repo.store.(tradeOperationStore)
+	if !ok {
+		return
+	}
+	rec := database.TradeOperationRecord{
+		FreqtradeID: tradeID,
+		Symbol:      symbol,
+		Operation:   op,
+		Details:     details,
+		Timestamp:   time.Now(),
+	}
+	if err := appender.AppendTradeOperation(ctx, rec); err != nil {
+		logger.Warnf("PlanScheduler: 写 trade_operation_log 失败(scale_in) trade=%d err=%v", tradeID, err)
+	}
+}
+
+// scaleInATR 优先使用行情服务的最新 ATR，缺失时取该交易计划中的 atr_value。
+func (s *PlanScheduler) scaleInATR(tradeID int, symbol string) float64 {
+	if s.atrSource != nil {
+		if atr, ok := s.atrSource(symbol); ok && atr > 0 {
+			return atr
+		}
+	}
+	s.mu.RLock()
+	watchers := append([]*planWatcher(nil), s.tradeIndex[tradeID]...)
+	s.mu.RUnlock()
+	for _, w := range watchers {
+		if atr := s.trailingATR(w); atr > 0 {
+			return atr
+		}
+	}
+	return 0
+}
+
+// pruneScaleIns 清理已不活跃交易的加仓进度。
+func (s *PlanScheduler) pruneScaleIns(active map[int]struct{}) {
+	s.scaleInMu.Lock()
+	defer s.scaleInMu.Unlock()
+	for tradeID := range s.scaleIns {
+		if _, ok := active[tradeID]; !ok {
+			delete(s.scaleIns, tradeID)
+		}
+	}
+}
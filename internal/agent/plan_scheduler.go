@@ -11,11 +11,13 @@ import (
 
 	"brale/internal/agent/interfaces"
 	"brale/internal/agent/ports"
+	brcfg "brale/internal/config"
 	"brale/internal/decision"
 	"brale/internal/exitplan"
 	"brale/internal/gateway/database"
 	"brale/internal/gateway/exchange"
 	"brale/internal/gateway/notifier"
+	"brale/internal/gateway/webhook"
 	"brale/internal/logger"
 	"brale/internal/pkg/utils"
 	"brale/internal/strategy/exit"
@@ -28,11 +30,13 @@ const (
 	planPriceBufferSize        = 1024
 	priceDebounceInterval      = 1 * time.Second
 
-	defaultStrategyPendingTimeout      = 12 * time.Minute
+	defaultStrategyPendingTimeout       = 12 * time.Minute
 	defaultStrategyPendingSweepInterval = 1 * time.Minute
 
 	defaultInactiveTradeSweepInterval = 10 * time.Second
 	defaultInactiveTradeMissThreshold = 2
+
+	defaultTimeExitSweepInterval = 1 * time.Minute
 )
 
 type PlanSchedulerParams struct {
@@ -45,6 +49,8 @@ type PlanSchedulerParams struct {
 	PendingTimeout  time.Duration
 	PendingSweep    time.Duration
 	DisableDebounce bool
+	PriceGuard      *PriceGuard
+	Webhooks        *webhook.Dispatcher
 }
 
 var _ exchange.PlanUpdateHook = (*PlanScheduler)(nil)
@@ -79,6 +85,20 @@ type PlanScheduler struct {
 
 	lastPriceMu   sync.Mutex
 	lastPriceTime map[string]time.Time
+	lastPrice     map[string]float64
+
+	trailing  brcfg.TrailingStopConfig
+	atrSource func(symbol string) (float64, bool)
+
+	scaleInCfg brcfg.ScaleInConfig
+	// scaleInGate 为加仓前的开仓闸门，nil 表示不校验。
+	scaleInGate scaleInGate
+	scaleInMu   sync.Mutex
+	scaleIns    map[int]*scaleInState
+
+	breakeven     brcfg.AutoBreakevenConfig
+	breakevenMu   sync.Mutex
+	breakevenDone map[int]bool
 }
 
 type priceTick struct {
@@ -115,10 +135,13 @@ func NewPlanScheduler(params PlanSchedulerParams) *PlanScheduler {
 		tradeIndex:      make(map[int][]*planWatcher),
 		pruneMisses:     make(map[int]int),
 		lastPriceTime:   make(map[string]time.Time),
+		lastPrice:       make(map[string]float64),
 		disableDebounce: params.DisableDebounce,
 	}
 
 	s.executor = NewPlanExecutor(repo, params.ExecManager, s.rebuildTrade)
+	s.executor.SetPriceGuard(params.PriceGuard)
+	s.executor.SetWebhooks(params.Webhooks)
 	return s
 }
 
@@ -127,6 +150,7 @@ func (s *PlanScheduler) Start(ctx context.Context) {
 		return
 	}
 	s.startOnce.Do(func() {
+		s.drainPriceTicks()
 		go s.refreshLoop(ctx)
 		go s.pendingLoop(ctx)
 		go s.pruneLoop(ctx)
@@ -134,6 +158,17 @@ func (s *PlanScheduler) Start(ctx context.Context) {
 	})
 }
 
+// drainPriceTicks 丢弃启动前积压的价格（如 standby 期间），避免以过期价格触发退出。
+func (s *PlanScheduler) drainPriceTicks() {
+	for {
+		select {
+		case <-s.priceCh:
+		default:
+			return
+		}
+	}
+}
+
 func (s *PlanScheduler) NotifyPrice(symbol string, price float64) {
 	if s == nil || price <= 0 {
 		return
@@ -201,6 +236,7 @@ func (s *PlanScheduler) pruneInactiveTrades(ctx context.Context, missThreshold i
 		return
 	}
 
+	s.pruneScaleIns(active)
 	missingActive := s.pruneInactiveLocked(active, missThreshold)
 	for _, id := range missingActive {
 		s.rebuildTrade(ctx, id)
@@ -436,16 +472,43 @@ func (s *PlanScheduler) priceLoop(ctx context.Context) {
 	if s == nil {
 		return
 	}
+	timeTicker := time.NewTicker(defaultTimeExitSweepInterval)
+	defer timeTicker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case tick := <-s.priceCh:
 			s.handlePriceTick(ctx, tick)
+		case now := <-timeTicker.C:
+			s.sweepTimeExits(ctx, now)
 		}
 	}
 }
 
+// sweepTimeExits 定时巡检全部持仓的时间退出（最长持仓、收盘前平仓）；与价格评估同在 priceLoop 中串行执行，
+// 避免同一 trade 的价格触发与时间触发并发平仓。
+func (s *PlanScheduler) sweepTimeExits(ctx context.Context, now time.Time) {
+	if s.executor == nil {
+		return
+	}
+	s.mu.RLock()
+	watchers := make([]*planWatcher, 0, len(s.tradeIndex))
+	for _, list := range s.tradeIndex {
+		watchers = append(watchers, list...)
+	}
+	s.mu.RUnlock()
+	for _, watcher := range watchers {
+		if watcher == nil {
+			continue
+		}
+		s.lastPriceMu.Lock()
+		price := s.lastPrice[watcher.symbol]
+		s.lastPriceMu.Unlock()
+		s.executor.EvaluateTime(ctx, watcher, now, price)
+	}
+}
+
 func (s *PlanScheduler) NotifyPlanUpdated(ctx context.Context, tradeID int) {
 	if s == nil {
 		return
@@ -533,6 +596,10 @@ func (s *PlanScheduler) rebuildTrade(ctx context.Context, tradeID int) {
 	watchers := []*planWatcher(nil)
 	if !allDone {
 		watchers = s.repo.BuildWatchers(recs)
+	} else {
+		s.breakevenMu.Lock()
+		delete(s.breakevenDone, tradeID)
+		s.breakevenMu.Unlock()
 	}
 	s.mu.Lock()
 	if s.symbolIndex == nil {
@@ -555,6 +622,9 @@ func (s *PlanScheduler) rebuildTrade(ctx context.Context, tradeID int) {
 }
 
 func (s *PlanScheduler) handlePriceTick(ctx context.Context, tick priceTick) {
+	s.lastPriceMu.Lock()
+	s.lastPrice[tick.symbol] = tick.price
+	s.lastPriceMu.Unlock()
 	s.mu.RLock()
 	watchers := append([]*planWatcher(nil), s.symbolIndex[tick.symbol]...)
 	s.mu.RUnlock()
@@ -567,6 +637,9 @@ func (s *PlanScheduler) handlePriceTick(ctx context.Context, tick priceTick) {
 	for _, watcher := range watchers {
 		s.executor.EvaluateWatcher(ctx, watcher, tick.price)
 	}
+	s.autoBreakeven(ctx, watchers, tick.price)
+	s.trailStops(ctx, watchers, tick.price)
+	s.checkScaleIns(ctx, watchers, tick.price)
 }
 
 func (s *PlanScheduler) removeTradeLocked(tradeID int) {
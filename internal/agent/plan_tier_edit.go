@@ -0,0 +1,245 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"brale/internal/agent/interfaces"
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+	"brale/internal/strategy/exit"
+)
+
+const changedFieldManualEdit = "manual_tier_edit"
+
+type editedTier struct {
+	rec      database.StrategyInstanceRecord
+	state    exit.TierComponentState
+	old      exit.TierComponentState
+	order    int
+	mode     string
+	modified bool
+}
+
+// EditTiers 人工修改持仓的 tier 目标价与比例（止盈、止损段位均为 tier 组件）。
+// 全部段位先按方向、顺序、比例和与最小止损距离校验，任一失败则不写入；通过后逐个持久化并写
+// strategy_change_log，最后一次性刷新该交易的 watcher，监控下一次报价即使用新价位。
+func (s *PlanScheduler) EditTiers(ctx context.Context, spec interfaces.TierEditSpec) ([]interfaces.TierEditChange, error) {
+	if s == nil || s.repo == nil {
+		return nil, fmt.Errorf("plan scheduler 未初始化")
+	}
+	if spec.TradeID <= 0 {
+		return nil, fmt.Errorf("trade_id 必填")
+	}
+	if len(spec.Levels) == 0 {
+		return nil, fmt.Errorf("levels 为空")
+	}
+	recs, err := s.repo.ListStrategyInstances(ctx, spec.TradeID)
+	if err != nil {
+		return nil, fmt.Errorf("读取策略实例失败: %w", err)
+	}
+	planID, err := resolveTierPlanID(recs, strings.TrimSpace(spec.PlanID))
+	if err != nil {
+		return nil, err
+	}
+	tiers, err := loadEditableTiers(recs, planID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTierEdits(tiers, spec.Levels); err != nil {
+		return nil, err
+	}
+	if err := validateTierEdits(tiers, spec.MinStopDistancePct); err != nil {
+		return nil, err
+	}
+
+	source := strings.TrimSpace(spec.Source)
+	if source == "" {
+		source = "operator"
+	}
+	reason := strings.TrimSpace(spec.Reason)
+	var changes []interfaces.TierEditChange
+	for _, t := range tiers {
+		if !t.modified {
+			continue
+		}
+		oldState := t.rec.StateJSON
+		inst := &exit.PlanInstance{Record: t.rec}
+		if !s.repo.PersistPlanState(ctx, inst, exit.EncodeTierComponentState(t.state), database.StrategyStatusWaiting) {
+			s.rebuildTrade(ctx, spec.TradeID)
+			return changes, fmt.Errorf("更新组件 %s 失败", t.rec.PlanComponent)
+		}
+		change := interfaces.TierEditChange{
+			Component:      t.rec.PlanComponent,
+			Mode:           t.mode,
+			OldTargetPrice: t.old.TargetPrice,
+			TargetPrice:    t.state.TargetPrice,
+			OldRatio:       t.old.Ratio,
+			Ratio:          t.state.Ratio,
+		}
+		s.repo.LogManualChange(ctx, inst, changedFieldManualEdit, oldState, source, manualEditReason(change, reason))
+		changes = append(changes, change)
+	}
+	if len(changes) > 0 {
+		logger.Infof("PlanScheduler: 人工修改 tier trade=%d plan=%s changes=%d source=%s", spec.TradeID, planID, len(changes), source)
+		s.notifyTierEdits(spec.TradeID, planID, changes, source, reason)
+	}
+	s.rebuildTrade(ctx, spec.TradeID)
+	return changes, nil
+}
+
+func loadEditableTiers(recs []database.StrategyInstanceRecord, planID string) ([]*editedTier, error) {
+	var tiers []*editedTier
+	for _, rec := range recs {
+		if strings.TrimSpace(rec.PlanID) != planID {
+			continue
+		}
+		order, ok := tierIndex(rec.PlanComponent)
+		if !ok {
+			continue
+		}
+		state, err := exit.DecodeTierComponentState(rec.StateJSON)
+		if err != nil {
+			return nil, fmt.Errorf("解析组件 %s 状态失败: %w", rec.PlanComponent, err)
+		}
+		mode := strings.ToLower(strings.TrimSpace(state.Mode))
+		if mode == "" {
+			mode = "take_profit"
+		}
+		tiers = append(tiers, &editedTier{rec: rec, state: state, old: state, order: order, mode: mode})
+	}
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("plan %s 无 tier 组件", planID)
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		ai, aj := componentAlias(tiers[i].rec.PlanComponent), componentAlias(tiers[j].rec.PlanComponent)
+		if ai != aj {
+			return ai < aj
+		}
+		return tiers[i].order < tiers[j].order
+	})
+	return tiers, nil
+}
+
+// applyTierEdits 把请求写入内存中的段位状态；已触发或已完成的段位不允许修改。
+func applyTierEdits(tiers []*editedTier, levels []interfaces.TierEditLevel) error {
+	byName := make(map[string]*editedTier, len(tiers))
+	for _, t := range tiers {
+		byName[strings.ToLower(strings.TrimSpace(t.rec.PlanComponent))] = t
+	}
+	for _, lvl := range levels {
+		name := strings.ToLower(strings.TrimSpace(lvl.Component))
+		t, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("未找到组件: %s", lvl.Component)
+		}
+		if t.rec.Status != database.StrategyStatusWaiting {
+			return fmt.Errorf("组件 %s 已触发或完成，无法修改", t.rec.PlanComponent)
+		}
+		if lvl.TargetPrice < 0 {
+			return fmt.Errorf("组件 %s target_price 非法", t.rec.PlanComponent)
+		}
+		if lvl.Ratio < 0 || lvl.Ratio > 1 {
+			return fmt.Errorf("组件 %s ratio 需在 (0,1]", t.rec.PlanComponent)
+		}
+		if lvl.TargetPrice > 0 {
+			t.state.TargetPrice = lvl.TargetPrice
+		}
+		if lvl.Ratio > 0 {
+			t.state.Ratio = lvl.Ratio
+			t.state.RemainingRatio = math.Max(0, lvl.Ratio-t.state.ExecutedRatio)
+		}
+		t.state.Status = "waiting"
+		t.state.LastEvent = changedFieldManualEdit
+		t.modified = t.state.TargetPrice != t.old.TargetPrice || t.state.Ratio != t.old.Ratio
+	}
+	return nil
+}
+
+// validateTierEdits 校验修改后的整组段位：目标价方向与开仓价一致、同组未触发段位严格单调、
+// 同组比例和不超过 1，止损段位距开仓价不小于 minStopPct。
+func validateTierEdits(tiers []*editedTier, minStopPct float64) error {
+	groups := make(map[string][]*editedTier)
+	var aliases []string
+	for _, t := range tiers {
+		alias := componentAlias(t.rec.PlanComponent)
+		if _, ok := groups[alias]; !ok {
+			aliases = append(aliases, alias)
+		}
+		groups[alias] = append(groups[alias], t)
+	}
+	for _, alias := range aliases {
+		var ratioSum, prev float64
+		for _, t := range groups[alias] {
+			ratioSum += t.state.Ratio
+			if t.rec.Status != database.StrategyStatusWaiting {
+				continue
+			}
+			entry := t.state.EntryPrice
+			side := strings.ToLower(strings.TrimSpace(t.state.Side))
+			if entry <= 0 || (side != "long" && side != "short") {
+				return fmt.Errorf("组件 %s 缺少开仓价或方向，无法校验", t.rec.PlanComponent)
+			}
+			target := t.state.TargetPrice
+			if target <= 0 {
+				return fmt.Errorf("组件 %s 目标价无效", t.rec.PlanComponent)
+			}
+			ascending := side == "long"
+			if t.mode == "stop_loss" {
+				ascending = !ascending
+			}
+			if ascending && target <= entry {
+				return fmt.Errorf("组件 %s 目标价 %.4f 需高于开仓价 %.4f（%s %s）", t.rec.PlanComponent, target, entry, side, t.mode)
+			}
+			if !ascending && target >= entry {
+				return fmt.Errorf("组件 %s 目标价 %.4f 需低于开仓价 %.4f（%s %s）", t.rec.PlanComponent, target, entry, side, t.mode)
+			}
+			if t.mode == "stop_loss" && minStopPct > 0 {
+				if dist := math.Abs(target-entry) / entry; dist < minStopPct {
+					return fmt.Errorf("组件 %s 止损距离过小: %.4f%% < %.4f%%", t.rec.PlanComponent, dist*100, minStopPct*100)
+				}
+			}
+			if prev > 0 {
+				if ascending && target <= prev {
+					return fmt.Errorf("组件 %s 目标价需严格递增", t.rec.PlanComponent)
+				}
+				if !ascending && target >= prev {
+					return fmt.Errorf("组件 %s 目标价需严格递减", t.rec.PlanComponent)
+				}
+			}
+			prev = target
+		}
+		if ratioSum > 1+tierRatioTolerance {
+			return fmt.Errorf("%s 比例和 %.4f 超过 1", alias, ratioSum)
+		}
+	}
+	return nil
+}
+
+func (s *PlanScheduler) notifyTierEdits(tradeID int, planID string, changes []interfaces.TierEditChange, source, reason string) {
+	if s.notifier == nil {
+		return
+	}
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, manualEditReason(c, ""))
+	}
+	msg := fmt.Sprintf("🛠 人工修改 tier (TradeID %d)\nPlan %s\n来源: %s\n\n%s", tradeID, planID, source, strings.Join(lines, "\n"))
+	if reason != "" {
+		msg += "\n原因: " + reason
+	}
+	if err := s.notifier.SendText(msg); err != nil {
+		logger.Warnf("Telegram 推送失败(manual_tier_edit): %v", err)
+	}
+}
+
+func manualEditReason(c interfaces.TierEditChange, reason string) string {
+	msg := fmt.Sprintf("%s [%s] 目标价 %.4f → %.4f，比例 %.4f → %.4f", c.Component, c.Mode, c.OldTargetPrice, c.TargetPrice, c.OldRatio, c.Ratio)
+	if reason != "" {
+		msg += "；" + reason
+	}
+	return msg
+}
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	brcfg "brale/internal/config"
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+	"brale/internal/strategy/exit"
+)
+
+const trailingStopSource = "trailing_stop"
+
+// SetTrailingStop 启用分段止损的移动止损；atr 为空时只做保本锁定，ATR 回退到计划参数中的 atr_value。
+func (s *PlanScheduler) SetTrailingStop(cfg brcfg.TrailingStopConfig, atr func(symbol string) (float64, bool)) {
+	if s == nil {
+		return
+	}
+	s.trailing = cfg
+	s.atrSource = atr
+	if cfg.Enabled {
+		logger.Infof("PlanScheduler: 移动止损已启用 lock=%s lock_atr=%.2f trail_atr=%.2f min_step=%.4f",
+			cfg.LockMode, cfg.LockATRMultiplier, cfg.TrailATRMultiplier, cfg.MinStepPct)
+	}
+}
+
+// trailStops 在 tier 评估之后运行：止盈第一段成交的计划，把仍在等待的止损段朝有利方向移动，
+// 每次移动通过 HandleAdjust 持久化并写 strategy_change_log。
+func (s *PlanScheduler) trailStops(ctx context.Context, watchers []*planWatcher, price float64) {
+	if !s.trailing.Enabled || price <= 0 || s.executor == nil {
+		return
+	}
+	adjusted := make(map[int]bool)
+	for _, w := range watchers {
+		if w == nil || watcherHasPending(w) || !takeProfitTier1Done(w) {
+			continue
+		}
+		if s.trailWatcher(ctx, w, price) {
+			adjusted[w.tradeID] = true
+		}
+	}
+	for tradeID := range adjusted {
+		s.rebuildTrade(ctx, tradeID)
+	}
+}
+
+func (s *PlanScheduler) trailWatcher(ctx context.Context, w *planWatcher, price float64) bool {
+	keys := make([]string, 0, len(w.components))
+	for k := range w.components {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	atr := s.trailingATR(w)
+	changed := false
+	for _, comp := range keys {
+		inst := w.components[comp]
+		if inst == nil || inst.Record.Status != database.StrategyStatusWaiting {
+			continue
+		}
+		state, err := exit.DecodeTierComponentState(inst.Record.StateJSON)
+		if err != nil || !strings.EqualFold(state.Mode, "stop_loss") || state.TargetPrice <= 0 {
+			continue
+		}
+		side := strings.ToLower(strings.TrimSpace(state.Side))
+		if side == "" {
+			side = w.side
+		}
+		next, ok := s.nextTrailingStop(side, state.EntryPrice, state.TargetPrice, price, atr)
+		if !ok {
+			continue
+		}
+		_, err = s.executor.HandleAdjust(ctx, w, comp, map[string]any{"target_price": next}, trailingStopSource)
+		if err != nil {
+			logger.Warnf("PlanScheduler: 移动止损失败 trade=%d plan=%s component=%s err=%v", w.tradeID, w.planID, comp, err)
+			continue
+		}
+		logger.Infof("PlanScheduler: 移动止损 trade=%d %s component=%s %.6f -> %.6f price=%.6f atr=%.6f",
+			w.tradeID, w.symbol, comp, state.TargetPrice, next, price, atr)
+		changed = true
+	}
+	return changed
+}
+
+// nextTrailingStop 取锁定价与追踪价中更有利者；只在比现止损更有利至少 MinStepPct 且仍在现价之外时返回。
+func (s *PlanScheduler) nextTrailingStop(side string, entry, current, price, atr float64) (float64, bool) {
+	cfg := s.trailing
+	short := side == "short"
+	var candidates []float64
+	if entry > 0 {
+		switch cfg.LockMode {
+		case "breakeven":
+			candidates = append(candidates, entry)
+		case "atr":
+			offset := 0.0
+			if atr > 0 {
+				offset = cfg.LockATRMultiplier * atr
+			}
+			if short {
+				candidates = append(candidates, entry-offset)
+			} else {
+				candidates = append(candidates, entry+offset)
+			}
+		}
+	}
+	if atr > 0 && cfg.TrailATRMultiplier > 0 {
+		if short {
+			candidates = append(candidates, price+cfg.TrailATRMultiplier*atr)
+		} else {
+			candidates = append(candidates, price-cfg.TrailATRMultiplier*atr)
+		}
+	}
+	best, found := 0.0, false
+	for _, c := range candidates {
+		if c <= 0 {
+			continue
+		}
+		// 止损不能越过现价，否则会立即触发
+		if (!short && c >= price) || (short && c <= price) {
+			continue
+		}
+		if !found || (!short && c > best) || (short && c < best) {
+			best, found = c, true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	step := current * cfg.MinStepPct
+	if short {
+		return best, best < current-step
+	}
+	return best, best > current+step
+}
+
+// trailingATR 优先使用行情服务的最新 ATR，缺失时取计划中 ATR 组件的 atr_value。
+func (s *PlanScheduler) trailingATR(w *planWatcher) float64 {
+	if s.atrSource != nil {
+		if atr, ok := s.atrSource(w.symbol); ok && atr > 0 {
+			return atr
+		}
+	}
+	for _, inst := range w.components {
+		if inst == nil {
+			continue
+		}
+		if v, ok := extractExecutorFloat(inst.Plan, "atr_value"); ok && v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// takeProfitTier1Done 判断计划中止盈第一段是否已成交。
+func takeProfitTier1Done(w *planWatcher) bool {
+	for comp, inst := range w.components {
+		if inst == nil || !isTier1Component(comp) {
+			continue
+		}
+		state, err := exit.DecodeTierComponentState(inst.Record.StateJSON)
+		if err != nil || !strings.EqualFold(effectiveTierMode(state, comp), "take_profit") {
+			continue
+		}
+		if inst.Record.Status == database.StrategyStatusDone || state.ExecutedRatio > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isTier1Component(comp string) bool {
+	comp = strings.ToLower(strings.TrimSpace(comp))
+	return comp == "tier1" || strings.HasSuffix(comp, ".tier1")
+}
+
+func effectiveTierMode(state exit.TierComponentState, comp string) string {
+	if mode := strings.TrimSpace(state.Mode); mode != "" {
+		return mode
+	}
+	return eventMode(nil, &exit.PlanEvent{PlanComponent: comp})
+}
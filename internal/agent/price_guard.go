@@ -0,0 +1,305 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/market"
+)
+
+const (
+	PriceGuardModeIndex = "index"
+	PriceGuardModeLast  = "last"
+
+	PriceConsensusTolerance = "tolerance"
+	PriceConsensusBoth      = "both"
+	PriceConsensusMedian    = "median"
+
+	defaultPriceGuardTimeout      = 3 * time.Second
+	defaultPriceGuardStreamMaxAge = 10 * time.Second
+	defaultDivergenceCooldown     = 15 * time.Minute
+)
+
+type PriceGuardParams struct {
+	Provider     market.ReferencePriceProvider
+	Mode         string
+	Label        string
+	Consensus    string
+	TolerancePct float64
+	FailOpen     bool
+	Timeout      time.Duration
+	// Stream 非空时订阅其实时成交价作为参考价，否则后台定期轮询参考价；StreamMaxAge 内的缓存价格优先于 REST 查询。
+	Stream             market.Source
+	StreamMaxAge       time.Duration
+	DivergencePct      float64
+	DivergenceCooldown time.Duration
+	Notifier           notifier.TextNotifier
+}
+
+// PriceGuard 在止损/分段触发前用第二个价格源交叉校验触发价，
+// 过滤只出现在单一交易所的插针，避免误平仓；两路价格长期偏离时发送告警。
+type PriceGuard struct {
+	provider  market.ReferencePriceProvider
+	mode      string
+	label     string
+	consensus string
+	tolerance float64
+	failOpen  bool
+	timeout   time.Duration
+
+	stream       market.Source
+	streamMaxAge time.Duration
+	primary      func(symbol string) (float64, bool)
+
+	divergencePct      float64
+	divergenceCooldown time.Duration
+	tg                 notifier.TextNotifier
+
+	mu        sync.RWMutex
+	feed      map[string]lastPriceEntry
+	lastAlert map[string]time.Time
+}
+
+func NewPriceGuard(p PriceGuardParams) *PriceGuard {
+	if p.Provider == nil || p.TolerancePct <= 0 {
+		return nil
+	}
+	mode := strings.ToLower(strings.TrimSpace(p.Mode))
+	if mode != PriceGuardModeLast {
+		mode = PriceGuardModeIndex
+	}
+	consensus := strings.ToLower(strings.TrimSpace(p.Consensus))
+	if consensus != PriceConsensusBoth && consensus != PriceConsensusMedian {
+		consensus = PriceConsensusTolerance
+	}
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultPriceGuardTimeout
+	}
+	label := strings.TrimSpace(p.Label)
+	if label == "" {
+		label = mode
+	}
+	maxAge := p.StreamMaxAge
+	if maxAge <= 0 {
+		maxAge = defaultPriceGuardStreamMaxAge
+	}
+	cooldown := p.DivergenceCooldown
+	if cooldown <= 0 {
+		cooldown = defaultDivergenceCooldown
+	}
+	return &PriceGuard{
+		provider:           p.Provider,
+		mode:               mode,
+		label:              label,
+		consensus:          consensus,
+		tolerance:          p.TolerancePct,
+		failOpen:           p.FailOpen,
+		timeout:            timeout,
+		stream:             p.Stream,
+		streamMaxAge:       maxAge,
+		divergencePct:      p.DivergencePct,
+		divergenceCooldown: cooldown,
+		tg:                 p.Notifier,
+		feed:               make(map[string]lastPriceEntry),
+		lastAlert:          make(map[string]time.Time),
+	}
+}
+
+// SetPrimary 设置主行情源最新价的读取函数，用于实时成交价流上的偏离告警。
+func (g *PriceGuard) SetPrimary(fn func(symbol string) (float64, bool)) {
+	if g == nil {
+		return
+	}
+	g.primary = fn
+}
+
+// Start 让参考价保持新鲜：配置了 Stream 时订阅实时成交价，否则（或订阅失败时）每 StreamMaxAge/2 轮询一次，
+// 使触发校验读取缓存而不是每次平仓都同步查询 REST。
+func (g *PriceGuard) Start(ctx context.Context, symbols []string) {
+	if g == nil || len(symbols) == 0 {
+		return
+	}
+	if g.stream != nil {
+		ch, err := g.stream.SubscribeTrades(ctx, symbols, market.SubscribeOptions{Buffer: 1024})
+		if err == nil {
+			logger.Infof("✓ PriceGuard: 参考源(%s)实时成交价订阅已启动 symbols=%d", g.label, len(symbols))
+			go g.consume(ctx, ch)
+			return
+		}
+		logger.Warnf("PriceGuard: 订阅参考源(%s)成交价失败，改为定期轮询: %v", g.label, err)
+	}
+	go g.poll(ctx, symbols)
+}
+
+func (g *PriceGuard) consume(ctx context.Context, ch <-chan market.TickEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			g.onReferenceTick(ev)
+		}
+	}
+}
+
+// poll 定期刷新参考价缓存；单个 symbol 查询失败只跳过本轮，触发时缓存过期再回退 REST。
+func (g *PriceGuard) poll(ctx context.Context, symbols []string) {
+	interval := max(g.streamMaxAge/2, time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, symbol := range symbols {
+			if ctx.Err() != nil {
+				return
+			}
+			callCtx, cancel := context.WithTimeout(ctx, g.timeout)
+			price, err := g.reference(callCtx, symbol)
+			cancel()
+			if err != nil || price <= 0 {
+				logger.Debugf("PriceGuard: 轮询参考价失败 %s(%s): %v", symbol, g.label, err)
+				continue
+			}
+			g.onReferenceTick(market.TickEvent{Symbol: symbol, Price: price})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *PriceGuard) onReferenceTick(ev market.TickEvent) {
+	symbol := strings.ToUpper(strings.TrimSpace(ev.Symbol))
+	if symbol == "" || ev.Price <= 0 {
+		return
+	}
+	ts := ev.EventTime
+	if ts == 0 {
+		ts = ev.TradeTime
+	}
+	if ts == 0 {
+		ts = time.Now().UnixMilli()
+	}
+	g.mu.Lock()
+	g.feed[symbol] = lastPriceEntry{price: ev.Price, ts: ts}
+	g.mu.Unlock()
+	if g.primary == nil {
+		return
+	}
+	if primary, ok := g.primary(symbol); ok {
+		g.checkDivergence(symbol, primary, ev.Price)
+	}
+}
+
+// Confirm 按共识规则返回触发是否成立；不成立时附带原因。
+// crosses 用给定价格重新判定触发条件，both/median 规则依赖它，为 nil 时退回 tolerance 规则。
+func (g *PriceGuard) Confirm(ctx context.Context, symbol string, price float64, crosses func(price float64) bool) (bool, string) {
+	if g == nil || price <= 0 {
+		return true, ""
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ref, err := g.referencePrice(ctx, symbol)
+	if err != nil || ref <= 0 {
+		if err == nil {
+			err = fmt.Errorf("参考价为空")
+		}
+		if g.failOpen {
+			logger.Warnf("PriceGuard: %s 获取参考价失败(%s)，按 fail_open 放行: %v", symbol, g.label, err)
+			return true, ""
+		}
+		return false, fmt.Sprintf("参考价(%s)不可用: %v", g.label, err)
+	}
+	g.checkDivergence(symbol, price, ref)
+	consensus := g.consensus
+	if crosses == nil {
+		consensus = PriceConsensusTolerance
+	}
+	switch consensus {
+	case PriceConsensusBoth:
+		if !crosses(ref) {
+			return false, fmt.Sprintf("参考价(%s) %.6f 未越过触发位（触发价 %.6f）", g.label, ref, price)
+		}
+	case PriceConsensusMedian:
+		// 两路价格的中位数即均值；主源单边插针只会把中位数拉动一半。
+		mid := (price + ref) / 2
+		if !crosses(mid) {
+			return false, fmt.Sprintf("中位价 %.6f 未越过触发位（触发价 %.6f，参考价(%s) %.6f）", mid, price, g.label, ref)
+		}
+	default:
+		dev := math.Abs(price-ref) / ref
+		if dev > g.tolerance {
+			return false, fmt.Sprintf("触发价 %.6f 与参考价(%s) %.6f 偏离 %.2f%% > %.2f%%", price, g.label, ref, dev*100, g.tolerance*100)
+		}
+	}
+	return true, ""
+}
+
+// referencePrice 优先使用缓存中未过期的参考价（实时成交价流或后台轮询写入），缓存过期时才按 mode 查询 REST 并回写缓存。
+func (g *PriceGuard) referencePrice(ctx context.Context, symbol string) (float64, error) {
+	if price, ok := g.cachedPrice(symbol); ok {
+		return price, nil
+	}
+	callCtx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	price, err := g.reference(callCtx, symbol)
+	if err == nil && price > 0 {
+		g.mu.Lock()
+		g.feed[strings.ToUpper(strings.TrimSpace(symbol))] = lastPriceEntry{price: price, ts: time.Now().UnixMilli()}
+		g.mu.Unlock()
+	}
+	return price, err
+}
+
+func (g *PriceGuard) cachedPrice(symbol string) (float64, bool) {
+	g.mu.RLock()
+	entry, ok := g.feed[strings.ToUpper(strings.TrimSpace(symbol))]
+	g.mu.RUnlock()
+	if !ok || entry.price <= 0 || time.Since(time.UnixMilli(entry.ts)) > g.streamMaxAge {
+		return 0, false
+	}
+	return entry.price, true
+}
+
+func (g *PriceGuard) reference(ctx context.Context, symbol string) (float64, error) {
+	if g.mode == PriceGuardModeLast {
+		return g.provider.LastPrice(ctx, symbol)
+	}
+	return g.provider.IndexPrice(ctx, symbol)
+}
+
+// checkDivergence 在两路价格偏离超过阈值时告警，同一 symbol 在冷却期内只发送一次。
+func (g *PriceGuard) checkDivergence(symbol string, primary, ref float64) {
+	if g.divergencePct <= 0 || primary <= 0 || ref <= 0 {
+		return
+	}
+	dev := math.Abs(primary-ref) / ref
+	if dev <= g.divergencePct {
+		return
+	}
+	now := time.Now()
+	g.mu.Lock()
+	if last, ok := g.lastAlert[symbol]; ok && now.Sub(last) < g.divergenceCooldown {
+		g.mu.Unlock()
+		return
+	}
+	g.lastAlert[symbol] = now
+	g.mu.Unlock()
+	msg := fmt.Sprintf("价格源偏离 ⚠️\n%s 主源 %.6f / 参考(%s) %.6f\n偏离 %.2f%% > %.2f%%", symbol, primary, g.label, ref, dev*100, g.divergencePct*100)
+	logger.Warnf("PriceGuard: %s 主源 %.6f 与参考价(%s) %.6f 偏离 %.2f%%", symbol, primary, g.label, ref, dev*100)
+	if g.tg != nil {
+		_ = notifier.SendCategoryText(g.tg, notifier.CategoryGeneral, msg)
+	}
+}
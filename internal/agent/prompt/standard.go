@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"brale/internal/decision"
@@ -149,7 +150,8 @@ func (s *StandardStrategy) buildProfilePrompts(candidates []string, activeProfil
 		sysPromptRefs := decision.CloneStringMap(rt.Definition.Prompts.SystemByModel)
 		exitText, example := s.buildProfileExitDirective(rt, sym)
 
-		if len(sysPrompts) == 0 && strings.TrimSpace(promptText) == "" && strings.TrimSpace(exitText) == "" && strings.TrimSpace(example) == "" {
+		contract := OutputContractFor(rt)
+		if len(sysPrompts) == 0 && strings.TrimSpace(promptText) == "" && strings.TrimSpace(exitText) == "" && strings.TrimSpace(example) == "" && contract.IsZero() {
 			continue
 		}
 		prompts[sym] = decision.ProfilePromptSpec{
@@ -159,13 +161,41 @@ func (s *StandardStrategy) buildProfilePrompts(candidates []string, activeProfil
 			SystemPromptsByModel:    sysPrompts,
 			SystemPromptRefsByModel: sysPromptRefs,
 			UserPrompt:              promptText,
+			PromptVersion:           rt.PromptVersion,
 			ExitConstraints:         exitText,
 			Example:                 example,
+			Contract:                contract,
+			Consensus: decision.ConsensusSpec{
+				Enabled:      rt.Definition.Consensus.Enabled,
+				MinAgreement: rt.Definition.Consensus.MinAgreement,
+				Weights:      rt.Definition.Consensus.Weights,
+			},
+			SnapshotDelta: decision.SnapshotDeltaSpec{
+				Enabled:           rt.Definition.Snapshot.Delta.Enabled,
+				Epsilon:           rt.Definition.Snapshot.Delta.Epsilon,
+				DefaultEpsilonPct: rt.Definition.Snapshot.Delta.DefaultEpsilonPct,
+				FullEvery:         rt.Definition.Snapshot.Delta.FullEvery,
+			},
 		}
 	}
 	return prompts
 }
 
+// OutputContractFor converts the profile output_contract section into the decision contract.
+func OutputContractFor(rt *profile.Runtime) decision.OutputContract {
+	if rt == nil {
+		return decision.OutputContract{}
+	}
+	c := rt.Definition.OutputContract
+	return decision.OutputContract{
+		Language:          c.Language,
+		ReasoningMaxChars: c.ReasoningMaxChars,
+		PriceUnit:         c.PriceUnit,
+		SizeUnit:          c.SizeUnit,
+		RequiredOnOpen:    append([]string(nil), c.RequiredOnOpen...),
+	}
+}
+
 func (s *StandardStrategy) renderExitPlanDirective(runtimes []*profile.Runtime) string {
 	if len(runtimes) == 0 {
 		return ""
@@ -199,6 +229,11 @@ func (s *StandardStrategy) buildProfileExitDirective(rt *profile.Runtime, symbol
 	prompts := s.lookupComboPrompts(rt.Definition.ExitPlans.ComboKeys())
 	if len(prompts) > 0 {
 		text := formatExitPlanConstraints(prompts)
+		if ratios := rt.Definition.ExitPlans.TierRatios; ratios.Configured() {
+			text += fmt.Sprintf("\n- 分段止盈/止损比例默认：多头 %s，空头 %s（tiers 段数较少时按前几段比例归一）",
+				formatTierRatios(ratios.ForSide("long")), formatTierRatios(ratios.ForSide("short")))
+		}
+		text += "\n- rationale：drivers 为驱动本次决策的指标（如 ema/macd/rsi/volume），invalidation 为开仓逻辑失效的价格，confidence 为 0-100 的信心"
 		sampleSymbol := strings.ToUpper(strings.TrimSpace(symbol))
 		if sampleSymbol == "" && len(rt.Definition.Targets) > 0 {
 			sampleSymbol = strings.ToUpper(strings.TrimSpace(rt.Definition.Targets[0]))
@@ -302,6 +337,14 @@ func formatExitPlanConstraints(prompts []promptkit.ExitPlanPrompt) string {
 	return strings.TrimSpace(builder.String())
 }
 
+func formatTierRatios(ratios []float64) string {
+	parts := make([]string, 0, len(ratios))
+	for _, r := range ratios {
+		parts = append(parts, strconv.FormatFloat(r, 'f', -1, 64))
+	}
+	return strings.Join(parts, "/")
+}
+
 func buildDecisionExampleJSON(symbol, planJSON string) string {
 	planJSON = strings.TrimSpace(planJSON)
 	if planJSON == "" {
@@ -319,6 +362,7 @@ func buildDecisionExampleJSON(symbol, planJSON string) string {
 		Symbol          string  `json:"symbol"`
 		Action          string  `json:"action"`
 		Reasoning       string  `json:"reasoning"`
+		Rationale       any     `json:"rationale"`
 		PositionSizeUSD float64 `json:"position_size_usd"`
 		Leverage        int     `json:"leverage"`
 		ExitPlan        any     `json:"exit_plan"`
@@ -328,6 +372,7 @@ func buildDecisionExampleJSON(symbol, planJSON string) string {
 		Symbol:          strings.ToUpper(symbol),
 		Action:          "open_long",
 		Reasoning:       "此处填入对当前 action 的判断，100 字以内。",
+		Rationale:       map[string]any{"drivers": []string{"ema", "macd", "volume"}, "confidence": 70},
 		PositionSizeUSD: 1000,
 		Leverage:        3,
 		ExitPlan:        plan,
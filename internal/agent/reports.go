@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/attribution"
+	"brale/internal/backtest"
+	"brale/internal/decision"
+	symbolpkg "brale/internal/pkg/symbol"
+	"brale/internal/profile"
+	livehttp "brale/internal/transport/http/live"
+)
+
+// DivergenceAttribution 汇总 since 之后平仓的交易，按开仓时的背离信号统计胜率与期望收益。
+func (s *LiveService) DivergenceAttribution(ctx context.Context, since time.Time) (attribution.Report, error) {
+	if s == nil || s.decLogs == nil {
+		return attribution.Report{}, fmt.Errorf("决策日志未启用")
+	}
+	outcomes, err := s.decLogs.ListEntrySignalOutcomes(ctx, since, 0)
+	if err != nil {
+		return attribution.Report{}, err
+	}
+	trades := make([]attribution.Trade, 0, len(outcomes))
+	for _, o := range outcomes {
+		trades = append(trades, attribution.Trade{
+			TradeID:  o.TradeID,
+			Side:     o.Side,
+			PnLUSD:   o.PnLUSD,
+			PnLRatio: o.PnLRatio,
+			Signals:  o.Signals,
+		})
+	}
+	return attribution.Compute(trades), nil
+}
+
+// ProfileComparison 汇总 [from, to) 区间内各 profile 的决策轮次与已平仓交易结果。
+// 交易优先按开仓决策记录的 profile 归属，缺失时按当前配置解析 symbol 所属 profile。
+func (s *LiveService) ProfileComparison(ctx context.Context, from, to time.Time) ([]attribution.ProfileStats, error) {
+	if s == nil || s.decLogs == nil {
+		return nil, fmt.Errorf("决策日志未启用")
+	}
+	rounds, err := s.decLogs.ListDecisionRounds(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var profileRounds []attribution.ProfileRound
+	for _, r := range rounds {
+		perProfile := make(map[string]*attribution.ProfileRound)
+		touch := func(name string) *attribution.ProfileRound {
+			pr, ok := perProfile[name]
+			if !ok {
+				pr = &attribution.ProfileRound{Profile: name}
+				perProfile[name] = pr
+			}
+			return pr
+		}
+		for _, sym := range r.Candidates {
+			touch(s.profileForSymbol(sym, ""))
+		}
+		for _, d := range r.Decisions {
+			pr := touch(s.profileForSymbol(d.Symbol, d.Profile))
+			switch decision.NormalizeAction(d.Action) {
+			case "hold", "":
+			case "open_long", "open_short":
+				pr.Decisions++
+				pr.Opens++
+			default:
+				pr.Decisions++
+			}
+		}
+		// 单轮通常只含一个 symbol；多 profile 共享一轮时各自计入全部模型调用。
+		for _, pr := range perProfile {
+			pr.ModelCalls = r.ModelCalls
+			profileRounds = append(profileRounds, *pr)
+		}
+	}
+
+	trades, err := s.decLogs.ListClosedTrades(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	traceIDs := make([]string, 0, len(trades))
+	seen := make(map[string]struct{})
+	for _, t := range trades {
+		if t.TraceID == "" {
+			continue
+		}
+		if _, ok := seen[t.TraceID]; !ok {
+			seen[t.TraceID] = struct{}{}
+			traceIDs = append(traceIDs, t.TraceID)
+		}
+	}
+	byTrace, err := s.decLogs.FinalDecisionsByTrace(ctx, traceIDs)
+	if err != nil {
+		return nil, err
+	}
+	profileTrades := make([]attribution.ProfileTrade, 0, len(trades))
+	for _, t := range trades {
+		var entry decision.Decision
+		for _, d := range byTrace[t.TraceID] {
+			if symbolpkg.Normalize(d.Symbol) == symbolpkg.Normalize(t.Symbol) {
+				entry = d
+				break
+			}
+		}
+		pt := attribution.ProfileTrade{
+			Profile:  s.profileForSymbol(t.Symbol, entry.Profile),
+			PnLUSD:   t.PnLUSD,
+			PnLRatio: t.PnLRatio,
+		}
+		if entry.StopLoss > 0 && t.EntryPrice > 0 && t.InitialAmount > 0 {
+			pt.RiskUSD = math.Abs(t.EntryPrice-entry.StopLoss) * t.InitialAmount
+		}
+		profileTrades = append(profileTrades, pt)
+	}
+	return attribution.CompareProfiles(profileRounds, profileTrades), nil
+}
+
+// TradeKPIs 汇总 [from, to) 内平仓交易的策略 KPI；q.Profile/q.Symbol 非空时只统计匹配的交易。
+// 开仓决策取自决策审计，止损用于换算 R 倍数，持仓时长按订单开平仓时间计算。
+func (s *LiveService) TradeKPIs(ctx context.Context, q livehttp.StatsQuery) (attribution.KPIReport, error) {
+	if s == nil || s.decLogs == nil {
+		return attribution.KPIReport{}, fmt.Errorf("决策日志未启用")
+	}
+	outcomes, err := s.decLogs.ListTradeOutcomes(ctx, q.From, q.To)
+	if err != nil {
+		return attribution.KPIReport{}, err
+	}
+	wantSymbol := symbolpkg.Normalize(q.Symbol)
+	trades := make([]attribution.KPITrade, 0, len(outcomes))
+	for _, o := range outcomes {
+		if wantSymbol != "" && symbolpkg.Normalize(o.Symbol) != wantSymbol {
+			continue
+		}
+		recorded := ""
+		if o.Entry != nil {
+			recorded = o.Entry.Profile
+		}
+		t := attribution.KPITrade{
+			TradeID:  o.TradeID,
+			Profile:  s.profileForSymbol(o.Symbol, recorded),
+			Symbol:   symbolpkg.Normalize(o.Symbol),
+			Side:     o.Side,
+			PnLUSD:   o.PnLUSD,
+			PnLRatio: o.PnLRatio,
+			Signals:  o.Signals,
+		}
+		if q.Profile != "" && !strings.EqualFold(t.Profile, q.Profile) {
+			continue
+		}
+		if o.Entry != nil && o.Entry.StopLoss > 0 && o.EntryPrice > 0 && o.InitialAmount > 0 {
+			t.RiskUSD = math.Abs(o.EntryPrice-o.Entry.StopLoss) * o.InitialAmount
+		}
+		if !o.OpenedAt.IsZero() && o.ClosedAt.After(o.OpenedAt) {
+			t.Holding = o.ClosedAt.Sub(o.OpenedAt)
+		}
+		trades = append(trades, t)
+	}
+	return attribution.ComputeKPIs(trades), nil
+}
+
+func (s *LiveService) profileForSymbol(symbol, recorded string) string {
+	if name := strings.TrimSpace(recorded); name != "" {
+		return name
+	}
+	if s.profileMgr != nil {
+		if rt, ok := s.profileMgr.Resolve(symbolpkg.Normalize(symbol)); ok && rt != nil {
+			return rt.Definition.Name
+		}
+	}
+	return "unknown"
+}
+
+// RunBacktest 用当前 profile 配置在 K 线缓存上回测；profile 未指定时按 symbol 解析，symbol 未指定时取 profile 的首个 target。
+func (s *LiveService) RunBacktest(ctx context.Context, req livehttp.BacktestRequest) (backtest.Result, error) {
+	if s == nil || s.profileMgr == nil || s.klineStore == nil {
+		return backtest.Result{}, fmt.Errorf("回测依赖未初始化")
+	}
+	symbol := symbolpkg.Normalize(req.Symbol)
+	var rt *profile.Runtime
+	if name := strings.TrimSpace(req.Profile); name != "" {
+		for _, candidate := range s.profileMgr.Profiles() {
+			if strings.EqualFold(candidate.Definition.Name, name) {
+				rt = candidate
+				break
+			}
+		}
+		if rt == nil {
+			return backtest.Result{}, fmt.Errorf("profile 不存在: %s", name)
+		}
+	} else if resolved, ok := s.profileMgr.Resolve(symbol); ok {
+		rt = resolved
+	}
+	if rt == nil {
+		return backtest.Result{}, fmt.Errorf("未找到 %s 对应的 profile", symbol)
+	}
+	if symbol == "" {
+		targets := rt.Definition.TargetsUpper()
+		if len(targets) == 0 {
+			return backtest.Result{}, fmt.Errorf("profile %s 未配置 targets，需指定 symbol", rt.Definition.Name)
+		}
+		symbol = targets[0]
+	}
+	limit := 0
+	if s.cfg != nil {
+		limit = s.cfg.Kline.MaxCached
+	}
+	runner := &backtest.Runner{Source: s.klineStore, Handlers: s.planHandlers, DefaultLimit: limit}
+	from, to := req.Range()
+	return runner.Run(ctx, backtest.Config{
+		Symbol:        symbol,
+		Profile:       rt.Definition,
+		From:          from,
+		To:            to,
+		Entry:         req.Entry,
+		Exit:          req.Exit,
+		StakeUSD:      req.StakeUSD,
+		InitialEquity: req.InitialEquity,
+		FeeRate:       req.FeeRate,
+		WarmupBars:    req.WarmupBars,
+		Execution:     req.Execution,
+	})
+}
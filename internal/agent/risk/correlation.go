@@ -0,0 +1,283 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/logger"
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+const correlationFetchTimeout = 10 * time.Second
+
+// CorrelationParams 为相关性闸门的配置；Action 为 block 或 downsize。
+type CorrelationParams struct {
+	KlineStore    market.KlineStore
+	Source        market.Source
+	Symbols       []string
+	Interval      string
+	Lookback      int
+	Threshold     float64
+	Action        string
+	DownsizeRatio float64
+	Refresh       time.Duration
+}
+
+// CorrelationMatrix 为监控 symbol 两两之间的收益率相关系数；样本不足的组合不出现在 Values 中。
+type CorrelationMatrix struct {
+	Interval  string                        `json:"interval"`
+	Lookback  int                           `json:"lookback"`
+	Threshold float64                       `json:"threshold"`
+	Action    string                        `json:"action"`
+	UpdatedAt time.Time                     `json:"updated_at"`
+	Symbols   []string                      `json:"symbols"`
+	Values    map[string]map[string]float64 `json:"values"`
+}
+
+// Correlation 定期计算监控 symbol 的滚动收益率相关性，新开仓与同方向持仓高度相关时拒绝或缩减仓位。
+type Correlation struct {
+	store     market.KlineStore
+	source    market.Source
+	symbols   []string
+	index     map[string]string
+	interval  string
+	lookback  int
+	threshold float64
+	action    string
+	downsize  float64
+	refresh   time.Duration
+
+	mu     sync.RWMutex
+	matrix CorrelationMatrix
+}
+
+func NewCorrelation(p CorrelationParams) *Correlation {
+	if len(p.Symbols) < 2 || p.Lookback < 3 || p.Threshold <= 0 {
+		return nil
+	}
+	refresh := p.Refresh
+	if refresh <= 0 {
+		refresh = time.Hour
+	}
+	symbols := make([]string, 0, len(p.Symbols))
+	index := make(map[string]string, len(p.Symbols))
+	for _, sym := range p.Symbols {
+		sym = normalizeSymbol(sym)
+		key := correlationKey(sym)
+		if key == "" {
+			continue
+		}
+		if _, dup := index[key]; dup {
+			continue
+		}
+		index[key] = sym
+		symbols = append(symbols, sym)
+	}
+	return &Correlation{
+		store:     p.KlineStore,
+		source:    p.Source,
+		symbols:   symbols,
+		index:     index,
+		interval:  p.Interval,
+		lookback:  p.Lookback,
+		threshold: p.Threshold,
+		action:    p.Action,
+		downsize:  p.DownsizeRatio,
+		refresh:   refresh,
+	}
+}
+
+// Start 立即计算一次，之后按 refresh 周期刷新，直到 ctx 结束。
+func (c *Correlation) Start(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(c.refresh)
+		defer ticker.Stop()
+		for {
+			c.Refresh(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Refresh 重新拉取 K 线并重算相关矩阵；单个 symbol 取数失败时只缺失其相关组合。
+func (c *Correlation) Refresh(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	returns := make(map[string]map[int64]float64, len(c.symbols))
+	for _, sym := range c.symbols {
+		if r := c.symbolReturns(ctx, sym); len(r) > 0 {
+			returns[sym] = r
+		}
+	}
+	minSamples := max(c.lookback/2, 3)
+	values := make(map[string]map[string]float64, len(returns))
+	for i, a := range c.symbols {
+		for _, b := range c.symbols[i+1:] {
+			corr, ok := pearson(returns[a], returns[b], minSamples)
+			if !ok {
+				continue
+			}
+			corr = math.Round(corr*1e4) / 1e4
+			setCorrelation(values, a, b, corr)
+			setCorrelation(values, b, a, corr)
+		}
+	}
+	c.mu.Lock()
+	c.matrix = CorrelationMatrix{
+		Interval:  c.interval,
+		Lookback:  c.lookback,
+		Threshold: c.threshold,
+		Action:    c.action,
+		UpdatedAt: time.Now(),
+		Symbols:   append([]string(nil), c.symbols...),
+		Values:    values,
+	}
+	c.mu.Unlock()
+	logger.Debugf("相关性: 已刷新 %d 个 symbol 的相关矩阵（%s x %d）", len(returns), c.interval, c.lookback)
+}
+
+// Matrix 返回最近一次计算的相关矩阵。
+func (c *Correlation) Matrix() CorrelationMatrix {
+	if c == nil {
+		return CorrelationMatrix{}
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.matrix
+}
+
+// Check 对开仓决策做相关性校验：与同方向持仓的最高相关系数超过阈值时，block 返回拒绝原因，
+// downsize 把 d 的保证金按比例缩减后放行（返回空字符串）。相关矩阵未就绪或无同方向持仓时直接放行。
+func (c *Correlation) Check(d *decision.Decision, positions []decision.PositionSnapshot) string {
+	if c == nil || d == nil || !isOpen(d.Action) {
+		return ""
+	}
+	sym := c.index[correlationKey(d.Symbol)]
+	side := strings.TrimPrefix(d.Action, "open_")
+	c.mu.RLock()
+	row := c.matrix.Values[sym]
+	c.mu.RUnlock()
+	if len(row) == 0 {
+		return ""
+	}
+	peer, best := "", 0.0
+	for _, pos := range positions {
+		other := c.index[correlationKey(pos.Symbol)]
+		if other == "" || other == sym || !strings.EqualFold(strings.TrimSpace(pos.Side), side) {
+			continue
+		}
+		if corr, ok := row[other]; ok && corr > best {
+			peer, best = other, corr
+		}
+	}
+	if peer == "" || best <= c.threshold {
+		return ""
+	}
+	if c.action == "downsize" {
+		before := d.PositionSizeUSD
+		d.PositionSizeUSD = before * c.downsize
+		logger.Infof("相关性: %s 与同向(%s)持仓 %s 相关系数 %.2f > %.2f，保证金 %.2f → %.2f",
+			sym, side, peer, best, c.threshold, before, d.PositionSizeUSD)
+		return ""
+	}
+	return fmt.Sprintf("与同向持仓 %s 相关系数 %.2f 超过 %.2f", peer, best, c.threshold)
+}
+
+// symbolReturns 返回最近 lookback 根 K 线的对数收益率（按 K 线开盘时间索引）；优先使用本地缓存，缓存不足时回退到行情源。
+func (c *Correlation) symbolReturns(ctx context.Context, symbol string) map[int64]float64 {
+	need := c.lookback + 1
+	var candles []market.Candle
+	if c.store != nil {
+		candles, _ = c.store.Get(ctx, symbol, c.interval)
+	}
+	if len(candles) < need && c.source != nil {
+		fetchCtx, cancel := context.WithTimeout(ctx, correlationFetchTimeout)
+		fetched, err := c.source.FetchHistory(fetchCtx, symbol, c.interval, need)
+		cancel()
+		if err != nil {
+			logger.Debugf("相关性: 获取 %s %s K 线失败: %v", symbol, c.interval, err)
+			return nil
+		}
+		candles = fetched
+	}
+	if len(candles) > need {
+		candles = candles[len(candles)-need:]
+	}
+	out := make(map[int64]float64, len(candles))
+	for i := 1; i < len(candles); i++ {
+		prev, cur := candles[i-1].Close, candles[i].Close
+		if prev <= 0 || cur <= 0 {
+			continue
+		}
+		out[candles[i].OpenTime] = math.Log(cur / prev)
+	}
+	return out
+}
+
+// pearson 在两组收益率的公共时间点上计算皮尔逊相关系数，公共样本少于 minSamples 时返回 false。
+func pearson(a, b map[int64]float64, minSamples int) (float64, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, false
+	}
+	keys := make([]int64, 0, len(a))
+	for ts := range a {
+		if _, ok := b[ts]; ok {
+			keys = append(keys, ts)
+		}
+	}
+	if len(keys) < minSamples {
+		return 0, false
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	var meanA, meanB float64
+	for _, ts := range keys {
+		meanA += a[ts]
+		meanB += b[ts]
+	}
+	n := float64(len(keys))
+	meanA /= n
+	meanB /= n
+	var cov, varA, varB float64
+	for _, ts := range keys {
+		da, db := a[ts]-meanA, b[ts]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varA*varB), true
+}
+
+func setCorrelation(values map[string]map[string]float64, a, b string, corr float64) {
+	row, ok := values[a]
+	if !ok {
+		row = make(map[string]float64)
+		values[a] = row
+	}
+	row[b] = corr
+}
+
+// correlationKey 统一 BTC/USDT:USDT 与 BTCUSDT 等写法，使持仓、决策与监控 symbol 能对上。
+func correlationKey(symbol string) string {
+	if norm := symbolpkg.Normalize(symbol); norm != "" {
+		return norm
+	}
+	return normalizeSymbol(symbol)
+}
@@ -0,0 +1,185 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	brcfg "brale/internal/config"
+	"brale/internal/decision"
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+)
+
+// Stage / Note 为组合风控拒绝写入决策日志时使用的 stage 与 note。
+const (
+	Stage = "risk"
+	Note  = "risk_limit"
+)
+
+// Store 提供当日已平仓交易（计算日内亏损）与决策日志写入。
+type Store interface {
+	ListClosedTrades(ctx context.Context, from, to time.Time) ([]database.ClosedTradeStat, error)
+	Insert(ctx context.Context, rec database.DecisionLogRecord) (int64, error)
+}
+
+// Manager 在执行器开仓前校验组合风控限制；nil 或未配置任何限制时放行所有决策。
+type Manager struct {
+	cfg      brcfg.RiskConfig
+	sectorOf map[string]string
+	store    Store
+}
+
+func NewManager(cfg brcfg.RiskConfig, store Store) *Manager {
+	sectorOf := make(map[string]string)
+	for sector, symbols := range cfg.Sectors {
+		for _, sym := range symbols {
+			sectorOf[normalizeSymbol(sym)] = sector
+		}
+	}
+	return &Manager{cfg: cfg, sectorOf: sectorOf, store: store}
+}
+
+// Enabled 表示至少配置了一项限制。
+func (m *Manager) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	c := m.cfg
+	return c.MaxPositions > 0 || c.MaxTotalNotional > 0 || c.MaxSymbolNotional > 0 ||
+		(c.MaxSectorNotional > 0 && len(m.sectorOf) > 0) || c.DailyLossLimitUSD > 0
+}
+
+// Exposure 为本轮执行开始时的持仓敞口，本轮放行的开仓通过 Commit 累加，避免同一轮内多笔开仓合计超限。
+type Exposure struct {
+	Positions int
+	Total     float64
+	BySymbol  map[string]float64
+	BySector  map[string]float64
+	// DailyPnL 为当日（UTC）已实现盈亏；DailyKnown 为 false 表示查询失败，此时不做日内亏损校验。
+	DailyPnL   float64
+	DailyKnown bool
+}
+
+// Snapshot 根据当前持仓与当日已平仓交易构建敞口。
+func (m *Manager) Snapshot(ctx context.Context, positions []decision.PositionSnapshot, now time.Time) *Exposure {
+	exp := &Exposure{BySymbol: make(map[string]float64), BySector: make(map[string]float64)}
+	for _, pos := range positions {
+		sym := normalizeSymbol(pos.Symbol)
+		notional := positionNotional(pos)
+		if _, held := exp.BySymbol[sym]; !held {
+			exp.Positions++
+		}
+		exp.BySymbol[sym] += notional
+		exp.Total += notional
+		if sector, ok := m.sectorOf[sym]; ok {
+			exp.BySector[sector] += notional
+		}
+	}
+	if m.cfg.DailyLossLimitUSD > 0 && m.store != nil {
+		now = now.UTC()
+		dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		trades, err := m.store.ListClosedTrades(ctx, dayStart, time.Time{})
+		if err != nil {
+			logger.Warnf("风控查询当日已平仓交易失败，跳过日内亏损校验: %v", err)
+		} else {
+			for _, tr := range trades {
+				exp.DailyPnL += tr.PnLUSD
+			}
+			exp.DailyKnown = true
+		}
+	}
+	return exp
+}
+
+// Check 返回开仓决策违反的第一条限制；空字符串表示放行。非开仓决策始终放行。
+func (m *Manager) Check(exp *Exposure, d decision.Decision) string {
+	if !m.Enabled() || exp == nil || !isOpen(d.Action) {
+		return ""
+	}
+	c := m.cfg
+	sym := normalizeSymbol(d.Symbol)
+	notional := decisionNotional(d)
+	if c.DailyLossLimitUSD > 0 && exp.DailyKnown && -exp.DailyPnL >= c.DailyLossLimitUSD {
+		return fmt.Sprintf("当日已实现亏损 %.2f USD 已达上限 %.2f", -exp.DailyPnL, c.DailyLossLimitUSD)
+	}
+	if _, held := exp.BySymbol[sym]; !held && c.MaxPositions > 0 && exp.Positions >= c.MaxPositions {
+		return fmt.Sprintf("持仓数 %d 已达上限 %d", exp.Positions, c.MaxPositions)
+	}
+	if c.MaxTotalNotional > 0 && exp.Total+notional > c.MaxTotalNotional {
+		return fmt.Sprintf("总名义价值 %.2f+%.2f 超过上限 %.2f", exp.Total, notional, c.MaxTotalNotional)
+	}
+	if c.MaxSymbolNotional > 0 && exp.BySymbol[sym]+notional > c.MaxSymbolNotional {
+		return fmt.Sprintf("%s 名义价值 %.2f+%.2f 超过上限 %.2f", sym, exp.BySymbol[sym], notional, c.MaxSymbolNotional)
+	}
+	if sector, ok := m.sectorOf[sym]; ok && c.MaxSectorNotional > 0 && exp.BySector[sector]+notional > c.MaxSectorNotional {
+		return fmt.Sprintf("板块 %s 名义价值 %.2f+%.2f 超过上限 %.2f", sector, exp.BySector[sector], notional, c.MaxSectorNotional)
+	}
+	return ""
+}
+
+// Commit 把已执行的开仓计入敞口。
+func (m *Manager) Commit(exp *Exposure, d decision.Decision) {
+	if m == nil || exp == nil || !isOpen(d.Action) {
+		return
+	}
+	sym := normalizeSymbol(d.Symbol)
+	notional := decisionNotional(d)
+	if _, held := exp.BySymbol[sym]; !held {
+		exp.Positions++
+	}
+	exp.BySymbol[sym] += notional
+	exp.Total += notional
+	if sector, ok := m.sectorOf[sym]; ok {
+		exp.BySector[sector] += notional
+	}
+}
+
+// Reject 把决策降级为 skip 并附上原因，同时以调用方给出的 stage/note 写入决策日志（与原决策同一 trace），
+// 供风控之外的开仓闸门（相关性、交易模式、入场漂移）共用。
+func (m *Manager) Reject(ctx context.Context, traceID string, d decision.Decision, stage, note, reason string) decision.Decision {
+	logger.Warnf("开仓被拒绝(%s) %s %s: %s", stage, d.Symbol, d.Action, reason)
+	skipped := d
+	skipped.Action = "skip"
+	skipped.Reasoning = strings.TrimSpace(fmt.Sprintf("[%s 拒绝 %s] %s %s", stage, d.Action, reason, d.Reasoning))
+	if m == nil || m.store == nil {
+		return skipped
+	}
+	if _, err := m.store.Insert(ctx, database.DecisionLogRecord{
+		TraceID:    traceID,
+		Timestamp:  time.Now().UnixMilli(),
+		ProviderID: stage,
+		Stage:      stage,
+		Decisions:  []decision.Decision{skipped},
+		Symbols:    []string{normalizeSymbol(d.Symbol)},
+		Error:      reason,
+		Note:       note,
+	}); err != nil {
+		logger.Warnf("写入开仓拒绝记录失败 %s: %v", d.Symbol, err)
+	}
+	return skipped
+}
+
+func isOpen(action string) bool {
+	return action == "open_long" || action == "open_short"
+}
+
+func decisionNotional(d decision.Decision) float64 {
+	return d.PositionSizeUSD * float64(max(d.Leverage, 1))
+}
+
+// positionNotional 优先用 数量*现价，其次 position_value，最后 保证金*杠杆。
+func positionNotional(pos decision.PositionSnapshot) float64 {
+	if pos.Quantity > 0 && pos.CurrentPrice > 0 {
+		return pos.Quantity * pos.CurrentPrice
+	}
+	if pos.PositionValue > 0 {
+		return pos.PositionValue
+	}
+	return pos.Stake * max(pos.Leverage, 1)
+}
+
+func normalizeSymbol(sym string) string {
+	return strings.ToUpper(strings.TrimSpace(sym))
+}
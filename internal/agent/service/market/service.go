@@ -8,9 +8,13 @@ import (
 	"time"
 
 	"brale/internal/agent/interfaces"
+	"brale/internal/analysis/indicator"
+	"brale/internal/analysis/screen"
 	"brale/internal/config"
+	"brale/internal/config/loader"
 	"brale/internal/decision"
 	"brale/internal/market"
+	"brale/internal/pkg/maputil"
 	"brale/internal/profile"
 	"brale/internal/store"
 )
@@ -20,10 +24,13 @@ type Service struct {
 	ks         market.KlineStore
 	profileMgr *profile.Manager
 
-	monitor PriceSource
+	monitor   PriceSource
+	orderBook market.OrderBookReader
 
 	indicatorMu   sync.RWMutex
 	indicatorSnap map[string]indicatorSnapshot
+	// indicatorCache 按最后收盘 K 线缓存指标计算结果，跨轮次复用。
+	indicatorCache *decision.IndicatorCache
 
 	hIntervals  []string
 	horizonName string
@@ -42,18 +49,22 @@ type ServiceParams struct {
 	Intervals   []string
 	HorizonName string
 	VisionReady bool
+	// OrderBook 为部分深度快照来源，profile 配置了 order_book 中间件时写入指标快照。
+	OrderBook market.OrderBookReader
 }
 
 func NewService(p ServiceParams) *Service {
 	return &Service{
-		cfg:           p.Config,
-		ks:            p.KlineStore,
-		profileMgr:    p.ProfileMgr,
-		monitor:       p.Monitor,
-		hIntervals:    p.Intervals,
-		horizonName:   p.HorizonName,
-		visionReady:   p.VisionReady,
-		indicatorSnap: make(map[string]indicatorSnapshot),
+		cfg:            p.Config,
+		ks:             p.KlineStore,
+		profileMgr:     p.ProfileMgr,
+		monitor:        p.Monitor,
+		orderBook:      p.OrderBook,
+		hIntervals:     p.Intervals,
+		horizonName:    p.HorizonName,
+		visionReady:    p.VisionReady,
+		indicatorSnap:  make(map[string]indicatorSnapshot),
+		indicatorCache: decision.NewIndicatorCache(),
 	}
 }
 
@@ -76,7 +87,7 @@ func (s *Service) GetAnalysisContexts(ctx context.Context, symbols []string) ([]
 		if symbol == "" {
 			continue
 		}
-		rt, ok := s.profileMgr.Resolve(symbol)
+		rt, ok := s.profileMgr.ResolveContext(ctx, symbol)
 		if !ok || rt == nil || rt.AnalysisSlice <= 0 {
 			continue
 		}
@@ -102,12 +113,98 @@ func (s *Service) GetAnalysisContexts(ctx context.Context, symbols []string) ([]
 			WithImages:        s.visionReady,
 			DisableIndicators: !rt.AgentEnabled,
 			RequireATR:        profileNeedsATR(rt),
+			Composite:         rt.Definition.Composite.Enabled,
+			CompositeWeights:  rt.Definition.Composite.Weights,
+			Snapshot: decision.SnapshotOptions{
+				DistanceUnits:    rt.Definition.Snapshot.DistanceUnits,
+				Preset:           rt.Definition.Snapshot.Preset,
+				Blocks:           rt.Definition.Snapshot.Blocks,
+				Tails:            rt.Definition.Snapshot.Tails,
+				Precision:        rt.Definition.Snapshot.Precision,
+				StochRSI:         indicator.StochRSISettings(rt.Definition.Snapshot.StochRSI),
+				ConnorsRSI:       indicator.ConnorsRSISettings(rt.Definition.Snapshot.ConnorsRSI),
+				EMAPeriods:       rt.Definition.Snapshot.EMAs,
+				RSIDivergence:    rsiDivergenceSettings(rt.Definition),
+				CustomIndicators: customIndicatorSpecs(rt.Definition),
+			},
+			Confluence:        rt.Definition.Confluence.Enabled,
+			ConfluenceWeights: rt.Definition.Confluence.Weights,
+			DivergenceScoring: screen.DivergenceScoring(rt.Definition.DivergenceScoring),
+			OrderBook:         s.orderBookMetrics(rt.Definition),
+			IndicatorCache:    s.indicatorCache,
 		}
 		out = append(out, decision.BuildAnalysisContexts(input)...)
 	}
 	return out, nil
 }
 
+// IndicatorCacheStats 返回指标缓存的命中统计。
+func (s *Service) IndicatorCacheStats() decision.IndicatorCacheStats {
+	return s.indicatorCache.Stats()
+}
+
+// orderBookMetrics 在 profile 配置了 order_book 中间件时，按其 band_pct/wall_multiple 计算快照用的盘口指标。
+func (s *Service) orderBookMetrics(def loader.ProfileDefinition) func(string) (market.OrderBookMetrics, bool) {
+	if s.orderBook == nil {
+		return nil
+	}
+	for _, mw := range def.Middlewares {
+		if strings.TrimSpace(mw.Name) != "order_book" {
+			continue
+		}
+		band, wall := maputil.Float(mw.Params, "band_pct"), maputil.Float(mw.Params, "wall_multiple")
+		return func(symbol string) (market.OrderBookMetrics, bool) {
+			book, ok := s.orderBook.LatestOrderBook(symbol)
+			if !ok {
+				return market.OrderBookMetrics{}, false
+			}
+			return market.ComputeOrderBookMetrics(book, band, wall, time.Now())
+		}
+	}
+	return nil
+}
+
+// rsiDivergenceSettings 在 profile 配置了 rsi_divergence 中间件时返回其参数，快照据此输出 rsi_divergence 块。
+func rsiDivergenceSettings(def loader.ProfileDefinition) indicator.RSIDivergenceSettings {
+	for _, mw := range def.Middlewares {
+		if strings.TrimSpace(mw.Name) != "rsi_divergence" {
+			continue
+		}
+		return indicator.RSIDivergenceSettings{
+			Period:     maputil.Int(mw.Params, "period"),
+			PivotLeft:  maputil.Int(mw.Params, "pivot_left"),
+			PivotRight: maputil.Int(mw.Params, "pivot_right"),
+			MinGap:     maputil.Int(mw.Params, "min_gap"),
+			MaxGap:     maputil.Int(mw.Params, "max_gap"),
+			MaxAge:     maputil.Int(mw.Params, "max_age"),
+			Overbought: maputil.Float(mw.Params, "overbought"),
+			Oversold:   maputil.Float(mw.Params, "oversold"),
+			Hidden:     strings.EqualFold(maputil.String(mw.Params, "hidden"), "true"),
+		}.WithDefaults()
+	}
+	return indicator.RSIDivergenceSettings{}
+}
+
+// customIndicatorSpecs 收集 profile 中 custom_indicator 中间件的表达式，快照据此输出 custom 块。
+func customIndicatorSpecs(def loader.ProfileDefinition) []decision.CustomIndicatorSpec {
+	var out []decision.CustomIndicatorSpec
+	for _, mw := range def.Middlewares {
+		if strings.TrimSpace(mw.Name) != "custom_indicator" {
+			continue
+		}
+		spec := decision.CustomIndicatorSpec{
+			Feature:  strings.ToLower(strings.TrimSpace(maputil.String(mw.Params, "feature"))),
+			Formula:  strings.TrimSpace(maputil.String(mw.Params, "formula")),
+			Interval: strings.ToLower(strings.TrimSpace(maputil.String(mw.Params, "interval"))),
+		}
+		if spec.Feature == "" || spec.Formula == "" {
+			continue
+		}
+		out = append(out, spec)
+	}
+	return out
+}
+
 func (s *Service) LatestPrice(ctx context.Context, symbol string) float64 {
 	if s.monitor != nil {
 		return s.monitor.LatestPrice(ctx, symbol)
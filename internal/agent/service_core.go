@@ -1,6 +1,11 @@
 package agent
 
-import "brale/internal/logger"
+import (
+	"context"
+	"time"
+
+	"brale/internal/logger"
+)
 
 func (s *LiveService) PlanScheduler() *PlanScheduler {
 	if s == nil {
@@ -9,6 +14,22 @@ func (s *LiveService) PlanScheduler() *PlanScheduler {
 	return s.planScheduler
 }
 
+// ApplyProfileUniverse 在 profiles.yaml 热更新后同步决策候选与行情订阅范围；持仓监控不受影响。
+// 交易对有增减时推送一次变更摘要。
+func (s *LiveService) ApplyProfileUniverse(symbols, intervals []string, lookbacks map[string]int) {
+	if s == nil || len(symbols) == 0 {
+		return
+	}
+	added, removed := s.swapTargets(symbols)
+	if s.liveEngine != nil {
+		s.liveEngine.SetCandidates(symbols)
+	}
+	if s.monitor != nil {
+		s.monitor.UpdateUniverse(symbols, intervals, lookbacks)
+	}
+	s.notifyTargetsChanged(added, removed, len(symbols))
+}
+
 func (s *LiveService) Close() error {
 	if s == nil {
 		return nil
@@ -19,6 +40,19 @@ func (s *LiveService) Close() error {
 		s.monitor.Close()
 		logger.Infof("LiveService: ✓ PriceMonitor 已关闭")
 	}
+	if s.klineCache != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if n, err := s.klineCache.Save(ctx); err != nil {
+			logger.Warnf("LiveService: K线快照写回失败（已写 %d 个）: %v", n, err)
+		} else {
+			logger.Infof("LiveService: ✓ K线快照已写回 %d 个 stream", n)
+		}
+		cancel()
+	}
+	if r, ok := s.execManager.(pendingExitRecovery); ok {
+		n := r.StopPendingTimers()
+		logger.Infof("LiveService: ✓ 已停止 %d 个成交等待计时器，平仓等待记录保留至下次启动对账", n)
+	}
 	if s.decLogs != nil {
 		if err := s.decLogs.Close(); err != nil {
 			logger.Warnf("LiveService: DecisionLogStore 关闭失败: %v", err)
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	brcfg "brale/internal/config"
+	"brale/internal/logger"
+	livehttp "brale/internal/transport/http/live"
+)
+
+// runMode 记录实例是否处于热备状态；promote 只能单向 standby → active。
+type runMode struct {
+	mu         sync.Mutex
+	standby    bool
+	since      time.Time
+	promotedBy string
+	promoted   chan struct{}
+}
+
+func newRunMode(standby bool) *runMode {
+	return &runMode{standby: standby, since: time.Now(), promoted: make(chan struct{})}
+}
+
+func (m *runMode) status() livehttp.RunModeStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mode := brcfg.AppModeActive
+	if m.standby {
+		mode = brcfg.AppModeStandby
+	}
+	return livehttp.RunModeStatus{Mode: mode, Since: m.since, PromotedBy: m.promotedBy}
+}
+
+func (m *runMode) isStandby() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.standby
+}
+
+func (m *runMode) promote(operator string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.standby {
+		return fmt.Errorf("实例已处于 active 模式")
+	}
+	m.standby = false
+	m.since = time.Now()
+	m.promotedBy = operator
+	close(m.promoted)
+	return nil
+}
+
+// waitPromoted 阻塞直到被 promote；ctx 结束时返回 false。
+func (m *runMode) waitPromoted(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-m.promoted:
+		return true
+	}
+}
+
+// RunMode 返回当前运行模式。
+func (s *LiveService) RunMode() livehttp.RunModeStatus {
+	if s == nil || s.mode == nil {
+		return livehttp.RunModeStatus{Mode: brcfg.AppModeActive}
+	}
+	return s.mode.status()
+}
+
+// Promote 将 standby 实例提升为 active：开始调用 LLM、执行退出计划与执行器相关后台任务。
+func (s *LiveService) Promote(ctx context.Context, operator string) (livehttp.RunModeStatus, error) {
+	if s == nil || s.mode == nil {
+		return livehttp.RunModeStatus{}, fmt.Errorf("live service 未初始化")
+	}
+	if err := s.mode.promote(operator); err != nil {
+		return s.mode.status(), err
+	}
+	logger.Infof("LiveService: standby 已提升为 active operator=%s", operator)
+	if s.tg != nil {
+		if err := s.tg.SendText(fmt.Sprintf("🔁 实例已由 standby 提升为 active\n操作人: %s", operator)); err != nil {
+			logger.Warnf("Telegram 推送失败(promote): %v", err)
+		}
+	}
+	return s.mode.status(), nil
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/market"
+)
+
+// diffTargets 按 market.StreamKey 比较新旧交易对列表，返回排序后的新增与移除 symbol。
+func diffTargets(prev, next []string) (added, removed []string) {
+	before := make(map[string]bool, len(prev))
+	for _, sym := range prev {
+		before[market.StreamKey(sym)] = true
+	}
+	after := make(map[string]bool, len(next))
+	for _, sym := range next {
+		key := market.StreamKey(sym)
+		if after[key] {
+			continue
+		}
+		after[key] = true
+		if !before[key] {
+			added = append(added, key)
+		}
+	}
+	for key := range before {
+		if !after[key] {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func (s *LiveService) targets() []string {
+	s.targetsMu.Lock()
+	defer s.targetsMu.Unlock()
+	return append([]string(nil), s.symbols...)
+}
+
+// swapTargets 记录新的交易对列表并返回与旧列表的差异。
+func (s *LiveService) swapTargets(symbols []string) (added, removed []string) {
+	s.targetsMu.Lock()
+	defer s.targetsMu.Unlock()
+	added, removed = diffTargets(s.symbols, symbols)
+	s.symbols = append([]string(nil), symbols...)
+	return added, removed
+}
+
+// notifyTargetsChanged 推送交易对增减摘要；移除但仍有持仓的 symbol 会标注保留订阅。
+func (s *LiveService) notifyTargetsChanged(added, removed []string, total int) {
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	held := make(map[string]bool)
+	if len(removed) > 0 && s.execManager != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		positions, err := s.execManager.ListOpenPositions(ctx)
+		cancel()
+		if err != nil {
+			logger.Warnf("交易对变更: 获取持仓失败: %v", err)
+		}
+		for _, p := range positions {
+			held[market.StreamKey(p.Symbol)] = true
+		}
+	}
+	removedLabels := make([]string, 0, len(removed))
+	for _, sym := range removed {
+		if held[sym] {
+			sym += "（仍有持仓，保留行情订阅）"
+		}
+		removedLabels = append(removedLabels, sym)
+	}
+	logger.Infof("交易对变更: 新增=%v 移除=%v 当前=%d", added, removedLabels, total)
+
+	var b strings.Builder
+	b.WriteString("🎯 交易对列表已更新\n")
+	if len(added) > 0 {
+		fmt.Fprintf(&b, "新增(%d): %s\n", len(added), strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(&b, "移除(%d): %s\n", len(removed), strings.Join(removedLabels, ", "))
+	}
+	fmt.Fprintf(&b, "当前共 %d 个，行情订阅已同步", total)
+	if err := notifier.SendCategoryText(s.tg, notifier.CategoryGeneral, b.String()); err != nil {
+		logger.Warnf("交易对变更: 推送失败: %v", err)
+	}
+}
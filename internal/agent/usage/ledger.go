@@ -0,0 +1,181 @@
+// Package usage 按模型与 UTC 自然日累计 token 用量与估算费用，并在当日预算超出后拒绝模型调用。
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	brcfg "brale/internal/config"
+	"brale/internal/gateway/database"
+	"brale/internal/gateway/notifier"
+	"brale/internal/gateway/provider"
+	"brale/internal/logger"
+)
+
+const dayLayout = "2006-01-02"
+
+// ErrBudgetExceeded 为当日预算超出时 Allow 返回的错误。
+var ErrBudgetExceeded = errors.New("模型当日预算已用尽")
+
+// Store 持久化每日用量；写入为增量累加。
+type Store interface {
+	AddModelUsage(ctx context.Context, rec database.ModelUsageRecord) error
+	ListModelUsage(ctx context.Context, fromDay, toDay string) ([]database.ModelUsageRecord, error)
+}
+
+var _ provider.UsageMeter = (*Ledger)(nil)
+
+// Ledger 实现 provider.UsageMeter：内存中维护当日各模型累计，跨日自动清零。
+type Ledger struct {
+	cfg brcfg.UsageConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	store    Store
+	notifier notifier.TextNotifier
+	day      string
+	totals   map[string]*database.ModelUsageRecord
+	alerted  map[string]bool
+}
+
+func NewLedger(cfg brcfg.UsageConfig) *Ledger {
+	return &Ledger{cfg: cfg, now: time.Now}
+}
+
+// Attach 设置持久化与告警通道，并从 store 恢复当日累计（重启后预算仍然有效）。
+func (l *Ledger) Attach(ctx context.Context, store Store, n notifier.TextNotifier) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.store = store
+	l.notifier = n
+	if store == nil {
+		return
+	}
+	l.rollLocked()
+	recs, err := store.ListModelUsage(ctx, l.day, l.day)
+	if err != nil {
+		logger.Warnf("模型用量: 恢复当日累计失败: %v", err)
+		return
+	}
+	for _, rec := range recs {
+		rec := rec
+		l.totals[rec.ModelID] = &rec
+	}
+	if len(recs) > 0 {
+		logger.Infof("模型用量: 已恢复 %s 累计 models=%d cost=$%.4f", l.day, len(recs), l.totalCostLocked())
+	}
+}
+
+// Allow 在当日全局或该模型的估算费用达到预算时返回 ErrBudgetExceeded。
+func (l *Ledger) Allow(modelID string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollLocked()
+	if scope, spent, budget, over := l.overBudgetLocked(modelID); over {
+		return fmt.Errorf("%w: %s 已用 $%.4f / 预算 $%.2f，拒绝调用 %s", ErrBudgetExceeded, scope, spent, budget, modelID)
+	}
+	return nil
+}
+
+// Record 累加一次调用的用量与估算费用并写入 store；本次调用使预算耗尽时推送一次告警。
+func (l *Ledger) Record(modelID string, u provider.Usage) {
+	if l == nil || u.Empty() {
+		return
+	}
+	cost := l.cost(modelID, u)
+	l.mu.Lock()
+	l.rollLocked()
+	rec, ok := l.totals[modelID]
+	if !ok {
+		rec = &database.ModelUsageRecord{Day: l.day, ModelID: modelID}
+		l.totals[modelID] = rec
+	}
+	rec.Calls++
+	rec.InputTokens += int64(u.InputTokens)
+	rec.OutputTokens += int64(u.OutputTokens)
+	rec.CostUSD += cost
+	rec.UpdatedAt = l.now().UnixMilli()
+	delta := database.ModelUsageRecord{
+		Day:          l.day,
+		ModelID:      modelID,
+		Calls:        1,
+		InputTokens:  int64(u.InputTokens),
+		OutputTokens: int64(u.OutputTokens),
+		CostUSD:      cost,
+		UpdatedAt:    rec.UpdatedAt,
+	}
+	store := l.store
+	var alert string
+	if scope, spent, budget, over := l.overBudgetLocked(modelID); over && !l.alerted[scope] {
+		l.alerted[scope] = true
+		alert = fmt.Sprintf("💸 模型当日预算已用尽\n范围: %s\n已用: $%.4f / 预算 $%.2f\n日期: %s (UTC)\n此后该范围内的模型调用将被拒绝，次日 00:00 UTC 恢复。",
+			scope, spent, budget, l.day)
+	}
+	n := l.notifier
+	l.mu.Unlock()
+
+	logger.Debugf("模型用量: %s in=%d out=%d cost=$%.6f", modelID, u.InputTokens, u.OutputTokens, cost)
+	if store != nil {
+		if err := store.AddModelUsage(context.Background(), delta); err != nil {
+			logger.Warnf("模型用量: 写入失败 model=%s err=%v", modelID, err)
+		}
+	}
+	if alert != "" {
+		logger.Warnf("模型用量: %s", strings.ReplaceAll(alert, "\n", " | "))
+		if err := notifier.SendCategoryText(n, notifier.CategoryError, alert); err != nil {
+			logger.Warnf("模型用量: 预算告警推送失败: %v", err)
+		}
+	}
+}
+
+func (l *Ledger) cost(modelID string, u provider.Usage) float64 {
+	price, ok := l.cfg.Prices[modelID]
+	if !ok {
+		return 0
+	}
+	return (float64(u.InputTokens)*price.InputPerMTok + float64(u.OutputTokens)*price.OutputPerMTok) / 1e6
+}
+
+// overBudgetLocked 先检查模型预算再检查全局预算，返回超出的范围（model:<id> 或 all）。
+func (l *Ledger) overBudgetLocked(modelID string) (string, float64, float64, bool) {
+	if budget := l.cfg.ModelBudgetsUSD[modelID]; budget > 0 {
+		if rec := l.totals[modelID]; rec != nil && rec.CostUSD >= budget {
+			return "model:" + modelID, rec.CostUSD, budget, true
+		}
+	}
+	if budget := l.cfg.DailyBudgetUSD; budget > 0 {
+		if spent := l.totalCostLocked(); spent >= budget {
+			return "all", spent, budget, true
+		}
+	}
+	return "", 0, 0, false
+}
+
+func (l *Ledger) totalCostLocked() float64 {
+	total := 0.0
+	for _, rec := range l.totals {
+		total += rec.CostUSD
+	}
+	return total
+}
+
+// rollLocked 在 UTC 日期变化时清空当日累计与告警状态。
+func (l *Ledger) rollLocked() {
+	day := l.now().UTC().Format(dayLayout)
+	if day == l.day && l.totals != nil {
+		return
+	}
+	l.day = day
+	l.totals = make(map[string]*database.ModelUsageRecord)
+	l.alerted = make(map[string]bool)
+}
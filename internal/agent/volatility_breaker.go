@@ -0,0 +1,265 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/market"
+)
+
+const (
+	liquidationWindow          = 5 * time.Minute
+	defaultVolBreakerCheck     = time.Minute
+	defaultVolBreakerFetchTime = 10 * time.Second
+)
+
+type VolatilityBreakerParams struct {
+	KlineStore      market.KlineStore
+	Source          market.Source
+	Liquidations    market.LiquidationProvider
+	Notifier        notifier.TextNotifier
+	Symbols         []string
+	Interval        string
+	Window          int
+	MaxVolPct       float64
+	MarketWideRatio float64
+	LiquidationUSD  float64
+	Resume          time.Duration
+	CheckInterval   time.Duration
+}
+
+type liquidationPoint struct {
+	at       time.Time
+	notional float64
+}
+
+// VolatilityBreaker 监控全市场已实现波动率与强平额，极端行情下暂停所有 profile 的新开仓，
+// 条件恢复正常并持续 Resume 后自动解除。平仓不受影响。
+type VolatilityBreaker struct {
+	store        market.KlineStore
+	source       market.Source
+	liquidations market.LiquidationProvider
+	notifier     notifier.TextNotifier
+	symbols      []string
+	interval     string
+	window       int
+	maxVol       float64
+	ratio        float64
+	liqUSD       float64
+	resume       time.Duration
+	check        time.Duration
+
+	mu        sync.Mutex
+	halted    bool
+	reason    string
+	calmSince time.Time
+	liqs      []liquidationPoint
+}
+
+func NewVolatilityBreaker(p VolatilityBreakerParams) *VolatilityBreaker {
+	if len(p.Symbols) == 0 || p.Window < 2 || p.MaxVolPct <= 0 || p.MarketWideRatio <= 0 {
+		return nil
+	}
+	check := p.CheckInterval
+	if check <= 0 {
+		check = defaultVolBreakerCheck
+	}
+	return &VolatilityBreaker{
+		store:        p.KlineStore,
+		source:       p.Source,
+		liquidations: p.Liquidations,
+		notifier:     p.Notifier,
+		symbols:      append([]string(nil), p.Symbols...),
+		interval:     p.Interval,
+		window:       p.Window,
+		maxVol:       p.MaxVolPct,
+		ratio:        p.MarketWideRatio,
+		liqUSD:       p.LiquidationUSD,
+		resume:       p.Resume,
+		check:        check,
+	}
+}
+
+// EntriesHalted 返回当前是否处于熔断状态及原因。
+func (b *VolatilityBreaker) EntriesHalted() (bool, string) {
+	if b == nil {
+		return false, ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.halted, b.reason
+}
+
+func (b *VolatilityBreaker) Start(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	if b.liqUSD > 0 && b.liquidations != nil {
+		events, err := b.liquidations.SubscribeLiquidations(ctx, market.SubscribeOptions{})
+		if err != nil {
+			logger.Warnf("波动熔断: 订阅强平流失败: %v", err)
+		} else {
+			go b.consumeLiquidations(ctx, events)
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(b.check)
+		defer ticker.Stop()
+		for {
+			b.evaluate(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (b *VolatilityBreaker) consumeLiquidations(ctx context.Context, events <-chan market.LiquidationEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			b.mu.Lock()
+			b.liqs = append(b.liqs, liquidationPoint{at: time.Now(), notional: ev.Notional})
+			b.mu.Unlock()
+		}
+	}
+}
+
+// liquidationSum 返回最近 5 分钟的全市场强平额，并清理过期记录。
+func (b *VolatilityBreaker) liquidationSum(now time.Time) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cutoff := now.Add(-liquidationWindow)
+	kept := b.liqs[:0]
+	sum := 0.0
+	for _, p := range b.liqs {
+		if p.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, p)
+		sum += p.notional
+	}
+	b.liqs = kept
+	return sum
+}
+
+func (b *VolatilityBreaker) evaluate(ctx context.Context, now time.Time) {
+	hot, total := 0, 0
+	maxVol := 0.0
+	for _, sym := range b.symbols {
+		vol, ok := b.symbolVol(ctx, sym)
+		if !ok {
+			continue
+		}
+		total++
+		if vol > b.maxVol {
+			hot++
+		}
+		maxVol = math.Max(maxVol, vol)
+	}
+	liq := b.liquidationSum(now)
+
+	var reasons []string
+	if total > 0 && float64(hot)/float64(total) >= b.ratio {
+		reasons = append(reasons, fmt.Sprintf("%d/%d 个 symbol %s 波动率超过 %.2f%%（最高 %.2f%%）",
+			hot, total, b.interval, b.maxVol*100, maxVol*100))
+	}
+	if b.liqUSD > 0 && liq >= b.liqUSD {
+		reasons = append(reasons, fmt.Sprintf("5 分钟强平额 %.0f USD ≥ %.0f", liq, b.liqUSD))
+	}
+	b.transition(now, reasons)
+}
+
+func (b *VolatilityBreaker) transition(now time.Time, reasons []string) {
+	b.mu.Lock()
+	var msg string
+	switch {
+	case len(reasons) > 0:
+		b.calmSince = time.Time{}
+		reason := strings.Join(reasons, "；")
+		if !b.halted {
+			msg = fmt.Sprintf("⚠️ 波动熔断触发：暂停所有新开仓（平仓照常）\n%s", reason)
+		}
+		b.halted = true
+		b.reason = reason
+	case b.halted:
+		if b.calmSince.IsZero() {
+			b.calmSince = now
+		}
+		if now.Sub(b.calmSince) >= b.resume {
+			b.halted = false
+			b.reason = ""
+			b.calmSince = time.Time{}
+			msg = fmt.Sprintf("✅ 波动熔断解除：行情已恢复正常 %s，恢复开仓", b.resume)
+		}
+	}
+	b.mu.Unlock()
+	if msg == "" {
+		return
+	}
+	logger.Warnf("%s", msg)
+	if b.notifier != nil {
+		if err := b.notifier.SendText(msg); err != nil {
+			logger.Warnf("波动熔断通知发送失败: %v", err)
+		}
+	}
+}
+
+// symbolVol 计算最近 window 根 K 线对数收益率的标准差；优先使用本地缓存，缓存不足时回退到行情源。
+func (b *VolatilityBreaker) symbolVol(ctx context.Context, symbol string) (float64, bool) {
+	need := b.window + 1
+	var candles []market.Candle
+	if b.store != nil {
+		candles, _ = b.store.Get(ctx, symbol, b.interval)
+	}
+	if len(candles) < need && b.source != nil {
+		fetchCtx, cancel := context.WithTimeout(ctx, defaultVolBreakerFetchTime)
+		fetched, err := b.source.FetchHistory(fetchCtx, symbol, b.interval, need)
+		cancel()
+		if err != nil {
+			logger.Debugf("波动熔断: 获取 %s %s K 线失败: %v", symbol, b.interval, err)
+			return 0, false
+		}
+		candles = fetched
+	}
+	return realizedVol(candles, b.window)
+}
+
+func realizedVol(candles []market.Candle, window int) (float64, bool) {
+	if len(candles) < window+1 {
+		return 0, false
+	}
+	tail := candles[len(candles)-window-1:]
+	returns := make([]float64, 0, window)
+	for i := 1; i < len(tail); i++ {
+		prev, cur := tail[i-1].Close, tail[i].Close
+		if prev <= 0 || cur <= 0 {
+			return 0, false
+		}
+		returns = append(returns, math.Log(cur/prev))
+	}
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+	return math.Sqrt(variance), true
+}
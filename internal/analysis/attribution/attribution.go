@@ -0,0 +1,151 @@
+// Package attribution 对已平仓交易做绩效归因：按开仓时的背离信号分组、按所属 profile 横向比较，
+// 或按 profile/symbol/信号汇总命中率、平均 R 与持仓时长等策略 KPI。
+package attribution
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	SignalBullish = "bullish"
+	SignalBearish = "bearish"
+	SignalNone    = "none"
+)
+
+// Trade 为一笔已平仓交易及其开仓快照；Signals 键形如 "rsi_div@1h"，值为 bullish/bearish/none。
+type Trade struct {
+	TradeID  int
+	Side     string
+	PnLUSD   float64
+	PnLRatio float64
+	Signals  map[string]string
+}
+
+// Bucket 为一组交易的结果统计；Expectancy 即平均每笔盈亏（USDT）。
+type Bucket struct {
+	Trades       int     `json:"trades"`
+	Wins         int     `json:"wins"`
+	WinRate      float64 `json:"win_rate"`
+	TotalPnLUSD  float64 `json:"total_pnl_usd"`
+	Expectancy   float64 `json:"expectancy_usd"`
+	AvgPnLRatio  float64 `json:"avg_pnl_ratio"`
+	sumPnLRatios float64
+}
+
+// IndicatorStats 按背离方向与交易方向的关系拆分：顺向（多单遇看涨背离）、逆向、无背离。
+type IndicatorStats struct {
+	Indicator string `json:"indicator"`
+	Aligned   Bucket `json:"aligned"`
+	Opposed   Bucket `json:"opposed"`
+	Absent    Bucket `json:"absent"`
+}
+
+type Report struct {
+	Overall    Bucket           `json:"overall"`
+	Indicators []IndicatorStats `json:"indicators"`
+}
+
+func (b *Bucket) add(t Trade) {
+	b.Trades++
+	if t.PnLUSD > 0 {
+		b.Wins++
+	}
+	b.TotalPnLUSD += t.PnLUSD
+	b.sumPnLRatios += t.PnLRatio
+}
+
+func (b *Bucket) finalize() {
+	if b.Trades == 0 {
+		return
+	}
+	n := float64(b.Trades)
+	b.WinRate = float64(b.Wins) / n
+	b.Expectancy = b.TotalPnLUSD / n
+	b.AvgPnLRatio = b.sumPnLRatios / n
+}
+
+// Compute 为每个周期级指标（如 rsi_div@1h）以及跨周期汇总指标（如 rsi_div，任一周期顺向即视为顺向）生成统计。
+func Compute(trades []Trade) Report {
+	var rep Report
+	stats := make(map[string]*IndicatorStats)
+	keys := make(map[string]struct{})
+	for _, t := range trades {
+		for key := range t.Signals {
+			keys[key] = struct{}{}
+			if base := baseIndicator(key); base != key {
+				keys[base] = struct{}{}
+			}
+		}
+	}
+	for key := range keys {
+		stats[key] = &IndicatorStats{Indicator: key}
+	}
+	for _, t := range trades {
+		rep.Overall.add(t)
+		for key, st := range stats {
+			switch relation(t, key) {
+			case 1:
+				st.Aligned.add(t)
+			case -1:
+				st.Opposed.add(t)
+			default:
+				st.Absent.add(t)
+			}
+		}
+	}
+	rep.Overall.finalize()
+	rep.Indicators = make([]IndicatorStats, 0, len(stats))
+	for _, st := range stats {
+		st.Aligned.finalize()
+		st.Opposed.finalize()
+		st.Absent.finalize()
+		rep.Indicators = append(rep.Indicators, *st)
+	}
+	sort.Slice(rep.Indicators, func(i, j int) bool {
+		return rep.Indicators[i].Indicator < rep.Indicators[j].Indicator
+	})
+	return rep
+}
+
+// relation 返回 1=顺向、-1=逆向、0=无背离；汇总指标下顺向优先于逆向。
+func relation(t Trade, key string) int {
+	if v, ok := t.Signals[key]; ok {
+		return direction(t.Side, v)
+	}
+	out := 0
+	for k, v := range t.Signals {
+		if baseIndicator(k) != key {
+			continue
+		}
+		switch direction(t.Side, v) {
+		case 1:
+			return 1
+		case -1:
+			out = -1
+		}
+	}
+	return out
+}
+
+func direction(side, signal string) int {
+	want := SignalBullish
+	if strings.EqualFold(strings.TrimSpace(side), "short") {
+		want = SignalBearish
+	}
+	switch strings.ToLower(strings.TrimSpace(signal)) {
+	case "", SignalNone:
+		return 0
+	case want:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func baseIndicator(key string) string {
+	if idx := strings.Index(key, "@"); idx > 0 {
+		return key[:idx]
+	}
+	return key
+}
@@ -0,0 +1,194 @@
+package attribution
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// journalMaxTradesPerSymbol 为 Markdown 总结中每个 symbol 列出的交易笔数上限，其余只计入汇总。
+const journalMaxTradesPerSymbol = 10
+
+// JournalEntry 为交易日志中的一笔已平仓交易；TPTiersHit/TPTiers 为分段止盈触发档数/总档数，SLTiersHit 为分段止损触发档数；
+// EntrySlippagePct 为开仓成交价相对决策参考价的不利偏移（正值表示成交更差），HasSlippage=false 表示未记录。
+type JournalEntry struct {
+	TradeID          int
+	Profile          string
+	Symbol           string
+	Side             string
+	EntryPrice       float64
+	ExitPrice        float64
+	Amount           float64
+	OpenedAt         time.Time
+	ClosedAt         time.Time
+	TPTiers          int
+	TPTiersHit       int
+	SLTiersHit       int
+	HitTiers         []string
+	PnLUSD           float64
+	PnLRatio         float64
+	FeeUSD           float64
+	EntrySlippagePct float64
+	HasSlippage      bool
+}
+
+// JournalStats 为一组交易的汇总；AvgSlippagePct 只统计记录了开仓漂移的 SlippageTrades 笔。
+type JournalStats struct {
+	Trades         int
+	Wins           int
+	WinRate        float64
+	PnLUSD         float64
+	FeeUSD         float64
+	AvgPnLRatio    float64
+	TPTiersHit     int
+	SLTiersHit     int
+	SlippageTrades int
+	AvgSlippagePct float64
+
+	sumRatio    float64
+	sumSlippage float64
+}
+
+// JournalSymbol 为单个 symbol 的汇总与交易明细（按平仓时间升序）。
+type JournalSymbol struct {
+	Symbol string
+	JournalStats
+	Entries []JournalEntry
+}
+
+// JournalSummary 为 [From, To) 内平仓交易的汇总，Symbols 按已实现盈亏降序。
+type JournalSummary struct {
+	From    time.Time
+	To      time.Time
+	Overall JournalStats
+	Symbols []JournalSymbol
+	Best    *JournalEntry
+	Worst   *JournalEntry
+}
+
+func (s *JournalStats) add(e JournalEntry) {
+	s.Trades++
+	if e.PnLUSD > 0 {
+		s.Wins++
+	}
+	s.PnLUSD += e.PnLUSD
+	s.FeeUSD += e.FeeUSD
+	s.sumRatio += e.PnLRatio
+	s.TPTiersHit += e.TPTiersHit
+	s.SLTiersHit += e.SLTiersHit
+	if e.HasSlippage {
+		s.SlippageTrades++
+		s.sumSlippage += e.EntrySlippagePct
+	}
+}
+
+func (s *JournalStats) finalize() {
+	if s.Trades > 0 {
+		s.WinRate = float64(s.Wins) / float64(s.Trades)
+		s.AvgPnLRatio = s.sumRatio / float64(s.Trades)
+	}
+	if s.SlippageTrades > 0 {
+		s.AvgSlippagePct = s.sumSlippage / float64(s.SlippageTrades)
+	}
+}
+
+// SummarizeJournal 汇总交易日志的整体与各 symbol 统计，并找出盈亏最大/最小的交易。
+func SummarizeJournal(from, to time.Time, entries []JournalEntry) JournalSummary {
+	sum := JournalSummary{From: from, To: to}
+	bySymbol := make(map[string]*JournalSymbol)
+	for i := range entries {
+		e := entries[i]
+		sum.Overall.add(e)
+		key := strings.ToUpper(strings.TrimSpace(e.Symbol))
+		g, ok := bySymbol[key]
+		if !ok {
+			g = &JournalSymbol{Symbol: key}
+			bySymbol[key] = g
+		}
+		g.add(e)
+		g.Entries = append(g.Entries, e)
+		if sum.Best == nil || e.PnLUSD > sum.Best.PnLUSD {
+			sum.Best = &entries[i]
+		}
+		if sum.Worst == nil || e.PnLUSD < sum.Worst.PnLUSD {
+			sum.Worst = &entries[i]
+		}
+	}
+	sum.Overall.finalize()
+	for _, g := range bySymbol {
+		g.finalize()
+		sort.SliceStable(g.Entries, func(i, j int) bool { return g.Entries[i].ClosedAt.Before(g.Entries[j].ClosedAt) })
+		sum.Symbols = append(sum.Symbols, *g)
+	}
+	sort.Slice(sum.Symbols, func(i, j int) bool {
+		if sum.Symbols[i].PnLUSD != sum.Symbols[j].PnLUSD {
+			return sum.Symbols[i].PnLUSD > sum.Symbols[j].PnLUSD
+		}
+		return sum.Symbols[i].Symbol < sum.Symbols[j].Symbol
+	})
+	return sum
+}
+
+// RenderJournalMarkdown 把汇总渲染为 Markdown：总览、最佳/最差交易与按 symbol 的明细（开平仓价、止盈档位、盈亏、滑点）。
+func RenderJournalMarkdown(sum JournalSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**交易日报** %s → %s UTC\n\n", sum.From.UTC().Format("2006-01-02 15:04"), sum.To.UTC().Format("2006-01-02 15:04"))
+	if sum.Overall.Trades == 0 {
+		b.WriteString("区间内没有平仓交易。")
+		return b.String()
+	}
+	o := sum.Overall
+	fmt.Fprintf(&b, "- 平仓 %d 笔，胜率 %.1f%%（%d 胜）\n", o.Trades, o.WinRate*100, o.Wins)
+	fmt.Fprintf(&b, "- 已实现盈亏 **%s USDT**，手续费 %.2f，平均收益率 %s\n", signedUSD(o.PnLUSD), o.FeeUSD, signedPct(o.AvgPnLRatio))
+	fmt.Fprintf(&b, "- 止盈档触发 %d 次，止损档触发 %d 次\n", o.TPTiersHit, o.SLTiersHit)
+	if o.SlippageTrades > 0 {
+		fmt.Fprintf(&b, "- 平均开仓滑点 %s（%d 笔有记录）\n", signedPct(o.AvgSlippagePct), o.SlippageTrades)
+	}
+	if sum.Best != nil && sum.Worst != nil && sum.Best.TradeID != sum.Worst.TradeID {
+		fmt.Fprintf(&b, "- 最佳 %s #%d %s，最差 %s #%d %s\n",
+			sum.Best.Symbol, sum.Best.TradeID, signedUSD(sum.Best.PnLUSD),
+			sum.Worst.Symbol, sum.Worst.TradeID, signedUSD(sum.Worst.PnLUSD))
+	}
+	for _, g := range sum.Symbols {
+		fmt.Fprintf(&b, "\n**%s** %d 笔 · 胜率 %.0f%% · %s USDT · 止盈档 %d\n", g.Symbol, g.Trades, g.WinRate*100, signedUSD(g.PnLUSD), g.TPTiersHit)
+		for i, e := range g.Entries {
+			if i >= journalMaxTradesPerSymbol {
+				fmt.Fprintf(&b, "- …另有 %d 笔\n", len(g.Entries)-i)
+				break
+			}
+			b.WriteString("- " + journalEntryLine(e) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func journalEntryLine(e JournalEntry) string {
+	line := fmt.Sprintf("#%d %s %s → %s", e.TradeID, strings.ToLower(e.Side), formatJournalPrice(e.EntryPrice), formatJournalPrice(e.ExitPrice))
+	if e.TPTiers > 0 {
+		line += fmt.Sprintf("，止盈 %d/%d", e.TPTiersHit, e.TPTiers)
+	}
+	if e.SLTiersHit > 0 {
+		line += fmt.Sprintf("，止损档 %d", e.SLTiersHit)
+	}
+	line += fmt.Sprintf("，%s（%s）", signedUSD(e.PnLUSD), signedPct(e.PnLRatio))
+	if e.HasSlippage {
+		line += "，滑点 " + signedPct(e.EntrySlippagePct)
+	}
+	return line
+}
+
+func formatJournalPrice(v float64) string {
+	if v <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", v)
+}
+
+func signedUSD(v float64) string {
+	return fmt.Sprintf("%+.2f", v)
+}
+
+func signedPct(v float64) string {
+	return fmt.Sprintf("%+.2f%%", v*100)
+}
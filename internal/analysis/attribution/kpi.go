@@ -0,0 +1,159 @@
+package attribution
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SignalNoneKey 为开仓时没有任何背离信号的交易分组。
+const SignalNoneKey = "none"
+
+// KPITrade 为已归属到 profile 的平仓交易；RiskUSD<=0 表示止损未知，Holding<=0 表示持仓时长未知。
+type KPITrade struct {
+	TradeID  int
+	Profile  string
+	Symbol   string
+	Side     string
+	PnLUSD   float64
+	PnLRatio float64
+	RiskUSD  float64
+	Holding  time.Duration
+	Signals  map[string]string
+}
+
+// KPI 为一组交易的策略指标：HitRate 为盈利笔数占比，AvgR 只统计能还原止损的交易，AvgHoldingMinutes 只统计时长已知的交易。
+type KPI struct {
+	Trades            int     `json:"trades"`
+	Wins              int     `json:"wins"`
+	HitRate           float64 `json:"hit_rate"`
+	TotalPnLUSD       float64 `json:"total_pnl_usd"`
+	AvgPnLUSD         float64 `json:"avg_pnl_usd"`
+	AvgPnLRatio       float64 `json:"avg_pnl_ratio"`
+	RTrades           int     `json:"r_trades"`
+	AvgR              float64 `json:"avg_r"`
+	AvgHoldingMinutes float64 `json:"avg_holding_minutes"`
+
+	sumRatio   float64
+	sumR       float64
+	holdTrades int
+	sumHold    time.Duration
+}
+
+// GroupKPI 为按 Key（profile/symbol/信号）分组的 KPI。
+type GroupKPI struct {
+	Key string `json:"key"`
+	KPI
+}
+
+// KPIReport 中 Signals 按开仓时出现的背离指标分组（如 rsi_div，不分周期与方向），一笔交易可计入多个分组，
+// 无任何背离的交易计入 none。
+type KPIReport struct {
+	Overall  KPI        `json:"overall"`
+	Profiles []GroupKPI `json:"profiles"`
+	Symbols  []GroupKPI `json:"symbols"`
+	Signals  []GroupKPI `json:"signals"`
+}
+
+func (k *KPI) add(t KPITrade) {
+	k.Trades++
+	if t.PnLUSD > 0 {
+		k.Wins++
+	}
+	k.TotalPnLUSD += t.PnLUSD
+	k.sumRatio += t.PnLRatio
+	if t.RiskUSD > 0 {
+		k.RTrades++
+		k.sumR += t.PnLUSD / t.RiskUSD
+	}
+	if t.Holding > 0 {
+		k.holdTrades++
+		k.sumHold += t.Holding
+	}
+}
+
+func (k *KPI) finalize() {
+	if k.Trades > 0 {
+		n := float64(k.Trades)
+		k.HitRate = float64(k.Wins) / n
+		k.AvgPnLUSD = k.TotalPnLUSD / n
+		k.AvgPnLRatio = k.sumRatio / n
+	}
+	if k.RTrades > 0 {
+		k.AvgR = k.sumR / float64(k.RTrades)
+	}
+	if k.holdTrades > 0 {
+		k.AvgHoldingMinutes = k.sumHold.Minutes() / float64(k.holdTrades)
+	}
+}
+
+// ComputeKPIs 汇总整体、各 profile、各 symbol 与各背离信号的 KPI，分组按已实现盈亏降序。
+func ComputeKPIs(trades []KPITrade) KPIReport {
+	var rep KPIReport
+	profiles := make(map[string]*GroupKPI)
+	symbols := make(map[string]*GroupKPI)
+	signals := make(map[string]*GroupKPI)
+	for _, t := range trades {
+		rep.Overall.add(t)
+		groupOf(profiles, t.Profile).add(t)
+		groupOf(symbols, strings.ToUpper(t.Symbol)).add(t)
+		for _, sig := range presentSignals(t.Signals) {
+			groupOf(signals, sig).add(t)
+		}
+	}
+	rep.Overall.finalize()
+	rep.Profiles = sortedGroups(profiles)
+	rep.Symbols = sortedGroups(symbols)
+	rep.Signals = sortedGroups(signals)
+	return rep
+}
+
+// presentSignals 返回开仓时方向非 none 的背离指标（去掉 @周期 后去重），没有时返回 none。
+func presentSignals(signals map[string]string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for key, v := range signals {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", SignalNone:
+			continue
+		}
+		base := baseIndicator(key)
+		if _, ok := seen[base]; ok {
+			continue
+		}
+		seen[base] = struct{}{}
+		out = append(out, base)
+	}
+	if len(out) == 0 {
+		return []string{SignalNoneKey}
+	}
+	return out
+}
+
+func groupOf(groups map[string]*GroupKPI, key string) *KPI {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		key = "unknown"
+	}
+	g, ok := groups[key]
+	if !ok {
+		g = &GroupKPI{Key: key}
+		groups[key] = g
+	}
+	return &g.KPI
+}
+
+func sortedGroups(groups map[string]*GroupKPI) []GroupKPI {
+	out := make([]GroupKPI, 0, len(groups))
+	for _, g := range groups {
+		g.finalize()
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TotalPnLUSD != out[j].TotalPnLUSD {
+			return out[i].TotalPnLUSD > out[j].TotalPnLUSD
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
@@ -0,0 +1,75 @@
+package attribution
+
+import "sort"
+
+// ProfileTrade 为已归属到 profile 的平仓交易；RiskUSD 为开仓时止损对应的风险金额，<=0 表示未知。
+type ProfileTrade struct {
+	Profile  string
+	PnLUSD   float64
+	PnLRatio float64
+	RiskUSD  float64
+}
+
+// ProfileRound 为一次决策轮次在某个 profile 下的贡献。
+type ProfileRound struct {
+	Profile    string
+	Decisions  int
+	Opens      int
+	ModelCalls int
+}
+
+// ProfileStats 为单个 profile 在统计区间内的决策与交易结果；AvgR 仅统计能还原止损的交易。
+type ProfileStats struct {
+	Profile    string  `json:"profile"`
+	Rounds     int     `json:"rounds"`
+	Decisions  int     `json:"decisions"`
+	Opens      int     `json:"opens"`
+	ModelCalls int     `json:"model_calls"`
+	Outcome    Bucket  `json:"outcome"`
+	RTrades    int     `json:"r_trades"`
+	AvgR       float64 `json:"avg_r"`
+	sumR       float64
+}
+
+// CompareProfiles 按 profile 汇总，结果按已实现盈亏降序。
+func CompareProfiles(rounds []ProfileRound, trades []ProfileTrade) []ProfileStats {
+	stats := make(map[string]*ProfileStats)
+	get := func(name string) *ProfileStats {
+		st, ok := stats[name]
+		if !ok {
+			st = &ProfileStats{Profile: name}
+			stats[name] = st
+		}
+		return st
+	}
+	for _, r := range rounds {
+		st := get(r.Profile)
+		st.Rounds++
+		st.Decisions += r.Decisions
+		st.Opens += r.Opens
+		st.ModelCalls += r.ModelCalls
+	}
+	for _, t := range trades {
+		st := get(t.Profile)
+		st.Outcome.add(Trade{PnLUSD: t.PnLUSD, PnLRatio: t.PnLRatio})
+		if t.RiskUSD > 0 {
+			st.RTrades++
+			st.sumR += t.PnLUSD / t.RiskUSD
+		}
+	}
+	out := make([]ProfileStats, 0, len(stats))
+	for _, st := range stats {
+		st.Outcome.finalize()
+		if st.RTrades > 0 {
+			st.AvgR = st.sumR / float64(st.RTrades)
+		}
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Outcome.TotalPnLUSD != out[j].Outcome.TotalPnLUSD {
+			return out[i].Outcome.TotalPnLUSD > out[j].Outcome.TotalPnLUSD
+		}
+		return out[i].Profile < out[j].Profile
+	})
+	return out
+}
@@ -0,0 +1,383 @@
+package formula
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/markcheno/go-talib"
+
+	"brale/internal/market"
+)
+
+// 函数的预热类型，Warmup 的 weigh 回调据此换算收敛所需根数。
+const (
+	WarmupEMA    = "ema"
+	WarmupWilder = "wilder"
+	WarmupWindow = "window"
+)
+
+var seriesFields = map[string]func(market.Candle) float64{
+	"open":   func(c market.Candle) float64 { return c.Open },
+	"high":   func(c market.Candle) float64 { return c.High },
+	"low":    func(c market.Candle) float64 { return c.Low },
+	"close":  func(c market.Candle) float64 { return c.Close },
+	"volume": func(c market.Candle) float64 { return c.Volume },
+	"hl2":    func(c market.Candle) float64 { return (c.High + c.Low) / 2 },
+	"hlc3":   func(c market.Candle) float64 { return (c.High + c.Low + c.Close) / 3 },
+	"ohlc4":  func(c market.Candle) float64 { return (c.Open + c.High + c.Low + c.Close) / 4 },
+}
+
+// SeriesNames 返回可直接引用的内置序列。
+func SeriesNames() []string {
+	out := make([]string, 0, len(seriesFields))
+	for k := range seriesFields {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// funcSpec 描述一个函数：period=true 时最后一个参数为周期常量，source=true 时周期前可选一个源序列（缺省 close）。
+type funcSpec struct {
+	args   int
+	period bool
+	source bool
+	warmup string
+}
+
+var funcs = map[string]funcSpec{
+	"ema":        {period: true, source: true, warmup: WarmupEMA},
+	"sma":        {period: true, source: true, warmup: WarmupWindow},
+	"wma":        {period: true, source: true, warmup: WarmupWindow},
+	"rsi":        {period: true, source: true, warmup: WarmupWilder},
+	"stddev":     {period: true, source: true, warmup: WarmupWindow},
+	"highest":    {period: true, source: true, warmup: WarmupWindow},
+	"lowest":     {period: true, source: true, warmup: WarmupWindow},
+	"roc":        {period: true, source: true, warmup: WarmupWindow},
+	"atr":        {period: true, warmup: WarmupWilder},
+	"shift":      {args: 1, period: true, warmup: WarmupWindow},
+	"abs":        {args: 1},
+	"min":        {args: 2},
+	"max":        {args: 2},
+	"crossover":  {args: 2},
+	"crossunder": {args: 2},
+}
+
+type node interface {
+	eval(candles []market.Candle) []float64
+	warmup(weigh func(kind string, period int) int) int
+}
+
+type numNode float64
+
+type seriesNode string
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+type callNode struct {
+	name   string
+	spec   funcSpec
+	args   []node
+	period int
+}
+
+func newCallNode(name string, args []node) (node, error) {
+	spec, ok := funcs[name]
+	if !ok {
+		return nil, fmt.Errorf("formula 未知函数 %s", name)
+	}
+	call := callNode{name: name, spec: spec}
+	if spec.period {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("formula %s 缺少周期参数", name)
+		}
+		n, ok := args[len(args)-1].(numNode)
+		if !ok || float64(n) < 1 || float64(n) != math.Trunc(float64(n)) {
+			return nil, fmt.Errorf("formula %s 的周期必须是正整数常量", name)
+		}
+		call.period = int(n)
+		args = args[:len(args)-1]
+	}
+	switch {
+	case spec.source && len(args) == 0:
+		args = []node{seriesNode("close")}
+	case spec.source && len(args) == 1:
+	case !spec.source && len(args) == spec.args:
+	default:
+		return nil, fmt.Errorf("formula %s 参数个数错误", name)
+	}
+	call.args = args
+	return call, nil
+}
+
+// Warmup 返回表达式得到稳定值所需的 K 线根数：嵌套函数沿调用链累加，分支取最大；
+// weigh 把函数预热类型（WarmupEMA/WarmupWilder/WarmupWindow）与周期换算为根数。
+func (p *Program) Warmup(weigh func(kind string, period int) int) int {
+	if p == nil || p.root == nil {
+		return 0
+	}
+	return p.root.warmup(weigh)
+}
+
+// Eval 逐根求值，结果与 candles 对齐；预热不足或除零的位置为 NaN。
+func (p *Program) Eval(candles []market.Candle) []float64 {
+	if p == nil || p.root == nil || len(candles) == 0 {
+		return nil
+	}
+	return p.root.eval(candles)
+}
+
+// Last 返回最后一个有效值；最新一根为 NaN 时 ok=false。
+func Last(series []float64) (float64, bool) {
+	if len(series) == 0 {
+		return 0, false
+	}
+	v := series[len(series)-1]
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func (n numNode) eval(candles []market.Candle) []float64 {
+	out := make([]float64, len(candles))
+	for i := range out {
+		out[i] = float64(n)
+	}
+	return out
+}
+
+func (n numNode) warmup(func(string, int) int) int { return 0 }
+
+func (n seriesNode) eval(candles []market.Candle) []float64 {
+	get := seriesFields[string(n)]
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = get(c)
+	}
+	return out
+}
+
+func (n seriesNode) warmup(func(string, int) int) int { return 0 }
+
+func (n unaryNode) eval(candles []market.Candle) []float64 {
+	out := n.operand.eval(candles)
+	for i, v := range out {
+		switch {
+		case math.IsNaN(v):
+		case n.op == "-":
+			out[i] = -v
+		default:
+			out[i] = boolFloat(v == 0)
+		}
+	}
+	return out
+}
+
+func (n unaryNode) warmup(weigh func(string, int) int) int { return n.operand.warmup(weigh) }
+
+func (n binaryNode) eval(candles []market.Candle) []float64 {
+	a, b := n.left.eval(candles), n.right.eval(candles)
+	out := make([]float64, len(a))
+	for i := range out {
+		x, y := a[i], b[i]
+		if math.IsNaN(x) || math.IsNaN(y) {
+			out[i] = math.NaN()
+			continue
+		}
+		switch n.op {
+		case "+":
+			out[i] = x + y
+		case "-":
+			out[i] = x - y
+		case "*":
+			out[i] = x * y
+		case "/":
+			if y == 0 {
+				out[i] = math.NaN()
+			} else {
+				out[i] = x / y
+			}
+		case ">":
+			out[i] = boolFloat(x > y)
+		case ">=":
+			out[i] = boolFloat(x >= y)
+		case "<":
+			out[i] = boolFloat(x < y)
+		case "<=":
+			out[i] = boolFloat(x <= y)
+		case "==":
+			out[i] = boolFloat(x == y)
+		case "!=":
+			out[i] = boolFloat(x != y)
+		case "&&":
+			out[i] = boolFloat(x != 0 && y != 0)
+		case "||":
+			out[i] = boolFloat(x != 0 || y != 0)
+		}
+	}
+	return out
+}
+
+func (n binaryNode) warmup(weigh func(string, int) int) int {
+	return max(n.left.warmup(weigh), n.right.warmup(weigh))
+}
+
+func (n callNode) warmup(weigh func(string, int) int) int {
+	inner := 0
+	for _, a := range n.args {
+		inner = max(inner, a.warmup(weigh))
+	}
+	if !n.spec.period {
+		if n.name == "crossover" || n.name == "crossunder" {
+			return inner + 1
+		}
+		return inner
+	}
+	own := n.period
+	if weigh != nil {
+		own = weigh(n.spec.warmup, n.period)
+	}
+	return inner + own
+}
+
+func (n callNode) eval(candles []market.Candle) []float64 {
+	switch n.name {
+	case "atr":
+		if len(candles) <= n.period {
+			return nanSeries(len(candles))
+		}
+		high, low, closes := make([]float64, len(candles)), make([]float64, len(candles)), make([]float64, len(candles))
+		for i, c := range candles {
+			high[i], low[i], closes[i] = c.High, c.Low, c.Close
+		}
+		return fillLookback(talib.Atr(high, low, closes, n.period), n.period, len(candles))
+	case "abs":
+		out := n.args[0].eval(candles)
+		for i, v := range out {
+			out[i] = math.Abs(v)
+		}
+		return out
+	case "min", "max":
+		a, b := n.args[0].eval(candles), n.args[1].eval(candles)
+		for i := range a {
+			if n.name == "min" {
+				a[i] = math.Min(a[i], b[i])
+			} else {
+				a[i] = math.Max(a[i], b[i])
+			}
+		}
+		return a
+	case "crossover", "crossunder":
+		a, b := n.args[0].eval(candles), n.args[1].eval(candles)
+		out := make([]float64, len(a))
+		out[0] = math.NaN()
+		for i := 1; i < len(a); i++ {
+			if math.IsNaN(a[i]) || math.IsNaN(b[i]) || math.IsNaN(a[i-1]) || math.IsNaN(b[i-1]) {
+				out[i] = math.NaN()
+				continue
+			}
+			if n.name == "crossover" {
+				out[i] = boolFloat(a[i-1] <= b[i-1] && a[i] > b[i])
+			} else {
+				out[i] = boolFloat(a[i-1] >= b[i-1] && a[i] < b[i])
+			}
+		}
+		return out
+	case "shift":
+		src := n.args[0].eval(candles)
+		out := make([]float64, len(src))
+		for i := range out {
+			if i < n.period {
+				out[i] = math.NaN()
+			} else {
+				out[i] = src[i-n.period]
+			}
+		}
+		return out
+	}
+	return onValid(n.args[0].eval(candles), func(src []float64) []float64 { return n.apply(src) })
+}
+
+// apply 在没有前导 NaN 的源序列上计算周期函数，预热不足的位置为 NaN。
+func (n callNode) apply(src []float64) []float64 {
+	p := n.period
+	if len(src) <= p {
+		return nanSeries(len(src))
+	}
+	switch n.name {
+	case "ema":
+		return fillLookback(talib.Ema(src, p), p-1, len(src))
+	case "sma":
+		return fillLookback(talib.Sma(src, p), p-1, len(src))
+	case "wma":
+		return fillLookback(talib.Wma(src, p), p-1, len(src))
+	case "rsi":
+		return fillLookback(talib.Rsi(src, p), p, len(src))
+	case "stddev":
+		return fillLookback(talib.StdDev(src, p, 1), p-1, len(src))
+	case "highest":
+		return fillLookback(talib.Max(src, p), p-1, len(src))
+	case "lowest":
+		return fillLookback(talib.Min(src, p), p-1, len(src))
+	case "roc":
+		out := make([]float64, len(src))
+		for i := range out {
+			if i < p || src[i-p] == 0 {
+				out[i] = math.NaN()
+			} else {
+				out[i] = (src[i] - src[i-p]) / src[i-p]
+			}
+		}
+		return out
+	}
+	return nanSeries(len(src))
+}
+
+// onValid 跳过源序列的前导 NaN（嵌套指标的预热段）后再计算，结果重新对齐到原长度。
+func onValid(src []float64, fn func([]float64) []float64) []float64 {
+	start := 0
+	for start < len(src) && math.IsNaN(src[start]) {
+		start++
+	}
+	out := nanSeries(len(src))
+	if start >= len(src) {
+		return out
+	}
+	copy(out[start:], fn(src[start:]))
+	return out
+}
+
+// fillLookback 把 talib 输出的前 lookback 个占位值置为 NaN；数据不足时 talib 可能返回空切片。
+func fillLookback(vals []float64, lookback, n int) []float64 {
+	out := nanSeries(n)
+	if len(vals) != n || lookback >= n {
+		return out
+	}
+	copy(out[lookback:], vals[lookback:])
+	return out
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
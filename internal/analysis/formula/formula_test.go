@@ -0,0 +1,320 @@
+package formula
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"brale/internal/market"
+)
+
+// candlesFromCloses 构造 open=high=low=close 的 K 线，便于按收盘价断言。
+func candlesFromCloses(closes ...float64) []market.Candle {
+	out := make([]market.Candle, len(closes))
+	for i, c := range closes {
+		out[i] = market.Candle{OpenTime: int64(i) * 60000, Open: c, High: c, Low: c, Close: c, Volume: 1}
+	}
+	return out
+}
+
+func evalLast(t *testing.T, src string, candles []market.Candle) float64 {
+	t.Helper()
+	prog, err := Compile(src)
+	require.NoError(t, err, src)
+	out := prog.Eval(candles)
+	require.Len(t, out, len(candles), src)
+	return out[len(out)-1]
+}
+
+func TestCompile_Precedence(t *testing.T) {
+	candles := candlesFromCloses(10)
+	cases := []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"24 / 4 / 2", 3},
+		{"-2 * 3", -6},
+		{"- -2", 2},
+		{"+close", 10},
+		{"close - 2 * 3", 4},
+		{"1 + 2 > 2", 1},
+		{"1 + 2 > 2 * 2", 0},
+		{"1 < 2 && 3 < 4", 1},
+		{"1 > 2 || 3 < 4", 1},
+		{"1 > 2 || 3 < 4 && 5 < 4", 0},
+		{"(1 > 2 || 3 < 4) && 5 > 4", 1},
+		{"!0 + 1", 0},
+		{"!(0 + 1)", 0},
+		{"!close > 20", 1},
+		{"!1 || 1", 1},
+		{"!!close", 1},
+		{"close == 10", 1},
+		{"close != 10", 0},
+		{"close >= 10 && close <= 10", 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.src, func(t *testing.T) {
+			assert.Equal(t, tc.want, evalLast(t, tc.src, candles))
+		})
+	}
+}
+
+func TestCompile_LogicalKeywords(t *testing.T) {
+	candles := candlesFromCloses(10)
+	cases := []struct {
+		src  string
+		want float64
+	}{
+		{"1 and 1", 1},
+		{"1 and 0", 0},
+		{"0 or 1", 1},
+		{"0 or 0", 0},
+		{"not 0", 1},
+		{"not 5", 0},
+		{"NOT close > 20", 1},
+		{"close > 5 AND close < 20", 1},
+		{"not close > 5 or close < 20", 1},
+		{"not close > 5 and close < 20", 0},
+		{"not (close > 5 or close < 20)", 0},
+		{"not not close > 5", 1},
+		{"0 or 1 and 0", 0},
+		{"1 && not 0", 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.src, func(t *testing.T) {
+			assert.Equal(t, tc.want, evalLast(t, tc.src, candles))
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	cases := []struct {
+		src     string
+		errPart string
+	}{
+		{"", "不能为空"},
+		{"   ", "不能为空"},
+		{"foo(14)", "未知函数 foo"},
+		{"close(14)", "未知函数 close"},
+		{"foo", "未知序列 foo"},
+		{"ema(0)", "正整数常量"},
+		{"ema(2.5)", "正整数常量"},
+		{"ema(-3)", "正整数常量"},
+		{"ema(close)", "正整数常量"},
+		{"ema(close, 1 + 2)", "正整数常量"},
+		{"ema()", "缺少周期参数"},
+		{"atr(close, 14)", "参数个数错误"},
+		{"ema(close, open, 14)", "参数个数错误"},
+		{"abs(close, open)", "参数个数错误"},
+		{"crossover(close)", "参数个数错误"},
+		{"shift(2)", "参数个数错误"},
+		{"1 +", "意外结束"},
+		{"(1 + 2", "缺少 )"},
+		{"ema(close, 14", "缺少 )"},
+		{"1 < 2 < 3", "多余的"},
+		{"close $ 2", "字符无效"},
+		{"1..2", "数字无效"},
+		{")", "意外的"},
+		{"1 + not 0", "意外的"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.src, func(t *testing.T) {
+			_, err := Compile(tc.src)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errPart)
+		})
+	}
+}
+
+func TestEval_WarmupAlignedWithNaN(t *testing.T) {
+	candles := candlesFromCloses(1, 2, 3, 4, 5, 6, 7, 8)
+	cases := []struct {
+		src     string
+		leadNaN int
+	}{
+		{"close", 0},
+		{"sma(3)", 2},
+		{"ema(close, 3)", 2},
+		{"highest(3)", 2},
+		{"shift(close, 2)", 2},
+		{"roc(3)", 3},
+		{"rsi(3)", 3},
+		{"atr(3)", 3},
+		// 嵌套：外层在内层首个有效值之后再预热
+		{"sma(sma(3), 3)", 4},
+		{"shift(sma(3), 1)", 3},
+		// 二元运算取两侧预热较长者
+		{"sma(2) - sma(4)", 3},
+		{"sma(4) > close", 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.src, func(t *testing.T) {
+			prog, err := Compile(tc.src)
+			require.NoError(t, err)
+			out := prog.Eval(candles)
+			require.Len(t, out, len(candles))
+			for i, v := range out {
+				if i < tc.leadNaN {
+					assert.Truef(t, math.IsNaN(v), "index %d want NaN, got %v", i, v)
+				} else {
+					assert.Falsef(t, math.IsNaN(v), "index %d want value, got NaN", i)
+				}
+			}
+		})
+	}
+
+	prog, err := Compile("sma(3)")
+	require.NoError(t, err)
+	out := prog.Eval(candles)
+	assert.InDelta(t, 2.0, out[2], 1e-9)
+	assert.InDelta(t, 7.0, out[7], 1e-9)
+
+	prog, err = Compile("sma(sma(3), 3)")
+	require.NoError(t, err)
+	out = prog.Eval(candles)
+	assert.InDelta(t, 3.0, out[4], 1e-9)
+
+	prog, err = Compile("sma(20)")
+	require.NoError(t, err)
+	for _, v := range prog.Eval(candles) {
+		assert.True(t, math.IsNaN(v))
+	}
+	_, ok := Last(prog.Eval(candles))
+	assert.False(t, ok)
+}
+
+func TestEval_CrossAtSeriesEdges(t *testing.T) {
+	cases := []struct {
+		name   string
+		src    string
+		closes []float64
+		want   []float64
+	}{
+		{
+			name:   "crossover on last bar",
+			src:    "crossover(close, 5)",
+			closes: []float64{4, 4, 5, 6},
+			want:   []float64{math.NaN(), 0, 0, 1},
+		},
+		{
+			name:   "crossover on second bar",
+			src:    "crossover(close, 5)",
+			closes: []float64{5, 6, 7},
+			want:   []float64{math.NaN(), 1, 0},
+		},
+		{
+			name:   "touch without crossing",
+			src:    "crossover(close, 5)",
+			closes: []float64{4, 5, 4},
+			want:   []float64{math.NaN(), 0, 0},
+		},
+		{
+			name:   "crossunder on last bar",
+			src:    "crossunder(close, 5)",
+			closes: []float64{6, 6, 5, 4},
+			want:   []float64{math.NaN(), 0, 0, 1},
+		},
+		{
+			name:   "single bar has no previous",
+			src:    "crossover(close, 5)",
+			closes: []float64{6},
+			want:   []float64{math.NaN()},
+		},
+		{
+			name:   "first valid bar after warmup has no previous",
+			src:    "crossover(close, sma(2))",
+			closes: []float64{1, 1, 3, 2},
+			want:   []float64{math.NaN(), math.NaN(), 1, 0},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			prog, err := Compile(tc.src)
+			require.NoError(t, err)
+			out := prog.Eval(candlesFromCloses(tc.closes...))
+			require.Len(t, out, len(tc.want))
+			for i := range tc.want {
+				if math.IsNaN(tc.want[i]) {
+					assert.Truef(t, math.IsNaN(out[i]), "index %d want NaN, got %v", i, out[i])
+				} else {
+					assert.Equalf(t, tc.want[i], out[i], "index %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestEval_DivisionByZero(t *testing.T) {
+	candles := candlesFromCloses(0, 2, 0)
+	prog, err := Compile("10 / close")
+	require.NoError(t, err)
+	out := prog.Eval(candles)
+	assert.True(t, math.IsNaN(out[0]))
+	assert.Equal(t, 5.0, out[1])
+	assert.True(t, math.IsNaN(out[2]))
+	_, ok := Last(out)
+	assert.False(t, ok)
+
+	cases := []string{
+		"1 / 0",
+		"(1 / 0) + 1",
+		"(1 / 0) > 0",
+		"(1 / 0) && 1",
+		"abs(1 / 0)",
+		"max(1 / 0, 1)",
+		"-(1 / 0)",
+		"roc(close, 1)",
+	}
+	for _, src := range cases {
+		t.Run(src, func(t *testing.T) {
+			assert.True(t, math.IsNaN(evalLast(t, src, candlesFromCloses(0, 0))))
+		})
+	}
+}
+
+func TestProgram_Warmup(t *testing.T) {
+	weigh := func(kind string, period int) int {
+		if kind == WarmupWilder {
+			return period * 5
+		}
+		return period
+	}
+	cases := []struct {
+		src  string
+		want int
+	}{
+		{"close", 0},
+		{"ema(20)", 20},
+		{"rsi(14)", 70},
+		{"ema(rsi(14), 10)", 80},
+		{"ema(20) - ema(50)", 50},
+		{"crossover(ema(5), ema(10))", 11},
+		{"abs(atr(14))", 70},
+	}
+	for _, tc := range cases {
+		t.Run(tc.src, func(t *testing.T) {
+			prog, err := Compile(tc.src)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, prog.Warmup(weigh))
+		})
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"trend_gap": true,
+		"_x":        true,
+		"ema20":     true,
+		"":          false,
+		"9lives":    false,
+		"Trend":     false,
+		"a-b":       false,
+	} {
+		assert.Equal(t, want, ValidName(name), name)
+	}
+}
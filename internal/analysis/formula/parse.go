@@ -0,0 +1,340 @@
+// Package formula 实现 profile 自定义指标的表达式语言：在内置序列（open/high/low/close/volume 等）与
+// 指标函数（ema/sma/rsi/atr 等）之上做四则运算、比较与逻辑组合，逐根求值得到一条序列。
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Program 为编译后的表达式。
+type Program struct {
+	src  string
+	root node
+}
+
+// Source 返回原始表达式。
+func (p *Program) Source() string { return p.src }
+
+// Compile 解析表达式，例如 "(ema(20) - ema(50)) / atr(14)"、"rsi(14) < 30 && close > ema(200)"。
+// 比较与逻辑运算结果为 1/0，优先级由高到低为 算术 > 比较 > not > and > or；指标函数的周期参数必须是正整数常量。
+func Compile(src string) (*Program, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("formula 不能为空")
+	}
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("formula 第 %d 个字符处多余的 %q", p.peek().pos+1, p.peek().text)
+	}
+	return &Program{src: src, root: root}, nil
+}
+
+// ValidName 报告 name 是否可作为自定义指标的特征名（小写字母或下划线开头，仅含小写字母、数字、下划线）。
+func ValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z'):
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokKind
+	text string
+	num  float64
+	pos  int
+}
+
+var twoCharOps = []string{">=", "<=", "==", "!=", "&&", "||"}
+
+func tokenize(src string) ([]token, error) {
+	var out []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			v, err := strconv.ParseFloat(string(rs[i:j]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("formula 第 %d 个字符处数字无效: %s", i+1, string(rs[i:j]))
+			}
+			out = append(out, token{kind: tokNum, text: string(rs[i:j]), num: v, pos: i})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_') {
+				j++
+			}
+			word := strings.ToLower(string(rs[i:j]))
+			switch word {
+			case "and":
+				out = append(out, token{kind: tokOp, text: "&&", pos: i})
+			case "or":
+				out = append(out, token{kind: tokOp, text: "||", pos: i})
+			case "not":
+				out = append(out, token{kind: tokOp, text: "!", pos: i})
+			default:
+				out = append(out, token{kind: tokIdent, text: word, pos: i})
+			}
+			i = j
+		case r == '(':
+			out = append(out, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			out = append(out, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			out = append(out, token{kind: tokComma, text: ",", pos: i})
+			i++
+		default:
+			if i+1 < len(rs) {
+				pair := string(rs[i : i+2])
+				matched := false
+				for _, op := range twoCharOps {
+					if pair == op {
+						out = append(out, token{kind: tokOp, text: op, pos: i})
+						i += 2
+						matched = true
+						break
+					}
+				}
+				if matched {
+					continue
+				}
+			}
+			if strings.ContainsRune("+-*/<>!", r) {
+				out = append(out, token{kind: tokOp, text: string(r), pos: i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("formula 第 %d 个字符无效: %q", i+1, string(r))
+		}
+	}
+	return append(out, token{kind: tokEOF, pos: len(rs)}), nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseNot 让 !/not 的优先级低于比较：not close > ema(20) 等价于 not (close > ema(20))。
+func (p *parser) parseNot() (node, error) {
+	if op, ok := p.acceptOp("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp(">=", "<=", "==", "!=", ">", "<")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseAdd() (node, error) {
+	left, err := p.parseMul()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMul()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMul() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.acceptOp("-", "+"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "+" {
+			return operand, nil
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		return numNode(t.num), nil
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("formula 第 %d 个字符处缺少 )", t.pos+1)
+		}
+		return inner, nil
+	case tokIdent:
+		if p.peek().kind != tokLParen {
+			if _, ok := seriesFields[t.text]; !ok {
+				return nil, fmt.Errorf("formula 未知序列 %s（可用 %s）", t.text, strings.Join(SeriesNames(), "/"))
+			}
+			return seriesNode(t.text), nil
+		}
+		p.next()
+		var args []node
+		if p.peek().kind != tokRParen {
+			for {
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.peek().kind != tokComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("formula %s( 缺少 )", t.text)
+		}
+		return newCallNode(t.text, args)
+	case tokEOF:
+		return nil, fmt.Errorf("formula 意外结束")
+	default:
+		return nil, fmt.Errorf("formula 第 %d 个字符处意外的 %q", t.pos+1, t.text)
+	}
+}
@@ -0,0 +1,75 @@
+package indicator
+
+import (
+	"fmt"
+
+	"github.com/markcheno/go-talib"
+)
+
+const defaultADXPeriod = 14
+
+// 趋势强度分档阈值（ADX）：<20 无趋势，20~25 弱趋势，25~50 强趋势，>=50 极强趋势。
+const (
+	adxWeakThreshold       = 20
+	adxStrongThreshold     = 25
+	adxVeryStrongThreshold = 50
+)
+
+// ADX 为最新一根 K 线上的 DMI/ADX 读数；Direction 取 +DI 与 -DI 的相对大小（bullish/bearish/flat）。
+type ADX struct {
+	Period    int       `json:"period"`
+	ADX       float64   `json:"adx"`
+	PlusDI    float64   `json:"plus_di"`
+	MinusDI   float64   `json:"minus_di"`
+	Strength  string    `json:"strength"`
+	Direction string    `json:"direction"`
+	Series    []float64 `json:"series,omitempty"`
+}
+
+// ComputeADX 计算 Wilder ADX 与 ±DI；ADX 需二次平滑，历史至少 2*period+1 根。
+func ComputeADX(highs, lows, closes []float64, period int) (*ADX, error) {
+	if period <= 0 {
+		period = defaultADXPeriod
+	}
+	n := len(closes)
+	required := 2*period + 1
+	if n < required || len(highs) != n || len(lows) != n {
+		return nil, fmt.Errorf("adx 需要至少 %d 根 K 线，当前 %d", required, n)
+	}
+	series := sanitizeSeries(talib.Adx(highs, lows, closes, period))
+	plus := lastValid(sanitizeSeries(talib.PlusDI(highs, lows, closes, period)))
+	minus := lastValid(sanitizeSeries(talib.MinusDI(highs, lows, closes, period)))
+	// talib 在预热区输出 0，只保留有效段。
+	series = series[min(len(series), 2*period-1):]
+	latest := lastValid(series)
+	direction := "flat"
+	switch {
+	case plus > minus:
+		direction = "bullish"
+	case minus > plus:
+		direction = "bearish"
+	}
+	return &ADX{
+		Period:    period,
+		ADX:       latest,
+		PlusDI:    plus,
+		MinusDI:   minus,
+		Strength:  ADXStrength(latest),
+		Direction: direction,
+		Series:    series,
+	}, nil
+}
+
+// ADXStrength 把 ADX 数值分为 no-trend/weak/strong/very-strong。
+func ADXStrength(adx float64) string {
+	switch {
+	case adx >= adxVeryStrongThreshold:
+		return "very-strong"
+	case adx >= adxStrongThreshold:
+		return "strong"
+	case adx >= adxWeakThreshold:
+		return "weak"
+	default:
+		return "no-trend"
+	}
+}
@@ -0,0 +1,137 @@
+package indicator
+
+import "fmt"
+
+type IchimokuSettings struct {
+	Tenkan       int `json:"tenkan,omitempty"`
+	Kijun        int `json:"kijun,omitempty"`
+	SenkouB      int `json:"senkou_b,omitempty"`
+	Displacement int `json:"displacement,omitempty"`
+}
+
+// Ichimoku 为最新一根 K 线上的一目均衡表读数。
+// SenkouA/SenkouB 是当前价格所对应的云（displacement 根之前计算、前移后的值），
+// FutureSenkouA/FutureSenkouB 是最新一根计算出的、displacement 根之后的云。
+type Ichimoku struct {
+	Tenkan        float64 `json:"tenkan"`
+	Kijun         float64 `json:"kijun"`
+	SenkouA       float64 `json:"senkou_a"`
+	SenkouB       float64 `json:"senkou_b"`
+	FutureSenkouA float64 `json:"future_senkou_a"`
+	FutureSenkouB float64 `json:"future_senkou_b"`
+	// Chikou 为最新收盘价（绘制在 displacement 根之前），ChikouRef 为被比较的当时收盘价。
+	Chikou    float64 `json:"chikou"`
+	ChikouRef float64 `json:"chikou_ref"`
+	// PriceVsCloud: above/below/inside；CloudColor/FutureCloudColor: bullish(A>B)/bearish/flat。
+	PriceVsCloud     string `json:"price_vs_cloud"`
+	CloudColor       string `json:"cloud_color"`
+	FutureCloudColor string `json:"future_cloud_color"`
+	// TKCross: bullish/bearish 表示最新一根发生转换线/基准线交叉，否则为空。
+	TKCross     string `json:"tk_cross,omitempty"`
+	ChikouState string `json:"chikou_state"`
+	// TwistInBars 为距下一次云层扭转（A/B 交叉）的 K 线数，-1 表示已计算的前移云内没有扭转。
+	TwistInBars int              `json:"twist_in_bars"`
+	Settings    IchimokuSettings `json:"settings"`
+}
+
+func (s IchimokuSettings) withDefaults() IchimokuSettings {
+	if s.Tenkan <= 0 {
+		s.Tenkan = 9
+	}
+	if s.Kijun <= 0 {
+		s.Kijun = 26
+	}
+	if s.SenkouB <= 0 {
+		s.SenkouB = 52
+	}
+	if s.Displacement <= 0 {
+		s.Displacement = 26
+	}
+	return s
+}
+
+// ComputeIchimoku 计算最新一根的一目均衡表；历史需至少 senkou_b + displacement 根。
+func ComputeIchimoku(highs, lows, closes []float64, cfg IchimokuSettings) (*Ichimoku, error) {
+	cfg = cfg.withDefaults()
+	n := len(closes)
+	required := max(cfg.SenkouB, cfg.Kijun, cfg.Tenkan) + cfg.Displacement
+	if n < required || len(highs) != n || len(lows) != n {
+		return nil, fmt.Errorf("ichimoku 需要至少 %d 根 K 线，当前 %d", required, n)
+	}
+	tenkan := donchianMid(highs, lows, cfg.Tenkan)
+	kijun := donchianMid(highs, lows, cfg.Kijun)
+	senkouB := donchianMid(highs, lows, cfg.SenkouB)
+	senkouA := make([]float64, n)
+	for i := range senkouA {
+		senkouA[i] = (tenkan[i] + kijun[i]) / 2
+	}
+
+	last := n - 1
+	shifted := last - cfg.Displacement
+	out := &Ichimoku{
+		Tenkan:        round4(tenkan[last]),
+		Kijun:         round4(kijun[last]),
+		SenkouA:       round4(senkouA[shifted]),
+		SenkouB:       round4(senkouB[shifted]),
+		FutureSenkouA: round4(senkouA[last]),
+		FutureSenkouB: round4(senkouB[last]),
+		Chikou:        round4(closes[last]),
+		ChikouRef:     round4(closes[shifted]),
+		TwistInBars:   -1,
+		Settings:      cfg,
+	}
+	out.CloudColor = cloudColor(senkouA[shifted], senkouB[shifted])
+	out.FutureCloudColor = cloudColor(senkouA[last], senkouB[last])
+	top, bottom := max(senkouA[shifted], senkouB[shifted]), min(senkouA[shifted], senkouB[shifted])
+	switch price := closes[last]; {
+	case price > top:
+		out.PriceVsCloud = "above"
+	case price < bottom:
+		out.PriceVsCloud = "below"
+	default:
+		out.PriceVsCloud = "inside"
+	}
+	out.ChikouState = relativeState(closes[last], closes[shifted])
+	if last > 0 {
+		prev, cur := tenkan[last-1]-kijun[last-1], tenkan[last]-kijun[last]
+		switch {
+		case prev <= 0 && cur > 0:
+			out.TKCross = "bullish"
+		case prev >= 0 && cur < 0:
+			out.TKCross = "bearish"
+		}
+	}
+	// 第 k 根之后的云由 shifted+k 处的 A/B 决定，最远可看到 displacement 根。
+	current := cloudColor(senkouA[shifted], senkouB[shifted])
+	for k := 1; k <= cfg.Displacement; k++ {
+		if color := cloudColor(senkouA[shifted+k], senkouB[shifted+k]); color != current && color != "flat" {
+			out.TwistInBars = k
+			break
+		}
+	}
+	return out, nil
+}
+
+func donchianMid(highs, lows []float64, period int) []float64 {
+	out := make([]float64, len(highs))
+	for i := period - 1; i < len(highs); i++ {
+		hi, lo := highs[i], lows[i]
+		for j := i - period + 1; j < i; j++ {
+			hi = max(hi, highs[j])
+			lo = min(lo, lows[j])
+		}
+		out[i] = (hi + lo) / 2
+	}
+	return out
+}
+
+func cloudColor(a, b float64) string {
+	switch {
+	case a > b:
+		return "bullish"
+	case a < b:
+		return "bearish"
+	default:
+		return "flat"
+	}
+}
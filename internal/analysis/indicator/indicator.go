@@ -10,16 +10,32 @@ import (
 )
 
 type Settings struct {
-	Symbol   string
-	Interval string
-	EMA      EMASettings
-	RSI      RSISettings
+	Symbol    string
+	Interval  string
+	EMA       EMASettings
+	RSI       RSISettings
+	MACD      MACDSettings
+	ATRPeriod int
+	Ichimoku  IchimokuSettings
+	ADXPeriod int
+	// Supertrend 缺省为 ATR(10)×3。
+	SupertrendPeriod     int
+	SupertrendMultiplier float64
+	StochRSI             StochRSISettings
+	ConnorsRSI           ConnorsRSISettings
 }
 
+// EMASettings 中 Periods 为额外输出的 EMA 周期，结果写入 Values[EMAKey(period)]。
 type EMASettings struct {
-	Fast int `json:"fast,omitempty"`
-	Mid  int `json:"mid,omitempty"`
-	Slow int `json:"slow,omitempty"`
+	Fast    int   `json:"fast,omitempty"`
+	Mid     int   `json:"mid,omitempty"`
+	Slow    int   `json:"slow,omitempty"`
+	Periods []int `json:"periods,omitempty"`
+}
+
+// EMAKey 返回按周期计算的 EMA 在 Report.Values 中的键，如 ema_55。
+func EMAKey(period int) string {
+	return fmt.Sprintf("ema_%d", period)
 }
 
 type RSISettings struct {
@@ -28,6 +44,12 @@ type RSISettings struct {
 	Overbought float64 `json:"overbought,omitempty"`
 }
 
+type MACDSettings struct {
+	Fast   int `json:"fast,omitempty"`
+	Slow   int `json:"slow,omitempty"`
+	Signal int `json:"signal,omitempty"`
+}
+
 type IndicatorValue struct {
 	Latest float64   `json:"latest"`
 	Series []float64 `json:"series,omitempty"`
@@ -36,11 +58,14 @@ type IndicatorValue struct {
 }
 
 type Report struct {
-	Symbol   string                    `json:"symbol"`
-	Interval string                    `json:"interval"`
-	Count    int                       `json:"count"`
-	Values   map[string]IndicatorValue `json:"values"`
-	Warnings []string                  `json:"warnings,omitempty"`
+	Symbol     string                    `json:"symbol"`
+	Interval   string                    `json:"interval"`
+	Count      int                       `json:"count"`
+	Values     map[string]IndicatorValue `json:"values"`
+	Warnings   []string                  `json:"warnings,omitempty"`
+	Ichimoku   *Ichimoku                 `json:"ichimoku,omitempty"`
+	ADX        *ADX                      `json:"adx,omitempty"`
+	Supertrend *Supertrend               `json:"supertrend,omitempty"`
 }
 
 func ComputeAll(candles []market.Candle, cfg Settings) (Report, error) {
@@ -95,6 +120,25 @@ func ComputeAll(candles []market.Candle, cfg Settings) (Report, error) {
 		State:  relativeState(lastClose, lastValid(emaSlow)),
 		Note:   fmt.Sprintf("EMA%d vs price", cfg.EMA.Slow),
 	}
+	for _, period := range cfg.EMA.Periods {
+		if period < 2 {
+			continue
+		}
+		if len(closes) < period {
+			rep.Warnings = append(rep.Warnings, fmt.Sprintf("%s: 需要至少 %d 根 K 线", EMAKey(period), period))
+			continue
+		}
+		series := trimEMALeadingZeros(sanitizeSeries(talib.Ema(closes, period)))
+		if len(series) == 0 {
+			continue
+		}
+		rep.Values[EMAKey(period)] = IndicatorValue{
+			Latest: lastValid(series),
+			Series: series,
+			State:  relativeState(lastClose, lastValid(series)),
+			Note:   fmt.Sprintf("EMA%d vs price", period),
+		}
+	}
 
 	if cfg.RSI.Period <= 0 {
 		cfg.RSI.Period = 14
@@ -121,7 +165,16 @@ func ComputeAll(candles []market.Candle, cfg Settings) (Report, error) {
 		Note:   fmt.Sprintf("period=%d thresholds=%.1f/%.1f", cfg.RSI.Period, cfg.RSI.Oversold, cfg.RSI.Overbought),
 	}
 
-	macd, signal, hist := talib.Macd(closes, 12, 26, 9)
+	if cfg.MACD.Fast <= 0 {
+		cfg.MACD.Fast = 12
+	}
+	if cfg.MACD.Slow <= 0 {
+		cfg.MACD.Slow = 26
+	}
+	if cfg.MACD.Signal <= 0 {
+		cfg.MACD.Signal = 9
+	}
+	macd, signal, hist := talib.Macd(closes, cfg.MACD.Fast, cfg.MACD.Slow, cfg.MACD.Signal)
 	macdSeries := sanitizeSeries(macd)
 	signalSeries := sanitizeSeries(signal)
 	histSeries := sanitizeSeries(hist)
@@ -153,6 +206,9 @@ func ComputeAll(candles []market.Candle, cfg Settings) (Report, error) {
 		Note:   fmt.Sprintf("d=%.2f", lastValid(dSeries)),
 	}
 
+	computeStochRSI(&rep, closes, cfg.StochRSI)
+	computeConnorsRSI(&rep, closes, cfg.ConnorsRSI)
+
 	will := sanitizeSeries(talib.WillR(highs, lows, closes, 14))
 	rep.Values["williams_r"] = IndicatorValue{
 		Latest: lastValid(will),
@@ -161,12 +217,15 @@ func ComputeAll(candles []market.Candle, cfg Settings) (Report, error) {
 		Note:   "period=14",
 	}
 
-	atrSeries := sanitizeSeries(talib.Atr(highs, lows, closes, 14))
+	if cfg.ATRPeriod <= 0 {
+		cfg.ATRPeriod = 14
+	}
+	atrSeries := sanitizeSeries(talib.Atr(highs, lows, closes, cfg.ATRPeriod))
 	rep.Values["atr"] = IndicatorValue{
 		Latest: lastValid(atrSeries),
 		Series: atrSeries,
 		State:  "volatility",
-		Note:   "period=14",
+		Note:   fmt.Sprintf("period=%d", cfg.ATRPeriod),
 	}
 
 	obv := sanitizeSeries(talib.Obv(closes, volumes))
@@ -177,6 +236,24 @@ func ComputeAll(candles []market.Candle, cfg Settings) (Report, error) {
 		Note:   "volume thrust",
 	}
 
+	if ichi, err := ComputeIchimoku(highs, lows, closes, cfg.Ichimoku); err == nil {
+		rep.Ichimoku = ichi
+	} else {
+		rep.Warnings = append(rep.Warnings, err.Error())
+	}
+
+	if adx, err := ComputeADX(highs, lows, closes, cfg.ADXPeriod); err == nil {
+		rep.ADX = adx
+	} else {
+		rep.Warnings = append(rep.Warnings, err.Error())
+	}
+
+	if st, err := ComputeSupertrend(highs, lows, closes, cfg.SupertrendPeriod, cfg.SupertrendMultiplier); err == nil {
+		rep.Supertrend = st
+	} else {
+		rep.Warnings = append(rep.Warnings, err.Error())
+	}
+
 	return rep, nil
 }
 
@@ -0,0 +1,267 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markcheno/go-talib"
+)
+
+// StochRSISettings 缺省为 RSI14 上取 14 根随机指标、%K 平滑 3、%D 平滑 3，阈值 80/20。
+type StochRSISettings struct {
+	RSIPeriod   int     `json:"rsi_period,omitempty"`
+	StochPeriod int     `json:"stoch_period,omitempty"`
+	K           int     `json:"k,omitempty"`
+	D           int     `json:"d,omitempty"`
+	Oversold    float64 `json:"oversold,omitempty"`
+	Overbought  float64 `json:"overbought,omitempty"`
+}
+
+// ConnorsRSISettings 缺省为 RSI3、连涨跌 RSI2、ROC 百分位 100，阈值 90/10。
+type ConnorsRSISettings struct {
+	RSIPeriod    int     `json:"rsi_period,omitempty"`
+	StreakPeriod int     `json:"streak_period,omitempty"`
+	RankPeriod   int     `json:"rank_period,omitempty"`
+	Oversold     float64 `json:"oversold,omitempty"`
+	Overbought   float64 `json:"overbought,omitempty"`
+}
+
+func (s StochRSISettings) withDefaults() StochRSISettings {
+	if s.RSIPeriod <= 0 {
+		s.RSIPeriod = 14
+	}
+	if s.StochPeriod <= 0 {
+		s.StochPeriod = 14
+	}
+	if s.K <= 0 {
+		s.K = 3
+	}
+	if s.D <= 0 {
+		s.D = 3
+	}
+	if s.Overbought == 0 {
+		s.Overbought = 80
+	}
+	if s.Oversold == 0 {
+		s.Oversold = 20
+	}
+	return s
+}
+
+func (s ConnorsRSISettings) withDefaults() ConnorsRSISettings {
+	if s.RSIPeriod <= 0 {
+		s.RSIPeriod = 3
+	}
+	if s.StreakPeriod <= 0 {
+		s.StreakPeriod = 2
+	}
+	if s.RankPeriod <= 0 {
+		s.RankPeriod = 100
+	}
+	if s.Overbought == 0 {
+		s.Overbought = 90
+	}
+	if s.Oversold == 0 {
+		s.Oversold = 10
+	}
+	return s
+}
+
+// Override 用 o 中的非零字段覆盖 s。
+func (s StochRSISettings) Override(o StochRSISettings) StochRSISettings {
+	s.RSIPeriod = overrideInt(s.RSIPeriod, o.RSIPeriod)
+	s.StochPeriod = overrideInt(s.StochPeriod, o.StochPeriod)
+	s.K = overrideInt(s.K, o.K)
+	s.D = overrideInt(s.D, o.D)
+	s.Oversold = overrideFloat(s.Oversold, o.Oversold)
+	s.Overbought = overrideFloat(s.Overbought, o.Overbought)
+	return s
+}
+
+// Override 用 o 中的非零字段覆盖 s。
+func (s ConnorsRSISettings) Override(o ConnorsRSISettings) ConnorsRSISettings {
+	s.RSIPeriod = overrideInt(s.RSIPeriod, o.RSIPeriod)
+	s.StreakPeriod = overrideInt(s.StreakPeriod, o.StreakPeriod)
+	s.RankPeriod = overrideInt(s.RankPeriod, o.RankPeriod)
+	s.Oversold = overrideFloat(s.Oversold, o.Oversold)
+	s.Overbought = overrideFloat(s.Overbought, o.Overbought)
+	return s
+}
+
+func overrideInt(base, v int) int {
+	if v > 0 {
+		return v
+	}
+	return base
+}
+
+func overrideFloat(base, v float64) float64 {
+	if v > 0 {
+		return v
+	}
+	return base
+}
+
+// StochRSISeries 返回与 closes 等长的 %K/%D 序列，预热段为 NaN。
+func StochRSISeries(closes []float64, cfg StochRSISettings) ([]float64, []float64) {
+	cfg = cfg.withDefaults()
+	n := len(closes)
+	k := nanSeries(n)
+	d := nanSeries(n)
+	if n <= cfg.RSIPeriod {
+		return k, d
+	}
+	rsi := validTail(talib.Rsi(closes, cfg.RSIPeriod), cfg.RSIPeriod)
+	raw := nanSeries(n)
+	for i := cfg.RSIPeriod + cfg.StochPeriod - 1; i < n; i++ {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for j := i - cfg.StochPeriod + 1; j <= i; j++ {
+			lo = math.Min(lo, rsi[j])
+			hi = math.Max(hi, rsi[j])
+		}
+		switch {
+		case math.IsNaN(lo) || math.IsNaN(hi):
+		case hi-lo <= 1e-12:
+			raw[i] = 50
+		default:
+			raw[i] = (rsi[i] - lo) / (hi - lo) * 100
+		}
+	}
+	k = smaSkipNaN(raw, cfg.K)
+	d = smaSkipNaN(k, cfg.D)
+	return k, d
+}
+
+// ConnorsRSISeries 返回与 closes 等长的 Connors RSI：(RSI(close) + RSI(连涨跌天数) + ROC1 百分位) / 3，预热段为 NaN。
+func ConnorsRSISeries(closes []float64, cfg ConnorsRSISettings) []float64 {
+	cfg = cfg.withDefaults()
+	n := len(closes)
+	out := nanSeries(n)
+	if n <= cfg.RankPeriod+1 || n <= cfg.RSIPeriod || n <= cfg.StreakPeriod {
+		return out
+	}
+	priceRSI := validTail(talib.Rsi(closes, cfg.RSIPeriod), cfg.RSIPeriod)
+	streak := make([]float64, n)
+	for i := 1; i < n; i++ {
+		switch {
+		case closes[i] > closes[i-1]:
+			streak[i] = math.Max(streak[i-1], 0) + 1
+		case closes[i] < closes[i-1]:
+			streak[i] = math.Min(streak[i-1], 0) - 1
+		}
+	}
+	streakRSI := validTail(talib.Rsi(streak, cfg.StreakPeriod), cfg.StreakPeriod)
+	roc := nanSeries(n)
+	for i := 1; i < n; i++ {
+		if closes[i-1] != 0 {
+			roc[i] = (closes[i] - closes[i-1]) / closes[i-1]
+		}
+	}
+	for i := cfg.RankPeriod + 1; i < n; i++ {
+		if math.IsNaN(priceRSI[i]) || math.IsNaN(streakRSI[i]) || math.IsNaN(roc[i]) {
+			continue
+		}
+		below := 0
+		for j := i - cfg.RankPeriod; j < i; j++ {
+			if roc[j] < roc[i] {
+				below++
+			}
+		}
+		rank := float64(below) / float64(cfg.RankPeriod) * 100
+		out[i] = (priceRSI[i] + streakRSI[i] + rank) / 3
+	}
+	return out
+}
+
+func computeStochRSI(rep *Report, closes []float64, cfg StochRSISettings) {
+	cfg = cfg.withDefaults()
+	k, d := StochRSISeries(closes, cfg)
+	kSeries := sanitizeSeries(k)
+	dSeries := sanitizeSeries(d)
+	if len(kSeries) == 0 {
+		rep.Warnings = append(rep.Warnings, fmt.Sprintf("stoch_rsi: 需要至少 %d 根 K 线", cfg.RSIPeriod+cfg.StochPeriod+cfg.K))
+		return
+	}
+	rep.Values["stoch_rsi"] = IndicatorValue{
+		Latest: lastValid(kSeries),
+		Series: kSeries,
+		State:  thresholdState(lastValid(kSeries), cfg.Oversold, cfg.Overbought),
+		Note: fmt.Sprintf("rsi=%d stoch=%d k=%d d=%d thresholds=%.1f/%.1f",
+			cfg.RSIPeriod, cfg.StochPeriod, cfg.K, cfg.D, cfg.Oversold, cfg.Overbought),
+	}
+	if len(dSeries) > 0 {
+		rep.Values["stoch_rsi_d"] = IndicatorValue{
+			Latest: lastValid(dSeries),
+			Series: dSeries,
+			State:  thresholdState(lastValid(dSeries), cfg.Oversold, cfg.Overbought),
+			Note:   fmt.Sprintf("%%D sma=%d", cfg.D),
+		}
+	}
+}
+
+func computeConnorsRSI(rep *Report, closes []float64, cfg ConnorsRSISettings) {
+	cfg = cfg.withDefaults()
+	series := sanitizeSeries(ConnorsRSISeries(closes, cfg))
+	if len(series) == 0 {
+		rep.Warnings = append(rep.Warnings, fmt.Sprintf("connors_rsi: 需要至少 %d 根 K 线", cfg.RankPeriod+2))
+		return
+	}
+	rep.Values["connors_rsi"] = IndicatorValue{
+		Latest: lastValid(series),
+		Series: series,
+		State:  thresholdState(lastValid(series), cfg.Oversold, cfg.Overbought),
+		Note: fmt.Sprintf("rsi=%d streak=%d rank=%d thresholds=%.1f/%.1f",
+			cfg.RSIPeriod, cfg.StreakPeriod, cfg.RankPeriod, cfg.Oversold, cfg.Overbought),
+	}
+}
+
+func thresholdState(v, oversold, overbought float64) string {
+	switch {
+	case v >= overbought:
+		return "overbought"
+	case v <= oversold:
+		return "oversold"
+	default:
+		return "neutral"
+	}
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+// validTail 把 talib 预热段（前 lookback 个填 0 的值）改为 NaN。
+func validTail(series []float64, lookback int) []float64 {
+	for i := 0; i < lookback && i < len(series); i++ {
+		series[i] = math.NaN()
+	}
+	return series
+}
+
+// smaSkipNaN 为简单移动平均，窗口内含 NaN 时输出 NaN。
+func smaSkipNaN(src []float64, period int) []float64 {
+	out := nanSeries(len(src))
+	if period <= 1 {
+		copy(out, src)
+		return out
+	}
+	for i := period - 1; i < len(src); i++ {
+		sum := 0.0
+		ok := true
+		for j := i - period + 1; j <= i; j++ {
+			if math.IsNaN(src[j]) {
+				ok = false
+				break
+			}
+			sum += src[j]
+		}
+		if ok {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
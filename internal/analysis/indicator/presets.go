@@ -0,0 +1,99 @@
+package indicator
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultPresetName 与 ComputeAll 的内置默认参数一致。
+const DefaultPresetName = "swing"
+
+// Preset 为一组命名的指标参数，供中间件 params.preset 与 profile snapshot.preset 引用，
+// 避免中间件配置与快照构建各自维护一份参数。
+type Preset struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	EMA         EMASettings        `json:"ema"`
+	RSI         RSISettings        `json:"rsi"`
+	MACD        MACDSettings       `json:"macd"`
+	ATRPeriod   int                `json:"atr_period"`
+	StochRSI    StochRSISettings   `json:"stoch_rsi"`
+	ConnorsRSI  ConnorsRSISettings `json:"connors_rsi"`
+}
+
+var presets = map[string]Preset{
+	"scalping": {
+		Name:        "scalping",
+		Description: "短周期剥头皮：更快的均线与振荡器，阈值更极端",
+		EMA:         EMASettings{Fast: 9, Mid: 21, Slow: 50},
+		RSI:         RSISettings{Period: 7, Oversold: 20, Overbought: 80},
+		MACD:        MACDSettings{Fast: 8, Slow: 21, Signal: 5},
+		ATRPeriod:   7,
+		StochRSI:    StochRSISettings{RSIPeriod: 7, StochPeriod: 7, K: 3, D: 3, Oversold: 15, Overbought: 85},
+		ConnorsRSI:  ConnorsRSISettings{RSIPeriod: 3, StreakPeriod: 2, RankPeriod: 50, Oversold: 5, Overbought: 95},
+	},
+	"swing": {
+		Name:        "swing",
+		Description: "波段（默认）：EMA21/50/200、RSI14、MACD12/26/9、ATR14、StochRSI14/14/3/3、ConnorsRSI3/2/100",
+		EMA:         EMASettings{Fast: 21, Mid: 50, Slow: 200},
+		RSI:         RSISettings{Period: 14, Oversold: 30, Overbought: 70},
+		MACD:        MACDSettings{Fast: 12, Slow: 26, Signal: 9},
+		ATRPeriod:   14,
+		StochRSI:    StochRSISettings{RSIPeriod: 14, StochPeriod: 14, K: 3, D: 3, Oversold: 20, Overbought: 80},
+		ConnorsRSI:  ConnorsRSISettings{RSIPeriod: 3, StreakPeriod: 2, RankPeriod: 100, Oversold: 10, Overbought: 90},
+	},
+	"position": {
+		Name:        "position",
+		Description: "中长线持仓：更慢的均线与振荡器，阈值更温和",
+		EMA:         EMASettings{Fast: 50, Mid: 100, Slow: 200},
+		RSI:         RSISettings{Period: 21, Oversold: 35, Overbought: 65},
+		MACD:        MACDSettings{Fast: 19, Slow: 39, Signal: 9},
+		ATRPeriod:   21,
+		StochRSI:    StochRSISettings{RSIPeriod: 21, StochPeriod: 21, K: 5, D: 5, Oversold: 25, Overbought: 75},
+		ConnorsRSI:  ConnorsRSISettings{RSIPeriod: 3, StreakPeriod: 2, RankPeriod: 100, Oversold: 15, Overbought: 85},
+	},
+}
+
+// LookupPreset 按名称（大小写不敏感）查找预设。
+func LookupPreset(name string) (Preset, bool) {
+	p, ok := presets[strings.ToLower(strings.TrimSpace(name))]
+	return p, ok
+}
+
+// Presets 返回全部预设，按名称排序。
+func Presets() []Preset {
+	out := make([]Preset, 0, len(presets))
+	for _, p := range presets {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Settings 把预设展开为 ComputeAll 参数。
+func (p Preset) Settings(symbol, interval string) Settings {
+	return Settings{
+		Symbol:     symbol,
+		Interval:   interval,
+		EMA:        p.EMA,
+		RSI:        p.RSI,
+		MACD:       p.MACD,
+		ATRPeriod:  p.ATRPeriod,
+		StochRSI:   p.StochRSI,
+		ConnorsRSI: p.ConnorsRSI,
+	}
+}
+
+// MiddlewareParams 返回中间件对应的参数展开；未知中间件返回 nil。
+func (p Preset) MiddlewareParams(middleware string) map[string]any {
+	switch strings.ToLower(strings.TrimSpace(middleware)) {
+	case "ema_trend":
+		return map[string]any{"fast": p.EMA.Fast, "mid": p.EMA.Mid, "slow": p.EMA.Slow}
+	case "rsi_extreme":
+		return map[string]any{"period": p.RSI.Period, "oversold": p.RSI.Oversold, "overbought": p.RSI.Overbought}
+	case "macd_trend":
+		return map[string]any{"fast": p.MACD.Fast, "slow": p.MACD.Slow, "signal": p.MACD.Signal}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,302 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/markcheno/go-talib"
+
+	"brale/internal/market"
+)
+
+const (
+	RSIDivergenceRegular = "regular"
+	RSIDivergenceHidden  = "hidden"
+
+	RSIDivergenceGradeA = "A"
+	RSIDivergenceGradeB = "B"
+	RSIDivergenceGradeC = "C"
+
+	// 两个枢轴间隔落在该区间内记 1 分：过近多为噪声，过远则前一个极值已失去参考意义。
+	rsiDivergenceIdealGapMin = 8
+	rsiDivergenceIdealGapMax = 40
+	// RSI 极值距超买/超卖线在该距离内记 1 分，进入超买/超卖区记 2 分。
+	rsiDivergenceNearZone = 10.0
+)
+
+// RSIDivergenceSettings 缺省为 RSI14、枢轴左 3 右 2 根确认、枢轴间隔 5-60 根、最近 10 根内确认、阈值 70/30，
+// Hidden 为 true 时同时识别隐藏背离（趋势延续信号）。
+type RSIDivergenceSettings struct {
+	Period     int     `json:"period,omitempty"`
+	PivotLeft  int     `json:"pivot_left,omitempty"`
+	PivotRight int     `json:"pivot_right,omitempty"`
+	MinGap     int     `json:"min_gap,omitempty"`
+	MaxGap     int     `json:"max_gap,omitempty"`
+	MaxAge     int     `json:"max_age,omitempty"`
+	Overbought float64 `json:"overbought,omitempty"`
+	Oversold   float64 `json:"oversold,omitempty"`
+	Hidden     bool    `json:"hidden,omitempty"`
+}
+
+// WithDefaults 补齐未设置的参数。
+func (s RSIDivergenceSettings) WithDefaults() RSIDivergenceSettings {
+	if s.Period <= 0 {
+		s.Period = 14
+	}
+	if s.PivotLeft <= 0 {
+		s.PivotLeft = 3
+	}
+	if s.PivotRight <= 0 {
+		s.PivotRight = 2
+	}
+	if s.MinGap <= 0 {
+		s.MinGap = 5
+	}
+	if s.MaxGap <= s.MinGap {
+		s.MaxGap = max(60, s.MinGap+1)
+	}
+	if s.MaxAge <= 0 {
+		s.MaxAge = 10
+	}
+	if s.Overbought <= 0 {
+		s.Overbought = 70
+	}
+	if s.Oversold <= 0 {
+		s.Oversold = 30
+	}
+	return s
+}
+
+// RSIDivergence 为一组价格枢轴与 RSI 的背离：From 为较早的枢轴，To 为较晚的枢轴；
+// AgeBars 为 To 距最新 K 线的根数。Score 0-3 = RSI 极值分（0-2）+ 间隔分（0-1），对应等级 A(3)/B(2)/C(0-1)。
+type RSIDivergence struct {
+	Kind      string  `json:"kind"`
+	Bias      string  `json:"bias"`
+	Grade     string  `json:"grade"`
+	Score     int     `json:"score"`
+	PriceFrom float64 `json:"price_from"`
+	PriceTo   float64 `json:"price_to"`
+	RSIFrom   float64 `json:"rsi_from"`
+	RSITo     float64 `json:"rsi_to"`
+	BarsApart int     `json:"bars_apart"`
+	AgeBars   int     `json:"age_bars"`
+	FromTime  int64   `json:"from_time"`
+	ToTime    int64   `json:"to_time"`
+}
+
+// DetectRSIDivergences 返回最近 MaxAge 根内确认的全部 RSI 背离（多空、常规/隐藏可同时存在），
+// 同一个较晚枢轴与同一类型只保留得分最高的一组；结果按 AgeBars 升序、Score 降序排列。
+func DetectRSIDivergences(candles []market.Candle, cfg RSIDivergenceSettings) []RSIDivergence {
+	cfg = cfg.WithDefaults()
+	n := len(candles)
+	if n < cfg.Period+cfg.PivotLeft+cfg.PivotRight+cfg.MinGap+1 {
+		return nil
+	}
+	closes := make([]float64, n)
+	highs := make([]float64, n)
+	lows := make([]float64, n)
+	for i, c := range candles {
+		closes[i], highs[i], lows[i] = c.Close, c.High, c.Low
+	}
+	rsi := talib.Rsi(closes, cfg.Period)
+	var out []RSIDivergence
+	out = append(out, scanRSIDivergences(candles, lows, rsi, cfg, "bullish")...)
+	out = append(out, scanRSIDivergences(candles, highs, rsi, cfg, "bearish")...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].AgeBars != out[j].AgeBars {
+			return out[i].AgeBars < out[j].AgeBars
+		}
+		return out[i].Score > out[j].Score
+	})
+	return out
+}
+
+// StrongestRSIDivergence 返回得分最高的背离，同分取更新的一组。
+func StrongestRSIDivergence(divs []RSIDivergence) (RSIDivergence, bool) {
+	if len(divs) == 0 {
+		return RSIDivergence{}, false
+	}
+	best := divs[0]
+	for _, d := range divs[1:] {
+		if d.Score > best.Score || (d.Score == best.Score && d.AgeBars < best.AgeBars) {
+			best = d
+		}
+	}
+	return best, true
+}
+
+// RSIDivergenceBias 按得分汇总背离的净方向：bullish/bearish，多空得分相同为 mixed，无背离为 none。
+func RSIDivergenceBias(divs []RSIDivergence) string {
+	var bullish, bearish int
+	for _, d := range divs {
+		if d.Bias == "bullish" {
+			bullish += d.Score
+		} else {
+			bearish += d.Score
+		}
+	}
+	switch {
+	case bullish > bearish:
+		return "bullish"
+	case bearish > bullish:
+		return "bearish"
+	case len(divs) > 0:
+		return "mixed"
+	default:
+		return "none"
+	}
+}
+
+// RSIDivergenceGrade 把 0-3 分映射为 A/B/C。
+func RSIDivergenceGrade(score int) string {
+	switch {
+	case score >= 3:
+		return RSIDivergenceGradeA
+	case score == 2:
+		return RSIDivergenceGradeB
+	default:
+		return RSIDivergenceGradeC
+	}
+}
+
+// scanRSIDivergences 在 prices（bullish 用低点、bearish 用高点）的枢轴上寻找背离。
+// 两个枢轴之间的价格不得越过两者中更极端的一个，否则中间已有更有效的枢轴。
+func scanRSIDivergences(candles []market.Candle, prices, rsi []float64, cfg RSIDivergenceSettings, bias string) []RSIDivergence {
+	bullish := bias == "bullish"
+	pivots := priceRSIPivots(prices, rsi, cfg, bullish)
+	n := len(prices)
+	var out []RSIDivergence
+	for pj := len(pivots) - 1; pj > 0; pj-- {
+		j := pivots[pj]
+		age := n - 1 - j
+		if age > cfg.MaxAge {
+			break
+		}
+		best := map[string]RSIDivergence{}
+		for pi := pj - 1; pi >= 0; pi-- {
+			i := pivots[pi]
+			gap := j - i
+			if gap < cfg.MinGap {
+				continue
+			}
+			if gap > cfg.MaxGap {
+				break
+			}
+			kind := divergenceKind(prices[i], prices[j], rsi[i], rsi[j], bullish)
+			if kind == "" || (kind == RSIDivergenceHidden && !cfg.Hidden) || !cleanBetween(prices, i, j, bullish) {
+				continue
+			}
+			score := rsiExtremeScore(rsi[i], rsi[j], cfg, bullish)
+			if gap >= rsiDivergenceIdealGapMin && gap <= rsiDivergenceIdealGapMax {
+				score++
+			}
+			if prev, ok := best[kind]; ok && prev.Score >= score {
+				continue
+			}
+			best[kind] = RSIDivergence{
+				Kind:      kind,
+				Bias:      bias,
+				Grade:     RSIDivergenceGrade(score),
+				Score:     score,
+				PriceFrom: round4(prices[i]),
+				PriceTo:   round4(prices[j]),
+				RSIFrom:   round4(rsi[i]),
+				RSITo:     round4(rsi[j]),
+				BarsApart: gap,
+				AgeBars:   age,
+				FromTime:  candleTime(candles[i]),
+				ToTime:    candleTime(candles[j]),
+			}
+		}
+		for _, kind := range []string{RSIDivergenceRegular, RSIDivergenceHidden} {
+			if d, ok := best[kind]; ok {
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
+// priceRSIPivots 返回 RSI 有效区间内的价格枢轴：左侧 PivotLeft 根严格更差、右侧 PivotRight 根不更优。
+func priceRSIPivots(prices, rsi []float64, cfg RSIDivergenceSettings, low bool) []int {
+	better := func(a, b float64) bool {
+		if low {
+			return a < b
+		}
+		return a > b
+	}
+	var out []int
+	for i := max(cfg.Period, cfg.PivotLeft); i < len(prices)-cfg.PivotRight; i++ {
+		if rsi[i] == 0 || math.IsNaN(rsi[i]) {
+			continue
+		}
+		ok := true
+		for k := i - cfg.PivotLeft; k < i && ok; k++ {
+			ok = better(prices[i], prices[k])
+		}
+		for k := i + 1; k <= i+cfg.PivotRight && ok; k++ {
+			ok = !better(prices[k], prices[i])
+		}
+		if ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// divergenceKind 判定背离类型：常规背离为价格创新极值而 RSI 未跟随，隐藏背离相反。
+func divergenceKind(p1, p2, r1, r2 float64, bullish bool) string {
+	if !bullish {
+		p1, p2, r1, r2 = -p1, -p2, -r1, -r2
+	}
+	switch {
+	case p2 < p1 && r2 > r1:
+		return RSIDivergenceRegular
+	case p2 > p1 && r2 < r1:
+		return RSIDivergenceHidden
+	default:
+		return ""
+	}
+}
+
+func cleanBetween(prices []float64, i, j int, low bool) bool {
+	bound := math.Min(prices[i], prices[j])
+	if !low {
+		bound = math.Max(prices[i], prices[j])
+	}
+	for k := i + 1; k < j; k++ {
+		if (low && prices[k] < bound) || (!low && prices[k] > bound) {
+			return false
+		}
+	}
+	return true
+}
+
+// rsiExtremeScore 按两个枢轴中更极端的 RSI 打分：进入超卖/超买区 2 分，距阈值 10 以内 1 分。
+func rsiExtremeScore(r1, r2 float64, cfg RSIDivergenceSettings, bullish bool) int {
+	if bullish {
+		v := math.Min(r1, r2)
+		switch {
+		case v <= cfg.Oversold:
+			return 2
+		case v <= cfg.Oversold+rsiDivergenceNearZone:
+			return 1
+		}
+		return 0
+	}
+	v := math.Max(r1, r2)
+	switch {
+	case v >= cfg.Overbought:
+		return 2
+	case v >= cfg.Overbought-rsiDivergenceNearZone:
+		return 1
+	}
+	return 0
+}
+
+func candleTime(c market.Candle) int64 {
+	if c.CloseTime > 0 {
+		return c.CloseTime
+	}
+	return c.OpenTime
+}
@@ -0,0 +1,96 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markcheno/go-talib"
+)
+
+const (
+	defaultSupertrendPeriod     = 10
+	defaultSupertrendMultiplier = 3.0
+)
+
+// Supertrend 为最新一根 K 线上的超级趋势读数：Direction 为 up（价格在下轨之上）或 down，
+// Band 为当前生效的轨道（up 时为下轨、down 时为上轨），BarsSinceFlip 为距最近一次方向翻转的根数
+// （0 表示最新一根刚翻转），Flipped 等价于 BarsSinceFlip == 0 且历史中确有翻转。
+type Supertrend struct {
+	Period        int       `json:"period"`
+	Multiplier    float64   `json:"multiplier"`
+	Band          float64   `json:"band"`
+	Direction     string    `json:"direction"`
+	BarsSinceFlip int       `json:"bars_since_flip"`
+	Flipped       bool      `json:"flipped"`
+	Series        []float64 `json:"series,omitempty"`
+}
+
+// ComputeSupertrend 以 Wilder ATR 计算超级趋势：基础轨 = (high+low)/2 ± multiplier×ATR，
+// 最终轨只向趋势方向收紧，收盘价穿越反向轨道时翻转方向。历史至少 period+2 根。
+func ComputeSupertrend(highs, lows, closes []float64, period int, multiplier float64) (*Supertrend, error) {
+	if period <= 0 {
+		period = defaultSupertrendPeriod
+	}
+	if multiplier <= 0 {
+		multiplier = defaultSupertrendMultiplier
+	}
+	n := len(closes)
+	required := period + 2
+	if n < required || len(highs) != n || len(lows) != n {
+		return nil, fmt.Errorf("supertrend 需要至少 %d 根 K 线，当前 %d", required, n)
+	}
+	atr := talib.Atr(highs, lows, closes, period)
+	start := period
+	upper := (highs[start]+lows[start])/2 + multiplier*atr[start]
+	lower := (highs[start]+lows[start])/2 - multiplier*atr[start]
+	up := closes[start] >= lower
+	lastFlip := -1
+	series := make([]float64, 0, n-start)
+	band := func() float64 {
+		if up {
+			return lower
+		}
+		return upper
+	}
+	series = append(series, round4(band()))
+	for i := start + 1; i < n; i++ {
+		if math.IsNaN(atr[i]) {
+			continue
+		}
+		mid := (highs[i] + lows[i]) / 2
+		basicUpper := mid + multiplier*atr[i]
+		basicLower := mid - multiplier*atr[i]
+		if basicUpper < upper || closes[i-1] > upper {
+			upper = basicUpper
+		}
+		if basicLower > lower || closes[i-1] < lower {
+			lower = basicLower
+		}
+		switch {
+		case up && closes[i] < lower:
+			up = false
+			lastFlip = i
+		case !up && closes[i] > upper:
+			up = true
+			lastFlip = i
+		}
+		series = append(series, round4(band()))
+	}
+	st := &Supertrend{
+		Period:     period,
+		Multiplier: multiplier,
+		Band:       round4(band()),
+		Direction:  "down",
+		Series:     series,
+	}
+	if up {
+		st.Direction = "up"
+	}
+	if lastFlip >= 0 {
+		st.BarsSinceFlip = n - 1 - lastFlip
+		st.Flipped = st.BarsSinceFlip == 0
+	} else {
+		st.BarsSinceFlip = n - 1 - start
+	}
+	return st, nil
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"strings"
+
+	"brale/internal/analysis/screen"
+	"brale/internal/types"
+)
+
+// Env 为规则求值的字段表，值为 float64 或 string；字段名统一小写。
+// 主周期字段不带后缀（rsi、regime），其它周期带 @interval 后缀（rsi@4h）。
+type Env map[string]any
+
+// AddSummary 写入单周期量化结论：trend/regime/divergence/rsi/adx/price/wt/mfi/score 及关键位。
+func (e Env) AddSummary(interval string, s screen.Summary, primary bool) {
+	fields := map[string]any{
+		"trend":            strings.ToLower(s.Trend),
+		"regime":           strings.ToLower(s.Regime),
+		"divergence":       strings.ToLower(s.Divergence),
+		"divergence_score": s.DivergenceScore,
+		"rsi":              s.RSI,
+		"adx":              s.ADX,
+		"price":            s.Price,
+		"wt":               s.WT,
+		"mfi":              s.MFI,
+		"score":            s.Score,
+		"support":          s.KeyLevels.Support,
+		"resistance":       s.KeyLevels.Resistance,
+	}
+	e.put(fields, interval, primary)
+}
+
+// AddFeatures 写入中间件特征：key 为 Feature.Value，key.meta 为元数据中的数值/字符串字段；
+// 带 interval 元数据的特征同时写入带 @interval 后缀的字段（如 ema_trend.trend@4h）。
+func (e Env) AddFeatures(features []types.Feature, primaryInterval string) {
+	for _, f := range features {
+		key := strings.ToLower(strings.TrimSpace(f.Key))
+		if key == "" {
+			continue
+		}
+		fields := map[string]any{key: f.Value}
+		for mk, mv := range f.Metadata {
+			mk = strings.ToLower(strings.TrimSpace(mk))
+			switch v := mv.(type) {
+			case float64:
+				fields[key+"."+mk] = v
+			case int:
+				fields[key+"."+mk] = float64(v)
+			case string:
+				fields[key+"."+mk] = strings.ToLower(v)
+			}
+		}
+		iv, _ := f.Metadata["interval"].(string)
+		iv = strings.ToLower(strings.TrimSpace(iv))
+		e.put(fields, iv, iv == "" || iv == primaryInterval)
+	}
+}
+
+// SetPosition 写入当前持仓方向（long/short/none）与浮盈比例。
+func (e Env) SetPosition(side string, pnlPct float64) {
+	side = strings.ToLower(strings.TrimSpace(side))
+	if side == "" {
+		side = "none"
+	}
+	e["position"] = side
+	e["pnl_pct"] = pnlPct
+}
+
+func (e Env) put(fields map[string]any, interval string, primary bool) {
+	for k, v := range fields {
+		if primary {
+			e[k] = v
+		}
+		if interval != "" {
+			e[k+"@"+interval] = v
+		}
+	}
+}
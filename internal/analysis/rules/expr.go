@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	orSplit  = regexp.MustCompile(`(?i)\s+or\s+|\|\|`)
+	andSplit = regexp.MustCompile(`(?i)\s+and\s+|&&`)
+	condOps  = []string{">=", "<=", "!=", "==", ">", "<"}
+)
+
+// Condition 为单个比较 "field op value"；value 可为数值、字符串或 vars 中的具名阈值。
+type Condition struct {
+	Field   string
+	Op      string
+	Raw     string
+	Num     float64
+	Numeric bool
+}
+
+// Expr 为析取范式：任一 AND 组全部满足即为真（AND 优先级高于 OR，不支持括号）。
+type Expr struct {
+	Source string
+	Groups [][]Condition
+}
+
+// Compile 解析形如 "wt < oversold AND divergence == bullish OR rsi <= 20" 的表达式；
+// 空表达式返回零值，Match 恒为 false。
+func Compile(src string, vars map[string]float64) (Expr, error) {
+	src = strings.TrimSpace(src)
+	expr := Expr{Source: src}
+	if src == "" {
+		return expr, nil
+	}
+	for _, part := range orSplit.Split(src, -1) {
+		var group []Condition
+		for _, clause := range andSplit.Split(part, -1) {
+			cond, err := parseCondition(clause, vars)
+			if err != nil {
+				return Expr{}, err
+			}
+			group = append(group, cond)
+		}
+		expr.Groups = append(expr.Groups, group)
+	}
+	return expr, nil
+}
+
+func parseCondition(clause string, vars map[string]float64) (Condition, error) {
+	clause = strings.TrimSpace(clause)
+	for _, op := range condOps {
+		idx := strings.Index(clause, op)
+		if idx <= 0 {
+			continue
+		}
+		cond := Condition{
+			Field: strings.ToLower(strings.TrimSpace(clause[:idx])),
+			Op:    op,
+			Raw:   strings.ToLower(strings.TrimSpace(clause[idx+len(op):])),
+		}
+		if cond.Field == "" || cond.Raw == "" {
+			break
+		}
+		if v, err := strconv.ParseFloat(cond.Raw, 64); err == nil {
+			cond.Num, cond.Numeric = v, true
+		} else if v, ok := lookupVar(vars, cond.Raw); ok {
+			cond.Num, cond.Numeric = v, true
+		} else if op != "==" && op != "!=" {
+			return Condition{}, fmt.Errorf("规则条件 %q 需数值或已定义的 vars", clause)
+		}
+		return cond, nil
+	}
+	return Condition{}, fmt.Errorf("规则条件无法解析: %q", clause)
+}
+
+func lookupVar(vars map[string]float64, name string) (float64, bool) {
+	for k, v := range vars {
+		if strings.EqualFold(strings.TrimSpace(k), name) {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// Empty 表示未配置表达式。
+func (e Expr) Empty() bool { return len(e.Groups) == 0 }
+
+// Match 在 env 上求值，返回是否满足以及满足的那组条件（用于生成 reasoning）；
+// env 中缺失的字段视为不满足。
+func (e Expr) Match(env Env) (bool, []string) {
+	for _, group := range e.Groups {
+		matched := make([]string, 0, len(group))
+		ok := true
+		for _, cond := range group {
+			hit, desc := cond.eval(env)
+			if !hit {
+				ok = false
+				break
+			}
+			matched = append(matched, desc)
+		}
+		if ok {
+			return true, matched
+		}
+	}
+	return false, nil
+}
+
+func (c Condition) eval(env Env) (bool, string) {
+	val, ok := env[c.Field]
+	if !ok {
+		return false, ""
+	}
+	switch v := val.(type) {
+	case float64:
+		want := c.Num
+		if !c.Numeric {
+			return false, ""
+		}
+		return compareNum(v, c.Op, want), fmt.Sprintf("%s=%.4g %s %s", c.Field, v, c.Op, c.Raw)
+	case string:
+		eq := strings.EqualFold(strings.TrimSpace(v), c.Raw)
+		hit := (c.Op == "==" && eq) || (c.Op == "!=" && !eq)
+		return hit, fmt.Sprintf("%s=%s %s %s", c.Field, v, c.Op, c.Raw)
+	default:
+		return false, ""
+	}
+}
+
+func compareNum(actual float64, op string, want float64) bool {
+	switch op {
+	case "==":
+		return actual == want
+	case "!=":
+		return actual != want
+	case ">":
+		return actual > want
+	case ">=":
+		return actual >= want
+	case "<":
+		return actual < want
+	case "<=":
+		return actual <= want
+	default:
+		return false
+	}
+}
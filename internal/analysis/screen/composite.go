@@ -0,0 +1,120 @@
+package screen
+
+import (
+	"math"
+	"strings"
+
+	"brale/internal/market"
+)
+
+// 单周期得分各分量的权重；趋势与震荡指标再按行情状态缩放。
+const (
+	compositeTrendWeight      = 0.4
+	compositeDivergenceWeight = 0.3
+	compositeOscillatorWeight = 0.3
+)
+
+// IntervalScore 为单周期的分量拆解，各分量与 Score 均在 [-1, 1]，正值偏多。
+type IntervalScore struct {
+	Interval   string  `json:"interval"`
+	Weight     float64 `json:"weight"`
+	Trend      float64 `json:"trend"`
+	Divergence float64 `json:"divergence"`
+	Oscillator float64 `json:"oscillator"`
+	Regime     string  `json:"regime"`
+	Score      float64 `json:"score"`
+}
+
+// Composite 为跨周期加权后的确定性综合评分，Score 在 [-100, 100]。
+type Composite struct {
+	Score     float64         `json:"score"`
+	Intervals []IntervalScore `json:"intervals"`
+}
+
+// CompositeFromCandles 对每个周期按 scoring 做 SummarizeWith 后计算综合评分；candles 返回空的周期被跳过。
+func CompositeFromCandles(intervals []string, candles func(string) []market.Candle, weights map[string]float64, scoring DivergenceScoring) Composite {
+	summaries := make(map[string]Summary, len(intervals))
+	for _, iv := range intervals {
+		if series := candles(iv); len(series) > 0 {
+			summaries[iv] = SummarizeWith(series, "", scoring)
+		}
+	}
+	return ComputeComposite(intervals, summaries, weights)
+}
+
+// ComputeComposite 按 intervals 顺序合成评分；weights 缺省或非正时该周期权重记为 1。
+func ComputeComposite(intervals []string, summaries map[string]Summary, weights map[string]float64) Composite {
+	var out Composite
+	var weighted, total float64
+	for _, iv := range intervals {
+		sum, ok := summaries[iv]
+		if !ok {
+			continue
+		}
+		w := weights[strings.ToLower(iv)]
+		if w <= 0 {
+			w = 1
+		}
+		is := scoreInterval(iv, sum)
+		is.Weight = w
+		out.Intervals = append(out.Intervals, is)
+		weighted += is.Score * w
+		total += w
+	}
+	if total > 0 {
+		out.Score = round1(weighted / total * 100)
+	}
+	return out
+}
+
+func scoreInterval(iv string, sum Summary) IntervalScore {
+	is := IntervalScore{Interval: iv, Regime: sum.Regime}
+	switch sum.Trend {
+	case "up":
+		is.Trend = 1
+	case "down":
+		is.Trend = -1
+	}
+	switch sum.Divergence {
+	case "bullish":
+		is.Divergence = 1
+	case "bearish":
+		is.Divergence = -1
+	}
+	// 震荡指标取均值回归含义：超卖偏多、超买偏空。
+	var osc []float64
+	if sum.WT != 0 {
+		osc = append(osc, clampUnit(-sum.WT/60))
+	}
+	if sum.MFI != 0 {
+		osc = append(osc, clampUnit((50-sum.MFI)/30))
+	}
+	for _, v := range osc {
+		is.Oscillator += v / float64(len(osc))
+	}
+	trendFactor, oscFactor := 0.75, 0.75
+	switch sum.Regime {
+	case "trending":
+		trendFactor, oscFactor = 1, 0.5
+	case "ranging":
+		trendFactor, oscFactor = 0.5, 1
+	}
+	is.Score = clampUnit(compositeTrendWeight*is.Trend*trendFactor +
+		compositeDivergenceWeight*is.Divergence +
+		compositeOscillatorWeight*is.Oscillator*oscFactor)
+	is.Oscillator = round2(is.Oscillator)
+	is.Score = round2(is.Score)
+	return is
+}
+
+func clampUnit(v float64) float64 {
+	return math.Max(-1, math.Min(1, v))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
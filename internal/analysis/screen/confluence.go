@@ -0,0 +1,146 @@
+package screen
+
+import (
+	"strings"
+
+	"brale/internal/market"
+)
+
+// 单周期多空分的各分量权重，合计为 1。
+const (
+	confluenceEMAWeight        = 0.4
+	confluenceDivergenceWeight = 0.3
+	confluenceWTWeight         = 0.2
+	confluenceMFIWeight        = 0.1
+	// 多空分差超过该值才给出 long/short 偏向。
+	confluenceBiasMargin = 10
+)
+
+// ConfluenceFrame 为单周期的共振拆解：EMA 排列、WT/MFI 状态与背离方向，Long/Short 在 [0, 1]。
+type ConfluenceFrame struct {
+	Interval   string  `json:"interval"`
+	Weight     float64 `json:"weight"`
+	EMAAlign   string  `json:"ema_align"`
+	WTState    string  `json:"wt_state"`
+	MFIState   string  `json:"mfi_state"`
+	Divergence string  `json:"divergence"`
+	Long       float64 `json:"long"`
+	Short      float64 `json:"short"`
+}
+
+// Confluence 为跨周期的多空共振分：Long/Short 在 [0, 100]，LongAligned/ShortAligned 为 EMA 排列同向的周期数。
+type Confluence struct {
+	Long         float64           `json:"long"`
+	Short        float64           `json:"short"`
+	Bias         string            `json:"bias"`
+	LongAligned  int               `json:"long_aligned"`
+	ShortAligned int               `json:"short_aligned"`
+	Frames       []ConfluenceFrame `json:"frames"`
+}
+
+// ConfluenceFromCandles 对每个周期按 scoring 做 SummarizeWith 后计算共振分；candles 返回空的周期被跳过。
+func ConfluenceFromCandles(intervals []string, candles func(string) []market.Candle, weights map[string]float64, scoring DivergenceScoring) Confluence {
+	summaries := make(map[string]Summary, len(intervals))
+	for _, iv := range intervals {
+		if series := candles(iv); len(series) > 0 {
+			summaries[iv] = SummarizeWith(series, "", scoring)
+		}
+	}
+	return ComputeConfluence(intervals, summaries, weights)
+}
+
+// ComputeConfluence 按 intervals 顺序合成多空共振分；weights 缺省或非正时该周期权重记为 1。
+func ComputeConfluence(intervals []string, summaries map[string]Summary, weights map[string]float64) Confluence {
+	out := Confluence{Bias: "neutral"}
+	var long, short, total float64
+	for _, iv := range intervals {
+		sum, ok := summaries[iv]
+		if !ok {
+			continue
+		}
+		w := weights[strings.ToLower(iv)]
+		if w <= 0 {
+			w = 1
+		}
+		frame := confluenceFrame(iv, sum)
+		frame.Weight = w
+		switch frame.EMAAlign {
+		case "bullish":
+			out.LongAligned++
+		case "bearish":
+			out.ShortAligned++
+		}
+		out.Frames = append(out.Frames, frame)
+		long += frame.Long * w
+		short += frame.Short * w
+		total += w
+	}
+	if total == 0 {
+		return out
+	}
+	out.Long = round1(long / total * 100)
+	out.Short = round1(short / total * 100)
+	switch {
+	case out.Long-out.Short >= confluenceBiasMargin:
+		out.Bias = "long"
+	case out.Short-out.Long >= confluenceBiasMargin:
+		out.Bias = "short"
+	}
+	return out
+}
+
+func confluenceFrame(iv string, sum Summary) ConfluenceFrame {
+	frame := ConfluenceFrame{
+		Interval:   iv,
+		EMAAlign:   "mixed",
+		WTState:    oscillatorState(sum.WT, -60, 60),
+		MFIState:   oscillatorState(sum.MFI, 20, 80),
+		Divergence: sum.Divergence,
+	}
+	if frame.Divergence == "" {
+		frame.Divergence = "none"
+	}
+	switch sum.Trend {
+	case "up":
+		frame.EMAAlign = "bullish"
+		frame.Long += confluenceEMAWeight
+	case "down":
+		frame.EMAAlign = "bearish"
+		frame.Short += confluenceEMAWeight
+	}
+	switch frame.Divergence {
+	case "bullish":
+		frame.Long += confluenceDivergenceWeight
+	case "bearish":
+		frame.Short += confluenceDivergenceWeight
+	}
+	// WT/MFI 取均值回归含义：超卖支持做多、超买支持做空。
+	for _, osc := range []struct {
+		state  string
+		weight float64
+	}{{frame.WTState, confluenceWTWeight}, {frame.MFIState, confluenceMFIWeight}} {
+		switch osc.state {
+		case "oversold":
+			frame.Long += osc.weight
+		case "overbought":
+			frame.Short += osc.weight
+		}
+	}
+	frame.Long = round2(frame.Long)
+	frame.Short = round2(frame.Short)
+	return frame
+}
+
+// oscillatorState 按阈值把震荡指标分为 oversold/overbought/neutral；值为 0 视为不可用。
+func oscillatorState(v, low, high float64) string {
+	switch {
+	case v == 0:
+		return "unknown"
+	case v <= low:
+		return "oversold"
+	case v >= high:
+		return "overbought"
+	default:
+		return "neutral"
+	}
+}
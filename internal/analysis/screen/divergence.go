@@ -0,0 +1,181 @@
+package screen
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/market"
+
+	talib "github.com/markcheno/go-talib"
+)
+
+// 默认只用 RSI 判定背离（权重 1），阈值 0.5：即 RSI 出现背离即成立，与早期单指标口径一致。
+const defaultDivergenceThreshold = 0.5
+
+var defaultDivergenceWeights = map[string]float64{"rsi": 1}
+
+// DivergenceIndicators 为支持参与背离打分的指标：rsi/wt/stoch_rsi/connors_rsi 为动量类，mfi/obv 为量能类。
+var DivergenceIndicators = []string{"rsi", "wt", "mfi", "obv", "stoch_rsi", "connors_rsi"}
+
+// DivergenceScoring 为背离打分参数：Weights 按指标覆盖默认权重（0 表示不参与），
+// Threshold 为同向背离指标权重占全部权重的最低比例，达到才给出 bullish/bearish。
+type DivergenceScoring struct {
+	Weights   map[string]float64
+	Threshold float64
+}
+
+// Resolved 合并默认权重并补齐阈值；未知指标与负权重被忽略。
+func (s DivergenceScoring) Resolved() DivergenceScoring {
+	out := DivergenceScoring{Weights: make(map[string]float64, len(DivergenceIndicators)), Threshold: s.Threshold}
+	for k, w := range defaultDivergenceWeights {
+		out.Weights[k] = w
+	}
+	for k, w := range s.Weights {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !isDivergenceIndicator(k) || w < 0 {
+			continue
+		}
+		out.Weights[k] = w
+	}
+	if out.Threshold <= 0 || out.Threshold > 1 {
+		out.Threshold = defaultDivergenceThreshold
+	}
+	return out
+}
+
+func isDivergenceIndicator(name string) bool {
+	for _, k := range DivergenceIndicators {
+		if k == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scoreDivergence 对每个参与指标比较最近两段窗口的价格极值与指标值，按权重汇总：
+// 返回方向与带符号得分（底背离为正），同向权重占比未达阈值时方向为 none。
+func scoreDivergence(candles []market.Candle, scoring DivergenceScoring) (string, float64) {
+	closes := closesOf(candles)
+	if len(closes) < divergenceLookback+rsiPeriod+1 {
+		return "none", 0
+	}
+	scoring = scoring.Resolved()
+	names := make([]string, 0, len(scoring.Weights))
+	for k := range scoring.Weights {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var bullish, bearish, total float64
+	for _, name := range names {
+		w := scoring.Weights[name]
+		if w <= 0 {
+			continue
+		}
+		series := divergenceSeries(name, candles, closes)
+		if len(series) != len(closes) {
+			continue
+		}
+		total += w
+		switch divergenceOf(closes, series) {
+		case "bullish":
+			bullish += w
+		case "bearish":
+			bearish += w
+		}
+	}
+	if total <= 0 {
+		return "none", 0
+	}
+	bullRatio, bearRatio := bullish/total, bearish/total
+	switch {
+	case bearRatio >= scoring.Threshold && bearRatio >= bullRatio:
+		return "bearish", -math.Round(bearRatio*100) / 100
+	case bullRatio >= scoring.Threshold:
+		return "bullish", math.Round(bullRatio*100) / 100
+	default:
+		return "none", math.Round((bullRatio-bearRatio)*100) / 100
+	}
+}
+
+func divergenceSeries(name string, candles []market.Candle, closes []float64) []float64 {
+	switch name {
+	case "rsi":
+		return talib.Rsi(closes, rsiPeriod)
+	case "wt":
+		return waveTrendSeries(candles)
+	case "mfi":
+		return mfiSeries(candles)
+	case "obv":
+		volumes := make([]float64, len(candles))
+		for i, c := range candles {
+			volumes[i] = c.Volume
+		}
+		return talib.Obv(closes, volumes)
+	case "stoch_rsi":
+		k, _ := indicator.StochRSISeries(closes, indicator.StochRSISettings{})
+		return k
+	case "connors_rsi":
+		return indicator.ConnorsRSISeries(closes, indicator.ConnorsRSISettings{})
+	default:
+		return nil
+	}
+}
+
+// divergenceOf 比较最近两段窗口的价格与指标极值，返回 bullish/bearish/none。
+func divergenceOf(closes, indicator []float64) string {
+	dir, _, _ := divergencePivots(closes, indicator)
+	return dir
+}
+
+// divergencePivots 与 divergenceOf 相同，另返回构成背离的前后两个价格极值下标。
+func divergencePivots(closes, indicator []float64) (string, int, int) {
+	half := divergenceLookback / 2
+	end := len(closes)
+	prevStart, curStart := end-divergenceLookback, end-half
+	prevHi, prevHiIdx := maxWithIndex(closes[prevStart:curStart])
+	curHi, curHiIdx := maxWithIndex(closes[curStart:end])
+	if curHi > prevHi && indicator[curStart+curHiIdx] < indicator[prevStart+prevHiIdx] {
+		return "bearish", prevStart + prevHiIdx, curStart + curHiIdx
+	}
+	prevLo, prevLoIdx := minWithIndex(closes[prevStart:curStart])
+	curLo, curLoIdx := minWithIndex(closes[curStart:end])
+	if curLo < prevLo && indicator[curStart+curLoIdx] > indicator[prevStart+prevLoIdx] {
+		return "bullish", prevStart + prevLoIdx, curStart + curLoIdx
+	}
+	return "none", 0, 0
+}
+
+// DivergenceMarker 为某指标当前成立的背离，PrevIndex/CurIndex 为 candles 中前后两个价格极值的下标，供图表标注。
+type DivergenceMarker struct {
+	Indicator string
+	Direction string
+	PrevIndex int
+	CurIndex  int
+}
+
+// DivergenceMarkers 按 scoring 中权重大于 0 的指标逐个判定背离，只返回成立的指标。
+func DivergenceMarkers(candles []market.Candle, scoring DivergenceScoring) []DivergenceMarker {
+	closes := closesOf(candles)
+	if len(closes) < divergenceLookback+rsiPeriod+1 {
+		return nil
+	}
+	scoring = scoring.Resolved()
+	var out []DivergenceMarker
+	for _, name := range DivergenceIndicators {
+		if scoring.Weights[name] <= 0 {
+			continue
+		}
+		series := divergenceSeries(name, candles, closes)
+		if len(series) != len(closes) {
+			continue
+		}
+		dir, prev, cur := divergencePivots(closes, series)
+		if dir == "none" {
+			continue
+		}
+		out = append(out, DivergenceMarker{Indicator: name, Direction: dir, PrevIndex: prev, CurIndex: cur})
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package screen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ModeAny = "any"
+	ModeAll = "all"
+)
+
+// Rule 形如 divergence != none、regime == trending、rsi <= 30、score >= 40。
+type Rule struct {
+	Field string
+	Op    string
+	Value string
+}
+
+var ruleOps = []string{">=", "<=", "!=", "==", ">", "<"}
+
+// ParseRule 解析 "field op value" 形式的规则。
+func ParseRule(expr string) (Rule, error) {
+	expr = strings.TrimSpace(expr)
+	for _, op := range ruleOps {
+		idx := strings.Index(expr, op)
+		if idx <= 0 {
+			continue
+		}
+		r := Rule{
+			Field: strings.ToLower(strings.TrimSpace(expr[:idx])),
+			Op:    op,
+			Value: strings.ToLower(strings.TrimSpace(expr[idx+len(op):])),
+		}
+		if err := r.Validate(); err != nil {
+			return Rule{}, err
+		}
+		return r, nil
+	}
+	return Rule{}, fmt.Errorf("screening 规则无法解析: %s", expr)
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s %s %s", r.Field, r.Op, r.Value)
+}
+
+// Validate 在加载配置时检查字段与运算符是否可用。
+func (r Rule) Validate() error {
+	switch r.Field {
+	case "trend", "regime", "divergence":
+		if r.Op != "==" && r.Op != "!=" {
+			return fmt.Errorf("screening 字段 %s 仅支持 == / !=", r.Field)
+		}
+	case "rsi", "adx", "price", "wt", "mfi", "score", "divergence_score":
+		switch r.Op {
+		case "==", "!=", ">", ">=", "<", "<=":
+		default:
+			return fmt.Errorf("screening 运算符无效: %s", r.Op)
+		}
+		if _, err := strconv.ParseFloat(r.Value, 64); err != nil {
+			return fmt.Errorf("screening 字段 %s 需为数值: %s", r.Field, r.Value)
+		}
+	default:
+		return fmt.Errorf("screening 字段未知: %s", r.Field)
+	}
+	return nil
+}
+
+func (r Rule) Match(s Summary) bool {
+	switch r.Field {
+	case "trend":
+		return compareString(s.Trend, r.Op, r.Value)
+	case "regime":
+		return compareString(s.Regime, r.Op, r.Value)
+	case "divergence":
+		return compareString(s.Divergence, r.Op, r.Value)
+	case "rsi":
+		return compareFloat(s.RSI, r.Op, r.Value)
+	case "adx":
+		return compareFloat(s.ADX, r.Op, r.Value)
+	case "price":
+		return compareFloat(s.Price, r.Op, r.Value)
+	case "wt":
+		return compareFloat(s.WT, r.Op, r.Value)
+	case "mfi":
+		return compareFloat(s.MFI, r.Op, r.Value)
+	case "score":
+		return compareFloat(s.Score, r.Op, r.Value)
+	case "divergence_score":
+		return compareFloat(s.DivergenceScore, r.Op, r.Value)
+	default:
+		return false
+	}
+}
+
+// Gate 决定本轮是否值得调用 LLM；mode=any 任一规则满足即放行，mode=all 需全部满足。
+type Gate struct {
+	Mode  string
+	Rules []Rule
+}
+
+// Evaluate 返回是否放行以及未满足的规则（用于统计筛除原因）。
+func (g Gate) Evaluate(s Summary) (bool, []string) {
+	if len(g.Rules) == 0 {
+		return true, nil
+	}
+	failed := make([]string, 0, len(g.Rules))
+	for _, r := range g.Rules {
+		if r.Match(s) {
+			if g.Mode != ModeAll {
+				return true, nil
+			}
+			continue
+		}
+		failed = append(failed, r.String())
+	}
+	return len(failed) == 0, failed
+}
+
+func compareString(actual, op, want string) bool {
+	eq := strings.EqualFold(strings.TrimSpace(actual), strings.TrimSpace(want))
+	if op == "!=" {
+		return !eq
+	}
+	return eq
+}
+
+func compareFloat(actual float64, op, raw string) bool {
+	want, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return false
+	}
+	switch op {
+	case "==":
+		return actual == want
+	case "!=":
+		return actual != want
+	case ">":
+		return actual > want
+	case ">=":
+		return actual >= want
+	case "<":
+		return actual < want
+	case "<=":
+		return actual <= want
+	default:
+		return false
+	}
+}
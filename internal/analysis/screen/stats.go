@@ -0,0 +1,82 @@
+package screen
+
+import (
+	"sync"
+	"time"
+)
+
+type Stats struct {
+	Evaluated int64            `json:"evaluated"`
+	Passed    int64            `json:"passed"`
+	Rejected  int64            `json:"rejected"`
+	Errors    int64            `json:"errors"`
+	Reasons   map[string]int64 `json:"reasons"`
+	BySymbol  map[string]int64 `json:"rejected_by_symbol"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// Counter 记录前置筛选的放行/筛除次数与原因，进程内累计。
+type Counter struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+func NewCounter() *Counter {
+	return &Counter{stats: Stats{Reasons: make(map[string]int64), BySymbol: make(map[string]int64)}}
+}
+
+func (c *Counter) RecordPass() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Evaluated++
+	c.stats.Passed++
+	c.stats.UpdatedAt = time.Now()
+}
+
+func (c *Counter) RecordReject(symbol string, reasons []string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Evaluated++
+	c.stats.Rejected++
+	c.stats.BySymbol[symbol]++
+	for _, r := range reasons {
+		c.stats.Reasons[r]++
+	}
+	c.stats.UpdatedAt = time.Now()
+}
+
+// RecordError 计算失败时按 fail-open 放行，仅计数。
+func (c *Counter) RecordError() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Evaluated++
+	c.stats.Errors++
+	c.stats.UpdatedAt = time.Now()
+}
+
+func (c *Counter) Snapshot() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := c.stats
+	out.Reasons = make(map[string]int64, len(c.stats.Reasons))
+	for k, v := range c.stats.Reasons {
+		out.Reasons[k] = v
+	}
+	out.BySymbol = make(map[string]int64, len(c.stats.BySymbol))
+	for k, v := range c.stats.BySymbol {
+		out.BySymbol[k] = v
+	}
+	return out
+}
@@ -0,0 +1,259 @@
+package screen
+
+import (
+	"math"
+	"strings"
+
+	"brale/internal/market"
+	"brale/internal/pipeline"
+	"brale/internal/strategy"
+
+	talib "github.com/markcheno/go-talib"
+)
+
+const (
+	divergenceLookback = 30
+	keyLevelLookback   = 50
+	adxPeriod          = 14
+	rsiPeriod          = 14
+	mfiPeriod          = 14
+	wtChannelLen       = 10
+	wtAverageLen       = 21
+)
+
+type KeyLevels struct {
+	Support    float64 `json:"support"`
+	Resistance float64 `json:"resistance"`
+	PivotHigh  float64 `json:"pivot_high,omitempty"`
+	PivotLow   float64 `json:"pivot_low,omitempty"`
+}
+
+// Summary 是单周期K线的紧凑量化结论，供批量筛选与 LLM 前置过滤共用。
+type Summary struct {
+	Price      float64 `json:"price,omitempty"`
+	Trend      string  `json:"trend,omitempty"`
+	Regime     string  `json:"regime,omitempty"`
+	Divergence string  `json:"divergence,omitempty"`
+	// DivergenceScore 为加权背离得分，正值偏多（底背离）、负值偏空，绝对值为出现该方向背离的指标权重占比。
+	DivergenceScore float64   `json:"divergence_score,omitempty"`
+	RSI             float64   `json:"rsi,omitempty"`
+	ADX             float64   `json:"adx,omitempty"`
+	WT              float64   `json:"wt,omitempty"`
+	MFI             float64   `json:"mfi,omitempty"`
+	Score           float64   `json:"score,omitempty"`
+	KeyLevels       KeyLevels `json:"key_levels"`
+}
+
+// Summarize 计算趋势/行情状态/背离方向/关键位；trendHint 非空时直接作为趋势结论。背离按默认权重（仅 RSI）判定。
+func Summarize(candles []market.Candle, trendHint string) Summary {
+	return SummarizeWith(candles, trendHint, DivergenceScoring{})
+}
+
+// SummarizeWith 与 Summarize 相同，背离方向按 scoring 的指标权重与阈值判定。
+func SummarizeWith(candles []market.Candle, trendHint string, scoring DivergenceScoring) Summary {
+	if len(candles) == 0 {
+		return Summary{Trend: "unknown", Regime: "unknown", Divergence: "none"}
+	}
+	closes := closesOf(candles)
+	sum := Summary{Price: closes[len(closes)-1]}
+	sum.Trend = strings.ToLower(strings.TrimSpace(trendHint))
+	if sum.Trend == "" {
+		sum.Trend = emaTrend(closes)
+	}
+	sum.ADX = lastADX(candles)
+	sum.Regime = regimeOf(sum.ADX)
+	sum.RSI = strategy.RSI(closes, rsiPeriod)
+	sum.Divergence, sum.DivergenceScore = scoreDivergence(candles, scoring)
+	sum.WT = lastWaveTrend(candles)
+	sum.MFI = lastMFI(candles)
+	sum.KeyLevels = keyLevelsOf(candles)
+	return sum
+}
+
+// TrendFromFeatures 复用 ema_trend 中间件在指定周期上的结论。
+func TrendFromFeatures(features []pipeline.Feature, interval string) string {
+	for _, f := range features {
+		if f.Key != "ema_trend" {
+			continue
+		}
+		if iv, _ := f.Metadata["interval"].(string); !strings.EqualFold(iv, interval) {
+			continue
+		}
+		if trend, ok := f.Metadata["trend"].(string); ok && trend != "" {
+			return strings.ToLower(trend)
+		}
+	}
+	return ""
+}
+
+func emaTrend(closes []float64) string {
+	fast := strategy.EMA(closes, 21)
+	mid := strategy.EMA(closes, 50)
+	slow := strategy.EMA(closes, 200)
+	if fast == 0 || mid == 0 || slow == 0 {
+		return "unknown"
+	}
+	return strings.ToLower(strategy.ClassifyTrend(fast, mid, slow))
+}
+
+func lastADX(candles []market.Candle) float64 {
+	if len(candles) < adxPeriod*2+1 {
+		return 0
+	}
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i] = c.High
+		lows[i] = c.Low
+	}
+	adx := talib.Adx(highs, lows, closesOf(candles), adxPeriod)
+	last := adx[len(adx)-1]
+	if math.IsNaN(last) || math.IsInf(last, 0) {
+		return 0
+	}
+	return last
+}
+
+// lastWaveTrend 返回 WaveTrend(10,21) 的 wt1，常用 ±60 作为超买/超卖阈值。
+func lastWaveTrend(candles []market.Candle) float64 {
+	if len(candles) < wtChannelLen+wtAverageLen {
+		return 0
+	}
+	wt := waveTrendSeries(candles)
+	return wt[len(wt)-1]
+}
+
+// WaveTrendSeries 返回与 candles 等长的 wt1 序列，供图表副图使用。
+func WaveTrendSeries(candles []market.Candle) []float64 {
+	if len(candles) == 0 {
+		return nil
+	}
+	return waveTrendSeries(candles)
+}
+
+func waveTrendSeries(candles []market.Candle) []float64 {
+	ap := make([]float64, len(candles))
+	for i, c := range candles {
+		ap[i] = (c.High + c.Low + c.Close) / 3
+	}
+	esa := emaSeries(ap, wtChannelLen)
+	dev := make([]float64, len(ap))
+	for i := range ap {
+		dev[i] = math.Abs(ap[i] - esa[i])
+	}
+	d := emaSeries(dev, wtChannelLen)
+	ci := make([]float64, len(ap))
+	for i := range ap {
+		if d[i] != 0 {
+			ci[i] = (ap[i] - esa[i]) / (0.015 * d[i])
+		}
+	}
+	return emaSeries(ci, wtAverageLen)
+}
+
+func lastMFI(candles []market.Candle) float64 {
+	if len(candles) <= mfiPeriod {
+		return 0
+	}
+	mfi := mfiSeries(candles)
+	last := mfi[len(mfi)-1]
+	if math.IsNaN(last) || math.IsInf(last, 0) {
+		return 0
+	}
+	return last
+}
+
+// MFISeries 返回 MFI(14) 序列，前 14 根无有效值（为 0）。
+func MFISeries(candles []market.Candle) []float64 {
+	if len(candles) <= mfiPeriod {
+		return nil
+	}
+	return mfiSeries(candles)
+}
+
+func mfiSeries(candles []market.Candle) []float64 {
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	volumes := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i] = c.High
+		lows[i] = c.Low
+		volumes[i] = c.Volume
+	}
+	return talib.Mfi(highs, lows, closesOf(candles), volumes, mfiPeriod)
+}
+
+// emaSeries 以首个值为种子逐根计算 EMA，不像 talib 那样在前 period-1 根填 0。
+func emaSeries(values []float64, period int) []float64 {
+	out := make([]float64, len(values))
+	if len(values) == 0 {
+		return out
+	}
+	k := 2 / float64(period+1)
+	out[0] = values[0]
+	for i := 1; i < len(values); i++ {
+		out[i] = values[i]*k + out[i-1]*(1-k)
+	}
+	return out
+}
+
+// regimeOf 以 ADX 粗分趋势/震荡。
+func regimeOf(adx float64) string {
+	switch {
+	case adx <= 0:
+		return "unknown"
+	case adx >= 25:
+		return "trending"
+	case adx < 20:
+		return "ranging"
+	default:
+		return "transition"
+	}
+}
+
+func keyLevelsOf(candles []market.Candle) KeyLevels {
+	window := candles
+	if len(window) > keyLevelLookback {
+		window = window[len(window)-keyLevelLookback:]
+	}
+	levels := KeyLevels{Support: window[0].Low, Resistance: window[0].High}
+	for _, c := range window {
+		levels.Support = math.Min(levels.Support, c.Low)
+		levels.Resistance = math.Max(levels.Resistance, c.High)
+	}
+	if len(candles) >= 2 {
+		prev := candles[len(candles)-2]
+		pivot := (prev.High + prev.Low + prev.Close) / 3
+		levels.PivotHigh = 2*pivot - prev.Low
+		levels.PivotLow = 2*pivot - prev.High
+	}
+	return levels
+}
+
+func closesOf(candles []market.Candle) []float64 {
+	out := make([]float64, len(candles))
+	for i, c := range candles {
+		out[i] = c.Close
+	}
+	return out
+}
+
+func maxWithIndex(values []float64) (float64, int) {
+	best, idx := math.Inf(-1), 0
+	for i, v := range values {
+		if v > best {
+			best, idx = v, i
+		}
+	}
+	return best, idx
+}
+
+func minWithIndex(values []float64) (float64, int) {
+	best, idx := math.Inf(1), 0
+	for i, v := range values {
+		if v < best {
+			best, idx = v, i
+		}
+	}
+	return best, idx
+}
@@ -20,6 +20,7 @@ import (
 
 	"brale/internal/analysis/indicator"
 	"brale/internal/analysis/pattern"
+	"brale/internal/analysis/screen"
 	"brale/internal/market"
 )
 
@@ -52,25 +53,79 @@ type CompositeInput struct {
 	History    map[string][]market.Candle
 	Indicators map[string]indicator.Report
 	Patterns   map[string]pattern.Result
+	// Divergence 为背离标注使用的指标权重与阈值，零值按默认（仅 RSI）。
+	Divergence screen.DivergenceScoring
+	// Options 覆盖 SetChartOptions 设置的默认尺寸与配色，零值字段沿用默认。
+	Options ChartOptions
+}
+
+// ChartOptions 控制图表尺寸与配色：Width 为图宽像素，KlineHeight 为主图高度（副图按比例缩放），Theme 为 dark/light。
+type ChartOptions struct {
+	Width       int
+	KlineHeight int
+	Theme       string
 }
 
 const (
-	colorBackground    = "#060c1b"
-	colorTextPrimary   = "#eceff4"
-	colorTextSecondary = "#9ca3af"
-	colorBull          = "#34d399"
-	colorBear          = "#f87171"
-	colorEmaFast       = "#3b82f6"
-	colorEmaMid        = "#fbbf24"
-	colorEmaSlow       = "#f472b6"
-	colorVolume        = "#a78bfa"
-	colorDIF           = "#22d3ee"
-	colorDEA           = "#fb7185"
+	ChartThemeDark  = "dark"
+	ChartThemeLight = "light"
+)
+
+type chartPalette struct {
+	echartsTheme  string
+	background    string
+	textPrimary   string
+	textSecondary string
+}
+
+var chartPalettes = map[string]chartPalette{
+	ChartThemeDark:  {echartsTheme: types.ThemeWesteros, background: "#060c1b", textPrimary: "#eceff4", textSecondary: "#9ca3af"},
+	ChartThemeLight: {echartsTheme: types.ThemeShine, background: "#ffffff", textPrimary: "#111827", textSecondary: "#4b5563"},
+}
+
+// chartLayout 为解析后的单周期布局：主图 + 成交量 + MACD + WT/MFI 四块。
+type chartLayout struct {
+	width        int
+	klineHeight  int
+	volumeHeight int
+	macdHeight   int
+	oscHeight    int
+	palette      chartPalette
+}
+
+func (l chartLayout) blockHeight() int {
+	return l.klineHeight + l.volumeHeight + l.macdHeight + l.oscHeight
+}
+
+func (l chartLayout) init(height int) opts.Initialization {
+	return opts.Initialization{
+		Theme:           l.palette.echartsTheme,
+		Width:           fmt.Sprintf("%dpx", l.width),
+		Height:          fmt.Sprintf("%dpx", height),
+		BackgroundColor: l.palette.background,
+	}
+}
+
+const (
+	colorBull    = "#34d399"
+	colorBear    = "#f87171"
+	colorEmaFast = "#3b82f6"
+	colorEmaMid  = "#fbbf24"
+	colorEmaSlow = "#f472b6"
+	colorVolume  = "#a78bfa"
+	colorDIF     = "#22d3ee"
+	colorDEA     = "#fb7185"
+	colorWT      = "#38bdf8"
+	colorMFI     = "#facc15"
 
 	chartWidthPx   = 1600
 	klineHeightPx  = 600
 	volumeHeightPx = 260
 	macdHeightPx   = 260
+	oscHeightPx    = 260
+
+	// wtLevel 为 WaveTrend 常用的超买/超卖线。
+	wtLevel = 60
 )
 
 func RenderComposite(input CompositeInput) (ImageResult, error) {
@@ -88,16 +143,16 @@ func RenderComposite(input CompositeInput) (ImageResult, error) {
 	if len(input.Intervals) == 0 {
 		return ImageResult{}, fmt.Errorf("at least one interval required for %s", input.Symbol)
 	}
-	html, desc, err := buildCompositeHTML(input)
+	layout := resolveChartLayout(input.Options)
+	html, desc, err := buildCompositeHTML(input, layout)
 	if err != nil {
 		return ImageResult{}, err
 	}
-	blockHeight := klineHeightPx + volumeHeightPx + macdHeightPx
-	height := len(input.Intervals) * blockHeight
+	height := len(input.Intervals) * layout.blockHeight()
 	if height < 520 {
 		height = 520
 	}
-	png, err := renderHTMLToPNG(input.Context, html, chartWidthPx, height)
+	png, err := renderHTMLToPNG(input.Context, html, layout.width, height)
 	if err != nil {
 		return ImageResult{}, err
 	}
@@ -117,8 +172,53 @@ var (
 	renderLimiterMu   sync.RWMutex
 	renderLimiter     chan struct{}
 	renderLimiterSize int
+
+	chartOptionsMu      sync.RWMutex
+	defaultChartOptions ChartOptions
 )
 
+// SetChartOptions 设置全局默认的图表尺寸与配色，单次渲染可通过 CompositeInput.Options 覆盖。
+func SetChartOptions(o ChartOptions) {
+	chartOptionsMu.Lock()
+	defer chartOptionsMu.Unlock()
+	defaultChartOptions = o
+}
+
+// resolveChartLayout 依次取 o、全局默认、内置默认；副图高度随主图按比例缩放。
+func resolveChartLayout(o ChartOptions) chartLayout {
+	chartOptionsMu.RLock()
+	def := defaultChartOptions
+	chartOptionsMu.RUnlock()
+	width := firstPositive(o.Width, def.Width, chartWidthPx)
+	kline := firstPositive(o.KlineHeight, def.KlineHeight, klineHeightPx)
+	theme := strings.ToLower(strings.TrimSpace(o.Theme))
+	if theme == "" {
+		theme = strings.ToLower(strings.TrimSpace(def.Theme))
+	}
+	palette, ok := chartPalettes[theme]
+	if !ok {
+		palette = chartPalettes[ChartThemeDark]
+	}
+	scale := func(h int) int { return h * kline / klineHeightPx }
+	return chartLayout{
+		width:        width,
+		klineHeight:  kline,
+		volumeHeight: scale(volumeHeightPx),
+		macdHeight:   scale(macdHeightPx),
+		oscHeight:    scale(oscHeightPx),
+		palette:      palette,
+	}
+}
+
+func firstPositive(vals ...int) int {
+	for _, v := range vals {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
 // SetRenderConcurrency limits the number of concurrent render jobs.
 // Values <= 0 fallback to 1.
 func SetRenderConcurrency(limit int) {
@@ -167,7 +267,7 @@ func EnsureHeadlessAvailable(ctx context.Context) error {
 	return headlessErr
 }
 
-func buildCompositeHTML(input CompositeInput) ([]byte, string, error) {
+func buildCompositeHTML(input CompositeInput, layout chartLayout) ([]byte, string, error) {
 	page := components.NewPage()
 	page.SetLayout(components.PageFlexLayout)
 	descriptions := make([]string, 0, len(input.Intervals))
@@ -192,37 +292,33 @@ func buildCompositeHTML(input CompositeInput) ([]byte, string, error) {
 		minAxis := round(minPrice-padding, 4)
 		maxAxis := round(maxPrice+padding, 4)
 
-		init := opts.Initialization{
-			Theme:           types.ThemeWesteros,
-			Width:           fmt.Sprintf("%dpx", chartWidthPx),
-			Height:          fmt.Sprintf("%dpx", klineHeightPx),
-			BackgroundColor: colorBackground,
-		}
+		palette := layout.palette
+		init := layout.init(layout.klineHeight)
 		kline := charts.NewKLine()
 		kline.SetGlobalOptions(
 			charts.WithInitializationOpts(init),
-			charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), TextStyle: &opts.TextStyle{Color: colorTextPrimary}}),
+			charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), TextStyle: &opts.TextStyle{Color: palette.textPrimary}}),
 			charts.WithTitleOpts(opts.Title{
 				Title:         fmt.Sprintf("%s %s", strings.ToUpper(input.Symbol), interval),
 				Subtitle:      info.Subtitle,
 				Left:          "left",
 				Top:           "10",
-				TitleStyle:    &opts.TextStyle{Color: colorTextPrimary, FontSize: 18},
-				SubtitleStyle: &opts.TextStyle{Color: colorTextSecondary},
+				TitleStyle:    &opts.TextStyle{Color: palette.textPrimary, FontSize: 18},
+				SubtitleStyle: &opts.TextStyle{Color: palette.textSecondary},
 			}),
 			charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
 			charts.WithDataZoomOpts(opts.DataZoom{Type: "slider", XAxisIndex: []int{0}}),
 			charts.WithXAxisOpts(opts.XAxis{
 				Type:      "category",
-				AxisLabel: &opts.AxisLabel{Color: colorTextSecondary},
+				AxisLabel: &opts.AxisLabel{Color: palette.textSecondary},
 				SplitLine: &opts.SplitLine{Show: opts.Bool(false)},
 			}),
 			charts.WithYAxisOpts(opts.YAxis{
 				Scale:     opts.Bool(true),
-				AxisLabel: &opts.AxisLabel{Color: colorTextSecondary},
+				AxisLabel: &opts.AxisLabel{Color: palette.textSecondary},
 				Min:       minAxis,
 				Max:       maxAxis,
-				SplitLine: &opts.SplitLine{Show: opts.Bool(true), LineStyle: &opts.LineStyle{Color: colorTextSecondary, Opacity: opts.Float(0.2)}},
+				SplitLine: &opts.SplitLine{Show: opts.Bool(true), LineStyle: &opts.LineStyle{Color: palette.textSecondary, Opacity: opts.Float(0.2)}},
 			}),
 		)
 		kline.SetSeriesOptions(
@@ -237,16 +333,17 @@ func buildCompositeHTML(input CompositeInput) ([]byte, string, error) {
 		xAxis := buildXAxis(candles)
 		klineData := buildKlineSeries(interval, candles)
 		kline.SetXAxis(xAxis)
-		kline.AddSeries(fmt.Sprintf("Price_%s", interval), klineData)
+		kline.AddSeries(fmt.Sprintf("Price_%s", interval), klineData, divergenceMarkPoints(xAxis, candles, input.Divergence)...)
 
 		emaLine := buildEMALine(interval, candles, input.Indicators[interval])
 		emaLine.SetXAxis(xAxis)
 		kline.Overlap(emaLine)
 
-		volume := buildVolumeChart(interval, xAxis, candles)
-		macdChart := buildMACDChart(interval, xAxis, candles, history)
+		volume := buildVolumeChart(interval, xAxis, candles, layout)
+		macdChart := buildMACDChart(interval, xAxis, candles, history, layout)
+		oscChart := buildOscillatorChart(interval, xAxis, candles, history, layout)
 
-		page.AddCharts(kline, volume, macdChart)
+		page.AddCharts(kline, volume, macdChart, oscChart)
 	}
 
 	if len(page.Charts) == 0 {
@@ -322,16 +419,12 @@ func emaLegendLabel(note, fallback string) string {
 	return fallback
 }
 
-func buildVolumeChart(interval string, xAxis []string, candles []market.Candle) *charts.Bar {
+func buildVolumeChart(interval string, xAxis []string, candles []market.Candle, layout chartLayout) *charts.Bar {
+	palette := layout.palette
 	bar := charts.NewBar()
 	bar.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{
-			Theme:           types.ThemeWesteros,
-			Width:           fmt.Sprintf("%dpx", chartWidthPx),
-			Height:          fmt.Sprintf("%dpx", volumeHeightPx),
-			BackgroundColor: colorBackground,
-		}),
-		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("Volume %s", interval), Left: "left", TitleStyle: &opts.TextStyle{Color: colorTextPrimary}}),
+		charts.WithInitializationOpts(layout.init(layout.volumeHeight)),
+		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("Volume %s", interval), Left: "left", TitleStyle: &opts.TextStyle{Color: palette.textPrimary}}),
 		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(false)}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
 		charts.WithXAxisOpts(opts.XAxis{
@@ -339,8 +432,8 @@ func buildVolumeChart(interval string, xAxis []string, candles []market.Candle)
 			AxisLabel:   &opts.AxisLabel{Show: opts.Bool(false)},
 		}),
 		charts.WithYAxisOpts(opts.YAxis{
-			AxisLabel: &opts.AxisLabel{Show: opts.Bool(true), Color: colorTextSecondary},
-			SplitLine: &opts.SplitLine{Show: opts.Bool(true), LineStyle: &opts.LineStyle{Color: colorTextSecondary, Opacity: opts.Float(0.15)}},
+			AxisLabel: &opts.AxisLabel{Show: opts.Bool(true), Color: palette.textSecondary},
+			SplitLine: &opts.SplitLine{Show: opts.Bool(true), LineStyle: &opts.LineStyle{Color: palette.textSecondary, Opacity: opts.Float(0.15)}},
 		}),
 	)
 	vols := make([]opts.BarData, len(candles))
@@ -362,22 +455,18 @@ func buildVolumeChart(interval string, xAxis []string, candles []market.Candle)
 	return bar
 }
 
-func buildMACDChart(interval string, xAxis []string, candles []market.Candle, history []market.Candle) *charts.Bar {
+func buildMACDChart(interval string, xAxis []string, candles []market.Candle, history []market.Candle, layout chartLayout) *charts.Bar {
+	palette := layout.palette
 	bar := charts.NewBar()
 	bar.SetGlobalOptions(
-		charts.WithInitializationOpts(opts.Initialization{
-			Theme:           types.ThemeWesteros,
-			Width:           fmt.Sprintf("%dpx", chartWidthPx),
-			Height:          fmt.Sprintf("%dpx", macdHeightPx),
-			BackgroundColor: colorBackground,
-		}),
-		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("MACD %s", interval), Left: "left", TitleStyle: &opts.TextStyle{Color: colorTextPrimary}}),
-		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), TextStyle: &opts.TextStyle{Color: colorTextSecondary}}),
+		charts.WithInitializationOpts(layout.init(layout.macdHeight)),
+		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("MACD %s", interval), Left: "left", TitleStyle: &opts.TextStyle{Color: palette.textPrimary}}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), TextStyle: &opts.TextStyle{Color: palette.textSecondary}}),
 		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
 		charts.WithXAxisOpts(opts.XAxis{AxisLabel: &opts.AxisLabel{Show: opts.Bool(false)}}),
 		charts.WithYAxisOpts(opts.YAxis{
-			AxisLabel: &opts.AxisLabel{Show: opts.Bool(true), Color: colorTextSecondary},
-			SplitLine: &opts.SplitLine{Show: opts.Bool(true), LineStyle: &opts.LineStyle{Color: colorTextSecondary, Opacity: opts.Float(0.15)}},
+			AxisLabel: &opts.AxisLabel{Show: opts.Bool(true), Color: palette.textSecondary},
+			SplitLine: &opts.SplitLine{Show: opts.Bool(true), LineStyle: &opts.LineStyle{Color: palette.textSecondary, Opacity: opts.Float(0.15)}},
 		}),
 	)
 	dif, dea, hist := calcMACDSeries(history)
@@ -420,6 +509,85 @@ func buildMACDChart(interval string, xAxis []string, candles []market.Candle, hi
 	return bar
 }
 
+// buildOscillatorChart 绘制 WT(wt1) 与 MFI 副图，WT 附 ±60 超买/超卖线；指标在完整历史上计算后截取可见窗口。
+func buildOscillatorChart(interval string, xAxis []string, candles []market.Candle, history []market.Candle, layout chartLayout) *charts.Line {
+	palette := layout.palette
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithInitializationOpts(layout.init(layout.oscHeight)),
+		charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("WT / MFI %s", interval), Left: "left", TitleStyle: &opts.TextStyle{Color: palette.textPrimary}}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), TextStyle: &opts.TextStyle{Color: palette.textSecondary}}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithXAxisOpts(opts.XAxis{AxisLabel: &opts.AxisLabel{Show: opts.Bool(false)}}),
+		charts.WithYAxisOpts(opts.YAxis{
+			AxisLabel: &opts.AxisLabel{Show: opts.Bool(true), Color: palette.textSecondary},
+			SplitLine: &opts.SplitLine{Show: opts.Bool(true), LineStyle: &opts.LineStyle{Color: palette.textSecondary, Opacity: opts.Float(0.15)}},
+		}),
+	)
+	line.SetSeriesOptions(
+		charts.WithLineChartOpts(opts.LineChart{ShowSymbol: opts.Bool(false)}),
+	)
+	wt := tailSeries(screen.WaveTrendSeries(history), len(candles))
+	mfi := screen.MFISeries(history)
+	for i := 0; i < len(mfi) && mfi[i] == 0; i++ {
+		mfi[i] = math.NaN()
+	}
+	mfi = tailSeries(mfi, len(candles))
+	line.SetXAxis(xAxis)
+	line.AddSeries("WT", toLineData(wt, len(candles)),
+		charts.WithLineStyleOpts(opts.LineStyle{Color: colorWT, Width: 2}),
+		charts.WithMarkLineNameYAxisItemOpts(
+			opts.MarkLineNameYAxisItem{Name: "OB", YAxis: wtLevel},
+			opts.MarkLineNameYAxisItem{Name: "OS", YAxis: -wtLevel},
+		),
+		charts.WithMarkLineStyleOpts(opts.MarkLineStyle{
+			Symbol:    []string{"none", "none"},
+			LineStyle: &opts.LineStyle{Color: palette.textSecondary, Type: "dashed", Opacity: opts.Float(0.5)},
+		}),
+	)
+	line.AddSeries("MFI", toLineData(mfi, len(candles)), charts.WithLineStyleOpts(opts.LineStyle{Color: colorMFI, Width: 2}))
+	return line
+}
+
+// divergenceMarkPoints 在主图上标注各指标当前成立的背离：顶背离标在两个高点上方，底背离标在两个低点下方。
+// 背离在可见窗口上判定，保证标注位置落在图内。
+func divergenceMarkPoints(xAxis []string, candles []market.Candle, scoring screen.DivergenceScoring) []charts.SeriesOpts {
+	markers := screen.DivergenceMarkers(candles, scoring)
+	if len(markers) == 0 {
+		return nil
+	}
+	items := make([]opts.MarkPointNameCoordItem, 0, len(markers)*2)
+	for _, m := range markers {
+		color, symbol, rotate := colorBull, "triangle", float32(0)
+		label := "Bull " + strings.ToUpper(m.Indicator)
+		if m.Direction == "bearish" {
+			color, rotate = colorBear, 180
+			label = "Bear " + strings.ToUpper(m.Indicator)
+		}
+		for _, idx := range []int{m.PrevIndex, m.CurIndex} {
+			if idx < 0 || idx >= len(candles) {
+				continue
+			}
+			price := candles[idx].Low
+			position := "bottom"
+			if m.Direction == "bearish" {
+				price = candles[idx].High
+				position = "top"
+			}
+			items = append(items, opts.MarkPointNameCoordItem{
+				Name:         label,
+				Coordinate:   []interface{}{xAxis[idx], price},
+				Symbol:       symbol,
+				SymbolSize:   14,
+				SymbolRotate: rotate,
+				ItemStyle:    &opts.ItemStyle{Color: color},
+				Label:        &opts.Label{Show: opts.Bool(true), Position: position, Color: color, Formatter: label},
+			})
+		}
+	}
+	return []charts.SeriesOpts{charts.WithMarkPointNameCoordItemOpts(items...)}
+}
+
 func toLineData(series []float64, length int) []opts.LineData {
 	line := make([]opts.LineData, length)
 	offset := length - len(series)
@@ -10,6 +10,7 @@ import (
 	"brale/internal/logger"
 	"brale/internal/market"
 	livehttp "brale/internal/transport/http/live"
+	tgbot "brale/internal/transport/telegram"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -20,6 +21,7 @@ type App struct {
 	liveHTTP   *livehttp.Server
 	metricsSvc *market.MetricsService
 	Summary    *StartupSummary
+	tgBot      *tgbot.Bot
 }
 
 func NewApp(cfg *brcfg.Config) (*App, error) {
@@ -53,6 +55,12 @@ func (a *App) Run(ctx context.Context) error {
 		})
 	}
 
+	if a.tgBot != nil {
+		group.Go(func() error {
+			return a.tgBot.Run(ctx)
+		})
+	}
+
 	group.Go(func() error {
 		defer a.live.Close()
 		return a.live.Run(ctx)
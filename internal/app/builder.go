@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"brale/internal/agent"
+	"brale/internal/agent/usage"
 	"brale/internal/analysis/visual"
 	brcfg "brale/internal/config"
 	cfgloader "brale/internal/config/loader"
@@ -23,6 +24,7 @@ import (
 	"brale/internal/profile"
 	promptkit "brale/internal/prompt"
 	"brale/internal/store"
+	"brale/internal/store/archive"
 	"brale/internal/store/gormstore"
 	"brale/internal/store/sqlite"
 	"brale/internal/strategy"
@@ -37,10 +39,10 @@ type AppBuilder struct {
 	cfg *brcfg.Config
 
 	promptManagerFn     func(string) (*strategy.Manager, error)
-	marketStackFn       func(context.Context, *brcfg.Config, []string, []string, map[string]int, []string) (*MarketStack, error)
-	modelProvidersFn    func(context.Context, brcfg.AIConfig, int) ([]provider.ModelProvider, map[string]bool, bool, error)
+	marketStackFn       func(context.Context, *brcfg.Config, []string, []string, map[string]int, []string, map[string]string) (*MarketStack, error)
+	modelProvidersFn    func(context.Context, brcfg.AIConfig, int, provider.UsageMeter) ([]provider.ModelProvider, map[string]bool, bool, error)
 	decisionArtifactsFn func(context.Context, brcfg.AIConfig, *decision.DecisionEngine) (*decisionArtifacts, error)
-	freqManagerFn       func(brcfg.FreqtradeConfig, string, *database.DecisionLogStore, database.LivePositionStore, store.Store, notifier.TextNotifier) (*freqexec.Manager, error)
+	freqManagerFn       func(brcfg.FreqtradeConfig, string, *database.DecisionLogStore, database.LivePositionStore, store.Store, notifier.TextNotifier, DirectExecution) (*freqexec.Manager, error)
 	liveHTTPFn          func(brcfg.AppConfig, *database.DecisionLogStore, livehttp.FreqtradeWebhookHandler, []string, map[string]livehttp.SymbolDetail) (*livehttp.Server, error)
 
 	liveStoreOverride     database.LivePositionStore
@@ -86,6 +88,11 @@ func (b *AppBuilder) Build(ctx context.Context) (*App, error) {
 	cfg := b.cfg
 	logger.SetLevel(cfg.App.LogLevel)
 	visual.SetRenderConcurrency(cfg.Advanced.VisualRenderConcurrency)
+	visual.SetChartOptions(visual.ChartOptions{
+		Width:       cfg.Advanced.VisualChart.Width,
+		KlineHeight: cfg.Advanced.VisualChart.KlineHeight,
+		Theme:       cfg.Advanced.VisualChart.Theme,
+	})
 
 	profiles, err := b.loadProfileSetup(cfg)
 	if err != nil {
@@ -102,7 +109,7 @@ func (b *AppBuilder) Build(ctx context.Context) (*App, error) {
 		return nil, err
 	}
 
-	marketStack, err := b.marketStackFn(ctx, cfg, profiles.symbols, profiles.intervals, profiles.lookbacks, profiles.derivativeSymbols)
+	marketStack, err := b.marketStackFn(ctx, cfg, profiles.symbols, profiles.intervals, profiles.lookbacks, profiles.derivativeSymbols, profiles.sourceRoutes)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +125,8 @@ func (b *AppBuilder) Build(ctx context.Context) (*App, error) {
 		logger.Infof("✓ Fear & Greed 数据服务未启用（profile 未请求）")
 	}
 
-	providers, finalDisabled, visionReady, err := b.modelProvidersFn(ctx, cfg.AI, cfg.MCP.TimeoutSeconds)
+	usageLedger := usage.NewLedger(cfg.AI.Usage)
+	providers, finalDisabled, visionReady, err := b.modelProvidersFn(ctx, cfg.AI, cfg.MCP.TimeoutSeconds, usageLedger)
 	if err != nil {
 		return nil, err
 	}
@@ -144,49 +152,79 @@ func (b *AppBuilder) Build(ctx context.Context) (*App, error) {
 		Sentiment:          marketStack.Sentiment,
 		FearGreed:          fearGreedSvc,
 		TimeoutSeconds:     cfg.MCP.TimeoutSeconds,
+		History:            cfg.AI.DecisionHistory,
 	})
 
 	tgClient := newTelegram(cfg.Notify)
-	var textNotifier notifier.TextNotifier
-	if tgClient != nil {
-		textNotifier = tgClient
+	notify, err := buildNotifier(cfg.Notify, tgClient)
+	if err != nil {
+		return nil, err
 	}
-	if textNotifier != nil {
-		engine.AgentNotifier = textNotifier
+	var textNotifier notifier.TextNotifier
+	if notify != nil {
+		textNotifier = notify
+		engine.AgentNotifier = notify
 	}
 
 	decArtifacts, err := b.decisionArtifactsFn(ctx, cfg.AI, engine)
 	if err != nil {
 		return nil, err
 	}
+	var usageStore usage.Store
+	if decArtifacts.store != nil {
+		usageStore = decArtifacts.store
+	}
+	usageLedger.Attach(ctx, usageStore, textNotifier)
 
 	stores, err := b.resolveStores(cfg, decArtifacts)
 	if err != nil {
 		return nil, err
 	}
 
-	freqManager, err := b.freqManagerFn(cfg.Freqtrade, cfg.AI.ActiveHorizon, decArtifacts.store, stores.liveStore, stores.stateStore, textNotifier)
+	orderBook := buildOrderBookTracker(updater, profiles.orderBookSymbols)
+	funding := buildFundingTracker(updater, profiles.snapshot, profiles.symbols)
+	profileMgr := b.buildProfileManager(cfg, profiles.loader, ks, promptLoader, orderBook, funding)
+
+	direct, err := buildDirectExecution(cfg.Execution, profileMgr)
 	if err != nil {
 		return nil, err
 	}
-
-	profileMgr := b.buildProfileManager(cfg, profiles.loader, ks, promptLoader)
+	freqManager, err := b.freqManagerFn(cfg.Freqtrade, cfg.AI.ActiveHorizon, decArtifacts.store, stores.liveStore, stores.stateStore, textNotifier, direct)
+	if err != nil {
+		return nil, err
+	}
+	if direct.Binance != nil && freqManager != nil {
+		direct.Binance.OnFill = freqManager.HandleExchangeFill
+		if cfg.Freqtrade.Enabled && cfg.Execution.Binance.VerifyFills {
+			freqManager.SetPositionVerifier(direct.Binance)
+		}
+	}
+	webhooks := buildWebhooks(cfg.Notify.Webhooks, stores.webhookLog)
+	if freqManager != nil {
+		freqManager.SetWebhooks(webhooks)
+	}
 
 	exitRegistry, planHandlers, exitPromptIndex, symbolDetails, err := b.setupExitPlans(cfg, engine, profiles.snapshot)
 	if err != nil {
 		return nil, err
 	}
 
+	archiver, err := buildArchiver(cfg.Store.Retention, stores.archiveSource)
+	if err != nil {
+		return nil, err
+	}
+
 	liveSvc := agent.NewLiveService(agent.LiveServiceParams{
 		Config:          cfg,
 		KlineStore:      ks,
 		Updater:         updater,
 		Metrics:         metricsSvc,
 		Engine:          engine,
-		Telegram:        tgClient,
+		Notifier:        notify,
 		DecisionLogs:    decArtifacts.store,
 		Symbols:         profiles.symbols,
 		Intervals:       profiles.intervals,
+		Lookbacks:       profiles.lookbacks,
 		HorizonName:     cfg.AI.ActiveHorizon,
 		HorizonSummary:  profiles.summary,
 		WarmupSummary:   warmupSummary,
@@ -197,8 +235,32 @@ func (b *AppBuilder) Build(ctx context.Context) (*App, error) {
 		PlanHandlers:    planHandlers,
 		StrategyStore:   stores.strategyStore,
 		ExitPlanPrompts: exitPromptIndex,
+		PriceGuard:      buildPriceGuard(cfg, updater, textNotifier),
+		VolBreaker:      buildVolatilityBreaker(cfg, ks, updater, profiles.symbols, textNotifier),
+		SymbolInfo:      buildSymbolInfoService(cfg, updater, direct),
+		Correlation:     buildCorrelation(cfg, ks, updater, profiles.symbols),
+		Webhooks:        webhooks,
+		Annotations:     stores.annotations,
+		Archiver:        archiver,
+		ProfileLoader:   profiles.loader,
+		FillStream:      direct.fillStream(),
+		OrderBook:       orderBook,
+		Funding:         funding,
+		Prompts:         pm,
+		KlineCache:      marketStack.KlineCache,
 	})
 
+	profiles.loader.Subscribe(func(snapshot cfgloader.ProfileSnapshot) {
+		syms, intervals, lookbacks, _, err := collectProfileUniverse(snapshot, cfg.Kline.MaxCached)
+		if err != nil {
+			logger.Warnf("profile 热更新: 交易对/周期校验失败，保持原订阅: %v", err)
+			return
+		}
+		liveSvc.ApplyProfileUniverse(syms, intervals, lookbacks)
+	})
+
+	tgBot := buildTelegramBot(cfg.Notify.Telegram, tgClient, liveSvc)
+
 	var freqHandler livehttp.FreqtradeWebhookHandler
 	if freqManager != nil {
 		freqHandler = liveSvc
@@ -222,6 +284,7 @@ func (b *AppBuilder) Build(ctx context.Context) (*App, error) {
 		live:       liveSvc,
 		liveHTTP:   liveHTTPServe,
 		metricsSvc: metricsSvc,
+		tgBot:      tgBot,
 		Summary: &StartupSummary{
 			KLine: KLineSummary{
 				Symbols:   profiles.symbols,
@@ -242,7 +305,9 @@ type profileSetup struct {
 	intervals         []string
 	lookbacks         map[string]int
 	derivativeSymbols []string
+	sourceRoutes      map[string]string
 	fearGreedEnabled  bool
+	orderBookSymbols  []string
 	summary           string
 }
 
@@ -266,6 +331,10 @@ func (b *AppBuilder) loadProfileSetup(cfg *brcfg.Config) (profileSetup, error) {
 	if err != nil {
 		return profileSetup{}, err
 	}
+	sourceRoutes, err := collectSourceRoutes(snapshot)
+	if err != nil {
+		return profileSetup{}, err
+	}
 	return profileSetup{
 		loader:            profileLoader,
 		snapshot:          snapshot,
@@ -273,7 +342,9 @@ func (b *AppBuilder) loadProfileSetup(cfg *brcfg.Config) (profileSetup, error) {
 		intervals:         intervals,
 		lookbacks:         lookbacks,
 		derivativeSymbols: derivativeSymbols,
+		sourceRoutes:      sourceRoutes,
 		fearGreedEnabled:  fearGreedEnabled,
+		orderBookSymbols:  collectOrderBookSymbols(snapshot),
 		summary:           formatProfileSummary(syms, intervals),
 	}, nil
 }
@@ -309,6 +380,9 @@ type storeSetup struct {
 	liveStore     database.LivePositionStore
 	stateStore    store.Store
 	sharedGorm    *gorm.DB
+	archiveSource archive.Source
+	webhookLog    database.WebhookDeliveryLog
+	annotations   database.AnnotationStore
 }
 
 func (b *AppBuilder) resolveStores(cfg *brcfg.Config, decArtifacts *decisionArtifacts) (storeSetup, error) {
@@ -317,6 +391,9 @@ func (b *AppBuilder) resolveStores(cfg *brcfg.Config, decArtifacts *decisionArti
 		return out, nil
 	}
 
+	if cfg != nil && cfg.Store.Driver == brcfg.StoreDriverPostgres {
+		return resolvePostgresStores(cfg, decArtifacts, out)
+	}
 	livePath, err := resolveLiveStorePath(cfg)
 	if err != nil {
 		return storeSetup{}, err
@@ -328,6 +405,9 @@ func (b *AppBuilder) resolveStores(cfg *brcfg.Config, decArtifacts *decisionArti
 	out.strategyStore = gormStore
 	out.liveStore = gormStore
 	out.sharedGorm = gormStore.GormDB()
+	out.archiveSource = gormStore
+	out.webhookLog = gormStore
+	out.annotations = gormStore
 
 	if shouldShareDecisionLog(cfg, livePath) {
 		if err := attachDecisionLogDB(gormStore, decArtifacts); err != nil {
@@ -347,6 +427,38 @@ func (b *AppBuilder) resolveStores(cfg *brcfg.Config, decArtifacts *decisionArti
 	return out, nil
 }
 
+// resolvePostgresStores 在 postgres 模式下让 live/strategy/state 与决策日志共用同一个库，多实例部署时共享持仓与计划状态。
+func resolvePostgresStores(cfg *brcfg.Config, decArtifacts *decisionArtifacts, out storeSetup) (storeSetup, error) {
+	gormStore, err := gormstore.NewPostgresGormStore(cfg.Store.DSN)
+	if err != nil {
+		return storeSetup{}, fmt.Errorf("初始化 postgres 存储失败: %w", err)
+	}
+	out.strategyStore = gormStore
+	out.liveStore = gormStore
+	out.sharedGorm = gormStore.GormDB()
+	out.archiveSource = gormStore
+	out.webhookLog = gormStore
+	out.annotations = gormStore
+
+	if decArtifacts != nil && decArtifacts.store != nil {
+		if err := attachDecisionLogDB(gormStore, decArtifacts); err != nil {
+			return storeSetup{}, err
+		}
+	} else {
+		logger.Warnf("store.driver=postgres 但未配置 ai.decision_log_path，决策日志未启用")
+	}
+
+	if out.stateStore == nil {
+		ns, err := initStateStore("", out.sharedGorm)
+		if err != nil {
+			return storeSetup{}, err
+		}
+		out.stateStore = ns
+	}
+	logger.Infof("✓ live/决策日志存储使用 postgres")
+	return out, nil
+}
+
 func applyStoreOverrides(b *AppBuilder) storeSetup {
 	var out storeSetup
 	if b == nil {
@@ -376,7 +488,8 @@ func attachDecisionLogDB(gormStore *gormstore.GormStore, decArtifacts *decisionA
 	if err != nil {
 		return fmt.Errorf("获取 SQL DB 失败: %w", err)
 	}
-	if err := decArtifacts.store.UseExternalDB(sqlDB); err != nil {
+	dialect := database.ParseDecisionLogDialect(gormStore.Dialect())
+	if err := decArtifacts.store.UseExternalDBWithDialect(sqlDB, dialect); err != nil {
 		return fmt.Errorf("绑定决策日志存储失败: %w", err)
 	}
 	return nil
@@ -441,13 +554,19 @@ func initStateStore(path string, shared *gorm.DB) (store.Store, error) {
 	return ns, nil
 }
 
-func (b *AppBuilder) buildProfileManager(cfg *brcfg.Config, loader *cfgloader.ProfileLoader, ks market.KlineStore, promptLoader profile.PromptLoader) *profile.Manager {
+func (b *AppBuilder) buildProfileManager(cfg *brcfg.Config, loader *cfgloader.ProfileLoader, ks market.KlineStore, promptLoader profile.PromptLoader, orderBook *market.OrderBookTracker, funding *market.FundingTracker) *profile.Manager {
 	exporter, ok := ks.(store.SnapshotExporter)
 	if !ok {
 		logger.Warnf("K 线存储不支持快照导出，Pipeline 功能被禁用")
 		return nil
 	}
 	pipeFactory := &factory.Factory{Exporter: exporter, DefaultLimit: cfg.Kline.MaxCached}
+	if orderBook != nil {
+		pipeFactory.OrderBook = orderBook
+	}
+	if funding != nil {
+		pipeFactory.Funding = funding
+	}
 	return profile.NewManager(loader, pipeFactory, promptLoader)
 }
 
@@ -571,7 +690,7 @@ func WithPromptManager(fn func(string) (*strategy.Manager, error)) AppBuilderOpt
 	}
 }
 
-func WithMarketStack(fn func(context.Context, *brcfg.Config, []string, []string, map[string]int, []string) (*MarketStack, error)) AppBuilderOption {
+func WithMarketStack(fn func(context.Context, *brcfg.Config, []string, []string, map[string]int, []string, map[string]string) (*MarketStack, error)) AppBuilderOption {
 	return func(b *AppBuilder) {
 		if fn != nil {
 			b.marketStackFn = fn
@@ -579,7 +698,7 @@ func WithMarketStack(fn func(context.Context, *brcfg.Config, []string, []string,
 	}
 }
 
-func WithModelProviders(fn func(context.Context, brcfg.AIConfig, int) ([]provider.ModelProvider, map[string]bool, bool, error)) AppBuilderOption {
+func WithModelProviders(fn func(context.Context, brcfg.AIConfig, int, provider.UsageMeter) ([]provider.ModelProvider, map[string]bool, bool, error)) AppBuilderOption {
 	return func(b *AppBuilder) {
 		if fn != nil {
 			b.modelProvidersFn = fn
@@ -609,7 +728,7 @@ func WithStorageOverrides(live database.LivePositionStore, strategy exit.Strateg
 	}
 }
 
-func WithFreqManager(fn func(brcfg.FreqtradeConfig, string, *database.DecisionLogStore, database.LivePositionStore, store.Store, notifier.TextNotifier) (*freqexec.Manager, error)) AppBuilderOption {
+func WithFreqManager(fn func(brcfg.FreqtradeConfig, string, *database.DecisionLogStore, database.LivePositionStore, store.Store, notifier.TextNotifier, DirectExecution) (*freqexec.Manager, error)) AppBuilderOption {
 	return func(b *AppBuilder) {
 		if fn != nil {
 			b.freqManagerFn = fn
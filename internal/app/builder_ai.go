@@ -14,6 +14,7 @@ import (
 	"brale/internal/gateway/provider"
 	"brale/internal/logger"
 	"brale/internal/market"
+	"brale/internal/store/artifact"
 	"brale/internal/strategy"
 )
 
@@ -34,6 +35,7 @@ type engineConfig struct {
 	Sentiment          *market.SentimentService
 	FearGreed          *market.FearGreedService
 	TimeoutSeconds     int
+	History            brcfg.DecisionHistoryConfig
 }
 
 type decisionArtifacts struct {
@@ -50,12 +52,20 @@ func buildDecisionArtifacts(ctx context.Context, cfg brcfg.AIConfig, engine *dec
 		return nil, fmt.Errorf("初始化决策日志存储失败: %w", err)
 	}
 	artifacts.store = store
+	if err := attachArtifactStore(store, cfg.Artifacts); err != nil {
+		return nil, err
+	}
+	if days := cfg.Artifacts.RetentionDays; days > 0 {
+		store.StartRetention(ctx, time.Duration(days)*24*time.Hour, time.Hour)
+		logger.Infof("✓ 决策日志保留 %d 天，过期记录与 artifact 定期清理", days)
+	}
 	if engine != nil {
 		if obs := database.NewDecisionLogObserver(store); obs != nil {
 			engine.Observer = obs
 		}
 		engine.AgentHistory = store
 		engine.ProviderHistory = store
+		engine.DecisionHistory = store
 	}
 	logPath := cfg.DecisionLogPath
 	if abs, err := filepath.Abs(logPath); err == nil {
@@ -75,7 +85,7 @@ func buildAggregator(cfg brcfg.AIConfig) decision.Aggregator {
 	return decision.FirstWinsAggregator{}
 }
 
-func buildModelProviders(ctx context.Context, cfg brcfg.AIConfig, timeoutSeconds int) ([]provider.ModelProvider, map[string]bool, bool, error) {
+func buildModelProviders(ctx context.Context, cfg brcfg.AIConfig, timeoutSeconds int, meter provider.UsageMeter) ([]provider.ModelProvider, map[string]bool, bool, error) {
 	var (
 		modelCfgs   []provider.ModelCfg
 		visionReady bool
@@ -105,6 +115,8 @@ func buildModelProviders(ctx context.Context, cfg brcfg.AIConfig, timeoutSeconds
 			Headers:        m.Headers,
 			SupportsVision: m.SupportsVision,
 			ExpectJSON:     m.ExpectJSON,
+			Redact:         m.Redact,
+			Stream:         m.Stream,
 		})
 		if m.Enabled && m.SupportsVision {
 			visionReady = true
@@ -121,7 +133,7 @@ func buildModelProviders(ctx context.Context, cfg brcfg.AIConfig, timeoutSeconds
 		logger.Infof("所有启用模型均不支持图像，跳过可视化渲染初始化")
 	}
 	timeout := time.Duration(timeoutSeconds) * time.Second
-	providers := provider.BuildProvidersFromConfig(modelCfgs, timeout)
+	providers := provider.BuildProvidersFromConfig(modelCfgs, timeout, meter)
 	if len(providers) == 0 {
 		logger.Warnf("未启用任何 AI 模型（请检查 ai.models 配置）")
 	} else {
@@ -165,8 +177,11 @@ func buildDecisionEngine(cfg engineConfig) *decision.DecisionEngine {
 		Parallel:           true,
 		LogEachModel:       cfg.LogEachModel,
 		TimeoutSeconds:     cfg.TimeoutSeconds,
+		History:            cfg.History,
 	}
-	engine.PromptBuilder = decision.NewDefaultPromptBuilder(cfg.PromptMgr, cfg.Store, cfg.Metrics, cfg.Sentiment, cfg.FearGreed, cfg.Intervals, cfg.LogEachModel)
+	builder := decision.NewDefaultPromptBuilder(cfg.PromptMgr, cfg.Store, cfg.Metrics, cfg.Sentiment, cfg.FearGreed, cfg.Intervals, cfg.LogEachModel)
+	builder.HistoryMaxChars = cfg.History.MaxChars
+	engine.PromptBuilder = builder
 	return engine
 }
 
@@ -232,3 +247,38 @@ func applyDefaultMultiAgentBlocks(cfg *brcfg.Config, symbolCount, intervalCount
 	cfg.AI.MultiAgent.MaxBlocks = auto
 	logger.Infof("✓ Multi-Agent max_blocks 未配置，自动使用 %d（%d 个币种 × %d 个周期）", auto, symbolCount, intervalCount)
 }
+
+func newS3ArtifactStore(cfg brcfg.S3ArtifactConfig) (*artifact.S3Store, error) {
+	return artifact.NewS3Store(artifact.S3Config{
+		Endpoint:  cfg.Endpoint,
+		Bucket:    cfg.Bucket,
+		Region:    cfg.Region,
+		Prefix:    cfg.Prefix,
+		AccessKey: cfg.AccessKey,
+		SecretKey: cfg.SecretKey,
+		Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+	})
+}
+
+func attachArtifactStore(store *database.DecisionLogStore, cfg brcfg.ArtifactStoreConfig) error {
+	var (
+		st  artifact.Store
+		err error
+	)
+	switch cfg.Backend {
+	case "":
+		return nil
+	case "fs":
+		st, err = artifact.NewFSStore(cfg.Dir)
+	case "s3":
+		st, err = newS3ArtifactStore(cfg.S3)
+	default:
+		return fmt.Errorf("未知 artifact backend: %s", cfg.Backend)
+	}
+	if err != nil {
+		return fmt.Errorf("初始化 artifact 存储失败: %w", err)
+	}
+	store.SetArtifactStore(st, cfg.MinBytes)
+	logger.Infof("✓ 决策日志 artifact 外置存储 backend=%s min_bytes=%d", st.Name(), cfg.MinBytes)
+	return nil
+}
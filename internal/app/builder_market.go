@@ -5,29 +5,33 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"brale/internal/agent"
+	"brale/internal/agent/risk"
 	brcfg "brale/internal/config"
 	cfgloader "brale/internal/config/loader"
 	"brale/internal/exitplan"
 	"brale/internal/gateway"
+	"brale/internal/gateway/binance"
+	"brale/internal/gateway/notifier"
 	"brale/internal/logger"
 	"brale/internal/market"
 	"brale/internal/pkg/maputil"
 	"brale/internal/store"
 )
 
-const defaultIndicatorLookback = 240
-
 type MarketStack struct {
 	Store         market.KlineStore
 	Updater       *market.WSUpdater
 	Metrics       *market.MetricsService
 	Sentiment     *market.SentimentService
 	WarmupSummary string
+	KlineCache    *store.KlineDiskCache
 }
 
-func buildMarketStack(ctx context.Context, cfg *brcfg.Config, symbols []string, intervals []string, lookbacks map[string]int, metricsSymbols []string) (*MarketStack, error) {
-	src, err := gateway.NewSourceFromConfig(cfg)
+func buildMarketStack(ctx context.Context, cfg *brcfg.Config, symbols []string, intervals []string, lookbacks map[string]int, metricsSymbols []string, sourceRoutes map[string]string) (*MarketStack, error) {
+	src, err := gateway.NewSourceWithRoutes(cfg, sourceRoutes)
 	if err != nil {
 		return nil, fmt.Errorf("初始化行情源失败: %w", err)
 	}
@@ -39,7 +43,15 @@ func buildMarketStack(ctx context.Context, cfg *brcfg.Config, symbols []string,
 	}()
 
 	kstore := store.NewMemoryKlineStore()
+	kstore.SetValidation(store.CandleValidation{
+		Enabled:     cfg.Kline.Validation.Enabled,
+		MaxSigma:    cfg.Kline.Validation.MaxSigma,
+		SigmaWindow: cfg.Kline.Validation.SigmaWindow,
+		Refetch:     cfg.Kline.Validation.Refetch,
+	}, src)
 	updater := market.NewWSUpdater(kstore, cfg.Kline.MaxCached, src)
+	updater.MaxStreams = cfg.Market.MaxStreams
+	klineCache := loadKlineCache(ctx, cfg.Kline, kstore, updater, symbols, intervals, lookbacks)
 
 	preheater := market.NewPreheater(kstore, cfg.Kline.MaxCached, src)
 	preheater.Warmup(ctx, symbols, lookbacks)
@@ -68,6 +80,7 @@ func buildMarketStack(ctx context.Context, cfg *brcfg.Config, symbols []string,
 		Metrics:       metricsSvc,
 		Sentiment:     sentimentSvc,
 		WarmupSummary: warmupSummary,
+		KlineCache:    klineCache,
 	}, nil
 }
 
@@ -123,6 +136,34 @@ func collectProfileUniverse(snapshot cfgloader.ProfileSnapshot, defaultLimit int
 	return symbols, intervals, lookbacks, derivatives, nil
 }
 
+// collectSourceRoutes 汇总 profile.market_source 指定的 symbol → 行情源映射；同一 symbol 不允许被不同 profile 指向不同行情源。
+func collectSourceRoutes(snapshot cfgloader.ProfileSnapshot) (map[string]string, error) {
+	routes := make(map[string]string)
+	owners := make(map[string]string)
+	names := make([]string, 0, len(snapshot.Profiles))
+	for name := range snapshot.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := snapshot.Profiles[name]
+		for _, sym := range def.TargetsUpper() {
+			source := def.MarketSource
+			if prev, ok := routes[sym]; ok && prev != source {
+				return nil, fmt.Errorf("symbol %s 在 profile %s 与 %s 中指定了不同的 market_source (%q vs %q)", sym, owners[sym], name, prev, source)
+			}
+			routes[sym] = source
+			owners[sym] = name
+		}
+	}
+	for sym, source := range routes {
+		if source == "" {
+			delete(routes, sym)
+		}
+	}
+	return routes, nil
+}
+
 func validateProfileDef(name string, def cfgloader.ProfileDefinition) error {
 	if def.AnalysisSlice <= 0 {
 		return fmt.Errorf("profile %s 缺少 analysis_slice 配置", name)
@@ -182,14 +223,25 @@ func collectIntervals(def cfgloader.ProfileDefinition, needBars int, intervalSet
 
 func collectMiddlewareNeeds(name string, def cfgloader.ProfileDefinition, intervalSet map[string]struct{}, lookbacks map[string]int) error {
 	ints := def.IntervalsLower()
+	warmup := def.IndicatorWarmupBars()
+	synthetic := make(map[string]struct{})
 	for _, mw := range def.Middlewares {
 		switch strings.ToLower(strings.TrimSpace(mw.Name)) {
 		case "", "kline_fetcher":
-			if err := collectKlineFetcherNeeds(mw, ints, intervalSet, lookbacks); err != nil {
+			if err := collectKlineFetcherNeeds(mw, ints, warmup, intervalSet, lookbacks, synthetic); err != nil {
 				return fmt.Errorf("middleware %s: %w", mw.Name, err)
 			}
-		case "ema_trend", "rsi_extreme", "macd_trend":
-			if err := collectIndicatorNeeds(mw, intervalSet, lookbacks); err != nil {
+		}
+	}
+	for _, mw := range def.Middlewares {
+		switch strings.ToLower(strings.TrimSpace(mw.Name)) {
+		case "ema_trend", "rsi_extreme", "macd_trend", "bb_squeeze", "adx_trend", "supertrend", "liquidity_sweep", "rsi_divergence", "custom_indicator":
+			// 合成周期由 kline_fetcher 重采样得到，只校验参数，不新增订阅
+			set, lb := intervalSet, lookbacks
+			if _, ok := synthetic[strings.ToLower(strings.TrimSpace(maputil.String(mw.Params, "interval")))]; ok {
+				set, lb = make(map[string]struct{}), make(map[string]int)
+			}
+			if err := collectIndicatorNeeds(mw, set, lb); err != nil {
 				return fmt.Errorf("middleware %s: %w", mw.Name, err)
 			}
 		}
@@ -199,7 +251,8 @@ func collectMiddlewareNeeds(name string, def cfgloader.ProfileDefinition, interv
 
 // collectKlineFetcherNeeds merges intervals/limits required by kline_fetcher middlewares.
 // Example: intervals=[1h,4h], limit=500 will ensure lookbacks[1h]=500 and 4h too.
-func collectKlineFetcherNeeds(mw cfgloader.MiddlewareConfig, defaultIntervals []string, intervalSet map[string]struct{}, lookbacks map[string]int) error {
+// resample 中的合成周期不订阅，只把源周期的 lookback 提高到 (limit+1)×倍数，并记录到 synthetic。
+func collectKlineFetcherNeeds(mw cfgloader.MiddlewareConfig, defaultIntervals []string, warmup int, intervalSet map[string]struct{}, lookbacks map[string]int, synthetic map[string]struct{}) error {
 	klineIntervals := maputil.StringSlice(mw.Params, "intervals")
 	if len(klineIntervals) == 0 {
 		klineIntervals = defaultIntervals
@@ -224,6 +277,20 @@ func collectKlineFetcherNeeds(mw cfgloader.MiddlewareConfig, defaultIntervals []
 			lookbacks[norm] = limit
 		}
 	}
+	for _, iv := range maputil.StringSlice(mw.Params, "resample") {
+		norm := strings.ToLower(strings.TrimSpace(iv))
+		if !brcfg.IsValidInterval(norm) {
+			return fmt.Errorf("kline_fetcher resample 包含无效 interval: %s", norm)
+		}
+		source, ratio, ok := market.ResampleSource(norm, klineIntervals)
+		if !ok {
+			return fmt.Errorf("kline_fetcher resample %s 无法由 intervals %v 合成", norm, klineIntervals)
+		}
+		synthetic[norm] = struct{}{}
+		if need := (max(limit, warmup) + 1) * ratio; need > lookbacks[source] {
+			lookbacks[source] = need
+		}
+	}
 	return nil
 }
 
@@ -254,16 +321,17 @@ func collectIndicatorNeeds(mw cfgloader.MiddlewareConfig, intervalSet map[string
 		if maputil.Int(mw.Params, "fast") <= 0 || maputil.Int(mw.Params, "slow") <= 0 || maputil.Int(mw.Params, "signal") <= 0 {
 			return fmt.Errorf("macd_trend 需设置 fast/slow/signal")
 		}
+	case "custom_indicator":
+		if strings.TrimSpace(maputil.String(mw.Params, "feature")) == "" || strings.TrimSpace(maputil.String(mw.Params, "formula")) == "" {
+			return fmt.Errorf("custom_indicator 需设置 feature/formula")
+		}
 	}
 	return nil
 }
 
+// estimateProfileLookback 与 profile.Runtime.IndicatorBars 一致，保证预热拉取的条数能通过分析阶段的历史充足性检查。
 func estimateProfileLookback(def cfgloader.ProfileDefinition) int {
-	need := def.AnalysisSlice + def.SliceDropTail
-	if need < defaultIndicatorLookback {
-		need = defaultIndicatorLookback
-	}
-	return need
+	return max(def.AnalysisSlice+def.SliceDropTail, def.IndicatorWarmupBars())
 }
 
 func collectSymbolDetails(snapshot cfgloader.ProfileSnapshot, exitReg *exitplan.Registry) map[string]SymbolDetail {
@@ -457,3 +525,247 @@ func comboLabel(key string, exitReg *exitplan.Registry) string {
 		return key
 	}
 }
+
+// loadKlineCache 从磁盘快照恢复 K 线缓存，并通过 REST 补齐快照保存后缺失的 K 线；未启用或失败时返回 nil。
+func loadKlineCache(ctx context.Context, cfg brcfg.KlineConfig, ks *store.MemoryKlineStore, updater *market.WSUpdater, symbols, intervals []string, lookbacks map[string]int) *store.KlineDiskCache {
+	if !cfg.Persist.Enabled {
+		return nil
+	}
+	maxBars := cfg.Persist.MaxBars
+	if maxBars <= 0 {
+		maxBars = cfg.MaxCached
+	}
+	cache, err := store.NewKlineDiskCache(store.KlineDiskCacheConfig{
+		Dir:      cfg.Persist.Dir,
+		TTL:      time.Duration(cfg.Persist.TTLHours) * time.Hour,
+		MaxBars:  maxBars,
+		MaxBytes: int64(cfg.Persist.MaxTotalMB) << 20,
+	}, ks)
+	if err != nil {
+		logger.Warnf("K线快照未启用: %v", err)
+		return nil
+	}
+	streams, bars, err := cache.Load(ctx)
+	if err != nil {
+		logger.Warnf("加载 K线快照失败: %v", err)
+	}
+	if streams == 0 {
+		return cache
+	}
+	all := append([]string(nil), intervals...)
+	for iv := range lookbacks {
+		all = append(all, iv)
+	}
+	sum := updater.Backfill(ctx, symbols, dedupAndSort(all))
+	logger.Infof("✓ K线快照已加载 %d 个 stream / %d 根，补齐缺口 %d 根（失败 %d）", streams, bars, sum.Recovered, len(sum.Failed))
+	return cache
+}
+
+func buildPriceGuard(cfg *brcfg.Config, updater *market.WSUpdater, tg notifier.TextNotifier) *agent.PriceGuard {
+	if cfg == nil || !cfg.Advanced.PriceGuard.Enabled {
+		return nil
+	}
+	guardCfg := cfg.Advanced.PriceGuard
+	params := agent.PriceGuardParams{
+		Mode:               agent.PriceGuardModeIndex,
+		Label:              guardCfg.Reference,
+		Consensus:          guardCfg.Consensus,
+		TolerancePct:       guardCfg.TolerancePct,
+		FailOpen:           guardCfg.FailOpen,
+		Timeout:            time.Duration(guardCfg.TimeoutSeconds) * time.Second,
+		StreamMaxAge:       time.Duration(guardCfg.StreamMaxAgeSeconds) * time.Second,
+		DivergencePct:      guardCfg.DivergenceAlertPct,
+		DivergenceCooldown: time.Duration(guardCfg.DivergenceCooldownMinutes) * time.Minute,
+		Notifier:           tg,
+	}
+	if guardCfg.Reference == agent.PriceGuardModeIndex {
+		if updater == nil || updater.Source == nil {
+			logger.Warnf("price_guard 未启用：缺少行情源")
+			return nil
+		}
+		provider, ok := updater.Source.(market.ReferencePriceProvider)
+		if !ok {
+			logger.Warnf("price_guard 未启用：当前行情源不支持指数价")
+			return nil
+		}
+		params.Provider = provider
+	} else {
+		src, err := gateway.NewSourceByName(cfg, guardCfg.Reference)
+		if err != nil {
+			logger.Warnf("price_guard 未启用：初始化参考行情源失败: %v", err)
+			return nil
+		}
+		provider, ok := src.(market.ReferencePriceProvider)
+		if !ok {
+			logger.Warnf("price_guard 未启用：行情源 %s 不支持参考价查询", guardCfg.Reference)
+			return nil
+		}
+		params.Provider = provider
+		params.Mode = agent.PriceGuardModeLast
+		if guardCfg.Stream {
+			params.Stream = src
+		}
+	}
+	logger.Infof("✓ 触发价交叉校验已启用 reference=%s consensus=%s tolerance=%.4f stream=%v", guardCfg.Reference, guardCfg.Consensus, guardCfg.TolerancePct, params.Stream != nil)
+	return agent.NewPriceGuard(params)
+}
+
+// buildCorrelation 构建相关性闸门，K 线优先读本地缓存，不足时回退当前行情源。
+func buildCorrelation(cfg *brcfg.Config, ks market.KlineStore, updater *market.WSUpdater, symbols []string) *risk.Correlation {
+	if cfg == nil || !cfg.Advanced.Correlation.Enabled {
+		return nil
+	}
+	cc := cfg.Advanced.Correlation
+	params := risk.CorrelationParams{
+		KlineStore:    ks,
+		Symbols:       symbols,
+		Interval:      cc.Interval,
+		Lookback:      cc.Lookback,
+		Threshold:     cc.Threshold,
+		Action:        cc.Action,
+		DownsizeRatio: cc.DownsizeRatio,
+		Refresh:       time.Duration(cc.RefreshMinutes) * time.Minute,
+	}
+	if updater != nil && updater.Source != nil {
+		params.Source = updater.Source
+	}
+	corr := risk.NewCorrelation(params)
+	if corr == nil {
+		logger.Warnf("correlation 未启用：至少需要 2 个监控 symbol")
+		return nil
+	}
+	logger.Infof("✓ 相关性闸门已启用 interval=%s lookback=%d threshold=%.2f action=%s",
+		cc.Interval, cc.Lookback, cc.Threshold, cc.Action)
+	return corr
+}
+
+// buildSymbolInfoService 构建交易规则缓存：symbol_rules 或 leverage_brackets 启用时供执行前取整与杠杆封顶，
+// 直连 Binance 执行器启用时供其下单取整（即使两者都未启用）。
+// 未指定 symbol_rules.source 且需要 Binance 规则（直连执行器或 leverage_brackets）时按 execution.binance 的 REST 地址
+// 与 API key 拉取交易规则与杠杆档位，不要求启用直连执行器。
+func buildSymbolInfoService(cfg *brcfg.Config, updater *market.WSUpdater, direct DirectExecution) *market.SymbolInfoService {
+	if cfg == nil {
+		return nil
+	}
+	rules := cfg.Advanced.SymbolRules
+	bc := cfg.Execution.Binance
+	if !rules.Enabled && !bc.LeverageBrackets && direct.Binance == nil {
+		return nil
+	}
+	var src any
+	switch {
+	case rules.Source != "":
+		named, err := gateway.NewSourceByName(cfg, rules.Source)
+		if err != nil {
+			logger.Warnf("symbol_rules 未启用：初始化行情源 %s 失败: %v", rules.Source, err)
+			return nil
+		}
+		src = named
+	case direct.Binance != nil || bc.LeverageBrackets:
+		src = binance.NewRulesClient(binanceExecutorConfig(bc))
+	case updater != nil && updater.Source != nil:
+		src = updater.Source
+	default:
+		logger.Warnf("symbol_rules 未启用：缺少行情源")
+		return nil
+	}
+	svc := market.NewSymbolInfoService(src, time.Duration(rules.RefreshMinutes)*time.Minute)
+	if svc == nil {
+		logger.Warnf("symbol_rules 未启用：行情源不支持交易规则查询")
+		return nil
+	}
+	if direct.Binance != nil {
+		direct.Binance.SetSymbolRules(svc)
+	}
+	if rules.Enabled || bc.LeverageBrackets {
+		logger.Infof("✓ 交易规则取整已启用 refresh=%dm leverage_brackets=%v", rules.RefreshMinutes, bc.LeverageBrackets)
+	}
+	return svc
+}
+
+// collectOrderBookSymbols 汇总配置了 order_book 中间件的 profile 的 targets，仅这些交易对订阅部分深度。
+func collectOrderBookSymbols(snapshot cfgloader.ProfileSnapshot) []string {
+	set := make(map[string]struct{})
+	for _, def := range snapshot.Profiles {
+		for _, mw := range def.Middlewares {
+			if strings.TrimSpace(mw.Name) != "order_book" {
+				continue
+			}
+			for _, sym := range def.TargetsUpper() {
+				set[sym] = struct{}{}
+			}
+			break
+		}
+	}
+	return setToSortedSlice(set)
+}
+
+// profilesUseFundingRate 判断是否有 profile 配置了 funding_rate 中间件；分位需要全市场样本，因此一旦启用即跟踪全部监控 symbol。
+func profilesUseFundingRate(snapshot cfgloader.ProfileSnapshot) bool {
+	for _, def := range snapshot.Profiles {
+		for _, mw := range def.Middlewares {
+			if strings.TrimSpace(mw.Name) == "funding_rate" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func buildFundingTracker(updater *market.WSUpdater, snapshot cfgloader.ProfileSnapshot, symbols []string) *market.FundingTracker {
+	if updater == nil || updater.Source == nil || !profilesUseFundingRate(snapshot) {
+		return nil
+	}
+	tracker := market.NewFundingTracker(updater.Source, symbols, 0, 0)
+	if tracker == nil {
+		return nil
+	}
+	logger.Infof("✓ 资金费率跟踪已启用: %d 个交易对", len(symbols))
+	return tracker
+}
+
+func buildOrderBookTracker(updater *market.WSUpdater, symbols []string) *market.OrderBookTracker {
+	if updater == nil || len(symbols) == 0 {
+		return nil
+	}
+	tracker := market.NewOrderBookTracker(updater.Source, symbols)
+	if tracker == nil {
+		logger.Warnf("行情源不支持深度推送，order_book 中间件将无数据")
+		return nil
+	}
+	logger.Infof("✓ 盘口深度订阅已启用: %v", symbols)
+	return tracker
+}
+
+func buildVolatilityBreaker(cfg *brcfg.Config, ks market.KlineStore, updater *market.WSUpdater, symbols []string, notify notifier.TextNotifier) *agent.VolatilityBreaker {
+	if cfg == nil || !cfg.Advanced.VolatilityBreaker.Enabled {
+		return nil
+	}
+	vbCfg := cfg.Advanced.VolatilityBreaker
+	params := agent.VolatilityBreakerParams{
+		KlineStore:      ks,
+		Symbols:         symbols,
+		Interval:        vbCfg.Interval,
+		Window:          vbCfg.Window,
+		MaxVolPct:       vbCfg.MaxVolPct,
+		MarketWideRatio: vbCfg.MarketWideRatio,
+		LiquidationUSD:  vbCfg.LiquidationUSD,
+		Resume:          time.Duration(vbCfg.ResumeMinutes) * time.Minute,
+		CheckInterval:   time.Duration(vbCfg.CheckSeconds) * time.Second,
+	}
+	params.Notifier = notify
+	if updater != nil && updater.Source != nil {
+		params.Source = updater.Source
+		if provider, ok := updater.Source.(market.LiquidationProvider); ok {
+			params.Liquidations = provider
+		} else if vbCfg.LiquidationUSD > 0 {
+			logger.Warnf("volatility_breaker: 当前行情源不支持强平流，仅按波动率判断")
+		}
+	}
+	breaker := agent.NewVolatilityBreaker(params)
+	if breaker != nil {
+		logger.Infof("✓ 波动熔断已启用 interval=%s max_vol=%.4f ratio=%.2f liquidation_usd=%.0f",
+			vbCfg.Interval, vbCfg.MaxVolPct, vbCfg.MarketWideRatio, vbCfg.LiquidationUSD)
+	}
+	return breaker
+}
@@ -3,19 +3,90 @@ package app
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"brale/internal/agent"
 	brcfg "brale/internal/config"
+	"brale/internal/gateway/binance"
 	"brale/internal/gateway/database"
+	"brale/internal/gateway/exchange"
 	freqexec "brale/internal/gateway/freqtrade"
 	"brale/internal/gateway/notifier"
+	"brale/internal/gateway/webhook"
 	"brale/internal/logger"
+	"brale/internal/profile"
 	"brale/internal/store"
+	"brale/internal/store/archive"
+	"brale/internal/store/artifact"
 	livehttp "brale/internal/transport/http/live"
+	tgbot "brale/internal/transport/telegram"
 )
 
-func buildFreqManager(cfg brcfg.FreqtradeConfig, horizon string, logStore *database.DecisionLogStore, liveStore database.LivePositionStore, newStore store.Store, textNotifier notifier.TextNotifier) (*freqexec.Manager, error) {
+// DirectExecution 为不经过 freqtrade 的直连执行器；Resolve 返回 symbol 所属 profile 选择的执行器名称。
+type DirectExecution struct {
+	Binance *binance.Executor
+	Resolve func(symbol string) string
+	// TradingMode 返回 symbol 所属 profile 覆盖的 freqtrade 交易模式，空串表示使用全局配置。
+	TradingMode func(symbol string) string
+}
+
+func (d DirectExecution) fillStream() agent.FillStream {
+	if d.Binance == nil {
+		return nil
+	}
+	return d.Binance
+}
+
+func buildDirectExecution(cfg brcfg.ExecutionConfig, profiles *profile.Manager) (DirectExecution, error) {
+	out := DirectExecution{
+		Resolve: func(symbol string) string {
+			if rt, ok := profiles.Resolve(symbol); ok && rt != nil {
+				return rt.Definition.Executor
+			}
+			return ""
+		},
+		TradingMode: func(symbol string) string {
+			if rt, ok := profiles.Resolve(symbol); ok && rt != nil {
+				return rt.Definition.TradingMode
+			}
+			return ""
+		},
+	}
+	bc := cfg.Binance
+	if !bc.Enabled {
+		return out, nil
+	}
+	exec, err := binance.NewExecutor(binanceExecutorConfig(bc))
+	if err != nil {
+		return out, fmt.Errorf("初始化 Binance 直连执行器失败: %w", err)
+	}
+	logger.Infof("✓ Binance 直连执行器已启用: %s", bc.RESTBaseURL)
+	out.Binance = exec
+	return out, nil
+}
+
+func binanceExecutorConfig(bc brcfg.BinanceExecutionConfig) binance.ExecutorConfig {
+	return binance.ExecutorConfig{
+		APIKey:        bc.APIKey,
+		SecretKey:     bc.SecretKey,
+		RESTBaseURL:   bc.RESTBaseURL,
+		StakeCurrency: bc.StakeCurrency,
+		HTTPTimeout:   time.Duration(bc.TimeoutSeconds) * time.Second,
+	}
+}
+
+func buildFreqManager(cfg brcfg.FreqtradeConfig, horizon string, logStore *database.DecisionLogStore, liveStore database.LivePositionStore, newStore store.Store, textNotifier notifier.TextNotifier, direct DirectExecution) (*freqexec.Manager, error) {
 	if !cfg.Enabled {
-		return nil, nil
+		if direct.Binance == nil {
+			return nil, nil
+		}
+		// 未部署 freqtrade：直连执行器承担全部下单，成交由 user-data stream 回推。
+		logger.Infof("Freqtrade 未启用，所有 profile 使用直连执行器 %s", direct.Binance.Name())
+		manager, err := freqexec.NewManager(nil, cfg, logStore, liveStore, newStore, textNotifier, direct.Binance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init execution manager: %w", err)
+		}
+		return manager, nil
 	}
 	client, err := freqexec.NewClient(cfg)
 	if err != nil {
@@ -24,13 +95,58 @@ func buildFreqManager(cfg brcfg.FreqtradeConfig, horizon string, logStore *datab
 	logger.Infof("Freqtrade executor enabled: %s", cfg.APIURL)
 
 	adapter := freqexec.NewAdapter(client, &cfg)
-	manager, err := freqexec.NewManager(client, cfg, logStore, liveStore, newStore, textNotifier, adapter)
+	adapter.SetTradingMode(direct.TradingMode)
+	if mode := cfg.ResolveTradingMode(""); mode != brcfg.TradingModeFutures {
+		logger.Infof("Freqtrade 交易模式: %s", mode)
+	}
+	var executor exchange.Exchange = adapter
+	if direct.Binance != nil {
+		direct.Binance.Scope = func(symbol string) bool {
+			return direct.Resolve(symbol) == direct.Binance.Name()
+		}
+		executor = &exchange.Router{
+			Default:   executor,
+			Executors: map[string]exchange.Exchange{direct.Binance.Name(): direct.Binance},
+			Resolve:   direct.Resolve,
+		}
+	}
+	manager, err := freqexec.NewManager(client, cfg, logStore, liveStore, newStore, textNotifier, executor)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init freqtrade manager: %w", err)
 	}
 	return manager, nil
 }
 
+func buildArchiver(cfg brcfg.RetentionConfig, source archive.Source) (*archive.Service, error) {
+	if !cfg.Enabled || source == nil {
+		return nil, nil
+	}
+	tables := make(map[string]time.Duration, len(cfg.Tables))
+	for name, days := range cfg.Tables {
+		tables[name] = time.Duration(days) * 24 * time.Hour
+	}
+	var objects artifact.Store
+	backend := "dir"
+	if cfg.Backend == "s3" {
+		backend = cfg.Backend
+		st, err := newS3ArtifactStore(cfg.S3)
+		if err != nil {
+			return nil, fmt.Errorf("初始化归档 S3 存储失败: %w", err)
+		}
+		objects = st
+	}
+	logger.Infof("✓ 归档清理已启用 backend=%s dir=%s format=%s tables=%v", backend, cfg.ArchiveDir, cfg.Format, cfg.Tables)
+	return archive.NewService(archive.Params{
+		Source:    source,
+		Objects:   objects,
+		Dir:       cfg.ArchiveDir,
+		Format:    cfg.Format,
+		BatchSize: cfg.BatchSize,
+		Interval:  time.Duration(cfg.IntervalMinutes) * time.Minute,
+		Tables:    tables,
+	}), nil
+}
+
 func buildLiveHTTPServer(cfg brcfg.AppConfig, logs *database.DecisionLogStore, freqHandler livehttp.FreqtradeWebhookHandler, defaultSymbols []string, symbolDetails map[string]livehttp.SymbolDetail) (*livehttp.Server, error) {
 	if logs == nil && freqHandler == nil {
 		return nil, nil
@@ -57,9 +173,87 @@ func buildLiveHTTPServer(cfg brcfg.AppConfig, logs *database.DecisionLogStore, f
 	return server, nil
 }
 
+// buildTelegramBot 在开启 notify.telegram.commands 时创建命令监听；allowlist 缺省为推送 chat_id。
+func buildTelegramBot(cfg brcfg.TelegramConfig, client *notifier.Telegram, backend tgbot.Backend) *tgbot.Bot {
+	if !cfg.Commands || client == nil {
+		return nil
+	}
+	allowed := cfg.AllowedChatIDs
+	if len(allowed) == 0 {
+		allowed = []string{cfg.ChatID}
+	}
+	bot, err := tgbot.NewBot(client, backend, allowed, cfg.AllowedUserIDs)
+	if err != nil {
+		logger.Warnf("Telegram 命令监听未启用: %v", err)
+		return nil
+	}
+	return bot
+}
+
 func newTelegram(cfg brcfg.NotifyConfig) *notifier.Telegram {
 	if !cfg.Telegram.Enabled {
 		return nil
 	}
 	return notifier.NewTelegram(cfg.Telegram.BotToken, cfg.Telegram.ChatID)
 }
+
+// buildNotifier 在配置了 notify.sinks 时把 Telegram 与各推送通道组合为 Fanout，否则直接使用 Telegram；均未启用时返回 nil。
+func buildNotifier(cfg brcfg.NotifyConfig, tg *notifier.Telegram) (notifier.Notifier, error) {
+	if len(cfg.Sinks) == 0 {
+		if tg == nil {
+			return nil, nil
+		}
+		return tg, nil
+	}
+	sinks := make([]*notifier.WebhookSink, 0, len(cfg.Sinks))
+	for _, sc := range cfg.Sinks {
+		sink, err := notifier.NewWebhookSink(notifier.SinkConfig{
+			Name:       sc.Name,
+			Kind:       sc.Type,
+			URL:        sc.URL,
+			Categories: sc.Categories,
+			Templates:  sc.Templates,
+			Headers:    sc.Headers,
+			Timeout:    time.Duration(sc.TimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	var primary notifier.Notifier
+	if tg != nil {
+		primary = tg
+	}
+	logger.Infof("✓ 推送通道已启用: %d 个", len(sinks))
+	return notifier.NewFanout(primary, sinks), nil
+}
+
+func buildWebhooks(cfg brcfg.WebhooksConfig, log database.WebhookDeliveryLog) *webhook.Dispatcher {
+	if !cfg.Enabled {
+		return nil
+	}
+	endpoints := make([]webhook.Endpoint, 0, len(cfg.Endpoints))
+	for _, ep := range cfg.Endpoints {
+		endpoints = append(endpoints, webhook.Endpoint{
+			Name:   ep.Name,
+			URL:    ep.URL,
+			Secret: ep.Secret,
+			Events: ep.Events,
+		})
+	}
+	if log == nil {
+		logger.Warnf("webhook 投递记录未启用：缺少 live store")
+	}
+	d := webhook.New(webhook.Config{
+		Endpoints: endpoints,
+		Retries:   cfg.Retries,
+		Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+		QueueSize: cfg.QueueSize,
+		Log:       log,
+	})
+	if d != nil {
+		logger.Infof("✓ 信号 webhook 已启用 endpoints=%d", len(endpoints))
+	}
+	return d
+}
@@ -0,0 +1,530 @@
+// Package backtest 在历史 K 线上回放 profile 的中间件流水线，按量化入场规则开仓，
+// 并用实盘同一套退出计划 handler（分段止盈/止损）模拟平仓，输出逐笔盈亏、胜率与最大回撤。
+// 回测不调用 LLM：入场由 screening 规则表达式决定，profile 自身的 screening 闸门同样生效。
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/screen"
+	"brale/internal/config/loader"
+	"brale/internal/pipeline"
+	"brale/internal/pipeline/factory"
+	"brale/internal/pkg/trading"
+	"brale/internal/scheduler"
+	"brale/internal/strategy/exit"
+)
+
+const (
+	defaultStakeUSD      = 100
+	defaultInitialEquity = 1000
+	defaultWarmupBars    = 100
+)
+
+// EntryRules 为入场规则，语法与 profile screening 相同（如 "rsi <= 30"、"divergence == bullish"）；Mode 默认 all。
+type EntryRules struct {
+	Interval string   `json:"interval"`
+	Mode     string   `json:"mode"`
+	Long     []string `json:"long"`
+	Short    []string `json:"short"`
+}
+
+// TierRule 为一段退出：Move 为相对开仓价的距离（0.02 表示 2%），Ratio 为占初始仓位的比例。
+type TierRule struct {
+	Move  float64 `json:"move"`
+	Ratio float64 `json:"ratio"`
+}
+
+// ExitRules 转换为 tier_take_profit / tier_stop_loss 组合（tp_tiers__sl_tiers），各组比例和需为 1。
+type ExitRules struct {
+	TakeProfit []TierRule `json:"take_profit"`
+	StopLoss   []TierRule `json:"stop_loss"`
+}
+
+// Config 为单个 symbol 的回测参数；From/To 为零值时不限制决策区间。
+// FeeRate 为旧参数，Execution 未设置手续费时作为 maker/taker 统一费率。
+type Config struct {
+	Symbol        string
+	Profile       loader.ProfileDefinition
+	From          time.Time
+	To            time.Time
+	Entry         EntryRules
+	Exit          ExitRules
+	StakeUSD      float64
+	InitialEquity float64
+	FeeRate       float64
+	WarmupBars    int
+	Execution     trading.ExecutionModel
+}
+
+// Fill 为一次（部分）平仓；Price 为含滑点的成交价，TriggerPrice 为触发时的参考价。
+type Fill struct {
+	Time         time.Time `json:"time"`
+	Price        float64   `json:"price"`
+	TriggerPrice float64   `json:"trigger_price"`
+	Ratio        float64   `json:"ratio"`
+	Reason       string    `json:"reason"`
+	FeeUSD       float64   `json:"fee_usd"`
+	SlippageUSD  float64   `json:"slippage_usd"`
+}
+
+// Trade 为一笔模拟交易；PnLRatio 为扣除手续费后相对名义仓位的收益率，FeeUSD/SlippageUSD 含开平仓两侧。
+type Trade struct {
+	ID           int       `json:"id"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`
+	SignalTime   time.Time `json:"signal_time"`
+	EntryTime    time.Time `json:"entry_time"`
+	EntryPrice   float64   `json:"entry_price"`
+	ExitTime     time.Time `json:"exit_time"`
+	AvgExitPrice float64   `json:"avg_exit_price"`
+	Bars         int       `json:"bars"`
+	Fills        []Fill    `json:"fills"`
+	PnLUSD       float64   `json:"pnl_usd"`
+	PnLRatio     float64   `json:"pnl_ratio"`
+	FeeUSD       float64   `json:"fee_usd"`
+	SlippageUSD  float64   `json:"slippage_usd"`
+	entryFee     float64
+	entrySlip    float64
+}
+
+// Summary 为回测整体统计；回撤按逐笔平仓后的权益曲线计算。
+type Summary struct {
+	Trades           int     `json:"trades"`
+	Wins             int     `json:"wins"`
+	Losses           int     `json:"losses"`
+	WinRate          float64 `json:"win_rate"`
+	TotalPnLUSD      float64 `json:"total_pnl_usd"`
+	AvgPnLRatio      float64 `json:"avg_pnl_ratio"`
+	ProfitFactor     float64 `json:"profit_factor"`
+	MaxDrawdownUSD   float64 `json:"max_drawdown_usd"`
+	MaxDrawdownPct   float64 `json:"max_drawdown_pct"`
+	FinalEquity      float64 `json:"final_equity"`
+	TotalFeeUSD      float64 `json:"total_fee_usd"`
+	TotalSlippageUSD float64 `json:"total_slippage_usd"`
+}
+
+type Result struct {
+	Symbol   string    `json:"symbol"`
+	Profile  string    `json:"profile"`
+	Interval string    `json:"interval"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Bars     int       `json:"bars"`
+	Steps    int       `json:"steps"`
+	Signals  int       `json:"signals"`
+	Trades   []Trade   `json:"trades"`
+	Summary  Summary   `json:"summary"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+// Runner 持有回测共享依赖：历史 K 线来源、退出计划 handler 与 kline_fetcher 默认 limit。
+type Runner struct {
+	Source       CandleSource
+	Handlers     *exit.HandlerRegistry
+	DefaultLimit int
+}
+
+// Run 逐根推进最短周期 K 线：每 decision_interval_multiple 根在收盘时运行流水线并评估入场规则，
+// 信号在下一根开盘成交；持仓期间每根 K 线都交给退出计划评估。同一时间最多持有一笔仓位。
+func (r *Runner) Run(ctx context.Context, cfg Config) (Result, error) {
+	cfg.normalize()
+	res := Result{Symbol: cfg.Symbol, Profile: cfg.Profile.Name}
+	if r == nil || r.Source == nil {
+		return res, fmt.Errorf("backtest: K 线来源未配置")
+	}
+	if cfg.Symbol == "" {
+		return res, fmt.Errorf("backtest: symbol 必填")
+	}
+	if err := cfg.Exit.validate(); err != nil {
+		return res, err
+	}
+	if err := cfg.Execution.Validate(); err != nil {
+		return res, err
+	}
+	longGate, err := buildGate(cfg.Entry.Mode, cfg.Entry.Long)
+	if err != nil {
+		return res, err
+	}
+	shortGate, err := buildGate(cfg.Entry.Mode, cfg.Entry.Short)
+	if err != nil {
+		return res, err
+	}
+	if len(longGate.Rules) == 0 && len(shortGate.Rules) == 0 {
+		return res, fmt.Errorf("backtest: 需至少配置一条 long 或 short 入场规则")
+	}
+	screenGate, err := buildGate(cfg.Profile.Screening.Mode, screeningRules(cfg.Profile))
+	if err != nil {
+		return res, err
+	}
+
+	intervals := cfg.Profile.IntervalsLower()
+	if len(intervals) == 0 {
+		return res, fmt.Errorf("backtest: profile %s 未配置 intervals", cfg.Profile.Name)
+	}
+	base := shortestInterval(intervals)
+	res.Interval = base
+	baseDur, _ := scheduler.ParseIntervalDuration(base)
+	entryInterval := strings.ToLower(strings.TrimSpace(cfg.Entry.Interval))
+	if entryInterval == "" {
+		entryInterval = intervals[0]
+	}
+	screenInterval := strings.ToLower(strings.TrimSpace(cfg.Profile.Screening.Interval))
+	if screenInterval == "" {
+		screenInterval = intervals[0]
+	}
+
+	replay := newReplayExporter(cfg.Symbol)
+	for _, iv := range intervals {
+		candles, err := r.Source.Get(ctx, cfg.Symbol, iv)
+		if err != nil {
+			return res, fmt.Errorf("backtest: 读取 %s %s 失败: %w", cfg.Symbol, iv, err)
+		}
+		replay.load(iv, candles)
+	}
+	bars := replay.series(base)
+	if len(bars) <= cfg.WarmupBars {
+		return res, fmt.Errorf("backtest: %s %s 历史 K 线不足（%d 根，预热需 %d 根）", cfg.Symbol, base, len(bars), cfg.WarmupBars)
+	}
+	pipe, err := r.buildPipeline(cfg.Profile, replay)
+	if err != nil {
+		return res, err
+	}
+
+	multiple := cfg.Profile.DecisionIntervalMultiple
+	if multiple <= 0 {
+		multiple = 1
+	}
+	var (
+		pos       *simPosition
+		pending   string
+		signalAt  time.Time
+		wait      int
+		warnSeen  = make(map[string]bool)
+		nextTrade = 1
+	)
+	for i := cfg.WarmupBars; i < len(bars); i++ {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		bar := bars[i]
+		res.Bars++
+		start := bar.Open
+		if pending != "" && pos == nil {
+			if wait > 0 {
+				wait--
+			} else {
+				pos, err = openPosition(ctx, r.Handlers, cfg, pending, bar, baseDur, nextTrade)
+				if err != nil {
+					return res, fmt.Errorf("backtest: 开仓失败 %s: %w", candleTime(bar.OpenTime).Format(time.RFC3339), err)
+				}
+				pos.trade.SignalTime = signalAt
+				start = cfg.Execution.DelayedEntryRef(bar.Open, bar.Close, baseDur)
+				nextTrade++
+				pending = ""
+			}
+		}
+		if pos != nil {
+			closed, err := pos.onBar(ctx, bar, start)
+			if err != nil {
+				return res, err
+			}
+			if closed {
+				res.Trades = append(res.Trades, pos.finish(cfg))
+				pos = nil
+			}
+		}
+		barTime := candleTime(bar.CloseTime)
+		if pos != nil || pending != "" || (i-cfg.WarmupBars)%multiple != 0 || !cfg.inRange(barTime) || i == len(bars)-1 {
+			continue
+		}
+		if res.From.IsZero() {
+			res.From = barTime
+		}
+		res.To = barTime
+		res.Steps++
+		replay.seek(bar.CloseTime)
+		ac := pipeline.NewContext(cfg.Symbol)
+		ac.Profile = cfg.Profile.Name
+		if err := pipe.Run(ctx, ac); err != nil {
+			addWarning(&res, warnSeen, err.Error())
+			continue
+		}
+		for _, w := range ac.Warnings() {
+			addWarning(&res, warnSeen, w)
+		}
+		summary, ok := summarizeAt(ac, entryInterval, intervals, cfg.Profile)
+		if !ok {
+			continue
+		}
+		if cfg.Profile.Screening.Enabled {
+			gated, ok := summary, true
+			if screenInterval != entryInterval {
+				gated, ok = summarizeAt(ac, screenInterval, intervals, cfg.Profile)
+			}
+			if pass, _ := screenGate.Evaluate(gated); ok && !pass {
+				continue
+			}
+		}
+		pending = pickSide(longGate, shortGate, summary)
+		if pending != "" {
+			res.Signals++
+			signalAt = barTime
+			wait = cfg.Execution.EntryDelayBars
+		}
+	}
+	if pos != nil {
+		pos.closeAll(bars[len(bars)-1], "end_of_data")
+		res.Trades = append(res.Trades, pos.finish(cfg))
+	}
+	res.Summary = summarize(res.Trades, cfg.InitialEquity)
+	return res, nil
+}
+
+func (r *Runner) buildPipeline(def loader.ProfileDefinition, replay *replayExporter) (*pipeline.Pipeline, error) {
+	fac := &factory.Factory{Exporter: replay, DefaultLimit: r.DefaultLimit}
+	mws := make([]pipeline.Middleware, 0, len(def.Middlewares))
+	for _, mc := range def.Middlewares {
+		mw, err := fac.Build(mc, def)
+		if err != nil {
+			return nil, fmt.Errorf("backtest: 构建中间件 %s 失败: %w", mc.Name, err)
+		}
+		mws = append(mws, mw)
+	}
+	if len(mws) == 0 {
+		return nil, fmt.Errorf("backtest: profile %s 无可用中间件", def.Name)
+	}
+	mws, err := fac.Order(def, mws)
+	if err != nil {
+		return nil, fmt.Errorf("backtest: %w", err)
+	}
+	return pipeline.New("backtest:"+def.Name, mws...), nil
+}
+
+func (c *Config) normalize() {
+	c.Symbol = strings.ToUpper(strings.TrimSpace(c.Symbol))
+	if c.StakeUSD <= 0 {
+		c.StakeUSD = defaultStakeUSD
+	}
+	if c.InitialEquity <= 0 {
+		c.InitialEquity = defaultInitialEquity
+	}
+	if strings.TrimSpace(c.Entry.Mode) == "" {
+		c.Entry.Mode = screen.ModeAll
+	}
+	if c.FeeRate < 0 {
+		c.FeeRate = 0
+	}
+	c.Execution.Normalize()
+	if c.Execution.MakerFeeRate == 0 && c.Execution.TakerFeeRate == 0 {
+		c.Execution.MakerFeeRate = c.FeeRate
+		c.Execution.TakerFeeRate = c.FeeRate
+	}
+	if c.WarmupBars <= 0 {
+		c.WarmupBars = c.Profile.AnalysisSlice
+	}
+	if c.WarmupBars <= 0 {
+		c.WarmupBars = defaultWarmupBars
+	}
+}
+
+func (c Config) inRange(t time.Time) bool {
+	if !c.From.IsZero() && t.Before(c.From) {
+		return false
+	}
+	if !c.To.IsZero() && !t.Before(c.To) {
+		return false
+	}
+	return true
+}
+
+func (e ExitRules) validate() error {
+	if len(e.TakeProfit) == 0 && len(e.StopLoss) == 0 {
+		return fmt.Errorf("backtest: exit 需至少配置 take_profit 或 stop_loss")
+	}
+	for name, tiers := range map[string][]TierRule{"take_profit": e.TakeProfit, "stop_loss": e.StopLoss} {
+		for i, t := range tiers {
+			if t.Move <= 0 || t.Move >= 1 {
+				return fmt.Errorf("backtest: %s#%d move 需位于 (0,1)", name, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// planSpec 按开仓价把相对距离换算为目标价，生成与实盘相同结构的 combo_group 参数。
+func (e ExitRules) planSpec(side string, entry float64) map[string]any {
+	sign := 1.0
+	if side == "short" {
+		sign = -1
+	}
+	tiers := func(rules []TierRule, dir float64) []any {
+		out := make([]any, 0, len(rules))
+		for _, t := range rules {
+			out = append(out, map[string]any{
+				"target_price": entry * (1 + dir*sign*t.Move),
+				"ratio":        t.Ratio,
+			})
+		}
+		return out
+	}
+	var children []any
+	if len(e.TakeProfit) > 0 {
+		children = append(children, map[string]any{
+			"component": "tp_tiers",
+			"handler":   "tier_take_profit",
+			"params":    map[string]any{"tiers": tiers(e.TakeProfit, 1)},
+		})
+	}
+	if len(e.StopLoss) > 0 {
+		children = append(children, map[string]any{
+			"component": "sl_tiers",
+			"handler":   "tier_stop_loss",
+			"params":    map[string]any{"tiers": tiers(e.StopLoss, -1)},
+		})
+	}
+	return map[string]any{"children": children}
+}
+
+// finish 按含滑点的成交价汇总盈亏，并扣除开仓与各次平仓的手续费。
+func (p *simPosition) finish(cfg Config) Trade {
+	t := *p.trade
+	sign := 1.0
+	if t.Side == "short" {
+		sign = -1
+	}
+	pnl := -t.entryFee
+	t.FeeUSD, t.SlippageUSD = t.entryFee, t.entrySlip
+	weighted, filled := 0.0, 0.0
+	for _, f := range t.Fills {
+		notional := cfg.StakeUSD * f.Ratio
+		pnl += notional*sign*(f.Price-t.EntryPrice)/t.EntryPrice - f.FeeUSD
+		t.FeeUSD += f.FeeUSD
+		t.SlippageUSD += f.SlippageUSD
+		weighted += f.Price * f.Ratio
+		filled += f.Ratio
+	}
+	if filled > 0 {
+		t.AvgExitPrice = weighted / filled
+	}
+	t.PnLUSD = pnl
+	t.PnLRatio = pnl / cfg.StakeUSD
+	return t
+}
+
+func summarize(trades []Trade, initialEquity float64) Summary {
+	sum := Summary{Trades: len(trades), FinalEquity: initialEquity}
+	if len(trades) == 0 {
+		return sum
+	}
+	ordered := append([]Trade(nil), trades...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].ExitTime.Before(ordered[j].ExitTime) })
+	equity, peak := initialEquity, initialEquity
+	grossWin, grossLoss, ratioSum := 0.0, 0.0, 0.0
+	for _, t := range ordered {
+		if t.PnLUSD > 0 {
+			sum.Wins++
+			grossWin += t.PnLUSD
+		} else {
+			sum.Losses++
+			grossLoss -= t.PnLUSD
+		}
+		ratioSum += t.PnLRatio
+		sum.TotalFeeUSD += t.FeeUSD
+		sum.TotalSlippageUSD += t.SlippageUSD
+		equity += t.PnLUSD
+		peak = math.Max(peak, equity)
+		if dd := peak - equity; dd > sum.MaxDrawdownUSD {
+			sum.MaxDrawdownUSD = dd
+			if peak > 0 {
+				sum.MaxDrawdownPct = dd / peak
+			}
+		}
+	}
+	sum.TotalPnLUSD = equity - initialEquity
+	sum.FinalEquity = equity
+	sum.WinRate = float64(sum.Wins) / float64(sum.Trades)
+	sum.AvgPnLRatio = ratioSum / float64(sum.Trades)
+	if grossLoss > 0 {
+		sum.ProfitFactor = grossWin / grossLoss
+	}
+	return sum
+}
+
+// summarizeAt 与实盘 screening 相同：趋势复用 ema_trend 结论，score 取多周期合成分。
+func summarizeAt(ac *pipeline.AnalysisContext, interval string, intervals []string, def loader.ProfileDefinition) (screen.Summary, bool) {
+	candles := ac.Candles(interval)
+	if len(candles) == 0 {
+		return screen.Summary{}, false
+	}
+	scoring := screen.DivergenceScoring(def.DivergenceScoring)
+	summary := screen.SummarizeWith(candles, screen.TrendFromFeatures(ac.Features(), interval), scoring)
+	summary.Score = screen.CompositeFromCandles(intervals, ac.Candles, def.Composite.Weights, scoring).Score
+	return summary, true
+}
+
+func buildGate(mode string, exprs []string) (screen.Gate, error) {
+	gate := screen.Gate{Mode: strings.ToLower(strings.TrimSpace(mode))}
+	for _, expr := range exprs {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		rule, err := screen.ParseRule(expr)
+		if err != nil {
+			return gate, fmt.Errorf("backtest: %w", err)
+		}
+		gate.Rules = append(gate.Rules, rule)
+	}
+	return gate, nil
+}
+
+func screeningRules(def loader.ProfileDefinition) []string {
+	if !def.Screening.Enabled {
+		return nil
+	}
+	return def.Screening.Rules
+}
+
+// pickSide 多空规则同时满足时视为信号冲突，本轮不开仓。
+func pickSide(long, short screen.Gate, s screen.Summary) string {
+	longOK := len(long.Rules) > 0
+	if longOK {
+		longOK, _ = long.Evaluate(s)
+	}
+	shortOK := len(short.Rules) > 0
+	if shortOK {
+		shortOK, _ = short.Evaluate(s)
+	}
+	switch {
+	case longOK && !shortOK:
+		return "long"
+	case shortOK && !longOK:
+		return "short"
+	default:
+		return ""
+	}
+}
+
+func shortestInterval(intervals []string) string {
+	best := intervals[0]
+	bestDur := time.Duration(math.MaxInt64)
+	for _, iv := range intervals {
+		if d, ok := scheduler.ParseIntervalDuration(iv); ok && d < bestDur {
+			best, bestDur = iv, d
+		}
+	}
+	return best
+}
+
+func addWarning(res *Result, seen map[string]bool, msg string) {
+	if msg == "" || seen[msg] || len(res.Warnings) >= 20 {
+		return
+	}
+	seen[msg] = true
+	res.Warnings = append(res.Warnings, msg)
+}
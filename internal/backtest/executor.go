@@ -0,0 +1,219 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/gateway/database"
+	"brale/internal/market"
+	"brale/internal/pkg/trading"
+	"brale/internal/strategy/exit"
+)
+
+const (
+	ratioTolerance = 1e-6
+	comboHandlerID = "combo_group"
+	backtestPlanID = "plan_combo_main"
+)
+
+// simPosition 用真实的退出计划 handler 驱动持仓，事件处理方式与实盘 PlanExecutor 一致，
+// 只是平仓不经过执行器，而是按 ExecutionModel 在触发价上施加滑点与手续费后成交。
+type simPosition struct {
+	trade     *Trade
+	handler   exit.PlanHandler
+	root      *exit.PlanInstance
+	comps     []*exit.PlanInstance
+	remaining float64
+	exec      trading.ExecutionModel
+	stake     float64
+}
+
+// openPosition 在 bar 内按执行模型成交（延迟插值 + 滑点），退出计划目标价以实际成交价为基准，与实盘一致。
+func openPosition(ctx context.Context, handlers *exit.HandlerRegistry, cfg Config, side string, bar market.Candle, barDur time.Duration, tradeID int) (*simPosition, error) {
+	if handlers == nil {
+		return nil, fmt.Errorf("backtest: exit handler registry 未初始化")
+	}
+	handler, ok := handlers.Handler(comboHandlerID)
+	if !ok || handler == nil {
+		return nil, fmt.Errorf("backtest: handler 未注册 %s", comboHandlerID)
+	}
+	symbol := cfg.Symbol
+	ref := cfg.Execution.DelayedEntryRef(bar.Open, bar.Close, barDur)
+	entry := cfg.Execution.FillPrice(ref, side != "short", cfg.StakeUSD)
+	spec := cfg.Exit.planSpec(side, entry)
+	insts, err := handler.Instantiate(ctx, exit.InstantiateArgs{
+		TradeID:    tradeID,
+		PlanID:     backtestPlanID,
+		PlanSpec:   spec,
+		Decision:   decision.Decision{Symbol: symbol, Action: "open_" + side},
+		EntryPrice: entry,
+		Side:       side,
+		Symbol:     symbol,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pos := &simPosition{
+		trade: &Trade{
+			ID:         tradeID,
+			Symbol:     symbol,
+			Side:       side,
+			EntryTime:  candleTime(bar.OpenTime).Add(time.Duration(cfg.Execution.EntryDelaySeconds) * time.Second),
+			EntryPrice: entry,
+			entryFee:   cfg.Execution.Fee(cfg.StakeUSD, false),
+			entrySlip:  math.Abs(entry-ref) / ref * cfg.StakeUSD,
+		},
+		handler:   handler,
+		remaining: 1,
+		exec:      cfg.Execution,
+		stake:     cfg.StakeUSD,
+	}
+	for i := range insts {
+		inst := insts[i]
+		if strings.TrimSpace(inst.Record.PlanComponent) == "" {
+			pos.root = &inst
+			continue
+		}
+		pos.comps = append(pos.comps, &inst)
+	}
+	sort.Slice(pos.comps, func(i, j int) bool { return pos.comps[i].Record.PlanComponent < pos.comps[j].Record.PlanComponent })
+	return pos, nil
+}
+
+// onBar 按 start → 不利极值 → 有利极值 → close 的路径推进价格（同根 K 线内先假设触发止损，偏保守）。
+// start 通常为开盘价，开仓所在 K 线为延迟成交的参考价；起点即越过目标位时按起点价成交（跳空），其余按目标价成交。
+func (p *simPosition) onBar(ctx context.Context, bar market.Candle, start float64) (bool, error) {
+	p.trade.Bars++
+	adverse, favorable := bar.Low, bar.High
+	if p.trade.Side == "short" {
+		adverse, favorable = bar.High, bar.Low
+	}
+	path := []float64{start, adverse, favorable, bar.Close}
+	for i, price := range path {
+		closed, err := p.onPrice(ctx, price, i == 0, bar.CloseTime)
+		if err != nil || closed {
+			return closed, err
+		}
+	}
+	return false, nil
+}
+
+func (p *simPosition) onPrice(ctx context.Context, price float64, gap bool, ts int64) (bool, error) {
+	insts := make([]*exit.PlanInstance, 0, len(p.comps)+1)
+	if p.root != nil {
+		insts = append(insts, p.root)
+	}
+	insts = append(insts, p.comps...)
+	for _, inst := range insts {
+		if inst.Record.Status == database.StrategyStatusDone {
+			continue
+		}
+		evt, err := p.handler.OnPrice(ctx, *inst, price)
+		if err != nil {
+			return false, fmt.Errorf("plan %s component=%s: %w", inst.Record.PlanID, inst.Record.PlanComponent, err)
+		}
+		if evt == nil {
+			continue
+		}
+		switch evt.Type {
+		case exit.PlanEventTypeTierHit:
+			ratio, _ := detailFloat(evt.Details, "ratio")
+			fill := fillPrice(evt.Details, "target_price", price, gap)
+			if err := markTierDone(inst); err != nil {
+				return false, err
+			}
+			maker := p.exec.TakeProfitMaker && !gap && inst.Record.PlanComponent == "tp_tiers"
+			p.fill(math.Min(ratio, p.remaining), fill, ts, inst.Record.PlanComponent, maker)
+		case exit.PlanEventTypeStopLoss, exit.PlanEventTypeTakeProfit,
+			exit.PlanEventTypeFinalStopLoss, exit.PlanEventTypeFinalTakeProfit:
+			inst.Record.Status = database.StrategyStatusDone
+			p.fill(p.remaining, fillPrice(evt.Details, "target", price, gap), ts, evt.Type, false)
+		case exit.PlanEventTypeAdjust:
+			if raw, _ := evt.Details["state_json"].(string); strings.TrimSpace(raw) != "" {
+				inst.Record.StateJSON = raw
+			}
+			continue
+		default:
+			continue
+		}
+		if p.remaining <= ratioTolerance {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// closeAll 在数据结束时按收盘价平掉剩余仓位。
+func (p *simPosition) closeAll(bar market.Candle, reason string) {
+	if p.remaining > ratioTolerance {
+		p.fill(p.remaining, bar.Close, bar.CloseTime, reason, false)
+	}
+}
+
+// fill 记录一次平仓：maker 为挂单止盈，按触发价成交且适用 maker 费率；其余视为市价单，施加滑点。
+func (p *simPosition) fill(ratio, trigger float64, ts int64, reason string, maker bool) {
+	if ratio <= 0 || trigger <= 0 {
+		return
+	}
+	p.remaining -= ratio
+	if p.remaining < ratioTolerance {
+		p.remaining = 0
+	}
+	notional := p.stake * ratio
+	price := trigger
+	if !maker {
+		price = p.exec.FillPrice(trigger, p.trade.Side == "short", notional)
+	}
+	p.trade.Fills = append(p.trade.Fills, Fill{
+		Time:         candleTime(ts),
+		Price:        price,
+		TriggerPrice: trigger,
+		Ratio:        ratio,
+		Reason:       reason,
+		FeeUSD:       p.exec.Fee(notional, maker),
+		SlippageUSD:  math.Abs(price-trigger) / trigger * notional,
+	})
+	p.trade.ExitTime = candleTime(ts)
+}
+
+func markTierDone(inst *exit.PlanInstance) error {
+	state, err := exit.DecodeTierComponentState(inst.Record.StateJSON)
+	if err != nil {
+		return fmt.Errorf("解析组件 %s 状态失败: %w", inst.Record.PlanComponent, err)
+	}
+	state.Status = "done"
+	state.ExecutedRatio = state.Ratio
+	state.RemainingRatio = 0
+	state.LastEvent = exit.PlanEventTypeTierHit
+	inst.Record.StateJSON = exit.EncodeTierComponentState(state)
+	inst.Record.Status = database.StrategyStatusDone
+	return nil
+}
+
+func fillPrice(details map[string]any, key string, price float64, gap bool) float64 {
+	if gap {
+		return price
+	}
+	if target, ok := detailFloat(details, key); ok && target > 0 {
+		return target
+	}
+	return price
+}
+
+func detailFloat(details map[string]any, key string) (float64, bool) {
+	switch v := details[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,79 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/market"
+	"brale/internal/scheduler"
+)
+
+// CandleSource 提供完整历史 K 线；market.KlineStore 即满足该接口。
+type CandleSource interface {
+	Get(ctx context.Context, symbol, interval string) ([]market.Candle, error)
+}
+
+// replayExporter 以游标时间截断历史数据，只暴露在 now 之前已收盘的 K 线，供 kline_fetcher 中间件读取。
+type replayExporter struct {
+	symbol  string
+	mu      sync.RWMutex
+	now     int64
+	candles map[string][]market.Candle
+}
+
+func newReplayExporter(symbol string) *replayExporter {
+	return &replayExporter{symbol: strings.ToUpper(strings.TrimSpace(symbol)), candles: make(map[string][]market.Candle)}
+}
+
+func (r *replayExporter) load(interval string, candles []market.Candle) {
+	iv := strings.ToLower(strings.TrimSpace(interval))
+	dur, _ := scheduler.ParseIntervalDuration(iv)
+	out := make([]market.Candle, 0, len(candles))
+	for _, c := range candles {
+		if c.CloseTime == 0 && dur > 0 {
+			c.CloseTime = c.OpenTime + dur.Milliseconds() - 1
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].OpenTime < out[j].OpenTime })
+	r.candles[iv] = out
+}
+
+func (r *replayExporter) seek(now int64) {
+	r.mu.Lock()
+	r.now = now
+	r.mu.Unlock()
+}
+
+func (r *replayExporter) series(interval string) []market.Candle {
+	return r.candles[strings.ToLower(strings.TrimSpace(interval))]
+}
+
+func (r *replayExporter) Export(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error) {
+	if !strings.EqualFold(strings.TrimSpace(symbol), r.symbol) {
+		return nil, fmt.Errorf("backtest: 仅加载了 %s", r.symbol)
+	}
+	r.mu.RLock()
+	now := r.now
+	r.mu.RUnlock()
+	all := r.series(interval)
+	end := sort.Search(len(all), func(i int) bool { return all[i].CloseTime > now })
+	start := 0
+	if limit > 0 && end > limit {
+		start = end - limit
+	}
+	out := make([]market.Candle, end-start)
+	copy(out, all[start:end])
+	return out, nil
+}
+
+func candleTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
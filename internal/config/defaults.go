@@ -26,11 +26,39 @@ const (
 	// 默认: "/data/logs/brale-llm.log"
 	// 重置: app.llm_log_path
 	defaultAppLLMLogPath = "/data/logs/brale-llm.log"
+	// 运行模式 (active/standby)
+	// 默认: "active"
+	// 重置: app.mode
+	defaultAppMode = AppModeActive
 
 	// K线数据最大缓存数量
 	// 默认: 300
 	// 重置: kline.max_cached
 	defaultKlineMaxCached = 300
+	// K线校验：单根收益率超过近期标准差的倍数视为极端波动
+	// 默认: 8
+	// 重置: kline.validation.max_sigma
+	defaultKlineMaxSigma = 8.0
+	// K线校验：计算标准差使用的 K 线根数
+	// 默认: 100
+	// 重置: kline.validation.sigma_window
+	defaultKlineSigmaWindow = 100
+	// K线落盘：快照目录
+	// 默认: "/data/kline_cache"
+	// 重置: kline.persist.dir
+	defaultKlinePersistDir = "/data/kline_cache"
+	// K线落盘：快照有效期（小时），超过后启动时丢弃
+	// 默认: 24
+	// 重置: kline.persist.ttl_hours
+	defaultKlinePersistTTLHours = 24
+	// K线落盘：快照目录总大小上限（MB）
+	// 默认: 200
+	// 重置: kline.persist.max_total_mb
+	defaultKlinePersistMaxMB = 200
+	// K线落盘：定期写回间隔（分钟）
+	// 默认: 15
+	// 重置: kline.persist.flush_minutes
+	defaultKlinePersistFlush = 15
 
 	// 默认市场交易所名称
 	// 默认: "binance"
@@ -41,6 +69,10 @@ const (
 	// 重置: market.sources[0].rest_base_url (当配置为空时)
 	defaultMarketREST = "https://fapi.binance.com"
 	defaultGateREST   = "https://api.gateio.ws/api/v4"
+	// 单条组合 WS 连接的最大 stream 数（Binance 限制 200）
+	// 默认: 200
+	// 重置: market.max_streams
+	defaultMarketMaxStreams = 200
 
 	// AI 决策聚合策略 (meta/first)
 	// 默认: "meta" (多模型投票)
@@ -50,11 +82,44 @@ const (
 	// 默认: "/data/live/decisions.db"
 	// 重置: ai.decision_log_path
 	defaultAIDecisionLog = "/data/live/decisions.db"
+	// 决策日志 artifact 目录（backend=fs）
+	// 默认: "/data/live/artifacts"
+	// 重置: ai.artifacts.dir
+	defaultArtifactDir = "/data/live/artifacts"
+	// 超过该字节数的字段才写入 artifact 存储
+	// 默认: 2048
+	// 重置: ai.artifacts.min_bytes
+	defaultArtifactMinBytes = 2048
 	// 决策执行偏移时间（秒），防止整点并发
 	// 默认: 10
 	// 重置: ai.decision_offset_seconds
 	defaultAIDecisionOffset = 10
 
+	// 单轮决策总时限（秒），覆盖多 Agent 与最终模型调用及其重试；负数表示不设时限
+	// 默认: 600
+	// 重置: ai.decision_timeout_seconds
+	defaultAIDecisionTimeout = 600
+	// prompt 中附带的同币种历史决策条数
+	// 默认: 5
+	// 重置: ai.decision_history.limit
+	defaultDecisionHistoryLimit = 5
+	// 历史决策回看窗口（小时）
+	// 默认: 72
+	// 重置: ai.decision_history.lookback_hours
+	defaultDecisionHistoryLookback = 72
+	// 历史决策段落字符上限
+	// 默认: 1200
+	// 重置: ai.decision_history.max_chars
+	defaultDecisionHistoryMaxChars = 1200
+	// profile 连续无成功决策的告警轮次
+	// 默认: 3
+	// 重置: ai.stale_profile_cycles
+	defaultStaleProfileCycles = 3
+	// 行情陈旧阈值（周期时长的倍数）
+	// 默认: 2
+	// 重置: ai.stale_data.max_age_multiple
+	defaultStaleDataMaxAgeMultiple = 2.0
+
 	// MCP 服务超时时间（秒）
 	// 默认: 300
 	// 重置: mcp.timeout_seconds
@@ -84,6 +149,36 @@ const (
 	// 默认: "/data/db/trade_risk.db"
 	// 重置: freqtrade.risk_store_path
 	defaultFreqtradeRiskDB = "/data/db/trade_risk.db"
+	// 平仓卡单巡检超时（分钟），closing_* 状态超过该时长未收到 exit_fill 即对账
+	// 默认: 15
+	// 重置: freqtrade.stuck_closing_minutes
+	defaultFreqtradeStuckClosing = 15
+	// Freqtrade 交易模式：futures / spot / margin
+	// 默认: "futures"
+	// 重置: freqtrade.trading_mode
+	defaultFreqtradeTradingMode = TradingModeFutures
+
+	// 直连执行器计价币种
+	// 默认: "USDT"
+	// 重置: execution.binance.stake_currency
+	defaultExecStakeCurrency = "USDT"
+	// 直连执行器 REST 超时时间（秒）
+	// 默认: 15
+	// 重置: execution.binance.timeout_seconds
+	defaultExecTimeout = 15
+
+	// webhook 投递失败后的重试次数
+	// 默认: 3
+	// 重置: notify.webhooks.retries
+	defaultWebhookRetries = 3
+	// webhook 单次投递超时（秒）
+	// 默认: 10
+	// 重置: notify.webhooks.timeout_seconds
+	defaultWebhookTimeout = 10
+	// webhook 待投递事件队列长度，队列满时丢弃新事件
+	// 默认: 256
+	// 重置: notify.webhooks.queue_size
+	defaultWebhookQueueSize = 256
 
 	// 高级配置：最小流动性过滤 (百万 USD)
 	// 默认: 15
@@ -109,6 +204,151 @@ const (
 	// 默认: 1
 	// 重置: advanced.visual_render_concurrency
 	defaultAdvancedVisualRender = 1
+	// 高级配置：每轮分析并发执行的 symbol 管道数上限
+	// 默认: 4
+	// 重置: advanced.pipeline_concurrency
+	defaultAdvancedPipelineConcurrency = 4
+	// 高级配置：触发价交叉校验参考源 (index/行情源名称)
+	// 默认: "index"
+	// 重置: advanced.price_guard.reference
+	defaultPriceGuardReference = "index"
+	// 高级配置：触发价与参考价允许的最大偏离 (0.005 = 0.5%)
+	// 默认: 0.005
+	// 重置: advanced.price_guard.tolerance_pct
+	defaultPriceGuardTolerance = 0.005
+	// 高级配置：参考价查询超时（秒）
+	// 默认: 3
+	// 重置: advanced.price_guard.timeout_seconds
+	defaultPriceGuardTimeout = 3
+	// 高级配置：触发价共识规则 (tolerance/both/median)
+	// 默认: "tolerance"
+	// 重置: advanced.price_guard.consensus
+	defaultPriceGuardConsensus = "tolerance"
+	// 高级配置：参考价缓存（实时成交价或后台轮询）最大可用时长（秒），超过后回退 REST 查询
+	// 默认: 10
+	// 重置: advanced.price_guard.stream_max_age_seconds
+	defaultPriceGuardStreamMaxAge = 10
+	// 高级配置：双源价格偏离告警的冷却时间（分钟）
+	// 默认: 15
+	// 重置: advanced.price_guard.divergence_cooldown_minutes
+	defaultPriceGuardDivergenceCooldown = 15
+	// 高级配置：交易规则（exchangeInfo）刷新间隔（分钟）
+	// 默认: 60
+	// 重置: advanced.symbol_rules.refresh_minutes
+	defaultSymbolRulesRefreshMinutes = 60
+	// 高级配置：波动熔断计算已实现波动率的 K 线周期
+	// 默认: 5m
+	// 重置: advanced.volatility_breaker.interval
+	defaultVolBreakerInterval = "5m"
+	// 高级配置：波动熔断计算已实现波动率的 K 线根数
+	// 默认: 12
+	// 重置: advanced.volatility_breaker.window
+	defaultVolBreakerWindow = 12
+	// 高级配置：单根 K 线对数收益率标准差上限 (0.01 = 1%)
+	// 默认: 0.01
+	// 重置: advanced.volatility_breaker.max_vol_pct
+	defaultVolBreakerMaxVol = 0.01
+	// 高级配置：波动超限 symbol 占比达到该值视为全市场极端波动
+	// 默认: 0.5
+	// 重置: advanced.volatility_breaker.market_wide_ratio
+	defaultVolBreakerRatio = 0.5
+	// 高级配置：熔断解除前需持续恢复正常的时长（分钟）
+	// 默认: 30
+	// 重置: advanced.volatility_breaker.resume_minutes
+	defaultVolBreakerResume = 30
+	// 高级配置：波动熔断检查间隔（秒）
+	// 默认: 60
+	// 重置: advanced.volatility_breaker.check_seconds
+	defaultVolBreakerCheck = 60
+	// 高级配置：相关性计算使用的 K 线周期
+	// 默认: 1h
+	// 重置: advanced.correlation.interval
+	defaultCorrelationInterval = "1h"
+	// 高级配置：相关性计算的 K 线根数（1h*168 = 7 天）
+	// 默认: 168
+	// 重置: advanced.correlation.lookback
+	defaultCorrelationLookback = 168
+	// 高级配置：与同方向持仓的相关系数超过该值时触发闸门
+	// 默认: 0.8
+	// 重置: advanced.correlation.threshold
+	defaultCorrelationThreshold = 0.8
+	// 高级配置：触发相关性闸门时的处理方式（block / downsize）
+	// 默认: block
+	// 重置: advanced.correlation.action
+	defaultCorrelationAction = "block"
+	// 高级配置：action=downsize 时保证金的缩放比例
+	// 默认: 0.5
+	// 重置: advanced.correlation.downsize_ratio
+	defaultCorrelationDownsize = 0.5
+	// 高级配置：相关矩阵刷新间隔（分钟）
+	// 默认: 60
+	// 重置: advanced.correlation.refresh_minutes
+	defaultCorrelationRefresh = 60
+	// 高级配置：交割合约到期前停止开仓的时长（小时）
+	// 默认: 48
+	// 重置: advanced.contract_calendar.entry_cutoff_hours
+	defaultContractEntryCutoffHours = 48
+	// 高级配置：交割合约到期前强制平仓的时长（小时）
+	// 默认: 6
+	// 重置: advanced.contract_calendar.close_before_hours
+	defaultContractCloseBeforeHours = 6
+	// 高级配置：止盈第一段成交后止损的锁定方式（breakeven / atr）
+	// 默认: breakeven
+	// 重置: advanced.trailing_stop.lock_mode
+	defaultTrailingLockMode = "breakeven"
+	// 高级配置：lock_mode=atr 时锁定在 entry 之外的 ATR 倍数
+	// 默认: 0.5
+	// 重置: advanced.trailing_stop.lock_atr_multiplier
+	defaultTrailingLockATR = 0.5
+	// 高级配置：移动止损与现价之间保持的 ATR 倍数
+	// 默认: 1.5
+	// 重置: advanced.trailing_stop.trail_atr_multiplier
+	defaultTrailingTrailATR = 1.5
+	// 高级配置：止损单次上移的最小幅度（相对现止损价）
+	// 默认: 0.001
+	// 重置: advanced.trailing_stop.min_step_pct
+	defaultTrailingMinStep = 0.001
+	// 高级配置：保本+止损相对开仓价的手续费缓冲
+	// 默认: 0.001
+	// 重置: advanced.auto_breakeven.fee_buffer_pct
+	defaultAutoBreakevenFeeBuffer = 0.001
+	// 高级配置：每笔交易最多加仓档数
+	// 默认: 3
+	// 重置: advanced.scale_in.max_entries
+	defaultScaleInMaxEntries = 3
+	// 高级配置：漂移超限时的处理方式（abort / resize）
+	// 默认: abort
+	// 重置: advanced.entry_drift.action
+	defaultEntryDriftAction = "abort"
+	// 高级配置：action=resize 时保证金的缩放比例
+	// 默认: 0.5
+	// 重置: advanced.entry_drift.resize_ratio
+	defaultEntryDriftResize = 0.5
+	// 高级配置：sense 快照到下单的耗时上限（秒，含模型决策耗时）
+	// 默认: 120
+	// 重置: advanced.entry_drift.max_latency_seconds
+	defaultEntryDriftMaxLatency = 120
+
+	// 归档清理执行间隔（分钟）
+	// 默认: 60
+	// 重置: store.retention.interval_minutes
+	defaultRetentionInterval = 60
+	// 归档文件目录
+	// 默认: "/data/archive"
+	// 重置: store.retention.archive_dir
+	defaultRetentionArchiveDir = "/data/archive"
+	// 归档文件格式 (jsonl/csv)
+	// 默认: "jsonl"
+	// 重置: store.retention.format
+	defaultRetentionFormat = "jsonl"
+	// 单批归档/删除的记录数
+	// 默认: 500
+	// 重置: store.retention.batch_size
+	defaultRetentionBatch = 500
+	// 各表默认保留天数
+	// 默认: 90
+	// 重置: store.retention.tables
+	defaultRetentionDays = 90
 
 	// 交易模式 (static/dynamic)
 	// 默认: "static"
@@ -127,6 +367,10 @@ const (
 	// 默认: "configs/profiles.yaml"
 	// 重置: ai.profiles_path
 	defaultProfilesPath = "configs/profiles.yaml"
+	// 软删除 profile 在回收区保留的天数，过期后永久删除
+	// 默认: 30
+	// 重置: ai.profile_trash_days
+	defaultProfileTrashDays = 30
 	// 退出策略配置文件路径
 	// 默认: "configs/exit_strategies.yaml"
 	// 重置: ai.exit_strategies_path
@@ -138,10 +382,12 @@ func (c *Config) applyDefaults(keys keySet) {
 	c.Kline.applyDefaults(keys)
 	c.Prompt.applyDefaults(keys)
 	c.MCP.applyDefaults(keys)
+	c.Notify.applyDefaults(keys)
 	c.Market.applyDefaults(keys)
 	c.AI.applyDefaults(keys)
 	c.Store.applyDefaults(keys)
 	c.Freqtrade.applyDefaults(keys)
+	c.Execution.applyDefaults(keys)
 	c.Advanced.applyDefaults(keys)
 	c.Trading.applyDefaults(keys)
 }
@@ -156,6 +402,7 @@ func (a *AppConfig) applyDefaults(keys keySet) {
 		stringFieldDefault("app.http_addr", &a.HTTPAddr, defaultAppHTTPAddr),
 		stringFieldDefault("app.log_path", &a.LogPath, defaultAppLogPath),
 		stringFieldDefault("app.llm_log_path", &a.LLMLog, defaultAppLLMLogPath),
+		stringFieldDefault("app.mode", &a.Mode, defaultAppMode),
 	)
 }
 
@@ -169,6 +416,32 @@ func (k *KlineConfig) applyDefaults(keys keySet) {
 			need:  func() bool { return k.MaxCached <= 0 },
 			apply: func() { k.MaxCached = defaultKlineMaxCached },
 		},
+		fieldDefault{
+			key:   "kline.validation.max_sigma",
+			need:  func() bool { return k.Validation.MaxSigma <= 0 },
+			apply: func() { k.Validation.MaxSigma = defaultKlineMaxSigma },
+		},
+		fieldDefault{
+			key:   "kline.validation.sigma_window",
+			need:  func() bool { return k.Validation.SigmaWindow <= 0 },
+			apply: func() { k.Validation.SigmaWindow = defaultKlineSigmaWindow },
+		},
+		stringFieldDefault("kline.persist.dir", &k.Persist.Dir, defaultKlinePersistDir),
+		fieldDefault{
+			key:   "kline.persist.ttl_hours",
+			need:  func() bool { return k.Persist.TTLHours <= 0 },
+			apply: func() { k.Persist.TTLHours = defaultKlinePersistTTLHours },
+		},
+		fieldDefault{
+			key:   "kline.persist.max_total_mb",
+			need:  func() bool { return k.Persist.MaxTotalMB <= 0 },
+			apply: func() { k.Persist.MaxTotalMB = defaultKlinePersistMaxMB },
+		},
+		fieldDefault{
+			key:   "kline.persist.flush_minutes",
+			need:  func() bool { return k.Persist.FlushMinutes <= 0 },
+			apply: func() { k.Persist.FlushMinutes = defaultKlinePersistFlush },
+		},
 	)
 }
 
@@ -177,8 +450,43 @@ func (s *StoreConfig) applyDefaults(keys keySet) {
 		return
 	}
 	applyFieldDefaults(keys,
+		stringFieldDefault("store.driver", &s.Driver, StoreDriverSQLite),
 		stringFieldDefault("store.live_db_path", &s.LiveDBPath, ""),
 	)
+	s.Driver = strings.ToLower(strings.TrimSpace(s.Driver))
+	s.Retention.applyDefaults(keys)
+}
+
+func (r *RetentionConfig) applyDefaults(keys keySet) {
+	if r == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		stringFieldDefault("store.retention.archive_dir", &r.ArchiveDir, defaultRetentionArchiveDir),
+		stringFieldDefault("store.retention.format", &r.Format, defaultRetentionFormat),
+		fieldDefault{
+			key:   "store.retention.interval_minutes",
+			need:  func() bool { return r.IntervalMinutes <= 0 },
+			apply: func() { r.IntervalMinutes = defaultRetentionInterval },
+		},
+		fieldDefault{
+			key:   "store.retention.batch_size",
+			need:  func() bool { return r.BatchSize <= 0 },
+			apply: func() { r.BatchSize = defaultRetentionBatch },
+		},
+		fieldDefault{
+			key:  "store.retention.tables",
+			need: func() bool { return len(r.Tables) == 0 },
+			apply: func() {
+				r.Tables = make(map[string]int, len(RetentionTables))
+				for _, name := range RetentionTables {
+					r.Tables[name] = defaultRetentionDays
+				}
+			},
+		},
+	)
+	r.Format = strings.ToLower(strings.TrimSpace(r.Format))
+	r.Backend = strings.ToLower(strings.TrimSpace(r.Backend))
 }
 
 func (m *MCPConfig) applyDefaults(keys keySet) {
@@ -239,9 +547,226 @@ func (a *AdvancedConfig) applyDefaults(keys keySet) {
 			need:  func() bool { return a.VisualRenderConcurrency <= 0 },
 			apply: func() { a.VisualRenderConcurrency = defaultAdvancedVisualRender },
 		},
+		fieldDefault{
+			key:   "advanced.pipeline_concurrency",
+			need:  func() bool { return a.PipelineConcurrency <= 0 },
+			apply: func() { a.PipelineConcurrency = defaultAdvancedPipelineConcurrency },
+		},
+	)
+	a.PriceGuard.applyDefaults(keys)
+	a.VolatilityBreaker.applyDefaults(keys)
+	a.ContractCalendar.applyDefaults(keys)
+	a.SymbolRules.applyDefaults(keys)
+	a.Correlation.applyDefaults(keys)
+	a.TrailingStop.applyDefaults(keys)
+	a.AutoBreakeven.applyDefaults(keys)
+	a.ScaleIn.applyDefaults(keys)
+	a.EntryDrift.applyDefaults(keys)
+}
+
+func (t *TrailingStopConfig) applyDefaults(keys keySet) {
+	if t == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		stringFieldDefault("advanced.trailing_stop.lock_mode", &t.LockMode, defaultTrailingLockMode),
+		fieldDefault{
+			key:   "advanced.trailing_stop.lock_atr_multiplier",
+			need:  func() bool { return t.LockATRMultiplier <= 0 },
+			apply: func() { t.LockATRMultiplier = defaultTrailingLockATR },
+		},
+		fieldDefault{
+			key:   "advanced.trailing_stop.trail_atr_multiplier",
+			need:  func() bool { return t.TrailATRMultiplier <= 0 },
+			apply: func() { t.TrailATRMultiplier = defaultTrailingTrailATR },
+		},
+		fieldDefault{
+			key:   "advanced.trailing_stop.min_step_pct",
+			need:  func() bool { return t.MinStepPct <= 0 },
+			apply: func() { t.MinStepPct = defaultTrailingMinStep },
+		},
+	)
+	t.LockMode = strings.ToLower(strings.TrimSpace(t.LockMode))
+}
+
+func (c *AutoBreakevenConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		fieldDefault{
+			key:   "advanced.auto_breakeven.fee_buffer_pct",
+			need:  func() bool { return c.FeeBufferPct <= 0 },
+			apply: func() { c.FeeBufferPct = defaultAutoBreakevenFeeBuffer },
+		},
+	)
+}
+
+func (c *ScaleInConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		fieldDefault{
+			key:   "advanced.scale_in.max_entries",
+			need:  func() bool { return c.MaxEntries <= 0 },
+			apply: func() { c.MaxEntries = defaultScaleInMaxEntries },
+		},
+	)
+}
+
+func (c *EntryDriftConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		stringFieldDefault("advanced.entry_drift.action", &c.Action, defaultEntryDriftAction),
+		fieldDefault{
+			key:   "advanced.entry_drift.resize_ratio",
+			need:  func() bool { return c.ResizeRatio <= 0 || c.ResizeRatio >= 1 },
+			apply: func() { c.ResizeRatio = defaultEntryDriftResize },
+		},
+		fieldDefault{
+			key:   "advanced.entry_drift.max_latency_seconds",
+			need:  func() bool { return c.MaxLatencySeconds == 0 },
+			apply: func() { c.MaxLatencySeconds = defaultEntryDriftMaxLatency },
+		},
+	)
+	c.Action = strings.ToLower(strings.TrimSpace(c.Action))
+}
+
+func (c *SymbolRulesConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		fieldDefault{
+			key:   "advanced.symbol_rules.refresh_minutes",
+			need:  func() bool { return c.RefreshMinutes <= 0 },
+			apply: func() { c.RefreshMinutes = defaultSymbolRulesRefreshMinutes },
+		},
+	)
+	c.Source = strings.ToLower(strings.TrimSpace(c.Source))
+}
+
+func (c *CorrelationConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		stringFieldDefault("advanced.correlation.interval", &c.Interval, defaultCorrelationInterval),
+		stringFieldDefault("advanced.correlation.action", &c.Action, defaultCorrelationAction),
+		fieldDefault{
+			key:   "advanced.correlation.lookback",
+			need:  func() bool { return c.Lookback <= 2 },
+			apply: func() { c.Lookback = defaultCorrelationLookback },
+		},
+		fieldDefault{
+			key:   "advanced.correlation.threshold",
+			need:  func() bool { return c.Threshold <= 0 || c.Threshold > 1 },
+			apply: func() { c.Threshold = defaultCorrelationThreshold },
+		},
+		fieldDefault{
+			key:   "advanced.correlation.downsize_ratio",
+			need:  func() bool { return c.DownsizeRatio <= 0 || c.DownsizeRatio >= 1 },
+			apply: func() { c.DownsizeRatio = defaultCorrelationDownsize },
+		},
+		fieldDefault{
+			key:   "advanced.correlation.refresh_minutes",
+			need:  func() bool { return c.RefreshMinutes <= 0 },
+			apply: func() { c.RefreshMinutes = defaultCorrelationRefresh },
+		},
+	)
+	c.Interval = strings.ToLower(strings.TrimSpace(c.Interval))
+	c.Action = strings.ToLower(strings.TrimSpace(c.Action))
+}
+
+func (c *ContractCalendarConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		fieldDefault{
+			key:   "advanced.contract_calendar.entry_cutoff_hours",
+			need:  func() bool { return c.EntryCutoffHours <= 0 },
+			apply: func() { c.EntryCutoffHours = defaultContractEntryCutoffHours },
+		},
+		fieldDefault{
+			key:   "advanced.contract_calendar.close_before_hours",
+			need:  func() bool { return c.CloseBeforeHours <= 0 },
+			apply: func() { c.CloseBeforeHours = defaultContractCloseBeforeHours },
+		},
 	)
 }
 
+func (v *VolatilityBreakerConfig) applyDefaults(keys keySet) {
+	if v == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		stringFieldDefault("advanced.volatility_breaker.interval", &v.Interval, defaultVolBreakerInterval),
+		fieldDefault{
+			key:   "advanced.volatility_breaker.window",
+			need:  func() bool { return v.Window <= 1 },
+			apply: func() { v.Window = defaultVolBreakerWindow },
+		},
+		fieldDefault{
+			key:   "advanced.volatility_breaker.max_vol_pct",
+			need:  func() bool { return v.MaxVolPct <= 0 },
+			apply: func() { v.MaxVolPct = defaultVolBreakerMaxVol },
+		},
+		fieldDefault{
+			key:   "advanced.volatility_breaker.market_wide_ratio",
+			need:  func() bool { return v.MarketWideRatio <= 0 },
+			apply: func() { v.MarketWideRatio = defaultVolBreakerRatio },
+		},
+		fieldDefault{
+			key:   "advanced.volatility_breaker.resume_minutes",
+			need:  func() bool { return v.ResumeMinutes <= 0 },
+			apply: func() { v.ResumeMinutes = defaultVolBreakerResume },
+		},
+		fieldDefault{
+			key:   "advanced.volatility_breaker.check_seconds",
+			need:  func() bool { return v.CheckSeconds <= 0 },
+			apply: func() { v.CheckSeconds = defaultVolBreakerCheck },
+		},
+	)
+	v.Interval = strings.ToLower(strings.TrimSpace(v.Interval))
+}
+
+func (p *PriceGuardConfig) applyDefaults(keys keySet) {
+	if p == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		stringFieldDefault("advanced.price_guard.reference", &p.Reference, defaultPriceGuardReference),
+		boolFieldDefault("advanced.price_guard.fail_open", &p.FailOpen, true),
+		fieldDefault{
+			key:   "advanced.price_guard.tolerance_pct",
+			need:  func() bool { return p.TolerancePct <= 0 },
+			apply: func() { p.TolerancePct = defaultPriceGuardTolerance },
+		},
+		fieldDefault{
+			key:   "advanced.price_guard.timeout_seconds",
+			need:  func() bool { return p.TimeoutSeconds <= 0 },
+			apply: func() { p.TimeoutSeconds = defaultPriceGuardTimeout },
+		},
+		stringFieldDefault("advanced.price_guard.consensus", &p.Consensus, defaultPriceGuardConsensus),
+		fieldDefault{
+			key:   "advanced.price_guard.stream_max_age_seconds",
+			need:  func() bool { return p.StreamMaxAgeSeconds <= 0 },
+			apply: func() { p.StreamMaxAgeSeconds = defaultPriceGuardStreamMaxAge },
+		},
+		fieldDefault{
+			key:   "advanced.price_guard.divergence_cooldown_minutes",
+			need:  func() bool { return p.DivergenceCooldownMinutes <= 0 },
+			apply: func() { p.DivergenceCooldownMinutes = defaultPriceGuardDivergenceCooldown },
+		},
+	)
+	p.Reference = strings.ToLower(strings.TrimSpace(p.Reference))
+	p.Consensus = strings.ToLower(strings.TrimSpace(p.Consensus))
+}
+
 func (t *TradingConfig) applyDefaults(keys keySet) {
 	if t == nil {
 		return
@@ -273,6 +798,7 @@ func (f *FreqtradeConfig) applyDefaults(keys keySet) {
 		stringFieldDefault("freqtrade.api_url", &f.APIURL, defaultFreqtradeAPI),
 		stringFieldDefault("freqtrade.webhook_url", &f.WebhookURL, defaultFreqtradeWebhook),
 		stringFieldDefault("freqtrade.risk_store_path", &f.RiskStorePath, defaultFreqtradeRiskDB),
+		stringFieldDefault("freqtrade.trading_mode", &f.TradingMode, defaultFreqtradeTradingMode),
 		fieldDefault{
 			key:   "freqtrade.default_stake_usd",
 			need:  func() bool { return f.DefaultStakeUSD <= 0 },
@@ -288,6 +814,11 @@ func (f *FreqtradeConfig) applyDefaults(keys keySet) {
 			need:  func() bool { return f.TimeoutSeconds <= 0 },
 			apply: func() { f.TimeoutSeconds = defaultFreqtradeTimeout },
 		},
+		fieldDefault{
+			key:   "freqtrade.stuck_closing_minutes",
+			need:  func() bool { return f.StuckClosingMinutes <= 0 },
+			apply: func() { f.StuckClosingMinutes = defaultFreqtradeStuckClosing },
+		},
 	)
 	if f.DefaultStakeUSD < 0 {
 		f.DefaultStakeUSD = 0
@@ -298,6 +829,72 @@ func (f *FreqtradeConfig) applyDefaults(keys keySet) {
 	if f.EntrySlipPct < 0 {
 		f.EntrySlipPct = 0
 	}
+	f.TradingMode = strings.ToLower(strings.TrimSpace(f.TradingMode))
+}
+
+func (n *NotifyConfig) applyDefaults(keys keySet) {
+	if n == nil {
+		return
+	}
+	w := &n.Webhooks
+	applyFieldDefaults(keys,
+		fieldDefault{
+			key:   "notify.webhooks.retries",
+			apply: func() { w.Retries = defaultWebhookRetries },
+		},
+		fieldDefault{
+			key:   "notify.webhooks.timeout_seconds",
+			need:  func() bool { return w.TimeoutSeconds <= 0 },
+			apply: func() { w.TimeoutSeconds = defaultWebhookTimeout },
+		},
+		fieldDefault{
+			key:   "notify.webhooks.queue_size",
+			need:  func() bool { return w.QueueSize <= 0 },
+			apply: func() { w.QueueSize = defaultWebhookQueueSize },
+		},
+	)
+	for i := range w.Endpoints {
+		ep := &w.Endpoints[i]
+		ep.Name = strings.TrimSpace(ep.Name)
+		ep.URL = strings.TrimSpace(ep.URL)
+		if ep.Name == "" {
+			ep.Name = fmt.Sprintf("endpoint-%d", i+1)
+		}
+		for j, evt := range ep.Events {
+			ep.Events[j] = strings.ToLower(strings.TrimSpace(evt))
+		}
+	}
+	for i := range n.Sinks {
+		sink := &n.Sinks[i]
+		sink.Type = strings.ToLower(strings.TrimSpace(sink.Type))
+		sink.URL = strings.TrimSpace(sink.URL)
+		sink.Name = strings.TrimSpace(sink.Name)
+		if sink.Name == "" {
+			sink.Name = fmt.Sprintf("%s-%d", sink.Type, i+1)
+		}
+		if sink.TimeoutSeconds <= 0 {
+			sink.TimeoutSeconds = defaultWebhookTimeout
+		}
+		for j, cat := range sink.Categories {
+			sink.Categories[j] = strings.ToLower(strings.TrimSpace(cat))
+		}
+	}
+}
+
+func (e *ExecutionConfig) applyDefaults(keys keySet) {
+	if e == nil {
+		return
+	}
+	b := &e.Binance
+	applyFieldDefaults(keys,
+		stringFieldDefault("execution.binance.rest_base_url", &b.RESTBaseURL, defaultMarketREST),
+		stringFieldDefault("execution.binance.stake_currency", &b.StakeCurrency, defaultExecStakeCurrency),
+		fieldDefault{
+			key:   "execution.binance.timeout_seconds",
+			need:  func() bool { return b.TimeoutSeconds <= 0 },
+			apply: func() { b.TimeoutSeconds = defaultExecTimeout },
+		},
+	)
 }
 
 func (a *AIConfig) applyDefaults(keys keySet) {
@@ -317,13 +914,85 @@ func (a *AIConfig) applyDefaults(keys keySet) {
 			need:  func() bool { return a.DecisionOffsetSeconds == 0 },
 			apply: func() { a.DecisionOffsetSeconds = defaultAIDecisionOffset },
 		},
+		fieldDefault{
+			key:   "ai.decision_timeout_seconds",
+			need:  func() bool { return a.DecisionTimeoutSeconds == 0 },
+			apply: func() { a.DecisionTimeoutSeconds = defaultAIDecisionTimeout },
+		},
+		fieldDefault{
+			key:   "ai.stale_profile_cycles",
+			need:  func() bool { return a.StaleProfileCycles <= 0 },
+			apply: func() { a.StaleProfileCycles = defaultStaleProfileCycles },
+		},
 		boolFieldDefault("ai.log_each_model", &a.LogEachModel, true),
+		fieldDefault{
+			key:   "ai.profile_trash_days",
+			need:  func() bool { return a.ProfileTrashDays <= 0 },
+			apply: func() { a.ProfileTrashDays = defaultProfileTrashDays },
+		},
 	)
 	a.ProviderPreference = normalizePreferenceList(a.ProviderPreference)
 	if strings.TrimSpace(a.ActiveHorizon) == "" {
 		a.ActiveHorizon = "profiles"
 	}
 	a.MultiAgent.applyDefaults(keys)
+	a.Artifacts.applyDefaults(keys)
+	a.DecisionHistory.applyDefaults(keys)
+	a.StaleData.applyDefaults(keys)
+}
+
+func (h *DecisionHistoryConfig) applyDefaults(keys keySet) {
+	if h == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		boolFieldDefault("ai.decision_history.enabled", &h.Enabled, true),
+		fieldDefault{
+			key:   "ai.decision_history.limit",
+			need:  func() bool { return h.Limit <= 0 },
+			apply: func() { h.Limit = defaultDecisionHistoryLimit },
+		},
+		fieldDefault{
+			key:   "ai.decision_history.lookback_hours",
+			need:  func() bool { return h.LookbackHours <= 0 },
+			apply: func() { h.LookbackHours = defaultDecisionHistoryLookback },
+		},
+		fieldDefault{
+			key:   "ai.decision_history.max_chars",
+			need:  func() bool { return h.MaxChars <= 0 },
+			apply: func() { h.MaxChars = defaultDecisionHistoryMaxChars },
+		},
+	)
+}
+
+func (c *StaleDataConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	applyFieldDefaults(keys,
+		stringFieldDefault("ai.stale_data.mode", &c.Mode, StaleDataModeDegrade),
+		fieldDefault{
+			key:   "ai.stale_data.max_age_multiple",
+			need:  func() bool { return c.MaxAgeMultiple <= 0 },
+			apply: func() { c.MaxAgeMultiple = defaultStaleDataMaxAgeMultiple },
+		},
+	)
+	c.Mode = strings.ToLower(strings.TrimSpace(c.Mode))
+}
+
+func (c *ArtifactStoreConfig) applyDefaults(keys keySet) {
+	if c == nil {
+		return
+	}
+	c.Backend = strings.ToLower(strings.TrimSpace(c.Backend))
+	applyFieldDefaults(keys,
+		stringFieldDefault("ai.artifacts.dir", &c.Dir, defaultArtifactDir),
+		fieldDefault{
+			key:   "ai.artifacts.min_bytes",
+			need:  func() bool { return c.MinBytes <= 0 },
+			apply: func() { c.MinBytes = defaultArtifactMinBytes },
+		},
+	)
 }
 
 func (m *MultiAgentConfig) applyDefaults(keys keySet) {
@@ -371,6 +1040,13 @@ func (m *MarketConfig) applyDefaults(keys keySet) {
 	if strings.TrimSpace(m.ActiveSource) == "" {
 		m.ActiveSource = firstEnabledMarket(m.Sources)
 	}
+	applyFieldDefaults(keys,
+		fieldDefault{
+			key:   "market.max_streams",
+			need:  func() bool { return m.MaxStreams <= 0 },
+			apply: func() { m.MaxStreams = defaultMarketMaxStreams },
+		},
+	)
 }
 
 func defaultRESTBySource(name string) string {
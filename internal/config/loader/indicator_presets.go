@@ -0,0 +1,44 @@
+package loader
+
+import (
+	"strings"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/logger"
+)
+
+// applyIndicatorPresets 用 params.preset（缺省为 profile snapshot.preset）补齐指标中间件参数；
+// 显式写出的参数优先，预设只填充缺失项。
+func applyIndicatorPresets(profile string, list []MiddlewareConfig, fallback string) []MiddlewareConfig {
+	for i := range list {
+		name := ""
+		if raw, ok := list[i].Params["preset"]; ok {
+			name, _ = raw.(string)
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fallback
+		}
+		if name == "" {
+			continue
+		}
+		preset, ok := indicator.LookupPreset(name)
+		if !ok {
+			logger.Warnf("profile %s 中间件 %s 引用未知指标预设 %q，已忽略", profile, list[i].Name, name)
+			continue
+		}
+		expanded := preset.MiddlewareParams(list[i].Name)
+		if len(expanded) == 0 {
+			continue
+		}
+		if list[i].Params == nil {
+			list[i].Params = make(map[string]interface{}, len(expanded))
+		}
+		for k, v := range expanded {
+			if _, set := list[i].Params[k]; !set {
+				list[i].Params[k] = v
+			}
+		}
+	}
+	return list
+}
@@ -3,11 +3,16 @@ package loader
 import (
 	"fmt"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"brale/internal/analysis/screen"
+	"brale/internal/config"
+	"brale/internal/decision"
 	"brale/internal/logger"
 
 	"github.com/fsnotify/fsnotify"
@@ -28,7 +33,30 @@ type ProfileDefinition struct {
 	ExitPlans                ExitPlanBinding    `mapstructure:"exit_plans"`
 	Derivatives              DerivativesConfig  `mapstructure:"derivatives"`
 	KlineWindows             KlineWindowConfig  `mapstructure:"kline_windows"`
+	OutputContract           OutputContract     `mapstructure:"output_contract"`
+	Screening                ScreeningConfig    `mapstructure:"screening"`
+	Schedule                 ScheduleConfig     `mapstructure:"schedule"`
+	Composite                CompositeConfig    `mapstructure:"composite"`
+	Snapshot                 SnapshotConfig     `mapstructure:"snapshot"`
+	Rules                    RulesConfig        `mapstructure:"rules"`
+	Consensus                ConsensusConfig    `mapstructure:"consensus"`
 	Default                  bool               `mapstructure:"default"`
+	// Executor 选择下单执行器：freqtrade（默认）或 binance（直连交易所）。
+	Executor string `mapstructure:"executor"`
+	// TradingMode 覆盖 freqtrade.trading_mode（futures / spot / margin），留空使用全局配置；直连执行器仅支持合约。
+	TradingMode string `mapstructure:"trading_mode"`
+	// MarketSource 指定本 profile 交易对使用的行情源（market.sources / 已注册名称，如 okx），留空使用 market.active_source。
+	MarketSource string `mapstructure:"market_source"`
+	// Priority 为 WS 订阅优先级，数值越大越晚被淘汰（持仓 symbol 始终保留）。
+	Priority int `mapstructure:"priority"`
+	// Confluence 与 composite 同构：Enabled 时把多周期多空共振分以 JSON 段写入提示词，Weights 为各周期权重。
+	Confluence CompositeConfig `mapstructure:"confluence"`
+	// SymbolGate 为分析前的交易对闸门：黑白名单与连续亏损后的冷却。
+	SymbolGate SymbolGateConfig `mapstructure:"symbol_gate"`
+	// DivergenceScoring 覆盖背离打分的指标权重与阈值，作用于 screening/rules/composite/confluence。
+	DivergenceScoring DivergenceScoringConfig `mapstructure:"divergence_scoring"`
+	// Session 限定分析/交易时段（时段窗口、周末、日开盘后静默期），时段外的轮次跳过并记入决策日志。
+	Session SessionConfig `mapstructure:"session"`
 
 	targetsUpper   []string
 	intervalsLower []string
@@ -48,6 +76,8 @@ const defaultExitPlanID = "plan_combo_main"
 type ExitPlanBinding struct {
 	Allowed []string `mapstructure:"-"`
 	Combos  []string `mapstructure:"combos"`
+	// TierRatios 为分段止盈/止损按多空区分的默认比例（人工开仓未填比例、提示词约束时使用）。
+	TierRatios TierRatioConfig `mapstructure:"tier_ratios"`
 
 	allowedNormalized []string
 	combosNormalized  []string
@@ -115,6 +145,376 @@ func (k KlineWindowConfig) IsEnabled() bool {
 	return *k.Enabled
 }
 
+// OutputContract 描述模型输出契约：reasoning 语言/长度、价格与仓位单位、开仓必填字段。
+// 同一份定义既注入 system prompt，也用于决策校验。
+type OutputContract struct {
+	Language          string   `mapstructure:"language"`
+	ReasoningMaxChars int      `mapstructure:"reasoning_max_chars"`
+	PriceUnit         string   `mapstructure:"price_unit"`
+	SizeUnit          string   `mapstructure:"size_unit"`
+	RequiredOnOpen    []string `mapstructure:"required_on_open"`
+}
+
+// normalize 规范字段；required_on_open 中无法校验的字段名在加载时告警并丢弃。
+func (c *OutputContract) normalize(profile string) {
+	if c == nil {
+		return
+	}
+	c.Language = strings.ToLower(strings.TrimSpace(c.Language))
+	c.PriceUnit = strings.TrimSpace(c.PriceUnit)
+	c.SizeUnit = strings.TrimSpace(c.SizeUnit)
+	if c.ReasoningMaxChars < 0 {
+		c.ReasoningMaxChars = 0
+	}
+	seen := make(map[string]struct{}, len(c.RequiredOnOpen))
+	fields := make([]string, 0, len(c.RequiredOnOpen))
+	for _, f := range c.RequiredOnOpen {
+		key := strings.ToLower(strings.TrimSpace(f))
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		fields = append(fields, key)
+	}
+	if unknown := decision.UnknownContractFields(fields); len(unknown) > 0 {
+		logger.Warnf("profile %s output_contract.required_on_open 含未知字段 %s，已忽略", profile, strings.Join(unknown, ","))
+		fields = slices.DeleteFunc(fields, func(f string) bool { return slices.Contains(unknown, f) })
+	}
+	c.RequiredOnOpen = fields
+}
+
+// ScreeningConfig 为 LLM 调用前的量化筛选：规则写作 "divergence != none"、"regime == trending"、"rsi <= 30"、"score >= 40"。
+type ScreeningConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	Mode     string   `mapstructure:"mode"`
+	Interval string   `mapstructure:"interval"`
+	Rules    []string `mapstructure:"rules"`
+
+	gate screen.Gate
+}
+
+// normalize 在加载时编译规则，无法解析的规则告警并丢弃；没有有效规则时关闭筛选。
+// 筛选基于 sense 快照，interval 必须是 profile 已订阅的周期，否则回退到首个周期。
+func (c *ScreeningConfig) normalize(profile string, intervals []string) {
+	if c == nil {
+		return
+	}
+	c.Mode = strings.ToLower(strings.TrimSpace(c.Mode))
+	if c.Mode != screen.ModeAll {
+		c.Mode = screen.ModeAny
+	}
+	c.Interval = strings.ToLower(strings.TrimSpace(c.Interval))
+	if c.Interval != "" && !slices.Contains(intervals, c.Interval) {
+		logger.Warnf("profile %s screening.interval=%s 不在 intervals 中，使用首个周期", profile, c.Interval)
+		c.Interval = ""
+	}
+	rules := make([]string, 0, len(c.Rules))
+	c.gate = screen.Gate{Mode: c.Mode}
+	for _, r := range c.Rules {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		rule, err := screen.ParseRule(r)
+		if err != nil {
+			logger.Warnf("profile %s screening 规则忽略: %v", profile, err)
+			continue
+		}
+		rules = append(rules, r)
+		c.gate.Rules = append(c.gate.Rules, rule)
+	}
+	c.Rules = rules
+	if len(c.Rules) == 0 {
+		c.Enabled = false
+	}
+}
+
+// Gate 返回加载时编译好的筛选规则。
+func (c ScreeningConfig) Gate() screen.Gate {
+	return c.gate
+}
+
+// ScheduleConfig 在决策周期内错开本 profile 的 symbol，避免所有 symbol 在同一秒触发。
+// OffsetSeconds 为整体延后；JitterSeconds 按 symbol 稳定散列出 [0, jitter) 的额外延后；
+// BatchSize>0 时 targets 按固定大小分批，各批次在 SpreadSeconds 窗口内均匀错开（0 表示取最短周期的 1/4）。
+type ScheduleConfig struct {
+	OffsetSeconds int `mapstructure:"offset_seconds"`
+	JitterSeconds int `mapstructure:"jitter_seconds"`
+	BatchSize     int `mapstructure:"batch_size"`
+	SpreadSeconds int `mapstructure:"spread_seconds"`
+}
+
+func (c *ScheduleConfig) normalize() {
+	if c == nil {
+		return
+	}
+	c.OffsetSeconds = max(c.OffsetSeconds, 0)
+	c.JitterSeconds = max(c.JitterSeconds, 0)
+	c.BatchSize = max(c.BatchSize, 0)
+	c.SpreadSeconds = max(c.SpreadSeconds, 0)
+}
+
+// CompositeConfig 控制跨周期综合评分：Enabled 时写入提示词的价格窗口快照；
+// Weights 为各周期权重（键为周期，如 "4h"），未配置的周期权重为 1。screening 的 score 规则总是可用。
+type CompositeConfig struct {
+	Enabled bool               `mapstructure:"enabled"`
+	Weights map[string]float64 `mapstructure:"weights"`
+}
+
+func (c *CompositeConfig) normalize() {
+	if c == nil || len(c.Weights) == 0 {
+		return
+	}
+	weights := make(map[string]float64, len(c.Weights))
+	for iv, w := range c.Weights {
+		if iv = strings.ToLower(strings.TrimSpace(iv)); iv != "" && w > 0 {
+			weights[iv] = w
+		}
+	}
+	c.Weights = weights
+}
+
+// DivergenceScoringConfig 为背离打分参数：Weights 按指标（rsi/wt/mfi/obv/stoch_rsi/connors_rsi）覆盖默认权重，默认仅 rsi=1；
+// Threshold 为同向背离指标权重占比下限，缺省 0.5。
+type DivergenceScoringConfig struct {
+	Weights   map[string]float64 `mapstructure:"weights"`
+	Threshold float64            `mapstructure:"threshold"`
+}
+
+func (c *DivergenceScoringConfig) normalize() {
+	if c == nil {
+		return
+	}
+	if c.Threshold < 0 || c.Threshold > 1 {
+		c.Threshold = 0
+	}
+	if len(c.Weights) == 0 {
+		return
+	}
+	weights := make(map[string]float64, len(c.Weights))
+	for k, w := range c.Weights {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" && w >= 0 {
+			weights[k] = w
+		}
+	}
+	c.Weights = weights
+}
+
+// SymbolGateConfig 控制哪些 targets 参与分析：Blacklist 中的 symbol 跳过，Whitelist 非空时只分析其中的 symbol；
+// LossStreak > 0 时，某 symbol 最近 LossStreak 笔平仓连续亏损后，从最后一笔平仓起冷却 CooldownHours 小时。
+// 持仓中的 symbol 始终放行，以便模型管理已有仓位。
+type SymbolGateConfig struct {
+	Blacklist     []string `mapstructure:"blacklist"`
+	Whitelist     []string `mapstructure:"whitelist"`
+	LossStreak    int      `mapstructure:"loss_streak"`
+	CooldownHours float64  `mapstructure:"cooldown_hours"`
+}
+
+func (c *SymbolGateConfig) normalize() {
+	if c == nil {
+		return
+	}
+	c.Blacklist = normalizeSymbols(c.Blacklist)
+	c.Whitelist = normalizeSymbols(c.Whitelist)
+	if c.LossStreak < 0 {
+		c.LossStreak = 0
+	}
+	if c.LossStreak > 0 && c.CooldownHours <= 0 {
+		c.CooldownHours = 12
+	}
+}
+
+// SnapshotConfig 控制指标快照的数值归一化：DistanceUnits 为 absolute（默认，原始价差）、
+// atr（价差以 ATR 倍数表示）或 both（两者同时输出）。
+// Preset 为指标参数预设名（见 indicator.Presets），同时作为本 profile 指标中间件的默认 preset。
+// Blocks/Tails/Precision 裁剪快照内容以节省 token：数据块白名单、各块 last_n 长度与小数位。
+type SnapshotConfig struct {
+	DistanceUnits string         `mapstructure:"distance_units"`
+	Preset        string         `mapstructure:"preset"`
+	Blocks        []string       `mapstructure:"blocks"`
+	Tails         map[string]int `mapstructure:"tails"`
+	Precision     int            `mapstructure:"precision"`
+	// Delta 为增量快照：只把相对上一轮发送给模型的值有明显变化的字段交给模型。
+	Delta SnapshotDeltaConfig `mapstructure:"delta"`
+	// StochRSI/ConnorsRSI 覆盖预设中的周期与超买超卖阈值，未填字段沿用预设。
+	StochRSI   StochRSIConfig   `mapstructure:"stoch_rsi"`
+	ConnorsRSI ConnorsRSIConfig `mapstructure:"connors_rsi"`
+	// EMAs 按周期（或 default）指定快照输出的 EMA 周期，配置后快照以 emas 数组替代 ema_fast/mid/slow。
+	EMAs map[string][]int `mapstructure:"emas"`
+}
+
+type StochRSIConfig struct {
+	RSIPeriod   int     `mapstructure:"rsi_period"`
+	StochPeriod int     `mapstructure:"stoch_period"`
+	K           int     `mapstructure:"k"`
+	D           int     `mapstructure:"d"`
+	Oversold    float64 `mapstructure:"oversold"`
+	Overbought  float64 `mapstructure:"overbought"`
+}
+
+type ConnorsRSIConfig struct {
+	RSIPeriod    int     `mapstructure:"rsi_period"`
+	StreakPeriod int     `mapstructure:"streak_period"`
+	RankPeriod   int     `mapstructure:"rank_period"`
+	Oversold     float64 `mapstructure:"oversold"`
+	Overbought   float64 `mapstructure:"overbought"`
+}
+
+// SnapshotDeltaConfig 控制增量快照：Epsilon 按字段路径（如 rsi.current）、数据块（如 rsi）或字段名（如 latest）
+// 覆盖绝对阈值，未覆盖的数值字段按 DefaultEpsilonPct（相对变化百分比，缺省 0.1）判断；
+// FullEvery 为每隔多少轮重新发送一次完整快照（缺省 12）。
+type SnapshotDeltaConfig struct {
+	Enabled           bool               `mapstructure:"enabled"`
+	Epsilon           map[string]float64 `mapstructure:"epsilon"`
+	DefaultEpsilonPct float64            `mapstructure:"default_epsilon_pct"`
+	FullEvery         int                `mapstructure:"full_every"`
+}
+
+func (c *SnapshotDeltaConfig) normalize() {
+	if c == nil {
+		return
+	}
+	if c.DefaultEpsilonPct <= 0 {
+		c.DefaultEpsilonPct = 0.1
+	}
+	if c.FullEvery <= 0 {
+		c.FullEvery = 12
+	}
+	if len(c.Epsilon) > 0 {
+		eps := make(map[string]float64, len(c.Epsilon))
+		for key, v := range c.Epsilon {
+			if key = strings.ToLower(strings.TrimSpace(key)); key != "" && v >= 0 {
+				eps[key] = v
+			}
+		}
+		c.Epsilon = eps
+	}
+}
+
+// normalizeEMAPeriods 去掉小于 2 的周期，去重后升序。
+func normalizeEMAPeriods(periods []int) []int {
+	seen := make(map[int]struct{}, len(periods))
+	out := make([]int, 0, len(periods))
+	for _, p := range periods {
+		if _, ok := seen[p]; ok || p < 2 {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	sort.Ints(out)
+	return out
+}
+
+func (c *SnapshotConfig) normalize() {
+	if c == nil {
+		return
+	}
+	switch units := strings.ToLower(strings.TrimSpace(c.DistanceUnits)); units {
+	case "atr", "both":
+		c.DistanceUnits = units
+	default:
+		c.DistanceUnits = "absolute"
+	}
+	c.Preset = strings.ToLower(strings.TrimSpace(c.Preset))
+	c.Blocks = normalizeComboKeys(c.Blocks)
+	if len(c.Tails) > 0 {
+		tails := make(map[string]int, len(c.Tails))
+		for block, n := range c.Tails {
+			if block = strings.ToLower(strings.TrimSpace(block)); block != "" && n >= 0 {
+				tails[block] = n
+			}
+		}
+		c.Tails = tails
+	}
+	if c.Precision < 0 {
+		c.Precision = 0
+	}
+	if len(c.EMAs) > 0 {
+		emas := make(map[string][]int, len(c.EMAs))
+		for iv, periods := range c.EMAs {
+			iv = strings.ToLower(strings.TrimSpace(iv))
+			if iv == "" {
+				continue
+			}
+			if periods = normalizeEMAPeriods(periods); len(periods) > 0 {
+				emas[iv] = periods
+			}
+		}
+		c.EMAs = emas
+	}
+	c.Delta.normalize()
+}
+
+// ConsensusConfig 让 profile 的最终决策改用多模型共识：同一 prompt 并发发给全部启用模型，
+// 加权占比达到 MinAgreement 的方向胜出，止盈止损与 tier 价格取平均，分歧写入决策日志。
+type ConsensusConfig struct {
+	Enabled      bool               `mapstructure:"enabled"`
+	MinAgreement float64            `mapstructure:"min_agreement"`
+	Weights      map[string]float64 `mapstructure:"weights"`
+}
+
+func (c *ConsensusConfig) normalize() {
+	if c == nil {
+		return
+	}
+	if c.MinAgreement <= 0 || c.MinAgreement > 1 {
+		c.MinAgreement = 0.5
+	}
+}
+
+// RulesConfig 为声明式规则决策：表达式基于量化结论、中间件特征与持仓字段确定性求值，产出与模型相同的 Decision。
+// Mode=replace 时本 profile 不调用模型；Mode=fallback 时仅在模型决策失败时兜底。
+// 开仓按 ATR 倍数生成单段止盈/止损（plan_combo_main 的 tp_single + sl_single）。
+type RulesConfig struct {
+	Enabled         bool               `mapstructure:"enabled"`
+	Mode            string             `mapstructure:"mode"`
+	Interval        string             `mapstructure:"interval"`
+	Vars            map[string]float64 `mapstructure:"vars"`
+	LongEntry       string             `mapstructure:"long_entry"`
+	ShortEntry      string             `mapstructure:"short_entry"`
+	LongExit        string             `mapstructure:"long_exit"`
+	ShortExit       string             `mapstructure:"short_exit"`
+	StopATR         float64            `mapstructure:"stop_atr"`
+	TakeProfitATR   float64            `mapstructure:"take_profit_atr"`
+	Leverage        int                `mapstructure:"leverage"`
+	PositionSizeUSD float64            `mapstructure:"position_size_usd"`
+}
+
+const (
+	RulesModeReplace  = "replace"
+	RulesModeFallback = "fallback"
+
+	defaultRulesStopATR       = 1.5
+	defaultRulesTakeProfitATR = 3.0
+)
+
+func (c *RulesConfig) normalize() {
+	if c == nil {
+		return
+	}
+	c.Mode = strings.ToLower(strings.TrimSpace(c.Mode))
+	if c.Mode != RulesModeFallback {
+		c.Mode = RulesModeReplace
+	}
+	c.Interval = strings.ToLower(strings.TrimSpace(c.Interval))
+	c.LongEntry = strings.TrimSpace(c.LongEntry)
+	c.ShortEntry = strings.TrimSpace(c.ShortEntry)
+	c.LongExit = strings.TrimSpace(c.LongExit)
+	c.ShortExit = strings.TrimSpace(c.ShortExit)
+	if c.StopATR <= 0 {
+		c.StopATR = defaultRulesStopATR
+	}
+	if c.TakeProfitATR <= 0 {
+		c.TakeProfitATR = defaultRulesTakeProfitATR
+	}
+	if c.LongEntry == "" && c.ShortEntry == "" && c.LongExit == "" && c.ShortExit == "" {
+		c.Enabled = false
+	}
+}
+
 type MiddlewareConfig struct {
 	Name           string                            `mapstructure:"name"`
 	Stage          int                               `mapstructure:"stage"`
@@ -143,6 +543,8 @@ type ProfileLoader struct {
 	mu        sync.RWMutex
 	snapshot  ProfileSnapshot
 	listeners []ChangeListener
+
+	editMu sync.Mutex
 }
 
 func NewProfileLoader(path string) (*ProfileLoader, error) {
@@ -155,15 +557,18 @@ func NewProfileLoader(path string) (*ProfileLoader, error) {
 		return nil, fmt.Errorf("read profile config failed: %w", err)
 	}
 	loader := &ProfileLoader{path: path, v: v}
-	if err := loader.reload(); err != nil {
+	if _, err := loader.reload(); err != nil {
 		return nil, err
 	}
 	v.OnConfigChange(func(evt fsnotify.Event) {
-		if err := loader.reload(); err != nil {
+		changed, err := loader.reload()
+		if err != nil {
 			logger.Errorf("profile reload failed (%s): %v", evt.Name, err)
 			return
 		}
-		loader.notify()
+		if changed {
+			loader.notify()
+		}
 	})
 	v.WatchConfig()
 	return loader, nil
@@ -213,10 +618,11 @@ func (l *ProfileLoader) notify() {
 	}
 }
 
-func (l *ProfileLoader) reload() error {
+// reload 重新解析配置；内容与当前快照一致时（如 ProfileWriter 写入后文件监听再次触发）不递增版本，返回 changed=false。
+func (l *ProfileLoader) reload() (bool, error) {
 	var fileCfg FileConfig
 	if err := l.v.Unmarshal(&fileCfg); err != nil {
-		return fmt.Errorf("parse profile config failed: %w", err)
+		return false, fmt.Errorf("parse profile config failed: %w", err)
 	}
 	normalized := make(map[string]ProfileDefinition)
 	for name, def := range fileCfg.Profiles {
@@ -224,6 +630,11 @@ func (l *ProfileLoader) reload() error {
 		normalized[name] = norm
 	}
 	l.mu.Lock()
+	if l.snapshot.Version > 0 && reflect.DeepEqual(l.snapshot.Profiles, normalized) {
+		l.mu.Unlock()
+		logger.Debugf("Profile loader: %s 内容未变化，跳过重载", filepath.Base(l.path))
+		return false, nil
+	}
 	l.snapshot = ProfileSnapshot{
 		Version:  l.snapshot.Version + 1,
 		LoadedAt: time.Now(),
@@ -231,7 +642,7 @@ func (l *ProfileLoader) reload() error {
 	}
 	l.mu.Unlock()
 	logger.Infof("Profile loader reloaded %d profiles from %s", len(normalized), filepath.Base(l.path))
-	return nil
+	return true, nil
 }
 
 func normalizeProfileDefinition(name string, def ProfileDefinition) ProfileDefinition {
@@ -253,11 +664,45 @@ func normalizeProfileDefinition(name string, def ProfileDefinition) ProfileDefin
 	}
 	def.Middlewares = expandMiddlewareConfigs(def.Middlewares)
 	def.ExitPlans.normalize()
+	def.ExitPlans.TierRatios.normalize(name)
 	def.Derivatives.normalize()
 	def.KlineWindows.normalize()
+	def.OutputContract.normalize(name)
+	def.Screening.normalize(name, def.intervalsLower)
+	def.Schedule.normalize()
+	def.Composite.normalize()
+	def.Confluence.normalize()
+	def.SymbolGate.normalize()
+	def.DivergenceScoring.normalize()
+	def.Session.normalize()
+	def.Snapshot.normalize()
+	def.Middlewares = applyIndicatorPresets(name, def.Middlewares, def.Snapshot.Preset)
+	def.Rules.normalize()
+	def.Consensus.normalize()
+	def.Executor = strings.ToLower(strings.TrimSpace(def.Executor))
+	def.TradingMode = normalizeTradingMode(name, def.TradingMode, def.Executor)
+	def.MarketSource = strings.ToLower(strings.TrimSpace(def.MarketSource))
 	return def
 }
 
+// normalizeTradingMode 校验 profile 的交易模式覆盖；无效值或直连执行器上的非合约模式会被忽略。
+func normalizeTradingMode(name, mode, executor string) string {
+	raw := strings.TrimSpace(mode)
+	if raw == "" {
+		return ""
+	}
+	norm := config.NormalizeTradingMode(raw)
+	if norm == "" {
+		logger.Warnf("profile %s: trading_mode=%s 无效（可选 futures/spot/margin），使用全局配置", name, raw)
+		return ""
+	}
+	if executor != "" && executor != "freqtrade" && norm != config.TradingModeFutures {
+		logger.Warnf("profile %s: 执行器 %s 仅支持合约，忽略 trading_mode=%s", name, executor, norm)
+		return ""
+	}
+	return norm
+}
+
 func normalizeSymbols(in []string) []string {
 	if len(in) == 0 {
 		return nil
@@ -423,7 +868,7 @@ func cloneSnapshot(src ProfileSnapshot) ProfileSnapshot {
 
 func isAgentMiddleware(name string) bool {
 	switch strings.ToLower(strings.TrimSpace(name)) {
-	case "ema_trend", "rsi_extreme", "macd_trend":
+	case "ema_trend", "rsi_extreme", "macd_trend", "bb_squeeze", "mtf_confluence", "adx_trend", "supertrend", "liquidity_sweep", "rsi_divergence", "custom_indicator", "order_book", "funding_rate":
 		return true
 	default:
 		return false
@@ -0,0 +1,297 @@
+package loader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"brale/internal/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	deletedProfilesKey = "deleted_profiles"
+	deletedAtKey       = "deleted_at"
+)
+
+// ProfileEntry 是 profile 列表项；软删除的 profile 保存在 YAML 的 deleted_profiles 段，不参与运行。
+type ProfileEntry struct {
+	Name      string     `json:"name"`
+	Targets   []string   `json:"targets,omitempty"`
+	Default   bool       `json:"default"`
+	Deleted   bool       `json:"deleted"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// TierRatios 为生效中的多/空分段比例（未配置的一侧为默认值），软删除的 profile 不返回。
+	TierRatios *TierRatioConfig `json:"tier_ratios,omitempty"`
+}
+
+// ListProfiles 返回当前 profile，includeDeleted 时附带软删除的 profile。
+func (l *ProfileLoader) ListProfiles(includeDeleted bool) ([]ProfileEntry, error) {
+	snap := l.Snapshot()
+	out := make([]ProfileEntry, 0, len(snap.Profiles))
+	for name, def := range snap.Profiles {
+		ratios := TierRatioConfig{
+			Long:  def.ExitPlans.TierRatios.ForSide("long"),
+			Short: def.ExitPlans.TierRatios.ForSide("short"),
+		}
+		out = append(out, ProfileEntry{Name: name, Targets: def.TargetsUpper(), Default: def.Default, TierRatios: &ratios})
+	}
+	if includeDeleted {
+		l.editMu.Lock()
+		doc, err := l.readDocument()
+		l.editMu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if trash := mappingValue(doc.Content[0], deletedProfilesKey); trash != nil {
+			for i := 0; i+1 < len(trash.Content); i += 2 {
+				entry := ProfileEntry{Name: trash.Content[i].Value, Deleted: true}
+				var def struct {
+					Targets []string `yaml:"targets"`
+				}
+				_ = trash.Content[i+1].Decode(&def)
+				entry.Targets = normalizeSymbols(def.Targets)
+				if ts, ok := deletedAt(trash.Content[i+1]); ok {
+					entry.DeletedAt = &ts
+				}
+				out = append(out, entry)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Deleted != out[j].Deleted {
+			return !out[i].Deleted
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// SoftDelete 把 profile 移入 deleted_profiles 并记录删除时间。
+func (l *ProfileLoader) SoftDelete(name string) error {
+	return l.editDocument(func(doc *yaml.Node) error {
+		profiles := mappingValue(doc, "profiles")
+		idx := mappingIndex(profiles, name)
+		if idx < 0 {
+			return fmt.Errorf("profile %s 不存在", name)
+		}
+		if len(profiles.Content) <= 2 {
+			return fmt.Errorf("profile %s 是最后一个 profile，不能删除", name)
+		}
+		trash := ensureMapping(doc, deletedProfilesKey)
+		if mappingIndex(trash, name) >= 0 {
+			return fmt.Errorf("回收区已存在同名 profile %s", name)
+		}
+		key, val := profiles.Content[idx], profiles.Content[idx+1]
+		profiles.Content = append(profiles.Content[:idx], profiles.Content[idx+2:]...)
+		setScalar(val, deletedAtKey, time.Now().UTC().Format(time.RFC3339))
+		trash.Content = append(trash.Content, key, val)
+		return nil
+	})
+}
+
+// Restore 把软删除的 profile 移回 profiles。
+func (l *ProfileLoader) Restore(name string) error {
+	return l.editDocument(func(doc *yaml.Node) error {
+		trash := mappingValue(doc, deletedProfilesKey)
+		idx := mappingIndex(trash, name)
+		if idx < 0 {
+			return fmt.Errorf("回收区不存在 profile %s", name)
+		}
+		profiles := ensureMapping(doc, "profiles")
+		if mappingIndex(profiles, name) >= 0 {
+			return fmt.Errorf("profile %s 已存在，无法恢复", name)
+		}
+		key, val := trash.Content[idx], trash.Content[idx+1]
+		trash.Content = append(trash.Content[:idx], trash.Content[idx+2:]...)
+		removeKey(val, deletedAtKey)
+		profiles.Content = append(profiles.Content, key, val)
+		return nil
+	})
+}
+
+// PurgeDeleted 永久删除超过保留期的软删除 profile，返回被清理的名称。
+func (l *ProfileLoader) PurgeDeleted(retention time.Duration) ([]string, error) {
+	if retention <= 0 {
+		return nil, nil
+	}
+	var purged []string
+	err := l.editDocument(func(doc *yaml.Node) error {
+		trash := mappingValue(doc, deletedProfilesKey)
+		if trash == nil {
+			return errNoChange
+		}
+		cutoff := time.Now().Add(-retention)
+		kept := make([]*yaml.Node, 0, len(trash.Content))
+		for i := 0; i+1 < len(trash.Content); i += 2 {
+			if ts, ok := deletedAt(trash.Content[i+1]); ok && ts.Before(cutoff) {
+				purged = append(purged, trash.Content[i].Value)
+				continue
+			}
+			kept = append(kept, trash.Content[i], trash.Content[i+1])
+		}
+		if len(purged) == 0 {
+			return errNoChange
+		}
+		trash.Content = kept
+		return nil
+	})
+	return purged, err
+}
+
+// StartPurge 周期性清理过期的软删除 profile。
+func (l *ProfileLoader) StartPurge(ctx context.Context, retention, interval time.Duration) {
+	if l == nil || retention <= 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if names, err := l.PurgeDeleted(retention); err != nil {
+				logger.Warnf("profile purge failed: %v", err)
+			} else if len(names) > 0 {
+				logger.Infof("profile purge: 已永久删除 %v", names)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+var errNoChange = fmt.Errorf("no change")
+
+func (l *ProfileLoader) editDocument(fn func(doc *yaml.Node) error) error {
+	l.editMu.Lock()
+	defer l.editMu.Unlock()
+	doc, err := l.readDocument()
+	if err != nil {
+		return err
+	}
+	if err := fn(doc.Content[0]); err != nil {
+		if err == errNoChange {
+			return nil
+		}
+		return err
+	}
+	raw, err := os.ReadFile(l.path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(l.path+".bak", raw, 0o644); err != nil {
+		return fmt.Errorf("备份 profile 配置失败: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+	if err := l.v.ReadInConfig(); err != nil {
+		return fmt.Errorf("read profile config failed: %w", err)
+	}
+	changed, err := l.reload()
+	if err != nil {
+		return err
+	}
+	if changed {
+		l.notify()
+	}
+	return nil
+}
+
+func (l *ProfileLoader) readDocument() (*yaml.Node, error) {
+	raw, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("parse profile config failed: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("profile config 根节点必须为 mapping")
+	}
+	return &doc, nil
+}
+
+func mappingIndex(m *yaml.Node, key string) int {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	idx := mappingIndex(m, key)
+	if idx < 0 {
+		return nil
+	}
+	return m.Content[idx+1]
+}
+
+func ensureMapping(m *yaml.Node, key string) *yaml.Node {
+	if val := mappingValue(m, key); val != nil {
+		if val.Kind != yaml.MappingNode {
+			val.Kind, val.Tag, val.Value, val.Content = yaml.MappingNode, "!!map", "", nil
+		}
+		return val
+	}
+	val := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, val)
+	return val
+}
+
+func setScalar(m *yaml.Node, key, value string) {
+	if val := mappingValue(m, key); val != nil {
+		val.Kind, val.Tag, val.Value = yaml.ScalarNode, "!!str", value
+		return
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+func removeKey(m *yaml.Node, key string) {
+	if idx := mappingIndex(m, key); idx >= 0 {
+		m.Content = append(m.Content[:idx], m.Content[idx+2:]...)
+	}
+}
+
+func deletedAt(def *yaml.Node) (time.Time, bool) {
+	val := mappingValue(def, deletedAtKey)
+	if val == nil {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, val.Value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
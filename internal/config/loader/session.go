@@ -0,0 +1,136 @@
+package loader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+)
+
+// SessionConfig 限定 profile 的分析/交易时段，由调度器在每轮分析前执行：
+// Windows 为 "HH:MM-HH:MM" 时段列表（按 Timezone，缺省 UTC；结束早于开始表示跨午夜），为空表示全天；
+// SkipWeekends 跳过周六、周日；SkipAfterOpenMinutes 跳过每日 00:00（Timezone）后的前 N 分钟。
+// 持仓中的 symbol 始终放行，以便模型管理已有仓位。
+type SessionConfig struct {
+	Windows              []string `mapstructure:"windows"`
+	Timezone             string   `mapstructure:"timezone"`
+	SkipWeekends         bool     `mapstructure:"skip_weekends"`
+	SkipAfterOpenMinutes int      `mapstructure:"skip_after_open_minutes"`
+
+	loc     *time.Location
+	windows []sessionWindow
+}
+
+type sessionWindow struct {
+	start, end int // 距当日 00:00 的分钟数
+}
+
+func (c *SessionConfig) normalize() {
+	if c == nil {
+		return
+	}
+	c.Timezone = strings.TrimSpace(c.Timezone)
+	c.loc = time.UTC
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			logger.Warnf("session.timezone=%s 无效，按 UTC 处理: %v", c.Timezone, err)
+		} else {
+			c.loc = loc
+		}
+	}
+	c.SkipAfterOpenMinutes = max(c.SkipAfterOpenMinutes, 0)
+	c.windows = nil
+	var valid []string
+	for _, raw := range c.Windows {
+		w, err := parseSessionWindow(raw)
+		if err != nil {
+			logger.Warnf("session.windows 忽略无效时段 %q: %v", raw, err)
+			continue
+		}
+		c.windows = append(c.windows, w)
+		valid = append(valid, strings.TrimSpace(raw))
+	}
+	c.Windows = valid
+}
+
+// Enabled 表示是否配置了任何时段限制。
+func (c SessionConfig) Enabled() bool {
+	return len(c.windows) > 0 || c.SkipWeekends || c.SkipAfterOpenMinutes > 0
+}
+
+// Check 判断 t 是否处于允许分析的时段，不允许时返回原因。
+func (c SessionConfig) Check(t time.Time) (bool, string) {
+	if !c.Enabled() {
+		return true, ""
+	}
+	loc := c.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	if c.SkipWeekends && (local.Weekday() == time.Saturday || local.Weekday() == time.Sunday) {
+		return false, "周末不交易"
+	}
+	minute := local.Hour()*60 + local.Minute()
+	if c.SkipAfterOpenMinutes > 0 && minute < c.SkipAfterOpenMinutes {
+		return false, fmt.Sprintf("日开盘后 %d 分钟内", c.SkipAfterOpenMinutes)
+	}
+	if len(c.windows) == 0 {
+		return true, ""
+	}
+	for _, w := range c.windows {
+		if w.contains(minute) {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("不在交易时段 %s（%s）", strings.Join(c.Windows, ","), loc.String())
+}
+
+func (w sessionWindow) contains(minute int) bool {
+	if w.start <= w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+func parseSessionWindow(raw string) (sessionWindow, error) {
+	parts := strings.Split(strings.TrimSpace(raw), "-")
+	if len(parts) != 2 {
+		return sessionWindow{}, fmt.Errorf("格式应为 HH:MM-HH:MM")
+	}
+	start, err := parseClockMinute(parts[0])
+	if err != nil {
+		return sessionWindow{}, err
+	}
+	end, err := parseClockMinute(parts[1])
+	if err != nil {
+		return sessionWindow{}, err
+	}
+	if start == end {
+		return sessionWindow{}, fmt.Errorf("开始与结束相同")
+	}
+	return sessionWindow{start: start, end: end}, nil
+}
+
+// parseClockMinute 解析 HH:MM（允许 24:00 表示当日结束）为分钟数。
+func parseClockMinute(raw string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok {
+		return 0, fmt.Errorf("时间 %q 格式应为 HH:MM", raw)
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil {
+		return 0, fmt.Errorf("时间 %q 小时无效", raw)
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("时间 %q 分钟无效", raw)
+	}
+	if h < 0 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("时间 %q 超出范围", raw)
+	}
+	return h*60 + m, nil
+}
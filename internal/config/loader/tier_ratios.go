@@ -0,0 +1,136 @@
+package loader
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"brale/internal/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 分段止盈/止损的默认比例，profile 未配置 exit_plans.tier_ratios 或配置无效时使用。
+const (
+	defaultTier1Ratio = 0.4
+	defaultTier2Ratio = 0.35
+	defaultTier3Ratio = 0.25
+
+	maxTierRatioCount  = 3
+	tierRatioTolerance = 1e-6
+)
+
+// TierRatioConfig 为分段止盈/止损的默认比例，Long/Short 分别作用于多/空仓；
+// 每组 1-3 段、各段位于 (0,1] 且合计为 1，留空的一侧使用默认 0.4/0.35/0.25。
+type TierRatioConfig struct {
+	Long  []float64 `mapstructure:"long" json:"long,omitempty"`
+	Short []float64 `mapstructure:"short" json:"short,omitempty"`
+}
+
+// DefaultTierRatios 返回内置的三段默认比例。
+func DefaultTierRatios() []float64 {
+	return []float64{defaultTier1Ratio, defaultTier2Ratio, defaultTier3Ratio}
+}
+
+// ValidateTierRatios 校验一组分段比例。
+func ValidateTierRatios(ratios []float64) error {
+	if len(ratios) == 0 || len(ratios) > maxTierRatioCount {
+		return fmt.Errorf("分段比例需为 1-%d 段，当前 %d 段", maxTierRatioCount, len(ratios))
+	}
+	sum := 0.0
+	for i, r := range ratios {
+		if r <= 0 || r > 1 {
+			return fmt.Errorf("第 %d 段比例 %.4f 需位于 (0,1]", i+1, r)
+		}
+		sum += r
+	}
+	if math.Abs(sum-1) > tierRatioTolerance {
+		return fmt.Errorf("分段比例合计需为 1.0，当前 %.4f", sum)
+	}
+	return nil
+}
+
+// Validate 校验已配置的一侧；留空的一侧视为使用默认值。
+func (c TierRatioConfig) Validate() error {
+	if len(c.Long) > 0 {
+		if err := ValidateTierRatios(c.Long); err != nil {
+			return fmt.Errorf("long: %w", err)
+		}
+	}
+	if len(c.Short) > 0 {
+		if err := ValidateTierRatios(c.Short); err != nil {
+			return fmt.Errorf("short: %w", err)
+		}
+	}
+	return nil
+}
+
+// Configured 表示 profile 显式配置了至少一侧的比例。
+func (c TierRatioConfig) Configured() bool {
+	return len(c.Long) > 0 || len(c.Short) > 0
+}
+
+// ForSide 返回 side（long/short）的分段比例，未配置时返回默认值。
+func (c TierRatioConfig) ForSide(side string) []float64 {
+	var ratios []float64
+	switch strings.ToLower(strings.TrimSpace(side)) {
+	case "long":
+		ratios = c.Long
+	case "short":
+		ratios = c.Short
+	}
+	if len(ratios) == 0 {
+		return DefaultTierRatios()
+	}
+	return append([]float64(nil), ratios...)
+}
+
+// normalize 丢弃无效的一侧并告警，使运行时始终拿到合法比例。
+func (c *TierRatioConfig) normalize(profile string) {
+	if len(c.Long) > 0 {
+		if err := ValidateTierRatios(c.Long); err != nil {
+			logger.Warnf("profile %s exit_plans.tier_ratios.long 无效，使用默认比例: %v", profile, err)
+			c.Long = nil
+		}
+	}
+	if len(c.Short) > 0 {
+		if err := ValidateTierRatios(c.Short); err != nil {
+			logger.Warnf("profile %s exit_plans.tier_ratios.short 无效，使用默认比例: %v", profile, err)
+			c.Short = nil
+		}
+	}
+}
+
+// SetTierRatios 把 profile 的 exit_plans.tier_ratios 写回 profiles.yaml 并触发重载；某一侧为空时删除该侧配置。
+func (l *ProfileLoader) SetTierRatios(name string, cfg TierRatioConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	return l.editDocument(func(doc *yaml.Node) error {
+		def := mappingValue(mappingValue(doc, "profiles"), name)
+		if def == nil {
+			return fmt.Errorf("profile %s 不存在", name)
+		}
+		ratios := ensureMapping(ensureMapping(def, "exit_plans"), "tier_ratios")
+		setFloatSequence(ratios, "long", cfg.Long)
+		setFloatSequence(ratios, "short", cfg.Short)
+		return nil
+	})
+}
+
+func setFloatSequence(m *yaml.Node, key string, values []float64) {
+	if len(values) == 0 {
+		removeKey(m, key)
+		return
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, v := range values {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(v, 'f', -1, 64)})
+	}
+	if idx := mappingIndex(m, key); idx >= 0 {
+		m.Content[idx+1] = seq
+		return
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, seq)
+}
@@ -0,0 +1,139 @@
+package loader
+
+import (
+	"math"
+	"strings"
+
+	"brale/internal/analysis/formula"
+	"brale/internal/pkg/maputil"
+)
+
+// 各类指标达到稳定所需的周期倍数：EMA 以 SMA 起算，1.2 倍周期后初值权重已可忽略；
+// RSI/ATR 为 Wilder 平滑（α=1/period），收敛更慢；MACD 在慢线之后还需叠加信号线平滑。
+const (
+	emaWarmupFactor    = 1.2
+	wilderWarmupFactor = 5.0
+	macdWarmupFactor   = 3.0
+)
+
+// 快照固定计算的指标（EMA21/50/200、RSI14、MACD12/26/9、ATR14、ADX14、Supertrend10），与 indicator.ComputeAll 的默认值保持一致。
+const (
+	snapshotEMASlow          = 200
+	snapshotRSIPeriod        = 14
+	snapshotATRPeriod        = 14
+	snapshotMACDSlow         = 26
+	snapshotMACDSignal       = 9
+	snapshotADXPeriod        = 14
+	snapshotSupertrendPeriod = 10
+)
+
+// defaultRSIDivergenceMaxGap 与 indicator.RSIDivergenceSettings 的默认枢轴最大间隔一致。
+const defaultRSIDivergenceMaxGap = 60
+
+// IndicatorWarmupBars 按 profile 中间件与快照指标的最大周期 × 对应倍数估算指标预热所需 K 线根数，
+// 同时作为拉取条数下限与分析前的历史充足性闸门。
+func (d ProfileDefinition) IndicatorWarmupBars() int {
+	need := max(
+		warmupEMA(snapshotEMASlow),
+		warmupWilder(snapshotRSIPeriod),
+		warmupWilder(snapshotATRPeriod),
+		warmupMACD(snapshotMACDSlow, snapshotMACDSignal),
+		warmupWilder(2*snapshotADXPeriod),
+		warmupWilder(snapshotSupertrendPeriod),
+	)
+	for _, mw := range d.Middlewares {
+		need = max(need, middlewareWarmup(mw))
+	}
+	return need
+}
+
+func middlewareWarmup(mw MiddlewareConfig) int {
+	switch strings.ToLower(strings.TrimSpace(mw.Name)) {
+	case "ema_trend":
+		return warmupEMA(max(maputil.Int(mw.Params, "fast"), maputil.Int(mw.Params, "mid"), maputil.Int(mw.Params, "slow")))
+	case "rsi_extreme":
+		return warmupWilder(maputil.Int(mw.Params, "period"))
+	case "macd_trend":
+		slow, signal := maputil.Int(mw.Params, "slow"), maputil.Int(mw.Params, "signal")
+		if slow <= 0 {
+			slow = snapshotMACDSlow
+		}
+		if signal <= 0 {
+			signal = snapshotMACDSignal
+		}
+		return warmupMACD(slow, signal)
+	case "bb_squeeze":
+		// KC 的 ATR 为 Wilder 平滑；带宽分位需要额外的回看窗口
+		kc, window := maputil.Int(mw.Params, "kc_period"), maputil.Int(mw.Params, "percentile_window")
+		if kc <= 0 {
+			kc = 20
+		}
+		if window <= 0 {
+			window = 120
+		}
+		return max(warmupWilder(kc), max(kc, maputil.Int(mw.Params, "bb_period"))+window)
+	case "adx_trend":
+		// ADX 在 DI 之上再做一次 Wilder 平滑
+		period := maputil.Int(mw.Params, "period")
+		if period <= 0 {
+			period = snapshotADXPeriod
+		}
+		return warmupWilder(2 * period)
+	case "supertrend":
+		period := maputil.Int(mw.Params, "period")
+		if period <= 0 {
+			period = snapshotSupertrendPeriod
+		}
+		return warmupWilder(period)
+	case "liquidity_sweep":
+		// 结构位去重依赖 ATR14，其预热已覆盖量能 z-score 的 20 根回看
+		return warmupWilder(snapshotATRPeriod)
+	case "rsi_divergence":
+		// 枢轴配对需在 RSI 收敛后再回看 max_gap 根
+		period, gap := maputil.Int(mw.Params, "period"), maputil.Int(mw.Params, "max_gap")
+		if period <= 0 {
+			period = snapshotRSIPeriod
+		}
+		if gap <= 0 {
+			gap = defaultRSIDivergenceMaxGap
+		}
+		return warmupWilder(period) + gap
+	case "custom_indicator":
+		// 嵌套函数沿调用链累加各自的预热根数，公式无效时由 factory 报错
+		program, err := formula.Compile(maputil.String(mw.Params, "formula"))
+		if err != nil {
+			return 0
+		}
+		return program.Warmup(func(kind string, period int) int {
+			switch kind {
+			case formula.WarmupEMA:
+				return warmupEMA(period)
+			case formula.WarmupWilder:
+				return warmupWilder(period)
+			default:
+				return period
+			}
+		})
+	default:
+		return 0
+	}
+}
+
+func warmupEMA(period int) int {
+	return scaledBars(period, emaWarmupFactor)
+}
+
+func warmupWilder(period int) int {
+	return scaledBars(period, wilderWarmupFactor)
+}
+
+func warmupMACD(slow, signal int) int {
+	return scaledBars(slow+signal, macdWarmupFactor)
+}
+
+func scaledBars(period int, factor float64) int {
+	if period <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(period) * factor))
+}
@@ -43,6 +43,17 @@ func (a AIConfig) ResolveModelConfigs() ([]ResolvedModelConfig, error) {
 		if raw.ExpectJSON != nil {
 			expectJSON = *raw.ExpectJSON
 		}
+		redact := strings.ToLower(strings.TrimSpace(raw.Redact))
+		if redact == "" {
+			redact = strings.ToLower(strings.TrimSpace(preset.Redact))
+		}
+		if redact == "none" || redact == "off" {
+			redact = ""
+		}
+		stream := preset.Stream
+		if raw.Stream != nil {
+			stream = *raw.Stream
+		}
 		out = append(out, ResolvedModelConfig{
 			ID:             strings.TrimSpace(raw.ID),
 			Provider:       strings.TrimSpace(raw.Provider),
@@ -54,6 +65,8 @@ func (a AIConfig) ResolveModelConfigs() ([]ResolvedModelConfig, error) {
 			Headers:        headers,
 			SupportsVision: supportsVision,
 			ExpectJSON:     expectJSON,
+			Redact:         redact,
+			Stream:         stream,
 		})
 	}
 	return out, nil
@@ -1,6 +1,9 @@
 package config
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type Config struct {
 	App       AppConfig       `toml:"app"`
@@ -12,6 +15,7 @@ type Config struct {
 	Prompt    PromptConfig    `toml:"prompt"`
 	Notify    NotifyConfig    `toml:"notify"`
 	Freqtrade FreqtradeConfig `toml:"freqtrade"`
+	Execution ExecutionConfig `toml:"execution"`
 	Advanced  AdvancedConfig  `toml:"advanced"`
 	Trading   TradingConfig   `toml:"trading"`
 }
@@ -23,16 +27,78 @@ type AppConfig struct {
 	LogPath  string `toml:"log_path"`
 	LLMLog   string `toml:"llm_log_path"`
 	LLMDump  bool   `toml:"llm_dump_payload"`
+	// Mode 为 active（默认）或 standby：standby 只维护行情/持仓并提供只读 API，不调用 LLM 与执行器，可通过接口提升为 active。
+	Mode string `toml:"mode"`
+}
+
+const (
+	AppModeActive  = "active"
+	AppModeStandby = "standby"
+)
+
+// IsStandby 表示实例以热备模式启动。
+func (a AppConfig) IsStandby() bool {
+	return strings.EqualFold(strings.TrimSpace(a.Mode), AppModeStandby)
 }
 
 type KlineConfig struct {
-	MaxCached int `toml:"max_cached"`
+	MaxCached  int                   `toml:"max_cached"`
+	Validation KlineValidationConfig `toml:"validation"`
+	Persist    KlinePersistConfig    `toml:"persist"`
+}
+
+// KlinePersistConfig 控制 K 线缓存落盘：启动时加载 Dir 下未超过 TTLHours 的快照并通过 REST 补齐缺口，
+// 每 FlushMinutes 分钟及关闭时写回；每个 symbol/interval 最多保存 MaxBars 根（0 表示使用 kline.max_cached），
+// 目录总大小超过 MaxTotalMB 时按修改时间删除最旧的快照。
+type KlinePersistConfig struct {
+	Enabled      bool   `toml:"enabled"`
+	Dir          string `toml:"dir"`
+	TTLHours     int    `toml:"ttl_hours"`
+	MaxBars      int    `toml:"max_bars"`
+	MaxTotalMB   int    `toml:"max_total_mb"`
+	FlushMinutes int    `toml:"flush_minutes"`
 }
 
+// KlineValidationConfig 控制 K 线写入缓存前的完整性校验：非正价格、low>high、开收盘价越界、已收盘零成交量、
+// 同批重复 open_time，以及收益率超过近 SigmaWindow 根标准差 MaxSigma 倍的单根极端波动会被隔离而不写入缓存；
+// Refetch 开启时隔离的 K 线会从 REST 重新拉取，REST 确认的数据不再做极端波动检查。
+type KlineValidationConfig struct {
+	Enabled     bool    `toml:"enabled"`
+	MaxSigma    float64 `toml:"max_sigma"`
+	SigmaWindow int     `toml:"sigma_window"`
+	Refetch     bool    `toml:"refetch"`
+}
+
+// StoreConfig 的 Driver 为 sqlite（默认，使用 LiveDBPath）或 postgres（使用 DSN，live 库与决策日志共用同一个库，便于多实例共享）。
 type StoreConfig struct {
-	LiveDBPath string `toml:"live_db_path"`
+	Driver     string          `toml:"driver"`
+	DSN        string          `toml:"dsn"`
+	LiveDBPath string          `toml:"live_db_path"`
+	Retention  RetentionConfig `toml:"retention"`
+}
+
+const (
+	StoreDriverSQLite   = "sqlite"
+	StoreDriverPostgres = "postgres"
+)
+
+// RetentionConfig 控制操作/事件类表的归档与清理，避免 SQLite 文件无限增长。
+// Tables 为 表名 -> 保留天数，0 表示该表不清理；过期记录先写入 ArchiveDir 再删除。
+// Backend=s3 时每批记录作为一个压缩对象上传到 S3 兼容存储，ArchiveDir 只保留对象索引。
+type RetentionConfig struct {
+	Enabled         bool             `toml:"enabled"`
+	IntervalMinutes int              `toml:"interval_minutes"`
+	ArchiveDir      string           `toml:"archive_dir"`
+	Format          string           `toml:"format"`
+	BatchSize       int              `toml:"batch_size"`
+	Tables          map[string]int   `toml:"tables"`
+	Backend         string           `toml:"backend"`
+	S3              S3ArtifactConfig `toml:"s3"`
 }
 
+// RetentionTables 为支持归档清理的表。
+var RetentionTables = []string{"trade_operation_log", "strategy_change_log", "event_log", "webhook_deliveries"}
+
 type MCPConfig struct {
 	TimeoutSeconds int `toml:"timeout_seconds"`
 }
@@ -44,12 +110,68 @@ type PromptConfig struct {
 
 type NotifyConfig struct {
 	Telegram TelegramConfig `toml:"telegram"`
+	Webhooks WebhooksConfig `toml:"webhooks"`
+	// Sinks 为 Discord/Slack/通用 JSON 推送通道，与 Telegram 一起接收开平仓、分批止盈、异常与行情连接状态等通知。
+	Sinks []NotifySinkConfig `toml:"sinks"`
+	// DailySummary 为每日交易总结推送。
+	DailySummary DailySummaryConfig `toml:"daily_summary"`
+}
+
+// DailySummaryConfig 控制每日交易总结：每天 UTC Hour 点汇总此前 24 小时内平仓的交易（开平仓价、止盈档位、盈亏、滑点），
+// 渲染为按 symbol 分组的 Markdown 并以 general 类别推送。
+type DailySummaryConfig struct {
+	Enabled bool `toml:"enabled"`
+	Hour    int  `toml:"hour"`
+}
+
+// NotifySinkTypes 为支持的推送通道类型。
+var NotifySinkTypes = []string{"discord", "slack", "generic"}
+
+// NotifyCategories 为推送通道可订阅的事件类别。
+var NotifyCategories = []string{"open", "close", "tier", "error", "ws_status", "general"}
+
+// NotifySinkConfig 为单个推送通道；Categories 为空表示接收全部类别，
+// Templates 以类别为键覆盖消息正文（Go text/template，"default" 为兜底），可用字段 .Category .Icon .Title .Text .Sections .Timestamp。
+type NotifySinkConfig struct {
+	Name           string            `toml:"name"`
+	Type           string            `toml:"type"`
+	URL            string            `toml:"url"`
+	Categories     []string          `toml:"categories"`
+	Templates      map[string]string `toml:"templates"`
+	Headers        map[string]string `toml:"headers"`
+	TimeoutSeconds int               `toml:"timeout_seconds"`
+}
+
+// WebhookEvents 为可推送给外部 webhook 的事件类型。
+var WebhookEvents = []string{"decision", "entry_fill", "tier_hit", "stop_hit", "divergence"}
+
+// WebhooksConfig 控制对外信号 webhook：事件以 JSON POST 到各 endpoint，
+// 配置 secret 时附带 HMAC-SHA256 签名，失败按指数退避重试，每次投递写入 webhook_deliveries。
+type WebhooksConfig struct {
+	Enabled        bool                    `toml:"enabled"`
+	Retries        int                     `toml:"retries"`
+	TimeoutSeconds int                     `toml:"timeout_seconds"`
+	QueueSize      int                     `toml:"queue_size"`
+	Endpoints      []WebhookEndpointConfig `toml:"endpoints"`
+}
+
+// WebhookEndpointConfig 为单个接收端；Events 为空表示接收全部事件。
+type WebhookEndpointConfig struct {
+	Name   string   `toml:"name"`
+	URL    string   `toml:"url"`
+	Secret string   `toml:"secret"`
+	Events []string `toml:"events"`
 }
 
 type TelegramConfig struct {
 	Enabled  bool   `toml:"enabled"`
 	BotToken string `toml:"bot_token"`
 	ChatID   string `toml:"chat_id"`
+	// Commands 开启长轮询命令监听（/positions、/close 等）；AllowedChatIDs 为允许下发命令的 chat，缺省仅 ChatID。
+	// AllowedUserIDs 为允许下发命令的 Telegram 用户，为空时只接受私聊命令（群组成员无法下发）。
+	Commands       bool     `toml:"commands"`
+	AllowedChatIDs []string `toml:"allowed_chat_ids"`
+	AllowedUserIDs []string `toml:"allowed_user_ids"`
 }
 
 type AdvancedConfig struct {
@@ -59,6 +181,143 @@ type AdvancedConfig struct {
 	MaxOpensPerCycle           int     `toml:"max_opens_per_cycle"`
 	PlanRefreshIntervalSeconds int     `toml:"plan_refresh_interval_seconds"`
 	VisualRenderConcurrency    int     `toml:"visual_render_concurrency"`
+	PipelineConcurrency        int     `toml:"pipeline_concurrency"`
+
+	PriceGuard        PriceGuardConfig        `toml:"price_guard"`
+	VolatilityBreaker VolatilityBreakerConfig `toml:"volatility_breaker"`
+	ContractCalendar  ContractCalendarConfig  `toml:"contract_calendar"`
+	SymbolRules       SymbolRulesConfig       `toml:"symbol_rules"`
+	Correlation       CorrelationConfig       `toml:"correlation"`
+	TrailingStop      TrailingStopConfig      `toml:"trailing_stop"`
+	AutoBreakeven     AutoBreakevenConfig     `toml:"auto_breakeven"`
+	ScaleIn           ScaleInConfig           `toml:"scale_in"`
+	EntryDrift        EntryDriftConfig        `toml:"entry_drift"`
+	VisualChart       VisualChartConfig       `toml:"visual_chart"`
+	Risk              RiskConfig              `toml:"risk"`
+}
+
+// RiskConfig 为开仓前的组合风控，任一限制超出时该开仓决策降级为 skip 并写入决策日志（stage=risk），各项为 0 表示不限制。
+// 名义价值按 保证金*杠杆 计算；Sectors 为 板块 -> symbol 列表，MaxSectorNotional 对每个板块分别生效；
+// DailyLossLimitUSD 按当日（UTC）已平仓交易的实现亏损计算。
+type RiskConfig struct {
+	MaxPositions      int                 `toml:"max_positions"`
+	MaxTotalNotional  float64             `toml:"max_total_notional"`
+	MaxSymbolNotional float64             `toml:"max_symbol_notional"`
+	MaxSectorNotional float64             `toml:"max_sector_notional"`
+	Sectors           map[string][]string `toml:"sectors"`
+	DailyLossLimitUSD float64             `toml:"daily_loss_limit_usd"`
+}
+
+// CorrelationConfig 控制相关性开仓闸门：每 RefreshMinutes 分钟用监控 symbol 最近 Lookback 根 Interval K 线的对数收益率
+// 计算两两皮尔逊相关系数；新开仓与任一同方向持仓的相关系数超过 Threshold 时，Action=block 拒绝该开仓，
+// Action=downsize 把保证金乘以 DownsizeRatio 后放行。
+type CorrelationConfig struct {
+	Enabled        bool    `toml:"enabled"`
+	Interval       string  `toml:"interval"`
+	Lookback       int     `toml:"lookback"`
+	Threshold      float64 `toml:"threshold"`
+	Action         string  `toml:"action"`
+	DownsizeRatio  float64 `toml:"downsize_ratio"`
+	RefreshMinutes int     `toml:"refresh_minutes"`
+}
+
+// TrailingStopConfig 控制分段止损的移动止损：止盈第一段成交后把未触发的止损段上移到保本价（LockMode=breakeven）
+// 或 entry+LockATRMultiplier*ATR（LockMode=atr），此后每个价格 tick 按 price-TrailATRMultiplier*ATR 继续上移（空头反向），
+// 单次移动幅度小于 MinStepPct 时忽略。
+type TrailingStopConfig struct {
+	Enabled            bool    `toml:"enabled"`
+	LockMode           string  `toml:"lock_mode"`
+	LockATRMultiplier  float64 `toml:"lock_atr_multiplier"`
+	TrailATRMultiplier float64 `toml:"trail_atr_multiplier"`
+	MinStepPct         float64 `toml:"min_step_pct"`
+}
+
+// AutoBreakevenConfig 控制保本+自动化：持仓价格相对开仓价的有利涨跌幅达到 ProfitPct，或有利偏移达到 ATRMultiplier 倍 ATR 时，
+// 把未触发的止损段移到 开仓价*(1±FeeBufferPct)（覆盖往返手续费），每笔交易只执行一次，调整以 auto_breakeven 写入 strategy_change_log。
+// 两个触发条件为 0 表示不检查该项。
+type AutoBreakevenConfig struct {
+	Enabled       bool    `toml:"enabled"`
+	ProfitPct     float64 `toml:"profit_pct"`
+	ATRMultiplier float64 `toml:"atr_multiplier"`
+	FeeBufferPct  float64 `toml:"fee_buffer_pct"`
+}
+
+// ScaleInConfig 控制分批加仓（DCA）：开仓决策中的 scale_in 价位由持仓监控盯价，到达后追加 forceenter（需在 freqtrade
+// 策略中开启 position_adjustment_enable），按成交价重算均价并按比例平移未触发的分段价位。每笔交易最多 MaxEntries 档。
+type ScaleInConfig struct {
+	Enabled    bool `toml:"enabled"`
+	MaxEntries int  `toml:"max_entries"`
+}
+
+// EntryDriftConfig 控制决策到下单之间的延迟预算与价格漂移守卫：以 sense 快照中的行情价（即模型看到的价格）为参考，
+// 下单前实时价向不利方向（多头上涨、空头下跌）偏离超过 MaxDriftPct 或 MaxDriftATR 倍 ATR 时，Action=abort 放弃开仓，
+// Action=resize 把保证金乘以 ResizeRatio 后放行。MaxLatencySeconds 从快照时刻起算，包含模型决策耗时，超过时直接放弃；
+// 未配置时默认 120 秒。各阈值显式设为 0 表示不检查该项。
+type EntryDriftConfig struct {
+	Enabled           bool    `toml:"enabled"`
+	MaxDriftPct       float64 `toml:"max_drift_pct"`
+	MaxDriftATR       float64 `toml:"max_drift_atr"`
+	Action            string  `toml:"action"`
+	ResizeRatio       float64 `toml:"resize_ratio"`
+	MaxLatencySeconds int     `toml:"max_latency_seconds"`
+}
+
+// VisualChartConfig 为发给视觉模型的 K 线图设置：Width/KlineHeight 为像素（0 使用 1600/600，副图按主图比例缩放），
+// Theme 为 dark（默认）或 light。
+type VisualChartConfig struct {
+	Width       int    `toml:"width"`
+	KlineHeight int    `toml:"kline_height"`
+	Theme       string `toml:"theme"`
+}
+
+// SymbolRulesConfig 控制交易所下单规则缓存：每 RefreshMinutes 分钟拉取一次 exchangeInfo（价格步长、数量步长、
+// 最小名义价值，execution.binance 启用或开启 leverage_brackets 时补充杠杆档位），执行前把决策中的止损/止盈/分段/加仓价格按价格步长取整、
+// 仓位按数量步长向下取整并按档位封顶杠杆，名义价值不足时放弃该开仓。Source 为空时使用当前行情源，否则为 market.sources 中的名称。
+type SymbolRulesConfig struct {
+	Enabled        bool   `toml:"enabled"`
+	Source         string `toml:"source"`
+	RefreshMinutes int    `toml:"refresh_minutes"`
+}
+
+// ContractCalendarConfig 控制交割合约的到期感知：到期前 EntryCutoffHours 内停止开仓，
+// 到期前 CloseBeforeHours 内强制平仓（换月由 profile 切换到下一期合约完成）。
+// Expiries 可按 symbol 覆盖到期时间（RFC3339），未配置时从 symbol 的 YYMMDD 后缀解析；永续合约不受影响。
+type ContractCalendarConfig struct {
+	EntryCutoffHours int               `toml:"entry_cutoff_hours"`
+	CloseBeforeHours int               `toml:"close_before_hours"`
+	Expiries         map[string]string `toml:"expiries"`
+}
+
+// PriceGuardConfig 控制止损/分段触发前的参考价交叉校验。
+// Reference 为 "index" 时使用当前行情源的指数价，否则视为 market.sources 中的行情源名称，取其最新成交价。
+// Consensus 为触发规则：tolerance 要求触发价与参考价偏离不超过 TolerancePct；both 要求参考价同样越过触发位；
+// median 以主/参考价的中位数重新判定是否越过触发位。参考价来自缓存：Stream 开启时订阅参考行情源的实时成交价，
+// 否则每 StreamMaxAgeSeconds/2 后台轮询一次；缓存过期超过 StreamMaxAgeSeconds 时触发校验才回退 REST 查询。DivergenceAlertPct>0 时两路价格偏离超过该比例会告警，同一 symbol 冷却 DivergenceCooldownMinutes。
+type PriceGuardConfig struct {
+	Enabled                   bool    `toml:"enabled"`
+	Reference                 string  `toml:"reference"`
+	Consensus                 string  `toml:"consensus"`
+	TolerancePct              float64 `toml:"tolerance_pct"`
+	FailOpen                  bool    `toml:"fail_open"`
+	TimeoutSeconds            int     `toml:"timeout_seconds"`
+	Stream                    bool    `toml:"stream"`
+	StreamMaxAgeSeconds       int     `toml:"stream_max_age_seconds"`
+	DivergenceAlertPct        float64 `toml:"divergence_alert_pct"`
+	DivergenceCooldownMinutes int     `toml:"divergence_cooldown_minutes"`
+}
+
+// VolatilityBreakerConfig 控制全市场极端波动熔断：已实现波动率超限的 symbol 占比达到 MarketWideRatio，
+// 或 5 分钟强平额超过 LiquidationUSD 时暂停所有 profile 的新开仓（平仓不受影响），
+// 恢复正常并持续 ResumeMinutes 后自动解除。
+type VolatilityBreakerConfig struct {
+	Enabled         bool    `toml:"enabled"`
+	Interval        string  `toml:"interval"`
+	Window          int     `toml:"window"`
+	MaxVolPct       float64 `toml:"max_vol_pct"`
+	MarketWideRatio float64 `toml:"market_wide_ratio"`
+	LiquidationUSD  float64 `toml:"liquidation_usd"`
+	ResumeMinutes   int     `toml:"resume_minutes"`
+	CheckSeconds    int     `toml:"check_seconds"`
 }
 
 type TradingConfig struct {
@@ -91,6 +350,68 @@ type FreqtradeConfig struct {
 	EntrySlipPct       float64 `toml:"entry_slip_pct"`
 	EntryTag           string  `toml:"entry_tag"`
 	StakeCurrency      string  `toml:"stake_currency"`
+	// StuckClosingMinutes 为 closing_* 状态无成交回报的最长容忍时间，超时后由巡检对账处理；0 表示关闭巡检。
+	StuckClosingMinutes int  `toml:"stuck_closing_minutes"`
+	ReissueStuckExit    bool `toml:"reissue_stuck_exit"`
+	// TradingMode 为 freqtrade 的交易模式：futures（默认，合约带杠杆）、spot（现货，无杠杆、不可开空）
+	// 或 margin（杠杆现货，允许开空）；profile 可通过 trading_mode 覆盖。
+	TradingMode string `toml:"trading_mode"`
+}
+
+const (
+	TradingModeFutures = "futures"
+	TradingModeSpot    = "spot"
+	TradingModeMargin  = "margin"
+)
+
+// NormalizeTradingMode 返回小写的交易模式，无法识别时返回空串。
+func NormalizeTradingMode(mode string) string {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case TradingModeFutures, TradingModeSpot, TradingModeMargin:
+		return m
+	default:
+		return ""
+	}
+}
+
+// ResolveTradingMode 返回生效的交易模式：override（profile 覆盖）优先，其次 freqtrade.trading_mode，缺省为 futures。
+func (f FreqtradeConfig) ResolveTradingMode(override string) string {
+	if m := NormalizeTradingMode(override); m != "" {
+		return m
+	}
+	if m := NormalizeTradingMode(f.TradingMode); m != "" {
+		return m
+	}
+	return TradingModeFutures
+}
+
+// TradingModeAllowsShort 表示该模式下可以开空（现货仅在 margin 模式借币做空）。
+func TradingModeAllowsShort(mode string) bool {
+	return NormalizeTradingMode(mode) != TradingModeSpot
+}
+
+// TradingModeUsesLeverage 表示该模式下下单携带杠杆；spot 的保证金即全部名义价值。
+func TradingModeUsesLeverage(mode string) bool {
+	return NormalizeTradingMode(mode) != TradingModeSpot
+}
+
+// ExecutionConfig 配置不经过 freqtrade 的直连交易所执行器，profile 通过 executor 字段选择。
+type ExecutionConfig struct {
+	Binance BinanceExecutionConfig `toml:"binance"`
+}
+
+type BinanceExecutionConfig struct {
+	Enabled        bool   `toml:"enabled"`
+	APIKey         string `toml:"api_key"`
+	SecretKey      string `toml:"secret_key"`
+	RESTBaseURL    string `toml:"rest_base_url"`
+	StakeCurrency  string `toml:"stake_currency"`
+	TimeoutSeconds int    `toml:"timeout_seconds"`
+	// VerifyFills 为 true 时用同一账户的 user-data stream 核对 freqtrade 回报的成交数量。
+	VerifyFills bool `toml:"verify_fills"`
+	// LeverageBrackets 为 true 时用 APIKey/SecretKey 拉取 Binance 杠杆档位（无需启用直连执行器），决策执行前按档位封顶杠杆、
+	// 按数量步长取整保证金，对 freqtrade 下单同样生效（freqtrade 对接 Binance 合约时开启）。
+	LeverageBrackets bool `toml:"leverage_brackets"`
 }
 
 type AIConfig struct {
@@ -106,7 +427,94 @@ type AIConfig struct {
 	Models                []AIModelConfig          `toml:"models"`
 	MultiAgent            MultiAgentConfig         `toml:"multi_agent"`
 	ProfilesPath          string                   `toml:"profiles_path"`
+	ProfileTrashDays      int                      `toml:"profile_trash_days"`
 	ExitPlanPath          string                   `toml:"exit_strategies_path"`
+	Artifacts             ArtifactStoreConfig      `toml:"artifacts"`
+	// DecisionTimeoutSeconds 为单轮决策（多 Agent + 最终模型，含重试退避）的总时限，超时后取消未完成的模型调用。
+	// 0 或未配置取默认值；负数（如 -1）表示不设时限。
+	DecisionTimeoutSeconds int `toml:"decision_timeout_seconds"`
+	// DecisionHistory 控制 prompt 中附带的同币种近期决策及其结果。
+	DecisionHistory DecisionHistoryConfig `toml:"decision_history"`
+	// StaleProfileCycles 为 profile 连续多少轮无成功决策时告警，0 关闭告警。
+	StaleProfileCycles int `toml:"stale_profile_cycles"`
+	// StaleData 为决策前的行情新鲜度闸门。
+	StaleData StaleDataConfig `toml:"stale_data"`
+	// Usage 控制模型 token 用量统计、费用估算与每日预算。
+	Usage UsageConfig `toml:"usage"`
+}
+
+// UsageConfig 按模型 id 与 UTC 自然日累计 token 用量，按 Prices 估算费用并写入决策日志库（model_usage_daily）。
+// 当日估算费用达到 DailyBudgetUSD（全部模型）或 ModelBudgetsUSD 中该模型的上限后拒绝调用并推送告警，0 或未配置表示不限。
+type UsageConfig struct {
+	Prices          map[string]ModelPrice `toml:"prices"`
+	DailyBudgetUSD  float64               `toml:"daily_budget_usd"`
+	ModelBudgetsUSD map[string]float64    `toml:"model_budgets_usd"`
+}
+
+// ModelPrice 为每百万 token 的价格（USD）。
+type ModelPrice struct {
+	InputPerMTok  float64 `toml:"input_per_mtok"`
+	OutputPerMTok float64 `toml:"output_per_mtok"`
+}
+
+// StaleDataConfig 在决策前检查各必需周期最新收盘 K 线的年龄，超过阈值视为陈旧。
+type StaleDataConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Mode 为 abort（中止本轮决策）或 degrade（照常决策但标记 data_stale_flag，并拦截陈旧 symbol 的开仓与退出计划调整）。
+	Mode string `toml:"mode"`
+	// MaxAgeMultiple 为默认阈值：K 线年龄超过周期时长的该倍数视为陈旧。
+	MaxAgeMultiple float64 `toml:"max_age_multiple"`
+	// MaxAgeSeconds 按周期覆盖阈值（秒），如 {"1h": 5400}。
+	MaxAgeSeconds map[string]int `toml:"max_age_seconds"`
+}
+
+const (
+	StaleDataModeAbort   = "abort"
+	StaleDataModeDegrade = "degrade"
+)
+
+// MaxAge 返回周期 interval（时长 dur）的陈旧阈值，按周期覆盖优先。
+func (c StaleDataConfig) MaxAge(interval string, dur time.Duration) time.Duration {
+	if sec, ok := c.MaxAgeSeconds[strings.ToLower(strings.TrimSpace(interval))]; ok && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	multiple := c.MaxAgeMultiple
+	if multiple <= 0 {
+		multiple = 2
+	}
+	return time.Duration(float64(dur) * multiple)
+}
+
+// DecisionHistoryConfig 在 prompt 中附带该币种最近 K 条非 hold 决策（方向、结果、时间），避免模型反复推荐同一失败形态。
+type DecisionHistoryConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Limit 为最多附带的决策条数。
+	Limit int `toml:"limit"`
+	// LookbackHours 只回看该时间窗口内的决策。
+	LookbackHours int `toml:"lookback_hours"`
+	// MaxChars 为该段落的字符上限，超出时丢弃较旧的条目。
+	MaxChars int `toml:"max_chars"`
+}
+
+// ArtifactStoreConfig 把决策日志中的大字段（prompt、模型输出、图片）移出 SQLite，压缩后存入文件系统或 S3 兼容存储。
+type ArtifactStoreConfig struct {
+	// Backend 为空表示内联存储；可选 fs / s3。
+	Backend  string           `toml:"backend"`
+	Dir      string           `toml:"dir"`
+	MinBytes int              `toml:"min_bytes"`
+	S3       S3ArtifactConfig `toml:"s3"`
+	// RetentionDays 为决策日志保留天数，过期行删除后回收不再被引用的 artifact；0 表示不清理。
+	RetentionDays int `toml:"retention_days"`
+}
+
+type S3ArtifactConfig struct {
+	Endpoint       string `toml:"endpoint"`
+	Bucket         string `toml:"bucket"`
+	Region         string `toml:"region"`
+	Prefix         string `toml:"prefix"`
+	AccessKey      string `toml:"access_key"`
+	SecretKey      string `toml:"secret_key"`
+	TimeoutSeconds int    `toml:"timeout_seconds"`
 }
 
 type ModelPreset struct {
@@ -115,6 +523,10 @@ type ModelPreset struct {
 	Headers        map[string]string `toml:"headers"`
 	SupportsVision bool              `toml:"supports_vision"`
 	ExpectJSON     bool              `toml:"expect_json"`
+	// Redact 为发往该提供方的 prompt 中账户金额的脱敏方式：strip / hash，留空不脱敏。
+	Redact string `toml:"redact"`
+	// Stream 以 SSE 流式接收 chat/completions 响应。
+	Stream bool `toml:"stream"`
 }
 
 type AIModelConfig struct {
@@ -130,6 +542,10 @@ type AIModelConfig struct {
 
 	SupportsVision *bool `toml:"supports_vision"`
 	ExpectJSON     *bool `toml:"expect_json"`
+	// Redact 覆盖 preset 的脱敏方式；填 none 可对单个模型关闭。
+	Redact string `toml:"redact"`
+	// Stream 覆盖 preset 的流式设置。
+	Stream *bool `toml:"stream"`
 }
 
 type ResolvedModelConfig struct {
@@ -143,6 +559,8 @@ type ResolvedModelConfig struct {
 	Headers        map[string]string
 	SupportsVision bool
 	ExpectJSON     bool
+	Redact         string
+	Stream         bool
 }
 
 type PersonaConfig struct {
@@ -163,6 +581,8 @@ type MultiAgentConfig struct {
 type MarketConfig struct {
 	ActiveSource string         `toml:"active_source"`
 	Sources      []MarketSource `toml:"sources"`
+	// MaxStreams 为单条组合 WS 连接允许的最大 stream 数，超出时按优先级淘汰。
+	MaxStreams int `toml:"max_streams"`
 }
 
 type MarketSource struct {
@@ -170,6 +590,8 @@ type MarketSource struct {
 	Enabled     bool        `toml:"enabled"`
 	RESTBaseURL string      `toml:"rest_base_url"`
 	Proxy       ProxyConfig `toml:"proxy"`
+	// WeightLimit 为每分钟 REST 权重上限（目前仅 binance 使用），<=0 使用交易所默认值。
+	WeightLimit int `toml:"weight_limit"`
 }
 
 type ProxyConfig struct {
@@ -2,10 +2,17 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 )
 
 func validate(c *Config) error {
+	if err := c.App.validate(); err != nil {
+		return err
+	}
 	if err := c.AI.validate(); err != nil {
 		return err
 	}
@@ -21,16 +28,76 @@ func validate(c *Config) error {
 	if err := c.Freqtrade.validate(); err != nil {
 		return err
 	}
+	if err := c.Execution.validate(); err != nil {
+		return err
+	}
 	if err := c.Trading.validate(); err != nil {
 		return err
 	}
+	if err := c.Advanced.PriceGuard.validate(c.Market); err != nil {
+		return err
+	}
+	if err := c.Advanced.VolatilityBreaker.validate(); err != nil {
+		return err
+	}
+	if err := c.Advanced.Risk.validate(); err != nil {
+		return err
+	}
+	if err := c.Advanced.Correlation.validate(); err != nil {
+		return err
+	}
+	if err := c.Advanced.EntryDrift.validate(); err != nil {
+		return err
+	}
+	if err := c.Advanced.ContractCalendar.validate(); err != nil {
+		return err
+	}
+	if err := c.Advanced.VisualChart.validate(); err != nil {
+		return err
+	}
+	if err := c.Advanced.TrailingStop.validate(); err != nil {
+		return err
+	}
+	if err := c.Advanced.AutoBreakeven.validate(); err != nil {
+		return err
+	}
+	if err := c.Store.validate(); err != nil {
+		return err
+	}
+	if err := c.Store.Retention.validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
+func (a *AppConfig) validate() error {
+	switch strings.ToLower(strings.TrimSpace(a.Mode)) {
+	case "", AppModeActive, AppModeStandby:
+		return nil
+	default:
+		return fmt.Errorf("app.mode must be active or standby, got %q", a.Mode)
+	}
+}
+
 func (a *AIConfig) validate() error {
 	if a.DecisionOffsetSeconds < 0 {
 		return fmt.Errorf("ai.decision_offset_seconds must be >= 0")
 	}
+	if a.StaleProfileCycles < 0 {
+		return fmt.Errorf("ai.stale_profile_cycles must be >= 0")
+	}
+	if err := a.Artifacts.validate(); err != nil {
+		return err
+	}
+	if err := a.StaleData.validate(); err != nil {
+		return err
+	}
+	if err := a.Usage.validate(); err != nil {
+		return err
+	}
+	if h := a.DecisionHistory; h.Limit < 0 || h.LookbackHours < 0 || h.MaxChars < 0 {
+		return fmt.Errorf("ai.decision_history.limit/lookback_hours/max_chars must be >= 0")
+	}
 	models, err := a.ResolveModelConfigs()
 	if err != nil {
 		return err
@@ -50,6 +117,11 @@ func (a *AIConfig) validate() error {
 		if strings.TrimSpace(m.Provider) == "" {
 			return fmt.Errorf("ai.models.%s missing provider", m.ID)
 		}
+		switch m.Redact {
+		case "", "strip", "hash":
+		default:
+			return fmt.Errorf("ai.models.%s redact must be strip/hash/none, got %q", m.ID, m.Redact)
+		}
 	}
 	if len(a.ProviderPreference) > 0 {
 		for _, id := range a.ProviderPreference {
@@ -150,6 +222,12 @@ func (k *KlineConfig) validate() error {
 	if k.MaxCached < 50 || k.MaxCached > 1000 {
 		return fmt.Errorf("kline.max_cached must be in [50,1000]")
 	}
+	if k.Validation.Enabled && k.Validation.SigmaWindow < 20 {
+		return fmt.Errorf("kline.validation.sigma_window must be >= 20")
+	}
+	if k.Persist.Enabled && k.Persist.MaxBars < 0 {
+		return fmt.Errorf("kline.persist.max_bars must be >= 0")
+	}
 	return nil
 }
 
@@ -191,6 +269,96 @@ func (n *NotifyConfig) validate() error {
 			return fmt.Errorf("telegram notification enabled but missing bot_token or chat_id")
 		}
 	}
+	if n.Telegram.Commands {
+		if !n.Telegram.Enabled {
+			return fmt.Errorf("notify.telegram.commands requires notify.telegram.enabled")
+		}
+		for _, id := range n.Telegram.AllowedChatIDs {
+			if _, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err != nil {
+				return fmt.Errorf("notify.telegram.allowed_chat_ids 无效: %s", id)
+			}
+		}
+		for _, id := range n.Telegram.AllowedUserIDs {
+			if _, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err != nil {
+				return fmt.Errorf("notify.telegram.allowed_user_ids 无效: %s", id)
+			}
+		}
+	}
+	for _, sink := range n.Sinks {
+		if err := sink.validate(); err != nil {
+			return err
+		}
+	}
+	if n.DailySummary.Hour < 0 || n.DailySummary.Hour > 23 {
+		return fmt.Errorf("notify.daily_summary.hour must be within [0,23], got %d", n.DailySummary.Hour)
+	}
+	return n.Webhooks.validate()
+}
+
+func (s *NotifySinkConfig) validate() error {
+	if !slices.Contains(NotifySinkTypes, s.Type) {
+		return fmt.Errorf("notify.sinks[%s].type must be one of %v, got %q", s.Name, NotifySinkTypes, s.Type)
+	}
+	if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return fmt.Errorf("notify.sinks[%s].url must be http(s), got %q", s.Name, s.URL)
+	}
+	for _, cat := range s.Categories {
+		if !slices.Contains(NotifyCategories, cat) {
+			return fmt.Errorf("notify.sinks[%s] unknown category %s", s.Name, cat)
+		}
+	}
+	for key, text := range s.Templates {
+		if key != "default" && !slices.Contains(NotifyCategories, key) {
+			return fmt.Errorf("notify.sinks[%s].templates unknown category %s", s.Name, key)
+		}
+		if _, err := template.New(key).Parse(text); err != nil {
+			return fmt.Errorf("notify.sinks[%s].templates[%s] 解析失败: %w", s.Name, key, err)
+		}
+	}
+	return nil
+}
+
+func (w *WebhooksConfig) validate() error {
+	if !w.Enabled {
+		return nil
+	}
+	if w.Retries < 0 {
+		return fmt.Errorf("notify.webhooks.retries must be >= 0")
+	}
+	if len(w.Endpoints) == 0 {
+		return fmt.Errorf("notify.webhooks enabled but no endpoints configured")
+	}
+	for _, ep := range w.Endpoints {
+		if !strings.HasPrefix(ep.URL, "http://") && !strings.HasPrefix(ep.URL, "https://") {
+			return fmt.Errorf("notify.webhooks.endpoints[%s].url must be http(s), got %q", ep.Name, ep.URL)
+		}
+		for _, evt := range ep.Events {
+			known := false
+			for _, name := range WebhookEvents {
+				if evt == name {
+					known = true
+					break
+				}
+			}
+			if !known {
+				return fmt.Errorf("notify.webhooks.endpoints[%s] unknown event %s", ep.Name, evt)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *ExecutionConfig) validate() error {
+	b := e.Binance
+	if !b.Enabled && !b.LeverageBrackets {
+		return nil
+	}
+	if strings.TrimSpace(b.APIKey) == "" || strings.TrimSpace(b.SecretKey) == "" {
+		if !b.Enabled {
+			return fmt.Errorf("execution.binance.leverage_brackets requires api_key and secret_key")
+		}
+		return fmt.Errorf("execution.binance requires api_key and secret_key")
+	}
 	return nil
 }
 
@@ -218,6 +386,12 @@ func (f *FreqtradeConfig) validate() error {
 	if f.EntrySlipPct < 0 {
 		return fmt.Errorf("freqtrade.entry_slip_pct must be >= 0")
 	}
+	if f.StuckClosingMinutes < 0 {
+		return fmt.Errorf("freqtrade.stuck_closing_minutes must be >= 0")
+	}
+	if NormalizeTradingMode(f.TradingMode) == "" {
+		return fmt.Errorf("freqtrade.trading_mode must be futures, spot or margin")
+	}
 	return nil
 }
 
@@ -237,6 +411,200 @@ func (t *TradingConfig) validate() error {
 	return nil
 }
 
+func (p *PriceGuardConfig) validate(m MarketConfig) error {
+	if !p.Enabled {
+		return nil
+	}
+	if p.TolerancePct <= 0 || p.TolerancePct > 0.2 {
+		return fmt.Errorf("advanced.price_guard.tolerance_pct must be in (0, 0.2]")
+	}
+	switch p.Consensus {
+	case "tolerance", "both", "median":
+	default:
+		return fmt.Errorf("advanced.price_guard.consensus must be tolerance/both/median, got %s", p.Consensus)
+	}
+	if p.DivergenceAlertPct < 0 || p.DivergenceAlertPct > 0.2 {
+		return fmt.Errorf("advanced.price_guard.divergence_alert_pct must be in [0, 0.2]")
+	}
+	if p.Reference == "index" {
+		if p.Stream {
+			return fmt.Errorf("advanced.price_guard.stream requires reference to be a market source")
+		}
+		return nil
+	}
+	for _, src := range m.Sources {
+		if strings.EqualFold(strings.TrimSpace(src.Name), p.Reference) {
+			return nil
+		}
+	}
+	return fmt.Errorf("advanced.price_guard.reference must be 'index' or a configured market source, got %s", p.Reference)
+}
+
+func (v *VolatilityBreakerConfig) validate() error {
+	if !v.Enabled {
+		return nil
+	}
+	if !IsValidInterval(v.Interval) {
+		return fmt.Errorf("advanced.volatility_breaker.interval invalid: %s", v.Interval)
+	}
+	if v.MarketWideRatio <= 0 || v.MarketWideRatio > 1 {
+		return fmt.Errorf("advanced.volatility_breaker.market_wide_ratio must be in (0, 1]")
+	}
+	if v.LiquidationUSD < 0 {
+		return fmt.Errorf("advanced.volatility_breaker.liquidation_usd must be >= 0")
+	}
+	return nil
+}
+
+func (c *CorrelationConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if !IsValidInterval(c.Interval) {
+		return fmt.Errorf("advanced.correlation.interval invalid: %s", c.Interval)
+	}
+	if c.Action != "block" && c.Action != "downsize" {
+		return fmt.Errorf("advanced.correlation.action must be 'block' or 'downsize', got %s", c.Action)
+	}
+	return nil
+}
+
+func (c *EntryDriftConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Action != "abort" && c.Action != "resize" {
+		return fmt.Errorf("advanced.entry_drift.action must be 'abort' or 'resize', got %s", c.Action)
+	}
+	if c.MaxDriftPct < 0 || c.MaxDriftATR < 0 || c.MaxLatencySeconds < 0 {
+		return fmt.Errorf("advanced.entry_drift thresholds must be >= 0")
+	}
+	if c.MaxDriftPct == 0 && c.MaxDriftATR == 0 && c.MaxLatencySeconds == 0 {
+		return fmt.Errorf("advanced.entry_drift requires max_drift_pct, max_drift_atr or max_latency_seconds")
+	}
+	return nil
+}
+
+func (c *ContractCalendarConfig) validate() error {
+	if c.EntryCutoffHours < 0 || c.CloseBeforeHours < 0 {
+		return fmt.Errorf("advanced.contract_calendar hours must be >= 0")
+	}
+	for sym, raw := range c.Expiries {
+		if _, err := time.Parse(time.RFC3339, strings.TrimSpace(raw)); err != nil {
+			return fmt.Errorf("advanced.contract_calendar.expiries.%s must be RFC3339: %w", sym, err)
+		}
+	}
+	return nil
+}
+
+func (r *RiskConfig) validate() error {
+	if r.MaxPositions < 0 || r.MaxTotalNotional < 0 || r.MaxSymbolNotional < 0 || r.MaxSectorNotional < 0 || r.DailyLossLimitUSD < 0 {
+		return fmt.Errorf("advanced.risk limits must be >= 0")
+	}
+	seen := make(map[string]string)
+	for sector, symbols := range r.Sectors {
+		for _, sym := range symbols {
+			sym = strings.ToUpper(strings.TrimSpace(sym))
+			if prev, ok := seen[sym]; ok && prev != sector {
+				return fmt.Errorf("advanced.risk.sectors: %s 同时属于 %s 与 %s", sym, prev, sector)
+			}
+			seen[sym] = sector
+		}
+	}
+	return nil
+}
+
+func (t *TrailingStopConfig) validate() error {
+	if !t.Enabled {
+		return nil
+	}
+	switch t.LockMode {
+	case "breakeven", "atr", "none":
+	default:
+		return fmt.Errorf("advanced.trailing_stop.lock_mode must be breakeven, atr or none, got %s", t.LockMode)
+	}
+	if t.LockATRMultiplier < 0 || t.TrailATRMultiplier < 0 || t.MinStepPct < 0 {
+		return fmt.Errorf("advanced.trailing_stop multipliers must be >= 0")
+	}
+	return nil
+}
+
+func (b *AutoBreakevenConfig) validate() error {
+	if !b.Enabled {
+		return nil
+	}
+	if b.ProfitPct < 0 || b.ATRMultiplier < 0 || b.FeeBufferPct < 0 {
+		return fmt.Errorf("advanced.auto_breakeven thresholds must be >= 0")
+	}
+	if b.ProfitPct == 0 && b.ATRMultiplier == 0 {
+		return fmt.Errorf("advanced.auto_breakeven requires profit_pct or atr_multiplier")
+	}
+	return nil
+}
+
+func (v *VisualChartConfig) validate() error {
+	switch strings.ToLower(strings.TrimSpace(v.Theme)) {
+	case "", "dark", "light":
+	default:
+		return fmt.Errorf("advanced.visual_chart.theme must be dark or light, got %s", v.Theme)
+	}
+	if v.Width < 0 || v.KlineHeight < 0 {
+		return fmt.Errorf("advanced.visual_chart width/kline_height must be >= 0")
+	}
+	return nil
+}
+
+func (s *StoreConfig) validate() error {
+	switch s.Driver {
+	case "", StoreDriverSQLite:
+		return nil
+	case StoreDriverPostgres:
+		if strings.TrimSpace(s.DSN) == "" {
+			return fmt.Errorf("store.dsn is required when store.driver is postgres")
+		}
+		return nil
+	default:
+		return fmt.Errorf("store.driver must be sqlite or postgres, got %s", s.Driver)
+	}
+}
+
+func (r *RetentionConfig) validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	if r.Format != "jsonl" && r.Format != "csv" {
+		return fmt.Errorf("store.retention.format must be jsonl or csv, got %s", r.Format)
+	}
+	if strings.TrimSpace(r.ArchiveDir) == "" {
+		return fmt.Errorf("store.retention.archive_dir is required")
+	}
+	switch r.Backend {
+	case "":
+	case "s3":
+		if strings.TrimSpace(r.S3.Endpoint) == "" || strings.TrimSpace(r.S3.Bucket) == "" {
+			return fmt.Errorf("store.retention.s3 requires endpoint and bucket")
+		}
+	default:
+		return fmt.Errorf("store.retention.backend must be empty or s3, got %q", r.Backend)
+	}
+	for name, days := range r.Tables {
+		known := false
+		for _, t := range RetentionTables {
+			if t == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("store.retention.tables contains unsupported table: %s", name)
+		}
+		if days < 0 {
+			return fmt.Errorf("store.retention.tables.%s must be >= 0", name)
+		}
+	}
+	return nil
+}
+
 func IsValidInterval(s string) bool {
 	if s == "" {
 		return false
@@ -252,3 +620,71 @@ func IsValidInterval(s string) bool {
 	}
 	return true
 }
+
+func (c *ArtifactStoreConfig) validate() error {
+	if c.RetentionDays < 0 {
+		return fmt.Errorf("ai.artifacts.retention_days must be >= 0")
+	}
+	switch c.Backend {
+	case "":
+		return nil
+	case "fs":
+		if strings.TrimSpace(c.Dir) == "" {
+			return fmt.Errorf("ai.artifacts.dir cannot be empty when backend=fs")
+		}
+	case "s3":
+		if strings.TrimSpace(c.S3.Endpoint) == "" || strings.TrimSpace(c.S3.Bucket) == "" {
+			return fmt.Errorf("ai.artifacts.s3 requires endpoint and bucket")
+		}
+	default:
+		return fmt.Errorf("ai.artifacts.backend must be fs or s3, got %q", c.Backend)
+	}
+	if c.MinBytes < 0 {
+		return fmt.Errorf("ai.artifacts.min_bytes must be >= 0")
+	}
+	return nil
+}
+
+func (c StaleDataConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Mode {
+	case StaleDataModeAbort, StaleDataModeDegrade:
+	default:
+		return fmt.Errorf("ai.stale_data.mode must be abort or degrade, got %q", c.Mode)
+	}
+	if c.MaxAgeMultiple < 0 {
+		return fmt.Errorf("ai.stale_data.max_age_multiple must be >= 0")
+	}
+	for iv, sec := range c.MaxAgeSeconds {
+		if sec < 0 {
+			return fmt.Errorf("ai.stale_data.max_age_seconds.%s must be >= 0", iv)
+		}
+	}
+	return nil
+}
+
+func (u UsageConfig) validate() error {
+	if u.DailyBudgetUSD < 0 {
+		return fmt.Errorf("ai.usage.daily_budget_usd must be >= 0")
+	}
+	if u.DailyBudgetUSD > 0 && len(u.Prices) == 0 {
+		return fmt.Errorf("ai.usage.daily_budget_usd requires ai.usage.prices")
+	}
+	for id, p := range u.Prices {
+		if p.InputPerMTok < 0 || p.OutputPerMTok < 0 {
+			return fmt.Errorf("ai.usage.prices.%s must be >= 0", id)
+		}
+	}
+	for id, b := range u.ModelBudgetsUSD {
+		if b < 0 {
+			return fmt.Errorf("ai.usage.model_budgets_usd.%s must be >= 0", id)
+		}
+		// 未定价模型的估算费用恒为 0，预算永远不会触发。
+		if p := u.Prices[id]; b > 0 && p.InputPerMTok <= 0 && p.OutputPerMTok <= 0 {
+			return fmt.Errorf("ai.usage.model_budgets_usd.%s requires a non-zero ai.usage.prices.%s", id, id)
+		}
+	}
+	return nil
+}
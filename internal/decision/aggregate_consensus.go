@@ -0,0 +1,287 @@
+package decision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const defaultConsensusAgreement = 0.5
+
+// ConsensusSpec 为 profile 级共识配置，随 ProfilePromptSpec 传入。
+type ConsensusSpec struct {
+	Enabled      bool
+	MinAgreement float64
+	Weights      map[string]float64
+}
+
+// ConsensusAggregator 对同一 prompt 的多模型输出按 symbol 计票：加权占比最高且不低于 MinAgreement 的动作胜出（平票视为未达成共识）；
+// 胜出动作的 stop_loss/take_profit/leverage/position_size_usd/confidence 与同构 exit_plan 的 tier 价格按权重取平均；
+// 各模型的分歧写入 MetaSummary，随 final 决策落库。
+type ConsensusAggregator struct {
+	Weights      map[string]float64
+	Preference   []string
+	MinAgreement float64
+}
+
+func (a ConsensusAggregator) Name() string { return "consensus" }
+
+func (a ConsensusAggregator) Aggregate(ctx context.Context, outputs []ModelOutput) (ModelOutput, error) {
+	tally := MetaAggregator{Weights: a.Weights, Preference: a.Preference}.tallyMetaVotes(outputs)
+	if len(tally.votes) == 0 || tally.totalWeight == 0 {
+		return ModelOutput{}, errors.New("无可用的模型输出")
+	}
+	agreement := a.MinAgreement
+	if agreement <= 0 || agreement > 1 {
+		agreement = defaultConsensusAgreement
+	}
+	threshold := tally.totalWeight * agreement
+	prefIndex := buildPreferenceIndex(a.Preference)
+	breakdown := buildMetaVoteBreakdown(tally.votes, tally.details, threshold, tally.totalWeight)
+
+	decisions := make([]Decision, 0)
+	winners := make(map[string]float64)
+	finals := make(map[string]string)
+	for _, sym := range metaSymbolsFromVotes(tally.votes) {
+		act, ok := consensusAction(tally.votes[sym], threshold)
+		if !ok {
+			finals[sym] = "hold"
+			continue
+		}
+		finals[sym] = act
+		if act == "hold" {
+			continue
+		}
+		choices := tally.details[sym][act]
+		d := averageConsensusDecision(pickDecision(choices, act, sym, prefIndex), choices)
+		decisions = append(decisions, d)
+		addWinnersForAction(winners, choices, act)
+	}
+	if bd := breakdown; bd != nil {
+		for i := range bd.Symbols {
+			bd.Symbols[i].FinalAction = strings.ToUpper(finals[bd.Symbols[i].Symbol])
+		}
+	}
+	if len(decisions) == 0 {
+		decisions = []Decision{{Action: "hold", Reasoning: "模型未达成共识，保持观望。"}}
+	}
+	res := DecisionResult{
+		Decisions:     decisions,
+		MetaSummary:   consensusSummary(outputs, tally, finals, agreement),
+		MetaBreakdown: breakdown,
+	}
+	best := buildMetaOutput(outputs, res, winners, prefIndex)
+	best.ProviderID = "consensus"
+	return best, nil
+}
+
+// consensusAction 返回加权最高且达到阈值的动作；最高权重并列时不产生共识。
+func consensusAction(actions map[string]float64, threshold float64) (string, bool) {
+	best, bestWeight, tie := "", -1.0, false
+	for act, w := range actions {
+		switch {
+		case w > bestWeight:
+			best, bestWeight, tie = act, w, false
+		case w == bestWeight:
+			tie = true
+		}
+	}
+	if best == "" || tie || bestWeight < threshold {
+		return "", false
+	}
+	return best, true
+}
+
+func averageConsensusDecision(base Decision, choices []metaChoice) Decision {
+	avg := func(get func(Decision) float64) float64 {
+		sum, weight := 0.0, 0.0
+		for _, c := range choices {
+			if v := get(c.Decision); v > 0 {
+				sum += v * c.Weight
+				weight += c.Weight
+			}
+		}
+		if weight == 0 {
+			return 0
+		}
+		return sum / weight
+	}
+	if v := avg(func(d Decision) float64 { return d.StopLoss }); v > 0 {
+		base.StopLoss = v
+	}
+	if v := avg(func(d Decision) float64 { return d.TakeProfit }); v > 0 {
+		base.TakeProfit = v
+	}
+	if v := avg(func(d Decision) float64 { return d.PositionSizeUSD }); v > 0 {
+		base.PositionSizeUSD = v
+	}
+	if v := avg(func(d Decision) float64 { return float64(d.Leverage) }); v > 0 {
+		base.Leverage = int(math.Round(v))
+	}
+	if v := avg(func(d Decision) float64 { return float64(d.Confidence) }); v > 0 {
+		base.Confidence = int(math.Round(v))
+	}
+	if v := avg(func(d Decision) float64 { return d.CloseRatio }); v > 0 {
+		base.CloseRatio = v
+	}
+	if base.ExitPlan != nil {
+		base.ExitPlan = averageExitPlan(base.ExitPlan, choices)
+	}
+	return base
+}
+
+// averageExitPlan 对与 base 同 ID、同结构的 exit_plan 逐个 target_price 取加权平均；结构不同的计划不参与该位置的平均。
+func averageExitPlan(base *ExitPlanSpec, choices []metaChoice) *ExitPlanSpec {
+	params, ok := cloneJSONValue(base.Params).(map[string]any)
+	if !ok {
+		return base
+	}
+	var others []any
+	var weights []float64
+	for _, c := range choices {
+		plan := c.Decision.ExitPlan
+		if plan == nil || !strings.EqualFold(strings.TrimSpace(plan.ID), strings.TrimSpace(base.ID)) {
+			continue
+		}
+		others = append(others, any(plan.Params))
+		weights = append(weights, c.Weight)
+	}
+	if len(others) < 2 {
+		return base
+	}
+	averageTargetPrices(params, others, weights)
+	out := *base
+	out.Params = params
+	return &out
+}
+
+func averageTargetPrices(node any, others []any, weights []float64) {
+	switch v := node.(type) {
+	case map[string]any:
+		for key, child := range v {
+			var subs []any
+			var subWeights []float64
+			for i, o := range others {
+				om, ok := asParamMap(o)
+				if !ok {
+					continue
+				}
+				if oc, ok := om[key]; ok {
+					subs = append(subs, oc)
+					subWeights = append(subWeights, weights[i])
+				}
+			}
+			if key == "target_price" {
+				if avg, ok := weightedNumber(subs, subWeights); ok {
+					v[key] = avg
+				}
+				continue
+			}
+			averageTargetPrices(child, subs, subWeights)
+		}
+	case []any:
+		for idx, child := range v {
+			var subs []any
+			var subWeights []float64
+			for i, o := range others {
+				os, ok := o.([]any)
+				if !ok || len(os) != len(v) {
+					continue
+				}
+				subs = append(subs, os[idx])
+				subWeights = append(subWeights, weights[i])
+			}
+			averageTargetPrices(child, subs, subWeights)
+		}
+	}
+}
+
+func asParamMap(v any) (map[string]any, bool) {
+	m, ok := v.(map[string]any)
+	return m, ok
+}
+
+func weightedNumber(values []any, weights []float64) (float64, bool) {
+	sum, total := 0.0, 0.0
+	for i, raw := range values {
+		var f float64
+		switch n := raw.(type) {
+		case float64:
+			f = n
+		case int:
+			f = float64(n)
+		default:
+			continue
+		}
+		if f <= 0 {
+			continue
+		}
+		sum += f * weights[i]
+		total += weights[i]
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return sum / total, true
+}
+
+func cloneJSONValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[k] = cloneJSONValue(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = cloneJSONValue(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// consensusSummary 输出每个 symbol 的计票与持不同意见的模型，以及调用失败的模型。
+func consensusSummary(outputs []ModelOutput, tally metaTally, finals map[string]string, agreement float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "共识模式 阈值=%.0f%% 总权重=%.2f", agreement*100, tally.totalWeight)
+	for _, sym := range metaSymbolsFromVotes(tally.votes) {
+		final := finals[sym]
+		votes := metaActionVoteList(tally.votes[sym])
+		parts := make([]string, 0, len(votes))
+		for _, v := range votes {
+			parts = append(parts, fmt.Sprintf("%s=%.2f", v.Action, v.Weight))
+		}
+		fmt.Fprintf(&b, "\n%s → %s (%s)", sym, strings.ToUpper(final), strings.Join(parts, ", "))
+		var dissent []string
+		for act, choices := range tally.details[sym] {
+			if act == final {
+				continue
+			}
+			for _, c := range choices {
+				dissent = append(dissent, c.ID+"="+act)
+			}
+		}
+		if final != "hold" {
+			for _, c := range tally.details[holdSymbolKey]["hold"] {
+				dissent = append(dissent, c.ID+"=hold")
+			}
+		}
+		if len(dissent) > 0 {
+			sort.Strings(dissent)
+			fmt.Fprintf(&b, " 分歧: %s", strings.Join(dissent, ", "))
+		}
+	}
+	for _, o := range outputs {
+		if o.Err != nil {
+			fmt.Fprintf(&b, "\n模型 %s 未参与: %v", o.ProviderID, o.Err)
+		}
+	}
+	return b.String()
+}
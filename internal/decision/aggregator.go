@@ -2,6 +2,7 @@ package decision
 
 import (
 	"context"
+	"time"
 
 	"brale/internal/gateway/provider"
 )
@@ -18,6 +19,7 @@ type ModelOutput struct {
 	Images        []provider.ImagePayload // Vision inputs (chart screenshots)
 	VisionEnabled bool
 	ImageCount    int
+	Latency       time.Duration // 模型调用耗时（不含解析）
 }
 
 // Aggregator combines outputs from multiple LLM providers into a final decision.
@@ -2,14 +2,18 @@ package decision
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"brale/internal/analysis/indicator"
 	"brale/internal/analysis/pattern"
+	"brale/internal/analysis/screen"
 	"brale/internal/analysis/visual"
 	"brale/internal/logger"
 	"brale/internal/market"
@@ -28,6 +32,12 @@ type AnalysisContext struct {
 	ImageB64        string `json:"image_base64"`
 	ImageNote       string `json:"image_note"`
 	ForecastHorizon string `json:"forecast_horizon"`
+	// Composite 为该 symbol 跨周期的综合评分，同一 symbol 的各周期上下文共享同一份。
+	Composite *screen.Composite `json:"composite,omitempty"`
+	// DistanceUnits 为快照价差字段的单位（absolute/atr/both），趋势结构块沿用同一设置。
+	DistanceUnits string `json:"distance_units,omitempty"`
+	// Confluence 为该 symbol 跨周期的多空共振分，与 Composite 一样由同一 symbol 的各周期共享。
+	Confluence *screen.Confluence `json:"confluence,omitempty"`
 }
 
 type AnalysisBuildInput struct {
@@ -43,6 +53,17 @@ type AnalysisBuildInput struct {
 	WithImages        bool
 	DisableIndicators bool
 	RequireATR        bool
+	Composite         bool
+	CompositeWeights  map[string]float64
+	Snapshot          SnapshotOptions
+	Confluence        bool
+	ConfluenceWeights map[string]float64
+	// DivergenceScoring 为 composite/confluence 中各周期背离判定的指标权重与阈值。
+	DivergenceScoring screen.DivergenceScoring
+	// OrderBook 返回 symbol 的最新盘口指标，非 nil 且有数据时快照附带 order_book 块。
+	OrderBook func(symbol string) (market.OrderBookMetrics, bool)
+	// IndicatorCache 非 nil 时按最后收盘 K 线复用指标与快照主体。
+	IndicatorCache *IndicatorCache
 }
 
 const defaultIndicatorLookback = 240
@@ -72,6 +93,14 @@ type analysisBuildConfig struct {
 	withImages        bool
 	disableIndicators bool
 	requireATR        bool
+	composite         bool
+	compositeWeights  map[string]float64
+	snapshot          SnapshotOptions
+	confluence        bool
+	confluenceWeights map[string]float64
+	divergenceScoring screen.DivergenceScoring
+	orderBook         func(symbol string) (market.OrderBookMetrics, bool)
+	indicatorCache    *IndicatorCache
 }
 
 func normalizeAnalysisBuildInput(input AnalysisBuildInput) (analysisBuildConfig, bool) {
@@ -107,6 +136,25 @@ func normalizeAnalysisBuildInput(input AnalysisBuildInput) (analysisBuildConfig,
 		withImages:        input.WithImages,
 		disableIndicators: input.DisableIndicators,
 		requireATR:        input.RequireATR,
+		composite:         input.Composite,
+		compositeWeights:  input.CompositeWeights,
+		snapshot: SnapshotOptions{
+			DistanceUnits:    NormalizeDistanceUnits(input.Snapshot.DistanceUnits),
+			Preset:           input.Snapshot.Preset,
+			Blocks:           input.Snapshot.Blocks,
+			Tails:            input.Snapshot.Tails,
+			Precision:        input.Snapshot.Precision,
+			StochRSI:         input.Snapshot.StochRSI,
+			ConnorsRSI:       input.Snapshot.ConnorsRSI,
+			EMAPeriods:       input.Snapshot.EMAPeriods,
+			RSIDivergence:    input.Snapshot.RSIDivergence,
+			CustomIndicators: input.Snapshot.CustomIndicators,
+		},
+		confluence:        input.Confluence,
+		confluenceWeights: input.ConfluenceWeights,
+		divergenceScoring: input.DivergenceScoring,
+		orderBook:         input.OrderBook,
+		indicatorCache:    input.IndicatorCache,
 	}, true
 }
 
@@ -116,31 +164,52 @@ func buildAnalysisContextsForSymbol(cfg analysisBuildConfig, rawSym string) []An
 		return nil
 	}
 	out := make([]AnalysisContext, 0, len(cfg.intervals))
+	series := make(map[string][]market.Candle, len(cfg.intervals))
 	for _, rawIv := range cfg.intervals {
-		ac, ok := buildAnalysisContextForSymbolInterval(cfg, sym, rawIv)
+		ac, candles, ok := buildAnalysisContextForSymbolInterval(cfg, sym, rawIv)
 		if !ok {
 			continue
 		}
+		series[ac.Interval] = candles
 		out = append(out, ac)
 	}
+	if (cfg.composite || cfg.confluence) && len(out) > 0 {
+		intervals := make([]string, 0, len(out))
+		for _, ac := range out {
+			intervals = append(intervals, ac.Interval)
+		}
+		candles := func(iv string) []market.Candle { return series[iv] }
+		if cfg.composite {
+			composite := screen.CompositeFromCandles(intervals, candles, cfg.compositeWeights, cfg.divergenceScoring)
+			for i := range out {
+				out[i].Composite = &composite
+			}
+		}
+		if cfg.confluence {
+			confluence := screen.ConfluenceFromCandles(intervals, candles, cfg.confluenceWeights, cfg.divergenceScoring)
+			for i := range out {
+				out[i].Confluence = &confluence
+			}
+		}
+	}
 	return out
 }
 
-func buildAnalysisContextForSymbolInterval(cfg analysisBuildConfig, sym string, rawIv string) (AnalysisContext, bool) {
+func buildAnalysisContextForSymbolInterval(cfg analysisBuildConfig, sym string, rawIv string) (AnalysisContext, []market.Candle, bool) {
 	iv := strings.TrimSpace(rawIv)
 	if iv == "" {
-		return AnalysisContext{}, false
+		return AnalysisContext{}, nil, false
 	}
 	effectiveSlice := enforceIntervalSliceLimit(iv, cfg.sliceLen)
 	candles := exportCandles(cfg, sym, iv)
 	if len(candles) == 0 {
-		return AnalysisContext{}, false
+		return AnalysisContext{}, nil, false
 	}
 
 	fullCandles, shortCandles, sourceCandles := prepareCandles(sym, iv, candles, effectiveSlice, cfg.sliceLen, cfg.sliceDrop)
 	rawJSON, ok := marshalCandlesJSON(sym, iv, sourceCandles)
 	if !ok {
-		return AnalysisContext{}, false
+		return AnalysisContext{}, nil, false
 	}
 	csvData := buildCandleCSVData(shortCandles, iv)
 
@@ -161,11 +230,12 @@ func buildAnalysisContextForSymbolInterval(cfg analysisBuildConfig, sym string,
 		PatternReport:   pat.PatternSummary,
 		TrendReport:     trendReport,
 		ForecastHorizon: cfg.horizonName,
+		DistanceUnits:   cfg.snapshot.DistanceUnits,
 	}
 	if cfg.withImages && calculated && indErr == nil {
-		ac.ImageB64, ac.ImageNote = renderComposite(cfg.ctx, sym, iv, cfg.horizonName, shortCandles, fullCandles, rep, pat)
+		ac.ImageB64, ac.ImageNote = renderComposite(cfg.ctx, sym, iv, cfg.horizonName, shortCandles, fullCandles, rep, pat, cfg.divergenceScoring)
 	}
-	return ac, true
+	return ac, sourceCandles, true
 }
 
 func exportCandles(cfg analysisBuildConfig, sym string, iv string) []market.Candle {
@@ -221,21 +291,42 @@ func buildCandleCSVData(shortCandles []market.Candle, iv string) string {
 }
 
 func buildIndicatorPayload(cfg analysisBuildConfig, sym, iv string, fullCandles, shortCandles []market.Candle) (string, indicator.Report, bool, error) {
-	rep, calculated, err := computeIndicators(cfg, sym, iv, fullCandles)
-	if err != nil || !calculated {
-		return "", rep, calculated, err
+	key := indicatorCacheKey(sym, iv)
+	closeTime := lastCandleCloseTime(fullCandles)
+	settingsHash := indicatorSettingsHash(cfg, sym, iv, len(fullCandles))
+	entry, hit := cfg.indicatorCache.get(key, closeTime, settingsHash)
+	if !hit {
+		rep, calculated, err := computeIndicators(cfg, sym, iv, fullCandles)
+		if err != nil || !calculated {
+			return "", rep, calculated, err
+		}
+		entry = indicatorCacheEntry{closeTime: closeTime, settingsHash: settingsHash, rep: rep}
+		entry.snapshot, entry.snapshotErr = newIndicatorSnapshot(fullCandles, rep, cfg.snapshot)
+		cfg.indicatorCache.put(key, entry)
 	}
+	rep := entry.rep
 
 	indJSON := ""
-	if payload, snapErr := BuildIndicatorSnapshot(fullCandles, rep); snapErr == nil {
-		indJSON = string(payload)
-	} else {
+	opts := cfg.snapshot
+	if cfg.orderBook != nil {
+		if ob, ok := cfg.orderBook(sym); ok {
+			opts.orderBook = &ob
+		}
+	}
+	snapErr := entry.snapshotErr
+	if snapErr == nil {
+		var payload []byte
+		if payload, snapErr = marshalIndicatorSnapshot(entry.snapshot, fullCandles[len(fullCandles)-1], opts); snapErr == nil {
+			indJSON = string(payload)
+		}
+	}
+	if snapErr != nil {
 		logger.Warnf("indicator snapshot 构建失败 %s %s: %v", sym, iv, snapErr)
 	}
 	if len(shortCandles) > 0 && len(shortCandles) < len(fullCandles) {
 		rep = clipIndicatorReport(rep, len(shortCandles))
 	}
-	return indJSON, rep, calculated, err
+	return indJSON, rep, true, nil
 }
 
 func computeIndicators(cfg analysisBuildConfig, sym, iv string, fullCandles []market.Candle) (indicator.Report, bool, error) {
@@ -246,10 +337,11 @@ func computeIndicators(cfg analysisBuildConfig, sym, iv string, fullCandles []ma
 			logger.Debugf("analysis %s %s 指标历史不足，需要 %d 根，当前仅 %d 根", sym, iv, cfg.indicatorLookback, len(fullCandles))
 			return indicator.Report{}, true, err
 		}
-		rep, err := indicator.ComputeAll(fullCandles, indicator.Settings{Symbol: sym, Interval: iv})
+		rep, err := indicator.ComputeAll(fullCandles, indicatorSettings(cfg, sym, iv))
 		return rep, true, err
 	case cfg.requireATR:
-		series, err := indicator.ComputeATRSeries(fullCandles, 14)
+		settings := indicatorSettings(cfg, sym, iv)
+		series, err := indicator.ComputeATRSeries(fullCandles, settings.ATRPeriod)
 		if err != nil {
 			return indicator.Report{}, true, err
 		}
@@ -261,7 +353,7 @@ func computeIndicators(cfg analysisBuildConfig, sym, iv string, fullCandles []ma
 				"atr": {
 					Latest: series[len(series)-1],
 					Series: series,
-					Note:   "period=14",
+					Note:   fmt.Sprintf("period=%d", settings.ATRPeriod),
 					State:  "volatility",
 				},
 			},
@@ -272,11 +364,25 @@ func computeIndicators(cfg analysisBuildConfig, sym, iv string, fullCandles []ma
 	}
 }
 
+// indicatorSettings 按快照 preset 展开指标参数，未配置或未知时使用 ComputeAll 内置默认。
+func indicatorSettings(cfg analysisBuildConfig, sym, iv string) indicator.Settings {
+	settings := indicator.Settings{Symbol: sym, Interval: iv, ATRPeriod: 14}
+	if preset, ok := indicator.LookupPreset(cfg.snapshot.Preset); ok {
+		settings = preset.Settings(sym, iv)
+	}
+	settings.StochRSI = settings.StochRSI.Override(cfg.snapshot.StochRSI)
+	settings.ConnorsRSI = settings.ConnorsRSI.Override(cfg.snapshot.ConnorsRSI)
+	if periods := cfg.snapshot.emaPeriods(iv); len(periods) > 0 {
+		settings.EMA.Periods = periods
+	}
+	return settings
+}
+
 func formatTrendReport(pat pattern.Result) string {
 	return pat.TrendSummary
 }
 
-func renderComposite(ctx context.Context, sym, iv, horizon string, candles []market.Candle, history []market.Candle, rep indicator.Report, pat pattern.Result) (string, string) {
+func renderComposite(ctx context.Context, sym, iv, horizon string, candles []market.Candle, history []market.Candle, rep indicator.Report, pat pattern.Result, divergence screen.DivergenceScoring) (string, string) {
 	imgInput := visual.CompositeInput{
 		Context:    ctx,
 		Symbol:     sym,
@@ -286,6 +392,7 @@ func renderComposite(ctx context.Context, sym, iv, horizon string, candles []mar
 		History:    map[string][]market.Candle{iv: history},
 		Indicators: map[string]indicator.Report{iv: rep},
 		Patterns:   map[string]pattern.Result{iv: pat},
+		Divergence: divergence,
 	}
 	img, err := visual.RenderComposite(imgInput)
 	if err != nil {
@@ -377,3 +484,43 @@ func roundTo(v float64, decimals int) float64 {
 	pow := math.Pow(10, float64(decimals))
 	return math.Round(v*pow) / pow
 }
+
+// profilePromptVersion 汇总本轮候选 symbol 所用 profile 的提示词版本，格式为 profile@version，多个以逗号分隔。
+func profilePromptVersion(specs map[string]ProfilePromptSpec, candidates []string) string {
+	seen := make(map[string]struct{})
+	var parts []string
+	for _, sym := range candidates {
+		spec, ok := specs[sym]
+		if !ok {
+			spec, ok = specs[strings.ToUpper(strings.TrimSpace(sym))]
+		}
+		if !ok || strings.TrimSpace(spec.PromptVersion) == "" {
+			continue
+		}
+		part := spec.Profile + "@" + spec.PromptVersion
+		if _, dup := seen[part]; dup {
+			continue
+		}
+		seen[part] = struct{}{}
+		parts = append(parts, part)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// indicatorSnapshotHash 对本轮喂给模型的指标快照（按 symbol/interval 排序）取 sha256，用于审计时比对输入是否一致。
+func indicatorSnapshotHash(ctxs []AnalysisContext) string {
+	parts := make([]string, 0, len(ctxs))
+	for _, ac := range ctxs {
+		if strings.TrimSpace(ac.IndicatorJSON) == "" {
+			continue
+		}
+		parts = append(parts, ac.Symbol+"|"+ac.Interval+"|"+ac.IndicatorJSON)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
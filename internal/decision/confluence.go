@@ -0,0 +1,33 @@
+package decision
+
+import (
+	"encoding/json"
+	"strings"
+
+	"brale/internal/analysis/screen"
+)
+
+// renderConfluence 以 JSON 输出各 symbol 的多周期共振分，模板中通过 {{.Confluence}} 引用。
+func renderConfluence(ctxs []AnalysisContext) string {
+	bySymbol := make(map[string]*screen.Confluence)
+	for _, ac := range ctxs {
+		if ac.Confluence == nil || len(ac.Confluence.Frames) == 0 {
+			continue
+		}
+		if _, ok := bySymbol[ac.Symbol]; !ok {
+			bySymbol[ac.Symbol] = ac.Confluence
+		}
+	}
+	if len(bySymbol) == 0 {
+		return ""
+	}
+	payload, err := json.MarshalIndent(bySymbol, "", "  ")
+	if err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n## 多周期共振（long/short 为 0~100 的多空共振分，bias 为偏向；frames 为各周期 EMA 排列、WT/MFI 状态与背离）\n```json\n")
+	sb.Write(payload)
+	sb.WriteString("\n```\n")
+	return sb.String()
+}
@@ -0,0 +1,30 @@
+package decision
+
+import (
+	"context"
+	"time"
+)
+
+// 历史决策的结果标签；空字符串表示无法关联到交易（未成交或决策日志与交易库分离）。
+const (
+	DecisionOutcomeOpen     = "open"
+	DecisionOutcomeWin      = "win"
+	DecisionOutcomeLoss     = "loss"
+	DecisionOutcomeFlat     = "flat"
+	DecisionOutcomeCanceled = "canceled"
+)
+
+// DecisionHistoryEntry 为某币种的一条历史最终决策；开仓决策附带关联交易的结果。
+type DecisionHistoryEntry struct {
+	Timestamp int64
+	Action    string
+	Reasoning string
+	TraceID   string
+	Outcome   string
+	PnLRatio  float64
+	ClosedAt  int64
+}
+
+type DecisionHistorySource interface {
+	RecentDecisions(ctx context.Context, symbol string, limit int, since time.Time) ([]DecisionHistoryEntry, error)
+}
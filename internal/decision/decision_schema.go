@@ -0,0 +1,247 @@
+package decision
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"brale/internal/pkg/jsonutil"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// decisionSchemaJSON 描述决策数组的结构。数值字段允许字符串（Decision.UnmarshalJSON 会宽松转换），
+// 范围约束只作用于数值形态。
+const decisionSchemaJSON = `{
+  "type": "array",
+  "minItems": 1,
+  "items": {
+    "type": "object",
+    "required": ["action"],
+    "properties": {
+      "symbol": {"type": "string"},
+      "action": {"type": "string", "minLength": 1},
+      "context_tag": {"type": "string"},
+      "profile": {"type": "string"},
+      "leverage": {"type": ["number", "string"], "minimum": 0},
+      "position_size_usd": {"type": ["number", "string"], "minimum": 0},
+      "close_ratio": {"type": ["number", "string"], "minimum": 0, "maximum": 1},
+      "stop_loss": {"type": ["number", "string"], "minimum": 0},
+      "take_profit": {"type": ["number", "string"], "minimum": 0},
+      "confidence": {"type": ["number", "string"], "minimum": 0, "maximum": 100},
+      "reasoning": {"type": "string"},
+      "rationale": {
+        "type": "object",
+        "properties": {
+          "drivers": {"type": ["array", "string"]},
+          "invalidation": {"type": ["number", "string"], "minimum": 0},
+          "confidence": {"type": ["number", "string"], "minimum": 0, "maximum": 100}
+        }
+      },
+      "exit_plan": {
+        "type": "object",
+        "required": ["id"],
+        "properties": {
+          "id": {"type": "string", "minLength": 1},
+          "params": {"type": "object"},
+          "components": {"type": "array", "items": {"type": "object"}}
+        }
+      },
+      "scale_in": {
+        "type": "array",
+        "items": {
+          "type": "object",
+          "properties": {
+            "price": {"type": ["number", "string"], "minimum": 0},
+            "atr_offset": {"type": ["number", "string"], "minimum": 0},
+            "size_usd": {"type": ["number", "string"], "minimum": 0}
+          }
+        }
+      }
+    }
+  }
+}`
+
+var (
+	decisionSchemaOnce sync.Once
+	decisionSchema     *jsonschema.Schema
+	decisionSchemaErr  error
+)
+
+func compiledDecisionSchema() (*jsonschema.Schema, error) {
+	decisionSchemaOnce.Do(func() {
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource("decision.json", strings.NewReader(decisionSchemaJSON)); err != nil {
+			decisionSchemaErr = err
+			return
+		}
+		decisionSchema, decisionSchemaErr = compiler.Compile("decision.json")
+	})
+	return decisionSchema, decisionSchemaErr
+}
+
+// DecisionFormatError 为模型输出的格式问题列表，用于日志与重新提示模型。
+type DecisionFormatError struct {
+	Issues []string
+}
+
+func (e *DecisionFormatError) Error() string {
+	return "决策格式校验失败: " + strings.Join(e.Issues, "; ")
+}
+
+// ValidateDecisionSchema 按 JSON Schema 校验决策数组，返回全部不符合项（按字段路径排序）。
+func ValidateDecisionSchema(arr string) error {
+	schema, err := compiledDecisionSchema()
+	if err != nil {
+		return fmt.Errorf("决策 schema 编译失败: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(arr), &doc); err != nil {
+		return &DecisionFormatError{Issues: []string{"json 格式无效: " + err.Error()}}
+	}
+	verr := schema.Validate(doc)
+	if verr == nil {
+		return nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(verr, &ve) {
+		return &DecisionFormatError{Issues: []string{verr.Error()}}
+	}
+	var issues []string
+	collectSchemaIssues(ve, &issues)
+	if len(issues) == 0 {
+		issues = append(issues, ve.Error())
+	}
+	sort.Strings(issues)
+	return &DecisionFormatError{Issues: issues}
+}
+
+// collectSchemaIssues 只保留叶子错误，避免 allOf/items 等中间层重复描述同一问题。
+func collectSchemaIssues(ve *jsonschema.ValidationError, out *[]string) {
+	if len(ve.Causes) == 0 {
+		loc := ve.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		*out = append(*out, fmt.Sprintf("%s: %s", loc, ve.Message))
+		return
+	}
+	for _, c := range ve.Causes {
+		collectSchemaIssues(c, out)
+	}
+}
+
+// RepairDecisionJSON 修复模型常见的 JSON 笔误：去掉 BOM 与残留的 markdown 围栏、删除对象/数组末尾多余的逗号。
+// 返回修复后的文本以及是否有改动。
+func RepairDecisionJSON(raw string) (string, bool) {
+	out := strings.TrimSpace(strings.TrimPrefix(raw, "\ufeff"))
+	if strings.HasPrefix(out, "```") {
+		out = strings.TrimPrefix(out, "```")
+		if idx := strings.Index(out, "\n"); idx != -1 && !strings.ContainsAny(out[:idx], "[{") {
+			out = out[idx+1:]
+		}
+	}
+	out = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(out), "```"))
+	out = stripTrailingCommas(out)
+	return out, out != strings.TrimSpace(raw)
+}
+
+// stripTrailingCommas 删除字符串字面量之外、紧跟在 } 或 ] 之前的逗号。
+func stripTrailingCommas(raw string) string {
+	var b strings.Builder
+	b.Grow(len(raw))
+	inString, escape := false, false
+	for i := 0; i < len(raw); i++ {
+		ch := raw[i]
+		if inString {
+			b.WriteByte(ch)
+			switch {
+			case escape:
+				escape = false
+			case ch == '\\':
+				escape = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		if ch == '"' {
+			inString = true
+			b.WriteByte(ch)
+			continue
+		}
+		if ch == ',' {
+			j := i + 1
+			for j < len(raw) && strings.IndexByte(" \t\r\n", raw[j]) >= 0 {
+				j++
+			}
+			if j < len(raw) && (raw[j] == '}' || raw[j] == ']') {
+				continue
+			}
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// parseDecisionOutput 是模型输出的统一解析入口：提取 JSON → 格式无效时尝试修复 → 归一为数组 →
+// schema 校验 → 业务校验 → 解码 → exit_plan 校验（validatePlans 可为 nil）。
+func parseDecisionOutput(raw string, validatePlans func([]Decision) error) (DecisionResult, error) {
+	parsed := DecisionResult{RawOutput: raw}
+	block, ok := jsonutil.ExtractJSON(raw)
+	if !ok {
+		return parsed, &DecisionFormatError{Issues: []string{"未找到 JSON 决策数组"}}
+	}
+	arr, err := CoerceDecisionArrayJSON(block)
+	if err != nil {
+		if repaired, changed := RepairDecisionJSON(block); changed {
+			if fixed, rerr := CoerceDecisionArrayJSON(repaired); rerr == nil {
+				block, arr, err = repaired, fixed, nil
+			}
+		}
+	}
+	if err != nil {
+		parsed.RawJSON = strings.TrimSpace(block)
+		return parsed, &DecisionFormatError{Issues: []string{err.Error()}}
+	}
+	parsed.RawJSON = arr
+	if serr := ValidateDecisionSchema(arr); serr != nil {
+		return parsed, serr
+	}
+	if qerr := ValidateDecisionArray(arr); qerr != nil {
+		return parsed, &DecisionFormatError{Issues: []string{qerr.Error()}}
+	}
+	var ds []Decision
+	dec := json.NewDecoder(strings.NewReader(arr))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&ds); err != nil {
+		return parsed, &DecisionFormatError{Issues: []string{err.Error()}}
+	}
+	if validatePlans != nil {
+		if verr := validatePlans(ds); verr != nil {
+			return parsed, &DecisionFormatError{Issues: []string{verr.Error()}}
+		}
+	}
+	for i := range ds {
+		ExtractRationale(&ds[i])
+	}
+	parsed.Decisions = ds
+	return parsed, nil
+}
+
+// decisionRepairPrompt 生成重新提示：附上校验问题，要求模型只输出修正后的 JSON 数组。
+func decisionRepairPrompt(user string, ferr *DecisionFormatError) string {
+	var b strings.Builder
+	b.WriteString(user)
+	b.WriteString("\n\n【上一次输出未通过格式校验】\n")
+	for _, issue := range ferr.Issues {
+		b.WriteString("- ")
+		b.WriteString(issue)
+		b.WriteByte('\n')
+	}
+	b.WriteString("请修正以上问题，只输出符合要求的 JSON 决策数组，不要附加其它文字。")
+	return b.String()
+}
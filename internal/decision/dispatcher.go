@@ -128,6 +128,7 @@ func (d *Dispatcher) callProvider(parent context.Context, p provider.ModelProvid
 
 	start := time.Now()
 	raw, err := p.Call(cctx, payload)
+	latency := time.Since(start)
 	logger.LogLLMResponse("main", p.ID(), purpose, raw)
 
 	if err != nil {
@@ -143,6 +144,7 @@ func (d *Dispatcher) callProvider(parent context.Context, p provider.ModelProvid
 		Images:        cloneImages(payload.Images),
 		VisionEnabled: visionEnabled,
 		ImageCount:    len(payload.Images),
+		Latency:       latency,
 	}
 }
 
@@ -0,0 +1,49 @@
+package decision
+
+import (
+	"context"
+	"sync"
+)
+
+type dryRunKey struct{}
+
+// DryRunCapture 收集 dry-run 期间本应交给 Observer 的 trace，用于直接返回给调用方而不写决策日志。
+type DryRunCapture struct {
+	mu     sync.Mutex
+	traces []DecisionTrace
+}
+
+// WithDryRun 标记 ctx 为 dry-run：DecisionEngine 照常组装 prompt 并调用模型，但不触发 Observer。
+func WithDryRun(ctx context.Context) (context.Context, *DryRunCapture) {
+	capture := &DryRunCapture{}
+	return context.WithValue(ctx, dryRunKey{}, capture), capture
+}
+
+// IsDryRun 判断 ctx 是否处于 dry-run。
+func IsDryRun(ctx context.Context) bool {
+	return dryRunFrom(ctx) != nil
+}
+
+func dryRunFrom(ctx context.Context) *DryRunCapture {
+	if ctx == nil {
+		return nil
+	}
+	capture, _ := ctx.Value(dryRunKey{}).(*DryRunCapture)
+	return capture
+}
+
+func (c *DryRunCapture) add(trace DecisionTrace) {
+	c.mu.Lock()
+	c.traces = append(c.traces, trace)
+	c.mu.Unlock()
+}
+
+// Traces 返回已收集的 trace 副本。
+func (c *DryRunCapture) Traces() []DecisionTrace {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CloneSlice(c.traces)
+}
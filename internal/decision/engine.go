@@ -23,10 +23,12 @@ type Context struct {
 	ExitPlanDirective       string                       // Exit strategy constraints for prompt
 	PreviousReasoning       map[string]string            // Last cycle's reasoning per symbol
 	PreviousProviderOutputs []ProviderOutputSnapshot     // Last cycle's provider outputs for the symbol
+	RecentDecisions         []DecisionHistoryEntry       // Last K non-hold final decisions for the symbol, newest first
 	Insights                []AgentInsight               // Multi-agent intermediate outputs
 	Directives              map[string]ProfileDirective  // Symbol-specific trading rules
 	DataAgeSec              map[string]int64             // data age by domain (indicator/trend/pattern/mechanics)
 	HardFlags               HardFlags                    // hard stop flags computed by code
+	DaysToExpiry            map[string]float64           // days to settlement for delivery contracts (perpetuals omitted)
 }
 
 // MarketData is the point-in-time snapshot of a symbol's market state.
@@ -54,8 +56,12 @@ type ProfilePromptSpec struct {
 	SystemPromptsByModel    map[string]string
 	SystemPromptRefsByModel map[string]string
 	UserPrompt              string
+	PromptVersion           string
 	ExitConstraints         string
 	Example                 string
+	Contract                OutputContract
+	Consensus               ConsensusSpec
+	SnapshotDelta           SnapshotDeltaSpec
 }
 
 // HardFlags carries system-computed guard rails (LLM 不得改判).
@@ -69,3 +75,9 @@ type HardFlags struct {
 type Decider interface {
 	Decide(ctx context.Context, input Context) (DecisionResult, error)
 }
+
+// SkipRecorder is optionally implemented by a Decider to annotate rounds
+// skipped before the model call (e.g. outside the profile trading session).
+type SkipRecorder interface {
+	RecordSkipped(ctx context.Context, symbols []string, reason string)
+}
@@ -0,0 +1,121 @@
+package decision
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/market"
+)
+
+// IndicatorCache 按 symbol|interval 缓存指标结果与快照主体，键为（最后一根已收盘 K 线时间，指标/快照设置哈希）。
+// 新 K 线收盘或设置变化时该条目失效重算，未收盘期间的重复决策直接复用，避免 50+ 个 symbol 每轮重算 MACD 等指标。
+type IndicatorCache struct {
+	mu      sync.Mutex
+	entries map[string]indicatorCacheEntry
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+}
+
+// IndicatorCacheStats 为缓存命中统计，Invalidations 为因新 K 线或设置变化而被替换的条目数。
+type IndicatorCacheStats struct {
+	Entries       int     `json:"entries"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Invalidations int64   `json:"invalidations"`
+	HitRate       float64 `json:"hit_rate"`
+}
+
+type indicatorCacheEntry struct {
+	closeTime    int64
+	settingsHash string
+	rep          indicator.Report
+	snapshot     indicatorSnapshot
+	snapshotErr  error
+}
+
+func NewIndicatorCache() *IndicatorCache {
+	return &IndicatorCache{entries: make(map[string]indicatorCacheEntry)}
+}
+
+// Stats 返回累计命中统计。
+func (c *IndicatorCache) Stats() IndicatorCacheStats {
+	if c == nil {
+		return IndicatorCacheStats{}
+	}
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+	stats := IndicatorCacheStats{
+		Entries:       entries,
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *IndicatorCache) get(key string, closeTime int64, settingsHash string) (indicatorCacheEntry, bool) {
+	if c == nil {
+		return indicatorCacheEntry{}, false
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.closeTime == closeTime && entry.settingsHash == settingsHash {
+		c.hits.Add(1)
+		return entry, true
+	}
+	c.misses.Add(1)
+	return indicatorCacheEntry{}, false
+}
+
+func (c *IndicatorCache) put(key string, entry indicatorCacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]indicatorCacheEntry)
+	}
+	if _, ok := c.entries[key]; ok {
+		c.invalidations.Add(1)
+	}
+	c.entries[key] = entry
+}
+
+func indicatorCacheKey(sym, iv string) string {
+	return strings.ToUpper(strings.TrimSpace(sym)) + "|" + strings.ToLower(strings.TrimSpace(iv))
+}
+
+func lastCandleCloseTime(candles []market.Candle) int64 {
+	if len(candles) == 0 {
+		return 0
+	}
+	last := candles[len(candles)-1]
+	if last.CloseTime > 0 {
+		return last.CloseTime
+	}
+	return last.OpenTime
+}
+
+// indicatorSettingsHash 覆盖影响指标结果与快照主体的全部设置；盘口在序列化时注入，不参与哈希。
+func indicatorSettingsHash(cfg analysisBuildConfig, sym, iv string, candleCount int) string {
+	opts := cfg.snapshot
+	opts.orderBook = nil
+	raw := fmt.Sprintf("%t|%t|%d|%d|%+v|%+v",
+		cfg.disableIndicators, cfg.requireATR, cfg.indicatorLookback, candleCount,
+		indicatorSettings(cfg, sym, iv), opts)
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:8])
+}
@@ -7,13 +7,18 @@ import (
 	"strings"
 	"time"
 
+	"brale/internal/analysis/formula"
 	"brale/internal/analysis/indicator"
 	"brale/internal/market"
 
 	talib "github.com/markcheno/go-talib"
 )
 
-const indicatorSnapshotVersion = "indicator_snapshot_v1"
+const (
+	indicatorSnapshotVersion = "indicator_snapshot_v1"
+	// v2 为按 SnapshotOptions 裁剪数据块/序列长度/精度后的快照，_meta 写明生效设置。
+	indicatorSnapshotVersionV2 = "indicator_snapshot_v2"
+)
 
 type indicatorSnapshot struct {
 	Meta   snapshotMeta   `json:"_meta"`
@@ -22,11 +27,17 @@ type indicatorSnapshot struct {
 }
 
 type snapshotMeta struct {
-	SeriesOrder  string           `json:"series_order"`
-	SampledAt    string           `json:"sampled_at"`
-	Version      string           `json:"version"`
-	TimestampNow string           `json:"timestamp_now_ts,omitempty"`
-	DataAgeSec   map[string]int64 `json:"data_age_sec,omitempty"`
+	SeriesOrder   string           `json:"series_order"`
+	SampledAt     string           `json:"sampled_at"`
+	Version       string           `json:"version"`
+	TimestampNow  string           `json:"timestamp_now_ts,omitempty"`
+	DataAgeSec    map[string]int64 `json:"data_age_sec,omitempty"`
+	DistanceUnits string           `json:"distance_units,omitempty"`
+	ATRRef        float64          `json:"atr_ref,omitempty"`
+	Blocks        []string         `json:"blocks,omitempty"`
+	Tails         map[string]int   `json:"tails,omitempty"`
+	Precision     int              `json:"precision,omitempty"`
+	EMAPeriods    []int            `json:"ema_periods,omitempty"`
 }
 
 type snapshotMarket struct {
@@ -37,14 +48,23 @@ type snapshotMarket struct {
 }
 
 type snapshotData struct {
-	EMAFast *emaSnapshot   `json:"ema_fast,omitempty"`
-	EMAMid  *emaSnapshot   `json:"ema_mid,omitempty"`
-	EMASlow *emaSnapshot   `json:"ema_slow,omitempty"`
-	MACD    *macdSnapshot  `json:"macd,omitempty"`
-	RSI     *rsiSnapshot   `json:"rsi,omitempty"`
-	OBV     *obvSnapshot   `json:"obv,omitempty"`
-	StochK  *stochSnapshot `json:"stoch_k,omitempty"`
-	ATR     *atrSnapshot   `json:"atr,omitempty"`
+	EMAFast       *emaSnapshot           `json:"ema_fast,omitempty"`
+	EMAMid        *emaSnapshot           `json:"ema_mid,omitempty"`
+	EMASlow       *emaSnapshot           `json:"ema_slow,omitempty"`
+	EMAs          []periodEMASnapshot    `json:"emas,omitempty"`
+	MACD          *macdSnapshot          `json:"macd,omitempty"`
+	RSI           *rsiSnapshot           `json:"rsi,omitempty"`
+	OBV           *obvSnapshot           `json:"obv,omitempty"`
+	StochK        *stochSnapshot         `json:"stoch_k,omitempty"`
+	StochRSI      *stochRSISnapshot      `json:"stoch_rsi,omitempty"`
+	ConnorsRSI    *oscillatorSnapshot    `json:"connors_rsi,omitempty"`
+	ATR           *atrSnapshot           `json:"atr,omitempty"`
+	Ichimoku      *ichimokuSnapshot      `json:"ichimoku,omitempty"`
+	ADX           *adxSnapshot           `json:"adx,omitempty"`
+	Supertrend    *supertrendSnapshot    `json:"supertrend,omitempty"`
+	OrderBook     *orderBookSnapshot     `json:"order_book,omitempty"`
+	RSIDivergence *rsiDivergenceSnapshot `json:"rsi_divergence,omitempty"`
+	Custom        []customSnapshot       `json:"custom,omitempty"`
 }
 
 type emaSnapshot struct {
@@ -52,10 +72,17 @@ type emaSnapshot struct {
 	LastN        []float64 `json:"last_n,omitempty"`
 	PeriodHigh   float64   `json:"period_high"`
 	PeriodLow    float64   `json:"period_low"`
-	DeltaToPrice float64   `json:"delta_to_price"`
+	DeltaToPrice *float64  `json:"delta_to_price,omitempty"`
+	DeltaATR     *float64  `json:"delta_atr,omitempty"`
 	DeltaPct     float64   `json:"delta_pct"`
 }
 
+// periodEMASnapshot 为按 profile 配置周期输出的 EMA，emas 数组按周期升序。
+type periodEMASnapshot struct {
+	Period int `json:"period"`
+	emaSnapshot
+}
+
 type macdSnapshot struct {
 	DIF             float64         `json:"dif"`
 	DEA             float64         `json:"dea"`
@@ -89,6 +116,28 @@ type stochSnapshot struct {
 	RangeHi float64   `json:"range_max"`
 }
 
+// stochRSISnapshot 的 state 为 overbought/oversold/neutral（按配置阈值），cross 为 %K 相对 %D 的位置 above/below。
+type stochRSISnapshot struct {
+	K               float64   `json:"k"`
+	D               float64   `json:"d"`
+	LastN           []float64 `json:"last_n,omitempty"`
+	State           string    `json:"state"`
+	Cross           string    `json:"cross,omitempty"`
+	Slope           *float64  `json:"slope,omitempty"`
+	NormalizedSlope *float64  `json:"normalized_slope,omitempty"`
+	SlopeState      string    `json:"slope_state,omitempty"`
+}
+
+// oscillatorSnapshot 为 0-100 振荡器的通用快照，state 按配置阈值判定。
+type oscillatorSnapshot struct {
+	Current         float64   `json:"current"`
+	LastN           []float64 `json:"last_n,omitempty"`
+	State           string    `json:"state"`
+	Slope           *float64  `json:"slope,omitempty"`
+	NormalizedSlope *float64  `json:"normalized_slope,omitempty"`
+	SlopeState      string    `json:"slope_state,omitempty"`
+}
+
 type seriesSnapshot struct {
 	Last []float64 `json:"last_n,omitempty"`
 }
@@ -101,90 +150,266 @@ type atrSnapshot struct {
 	ChangePct *float64  `json:"change_pct,omitempty"`
 }
 
+// adxSnapshot 的 strength 为 no-trend/weak/strong/very-strong，direction 为 +DI/-DI 的相对方向，用于区分震荡与趋势行情。
+type adxSnapshot struct {
+	ADX       float64   `json:"adx"`
+	PlusDI    float64   `json:"plus_di"`
+	MinusDI   float64   `json:"minus_di"`
+	Strength  string    `json:"strength"`
+	Direction string    `json:"direction"`
+	LastN     []float64 `json:"last_n,omitempty"`
+}
+
+// supertrendSnapshot 的 direction 为 up/down，bars_since_flip 为 0 表示最新一根刚翻转；band_distance 为价格到生效轨道的距离。
+type supertrendSnapshot struct {
+	Band            float64   `json:"band"`
+	Direction       string    `json:"direction"`
+	BarsSinceFlip   int       `json:"bars_since_flip"`
+	BandDistance    *float64  `json:"band_distance,omitempty"`
+	BandDistanceATR *float64  `json:"band_distance_atr,omitempty"`
+	LastN           []float64 `json:"last_n,omitempty"`
+}
+
+// orderBookSnapshot 来自最新部分深度：imbalance 为 band_pct 内 (买量-卖量)/(买量+卖量)，
+// 挂单墙的 distance_pct 为相对中间价的百分比（买墙为负）。
+type orderBookSnapshot struct {
+	Imbalance float64               `json:"imbalance"`
+	SpreadBps float64               `json:"spread_bps"`
+	BandPct   float64               `json:"band_pct"`
+	BidDepth  float64               `json:"bid_depth"`
+	AskDepth  float64               `json:"ask_depth"`
+	BidWall   *market.OrderBookWall `json:"bid_wall,omitempty"`
+	AskWall   *market.OrderBookWall `json:"ask_wall,omitempty"`
+	AgeSec    int64                 `json:"age_sec"`
+}
+
+// rsiDivergenceSnapshot 列出最近确认的 RSI 背离（按确认先后，最多 tail 组）：grade 为 A/B/C 强度（A 最强，
+// 综合 RSI 是否进入超买/超卖区与两个枢轴的间隔），bias 为按得分汇总的净方向 bullish/bearish/mixed/none。
+type rsiDivergenceSnapshot struct {
+	Bias      string              `json:"bias"`
+	Strongest string              `json:"strongest_grade,omitempty"`
+	Count     int                 `json:"count"`
+	Items     []rsiDivergenceItem `json:"items,omitempty"`
+}
+
+// rsiDivergenceItem 的 kind 为 regular（反转）或 hidden（延续），age_bars 为较晚枢轴距最新 K 线的根数。
+type rsiDivergenceItem struct {
+	Bias      string  `json:"bias"`
+	Kind      string  `json:"kind"`
+	Grade     string  `json:"grade"`
+	PriceFrom float64 `json:"price_from"`
+	PriceTo   float64 `json:"price_to"`
+	RSIFrom   float64 `json:"rsi_from"`
+	RSITo     float64 `json:"rsi_to"`
+	BarsApart int     `json:"bars_apart"`
+	AgeBars   int     `json:"age_bars"`
+}
+
+// customSnapshot 为 profile 自定义表达式指标的结果：比较/逻辑表达式取值 1/0。
+type customSnapshot struct {
+	Name    string    `json:"name"`
+	Formula string    `json:"formula"`
+	Value   float64   `json:"value"`
+	LastN   []float64 `json:"last_n,omitempty"`
+}
+
+type ichimokuSnapshot struct {
+	Tenkan           float64  `json:"tenkan"`
+	Kijun            float64  `json:"kijun"`
+	SenkouA          float64  `json:"senkou_a"`
+	SenkouB          float64  `json:"senkou_b"`
+	Chikou           float64  `json:"chikou"`
+	PriceVsCloud     string   `json:"price_vs_cloud"`
+	CloudColor       string   `json:"cloud_color"`
+	FutureCloudColor string   `json:"future_cloud_color"`
+	ChikouState      string   `json:"chikou_state"`
+	TKCross          string   `json:"tk_cross,omitempty"`
+	TwistInBars      *int     `json:"cloud_twist_in_bars,omitempty"`
+	CloudDistance    *float64 `json:"cloud_distance,omitempty"`
+	CloudDistanceATR *float64 `json:"cloud_distance_atr,omitempty"`
+}
+
 func BuildIndicatorSnapshot(candles []market.Candle, rep indicator.Report) ([]byte, error) {
+	return BuildIndicatorSnapshotWithOptions(candles, rep, SnapshotOptions{})
+}
+
+// BuildIndicatorSnapshotWithOptions 在 ATR 模式下把价差类字段同时（或仅）以 ATR 倍数输出，
+// 便于模型跨不同价位资产比较距离；ATR 不可用时回退为绝对值。
+func BuildIndicatorSnapshotWithOptions(candles []market.Candle, rep indicator.Report, opts SnapshotOptions) ([]byte, error) {
+	snapshot, err := newIndicatorSnapshot(candles, rep, opts)
+	if err != nil {
+		return nil, err
+	}
+	return marshalIndicatorSnapshot(snapshot, candles[len(candles)-1], opts)
+}
+
+// marshalIndicatorSnapshot 补上与调用时刻相关的字段（当前时间、数据延迟、盘口）后序列化；snapshot 按值传入，缓存的副本不受影响。
+func marshalIndicatorSnapshot(snapshot indicatorSnapshot, last market.Candle, opts SnapshotOptions) ([]byte, error) {
+	now := time.Now().UTC()
+	snapshot.Meta.TimestampNow = now.Format(time.RFC3339)
+	if last.CloseTime > 0 {
+		ageSec := int64(now.Sub(time.UnixMilli(last.CloseTime)).Seconds())
+		if ageSec < 0 {
+			ageSec = 0
+		}
+		snapshot.Meta.DataAgeSec = map[string]int64{"indicator": ageSec}
+	}
+	if opts.orderBook != nil && opts.includes(SnapshotBlockOrderBook) {
+		snapshot.Data.OrderBook = buildOrderBookSnapshot(*opts.orderBook, opts.digits())
+	}
+	return json.Marshal(snapshot)
+}
+
+// newIndicatorSnapshot 只依赖已收盘 K 线与指标结果，同一根 K 线收盘前结果不变，可按收盘时间缓存。
+func newIndicatorSnapshot(candles []market.Candle, rep indicator.Report, opts SnapshotOptions) (indicatorSnapshot, error) {
 	if len(candles) == 0 {
-		return nil, fmt.Errorf("indicator snapshot: no candles")
+		return indicatorSnapshot{}, fmt.Errorf("indicator snapshot: no candles")
 	}
 	if len(rep.Values) == 0 {
-		return nil, fmt.Errorf("indicator snapshot: empty report")
+		return indicatorSnapshot{}, fmt.Errorf("indicator snapshot: empty report")
 	}
 	last := candles[len(candles)-1]
 	stamp := candleTimestamp(last)
 	price := last.Close
-	now := time.Now().UTC()
+	d := opts.digits()
 	snapshot := indicatorSnapshot{
 		Meta: snapshotMeta{
-			SeriesOrder:  "oldest_to_latest",
-			SampledAt:    stamp,
-			Version:      indicatorSnapshotVersion,
-			TimestampNow: now.Format(time.RFC3339),
+			SeriesOrder: "oldest_to_latest",
+			SampledAt:   stamp,
+			Version:     indicatorSnapshotVersion,
 		},
 		Market: snapshotMarket{
 			Symbol:         strings.ToUpper(strings.TrimSpace(rep.Symbol)),
 			Interval:       strings.ToLower(strings.TrimSpace(rep.Interval)),
-			CurrentPrice:   roundFloat(price, 4),
+			CurrentPrice:   roundFloat(price, d),
 			PriceTimestamp: stamp,
 		},
 	}
-	if last.CloseTime > 0 {
-		ageSec := int64(now.Sub(time.UnixMilli(last.CloseTime)).Seconds())
-		if ageSec < 0 {
-			ageSec = 0
+	if opts.customized() {
+		snapshot.Meta.Version = indicatorSnapshotVersionV2
+		snapshot.Meta.Blocks = opts.activeBlocks()
+		snapshot.Meta.Tails = opts.Tails
+		snapshot.Meta.Precision = d
+	}
+	units := NormalizeDistanceUnits(opts.DistanceUnits)
+	atrRef := 0.0
+	if distanceInATR(units) {
+		if val, ok := rep.Values["atr"]; ok && val.Latest > 0 {
+			atrRef = val.Latest
+		} else {
+			atrRef = latestATR(candles)
+		}
+		if atrRef > 0 {
+			snapshot.Meta.DistanceUnits = units
+			snapshot.Meta.ATRRef = roundFloat(atrRef, d)
+		} else {
+			units = DistanceUnitsAbsolute
 		}
-		snapshot.Meta.DataAgeSec = map[string]int64{"indicator": ageSec}
 	}
 	data := snapshotData{}
-	if val, ok := rep.Values["ema_fast"]; ok {
-		data.EMAFast = buildEMASnapshot(val, price, 5)
-	}
-	if val, ok := rep.Values["ema_mid"]; ok {
-		data.EMAMid = buildEMASnapshot(val, price, 4)
-	}
-	if val, ok := rep.Values["ema_slow"]; ok {
-		data.EMASlow = buildEMASnapshot(val, price, 3)
+	if opts.includes(SnapshotBlockEMA) {
+		tail := func(def int) int { return opts.tail(SnapshotBlockEMA, def) }
+		if periods := opts.emaPeriods(rep.Interval); len(periods) > 0 {
+			snapshot.Meta.EMAPeriods = periods
+			data.EMAs = buildPeriodEMASnapshots(rep, periods, price, tail, units, atrRef, d)
+		} else {
+			if val, ok := rep.Values["ema_fast"]; ok {
+				data.EMAFast = buildEMASnapshot(val, price, tail(5), units, atrRef, d)
+			}
+			if val, ok := rep.Values["ema_mid"]; ok {
+				data.EMAMid = buildEMASnapshot(val, price, tail(4), units, atrRef, d)
+			}
+			if val, ok := rep.Values["ema_slow"]; ok {
+				data.EMASlow = buildEMASnapshot(val, price, tail(3), units, atrRef, d)
+			}
+		}
 	}
-	if _, ok := rep.Values["macd"]; ok {
-		if snap := buildMACDSnapshot(candles, 3); snap != nil {
+	if _, ok := rep.Values["macd"]; ok && opts.includes(SnapshotBlockMACD) {
+		if snap := buildMACDSnapshot(candles, opts.tail(SnapshotBlockMACD, 3), d); snap != nil {
 			data.MACD = snap
 		}
 	}
-	if val, ok := rep.Values["rsi"]; ok {
-		data.RSI = buildRSISnapshot(val)
+	if val, ok := rep.Values["rsi"]; ok && opts.includes(SnapshotBlockRSI) {
+		data.RSI = buildRSISnapshot(val, opts.tail(SnapshotBlockRSI, 3), d)
+	}
+	if val, ok := rep.Values["obv"]; ok && opts.includes(SnapshotBlockOBV) {
+		data.OBV = buildOBVSnapshot(val, opts.tail(SnapshotBlockOBV, 3), d)
 	}
-	if val, ok := rep.Values["obv"]; ok {
-		data.OBV = buildOBVSnapshot(val)
+	if val, ok := rep.Values["stoch_k"]; ok && opts.includes(SnapshotBlockStoch) {
+		data.StochK = buildStochSnapshot(val, opts.tail(SnapshotBlockStoch, 2), d)
 	}
-	if val, ok := rep.Values["stoch_k"]; ok {
-		data.StochK = buildStochSnapshot(val)
+	if val, ok := rep.Values["stoch_rsi"]; ok && opts.includes(SnapshotBlockStochRSI) {
+		data.StochRSI = buildStochRSISnapshot(val, rep.Values["stoch_rsi_d"], opts.tail(SnapshotBlockStochRSI, 3), d)
 	}
-	if val, ok := rep.Values["atr"]; ok {
-		data.ATR = buildATRSnapshot(val)
+	if val, ok := rep.Values["connors_rsi"]; ok && opts.includes(SnapshotBlockConnorsRSI) {
+		data.ConnorsRSI = buildOscillatorSnapshot(val, opts.tail(SnapshotBlockConnorsRSI, 3), d)
+	}
+	if val, ok := rep.Values["atr"]; ok && opts.includes(SnapshotBlockATR) {
+		data.ATR = buildATRSnapshot(val, opts.tail(SnapshotBlockATR, 3), d)
+	}
+	if rep.Ichimoku != nil && opts.includes(SnapshotBlockIchimoku) {
+		data.Ichimoku = buildIchimokuSnapshot(rep.Ichimoku, price, units, atrRef, d)
+	}
+	if rep.ADX != nil && opts.includes(SnapshotBlockADX) {
+		data.ADX = buildADXSnapshot(rep.ADX, opts.tail(SnapshotBlockADX, 3), d)
+	}
+	if rep.Supertrend != nil && opts.includes(SnapshotBlockSupertrend) {
+		data.Supertrend = buildSupertrendSnapshot(rep.Supertrend, price, opts.tail(SnapshotBlockSupertrend, 3), units, atrRef, d)
+	}
+	if opts.RSIDivergence.Period > 0 && opts.includes(SnapshotBlockRSIDivergence) {
+		data.RSIDivergence = buildRSIDivergenceSnapshot(candles, opts.RSIDivergence, opts.tail(SnapshotBlockRSIDivergence, 3), d)
+	}
+	if len(opts.CustomIndicators) > 0 && opts.includes(SnapshotBlockCustom) {
+		data.Custom = buildCustomSnapshots(candles, opts.CustomIndicators, rep.Interval, opts.tail(SnapshotBlockCustom, 3), d)
 	}
 	snapshot.Data = data
-	return json.Marshal(snapshot)
+	return snapshot, nil
 }
 
-func buildEMASnapshot(val indicator.IndicatorValue, price float64, tail int) *emaSnapshot {
+func buildEMASnapshot(val indicator.IndicatorValue, price float64, tail int, units string, atr float64, d int) *emaSnapshot {
 	if val.Latest == 0 && len(val.Series) == 0 {
 		return nil
 	}
-	maxVal, minVal := seriesBounds(val.Series)
+	maxVal, minVal := seriesBounds(val.Series, d)
 	delta := price - val.Latest
 	deltaPct := 0.0
 	if val.Latest != 0 {
 		deltaPct = (delta / val.Latest) * 100
 	}
-	return &emaSnapshot{
-		Latest:       roundFloat(val.Latest, 4),
-		LastN:        roundSeriesTail(val.Series, tail),
-		PeriodHigh:   roundFloat(maxVal, 4),
-		PeriodLow:    roundFloat(minVal, 4),
-		DeltaToPrice: roundFloat(delta, 4),
-		DeltaPct:     roundFloat(deltaPct, 4),
+	es := &emaSnapshot{
+		Latest:     roundFloat(val.Latest, d),
+		LastN:      roundSeriesTail(val.Series, tail, d),
+		PeriodHigh: roundFloat(maxVal, d),
+		PeriodLow:  roundFloat(minVal, d),
+		DeltaPct:   roundFloat(deltaPct, d),
+	}
+	if distanceInAbsolute(units) {
+		d := roundFloat(delta, d)
+		es.DeltaToPrice = &d
+	}
+	if distanceInATR(units) {
+		es.DeltaATR = atrMultiple(delta, atr)
+	}
+	return es
+}
+
+// buildPeriodEMASnapshots 沿用 fast/mid/slow 的序列长度习惯：最短周期 5 根、次短 4 根、其余 3 根，tails.ema 可统一覆盖。
+func buildPeriodEMASnapshots(rep indicator.Report, periods []int, price float64, tail func(int) int, units string, atr float64, d int) []periodEMASnapshot {
+	out := make([]periodEMASnapshot, 0, len(periods))
+	for i, period := range periods {
+		val, ok := rep.Values[indicator.EMAKey(period)]
+		if !ok {
+			continue
+		}
+		n := max(5-i, 3)
+		if es := buildEMASnapshot(val, price, tail(n), units, atr, d); es != nil {
+			out = append(out, periodEMASnapshot{Period: period, emaSnapshot: *es})
+		}
 	}
+	return out
 }
 
-func buildMACDSnapshot(candles []market.Candle, tail int) *macdSnapshot {
+func buildMACDSnapshot(candles []market.Candle, tail int, d int) *macdSnapshot {
 	if len(candles) == 0 {
 		return nil
 	}
@@ -193,20 +418,20 @@ func buildMACDSnapshot(candles []market.Candle, tail int) *macdSnapshot {
 		closes[i] = c.Close
 	}
 	macdSeries, signalSeries, histSeries := talib.Macd(closes, 12, 26, 9)
-	mSeries := sanitizeSeries(macdSeries)
-	sSeries := sanitizeSeries(signalSeries)
-	hSeries := sanitizeSeries(histSeries)
+	mSeries := sanitizeSeries(macdSeries, d)
+	sSeries := sanitizeSeries(signalSeries, d)
+	hSeries := sanitizeSeries(histSeries, d)
 	if len(mSeries) == 0 || len(sSeries) == 0 || len(hSeries) == 0 {
 		return nil
 	}
-	histLast := roundSeriesTail(hSeries, tail)
+	histLast := roundSeriesTail(hSeries, tail, d)
 	var hist *seriesSnapshot
 	if len(histLast) > 0 {
 		hist = &seriesSnapshot{Last: histLast}
 	}
 	ms := &macdSnapshot{
-		DIF:       roundFloat(mSeries[len(mSeries)-1], 4),
-		DEA:       roundFloat(sSeries[len(sSeries)-1], 4),
+		DIF:       roundFloat(mSeries[len(mSeries)-1], d),
+		DEA:       roundFloat(sSeries[len(sSeries)-1], d),
 		Histogram: hist,
 	}
 	if slope, norm := computeSlope(histLast); slope != nil {
@@ -217,18 +442,18 @@ func buildMACDSnapshot(candles []market.Candle, tail int) *macdSnapshot {
 	return ms
 }
 
-func buildRSISnapshot(val indicator.IndicatorValue) *rsiSnapshot {
+func buildRSISnapshot(val indicator.IndicatorValue, tail int, d int) *rsiSnapshot {
 	if val.Latest == 0 && len(val.Series) == 0 {
 		return nil
 	}
-	maxVal, minVal := seriesBounds(val.Series)
+	maxVal, minVal := seriesBounds(val.Series, d)
 	rs := &rsiSnapshot{
-		Current:        roundFloat(val.Latest, 4),
-		LastN:          roundSeriesTail(val.Series, 3),
-		PeriodHigh:     roundFloat(maxVal, 4),
-		PeriodLow:      roundFloat(minVal, 4),
-		DistanceToHigh: roundFloat(maxVal-val.Latest, 4),
-		DistanceToLow:  roundFloat(val.Latest-minVal, 4),
+		Current:        roundFloat(val.Latest, d),
+		LastN:          roundSeriesTail(val.Series, tail, d),
+		PeriodHigh:     roundFloat(maxVal, d),
+		PeriodLow:      roundFloat(minVal, d),
+		DistanceToHigh: roundFloat(maxVal-val.Latest, d),
+		DistanceToLow:  roundFloat(val.Latest-minVal, d),
 	}
 	if slope, norm := computeSlope(rs.LastN); slope != nil {
 		rs.Slope = slope
@@ -238,38 +463,81 @@ func buildRSISnapshot(val indicator.IndicatorValue) *rsiSnapshot {
 	return rs
 }
 
-func buildOBVSnapshot(val indicator.IndicatorValue) *obvSnapshot {
+func buildOBVSnapshot(val indicator.IndicatorValue, tail int, d int) *obvSnapshot {
 	if len(val.Series) == 0 {
 		return nil
 	}
 	return &obvSnapshot{
-		Latest: roundFloat(val.Latest, 4),
-		LastN:  roundSeriesTail(val.Series, 3),
+		Latest: roundFloat(val.Latest, d),
+		LastN:  roundSeriesTail(val.Series, tail, d),
 	}
 }
 
-func buildStochSnapshot(val indicator.IndicatorValue) *stochSnapshot {
+func buildStochSnapshot(val indicator.IndicatorValue, tail int, d int) *stochSnapshot {
 	if len(val.Series) == 0 {
 		return nil
 	}
 	return &stochSnapshot{
-		Current: roundFloat(val.Latest, 4),
-		LastN:   roundSeriesTail(val.Series, 2),
+		Current: roundFloat(val.Latest, d),
+		LastN:   roundSeriesTail(val.Series, tail, d),
 		RangeLo: 0,
 		RangeHi: 100,
 	}
 }
 
-func buildATRSnapshot(val indicator.IndicatorValue) *atrSnapshot {
+func buildStochRSISnapshot(k, d indicator.IndicatorValue, tail int, digits int) *stochRSISnapshot {
+	if len(k.Series) == 0 {
+		return nil
+	}
+	ss := &stochRSISnapshot{
+		K:     roundFloat(k.Latest, digits),
+		D:     roundFloat(d.Latest, digits),
+		LastN: roundSeriesTail(k.Series, tail, digits),
+		State: k.State,
+	}
+	if len(d.Series) > 0 {
+		switch {
+		case k.Latest > d.Latest:
+			ss.Cross = "above"
+		case k.Latest < d.Latest:
+			ss.Cross = "below"
+		}
+	}
+	if slope, norm := computeSlope(roundSeriesTail(k.Series, 3, digits)); slope != nil {
+		ss.Slope = slope
+		ss.NormalizedSlope = norm
+		ss.SlopeState = indicatorSlopeState(norm)
+	}
+	return ss
+}
+
+func buildOscillatorSnapshot(val indicator.IndicatorValue, tail int, d int) *oscillatorSnapshot {
+	if len(val.Series) == 0 {
+		return nil
+	}
+	snap := &oscillatorSnapshot{
+		Current: roundFloat(val.Latest, d),
+		LastN:   roundSeriesTail(val.Series, tail, d),
+		State:   val.State,
+	}
+	if slope, norm := computeSlope(roundSeriesTail(val.Series, 3, d)); slope != nil {
+		snap.Slope = slope
+		snap.NormalizedSlope = norm
+		snap.SlopeState = indicatorSlopeState(norm)
+	}
+	return snap
+}
+
+func buildATRSnapshot(val indicator.IndicatorValue, tail int, d int) *atrSnapshot {
 	if val.Latest == 0 && len(val.Series) == 0 {
 		return nil
 	}
-	maxVal, minVal := seriesBounds(val.Series)
+	maxVal, minVal := seriesBounds(val.Series, d)
 	as := &atrSnapshot{
-		Latest:  roundFloat(val.Latest, 4),
-		LastN:   roundSeriesTail(val.Series, 3),
-		RangeLo: roundFloat(minVal, 4),
-		RangeHi: roundFloat(maxVal, 4),
+		Latest:  roundFloat(val.Latest, d),
+		LastN:   roundSeriesTail(val.Series, tail, d),
+		RangeLo: roundFloat(minVal, d),
+		RangeHi: roundFloat(maxVal, d),
 	}
 	if change := computeChangePct(val.Series); change != nil {
 		as.ChangePct = change
@@ -277,7 +545,152 @@ func buildATRSnapshot(val indicator.IndicatorValue) *atrSnapshot {
 	return as
 }
 
-func roundSeriesTail(series []float64, n int) []float64 {
+// buildIchimokuSnapshot 的 cloud_distance 为价格到最近云边的距离（云内为 0，云下为负）。
+func buildIchimokuSnapshot(ichi *indicator.Ichimoku, price float64, units string, atr float64, d int) *ichimokuSnapshot {
+	is := &ichimokuSnapshot{
+		Tenkan:           roundFloat(ichi.Tenkan, d),
+		Kijun:            roundFloat(ichi.Kijun, d),
+		SenkouA:          roundFloat(ichi.SenkouA, d),
+		SenkouB:          roundFloat(ichi.SenkouB, d),
+		Chikou:           roundFloat(ichi.Chikou, d),
+		PriceVsCloud:     ichi.PriceVsCloud,
+		CloudColor:       ichi.CloudColor,
+		FutureCloudColor: ichi.FutureCloudColor,
+		ChikouState:      ichi.ChikouState,
+		TKCross:          ichi.TKCross,
+	}
+	if ichi.TwistInBars > 0 {
+		twist := ichi.TwistInBars
+		is.TwistInBars = &twist
+	}
+	distance := 0.0
+	switch ichi.PriceVsCloud {
+	case "above":
+		distance = price - math.Max(ichi.SenkouA, ichi.SenkouB)
+	case "below":
+		distance = price - math.Min(ichi.SenkouA, ichi.SenkouB)
+	}
+	if distanceInAbsolute(units) {
+		d := roundFloat(distance, d)
+		is.CloudDistance = &d
+	}
+	if distanceInATR(units) {
+		is.CloudDistanceATR = atrMultiple(distance, atr)
+	}
+	return is
+}
+
+func buildADXSnapshot(adx *indicator.ADX, tail int, d int) *adxSnapshot {
+	return &adxSnapshot{
+		ADX:       roundFloat(adx.ADX, d),
+		PlusDI:    roundFloat(adx.PlusDI, d),
+		MinusDI:   roundFloat(adx.MinusDI, d),
+		Strength:  adx.Strength,
+		Direction: adx.Direction,
+		LastN:     roundSeriesTail(adx.Series, tail, d),
+	}
+}
+
+func buildSupertrendSnapshot(st *indicator.Supertrend, price float64, tail int, units string, atr float64, d int) *supertrendSnapshot {
+	ss := &supertrendSnapshot{
+		Band:          roundFloat(st.Band, d),
+		Direction:     st.Direction,
+		BarsSinceFlip: st.BarsSinceFlip,
+		LastN:         roundSeriesTail(st.Series, tail, d),
+	}
+	distance := price - st.Band
+	if distanceInAbsolute(units) {
+		d := roundFloat(distance, d)
+		ss.BandDistance = &d
+	}
+	if distanceInATR(units) {
+		ss.BandDistanceATR = atrMultiple(distance, atr)
+	}
+	return ss
+}
+
+func buildRSIDivergenceSnapshot(candles []market.Candle, cfg indicator.RSIDivergenceSettings, tail int, d int) *rsiDivergenceSnapshot {
+	divs := indicator.DetectRSIDivergences(candles, cfg)
+	rs := &rsiDivergenceSnapshot{Bias: indicator.RSIDivergenceBias(divs), Count: len(divs)}
+	if best, ok := indicator.StrongestRSIDivergence(divs); ok {
+		rs.Strongest = best.Grade
+	}
+	for i, div := range divs {
+		if i >= tail {
+			break
+		}
+		rs.Items = append(rs.Items, rsiDivergenceItem{
+			Bias:      div.Bias,
+			Kind:      div.Kind,
+			Grade:     div.Grade,
+			PriceFrom: roundFloat(div.PriceFrom, d),
+			PriceTo:   roundFloat(div.PriceTo, d),
+			RSIFrom:   roundFloat(div.RSIFrom, 2),
+			RSITo:     roundFloat(div.RSITo, 2),
+			BarsApart: div.BarsApart,
+			AgeBars:   div.AgeBars,
+		})
+	}
+	return rs
+}
+
+// buildCustomSnapshots 只输出 interval 匹配且最新值有效的表达式；公式已在 factory 校验，编译失败时跳过。
+func buildCustomSnapshots(candles []market.Candle, specs []CustomIndicatorSpec, interval string, tail int, d int) []customSnapshot {
+	interval = strings.ToLower(strings.TrimSpace(interval))
+	var out []customSnapshot
+	for _, spec := range specs {
+		if spec.Interval != "" && !strings.EqualFold(spec.Interval, interval) {
+			continue
+		}
+		program, err := formula.Compile(spec.Formula)
+		if err != nil {
+			continue
+		}
+		series := program.Eval(candles)
+		value, ok := formula.Last(series)
+		if !ok {
+			continue
+		}
+		start := max(len(series)-tail, 0)
+		for start < len(series) && math.IsNaN(series[start]) {
+			start++
+		}
+		out = append(out, customSnapshot{
+			Name:    spec.Feature,
+			Formula: program.Source(),
+			Value:   roundFloat(value, d),
+			LastN:   roundSeriesTail(series[start:], tail, d),
+		})
+	}
+	return out
+}
+
+func buildOrderBookSnapshot(m market.OrderBookMetrics, d int) *orderBookSnapshot {
+	wall := func(w *market.OrderBookWall) *market.OrderBookWall {
+		if w == nil {
+			return nil
+		}
+		return &market.OrderBookWall{
+			Price:       roundFloat(w.Price, d),
+			Quantity:    roundFloat(w.Quantity, d),
+			Notional:    roundFloat(w.Notional, 2),
+			DistancePct: roundFloat(w.DistancePct, 3),
+			Multiple:    roundFloat(w.Multiple, 2),
+		}
+	}
+	return &orderBookSnapshot{
+		Imbalance: roundFloat(m.Imbalance, 3),
+		SpreadBps: roundFloat(m.SpreadBps, 2),
+		BandPct:   m.BandPct,
+		BidDepth:  roundFloat(m.BidDepth, d),
+		AskDepth:  roundFloat(m.AskDepth, d),
+		BidWall:   wall(m.BidWall),
+		AskWall:   wall(m.AskWall),
+		AgeSec:    m.AgeSec,
+	}
+}
+
+func roundSeriesTail(series []float64, n int, digits int) []float64 {
 	if n <= 0 || len(series) == 0 {
 		return nil
 	}
@@ -287,12 +700,12 @@ func roundSeriesTail(series []float64, n int) []float64 {
 	}
 	out := make([]float64, 0, len(series)-start)
 	for i := start; i < len(series); i++ {
-		out = append(out, roundFloat(series[i], 4))
+		out = append(out, roundFloat(series[i], digits))
 	}
 	return out
 }
 
-func seriesBounds(series []float64) (max, min float64) {
+func seriesBounds(series []float64, digits int) (max, min float64) {
 	if len(series) == 0 {
 		return 0, 0
 	}
@@ -315,7 +728,7 @@ func seriesBounds(series []float64) (max, min float64) {
 	if min == math.MaxFloat64 {
 		min = 0
 	}
-	return roundFloat(max, 4), roundFloat(min, 4)
+	return roundFloat(max, digits), roundFloat(min, digits)
 }
 
 func roundFloat(v float64, digits int) float64 {
@@ -326,7 +739,7 @@ func roundFloat(v float64, digits int) float64 {
 	return math.Round(v*factor) / factor
 }
 
-func sanitizeSeries(series []float64) []float64 {
+func sanitizeSeries(series []float64, digits int) []float64 {
 	if len(series) == 0 {
 		return nil
 	}
@@ -335,7 +748,7 @@ func sanitizeSeries(series []float64) []float64 {
 		if math.IsNaN(v) || math.IsInf(v, 0) {
 			continue
 		}
-		out = append(out, roundFloat(v, 4))
+		out = append(out, roundFloat(v, digits))
 	}
 	return out
 }
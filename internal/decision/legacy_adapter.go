@@ -2,7 +2,7 @@ package decision
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -30,6 +30,8 @@ type DecisionEngine struct {
 	AgentNotifier   notifier.TextNotifier
 	AgentHistory    AgentOutputHistory
 	ProviderHistory ProviderOutputHistory
+	DecisionHistory DecisionHistorySource
+	History         brcfg.DecisionHistoryConfig
 
 	PromptBuilder PromptBuilder
 	PromptMgr     *strategy.Manager
@@ -53,6 +55,8 @@ type DecisionEngine struct {
 	LogEachModel bool
 
 	TimeoutSeconds int
+
+	snapshotDeltas snapshotDeltaStore
 }
 
 const priceWindowBars = 4
@@ -150,6 +154,11 @@ func (e *DecisionEngine) Decide(ctx context.Context, input Context) (DecisionRes
 // 5. Aggregate: Combine outputs using the configured strategy (FirstWins or MetaVoting).
 // 6. Trace: Log full decision trace for debugging/audit.
 func (e *DecisionEngine) decideSingle(ctx context.Context, input Context, applyDelay bool) (DecisionResult, error) {
+	started := time.Now()
+	// 模型只看到增量快照；决策日志的 SnapshotHash 仍基于完整快照。
+	fullAnalysis := input.Analysis
+	var deltaPending snapshotDeltaPending
+	input.Analysis, deltaPending = e.snapshotDeltas.apply(input.Analysis, input.ProfilePrompts)
 	insights := e.runMultiAgents(ctx, input)
 	if e.PromptBuilder == nil {
 		return DecisionResult{}, fmt.Errorf("prompt builder not configured")
@@ -157,6 +166,9 @@ func (e *DecisionEngine) decideSingle(ctx context.Context, input Context, applyD
 	if prevProviders := e.lookupPreviousProviderOutputs(ctx, input); len(prevProviders) > 0 {
 		input.PreviousProviderOutputs = prevProviders
 	}
+	if recent := e.lookupRecentDecisions(ctx, input); len(recent) > 0 {
+		input.RecentDecisions = recent
+	}
 	promptsByProvider, fallbackPrompt, err := e.prepareProviderPrompts(ctx, input, insights)
 	if err != nil {
 		return DecisionResult{}, err
@@ -165,7 +177,11 @@ func (e *DecisionEngine) decideSingle(ctx context.Context, input Context, applyD
 	baseUsr := fallbackPrompt.user
 
 	if applyDelay {
-		time.Sleep(5 * time.Second)
+		select {
+		case <-ctx.Done():
+			return DecisionResult{}, ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
 	}
 
 	outs := e.invokeProvidersWithPrompts(ctx, promptsByProvider, fallbackPrompt, input)
@@ -174,10 +190,7 @@ func (e *DecisionEngine) decideSingle(ctx context.Context, input Context, applyD
 		outs = orderOutputsByPreference(outs, e.ProviderPreference)
 	}
 
-	agg := e.Agg
-	if agg == nil {
-		agg = FirstWinsAggregator{}
-	}
+	agg := e.aggregatorFor(input)
 	if e.LogEachModel {
 		e.logModelTables(outs)
 	}
@@ -191,12 +204,13 @@ func (e *DecisionEngine) decideSingle(ctx context.Context, input Context, applyD
 	best.Parsed.Decisions = result.Decisions
 
 	traceID := uuid.NewString()
-	if e.Observer != nil {
+	capture := dryRunFrom(ctx)
+	if e.Observer != nil || capture != nil {
 		bestSys := baseSys
 		if resolved, err := resolveSystemPromptForFinalModel(input.ProfilePrompts, input.Candidates, best.ProviderID); err == nil && strings.TrimSpace(resolved) != "" {
 			bestSys = resolved
 		}
-		e.Observer.AfterDecide(ctx, DecisionTrace{
+		trace := DecisionTrace{
 			TraceID:       traceID,
 			SystemPrompt:  bestSys,
 			UserPrompt:    baseUsr,
@@ -207,13 +221,62 @@ func (e *DecisionEngine) decideSingle(ctx context.Context, input Context, applyD
 			HorizonName:   e.HorizonName,
 			Positions:     CloneSlice(input.Positions),
 			AgentInsights: CloneSlice(insights),
-		})
+			SnapshotHash:  indicatorSnapshotHash(fullAnalysis),
+			PromptVersion: profilePromptVersion(input.ProfilePrompts, input.Candidates),
+			Latency:       time.Since(started),
+		}
+		if capture != nil {
+			capture.add(trace)
+		} else {
+			e.Observer.AfterDecide(ctx, trace)
+		}
+	}
+	if capture == nil {
+		e.snapshotDeltas.commit(deltaPending)
 	}
 	result.TraceID = traceID
 	best.Parsed.TraceID = traceID
 	return result, nil
 }
 
+// RecordSkipped 把未调用模型即跳过的轮次（如交易时段外）写入决策日志，便于事后核对空窗。
+func (e *DecisionEngine) RecordSkipped(ctx context.Context, symbols []string, reason string) {
+	if e == nil || e.Observer == nil || len(symbols) == 0 {
+		return
+	}
+	e.Observer.AfterDecide(ctx, DecisionTrace{
+		TraceID:     "skip-" + uuid.NewString(),
+		Candidates:  CloneSlice(symbols),
+		Timeframes:  CloneSlice(e.Intervals),
+		HorizonName: e.HorizonName,
+		SkipReason:  reason,
+	})
+}
+
+// aggregatorFor 在当前 symbol 的 profile 开启 consensus 时改用共识聚合，权重缺省沿用 meta 聚合的配置。
+func (e *DecisionEngine) aggregatorFor(input Context) Aggregator {
+	for _, spec := range input.ProfilePrompts {
+		if !spec.Consensus.Enabled {
+			continue
+		}
+		weights := spec.Consensus.Weights
+		if len(weights) == 0 {
+			if meta, ok := e.Agg.(MetaAggregator); ok {
+				weights = meta.Weights
+			}
+		}
+		return ConsensusAggregator{
+			Weights:      weights,
+			Preference:   e.ProviderPreference,
+			MinAgreement: spec.Consensus.MinAgreement,
+		}
+	}
+	if e.Agg == nil {
+		return FirstWinsAggregator{}
+	}
+	return e.Agg
+}
+
 type providerPrompt struct {
 	system string
 	user   string
@@ -310,13 +373,7 @@ func (e *DecisionEngine) lookupPreviousProviderOutputs(ctx context.Context, inpu
 	if e == nil || e.ProviderHistory == nil {
 		return nil
 	}
-	symbol := ""
-	if len(input.Candidates) == 1 {
-		symbol = normalizeSymbol(input.Candidates[0])
-	}
-	if symbol == "" {
-		symbol = agentSymbolFromContexts(input.Analysis)
-	}
+	symbol := historySymbol(input)
 	if symbol == "" {
 		return nil
 	}
@@ -328,6 +385,38 @@ func (e *DecisionEngine) lookupPreviousProviderOutputs(ctx context.Context, inpu
 	return out
 }
 
+// lookupRecentDecisions 读取该币种最近 K 条非 hold 最终决策及其交易结果，失败时仅记录日志，不影响本轮决策。
+func (e *DecisionEngine) lookupRecentDecisions(ctx context.Context, input Context) []DecisionHistoryEntry {
+	if e == nil || e.DecisionHistory == nil || !e.History.Enabled || e.History.Limit <= 0 {
+		return nil
+	}
+	symbol := historySymbol(input)
+	if symbol == "" {
+		return nil
+	}
+	var since time.Time
+	if e.History.LookbackHours > 0 {
+		since = time.Now().Add(-time.Duration(e.History.LookbackHours) * time.Hour)
+	}
+	out, err := e.DecisionHistory.RecentDecisions(ctx, symbol, e.History.Limit, since)
+	if err != nil {
+		logger.Debugf("lookupRecentDecisions failed symbol=%s err=%v", symbol, err)
+		return nil
+	}
+	return out
+}
+
+func historySymbol(input Context) string {
+	symbol := ""
+	if len(input.Candidates) == 1 {
+		symbol = normalizeSymbol(input.Candidates[0])
+	}
+	if symbol == "" {
+		symbol = agentSymbolFromContexts(input.Analysis)
+	}
+	return symbol
+}
+
 func (e *DecisionEngine) loadTemplate(name string) string {
 	if e.PromptMgr == nil {
 		return ""
@@ -349,9 +438,11 @@ func (e *DecisionEngine) loadTemplate(name string) string {
 // 2. Checks if provider supports Vision (images) and attaches if so.
 // 3. Invokes API (p.Call).
 // 4. Attempts to extract and parse JSON from the raw text response.
-//   - Uses aggressive JSON extraction (ExtractJSON) to handle Markdown code blocks.
-//   - Validates schema (CoerceDecisionArrayJSON, ValidateDecisionArray).
+//   - Uses aggressive JSON extraction (ExtractJSON) to handle Markdown code blocks,
+//     repairing trailing commas / stray fences when the block is not valid JSON.
+//   - Validates schema (ValidateDecisionSchema, ValidateDecisionArray).
 //   - Validates business logic (validateExitPlans).
+//   - On a format error, re-prompts the model once with the validation issues.
 //
 // Returns a ModelOutput containing both raw response and parsed structure.
 func (e *DecisionEngine) callProvider(parent context.Context, p provider.ModelProvider, system, user string, baseImages []provider.ImagePayload) ModelOutput {
@@ -375,44 +466,28 @@ func (e *DecisionEngine) callProvider(parent context.Context, p provider.ModelPr
 	logAIInput("main", p.ID(), purpose, payload.System, payload.User, summarizeImagePayloads(payload.Images))
 	start := time.Now()
 	raw, err := p.Call(cctx, payload)
+	latency := time.Since(start)
 	logger.LogLLMResponse("main", p.ID(), purpose, raw)
 
 	parsed := DecisionResult{}
 	if err == nil {
-		if block, ok := jsonutil.ExtractJSON(raw); ok {
-			arr, cerr := CoerceDecisionArrayJSON(block)
-			if cerr != nil {
-				parsed.RawOutput = raw
-				parsed.RawJSON = strings.TrimSpace(block)
-				err = cerr
-			} else if qerr := ValidateDecisionArray(arr); qerr != nil {
-				parsed.RawOutput = raw
-				parsed.RawJSON = arr
-				err = qerr
-			} else {
-				var ds []Decision
-				dec := json.NewDecoder(strings.NewReader(arr))
-				dec.DisallowUnknownFields()
-				if je := dec.Decode(&ds); je == nil {
-					parsed.RawOutput = raw
-					parsed.RawJSON = arr
-					if verr := e.validateExitPlans(ds); verr != nil {
-						err = verr
-					} else {
-						parsed.Decisions = ds
-						logger.Infof("模型 %s 解析到 %d 条决策", p.ID(), len(ds))
-					}
-				} else {
-					err = je
-				}
-			}
-		} else {
-			snippet := raw
-			if len(snippet) > 160 {
-				snippet = snippet[:160] + "..."
+		parsed, err = parseDecisionOutput(raw, e.validateExitPlans)
+		var ferr *DecisionFormatError
+		if errors.As(err, &ferr) && cctx.Err() == nil {
+			// 格式问题带着校验结果重新提示一次，仍失败则按原错误返回。
+			logger.Warnf("模型 %s 输出未通过校验，重新提示一次: %v", p.ID(), ferr)
+			payload.User = decisionRepairPrompt(user, ferr)
+			retryStart := time.Now()
+			retryRaw, retryErr := p.Call(cctx, payload)
+			latency += time.Since(retryStart)
+			logger.LogLLMResponse("main", p.ID(), purpose+" retry", retryRaw)
+			if retryErr == nil {
+				raw = retryRaw
+				parsed, err = parseDecisionOutput(raw, e.validateExitPlans)
 			}
-			logger.Warnf("模型 %s 响应未包含 JSON 决策数组 elapsed=%s 片段=%q", p.ID(), time.Since(start).Truncate(time.Millisecond), snippet)
-			err = fmt.Errorf("未找到 JSON 决策数组")
+		}
+		if err == nil {
+			logger.Infof("模型 %s 解析到 %d 条决策", p.ID(), len(parsed.Decisions))
 		}
 	}
 	if err != nil {
@@ -428,6 +503,7 @@ func (e *DecisionEngine) callProvider(parent context.Context, p provider.ModelPr
 		Images:        CloneSlice(payload.Images),
 		VisionEnabled: visionEnabled,
 		ImageCount:    len(payload.Images),
+		Latency:       latency,
 	}
 }
 
@@ -651,6 +727,9 @@ func resolveSystemPromptForFinalModel(prompts map[string]ProfilePromptSpec, cand
 		if sys == "" {
 			return "", fmt.Errorf("symbol=%s 缺少 system prompt 配置 model=%s", symbol, modelID)
 		}
+		if contract := spec.Contract.Render(); contract != "" {
+			sys += "\n\n" + contract
+		}
 		return sys, nil
 	}
 	return "", fmt.Errorf("未找到 symbol=%s 对应的 profile prompts", symbol)
@@ -0,0 +1,150 @@
+package decision
+
+import (
+	"math"
+	"sort"
+
+	"brale/internal/market"
+
+	"github.com/markcheno/go-talib"
+)
+
+const (
+	structureEventSweepHigh = "liquidity_sweep_high"
+	structureEventSweepLow  = "liquidity_sweep_low"
+)
+
+// TrendStructureEvent 为结构位上发生的事件。流动性扫单：价格影线刺破分形高/低点后
+// ReclaimBars 根内收回结构位内侧，且刺破至收回区间的成交量 z-score 达到阈值。
+type TrendStructureEvent struct {
+	Type        string  `json:"type"`
+	Idx         int     `json:"idx"`
+	ReclaimIdx  int     `json:"reclaim_idx"`
+	LevelIdx    int     `json:"level_idx"`
+	Level       float64 `json:"level"`
+	Extreme     float64 `json:"extreme"`
+	VolumeZ     float64 `json:"volume_z"`
+	Bias        string  `json:"bias"`
+	ReclaimBars int     `json:"reclaim_bars"`
+	AgeCandles  int     `json:"age_candles"`
+}
+
+// DetectLiquiditySweeps 按 trend_compress 的分形规则选取结构位并识别流动性扫单，结果按刺破位置升序。
+func DetectLiquiditySweeps(candles []market.Candle, opts TrendCompressOptions) []TrendStructureEvent {
+	opts = normalizeTrendCompressOptions(opts)
+	n := len(candles)
+	if n == 0 {
+		return nil
+	}
+	closes := make([]float64, n)
+	highs := make([]float64, n)
+	lows := make([]float64, n)
+	volumes := make([]float64, n)
+	for i, c := range candles {
+		closes[i] = c.Close
+		highs[i] = c.High
+		lows[i] = c.Low
+		volumes[i] = c.Volume
+	}
+	atr := talib.Atr(highs, lows, closes, opts.ATRPeriod)
+	points := selectStructurePoints(candles, highs, lows, nil, atr, opts)
+	return detectLiquiditySweeps(highs, lows, closes, volumes, points, opts)
+}
+
+// detectLiquiditySweeps 对每个结构位只看其后第一次被刺破：刺破后 SweepReclaimBars 根内收盘回到结构位内侧才算扫单，
+// 否则视为有效突破；K 线不足以判定的刺破暂不输出。
+func detectLiquiditySweeps(highs, lows, closes, volumes []float64, points []TrendStructurePoint, opts TrendCompressOptions) []TrendStructureEvent {
+	n := len(closes)
+	var events []TrendStructureEvent
+	for _, p := range points {
+		if p.Idx < 0 || p.Idx >= n {
+			continue
+		}
+		high := p.Type == "High"
+		level := lows[p.Idx]
+		if high {
+			level = highs[p.Idx]
+		}
+		for i := p.Idx + 1; i < n; i++ {
+			breached := (high && highs[i] > level) || (!high && lows[i] < level)
+			if !breached {
+				continue
+			}
+			reclaim := -1
+			for j := i; j < n && j-i <= opts.SweepReclaimBars; j++ {
+				if (high && closes[j] < level) || (!high && closes[j] > level) {
+					reclaim = j
+					break
+				}
+			}
+			if reclaim < 0 {
+				break
+			}
+			extreme := lows[i]
+			if high {
+				extreme = highs[i]
+			}
+			z := volumeZScore(volumes, i, opts.VolumeMAPeriod)
+			for j := i + 1; j <= reclaim; j++ {
+				if high {
+					extreme = math.Max(extreme, highs[j])
+				} else {
+					extreme = math.Min(extreme, lows[j])
+				}
+				z = math.Max(z, volumeZScore(volumes, j, opts.VolumeMAPeriod))
+			}
+			if z < opts.SweepVolumeZ {
+				break
+			}
+			ev := TrendStructureEvent{
+				Type:        structureEventSweepLow,
+				Idx:         i,
+				ReclaimIdx:  reclaim,
+				LevelIdx:    p.Idx,
+				Level:       roundFloat(level, 4),
+				Extreme:     roundFloat(extreme, 4),
+				VolumeZ:     roundFloat(z, 2),
+				Bias:        "bullish",
+				ReclaimBars: reclaim - i,
+				AgeCandles:  n - 1 - reclaim,
+			}
+			if high {
+				ev.Type = structureEventSweepHigh
+				ev.Bias = "bearish"
+			}
+			events = append(events, ev)
+			break
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Idx < events[j].Idx })
+	if len(events) > opts.MaxStructureEvents {
+		events = events[len(events)-opts.MaxStructureEvents:]
+	}
+	return events
+}
+
+// volumeZScore 以 idx 之前 lookback 根的均值与标准差计算 idx 根成交量的 z-score，样本不足或无波动时为 0。
+func volumeZScore(volumes []float64, idx, lookback int) float64 {
+	start := idx - lookback
+	if start < 0 {
+		start = 0
+	}
+	window := volumes[start:idx]
+	if len(window) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range window {
+		mean += v
+	}
+	mean /= float64(len(window))
+	variance := 0.0
+	for _, v := range window {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / float64(len(window)))
+	if std == 0 {
+		return 0
+	}
+	return (volumes[idx] - mean) / std
+}
@@ -113,7 +113,11 @@ func buildTrendAgentPrompt(ctxs []AnalysisContext, cfg brcfg.MultiAgentConfig) s
 	b.WriteString("# Trend Structured Blocks\n")
 	b.WriteString("每个区块为 JSON：meta + structure_points(Fractal拐点) + structure_candidates(含 price/type/source/age_candles/window) + recent_candles + global_context。\n")
 	b.WriteString("You must SELECT, not CREATE；支撑/阻力/失效位只能从 structure_candidates/structure_points 选择。\n")
-	b.WriteString("idx 为 0-based，数值越大越新；禁止方向词，必须引用输入字段名/索引。\n\n")
+	b.WriteString("idx 为 0-based，数值越大越新；禁止方向词，必须引用输入字段名/索引。\n")
+	if hasATRDistances(ctxs) {
+		b.WriteString("dist_atr/ema*_dist_atr = (价位 - 现价) / ATR，正值表示在现价上方。\n")
+	}
+	b.WriteString("\n")
 	count := 0
 	opts := DefaultTrendCompressOptions()
 	for _, ac := range ctxs {
@@ -123,6 +127,7 @@ func buildTrendAgentPrompt(ctxs []AnalysisContext, cfg brcfg.MultiAgentConfig) s
 		if raw != "" {
 			var candles []market.Candle
 			if err := json.Unmarshal([]byte(raw), &candles); err == nil && len(candles) > 0 {
+				opts.DistanceUnits = ac.DistanceUnits
 				if built, err := BuildTrendCompressedJSON(ac.Symbol, ac.Interval, candles, opts); err == nil {
 					payload = strings.TrimSpace(built)
 				}
@@ -174,3 +179,12 @@ func formatAgentStageTitle(stage string) string {
 		return strings.ToUpper(stage[:1]) + stage[1:] + " Agent"
 	}
 }
+
+func hasATRDistances(ctxs []AnalysisContext) bool {
+	for _, ac := range ctxs {
+		if distanceInATR(ac.DistanceUnits) {
+			return true
+		}
+	}
+	return false
+}
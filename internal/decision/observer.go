@@ -1,6 +1,9 @@
 package decision
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // DecisionObserver receives decision results for logging/notification.
 // Called after each decision cycle with full trace data.
@@ -21,4 +24,8 @@ type DecisionTrace struct {
 	HorizonName   string        // Active profile group
 	Positions     []PositionSnapshot
 	AgentInsights []AgentInsight // Multi-agent intermediate reasoning
+	SnapshotHash  string         // Hash of indicator snapshots fed into the prompt
+	PromptVersion string         // Version hash of the profile prompt templates used this round
+	Latency       time.Duration  // Whole decision round, prompt build to aggregation
+	SkipReason    string         // Non-empty when the round was skipped without calling models (e.g. outside trading session)
 }
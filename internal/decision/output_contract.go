@@ -0,0 +1,131 @@
+package decision
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// OutputContract is the per-profile description of what the model must return.
+// The same definition is rendered into the system prompt and enforced by CheckOutputContract.
+type OutputContract struct {
+	Language          string
+	ReasoningMaxChars int
+	PriceUnit         string
+	SizeUnit          string
+	RequiredOnOpen    []string
+}
+
+var contractLanguageNames = map[string]string{
+	"zh": "简体中文",
+	"en": "English",
+}
+
+var contractFieldCheckers = map[string]func(Decision) bool{
+	"stop_loss":         func(d Decision) bool { return d.StopLoss > 0 },
+	"take_profit":       func(d Decision) bool { return d.TakeProfit > 0 },
+	"confidence":        func(d Decision) bool { return d.Confidence > 0 },
+	"reasoning":         func(d Decision) bool { return strings.TrimSpace(d.Reasoning) != "" },
+	"leverage":          func(d Decision) bool { return d.Leverage > 0 },
+	"position_size_usd": func(d Decision) bool { return d.PositionSizeUSD > 0 },
+	"exit_plan":         func(d Decision) bool { return d.ExitPlan != nil && strings.TrimSpace(d.ExitPlan.ID) != "" },
+}
+
+func (c OutputContract) IsZero() bool {
+	return c.Language == "" && c.ReasoningMaxChars <= 0 && c.PriceUnit == "" && c.SizeUnit == "" && len(c.RequiredOnOpen) == 0
+}
+
+// Render returns the prompt block describing the contract; empty when nothing is configured.
+func (c OutputContract) Render() string {
+	if c.IsZero() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("### 输出契约\n")
+	if lang := c.languageName(); lang != "" {
+		b.WriteString(fmt.Sprintf("- reasoning 必须使用%s书写\n", lang))
+	}
+	if c.ReasoningMaxChars > 0 {
+		b.WriteString(fmt.Sprintf("- reasoning 不超过 %d 字\n", c.ReasoningMaxChars))
+	}
+	if c.PriceUnit != "" {
+		b.WriteString(fmt.Sprintf("- stop_loss/take_profit/exit_plan 中的价格单位: %s\n", c.PriceUnit))
+	}
+	if c.SizeUnit != "" {
+		b.WriteString(fmt.Sprintf("- position_size_usd 单位: %s\n", c.SizeUnit))
+	}
+	if len(c.RequiredOnOpen) > 0 {
+		b.WriteString(fmt.Sprintf("- open_long/open_short 必须包含字段: %s\n", strings.Join(c.RequiredOnOpen, ", ")))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (c OutputContract) languageName() string {
+	if c.Language == "" {
+		return ""
+	}
+	if name, ok := contractLanguageNames[c.Language]; ok {
+		return name
+	}
+	return c.Language
+}
+
+// UnknownContractFields returns the required_on_open names that have no checker.
+func UnknownContractFields(fields []string) []string {
+	var unknown []string
+	for _, field := range fields {
+		if _, ok := contractFieldCheckers[field]; !ok {
+			unknown = append(unknown, field)
+		}
+	}
+	return unknown
+}
+
+// CheckOutputContract validates a parsed open decision against the profile contract;
+// close/hold/update_exit_plan are never rejected by it.
+// Field names are validated at profile load, so unknown names are not expected here.
+func CheckOutputContract(d Decision, c OutputContract) error {
+	if c.IsZero() || !isOpenAction(d.Action) {
+		return nil
+	}
+	reasoning := strings.TrimSpace(d.Reasoning)
+	if c.ReasoningMaxChars > 0 && utf8.RuneCountInString(reasoning) > c.ReasoningMaxChars {
+		return fmt.Errorf("reasoning 超出长度限制: %d > %d", utf8.RuneCountInString(reasoning), c.ReasoningMaxChars)
+	}
+	if reasoning != "" {
+		switch c.Language {
+		case "zh":
+			if !containsHan(reasoning) {
+				return fmt.Errorf("reasoning 未使用约定语言 zh")
+			}
+		case "en":
+			if containsHan(reasoning) {
+				return fmt.Errorf("reasoning 未使用约定语言 en")
+			}
+		}
+	}
+	for _, field := range c.RequiredOnOpen {
+		check, ok := contractFieldCheckers[field]
+		if !ok {
+			continue
+		}
+		if !check(d) {
+			return fmt.Errorf("开仓缺少契约字段: %s", field)
+		}
+	}
+	return nil
+}
+
+func isOpenAction(action string) bool {
+	return action == "open_long" || action == "open_short"
+}
+
+func containsHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,10 @@
 package decision
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	"brale/internal/exitplan"
-	"brale/internal/pkg/jsonutil"
 )
 
 type Parser struct {
@@ -19,42 +17,9 @@ func NewParser(exitPlans *exitplan.Registry) *Parser {
 	}
 }
 
+// Parse 解析模型输出；格式问题以 *DecisionFormatError 返回，调用方可据此重新提示模型。
 func (p *Parser) Parse(raw string) (DecisionResult, error) {
-	parsed := DecisionResult{
-		RawOutput: raw,
-	}
-
-	block, ok := jsonutil.ExtractJSON(raw)
-	if !ok {
-		return parsed, fmt.Errorf("未找到 JSON 决策数组")
-	}
-
-	arr, err := CoerceDecisionArrayJSON(block)
-	if err != nil {
-		parsed.RawJSON = strings.TrimSpace(block)
-		return parsed, err
-	}
-
-	if qerr := ValidateDecisionArray(arr); qerr != nil {
-		parsed.RawJSON = arr
-		return parsed, qerr
-	}
-
-	var ds []Decision
-	dec := json.NewDecoder(strings.NewReader(arr))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(&ds); err != nil {
-		parsed.RawJSON = arr
-		return parsed, err
-	}
-
-	parsed.RawJSON = arr
-	if verr := p.validateExitPlans(ds); verr != nil {
-		return parsed, verr
-	}
-
-	parsed.Decisions = ds
-	return parsed, nil
+	return parseDecisionOutput(raw, p.validateExitPlans)
 }
 
 func (p *Parser) validateExitPlans(decisions []Decision) error {
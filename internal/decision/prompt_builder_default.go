@@ -22,6 +22,8 @@ type DefaultPromptBuilder struct {
 	FearGreed             *market.FearGreedService
 	Intervals             []string
 	DebugStructuredBlocks bool
+	// HistoryMaxChars 为历史决策段落的字符上限，<=0 不限制。
+	HistoryMaxChars int
 }
 
 func NewDefaultPromptBuilder(promptMgr *strategy.Manager, store market.KlineStore, metrics *market.MetricsService, sentiment *market.SentimentService, fearGreed *market.FearGreedService, intervals []string, debug bool) *DefaultPromptBuilder {
@@ -73,9 +75,11 @@ func (b *DefaultPromptBuilder) buildUserSummary(ctx context.Context, input Conte
 		Account:           b.renderAccountOverview(input.Account, augmentMarketData(input.Market, input.Analysis)),
 		Previous:          b.renderPreviousReasoning(input.PreviousReasoning),
 		PreviousProviders: b.renderPreviousProviderOutputs(input.PreviousProviderOutputs),
+		RecentDecisions:   b.renderRecentDecisions(input.RecentDecisions, input.TimestampNow),
 		Derivatives:       "", // provider 阶段无需在主 prompt 展示衍生品数据
 		Positions:         b.renderPositionDetails(filterPositions(input.Positions, input.Candidates)),
 		Klines:            b.renderKlineWindows(input.Analysis, input.Directives),
+		Confluence:        renderConfluence(input.Analysis),
 		Agents:            b.renderAgentBlocks(insights),
 		Guidelines:        b.renderOutputConstraints(input),
 	}
@@ -448,6 +448,62 @@ func (b *DefaultPromptBuilder) renderPreviousProviderOutputs(outputs []ProviderO
 	return sb.String()
 }
 
+// renderRecentDecisions 按新→旧列出该币种近期决策及结果；超出 HistoryMaxChars 时丢弃较旧条目。
+func (b *DefaultPromptBuilder) renderRecentDecisions(entries []DecisionHistoryEntry, now time.Time) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	const header = "\n## 近期决策与结果（新→旧，勿重复推荐已失败的同类形态）\n"
+	var sb strings.Builder
+	sb.WriteString(header)
+	written := 0
+	for _, ent := range entries {
+		line := formatDecisionHistoryLine(ent, now)
+		if b.HistoryMaxChars > 0 && written > 0 && sb.Len()+len(line) > b.HistoryMaxChars {
+			sb.WriteString(fmt.Sprintf("- 更早 %d 条已省略\n", len(entries)-written))
+			break
+		}
+		sb.WriteString(line)
+		written++
+	}
+	return sb.String()
+}
+
+func formatDecisionHistoryLine(ent DecisionHistoryEntry, now time.Time) string {
+	action := strings.TrimSpace(ent.Action)
+	if action == "" {
+		action = "unknown"
+	}
+	ts := time.UnixMilli(ent.Timestamp).UTC()
+	age := now.Sub(ts).Truncate(time.Minute)
+	var outcome string
+	switch ent.Outcome {
+	case DecisionOutcomeOpen:
+		outcome = fmt.Sprintf("持仓中 %+.2f%%", ent.PnLRatio*100)
+	case DecisionOutcomeWin:
+		outcome = fmt.Sprintf("盈利 %+.2f%%", ent.PnLRatio*100)
+	case DecisionOutcomeLoss:
+		outcome = fmt.Sprintf("亏损 %+.2f%%", ent.PnLRatio*100)
+	case DecisionOutcomeFlat:
+		outcome = "持平"
+	case DecisionOutcomeCanceled:
+		outcome = "未成交/已撤销"
+	default:
+		outcome = "无关联交易"
+	}
+	if ent.ClosedAt > 0 && ent.Outcome != DecisionOutcomeOpen {
+		outcome += fmt.Sprintf("，平仓于 %s", time.UnixMilli(ent.ClosedAt).UTC().Format("01-02 15:04"))
+	}
+	line := fmt.Sprintf("- %s（%s前）%s → %s", ts.Format("01-02 15:04Z"), formatutil.Duration(age.Milliseconds()), action, outcome)
+	if reason := strings.TrimSpace(ent.Reasoning); reason != "" {
+		line += "；理由：" + textutil.Truncate(strings.ReplaceAll(reason, "\n", " "), 160)
+	}
+	return line + "\n"
+}
+
 func formatProviderOutputSummary(out ProviderOutputSnapshot) string {
 	if len(out.Decisions) == 0 {
 		raw := strings.TrimSpace(out.RawOutput)
@@ -591,12 +647,13 @@ func buildKlineWindow(ac AnalysisContext) (klineWindow, bool, string) {
 		return klineWindow{}, false, ""
 	}
 	win := klineWindow{
-		Symbol:   symbol,
-		Interval: interval,
-		Horizon:  strings.TrimSpace(ac.ForecastHorizon),
-		Trend:    ac.TrendReport,
-		CSV:      csvData,
-		Bars:     bars,
+		Symbol:    symbol,
+		Interval:  interval,
+		Horizon:   strings.TrimSpace(ac.ForecastHorizon),
+		Trend:     ac.TrendReport,
+		CSV:       csvData,
+		Bars:      bars,
+		Composite: ac.Composite,
 	}
 	return win, true, buildLatestPriceLine(symbol, bars)
 }
@@ -635,7 +692,12 @@ func renderKlineWindowsOutput(windows []klineWindow, latestLine string) string {
 	if latestLine != "" {
 		sb.WriteString(latestLine + "\n")
 	}
+	lastSymbol := ""
 	for _, win := range windows {
+		if win.Symbol != lastSymbol {
+			writeCompositeScore(&sb, win)
+			lastSymbol = win.Symbol
+		}
 		writeKlineWindow(&sb, win)
 	}
 	sb.WriteString("请结合这些时间窗口评估当前价格位置与动量。\n")
@@ -670,6 +732,18 @@ func writeKlineWindow(sb *strings.Builder, win klineWindow) {
 	}
 }
 
+// writeCompositeScore 在每个 symbol 的首个窗口前输出一次跨周期综合评分，作为定量锚点。
+func writeCompositeScore(sb *strings.Builder, win klineWindow) {
+	if sb == nil || win.Composite == nil || len(win.Composite.Intervals) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(win.Composite.Intervals))
+	for _, is := range win.Composite.Intervals {
+		parts = append(parts, fmt.Sprintf("%s=%+.2f×%g(%s)", is.Interval, is.Score, is.Weight, is.Regime))
+	}
+	fmt.Fprintf(sb, "- %s Composite: %+.1f/100 [%s]\n", win.Symbol, win.Composite.Score, strings.Join(parts, ", "))
+}
+
 func logStructuredBlocksDebug(debug bool, ctxs []AnalysisContext) {
 	if !debug || len(ctxs) == 0 {
 		return
@@ -734,6 +808,16 @@ func (b *DefaultPromptBuilder) renderHeader(input Context) string {
 			sb.WriteString(fmt.Sprintf("_meta.data_age_sec.%s: %d\n", k, age))
 		}
 	}
+	if len(input.DaysToExpiry) > 0 {
+		keys := make([]string, 0, len(input.DaysToExpiry))
+		for k := range input.DaysToExpiry {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("_meta.days_to_expiry.%s: %.2f\n", k, input.DaysToExpiry[k]))
+		}
+	}
 	sb.WriteString(fmt.Sprintf("hard_flags.liq_risk_flag: %v\n", input.HardFlags.LiqRiskFlag))
 	sb.WriteString(fmt.Sprintf("hard_flags.data_stale_flag: %v\n", input.HardFlags.DataStaleFlag))
 	sb.WriteString("\n")
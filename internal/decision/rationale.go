@@ -0,0 +1,145 @@
+package decision
+
+import (
+	"strings"
+)
+
+const (
+	RationaleSourceModel  = "model"
+	RationaleSourceParsed = "parsed"
+
+	maxRationaleDrivers = 8
+)
+
+// Rationale 为决策的结构化理由：驱动该决策的指标、失效价位与信心（0-100）。
+// Source=model 表示模型直接输出了 rationale，parsed 表示由 reasoning/止损/confidence 推断。
+type Rationale struct {
+	Drivers      []string `json:"drivers,omitempty"`
+	Invalidation float64  `json:"invalidation,omitempty"`
+	Confidence   int      `json:"confidence,omitempty"`
+	Source       string   `json:"source,omitempty"`
+}
+
+// rationaleKeywords 为从 reasoning 中识别驱动指标的关键词（小写匹配），按输出顺序排列。
+var rationaleKeywords = []struct {
+	driver   string
+	keywords []string
+}{
+	{"trend", []string{"trend", "趋势"}},
+	{"ema", []string{"ema", "均线"}},
+	{"macd", []string{"macd"}},
+	{"rsi", []string{"rsi"}},
+	{"bollinger", []string{"boll", "布林"}},
+	{"atr", []string{"atr", "波动率"}},
+	{"divergence", []string{"divergence", "背离"}},
+	{"support_resistance", []string{"support", "resistance", "支撑", "阻力", "压力位"}},
+	{"volume", []string{"volume", "成交量", "放量", "缩量"}},
+	{"cvd", []string{"cvd"}},
+	{"open_interest", []string{"open interest", "持仓量", "oi "}},
+	{"funding", []string{"funding", "资金费率"}},
+	{"orderbook", []string{"orderbook", "order book", "订单簿", "盘口"}},
+	{"sentiment", []string{"sentiment", "fear", "greed", "情绪", "恐慌", "贪婪"}},
+}
+
+// ExtractRationale 补全决策的结构化理由：模型给出的字段优先，缺失的驱动指标从 reasoning 关键词推断，
+// 失效价位退回 stop_loss，信心与 confidence 字段互相补齐。hold 且无任何信息时不生成。
+func ExtractRationale(d *Decision) {
+	if d == nil {
+		return
+	}
+	r := Rationale{Source: RationaleSourceParsed}
+	if d.Rationale != nil {
+		r = *d.Rationale
+		r.Source = RationaleSourceModel
+	}
+	r.Drivers = normalizeDrivers(r.Drivers)
+	if len(r.Drivers) == 0 {
+		r.Drivers = driversFromReasoning(d.Reasoning)
+	}
+	if r.Invalidation <= 0 && d.StopLoss > 0 {
+		r.Invalidation = d.StopLoss
+	}
+	if r.Confidence <= 0 || r.Confidence > 100 {
+		r.Confidence = d.Confidence
+	}
+	if d.Confidence <= 0 && r.Confidence > 0 {
+		d.Confidence = r.Confidence
+	}
+	if len(r.Drivers) == 0 && r.Invalidation <= 0 && r.Confidence <= 0 {
+		d.Rationale = nil
+		return
+	}
+	d.Rationale = &r
+}
+
+func driversFromReasoning(reasoning string) []string {
+	text := " " + strings.ToLower(reasoning) + " "
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	var out []string
+	for _, item := range rationaleKeywords {
+		for _, kw := range item.keywords {
+			if strings.Contains(text, kw) {
+				out = append(out, item.driver)
+				break
+			}
+		}
+		if len(out) >= maxRationaleDrivers {
+			break
+		}
+	}
+	return out
+}
+
+func normalizeDrivers(in []string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(in))
+	out := make([]string, 0, len(in))
+	for _, v := range in {
+		v = strings.TrimSpace(strings.ReplaceAll(v, ",", " "))
+		key := strings.ToLower(v)
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, v)
+		if len(out) >= maxRationaleDrivers {
+			break
+		}
+	}
+	return out
+}
+
+// parseRationale 宽松解析模型输出的 rationale：drivers 可为数组或逗号分隔字符串，失效价位兼容 invalidation_price。
+func parseRationale(v any) *Rationale {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	r := &Rationale{
+		Invalidation: coerceFloat64(m["invalidation"]),
+		Confidence:   coerceInt(m["confidence"]),
+	}
+	if r.Invalidation <= 0 {
+		r.Invalidation = coerceFloat64(m["invalidation_price"])
+	}
+	switch x := m["drivers"].(type) {
+	case []any:
+		for _, item := range x {
+			if s := coerceString(item); s != "" {
+				r.Drivers = append(r.Drivers, s)
+			}
+		}
+	case string:
+		for _, s := range strings.FieldsFunc(x, func(c rune) bool { return c == ',' || c == '，' || c == '、' }) {
+			r.Drivers = append(r.Drivers, s)
+		}
+	}
+	return r
+}
@@ -16,15 +16,17 @@ type Sections struct {
 	Account           string
 	Previous          string
 	PreviousProviders string
+	RecentDecisions   string
 	Derivatives       string
 	Positions         string
 	Klines            string
+	Confluence        string
 	Agents            string
 	Guidelines        string
 }
 
 const defaultTemplate = `# 决策输入（Multi-Agent 汇总）
-{{if .Header}}{{.Header}}{{end}}{{if .Account}}{{.Account}}{{end}}{{if .Previous}}{{.Previous}}{{end}}{{if .Derivatives}}{{.Derivatives}}{{end}}{{if .PreviousProviders}}{{.PreviousProviders}}{{end}}{{if .Klines}}{{.Klines}}{{end}}{{if .Positions}}{{.Positions}}{{end}}{{if .Agents}}{{.Agents}}{{end}}
+{{if .Header}}{{.Header}}{{end}}{{if .Account}}{{.Account}}{{end}}{{if .Previous}}{{.Previous}}{{end}}{{if .Derivatives}}{{.Derivatives}}{{end}}{{if .PreviousProviders}}{{.PreviousProviders}}{{end}}{{if .RecentDecisions}}{{.RecentDecisions}}{{end}}{{if .Klines}}{{.Klines}}{{end}}{{if .Confluence}}{{.Confluence}}{{end}}{{if .Positions}}{{.Positions}}{{end}}{{if .Agents}}{{.Agents}}{{end}}
 {{.Guidelines}}`
 
 var defaultSummaryTemplate = template.Must(template.New("user_summary_default").Parse(defaultTemplate))
@@ -67,9 +69,15 @@ func fallbackSummary(sections Sections) string {
 	if s := strings.TrimSpace(sections.PreviousProviders); s != "" {
 		b.WriteString(s)
 	}
+	if s := strings.TrimSpace(sections.RecentDecisions); s != "" {
+		b.WriteString(s)
+	}
 	if s := strings.TrimSpace(sections.Klines); s != "" {
 		b.WriteString(s)
 	}
+	if s := strings.TrimSpace(sections.Confluence); s != "" {
+		b.WriteString(s)
+	}
 	if s := strings.TrimSpace(sections.Positions); s != "" {
 		b.WriteString(s)
 	}
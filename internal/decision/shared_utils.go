@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 
+	"brale/internal/analysis/screen"
 	"brale/internal/gateway/provider"
 	"brale/internal/logger"
 	"brale/internal/market"
@@ -12,12 +13,13 @@ import (
 )
 
 type klineWindow struct {
-	Symbol   string
-	Interval string
-	Horizon  string
-	Trend    string
-	CSV      string
-	Bars     []market.Candle
+	Symbol    string
+	Interval  string
+	Horizon   string
+	Trend     string
+	CSV       string
+	Bars      []market.Candle
+	Composite *screen.Composite
 }
 
 func buildIntervalRank(intervals []string) map[string]int {
@@ -0,0 +1,240 @@
+package decision
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SnapshotDeltaSpec 为 profile 级增量快照配置，随 ProfilePromptSpec 传入。
+type SnapshotDeltaSpec struct {
+	Enabled           bool
+	Epsilon           map[string]float64
+	DefaultEpsilonPct float64
+	FullEvery         int
+}
+
+// snapshotDeltaStore 按 symbol|interval 保存上一轮发送给模型的指标值，用于生成增量快照。
+type snapshotDeltaStore struct {
+	mu   sync.Mutex
+	prev map[string]snapshotBaseline
+}
+
+type snapshotBaseline struct {
+	data      map[string]any
+	sampledAt string
+	// rounds 为自上次完整快照以来发送增量的轮数。
+	rounds int
+}
+
+// snapshotDeltaPending 为本轮生成增量后待提交的基线，模型调用成功后才写入，避免模型未看到的值被当作已发送。
+type snapshotDeltaPending map[string]snapshotBaseline
+
+// apply 把启用了增量快照的 symbol 的 IndicatorJSON 替换为相对基线的增量，返回替换后的副本与待提交基线。
+func (s *snapshotDeltaStore) apply(ctxs []AnalysisContext, specs map[string]ProfilePromptSpec) ([]AnalysisContext, snapshotDeltaPending) {
+	if len(ctxs) == 0 || len(specs) == 0 {
+		return ctxs, nil
+	}
+	var out []AnalysisContext
+	pending := make(snapshotDeltaPending)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, ac := range ctxs {
+		spec, ok := snapshotDeltaSpecFor(specs, ac.Symbol)
+		if !ok || strings.TrimSpace(ac.IndicatorJSON) == "" {
+			continue
+		}
+		var cur map[string]any
+		if err := json.Unmarshal([]byte(ac.IndicatorJSON), &cur); err != nil {
+			continue
+		}
+		data, _ := cur["data"].(map[string]any)
+		if data == nil {
+			continue
+		}
+		sampledAt := snapshotSampledAt(cur)
+		key := strings.ToUpper(strings.TrimSpace(ac.Symbol)) + "|" + strings.ToLower(strings.TrimSpace(ac.Interval))
+		base, ok := s.prev[key]
+		if !ok || base.rounds+1 >= spec.FullEvery {
+			// 首轮或到达完整快照周期时发送完整快照并重置基线。
+			pending[key] = snapshotBaseline{data: data, sampledAt: sampledAt}
+			continue
+		}
+		changed, unchangedBlocks, unchangedFields := diffSnapshotData(base.data, data, spec)
+		meta, _ := cur["_meta"].(map[string]any)
+		if meta == nil {
+			meta = map[string]any{}
+		}
+		meta["mode"] = "delta"
+		meta["base_sampled_at"] = base.sampledAt
+		delta := map[string]any{
+			"_meta":   meta,
+			"market":  cur["market"],
+			"changed": changed,
+			"_unchanged": map[string]any{
+				"blocks": unchangedBlocks,
+				"fields": unchangedFields,
+			},
+		}
+		raw, err := json.Marshal(delta)
+		if err != nil {
+			continue
+		}
+		if out == nil {
+			out = CloneSlice(ctxs)
+		}
+		out[i].IndicatorJSON = string(raw)
+		pending[key] = snapshotBaseline{data: mergeSnapshotData(base.data, changed), sampledAt: sampledAt, rounds: base.rounds + 1}
+	}
+	if out == nil {
+		out = ctxs
+	}
+	return out, pending
+}
+
+func (s *snapshotDeltaStore) commit(pending snapshotDeltaPending) {
+	if len(pending) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prev == nil {
+		s.prev = make(map[string]snapshotBaseline, len(pending))
+	}
+	for key, base := range pending {
+		s.prev[key] = base
+	}
+}
+
+func snapshotDeltaSpecFor(specs map[string]ProfilePromptSpec, symbol string) (SnapshotDeltaSpec, bool) {
+	target := normalizeSymbol(symbol)
+	for sym, spec := range specs {
+		if normalizeSymbol(sym) == target && spec.SnapshotDelta.Enabled {
+			return spec.SnapshotDelta, true
+		}
+	}
+	return SnapshotDeltaSpec{}, false
+}
+
+func snapshotSampledAt(snapshot map[string]any) string {
+	meta, _ := snapshot["_meta"].(map[string]any)
+	at, _ := meta["sampled_at"].(string)
+	return at
+}
+
+// diffSnapshotData 逐块比较快照 data，返回有明显变化的字段（保持嵌套结构）、完全未变的数据块与未变字段数。
+func diffSnapshotData(base, cur map[string]any, spec SnapshotDeltaSpec) (map[string]any, []string, int) {
+	changed := make(map[string]any)
+	unchangedBlocks := make([]string, 0)
+	unchangedFields := 0
+	for block, val := range cur {
+		sub, n := diffSnapshotValue(block, block, base[block], val, spec)
+		if sub == nil {
+			unchangedBlocks = append(unchangedBlocks, block)
+			continue
+		}
+		changed[block] = sub
+		unchangedFields += n
+	}
+	sort.Strings(unchangedBlocks)
+	return changed, unchangedBlocks, unchangedFields
+}
+
+// diffSnapshotValue 返回 cur 相对 base 的变化部分（nil 表示无明显变化）以及其中未变化的字段数（序列按一个字段计）。
+func diffSnapshotValue(block, path string, base, cur any, spec SnapshotDeltaSpec) (any, int) {
+	switch c := cur.(type) {
+	case map[string]any:
+		b, _ := base.(map[string]any)
+		out := make(map[string]any)
+		unchanged := 0
+		for k, v := range c {
+			sub, n := diffSnapshotValue(block, path+"."+k, b[k], v, spec)
+			unchanged += n
+			if sub != nil {
+				out[k] = sub
+			}
+		}
+		if len(out) == 0 {
+			return nil, unchanged
+		}
+		return out, unchanged
+	case []any:
+		b, _ := base.([]any)
+		if len(b) != len(c) {
+			return c, 0
+		}
+		for i := range c {
+			if snapshotValueChanged(block, path, b[i], c[i], spec) {
+				return c, 0
+			}
+		}
+		return nil, 1
+	default:
+		if snapshotValueChanged(block, path, base, cur, spec) {
+			return cur, 0
+		}
+		return nil, 1
+	}
+}
+
+func snapshotValueChanged(block, path string, base, cur any, spec SnapshotDeltaSpec) bool {
+	cf, cok := cur.(float64)
+	bf, bok := base.(float64)
+	if !cok || !bok {
+		return !snapshotScalarEqual(base, cur)
+	}
+	diff := math.Abs(cf - bf)
+	if eps, ok := snapshotEpsilon(block, path, spec); ok {
+		return diff > eps
+	}
+	scale := math.Max(math.Abs(cf), math.Abs(bf))
+	return diff > scale*spec.DefaultEpsilonPct/100
+}
+
+// snapshotEpsilon 依次按完整路径、数据块、字段名查找绝对阈值。
+func snapshotEpsilon(block, path string, spec SnapshotDeltaSpec) (float64, bool) {
+	if len(spec.Epsilon) == 0 {
+		return 0, false
+	}
+	if eps, ok := spec.Epsilon[path]; ok {
+		return eps, true
+	}
+	if eps, ok := spec.Epsilon[block]; ok {
+		return eps, true
+	}
+	leaf := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		leaf = path[i+1:]
+	}
+	eps, ok := spec.Epsilon[leaf]
+	return eps, ok
+}
+
+func snapshotScalarEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ab, err1 := json.Marshal(a)
+	bb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(ab) == string(bb)
+}
+
+// mergeSnapshotData 以 changed 覆盖 base 得到新基线：未发送的小幅变化继续与旧值比较，避免缓慢漂移被持续忽略。
+func mergeSnapshotData(base, changed map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(changed))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range changed {
+		if sub, ok := v.(map[string]any); ok {
+			if prev, ok := out[k].(map[string]any); ok {
+				out[k] = mergeSnapshotData(prev, sub)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
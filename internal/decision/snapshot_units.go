@@ -0,0 +1,176 @@
+package decision
+
+import (
+	"strings"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/market"
+
+	talib "github.com/markcheno/go-talib"
+)
+
+// 快照中价格距离字段的表达单位：absolute 为原始价差，atr 为 ATR 倍数，both 同时输出。
+const (
+	DistanceUnitsAbsolute = "absolute"
+	DistanceUnitsATR      = "atr"
+	DistanceUnitsBoth     = "both"
+)
+
+const snapshotATRPeriod = 14
+
+// 快照可裁剪的数据块名称。
+const (
+	SnapshotBlockEMA        = "ema"
+	SnapshotBlockMACD       = "macd"
+	SnapshotBlockRSI        = "rsi"
+	SnapshotBlockOBV        = "obv"
+	SnapshotBlockStoch      = "stoch"
+	SnapshotBlockStochRSI   = "stoch_rsi"
+	SnapshotBlockConnorsRSI = "connors_rsi"
+	SnapshotBlockATR        = "atr"
+	SnapshotBlockIchimoku   = "ichimoku"
+	SnapshotBlockADX        = "adx"
+	SnapshotBlockSupertrend = "supertrend"
+	SnapshotBlockOrderBook  = "order_book"
+	// SnapshotBlockRSIDivergence 仅在 profile 配置了 rsi_divergence 中间件时输出。
+	SnapshotBlockRSIDivergence = "rsi_divergence"
+	// SnapshotBlockCustom 仅在 profile 配置了 custom_indicator 中间件时输出。
+	SnapshotBlockCustom = "custom"
+)
+
+const defaultSnapshotPrecision = 4
+
+// SnapshotOptions 控制指标快照的数值归一化方式；Preset 为指标参数预设名，空或未知时使用内置默认。
+// Blocks/Tails/Precision 任一被设置时快照版本升为 v2，并在 _meta 中写明实际生效的裁剪设置。
+type SnapshotOptions struct {
+	DistanceUnits string
+	Preset        string
+	// Blocks 为输出的数据块白名单，为空时全部输出；未知名称忽略。
+	Blocks []string
+	// Tails 按数据块覆盖 last_n 长度，0 表示不输出序列。
+	Tails map[string]int
+	// Precision 为数值保留的小数位，<=0 时为 4。
+	Precision int
+	// StochRSI/ConnorsRSI 覆盖预设参数，零值字段沿用预设。
+	StochRSI   indicator.StochRSISettings
+	ConnorsRSI indicator.ConnorsRSISettings
+	// EMAPeriods 按周期（default 为兜底）指定 EMA 周期；命中时快照输出 emas 数组，替代 ema_fast/mid/slow。
+	EMAPeriods map[string][]int
+	// RSIDivergence 为 rsi_divergence 中间件的参数，Period<=0 时不输出 rsi_divergence 块。
+	RSIDivergence indicator.RSIDivergenceSettings
+	// CustomIndicators 为 custom_indicator 中间件的表达式，按 Interval 输出到对应周期的 custom 块。
+	CustomIndicators []CustomIndicatorSpec
+
+	// orderBook 为构建时注入的盘口指标（非配置项），为 nil 时不输出 order_book 块。
+	orderBook *market.OrderBookMetrics
+}
+
+// CustomIndicatorSpec 为一个自定义表达式指标；Interval 为空时所有周期都输出。
+type CustomIndicatorSpec struct {
+	Feature  string
+	Formula  string
+	Interval string
+}
+
+// customized 报告是否偏离 v1 默认结构。
+func (o SnapshotOptions) customized() bool {
+	return len(o.Blocks) > 0 || len(o.Tails) > 0 || o.Precision > 0 || len(o.EMAPeriods) > 0
+}
+
+// emaPeriods 返回 interval 生效的 EMA 周期，未配置时为 nil。
+func (o SnapshotOptions) emaPeriods(interval string) []int {
+	if len(o.EMAPeriods) == 0 {
+		return nil
+	}
+	if periods, ok := o.EMAPeriods[strings.ToLower(strings.TrimSpace(interval))]; ok {
+		return periods
+	}
+	return o.EMAPeriods["default"]
+}
+
+func (o SnapshotOptions) includes(block string) bool {
+	if len(o.Blocks) == 0 {
+		return true
+	}
+	for _, b := range o.Blocks {
+		if strings.EqualFold(strings.TrimSpace(b), block) {
+			return true
+		}
+	}
+	return false
+}
+
+// tail 返回数据块的序列长度，未覆盖时使用 def。
+func (o SnapshotOptions) tail(block string, def int) int {
+	if n, ok := o.Tails[block]; ok && n >= 0 {
+		return n
+	}
+	return def
+}
+
+func (o SnapshotOptions) digits() int {
+	if o.Precision > 0 {
+		return o.Precision
+	}
+	return defaultSnapshotPrecision
+}
+
+// activeBlocks 返回实际启用的已知数据块，用于写入 _meta。
+func (o SnapshotOptions) activeBlocks() []string {
+	known := []string{SnapshotBlockEMA, SnapshotBlockMACD, SnapshotBlockRSI, SnapshotBlockOBV,
+		SnapshotBlockStoch, SnapshotBlockStochRSI, SnapshotBlockConnorsRSI, SnapshotBlockATR, SnapshotBlockIchimoku, SnapshotBlockADX, SnapshotBlockSupertrend, SnapshotBlockOrderBook,
+		SnapshotBlockRSIDivergence, SnapshotBlockCustom}
+	out := make([]string, 0, len(known))
+	for _, b := range known {
+		if o.includes(b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// NormalizeDistanceUnits 将未知取值回退为 absolute。
+func NormalizeDistanceUnits(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case DistanceUnitsATR:
+		return DistanceUnitsATR
+	case DistanceUnitsBoth:
+		return DistanceUnitsBoth
+	default:
+		return DistanceUnitsAbsolute
+	}
+}
+
+func distanceInATR(units string) bool {
+	units = NormalizeDistanceUnits(units)
+	return units == DistanceUnitsATR || units == DistanceUnitsBoth
+}
+
+func distanceInAbsolute(units string) bool {
+	return NormalizeDistanceUnits(units) != DistanceUnitsATR
+}
+
+// atrMultiple 把价差换算为 ATR 倍数，ATR 不可用时返回 nil。
+func atrMultiple(delta, atr float64) *float64 {
+	if atr <= 0 {
+		return nil
+	}
+	v := roundFloat(delta/atr, 2)
+	return &v
+}
+
+// latestATR 从 K 线计算最近一根的 ATR，作为报告中缺少 atr 时的兜底。
+func latestATR(candles []market.Candle) float64 {
+	if len(candles) <= snapshotATRPeriod {
+		return 0
+	}
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i] = c.High
+		lows[i] = c.Low
+		closes[i] = c.Close
+	}
+	return lastNonZero(talib.Atr(highs, lows, closes, snapshotATRPeriod))
+}
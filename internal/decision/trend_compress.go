@@ -27,6 +27,12 @@ type TrendCompressOptions struct {
 	Pretty              bool
 	IncludeCurrentRSI   bool
 	IncludeStructureRSI bool
+	// DistanceUnits 为 atr/both 时，为结构位与 EMA 附加相对现价的 ATR 倍数距离。
+	DistanceUnits string
+	// 流动性扫单：刺破结构位后允许收回的根数、成交量 z-score 阈值与输出事件上限。
+	SweepReclaimBars   int
+	SweepVolumeZ       float64
+	MaxStructureEvents int
 }
 
 func DefaultTrendCompressOptions() TrendCompressOptions {
@@ -45,6 +51,9 @@ func DefaultTrendCompressOptions() TrendCompressOptions {
 		Pretty:              false,
 		IncludeCurrentRSI:   true,
 		IncludeStructureRSI: true,
+		SweepReclaimBars:    3,
+		SweepVolumeZ:        1.5,
+		MaxStructureEvents:  4,
 	}
 }
 
@@ -52,6 +61,7 @@ type TrendCompressedInput struct {
 	Meta                TrendCompressedMeta       `json:"meta"`
 	StructurePoints     []TrendStructurePoint     `json:"structure_points"`
 	StructureCandidates []TrendStructureCandidate `json:"structure_candidates,omitempty"`
+	StructureEvents     []TrendStructureEvent     `json:"structure_events,omitempty"`
 	RecentCandles       []TrendRecentCandle       `json:"recent_candles"`
 	GlobalContext       TrendGlobalContext        `json:"global_context"`
 	RawCandles          []TrendRawCandleOptional  `json:"raw_candles,omitempty"`
@@ -64,10 +74,11 @@ type TrendCompressedMeta struct {
 }
 
 type TrendStructurePoint struct {
-	Idx   int      `json:"idx"`
-	Type  string   `json:"type"`
-	Price float64  `json:"price"`
-	RSI   *float64 `json:"rsi,omitempty"`
+	Idx     int      `json:"idx"`
+	Type    string   `json:"type"`
+	Price   float64  `json:"price"`
+	RSI     *float64 `json:"rsi,omitempty"`
+	DistATR *float64 `json:"dist_atr,omitempty"`
 }
 
 type TrendRecentCandle struct {
@@ -89,6 +100,10 @@ type TrendGlobalContext struct {
 	EMA20           *float64 `json:"ema20,omitempty"`
 	EMA50           *float64 `json:"ema50,omitempty"`
 	EMA200          *float64 `json:"ema200,omitempty"`
+	ATR             *float64 `json:"atr,omitempty"`
+	EMA20DistATR    *float64 `json:"ema20_dist_atr,omitempty"`
+	EMA50DistATR    *float64 `json:"ema50_dist_atr,omitempty"`
+	EMA200DistATR   *float64 `json:"ema200_dist_atr,omitempty"`
 }
 
 type TrendRawCandleOptional struct {
@@ -101,11 +116,12 @@ type TrendRawCandleOptional struct {
 }
 
 type TrendStructureCandidate struct {
-	Price      float64 `json:"price"`
-	Type       string  `json:"type"`
-	Source     string  `json:"source"`
-	AgeCandles int     `json:"age_candles"`
-	Window     int     `json:"window,omitempty"`
+	Price      float64  `json:"price"`
+	Type       string   `json:"type"`
+	Source     string   `json:"source"`
+	AgeCandles int      `json:"age_candles"`
+	Window     int      `json:"window,omitempty"`
+	DistATR    *float64 `json:"dist_atr,omitempty"`
 }
 
 func BuildTrendCompressedJSON(symbol, interval string, candles []market.Candle, opts TrendCompressOptions) (string, error) {
@@ -174,12 +190,17 @@ func BuildTrendCompressedInput(symbol, interval string, candles []market.Candle,
 
 	structurePoints := selectStructurePoints(candles, highs, lows, rsiSeries, atrSeries, opts)
 	candidates := buildStructureCandidates(candles, highs, lows, atrSeries, gc, structurePoints, opts)
+	events := detectLiquiditySweeps(highs, lows, closes, volumes, structurePoints, opts)
 	recentCandles := buildRecentCandles(candles, rsiSeries, opts)
+	if distanceInATR(opts.DistanceUnits) {
+		applyATRDistances(closes[n-1], lastNonZero(atrSeries), &gc, structurePoints, candidates)
+	}
 
 	return TrendCompressedInput{
 		Meta:                meta,
 		StructurePoints:     structurePoints,
 		StructureCandidates: candidates,
+		StructureEvents:     events,
 		RecentCandles:       recentCandles,
 		GlobalContext:       gc,
 	}, nil
@@ -220,6 +241,15 @@ func normalizeTrendCompressOptions(opts TrendCompressOptions) TrendCompressOptio
 	if opts.EMA200Period <= 0 {
 		opts.EMA200Period = def.EMA200Period
 	}
+	if opts.SweepReclaimBars <= 0 {
+		opts.SweepReclaimBars = def.SweepReclaimBars
+	}
+	if opts.SweepVolumeZ <= 0 {
+		opts.SweepVolumeZ = def.SweepVolumeZ
+	}
+	if opts.MaxStructureEvents <= 0 {
+		opts.MaxStructureEvents = def.MaxStructureEvents
+	}
 	if !opts.IncludeCurrentRSI && !opts.IncludeStructureRSI {
 		opts.IncludeCurrentRSI = def.IncludeCurrentRSI
 		opts.IncludeStructureRSI = def.IncludeStructureRSI
@@ -609,3 +639,27 @@ func dedupCandidates(in []TrendStructureCandidate, atr float64, opts TrendCompre
 	})
 	return out
 }
+
+// applyATRDistances 以 (价位 - 现价) / ATR 表示距离，正值在现价上方。
+func applyATRDistances(price, atr float64, gc *TrendGlobalContext, points []TrendStructurePoint, candidates []TrendStructureCandidate) {
+	if atr <= 0 {
+		return
+	}
+	v := roundFloat(atr, 4)
+	gc.ATR = &v
+	emaDist := func(ema *float64) *float64 {
+		if ema == nil {
+			return nil
+		}
+		return atrMultiple(*ema-price, atr)
+	}
+	gc.EMA20DistATR = emaDist(gc.EMA20)
+	gc.EMA50DistATR = emaDist(gc.EMA50)
+	gc.EMA200DistATR = emaDist(gc.EMA200)
+	for i := range points {
+		points[i].DistATR = atrMultiple(points[i].Price-price, atr)
+	}
+	for i := range candidates {
+		candidates[i].DistATR = atrMultiple(candidates[i].Price-price, atr)
+	}
+}
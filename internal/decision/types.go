@@ -1,5 +1,7 @@
 package decision
 
+import "time"
+
 type ProfileDirective struct {
 	DerivativesEnabled bool
 	IncludeOI          bool
@@ -26,9 +28,37 @@ type Decision struct {
 	Confidence      int     `json:"confidence,omitempty"`
 	Reasoning       string  `json:"reasoning,omitempty"`
 
+	// Rationale 为结构化理由，解析后由 ExtractRationale 补全。
+	Rationale *Rationale `json:"rationale,omitempty"`
+
 	ExitPlan *ExitPlanSpec `json:"exit_plan,omitempty"`
 
+	// ScaleIn 为开仓后的加仓价位（多头在开仓价下方、空头在上方），由持仓监控在价格到达时追加入场。
+	ScaleIn []ScaleInLevel `json:"scale_in,omitempty"`
+
 	ExitPlanVersion int `json:"-"`
+
+	// EntryDrift 为决策时参考价与下单前实时价的漂移，由执行前的漂移守卫填写，开仓成交后记入 trade_operation_log。
+	EntryDrift *EntryDrift `json:"-"`
+}
+
+// EntryDrift 记录一次开仓从决策到执行的价格漂移；DriftPct 为不利方向的相对漂移（负值表示价格向有利方向移动），
+// DriftATR 为以 ATR 计的漂移（ATR 不可用时为 0），Action 为 pass/resize。DecidedAt 为 sense 快照时刻，LatencyMs 由此起算。
+type EntryDrift struct {
+	ReferencePrice float64   `json:"reference_price"`
+	MarketPrice    float64   `json:"market_price"`
+	DriftPct       float64   `json:"drift_pct"`
+	DriftATR       float64   `json:"drift_atr,omitempty"`
+	LatencyMs      int64     `json:"latency_ms"`
+	Action         string    `json:"action,omitempty"`
+	DecidedAt      time.Time `json:"decided_at"`
+}
+
+// ScaleInLevel 为一档加仓：Price 为绝对价格，未给出时按开仓价 ∓ ATROffset*ATR 计算；SizeUSD 为追加保证金，缺省与首仓相同。
+type ScaleInLevel struct {
+	Price     float64 `json:"price,omitempty"`
+	ATROffset float64 `json:"atr_offset,omitempty"`
+	SizeUSD   float64 `json:"size_usd,omitempty"`
 }
 
 type DecisionResult struct {
@@ -80,6 +80,7 @@ func (d *Decision) UnmarshalJSON(data []byte) error {
 	d.TakeProfit = coerceFloat64(raw["take_profit"])
 	d.Confidence = coerceInt(raw["confidence"])
 	d.Reasoning = coerceString(raw["reasoning"])
+	d.Rationale = parseRationale(raw["rationale"])
 
 	if v, ok := raw["exit_plan"]; ok && v != nil {
 		if b, err := json.Marshal(v); err == nil {
@@ -89,5 +90,21 @@ func (d *Decision) UnmarshalJSON(data []byte) error {
 			}
 		}
 	}
+	if list, ok := raw["scale_in"].([]any); ok {
+		for _, item := range list {
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			level := ScaleInLevel{
+				Price:     coerceFloat64(m["price"]),
+				ATROffset: coerceFloat64(m["atr_offset"]),
+				SizeUSD:   coerceFloat64(m["size_usd"]),
+			}
+			if level.Price > 0 || level.ATROffset > 0 {
+				d.ScaleIn = append(d.ScaleIn, level)
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,92 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"brale/internal/gateway/exchange"
+	"brale/internal/logger"
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+var _ market.LeverageBracketProvider = (*RulesClient)(nil)
+
+// LeverageBrackets 读取全部 symbol 的杠杆档位（/fapi/v1/leverageBracket，需签名），键为交易所 symbol。
+func (c *RulesClient) LeverageBrackets(ctx context.Context) (map[string][]market.LeverageBracket, error) {
+	if c == nil || c.client == nil {
+		return nil, fmt.Errorf("binance rules client not initialized")
+	}
+	if strings.TrimSpace(c.client.APIKey) == "" || strings.TrimSpace(c.client.SecretKey) == "" {
+		return nil, fmt.Errorf("binance leverage brackets require api key and secret")
+	}
+	res, err := c.client.NewGetLeverageBracketService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("binance leverage brackets failed: %w", err)
+	}
+	table := make(map[string][]market.LeverageBracket, len(res))
+	for _, item := range res {
+		if item == nil {
+			continue
+		}
+		list := make([]market.LeverageBracket, 0, len(item.Brackets))
+		for _, b := range item.Brackets {
+			list = append(list, market.LeverageBracket{
+				NotionalFloor: b.NotionalFloor,
+				NotionalCap:   b.NotionalCap,
+				MaxLeverage:   float64(b.InitialLeverage),
+			})
+		}
+		table[item.Symbol] = list
+	}
+	return table, nil
+}
+
+// constrainOpen 按共享交易规则把杠杆封顶到预计名义价值所在档位允许的最大值，并按数量步长向下取整数量、反推保证金。
+// req.Price 为空时使用最新价；未指定杠杆时不做调整；交易规则获取失败时返回错误，由调用方决定是否按原参数下单。
+func (e *Executor) constrainOpen(ctx context.Context, req exchange.OpenRequest) (exchange.OpenRequest, error) {
+	symbol := symbolpkg.Binance.ToExchange(req.Symbol)
+	if symbol == "" {
+		return req, fmt.Errorf("invalid symbol %q", req.Symbol)
+	}
+	stake := req.Amount
+	if stake <= 0 {
+		stake = req.Stake
+	}
+	leverage := req.Leverage
+	if stake <= 0 || leverage <= 0 {
+		// 未指定杠杆时由执行端沿用账户/策略默认值，无法推算名义价值，原样返回。
+		return req, nil
+	}
+	price := req.Price
+	if price <= 0 {
+		quote, err := e.GetPrice(ctx, req.Symbol)
+		if err != nil {
+			return req, err
+		}
+		price = quote.Last
+	}
+	if price <= 0 {
+		return req, fmt.Errorf("binance %s price unavailable", symbol)
+	}
+	info, err := e.symbolInfo(ctx, symbol)
+	if err != nil {
+		return req, err
+	}
+	stake, clamped, err := info.SizeOpen(stake, leverage, price)
+	if err != nil {
+		return req, err
+	}
+	if clamped < leverage {
+		logger.Infof("binance %s leverage x%.2f adjusted to x%.0f by bracket (notional %.2f)", symbol, leverage, clamped, stake*clamped)
+	}
+	out := req
+	out.Leverage = clamped
+	if req.Amount > 0 {
+		out.Amount = stake
+	} else {
+		out.Stake = stake
+	}
+	return out, nil
+}
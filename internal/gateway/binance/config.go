@@ -12,6 +12,9 @@ type Config struct {
 	ProxyEnabled bool
 	RESTProxyURL string
 	WSProxyURL   string
+
+	// WeightLimit 为每分钟 REST 权重上限，<=0 使用 Binance 默认 2400。
+	WeightLimit int
 }
 
 func (c *Config) withDefaults() Config {
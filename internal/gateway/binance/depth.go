@@ -0,0 +1,134 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// depthLevels 为部分深度推送的档位数（@depth20）。
+const depthLevels = "20"
+
+// SubscribeDepth 订阅 symbols 的部分深度（<symbol>@depth20），断线后按退避重连。
+func (s *Source) SubscribeDepth(ctx context.Context, symbols []string, opts market.SubscribeOptions) (<-chan market.OrderBook, error) {
+	symbolMap := make(map[string]string)
+	levels := make(map[string]string)
+	for _, sym := range symbols {
+		normalized := symbolpkg.Normalize(sym)
+		if normalized == "" {
+			continue
+		}
+		clean := symbolpkg.Binance.ToExchange(normalized)
+		symbolMap[clean] = sym
+		levels[clean] = depthLevels
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no valid symbols for depth subscription")
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = 256
+	}
+	out := make(chan market.OrderBook, buffer)
+	go func() {
+		defer close(out)
+		s.runDepthLoop(ctx, levels, symbolMap, out, opts)
+	}()
+	return out, nil
+}
+
+func (s *Source) runDepthLoop(ctx context.Context, levels map[string]string, symbolMap map[string]string, out chan<- market.OrderBook, opts market.SubscribeOptions) {
+	delay := time.Second
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		var errMu sync.Mutex
+		var lastErr error
+		handler := func(event *futures.WsDepthEvent) {
+			book, ok := convertDepthEvent(event)
+			if !ok {
+				return
+			}
+			if original, ok := symbolMap[book.Symbol]; ok {
+				book.Symbol = original
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- book:
+			default:
+				logger.Warnf("[binance] depth channel full, drop %s", book.Symbol)
+			}
+		}
+		errHandler := func(err error) {
+			if err == nil {
+				return
+			}
+			errMu.Lock()
+			lastErr = err
+			errMu.Unlock()
+		}
+		doneC, stopC, err := futures.WsCombinedDepthServe(levels, handler, errHandler)
+		if err != nil {
+			s.recordSubscribeError(err)
+			if opts.OnDisconnect != nil {
+				opts.OnDisconnect(err)
+			}
+			if !sleepWithContext(ctx, delay) {
+				return
+			}
+			delay = nextDelay(delay)
+			continue
+		}
+		delay = time.Second
+		if opts.OnConnect != nil {
+			opts.OnConnect()
+		}
+		select {
+		case <-ctx.Done():
+			close(stopC)
+			<-doneC
+			return
+		case <-doneC:
+		}
+		close(stopC)
+		errMu.Lock()
+		errCopy := lastErr
+		errMu.Unlock()
+		s.recordReconnect(errCopy)
+		if opts.OnDisconnect != nil {
+			opts.OnDisconnect(errCopy)
+		}
+		if !sleepWithContext(ctx, delay) {
+			return
+		}
+		delay = nextDelay(delay)
+	}
+}
+
+func convertDepthEvent(ev *futures.WsDepthEvent) (market.OrderBook, bool) {
+	if ev == nil || ev.Symbol == "" {
+		return market.OrderBook{}, false
+	}
+	book := market.OrderBook{
+		Symbol:    ev.Symbol,
+		Bids:      make([]market.OrderBookLevel, 0, len(ev.Bids)),
+		Asks:      make([]market.OrderBookLevel, 0, len(ev.Asks)),
+		EventTime: ev.Time,
+	}
+	for _, b := range ev.Bids {
+		book.Bids = append(book.Bids, market.OrderBookLevel{Price: parseFloat(b.Price), Quantity: parseFloat(b.Quantity)})
+	}
+	for _, a := range ev.Asks {
+		book.Asks = append(book.Asks, market.OrderBookLevel{Price: parseFloat(a.Price), Quantity: parseFloat(a.Quantity)})
+	}
+	return book, len(book.Bids) > 0 && len(book.Asks) > 0
+}
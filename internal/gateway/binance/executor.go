@@ -0,0 +1,444 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/gateway/exchange"
+	"brale/internal/logger"
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+const (
+	ExecutorName        = "binance"
+	clientOrderIDPrefix = "brale-"
+)
+
+type ExecutorConfig struct {
+	APIKey        string
+	SecretKey     string
+	RESTBaseURL   string
+	StakeCurrency string
+	HTTPTimeout   time.Duration
+}
+
+// Executor 直接通过 Binance U 本位合约签名接口下单（单向持仓模式），
+// 持仓 ID 使用开仓市价单的 orderId，成交由 user-data stream 推送（见 userstream.go）。
+type Executor struct {
+	client        *futures.Client
+	stakeCurrency string
+
+	// OnFill 接收由成交推送转换而来的 webhook 消息，与 freqtrade webhook 走同一条处理链路。
+	OnFill func(ctx context.Context, msg exchange.WebhookMessage)
+	// Scope 限定本执行器负责的 symbol（与 freqtrade 共用账户时避免重复持仓），nil 表示全部。
+	Scope func(symbol string) bool
+
+	// rules 为共享的交易规则缓存（数量步长、最小数量、杠杆档位），由 SetSymbolRules 注入。
+	rules SymbolRules
+
+	mu      sync.Mutex
+	account map[string]accountPosition
+	posIDs  map[string]int64
+	// exitAt 为已发出 exit_fill 但尚未确认持仓归零的持仓（positionKey → 成交时间，毫秒），等 ACCOUNT_UPDATE 确认后丢弃持仓 ID。
+	exitAt    map[string]int64
+	leverages map[string]float64
+	// orderFees 累计订单各笔成交的手续费（仅计 stake 币种），entryFees 记录持仓尚未分摊的开仓手续费。
+	orderFees map[int64]float64
+	entryFees map[string]entryFee
+}
+
+type entryFee struct {
+	qty float64
+	fee float64
+}
+
+// SymbolRules 提供 symbol 的下单规则，缓存未命中时允许实现方补拉。
+type SymbolRules interface {
+	Lookup(ctx context.Context, symbol string) (market.SymbolInfo, error)
+}
+
+func NewExecutor(cfg ExecutorConfig) (*Executor, error) {
+	if strings.TrimSpace(cfg.APIKey) == "" || strings.TrimSpace(cfg.SecretKey) == "" {
+		return nil, fmt.Errorf("binance executor requires api key and secret")
+	}
+	client := newFuturesClient(cfg)
+	stake := strings.ToUpper(strings.TrimSpace(cfg.StakeCurrency))
+	if stake == "" {
+		stake = "USDT"
+	}
+	return &Executor{
+		client:        client,
+		stakeCurrency: stake,
+		posIDs:        make(map[string]int64),
+		exitAt:        make(map[string]int64),
+		leverages:     make(map[string]float64),
+		account:       make(map[string]accountPosition),
+		orderFees:     make(map[int64]float64),
+		entryFees:     make(map[string]entryFee),
+	}, nil
+}
+
+func newFuturesClient(cfg ExecutorConfig) *futures.Client {
+	client := futures.NewClient(strings.TrimSpace(cfg.APIKey), strings.TrimSpace(cfg.SecretKey))
+	if base := strings.TrimSpace(cfg.RESTBaseURL); base != "" {
+		client.BaseURL = base
+	}
+	timeout := cfg.HTTPTimeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	client.HTTPClient = &http.Client{Timeout: timeout}
+	return client
+}
+
+// SetSymbolRules 注入交易规则缓存；下单前按其数量步长取整，未注入时拒绝下单。
+func (e *Executor) SetSymbolRules(rules SymbolRules) {
+	e.rules = rules
+}
+
+func (e *Executor) Name() string {
+	return ExecutorName
+}
+
+// OpenPosition 以市价开仓，req.Amount 为保证金（USDT），数量 = 保证金 × 杠杆 / 价格。
+func (e *Executor) OpenPosition(ctx context.Context, req exchange.OpenRequest) (*exchange.OpenResult, error) {
+	symbol := symbolpkg.Binance.ToExchange(req.Symbol)
+	if symbol == "" {
+		return nil, fmt.Errorf("invalid symbol %q", req.Symbol)
+	}
+	side, err := orderSide(req.Side, false)
+	if err != nil {
+		return nil, err
+	}
+	stake := req.Amount
+	if stake <= 0 {
+		stake = req.Stake
+	}
+	if stake <= 0 {
+		return nil, fmt.Errorf("binance open %s: stake must be > 0", symbol)
+	}
+	if req.Leverage > 0 {
+		if constrained, err := e.constrainOpen(ctx, req); err != nil {
+			logger.Warnf("binance open %s: leverage bracket check skipped: %v", symbol, err)
+		} else {
+			req.Leverage = constrained.Leverage
+		}
+	}
+	leverage := req.Leverage
+	if leverage > 0 {
+		if _, err := e.client.NewChangeLeverageService().Symbol(symbol).Leverage(int(math.Round(leverage))).Do(ctx); err != nil {
+			return nil, fmt.Errorf("binance change leverage %s x%.0f failed: %w", symbol, leverage, err)
+		}
+	} else {
+		leverage = 1
+	}
+	price := req.Price
+	if price <= 0 {
+		quote, err := e.GetPrice(ctx, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		price = quote.Last
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("binance open %s: price unavailable", symbol)
+	}
+	qty, err := e.roundQuantity(ctx, symbol, stake*leverage/price)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Binance executor open: %s %s stake=%.2f lev=%.0f qty=%s", symbol, req.Side, stake, leverage, qty)
+	resp, err := e.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Type(futures.OrderTypeMarket).
+		Quantity(qty).
+		NewClientOrderID(clientOrderIDPrefix + strconv.FormatInt(time.Now().UnixMilli(), 10)).
+		NewOrderResponseType(futures.NewOrderRespTypeRESULT).
+		Do(ctx)
+	if err != nil {
+		logger.Errorf("binance create order failed (symbol=%s side=%s qty=%s): %v", symbol, side, qty, err)
+		return nil, fmt.Errorf("binance create order failed: %w", err)
+	}
+	// 已有同向持仓时（加仓）沿用原持仓 ID，成交推送与本地交易记录仍归到同一笔交易。
+	key := positionKey(symbol, req.Side)
+	e.mu.Lock()
+	posID, open := e.posIDs[key]
+	if !open || e.flatLocked(symbol) {
+		posID = resp.OrderID
+		e.posIDs[key] = posID
+		delete(e.exitAt, key)
+	}
+	e.leverages[symbol] = leverage
+	e.mu.Unlock()
+	return &exchange.OpenResult{
+		PositionID: strconv.FormatInt(posID, 10),
+		OrderID:    strconv.FormatInt(resp.OrderID, 10),
+	}, nil
+}
+
+// ClosePosition 以 reduce-only 市价单平仓，Amount<=0 或超出持仓时平掉全部。
+func (e *Executor) ClosePosition(ctx context.Context, req exchange.CloseRequest) error {
+	pos, err := e.findPosition(ctx, req.PositionID, req.Symbol)
+	if err != nil {
+		return err
+	}
+	if pos == nil {
+		return fmt.Errorf("no open binance position for %s", req.Symbol)
+	}
+	symbol := symbolpkg.Binance.ToExchange(pos.Symbol)
+	side, err := orderSide(pos.Side, true)
+	if err != nil {
+		return err
+	}
+	amount := req.Amount
+	if amount <= 0 || amount > pos.Amount {
+		amount = pos.Amount
+	}
+	qty, err := e.roundQuantity(ctx, symbol, amount)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Binance executor close: %s (ID: %s) qty=%s remain=%.6f", symbol, pos.ID, qty, pos.Amount)
+	_, err = e.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		Type(futures.OrderTypeMarket).
+		Quantity(qty).
+		ReduceOnly(true).
+		NewClientOrderID(clientOrderIDPrefix + "exit-" + strconv.FormatInt(time.Now().UnixMilli(), 10)).
+		Do(ctx)
+	if err != nil {
+		logger.Errorf("binance close order failed (symbol=%s qty=%s): %v", symbol, qty, err)
+		return fmt.Errorf("binance close order failed: %w", err)
+	}
+	return nil
+}
+
+func (e *Executor) findPosition(ctx context.Context, positionID, symbol string) (*exchange.Position, error) {
+	positions, err := e.ListOpenPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	positionID = strings.TrimSpace(positionID)
+	want := symbolpkg.Normalize(symbol)
+	for i := range positions {
+		if positionID != "" && positions[i].ID == positionID {
+			return &positions[i], nil
+		}
+	}
+	for i := range positions {
+		if want != "" && symbolpkg.Normalize(positions[i].Symbol) == want {
+			return &positions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (e *Executor) ListOpenPositions(ctx context.Context) ([]exchange.Position, error) {
+	risks, err := e.client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		logger.Errorf("binance position risk failed: %v", err)
+		return nil, err
+	}
+	out := make([]exchange.Position, 0, len(risks))
+	for _, r := range risks {
+		if r == nil {
+			continue
+		}
+		amt := parseFloat(r.PositionAmt)
+		if amt == 0 || !e.owns(r.Symbol) {
+			continue
+		}
+		side := "long"
+		if amt < 0 {
+			side = "short"
+		}
+		lev := parseFloat(r.Leverage)
+		notional := math.Abs(parseFloat(r.Notional))
+		stake := notional
+		if lev > 0 {
+			stake = notional / lev
+		}
+		entry := parseFloat(r.EntryPrice)
+		pnl := parseFloat(r.UnRealizedProfit)
+		ratio := 0.0
+		if stake > 0 {
+			ratio = pnl / stake
+		}
+		id := e.positionID(ctx, r.Symbol, side)
+		out = append(out, exchange.Position{
+			ID:                 strconv.FormatInt(id, 10),
+			Symbol:             symbolpkg.Binance.FromExchange(r.Symbol),
+			Side:               side,
+			Amount:             math.Abs(amt),
+			InitialAmount:      math.Abs(amt),
+			EntryPrice:         entry,
+			Leverage:           lev,
+			StakeAmount:        stake,
+			IsOpen:             true,
+			UnrealizedPnL:      pnl,
+			UnrealizedPnLRatio: ratio,
+			CurrentPrice:       parseFloat(r.MarkPrice),
+			UpdatedAt:          time.Now(),
+		})
+	}
+	return out, nil
+}
+
+func (e *Executor) GetPosition(ctx context.Context, positionID string) (*exchange.Position, error) {
+	if strings.TrimSpace(positionID) == "" {
+		return nil, fmt.Errorf("positionID required")
+	}
+	positions, err := e.ListOpenPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range positions {
+		if positions[i].ID == positionID {
+			return &positions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (e *Executor) GetBalance(ctx context.Context) (exchange.Balance, error) {
+	balances, err := e.client.NewGetBalanceService().Do(ctx)
+	if err != nil {
+		logger.Errorf("binance get balance failed: %v", err)
+		return exchange.Balance{}, err
+	}
+	out := exchange.Balance{
+		StakeCurrency: e.stakeCurrency,
+		Wallets:       make(map[string]float64),
+		UpdatedAt:     time.Now(),
+	}
+	for _, b := range balances {
+		if b == nil {
+			continue
+		}
+		total := parseFloat(b.Balance)
+		if total != 0 {
+			out.Wallets[b.Asset] = total
+		}
+		if strings.EqualFold(b.Asset, e.stakeCurrency) {
+			out.Total = total + parseFloat(b.CrossUnPnl)
+			out.Available = parseFloat(b.AvailableBalance)
+			out.Used = out.Total - out.Available
+		}
+	}
+	return out, nil
+}
+
+func (e *Executor) GetPrice(ctx context.Context, symbol string) (exchange.PriceQuote, error) {
+	clean := symbolpkg.Binance.ToExchange(symbol)
+	tickers, err := e.client.NewListBookTickersService().Symbol(clean).Do(ctx)
+	if err != nil {
+		return exchange.PriceQuote{}, fmt.Errorf("binance book ticker %s failed: %w", clean, err)
+	}
+	if len(tickers) == 0 || tickers[0] == nil {
+		return exchange.PriceQuote{}, fmt.Errorf("binance book ticker %s empty", clean)
+	}
+	bid := parseFloat(tickers[0].BidPrice)
+	ask := parseFloat(tickers[0].AskPrice)
+	last := (bid + ask) / 2
+	if bid <= 0 || ask <= 0 {
+		last = math.Max(bid, ask)
+	}
+	return exchange.PriceQuote{
+		Symbol:    symbolpkg.Normalize(symbol),
+		Last:      last,
+		Bid:       bid,
+		Ask:       ask,
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// positionID 返回持仓 ID；重启后内存映射丢失时，取该方向最近一笔已成交的非 reduce-only 订单。
+func (e *Executor) positionID(ctx context.Context, symbol, side string) int64 {
+	key := positionKey(symbol, side)
+	e.mu.Lock()
+	id, ok := e.posIDs[key]
+	e.mu.Unlock()
+	if ok {
+		return id
+	}
+	want, _ := orderSide(side, false)
+	orders, err := e.client.NewListOrdersService().Symbol(symbol).Limit(50).Do(ctx)
+	if err != nil {
+		logger.Warnf("binance list orders %s failed: %v", symbol, err)
+		return 0
+	}
+	for i := len(orders) - 1; i >= 0; i-- {
+		o := orders[i]
+		if o == nil || o.ReduceOnly || o.Side != want || o.Status != futures.OrderStatusTypeFilled {
+			continue
+		}
+		e.mu.Lock()
+		e.posIDs[key] = o.OrderID
+		e.mu.Unlock()
+		return o.OrderID
+	}
+	return 0
+}
+
+func (e *Executor) owns(exchangeSymbol string) bool {
+	return e.Scope == nil || e.Scope(symbolpkg.Binance.FromExchange(exchangeSymbol))
+}
+
+// dropPositionLocked 在持仓归零且 exit_fill 已发出后丢弃持仓 ID 与分摊状态；调用方需持有 e.mu。
+func (e *Executor) dropPositionLocked(key string) {
+	delete(e.posIDs, key)
+	delete(e.entryFees, key)
+	delete(e.exitAt, key)
+}
+
+func (e *Executor) roundQuantity(ctx context.Context, symbol string, qty float64) (string, error) {
+	info, err := e.symbolInfo(ctx, symbol)
+	if err != nil {
+		return "", err
+	}
+	qty = info.FloorQty(qty)
+	if qty <= 0 || qty < info.MinQty {
+		return "", fmt.Errorf("binance %s quantity %.8f below min %.8f", symbol, qty, info.MinQty)
+	}
+	return strconv.FormatFloat(qty, 'f', -1, 64), nil
+}
+
+func (e *Executor) symbolInfo(ctx context.Context, symbol string) (market.SymbolInfo, error) {
+	if e.rules == nil {
+		return market.SymbolInfo{}, fmt.Errorf("binance %s: symbol rules not configured", symbol)
+	}
+	return e.rules.Lookup(ctx, symbol)
+}
+
+func positionKey(symbol, side string) string {
+	return strings.ToUpper(symbol) + "|" + strings.ToLower(strings.TrimSpace(side))
+}
+
+// orderSide 把 long/short 转换为下单方向，closing 为 true 时取反。
+func orderSide(side string, closing bool) (futures.SideType, error) {
+	var long bool
+	switch strings.ToLower(strings.TrimSpace(side)) {
+	case "long", "buy":
+		long = true
+	case "short", "sell":
+		long = false
+	default:
+		return "", fmt.Errorf("invalid side %q", side)
+	}
+	if long != closing {
+		return futures.SideTypeBuy, nil
+	}
+	return futures.SideTypeSell, nil
+}
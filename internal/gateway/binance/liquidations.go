@@ -0,0 +1,125 @@
+package binance
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// SubscribeLiquidations 订阅全市场强平流（!forceOrder@arr），断线后按退避重连。
+func (s *Source) SubscribeLiquidations(ctx context.Context, opts market.SubscribeOptions) (<-chan market.LiquidationEvent, error) {
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = 1024
+	}
+	out := make(chan market.LiquidationEvent, buffer)
+	go func() {
+		defer close(out)
+		s.runLiquidationLoop(ctx, out, opts)
+	}()
+	return out, nil
+}
+
+func (s *Source) runLiquidationLoop(ctx context.Context, out chan<- market.LiquidationEvent, opts market.SubscribeOptions) {
+	delay := time.Second
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		var errMu sync.Mutex
+		var lastErr error
+		handler := func(event *futures.WsLiquidationOrderEvent) {
+			le, ok := convertLiquidationEvent(event)
+			if !ok {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- le:
+			default:
+				logger.Warnf("[binance] liquidation channel full, drop %s", le.Symbol)
+			}
+		}
+		errHandler := func(err error) {
+			if err == nil {
+				return
+			}
+			errMu.Lock()
+			lastErr = err
+			errMu.Unlock()
+		}
+		doneC, stopC, err := futures.WsAllLiquidationOrderServe(handler, errHandler)
+		if err != nil {
+			s.recordSubscribeError(err)
+			if opts.OnDisconnect != nil {
+				opts.OnDisconnect(err)
+			}
+			if !sleepWithContext(ctx, delay) {
+				return
+			}
+			delay = nextDelay(delay)
+			continue
+		}
+		delay = time.Second
+		if opts.OnConnect != nil {
+			opts.OnConnect()
+		}
+		select {
+		case <-ctx.Done():
+			close(stopC)
+			<-doneC
+			return
+		case <-doneC:
+		}
+		close(stopC)
+		errMu.Lock()
+		errCopy := lastErr
+		errMu.Unlock()
+		s.recordReconnect(errCopy)
+		if opts.OnDisconnect != nil {
+			opts.OnDisconnect(errCopy)
+		}
+		if !sleepWithContext(ctx, delay) {
+			return
+		}
+		delay = nextDelay(delay)
+	}
+}
+
+func convertLiquidationEvent(ev *futures.WsLiquidationOrderEvent) (market.LiquidationEvent, bool) {
+	if ev == nil {
+		return market.LiquidationEvent{}, false
+	}
+	o := ev.LiquidationOrder
+	symbol := strings.ToUpper(strings.TrimSpace(o.Symbol))
+	if symbol == "" {
+		return market.LiquidationEvent{}, false
+	}
+	price := parseFloat(o.AvgPrice)
+	if price <= 0 {
+		price = parseFloat(o.Price)
+	}
+	qty := parseFloat(o.AccumulatedFilledQty)
+	if qty <= 0 {
+		qty = parseFloat(o.OrigQuantity)
+	}
+	if price <= 0 || qty <= 0 {
+		return market.LiquidationEvent{}, false
+	}
+	return market.LiquidationEvent{
+		Symbol:    symbolpkg.Binance.FromExchange(symbol),
+		Side:      strings.ToLower(string(o.Side)),
+		Price:     price,
+		Quantity:  qty,
+		Notional:  price * qty,
+		TradeTime: o.TradeTime,
+	}, true
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"brale/internal/market"
 	"brale/internal/pkg/symbol"
+
+	"github.com/adshao/go-binance/v2/futures"
 )
 
 func (s *Source) GetFundingRate(ctx context.Context, sym string) (float64, error) {
@@ -36,6 +39,44 @@ func (s *Source) GetFundingRate(ctx context.Context, sym string) (float64, error
 	return 0, fmt.Errorf("funding rate not available for %s", sym)
 }
 
+// FundingInfo 用 premiumIndex 取预测费率与下次结算时间，并用最近一条资金费率历史作为已结算费率。
+func (s *Source) FundingInfo(ctx context.Context, sym string) (market.FundingInfo, error) {
+	if s == nil || s.client == nil {
+		return market.FundingInfo{}, fmt.Errorf("binance source not initialized")
+	}
+	binanceSymbol := symbol.Parse(sym).Binance()
+	if binanceSymbol == "" {
+		return market.FundingInfo{}, fmt.Errorf("invalid symbol: %s", sym)
+	}
+	res, err := s.client.NewPremiumIndexService().Symbol(binanceSymbol).Do(ctx)
+	if err != nil {
+		return market.FundingInfo{}, err
+	}
+	var premium *futures.PremiumIndex
+	for _, entry := range res {
+		if entry != nil && strings.EqualFold(entry.Symbol, binanceSymbol) {
+			premium = entry
+			break
+		}
+	}
+	if premium == nil {
+		return market.FundingInfo{}, fmt.Errorf("funding rate not available for %s", sym)
+	}
+	info := market.FundingInfo{
+		Symbol:        sym,
+		PredictedRate: parseFloat(premium.LastFundingRate),
+	}
+	info.Rate = info.PredictedRate
+	if premium.NextFundingTime > 0 {
+		info.NextFundingTime = time.UnixMilli(premium.NextFundingTime)
+	}
+	history, err := s.client.NewFundingRateService().Symbol(binanceSymbol).Limit(1).Do(ctx)
+	if err == nil && len(history) > 0 && history[len(history)-1] != nil {
+		info.Rate = parseFloat(history[len(history)-1].FundingRate)
+	}
+	return info, nil
+}
+
 func (s *Source) GetOpenInterestHistory(ctx context.Context, sym, period string, limit int) ([]market.OpenInterestPoint, error) {
 	if s == nil || s.client == nil {
 		return nil, fmt.Errorf("binance source not initialized")
@@ -0,0 +1,55 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"brale/internal/pkg/symbol"
+)
+
+func (s *Source) IndexPrice(ctx context.Context, sym string) (float64, error) {
+	if s == nil || s.client == nil {
+		return 0, fmt.Errorf("binance source not initialized")
+	}
+	binanceSymbol := symbol.Parse(sym).Binance()
+	if binanceSymbol == "" {
+		return 0, fmt.Errorf("invalid symbol: %s", sym)
+	}
+	res, err := s.client.NewPremiumIndexService().Symbol(binanceSymbol).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range res {
+		if entry == nil || !strings.EqualFold(entry.Symbol, binanceSymbol) {
+			continue
+		}
+		if price := parseFloat(entry.IndexPrice); price > 0 {
+			return price, nil
+		}
+	}
+	return 0, fmt.Errorf("index price not available for %s", sym)
+}
+
+func (s *Source) LastPrice(ctx context.Context, sym string) (float64, error) {
+	if s == nil || s.client == nil {
+		return 0, fmt.Errorf("binance source not initialized")
+	}
+	binanceSymbol := symbol.Parse(sym).Binance()
+	if binanceSymbol == "" {
+		return 0, fmt.Errorf("invalid symbol: %s", sym)
+	}
+	res, err := s.client.NewListPricesService().Symbol(binanceSymbol).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range res {
+		if entry == nil || !strings.EqualFold(entry.Symbol, binanceSymbol) {
+			continue
+		}
+		if price := parseFloat(entry.Price); price > 0 {
+			return price, nil
+		}
+	}
+	return 0, fmt.Errorf("last price not available for %s", sym)
+}
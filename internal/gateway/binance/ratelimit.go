@@ -0,0 +1,250 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/market"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+const (
+	// Binance USDT-M 合约 REST 默认 REQUEST_WEIGHT 为 2400/分钟（按 IP 计）。
+	defaultWeightLimit = 2400
+	// 预留给下单、账户等其它调用的权重比例。
+	weightHeadroom      = 0.9
+	minRateLimitBackoff = time.Second
+	maxRateLimitBackoff = 2 * time.Minute
+	maxHistoryAttempts  = 3
+	// 合并后的 K 线请求不绑定任一调用方的 ctx，以此时限兜底（覆盖限流等待与重试）。
+	historyCallTimeout = 3 * time.Minute
+)
+
+// rateLimitError 为 429/418 响应；RetryAfter 为服务端要求（或本地退避计算出）的等待时长。
+type rateLimitError struct {
+	Status     int
+	RetryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("binance rate limited: status=%d retry_after=%s", e.Status, e.RetryAfter)
+}
+
+// weightLimiter 按分钟窗口跟踪已用权重：请求前预估权重并在超限时等待到下一个窗口，
+// 响应后以 X-MBX-USED-WEIGHT-1M 校准；429/418 时进入指数退避，期间所有请求阻塞。
+type weightLimiter struct {
+	limit int
+
+	mu          sync.Mutex
+	window      time.Time
+	used        int
+	bannedUntil time.Time
+	backoff     time.Duration
+}
+
+func newWeightLimiter(limit int) *weightLimiter {
+	if limit <= 0 {
+		limit = defaultWeightLimit
+	}
+	return &weightLimiter{limit: int(float64(limit) * weightHeadroom)}
+}
+
+// acquire 在预算足够时登记 weight 并返回；否则等待退避结束或下一个分钟窗口。
+func (l *weightLimiter) acquire(ctx context.Context, weight int) error {
+	for {
+		wait := l.reserve(time.Now(), weight)
+		if wait <= 0 {
+			return nil
+		}
+		logger.Debugf("[binance] REST 权重限流等待 %s weight=%d", wait.Round(time.Millisecond), weight)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *weightLimiter) reserve(now time.Time, weight int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Before(l.bannedUntil) {
+		return l.bannedUntil.Sub(now)
+	}
+	l.roll(now)
+	if l.used > 0 && l.used+weight > l.limit {
+		return l.window.Add(time.Minute).Sub(now)
+	}
+	l.used += weight
+	return 0
+}
+
+func (l *weightLimiter) roll(now time.Time) {
+	if window := now.Truncate(time.Minute); !window.Equal(l.window) {
+		l.window = window
+		l.used = 0
+	}
+}
+
+// observe 用服务端返回的已用权重校准本地计数，并在成功响应后重置退避。
+func (l *weightLimiter) observe(now time.Time, used int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(now)
+	if used > 0 {
+		l.used = used
+	}
+	l.backoff = 0
+}
+
+// penalize 记录 429/418：优先遵循 Retry-After，否则按指数退避。
+func (l *weightLimiter) penalize(now time.Time, retryAfter time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.backoff <= 0 {
+		l.backoff = minRateLimitBackoff
+	} else {
+		l.backoff = min(l.backoff*2, maxRateLimitBackoff)
+	}
+	wait := max(retryAfter, l.backoff)
+	if until := now.Add(wait); until.After(l.bannedUntil) {
+		l.bannedUntil = until
+	}
+	return wait
+}
+
+// limitedTransport 在 HTTP 层为所有 REST 调用（K 线、资金费率、OI 等）统一做权重限流。
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *weightLimiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.acquire(req.Context(), requestWeight(req)); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		_ = resp.Body.Close()
+		wait := t.limiter.penalize(now, parseRetryAfter(resp.Header.Get("Retry-After")))
+		logger.Warnf("[binance] REST 触发限流 status=%d path=%s，退避 %s", resp.StatusCode, req.URL.Path, wait)
+		return nil, &rateLimitError{Status: resp.StatusCode, RetryAfter: wait}
+	}
+	used, _ := strconv.Atoi(strings.TrimSpace(resp.Header.Get("X-MBX-USED-WEIGHT-1M")))
+	t.limiter.observe(now, used)
+	return resp, nil
+}
+
+// requestWeight 估算请求权重；K 线按 limit 分档，其余接口按 1 计，由响应头校准。
+func requestWeight(req *http.Request) int {
+	if req == nil || req.URL == nil || !strings.HasSuffix(req.URL.Path, "/klines") {
+		return 1
+	}
+	limit, err := strconv.Atoi(req.URL.Query().Get("limit"))
+	if err != nil {
+		limit = 500
+	}
+	switch {
+	case limit < 100:
+		return 1
+	case limit < 500:
+		return 2
+	case limit <= 1000:
+		return 5
+	default:
+		return 10
+	}
+}
+
+func parseRetryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// historyCall 为进行中的 K 线请求；同一 symbol/interval 的并发请求若 limit 不超过它则复用结果。
+type historyCall struct {
+	limit int
+	done  chan struct{}
+	out   []market.Candle
+	err   error
+}
+
+// coalesceHistory 合并同一 symbol/interval 的并发 K 线请求：已有进行中的请求且 limit 足够时等待并截取其结果。
+// 共享请求在独立的 ctx（historyCallTimeout）上执行，发起方取消只影响它自己的等待，不会让复用者一起失败。
+func (s *Source) coalesceHistory(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error) {
+	key := symbol + "|" + interval
+	s.inflightMu.Lock()
+	call, ok := s.inflight[key]
+	if !ok || call.limit < limit {
+		call = &historyCall{limit: limit, done: make(chan struct{})}
+		s.inflight[key] = call
+		go s.runHistoryCall(key, call, symbol, interval)
+	}
+	s.inflightMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.done:
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	return tailCandles(call.out, limit), nil
+}
+
+// runHistoryCall 执行共享的 K 线请求，完成后唤醒所有等待者并移出 inflight。
+func (s *Source) runHistoryCall(key string, call *historyCall, symbol, interval string) {
+	ctx, cancel := context.WithTimeout(context.Background(), historyCallTimeout)
+	defer cancel()
+	call.out, call.err = s.fetchHistoryWithRetry(ctx, symbol, interval, call.limit)
+	close(call.done)
+	s.inflightMu.Lock()
+	if s.inflight[key] == call {
+		delete(s.inflight, key)
+	}
+	s.inflightMu.Unlock()
+}
+
+// fetchHistoryWithRetry 在 429/418 时重试；退避等待由 limitedTransport 在下一次请求前完成。
+func (s *Source) fetchHistoryWithRetry(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error) {
+	var err error
+	for attempt := 1; attempt <= maxHistoryAttempts; attempt++ {
+		var kls []*futures.Kline
+		kls, err = s.client.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit).Do(ctx)
+		if err == nil {
+			return convertKlines(kls, interval), nil
+		}
+		var rle *rateLimitError
+		if !errors.As(err, &rle) || ctx.Err() != nil {
+			break
+		}
+		logger.Warnf("[binance] fetch kline 限流重试 %s %s attempt=%d/%d", symbol, interval, attempt, maxHistoryAttempts)
+	}
+	return nil, err
+}
+
+// tailCandles 返回末尾 limit 根的副本，避免合并请求的调用方共享底层数组。
+func tailCandles(candles []market.Candle, limit int) []market.Candle {
+	if limit > 0 && len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return append([]market.Candle(nil), candles...)
+}
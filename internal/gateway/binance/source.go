@@ -30,6 +30,9 @@ type Source struct {
 
 	statsMu sync.Mutex
 	stats   market.SourceStats
+
+	inflightMu sync.Mutex
+	inflight   map[string]*historyCall
 }
 
 func New(cfg Config) (*Source, error) {
@@ -50,6 +53,11 @@ func New(cfg Config) (*Source, error) {
 		transport.Proxy = http.ProxyURL(proxyURL)
 		httpClient.Transport = transport
 	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient.Transport = &limitedTransport{base: base, limiter: newWeightLimiter(final.WeightLimit)}
 	client.HTTPClient = httpClient
 	if final.ProxyEnabled {
 		wsProxy := final.WSProxyURL
@@ -61,8 +69,9 @@ func New(cfg Config) (*Source, error) {
 		}
 	}
 	return &Source{
-		cfg:    final,
-		client: client,
+		cfg:      final,
+		client:   client,
+		inflight: make(map[string]*historyCall),
 	}, nil
 }
 
@@ -84,12 +93,15 @@ func (s *Source) FetchHistory(ctx context.Context, symbol, interval string, limi
 	if interval == "" {
 		return nil, fmt.Errorf("interval is required")
 	}
-	svc := s.client.NewKlinesService().Symbol(cleanSymbol).Interval(interval).Limit(limit)
-	kls, err := svc.Do(ctx)
+	out, err := s.coalesceHistory(ctx, cleanSymbol, interval, limit)
 	if err != nil {
 		logger.Errorf("[binance] fetch kline failed %s %s limit=%d: %v", symbol, interval, limit, err)
 		return nil, err
 	}
+	return out, nil
+}
+
+func convertKlines(kls []*futures.Kline, interval string) []market.Candle {
 	out := make([]market.Candle, 0, len(kls))
 	for _, kl := range kls {
 		if kl == nil {
@@ -114,7 +126,7 @@ func (s *Source) FetchHistory(ctx context.Context, symbol, interval string, limi
 	if dur, ok := scheduler.ParseIntervalDuration(interval); ok {
 		out = scheduler.DropUnclosedBinanceKline(out, dur)
 	}
-	return out, nil
+	return out
 }
 
 func (s *Source) Subscribe(ctx context.Context, symbols, intervals []string, opts market.SubscribeOptions) (<-chan market.CandleEvent, error) {
@@ -0,0 +1,66 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+
+	"brale/internal/market"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+var (
+	_ market.SymbolInfoProvider = (*Source)(nil)
+	_ market.SymbolInfoProvider = (*RulesClient)(nil)
+)
+
+// SymbolInfos 读取 U 本位合约 exchangeInfo（公开接口），返回价格步长、数量步长、最小数量与最小名义价值。
+func (s *Source) SymbolInfos(ctx context.Context) ([]market.SymbolInfo, error) {
+	if s == nil || s.client == nil {
+		return nil, fmt.Errorf("binance source not initialized")
+	}
+	return fetchSymbolInfos(ctx, s.client)
+}
+
+// RulesClient 按直连执行器的 REST 地址读取交易规则，供 market.SymbolInfoService 整表刷新；
+// 执行器下单取整读取的就是这份缓存，与当前行情源无关。
+type RulesClient struct {
+	client *futures.Client
+}
+
+// NewRulesClient 复用执行器配置中的 REST 地址与超时。
+func NewRulesClient(cfg ExecutorConfig) *RulesClient {
+	return &RulesClient{client: newFuturesClient(cfg)}
+}
+
+// SymbolInfos 读取 exchangeInfo，字段含义同 Source.SymbolInfos。
+func (c *RulesClient) SymbolInfos(ctx context.Context) ([]market.SymbolInfo, error) {
+	if c == nil || c.client == nil {
+		return nil, fmt.Errorf("binance rules client not initialized")
+	}
+	return fetchSymbolInfos(ctx, c.client)
+}
+
+func fetchSymbolInfos(ctx context.Context, client *futures.Client) ([]market.SymbolInfo, error) {
+	info, err := client.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("binance exchange info failed: %w", err)
+	}
+	out := make([]market.SymbolInfo, 0, len(info.Symbols))
+	for i := range info.Symbols {
+		sym := &info.Symbols[i]
+		item := market.SymbolInfo{Symbol: sym.Symbol}
+		if f := sym.PriceFilter(); f != nil {
+			item.TickSize = parseFloat(f.TickSize)
+		}
+		if f := sym.LotSizeFilter(); f != nil {
+			item.StepSize = parseFloat(f.StepSize)
+			item.MinQty = parseFloat(f.MinQuantity)
+		}
+		if f := sym.MinNotionalFilter(); f != nil {
+			item.MinNotional = parseFloat(f.Notional)
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
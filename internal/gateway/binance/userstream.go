@@ -0,0 +1,308 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/gateway/exchange"
+	"brale/internal/logger"
+	symbolpkg "brale/internal/pkg/symbol"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+const listenKeyKeepalive = 30 * time.Minute
+
+// accountPosition 为 ACCOUNT_UPDATE 推送的最新持仓（单向模式，Amount 带符号）；TxTime 为撮合时间（毫秒），
+// 与同一笔成交的 ORDER_TRADE_UPDATE 成交时间一致，用于判断两类推送的先后。
+type accountPosition struct {
+	Amount     float64
+	EntryPrice float64
+	UpdatedAt  time.Time
+	TxTime     int64
+}
+
+// StartUserStream 订阅 user-data stream，把订单成交转换为 entry_fill/exit_fill 消息交给 OnFill。
+// listenKey 每 30 分钟续期，断线后按退避重连。
+func (e *Executor) StartUserStream(ctx context.Context) {
+	if e == nil {
+		return
+	}
+	go func() {
+		delay := time.Second
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := e.serveUserStream(ctx); err != nil {
+				logger.Warnf("[binance] user stream 断开: %v", err)
+			} else {
+				delay = time.Second
+			}
+			if !sleepWithContext(ctx, delay) {
+				return
+			}
+			delay = nextDelay(delay)
+		}
+	}()
+}
+
+func (e *Executor) serveUserStream(ctx context.Context) error {
+	listenKey, err := e.client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return err
+	}
+	var errMu sync.Mutex
+	var lastErr error
+	doneC, stopC, err := futures.WsUserDataServe(listenKey, func(event *futures.WsUserDataEvent) {
+		e.handleUserEvent(ctx, event)
+	}, func(err error) {
+		if err == nil {
+			return
+		}
+		errMu.Lock()
+		lastErr = err
+		errMu.Unlock()
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infof("✓ Binance user stream 已连接")
+	ticker := time.NewTicker(listenKeyKeepalive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			close(stopC)
+			<-doneC
+			_ = e.client.NewCloseUserStreamService().ListenKey(listenKey).Do(context.Background())
+			return nil
+		case <-doneC:
+			errMu.Lock()
+			defer errMu.Unlock()
+			return lastErr
+		case <-ticker.C:
+			if err := e.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+				logger.Warnf("[binance] listenKey 续期失败: %v", err)
+			}
+		}
+	}
+}
+
+func (e *Executor) handleUserEvent(ctx context.Context, event *futures.WsUserDataEvent) {
+	if event == nil {
+		return
+	}
+	switch event.Event {
+	case futures.UserDataEventTypeOrderTradeUpdate:
+		if !e.ownsOrder(event.OrderTradeUpdate) {
+			return
+		}
+		e.accumulateFee(event.OrderTradeUpdate)
+		if msg, ok := e.fillMessage(event.OrderTradeUpdate); ok && e.OnFill != nil {
+			e.OnFill(ctx, msg)
+		}
+	case futures.UserDataEventTypeAccountUpdate:
+		now := time.UnixMilli(event.Time)
+		tx := event.TransactionTime
+		if tx <= 0 {
+			tx = event.Time
+		}
+		for _, p := range event.AccountUpdate.Positions {
+			amt := parseFloat(p.Amount)
+			e.mu.Lock()
+			e.account[p.Symbol] = accountPosition{Amount: amt, EntryPrice: parseFloat(p.EntryPrice), UpdatedAt: now, TxTime: tx}
+			if amt == 0 {
+				e.settleFlatLocked(p.Symbol, tx)
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// settleFlatLocked 处理持仓归零的 ACCOUNT_UPDATE：平仓成交已先行推送（exitAt 不早于归零时间）时丢弃持仓 ID；
+// 否则保留，由随后到达的 exit_fill 在发出后丢弃，强平/ADL 单也仍能被 ownsOrder 识别。调用方需持有 e.mu。
+func (e *Executor) settleFlatLocked(symbol string, tx int64) {
+	for _, side := range []string{"long", "short"} {
+		key := positionKey(symbol, side)
+		if at, ok := e.exitAt[key]; ok && at >= tx {
+			e.dropPositionLocked(key)
+		}
+	}
+}
+
+// flatLocked 判断最近的 ACCOUNT_UPDATE 是否显示 symbol 已无持仓（残留的持仓 ID 不再有效）。调用方需持有 e.mu。
+func (e *Executor) flatLocked(symbol string) bool {
+	snap, ok := e.account[symbol]
+	return ok && snap.Amount == 0
+}
+
+// flatAfterLocked 判断 ACCOUNT_UPDATE 是否已确认 symbol 在 tradeTime 这笔成交时（或之后）归零。调用方需持有 e.mu。
+func (e *Executor) flatAfterLocked(symbol string, tradeTime int64) bool {
+	snap, ok := e.account[symbol]
+	return ok && snap.Amount == 0 && snap.TxTime >= tradeTime
+}
+
+// ownsOrder 过滤出由本执行器负责的订单：brale 下的单，或本执行器持仓上的强平/ADL 单。
+// 与 freqtrade 共用账户时，freqtrade 的订单不会被当作直连成交重复处理。
+func (e *Executor) ownsOrder(o futures.WsOrderTradeUpdate) bool {
+	if strings.HasPrefix(o.ClientOrderID, clientOrderIDPrefix) {
+		return true
+	}
+	if !e.owns(o.Symbol) {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, long := e.posIDs[positionKey(o.Symbol, "long")]
+	_, short := e.posIDs[positionKey(o.Symbol, "short")]
+	return (long || short) && isExitOrder(o)
+}
+
+// isExitOrder 判断订单是否为减仓单（reduce-only、全平条件单、强平/ADL）。
+func isExitOrder(o futures.WsOrderTradeUpdate) bool {
+	return o.IsReduceOnly || o.IsClosingPosition ||
+		strings.HasPrefix(o.ClientOrderID, "autoclose") || strings.HasPrefix(o.ClientOrderID, "adl_autoclose")
+}
+
+// VerifyPosition 返回交易所侧 symbol 在 side 方向的持仓数量：优先使用 user-data stream 推送的快照，
+// 无快照时回退到 REST 查询。用于核对 freqtrade 回报的成交数量。
+func (e *Executor) VerifyPosition(ctx context.Context, symbol, side string) (float64, error) {
+	clean := symbolpkg.Binance.ToExchange(symbol)
+	e.mu.Lock()
+	snap, ok := e.account[clean]
+	e.mu.Unlock()
+	amt := snap.Amount
+	if !ok {
+		risks, err := e.client.NewGetPositionRiskService().Symbol(clean).Do(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("binance position risk %s failed: %w", clean, err)
+		}
+		amt = 0
+		for _, r := range risks {
+			if r != nil {
+				amt += parseFloat(r.PositionAmt)
+			}
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(side)) {
+	case "short":
+		if amt < 0 {
+			return -amt, nil
+		}
+	default:
+		if amt > 0 {
+			return amt, nil
+		}
+	}
+	return 0, nil
+}
+
+// fillMessage 只处理完全成交的订单；reduce-only/平仓单视为 exit_fill，其余视为 entry_fill。
+func (e *Executor) fillMessage(o futures.WsOrderTradeUpdate) (exchange.WebhookMessage, bool) {
+	if o.Status != futures.OrderStatusTypeFilled {
+		return exchange.WebhookMessage{}, false
+	}
+	exit := isExitOrder(o)
+	direction := "long"
+	if (o.Side == futures.SideTypeSell) != exit {
+		direction = "short"
+	}
+	qty := parseFloat(o.AccumulatedFilledQty)
+	key := positionKey(o.Symbol, direction)
+	e.mu.Lock()
+	leverage := e.leverages[o.Symbol]
+	id, ok := e.posIDs[key]
+	if !exit && (!ok || e.flatLocked(o.Symbol)) {
+		id = o.ID
+		e.posIDs[key] = id
+	}
+	fee := e.orderFees[o.ID]
+	delete(e.orderFees, o.ID)
+	if exit {
+		fee += e.takeEntryFee(key, qty)
+		// 持仓 ID 须在 exit_fill 带出之后才丢弃：归零已确认（或全平单）时立即丢弃，否则等 ACCOUNT_UPDATE。
+		if o.IsClosingPosition || e.flatAfterLocked(o.Symbol, o.TradeTime) {
+			e.dropPositionLocked(key)
+		} else {
+			e.exitAt[key] = o.TradeTime
+		}
+	} else {
+		ef := e.entryFees[key]
+		e.entryFees[key] = entryFee{qty: ef.qty + qty, fee: ef.fee + fee}
+		delete(e.exitAt, key)
+	}
+	e.mu.Unlock()
+	if leverage <= 0 {
+		leverage = 1
+	}
+	price := parseFloat(o.AveragePrice)
+	if price <= 0 {
+		price = parseFloat(o.LastFilledPrice)
+	}
+	ts := time.UnixMilli(o.TradeTime).UTC().Format(time.RFC3339)
+	msg := exchange.WebhookMessage{
+		TradeID:     id,
+		Pair:        symbolpkg.Binance.FromExchange(o.Symbol),
+		Direction:   direction,
+		Amount:      qty,
+		StakeAmount: qty * price / leverage,
+		Leverage:    int(leverage),
+		Fee:         fee,
+	}
+	if exit {
+		msg.Type = "exit_fill"
+		msg.CloseRate = price
+		msg.CloseDate = ts
+		// rp 为毛盈亏，扣除平仓与按数量分摊的开仓手续费后与 freqtrade 的 profit_abs 口径一致。
+		msg.ProfitAbs = parseFloat(o.RealizedPnL) - fee
+		msg.ExitReason = strings.ToLower(string(o.OriginalType))
+	} else {
+		msg.Type = "entry_fill"
+		msg.OpenRate = price
+		msg.OpenDate = ts
+	}
+	return msg, true
+}
+
+// accumulateFee 累计每笔成交推送的手续费；以 BNB 等非 stake 币种抵扣的手续费无法直接折算，忽略。
+func (e *Executor) accumulateFee(o futures.WsOrderTradeUpdate) {
+	if o.Status == futures.OrderStatusTypeCanceled || o.Status == futures.OrderStatusTypeExpired {
+		e.mu.Lock()
+		delete(e.orderFees, o.ID)
+		e.mu.Unlock()
+		return
+	}
+	if o.ExecutionType != futures.OrderExecutionTypeTrade || !strings.EqualFold(o.CommissionAsset, e.stakeCurrency) {
+		return
+	}
+	fee := parseFloat(o.Commission)
+	if fee == 0 {
+		return
+	}
+	e.mu.Lock()
+	e.orderFees[o.ID] += fee
+	e.mu.Unlock()
+}
+
+// takeEntryFee 按平仓数量占比分摊开仓手续费；调用方需持有 e.mu。
+func (e *Executor) takeEntryFee(key string, qty float64) float64 {
+	ef, ok := e.entryFees[key]
+	if !ok || ef.qty <= 0 || qty <= 0 {
+		return 0
+	}
+	share := math.Min(1, qty/ef.qty)
+	fee := ef.fee * share
+	ef.qty -= qty
+	ef.fee -= fee
+	if ef.qty <= 1e-12 {
+		delete(e.entryFees, key)
+	} else {
+		e.entryFees[key] = ef
+	}
+	return fee
+}
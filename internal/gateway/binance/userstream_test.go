@@ -0,0 +1,123 @@
+package binance
+
+import (
+	"context"
+	"testing"
+
+	"brale/internal/gateway/exchange"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSymbol = "BTCUSDT"
+
+// newStreamExecutor 构造只用于处理 user-data 推送的执行器，已有一笔 id=100 的多仓。
+func newStreamExecutor(fills *[]exchange.WebhookMessage) *Executor {
+	e := &Executor{
+		stakeCurrency: "USDT",
+		posIDs:        map[string]int64{positionKey(testSymbol, "long"): 100},
+		exitAt:        make(map[string]int64),
+		leverages:     map[string]float64{testSymbol: 5},
+		account:       make(map[string]accountPosition),
+		orderFees:     make(map[int64]float64),
+		entryFees:     make(map[string]entryFee),
+	}
+	e.OnFill = func(_ context.Context, msg exchange.WebhookMessage) {
+		*fills = append(*fills, msg)
+	}
+	return e
+}
+
+func accountUpdate(tx int64, amount string) *futures.WsUserDataEvent {
+	ev := &futures.WsUserDataEvent{Event: futures.UserDataEventTypeAccountUpdate, Time: tx + 5, TransactionTime: tx}
+	ev.AccountUpdate.Positions = []futures.WsPosition{{Symbol: testSymbol, Amount: amount, EntryPrice: "60000"}}
+	return ev
+}
+
+func exitFill(id, tradeTime int64, clientID, qty string) *futures.WsUserDataEvent {
+	ev := &futures.WsUserDataEvent{Event: futures.UserDataEventTypeOrderTradeUpdate, Time: tradeTime + 5}
+	ev.OrderTradeUpdate = futures.WsOrderTradeUpdate{
+		Symbol:               testSymbol,
+		ClientOrderID:        clientID,
+		Side:                 futures.SideTypeSell,
+		OriginalType:         futures.OrderTypeMarket,
+		Status:               futures.OrderStatusTypeFilled,
+		ExecutionType:        futures.OrderExecutionTypeTrade,
+		ID:                   id,
+		AccumulatedFilledQty: qty,
+		AveragePrice:         "61000",
+		IsReduceOnly:         true,
+		TradeTime:            tradeTime,
+	}
+	return ev
+}
+
+func (e *Executor) hasPositionID(side string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.posIDs[positionKey(testSymbol, side)]
+	return ok
+}
+
+func TestUserStream_AccountUpdateBeforeExitFill(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name     string
+		clientID string
+	}{
+		{name: "brale reduce-only close", clientID: clientOrderIDPrefix + "exit-1"},
+		{name: "liquidation", clientID: "autoclose-123"},
+		{name: "adl", clientID: "adl_autoclose-123"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fills []exchange.WebhookMessage
+			e := newStreamExecutor(&fills)
+
+			e.handleUserEvent(ctx, accountUpdate(1000, "0"))
+			assert.True(t, e.hasPositionID("long"), "position id must survive the flat snapshot until the exit fill")
+
+			e.handleUserEvent(ctx, exitFill(200, 1000, tc.clientID, "0.1"))
+			require.Len(t, fills, 1)
+			assert.Equal(t, "exit_fill", fills[0].Type)
+			assert.Equal(t, int64(100), fills[0].TradeID)
+			assert.Equal(t, "long", fills[0].Direction)
+			assert.False(t, e.hasPositionID("long"))
+		})
+	}
+}
+
+func TestUserStream_ExitFillBeforeAccountUpdate(t *testing.T) {
+	ctx := context.Background()
+	var fills []exchange.WebhookMessage
+	e := newStreamExecutor(&fills)
+
+	e.handleUserEvent(ctx, exitFill(200, 1000, clientOrderIDPrefix+"exit-1", "0.1"))
+	require.Len(t, fills, 1)
+	assert.Equal(t, int64(100), fills[0].TradeID)
+	assert.True(t, e.hasPositionID("long"), "flatness not yet confirmed")
+
+	e.handleUserEvent(ctx, accountUpdate(1000, "0"))
+	assert.False(t, e.hasPositionID("long"))
+}
+
+func TestUserStream_PartialExitKeepsPositionID(t *testing.T) {
+	ctx := context.Background()
+	var fills []exchange.WebhookMessage
+	e := newStreamExecutor(&fills)
+
+	// 部分平仓：成交先到、持仓仍非零。
+	e.handleUserEvent(ctx, exitFill(200, 1000, clientOrderIDPrefix+"exit-1", "0.05"))
+	e.handleUserEvent(ctx, accountUpdate(1000, "0.05"))
+	assert.True(t, e.hasPositionID("long"))
+
+	// 剩余部分平仓：归零快照先到，随后的成交仍带原持仓 ID。
+	e.handleUserEvent(ctx, accountUpdate(2000, "0"))
+	assert.True(t, e.hasPositionID("long"))
+	e.handleUserEvent(ctx, exitFill(201, 2000, clientOrderIDPrefix+"exit-2", "0.05"))
+	require.Len(t, fills, 2)
+	assert.Equal(t, int64(100), fills[1].TradeID)
+	assert.False(t, e.hasPositionID("long"))
+}
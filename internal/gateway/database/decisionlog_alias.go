@@ -13,10 +13,19 @@ type (
 	StrategyInstanceRecord  = decisionlog.StrategyInstanceRecord
 	StrategyChangeLogRecord = decisionlog.StrategyChangeLogRecord
 	DecisionRoundSummary    = decisionlog.DecisionRoundSummary
+	EntrySignalRecord       = decisionlog.EntrySignalRecord
+	EntrySignalOutcome      = decisionlog.EntrySignalOutcome
+	DecisionAuditRecord     = decisionlog.DecisionAuditRecord
+	DecisionRationaleRecord = decisionlog.DecisionRationaleRecord
+	ClosedTradeStat         = decisionlog.ClosedTradeStat
+	TradeOutcome            = decisionlog.TradeOutcome
+	ModelUsageRecord        = decisionlog.ModelUsageRecord
+	DecisionLogDialect      = decisionlog.Dialect
 )
 
 var (
-	NewDecisionLogObserver = decisionlog.NewDecisionLogObserver
+	NewDecisionLogObserver  = decisionlog.NewDecisionLogObserver
+	ParseDecisionLogDialect = decisionlog.ParseDialect
 )
 
 const (
@@ -31,6 +31,26 @@ type WriteLivePositionStore interface {
 	AppendEvent(ctx context.Context, evt EventRecord) error
 }
 
+// WebhookDeliveryLog 持久化对外 webhook 的投递记录。
+type WebhookDeliveryLog interface {
+	AppendWebhookDelivery(ctx context.Context, rec WebhookDeliveryRecord) error
+	ListWebhookDeliveries(ctx context.Context, q WebhookDeliveryQuery) ([]WebhookDeliveryRecord, error)
+}
+
+// AnnotationStore 持久化人工备注与标签（交易、profile）。
+type AnnotationStore interface {
+	AddAnnotation(ctx context.Context, rec AnnotationRecord) (AnnotationRecord, error)
+	ListAnnotations(ctx context.Context, q AnnotationQuery) ([]AnnotationRecord, error)
+	DeleteAnnotation(ctx context.Context, id int64) error
+}
+
+// PendingExitStore 持久化已下发平仓、尚未收到成交回报的交易，重启后据此与 freqtrade 对账。
+type PendingExitStore interface {
+	SavePendingExit(ctx context.Context, rec PendingExitRecord) error
+	DeletePendingExit(ctx context.Context, tradeID int) error
+	ListPendingExits(ctx context.Context) ([]PendingExitRecord, error)
+}
+
 type LivePositionStore interface {
 	ReadLivePositionStore
 	WriteLivePositionStore
@@ -77,7 +97,11 @@ type LiveOrderRecord struct {
 	UnrealizedPnLUSD   *float64
 	RealizedPnLRatio   *float64
 	RealizedPnLUSD     *float64
-	LastStatusSync     *time.Time
+	FeeUSD             *float64
+	FundingUSD         *float64
+	// GrossRealizedPnLUSD 为已平仓交易还原手续费与资金费前的毛盈亏，RealizedPnLUSD/PnLUSD 为净值。
+	GrossRealizedPnLUSD *float64
+	LastStatusSync      *time.Time
 }
 
 type OperationType int
@@ -91,6 +115,8 @@ const (
 	OperationFinalStop  OperationType = 9
 	OperationFailed     OperationType = 10
 	OperationForceExit  OperationType = 11
+	OperationScaleIn    OperationType = 12
+	OperationTimeExit   OperationType = 13
 )
 
 type TradeOperationRecord struct {
@@ -109,3 +135,55 @@ type EventRecord struct {
 	TradeID   int
 	Symbol    string
 }
+
+// PendingExitRecord 为一笔等待平仓成交的交易，RequestedAt 为下发平仓（或重新下发）的时间。
+type PendingExitRecord struct {
+	TradeID     int
+	RequestedAt time.Time
+}
+
+// WebhookDeliveryRecord 为一次 webhook 投递尝试（含重试）。
+type WebhookDeliveryRecord struct {
+	ID         int64     `json:"id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Symbol     string    `json:"symbol,omitempty"`
+	TradeID    int       `json:"trade_id,omitempty"`
+	Endpoint   string    `json:"endpoint"`
+	URL        string    `json:"url"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryQuery struct {
+	EventType string
+	Endpoint  string
+	Failed    bool
+	Limit     int
+}
+
+const (
+	AnnotationTargetTrade   = "trade"
+	AnnotationTargetProfile = "profile"
+)
+
+// AnnotationRecord 为附加在交易或 profile 上的一条人工备注。
+type AnnotationRecord struct {
+	ID         int64     `json:"id"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	Note       string    `json:"note"`
+	Tags       []string  `json:"tags,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AnnotationQuery struct {
+	TargetType string
+	TargetID   string
+	Tag        string
+	Limit      int
+}
@@ -22,6 +22,11 @@ type WebhookProvider interface {
 	HandleWebhook(ctx context.Context, payload map[string]any) error
 }
 
+// PositionVerifier 由能直接读取交易所持仓的执行器实现，返回 symbol 在 side 方向的持仓数量。
+type PositionVerifier interface {
+	VerifyPosition(ctx context.Context, symbol, side string) (float64, error)
+}
+
 type PriceSubscriber interface {
 	SubscribePrice(ctx context.Context, symbol string, callback func(PriceQuote)) error
 
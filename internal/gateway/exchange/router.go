@@ -0,0 +1,116 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Router 按 symbol 所属 profile 把下单请求分发到不同执行器，未匹配时使用 Default。
+// 持仓查询会合并所有执行器的结果。
+type Router struct {
+	Default   Exchange
+	Executors map[string]Exchange
+	// Resolve 返回 symbol 对应的执行器名称，空字符串表示使用 Default。
+	Resolve func(symbol string) string
+}
+
+func (r *Router) Name() string {
+	return "router"
+}
+
+func (r *Router) pick(symbol string) (Exchange, error) {
+	if r.Resolve != nil {
+		if name := strings.ToLower(strings.TrimSpace(r.Resolve(symbol))); name != "" {
+			if ex, ok := r.Executors[name]; ok && ex != nil {
+				return ex, nil
+			}
+			if r.Default == nil || !strings.EqualFold(r.Default.Name(), name) {
+				return nil, fmt.Errorf("executor %s 未启用 (symbol=%s)", name, symbol)
+			}
+		}
+	}
+	if r.Default == nil {
+		return nil, fmt.Errorf("no default executor for %s", symbol)
+	}
+	return r.Default, nil
+}
+
+// UsesDefault 判断 symbol 是否由 Default 执行器处理。
+func (r *Router) UsesDefault(symbol string) bool {
+	ex, err := r.pick(symbol)
+	return err == nil && ex == r.Default
+}
+
+func (r *Router) all() []Exchange {
+	out := make([]Exchange, 0, len(r.Executors)+1)
+	if r.Default != nil {
+		out = append(out, r.Default)
+	}
+	for _, ex := range r.Executors {
+		if ex == nil || ex == r.Default {
+			continue
+		}
+		out = append(out, ex)
+	}
+	return out
+}
+
+func (r *Router) OpenPosition(ctx context.Context, req OpenRequest) (*OpenResult, error) {
+	ex, err := r.pick(req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	return ex.OpenPosition(ctx, req)
+}
+
+func (r *Router) ClosePosition(ctx context.Context, req CloseRequest) error {
+	ex, err := r.pick(req.Symbol)
+	if err != nil {
+		return err
+	}
+	return ex.ClosePosition(ctx, req)
+}
+
+// GetPosition 依次询问各执行器，返回第一个命中的持仓。
+func (r *Router) GetPosition(ctx context.Context, positionID string) (*Position, error) {
+	var lastErr error
+	for _, ex := range r.all() {
+		pos, err := ex.GetPosition(ctx, positionID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if pos != nil {
+			return pos, nil
+		}
+	}
+	return nil, lastErr
+}
+
+func (r *Router) ListOpenPositions(ctx context.Context) ([]Position, error) {
+	var out []Position
+	for _, ex := range r.all() {
+		positions, err := ex.ListOpenPositions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s list positions failed: %w", ex.Name(), err)
+		}
+		out = append(out, positions...)
+	}
+	return out, nil
+}
+
+func (r *Router) GetBalance(ctx context.Context) (Balance, error) {
+	if r.Default == nil {
+		return Balance{}, fmt.Errorf("no default executor")
+	}
+	return r.Default.GetBalance(ctx)
+}
+
+func (r *Router) GetPrice(ctx context.Context, symbol string) (PriceQuote, error) {
+	ex, err := r.pick(symbol)
+	if err != nil {
+		return PriceQuote{}, err
+	}
+	return ex.GetPrice(ctx, symbol)
+}
@@ -147,6 +147,10 @@ type APIPosition struct {
 	UnrealizedPnLUSD   float64    `json:"unrealized_pnl_usd"`
 	RealizedPnLRatio   float64    `json:"realized_pnl_ratio,omitempty"`
 	RealizedPnLUSD     float64    `json:"realized_pnl_usd,omitempty"`
+	FeeUSD             float64    `json:"fee_usd,omitempty"`
+	FundingUSD         float64    `json:"funding_usd,omitempty"`
+	GrossPnLUSD        float64    `json:"gross_pnl_usd,omitempty"`
+	GrossRealizedUSD   float64    `json:"gross_realized_pnl_usd,omitempty"`
 	RemainingRatio     float64    `json:"remaining_ratio"`
 	Placeholder        bool       `json:"placeholder,omitempty"`
 	CloseHistory       []APIOrder `json:"close_history,omitempty"`
@@ -176,6 +180,29 @@ type PlanUpdateHook interface {
 	NotifyPlanUpdated(context.Context, int)
 }
 
+// ScaleInHook 由持仓监控实现：开仓成交后登记决策中的加仓价位，由监控在价格到达时追加入场。
+type ScaleInHook interface {
+	RegisterScaleIn(context.Context, ScaleInRegistration)
+}
+
+// ScaleInRegistration 为一笔已成交开仓的加仓计划；Stake 为首仓保证金，用于计算均价与缺省加仓金额。
+type ScaleInRegistration struct {
+	TradeID    int
+	Symbol     string
+	Side       string
+	EntryPrice float64
+	Stake      float64
+	Leverage   float64
+	TraceID    string
+	Levels     []ScaleInLevel
+}
+
+type ScaleInLevel struct {
+	Price     float64
+	ATROffset float64
+	SizeUSD   float64
+}
+
 type WebhookMessage struct {
 	Type        string  `json:"type"`
 	TradeID     int64   `json:"trade_id"`
@@ -192,6 +219,7 @@ type WebhookMessage struct {
 	CurrentRate float64 `json:"current_rate"`
 	ProfitRatio float64 `json:"profit_ratio"`
 	ProfitAbs   float64 `json:"profit_abs"`
+	Fee         float64 `json:"fee,omitempty"`
 	ExitReason  string  `json:"exit_reason"`
 	Reason      string  `json:"reason"`
 	Leverage    int     `json:"leverage"`
@@ -2,36 +2,117 @@ package gateway
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	brcfg "brale/internal/config"
 	"brale/internal/gateway/binance"
 	"brale/internal/gateway/gate"
+	"brale/internal/gateway/okx"
 	"brale/internal/market"
 )
 
-func NewSourceFromConfig(cfg *brcfg.Config) (market.Source, error) {
-	if cfg == nil {
-		return nil, fmt.Errorf("nil config")
+// SourceBuilder 根据 market.sources 中的单项配置构建行情源。
+type SourceBuilder func(brcfg.MarketSource) (market.Source, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]SourceBuilder)
+)
+
+// RegisterSource 以一个或多个名称（大小写不敏感）注册行情源，后注册的同名实现覆盖先前的。
+func RegisterSource(builder SourceBuilder, names ...string) {
+	if builder == nil {
+		return
 	}
-	active := cfg.Market.ResolveActiveSource()
-	name := strings.ToLower(active.Name)
-	switch name {
-	case "", "binance", "binance-futures":
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, name := range names {
+		registry[strings.ToLower(strings.TrimSpace(name))] = builder
+	}
+}
+
+// RegisteredSources 返回已注册的行情源名称（不含空名称别名）。
+func RegisteredSources() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]string, 0, len(registry))
+	for name := range registry {
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func init() {
+	RegisterSource(func(src brcfg.MarketSource) (market.Source, error) {
 		return binance.New(binance.Config{
-			RESTBaseURL:  active.RESTBaseURL,
-			ProxyEnabled: active.Proxy.Enabled,
-			RESTProxyURL: active.Proxy.RESTURL,
-			WSProxyURL:   active.Proxy.WSURL,
+			RESTBaseURL:  src.RESTBaseURL,
+			ProxyEnabled: src.Proxy.Enabled,
+			RESTProxyURL: src.Proxy.RESTURL,
+			WSProxyURL:   src.Proxy.WSURL,
+			WeightLimit:  src.WeightLimit,
 		})
-	case "gate":
+	}, "", "binance", "binance-futures")
+	RegisterSource(func(src brcfg.MarketSource) (market.Source, error) {
 		return gate.New(gate.Config{
-			RESTBaseURL:  active.RESTBaseURL,
-			ProxyEnabled: active.Proxy.Enabled,
-			RESTProxyURL: active.Proxy.RESTURL,
-			WSProxyURL:   active.Proxy.WSURL,
+			RESTBaseURL:  src.RESTBaseURL,
+			ProxyEnabled: src.Proxy.Enabled,
+			RESTProxyURL: src.Proxy.RESTURL,
+			WSProxyURL:   src.Proxy.WSURL,
 		})
-	default:
-		return nil, fmt.Errorf("unsupported market source: %s", active.Name)
+	}, "gate")
+	RegisterSource(func(src brcfg.MarketSource) (market.Source, error) {
+		return okx.New(okx.Config{
+			RESTBaseURL:  src.RESTBaseURL,
+			ProxyEnabled: src.Proxy.Enabled,
+			RESTProxyURL: src.Proxy.RESTURL,
+			WSProxyURL:   src.Proxy.WSURL,
+		})
+	}, "okx", "okx-swap")
+}
+
+func NewSourceFromConfig(cfg *brcfg.Config) (market.Source, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("nil config")
+	}
+	return newSource(cfg.Market.ResolveActiveSource())
+}
+
+// NewSourceByName 按名称构建 market.sources 中的行情源（不要求 enabled），用于交叉校验等辅助场景。
+// 未在 market.sources 中声明但已注册的名称按默认参数构建。
+func NewSourceByName(cfg *brcfg.Config, name string) (market.Source, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("nil config")
+	}
+	name = strings.TrimSpace(name)
+	for _, src := range cfg.Market.Sources {
+		if strings.EqualFold(strings.TrimSpace(src.Name), name) {
+			return newSource(src)
+		}
+	}
+	if name != "" && isRegistered(name) {
+		return newSource(brcfg.MarketSource{Name: name, Enabled: true})
+	}
+	return nil, fmt.Errorf("market source not configured: %s", name)
+}
+
+func isRegistered(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+func newSource(active brcfg.MarketSource) (market.Source, error) {
+	registryMu.RLock()
+	builder, ok := registry[strings.ToLower(strings.TrimSpace(active.Name))]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported market source: %s (registered: %s)", active.Name, strings.Join(RegisteredSources(), ", "))
 	}
+	return builder(active)
 }
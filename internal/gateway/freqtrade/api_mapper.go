@@ -318,16 +318,42 @@ func liveOrderToAPIPosition(rec database.LiveOrderRecord, nowMillis int64) excha
 	computePositionValue(&out)
 	baseStake := deriveBaseStake(out)
 	derivedUSD, derivedRatio := derivePnL(out.EntryPrice, out.CurrentPrice, out.Amount, out.Stake, out.Leverage, out.Side)
+	derivedUSD, derivedRatio = netOfFees(rec, baseStake, derivedUSD, derivedRatio)
 	fillPnL(&out, rec.Status == database.LiveOrderStatusClosed, baseStake, pnlUSD, pnlRatio, derivedUSD, derivedRatio)
 	if rec.Status != database.LiveOrderStatusClosed {
 		syncOpenOrderPnL(&out, rec, baseStake)
 	}
+	applyFeeBreakdown(&out, rec)
 
 	out.RemainingRatio = remainingRatio(rec)
 	finalizeClosure(&out, times.closeMillis, currentPrice)
 	return out
 }
 
+// netOfFees 把按价格推算的毛盈亏扣除手续费并计入资金费，与 freqtrade 回报的净盈亏口径一致。
+func netOfFees(rec database.LiveOrderRecord, baseStake, usd, ratio float64) (float64, float64) {
+	adj := valOrZero(rec.FundingUSD) - valOrZero(rec.FeeUSD)
+	if adj == 0 || (usd == 0 && ratio == 0) {
+		return usd, ratio
+	}
+	usd += adj
+	if baseStake > 0 {
+		ratio = usd / baseStake
+	}
+	return usd, ratio
+}
+
+// applyFeeBreakdown 输出手续费、资金费以及还原后的毛盈亏；PnLUSD/RealizedPnLUSD 始终为净值。
+func applyFeeBreakdown(out *exchange.APIPosition, rec database.LiveOrderRecord) {
+	out.FeeUSD = valOrZero(rec.FeeUSD)
+	out.FundingUSD = valOrZero(rec.FundingUSD)
+	out.GrossRealizedUSD = valOrZero(rec.GrossRealizedPnLUSD)
+	if out.FeeUSD == 0 && out.FundingUSD == 0 {
+		return
+	}
+	out.GrossPnLUSD = out.PnLUSD + out.FeeUSD - out.FundingUSD
+}
+
 func exchangePositionToAPIPosition(pos exchange.Position, nowMillis int64) exchange.APIPosition {
 	now := time.Now()
 	if nowMillis > 0 {
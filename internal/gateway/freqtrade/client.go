@@ -140,6 +140,12 @@ type Trade struct {
 	ProfitAbs        float64 `json:"profit_abs"`
 	TotalProfitAbs   float64 `json:"total_profit_abs,omitempty"`   // Total: realized + unrealized
 	TotalProfitRatio float64 `json:"total_profit_ratio,omitempty"` // Total ratio
+
+	FeeOpen      float64 `json:"fee_open,omitempty"`
+	FeeOpenCost  float64 `json:"fee_open_cost,omitempty"`
+	FeeClose     float64 `json:"fee_close,omitempty"`
+	FeeCloseCost float64 `json:"fee_close_cost,omitempty"`
+	FundingFees  float64 `json:"funding_fees,omitempty"` // 正数为收到资金费，负数为支付
 }
 
 type TradeOrder struct {
@@ -28,6 +28,7 @@ func tradeToLiveRecord(tr *Trade) database.LiveOrderRecord {
 	rec = applyPricing(tr, rec)
 	rec = applyTimestamps(tr, rec)
 	rec = applyPnLFields(tr, rec, isOpen)
+	rec = applyFees(tr, rec)
 
 	rec.LastStatusSync = ptrTime(time.Now())
 	if raw, err := json.Marshal(tr); err == nil {
@@ -138,6 +139,23 @@ func applyClosedPnL(tr *Trade, rec database.LiveOrderRecord) database.LiveOrderR
 	return rec
 }
 
+// applyFees 记录开/平仓手续费与累计资金费；freqtrade 的 profit 字段已扣除二者，这里仅用于展示与核对。
+// 已平仓交易另存还原二者后的毛盈亏，与净盈亏并列落库。
+func applyFees(tr *Trade, rec database.LiveOrderRecord) database.LiveOrderRecord {
+	fee := tr.FeeOpenCost + tr.FeeCloseCost
+	if fee != 0 {
+		rec.FeeUSD = ptrFloat(fee)
+	}
+	if tr.FundingFees != 0 {
+		rec.FundingUSD = ptrFloat(tr.FundingFees)
+	}
+	if rec.Status == database.LiveOrderStatusClosed && rec.PnLUSD != nil {
+		rec.RealizedPnLUSD = ptrFloat(*rec.PnLUSD)
+		rec.GrossRealizedPnLUSD = ptrFloat(*rec.PnLUSD + fee - tr.FundingFees)
+	}
+	return rec
+}
+
 func normalizeTradeSide(tr *Trade) string {
 	if tr == nil {
 		return "long"
@@ -77,6 +77,10 @@ func planComponentLabel(component string) string {
 		return "ATR 止盈"
 	case "sl_atr":
 		return "ATR 止损"
+	case "time_max_hold":
+		return "最长持仓"
+	case "time_session_flat":
+		return "收盘平仓"
 	default:
 		return strings.ToUpper(strings.TrimSpace(component))
 	}
@@ -94,6 +98,8 @@ func eventDisplay(event string) string {
 		return "最终止盈"
 	case exit.PlanEventTypeFinalStopLoss:
 		return "最终止损"
+	case exit.PlanEventTypeTimeExit:
+		return "到时平仓"
 	default:
 		return event
 	}
@@ -0,0 +1,85 @@
+package freqtrade
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+)
+
+type cachedEntryDrift struct {
+	TraceID  string
+	Drift    decision.EntryDrift
+	CachedAt time.Time
+}
+
+// cacheEntryDrift 暂存开仓决策的价格漂移，待入场成交（拿到 trade_id）后写入 trade_operation_log。
+func (m *Manager) cacheEntryDrift(traceID string, d decision.Decision) {
+	if m == nil || d.EntryDrift == nil {
+		return
+	}
+	symbol := normalizePlanSymbol(d.Symbol)
+	if symbol == "" {
+		return
+	}
+	now := time.Now()
+	m.entryDriftMu.Lock()
+	defer m.entryDriftMu.Unlock()
+	if m.entryDrifts == nil {
+		m.entryDrifts = make(map[string]cachedEntryDrift)
+	}
+	cutoff := now.Add(-openPlanCacheTTL)
+	for k, v := range m.entryDrifts {
+		if v.CachedAt.Before(cutoff) {
+			delete(m.entryDrifts, k)
+		}
+	}
+	m.entryDrifts[symbol] = cachedEntryDrift{TraceID: strings.TrimSpace(traceID), Drift: *d.EntryDrift, CachedAt: now}
+}
+
+// recordEntryDrift 在入场成交后以 OperationOpen 记录决策参考价、下单前实时价、成交价与延迟。
+func (m *Manager) recordEntryDrift(ctx context.Context, tradeID int, symbol string, fillPrice float64) {
+	if m == nil || m.posStore == nil || tradeID <= 0 {
+		return
+	}
+	key := normalizePlanSymbol(symbol)
+	m.entryDriftMu.Lock()
+	entry, ok := m.entryDrifts[key]
+	if ok {
+		delete(m.entryDrifts, key)
+	}
+	m.entryDriftMu.Unlock()
+	if !ok || time.Since(entry.CachedAt) > openPlanCacheTTL {
+		return
+	}
+	details := map[string]any{
+		"trace_id":        entry.TraceID,
+		"reference_price": entry.Drift.ReferencePrice,
+		"market_price":    entry.Drift.MarketPrice,
+		"drift_pct":       entry.Drift.DriftPct,
+		"latency_ms":      entry.Drift.LatencyMs,
+		"drift_action":    entry.Drift.Action,
+	}
+	if entry.Drift.DriftATR != 0 {
+		details["drift_atr"] = entry.Drift.DriftATR
+	}
+	if fillPrice > 0 {
+		details["fill_price"] = fillPrice
+		if ref := entry.Drift.ReferencePrice; ref > 0 {
+			details["fill_drift_pct"] = (fillPrice - ref) / ref
+		}
+	}
+	rec := database.TradeOperationRecord{
+		FreqtradeID: tradeID,
+		Symbol:      key,
+		Operation:   database.OperationOpen,
+		Details:     details,
+		Timestamp:   time.Now(),
+	}
+	if err := m.posStore.AppendTradeOperation(ctx, rec); err != nil {
+		logger.Warnf("freqtrade: 写入开仓漂移记录失败 trade=%d symbol=%s err=%v", tradeID, key, err)
+	}
+}
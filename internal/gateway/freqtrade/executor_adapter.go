@@ -17,6 +17,8 @@ import (
 type Adapter struct {
 	client *Client
 	cfg    *config.FreqtradeConfig
+	// tradingMode 返回 symbol 所属 profile 覆盖的交易模式，空串表示使用 freqtrade.trading_mode。
+	tradingMode func(symbol string) string
 }
 
 func NewAdapter(client *Client, cfg *config.FreqtradeConfig) *Adapter {
@@ -26,13 +28,37 @@ func NewAdapter(client *Client, cfg *config.FreqtradeConfig) *Adapter {
 	}
 }
 
+// SetTradingMode 设置按 symbol 解析 profile 交易模式覆盖的函数。
+func (a *Adapter) SetTradingMode(fn func(symbol string) string) {
+	a.tradingMode = fn
+}
+
+func (a *Adapter) modeFor(symbol string) string {
+	override := ""
+	if a.tradingMode != nil {
+		override = a.tradingMode(symbol)
+	}
+	if a.cfg == nil {
+		return config.FreqtradeConfig{}.ResolveTradingMode(override)
+	}
+	return a.cfg.ResolveTradingMode(override)
+}
+
 func (a *Adapter) Name() string {
 	return "freqtrade"
 }
 
 func (a *Adapter) OpenPosition(ctx context.Context, req exchange.OpenRequest) (*exchange.OpenResult, error) {
+	mode := a.modeFor(req.Symbol)
+	if strings.EqualFold(req.Side, "short") && !config.TradingModeAllowsShort(mode) {
+		return nil, fmt.Errorf("freqtrade %s 模式不支持开空 %s", mode, req.Symbol)
+	}
+	if !config.TradingModeUsesLeverage(mode) {
+		// 现货不带杠杆：stake 即全部名义价值。合约的杠杆档位封顶在决策执行前按交易规则完成。
+		req.Leverage = 0
+	}
 	payload := ForceEnterPayload{
-		Pair:        a.toFreqtradePair(req.Symbol),
+		Pair:        a.toFreqtradePair(req.Symbol, mode),
 		Side:        req.Side,
 		StakeAmount: req.Amount,
 		OrderType:   req.OrderType,
@@ -231,11 +257,15 @@ func (a *Adapter) lookupAvailableStake(ctx context.Context) float64 {
 	return bal.Available
 }
 
-func (a *Adapter) toFreqtradePair(sym string) string {
+// toFreqtradePair 按交易模式格式化交易对：futures 带结算币后缀（BTC/USDT:USDT），spot/margin 不带。
+func (a *Adapter) toFreqtradePair(sym, mode string) string {
 	stakeCurrency := ""
 	if a.cfg != nil {
 		stakeCurrency = a.cfg.StakeCurrency
 	}
+	if mode != config.TradingModeFutures {
+		return symbolpkg.FreqtradeSpot(stakeCurrency).ToExchange(sym)
+	}
 	return symbolpkg.Freqtrade(stakeCurrency).ToExchange(sym)
 }
 
@@ -11,6 +11,7 @@ import (
 	"brale/internal/gateway/database"
 	"brale/internal/gateway/exchange"
 	"brale/internal/gateway/notifier"
+	"brale/internal/gateway/webhook"
 	"brale/internal/logger"
 	"brale/internal/store"
 	"brale/internal/trader"
@@ -30,15 +31,24 @@ type Manager struct {
 	executor       exchange.Exchange
 	balance        exchange.Balance
 	planUpdateHook exchange.PlanUpdateHook
+	verifier       exchange.PositionVerifier
+	webhooks       *webhook.Dispatcher
 
 	trader *trader.Trader
 
 	openPlanMu    sync.Mutex
 	openPlanCache map[string]cachedOpenPlan
 
-	pendingMu sync.Mutex
-	pending   map[int]*pendingState
-	notifier  notifier.TextNotifier
+	entryDriftMu sync.Mutex
+	entryDrifts  map[string]cachedEntryDrift
+
+	pendingMu    sync.Mutex
+	pending      map[int]*pendingState
+	pendingExits database.PendingExitStore
+	notifier     notifier.TextNotifier
+
+	// stuckAlerts 记录卡单巡检已告警的结果，仅由巡检 goroutine 访问。
+	stuckAlerts map[int]closingResult
 }
 
 const (
@@ -73,6 +83,8 @@ func NewManager(client *Client, cfg config.FreqtradeConfig, logStore Logger, pos
 	}
 	t.Start()
 
+	pendingExits, _ := posStore.(database.PendingExitStore)
+
 	return &Manager{
 		client:        client,
 		cfg:           cfg,
@@ -82,6 +94,7 @@ func NewManager(client *Client, cfg config.FreqtradeConfig, logStore Logger, pos
 		posRepo:       NewPositionRepo(newStore, posStore),
 		executor:      executor,
 		trader:        t,
+		pendingExits:  pendingExits,
 		notifier:      textNotifier,
 		openPlanCache: make(map[string]cachedOpenPlan),
 	}, nil
@@ -8,10 +8,17 @@ import (
 )
 
 func (m *Manager) RefreshBalance(ctx context.Context) (exchange.Balance, error) {
-	if m.client == nil {
+	var bal exchange.Balance
+	var err error
+	switch {
+	case m.client != nil:
+		bal, err = m.client.GetBalance(ctx)
+	case m.executor != nil:
+		// 未部署 freqtrade 时由直连执行器提供余额。
+		bal, err = m.executor.GetBalance(ctx)
+	default:
 		return exchange.Balance{}, fmt.Errorf("client not initialized")
 	}
-	bal, err := m.client.GetBalance(ctx)
 	if err != nil {
 		return exchange.Balance{}, err
 	}
@@ -50,6 +50,35 @@ func (m *Manager) CloseFreqtradePosition(ctx context.Context, tradeID int, symbo
 	return nil
 }
 
+// ScaleInFreqtradePosition 对已有交易追加入场（forceenter 同一交易对，需 freqtrade 开启 position_adjustment_enable）。
+// 不经过 trader actor：actor 对已有持仓的入场信号会直接忽略。
+func (m *Manager) ScaleInFreqtradePosition(ctx context.Context, tradeID int, symbol, side string, stake, leverage float64) error {
+	if m.executor == nil {
+		return fmt.Errorf("executor not initialized")
+	}
+	if stake <= 0 {
+		return fmt.Errorf("ScaleInFreqtradePosition: invalid stake %.4f", stake)
+	}
+	if norm := freqtradePairToSymbol(symbol); norm != "" {
+		symbol = norm
+	}
+	if activeID, ok := m.TradeIDBySymbol(symbol); !ok || activeID != tradeID {
+		return fmt.Errorf("ScaleInFreqtradePosition: trade %d is not the active position for %s", tradeID, symbol)
+	}
+	req := exchange.OpenRequest{
+		Symbol:    symbol,
+		Side:      side,
+		OrderType: "market",
+		Amount:    stake,
+		Leverage:  leverage,
+	}
+	if _, err := m.executor.OpenPosition(ctx, req); err != nil {
+		return err
+	}
+	logger.Infof("freqtrade scale-in submitted trade=%d %s %s stake=%.4f leverage=%.2f", tradeID, symbol, side, stake, leverage)
+	return nil
+}
+
 func (m *Manager) validateTradeForClose(ctx context.Context, tradeID int, symbol string) error {
 	if norm := freqtradePairToSymbol(symbol); norm != "" {
 		symbol = norm
@@ -64,7 +64,7 @@ func (m *Manager) sendEntryFillNotification(ctx context.Context, msg exchange.We
 		Sections:  []notifier.MessageSection{{Title: "执行明细", Lines: lines}},
 		Timestamp: time.Now().UTC(),
 	}
-	if err := m.notifier.SendText(msgBody.RenderMarkdown()); err != nil {
+	if err := notifier.SendCategory(m.notifier, notifier.CategoryOpen, msgBody); err != nil {
 		logger.Warnf("Telegram 推送失败(entry_fill): %v", err)
 	}
 }
@@ -91,7 +91,11 @@ func (m *Manager) sendExitFillNotification(ctx context.Context, msg exchange.Web
 		Sections:  []notifier.MessageSection{{Title: "执行明细", Lines: lines}},
 		Timestamp: time.Now().UTC(),
 	}
-	if err := m.notifier.SendText(msgBody.RenderMarkdown()); err != nil {
+	category := notifier.CategoryClose
+	if payload.RemainingAmount > 0 {
+		category = notifier.CategoryTier
+	}
+	if err := notifier.SendCategory(m.notifier, category, msgBody); err != nil {
 		logger.Warnf("Telegram 推送失败(exit_fill): %v", err)
 	}
 }
@@ -130,6 +134,19 @@ func (m *Manager) buildExitNotificationLines(ctx context.Context, payload trader
 	if math.Abs(pnlAbs) >= 1e-9 || math.Abs(pnlPct) >= 1e-9 {
 		lines = append(lines, formatPnLLine(pnlAbs, pnlPct, pctAlreadyPercent))
 	}
+	funding := 0.0
+	if payload.RemainingAmount <= 0 {
+		funding = m.lookupFunding(ctx, tradeID)
+	}
+	if payload.Fee > 0 || funding != 0 {
+		// freqtrade 回报的盈亏已扣手续费与资金费；按价格估算的盈亏为毛值，需要自行扣除。
+		gross, net := pnlAbs+payload.Fee-funding, pnlAbs
+		if pctAlreadyPercent {
+			gross, net = pnlAbs, pnlAbs-payload.Fee+funding
+		}
+		lines = append(lines, fmt.Sprintf("毛盈亏 %s · 手续费 %.4f USDT · 资金费 %s → 净盈亏 %s",
+			formatSignedValue(gross), payload.Fee, formatSignedValue(funding), formatSignedValue(net)))
+	}
 	if tradeID > 0 {
 		lines = append(lines, fmt.Sprintf("TradeID %d", tradeID))
 	}
@@ -145,6 +162,21 @@ func formatPnLLine(pnlAbs, pnlPct float64, pctAlreadyPercent bool) string {
 	return fmt.Sprintf("盈亏 %s · %s", formatSignedValue(pnlAbs), formatSignedPercent(displayPct))
 }
 
+// lookupFunding 读取持仓累计资金费（正数为收到），仅在完全平仓时计入通知。
+func (m *Manager) lookupFunding(ctx context.Context, tradeID int) float64 {
+	if tradeID <= 0 || m.posRepo == nil {
+		return 0
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rec, ok, err := m.posRepo.GetPosition(ctx, tradeID)
+	if err != nil || !ok || rec.FundingUSD == nil {
+		return 0
+	}
+	return *rec.FundingUSD
+}
+
 func (m *Manager) lookupEntryPrice(ctx context.Context, tradeID int, symbol string) float64 {
 	if ctx == nil {
 		ctx = context.Background()
@@ -0,0 +1,242 @@
+package freqtrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/database"
+	"brale/internal/gateway/exchange"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+)
+
+const pendingRecoveryMinWait = time.Minute
+
+func (m *Manager) persistPendingExit(tradeID int, requestedAt time.Time) {
+	if m == nil || m.pendingExits == nil || tradeID <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.pendingExits.SavePendingExit(ctx, database.PendingExitRecord{TradeID: tradeID, RequestedAt: requestedAt}); err != nil {
+		logger.Warnf("freqtrade: 持久化平仓等待失败 trade=%d err=%v", tradeID, err)
+	}
+}
+
+func (m *Manager) forgetPendingExit(tradeID int) {
+	if m == nil || m.pendingExits == nil || tradeID <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.pendingExits.DeletePendingExit(ctx, tradeID); err != nil {
+		logger.Warnf("freqtrade: 删除平仓等待记录失败 trade=%d err=%v", tradeID, err)
+	}
+}
+
+// StopPendingTimers 在关闭时停止全部等待计时器，避免退出过程中误触发超时回退；
+// 持久化的平仓等待记录保留，下次启动由 RecoverPendingExits 接管。
+func (m *Manager) StopPendingTimers() int {
+	if m == nil {
+		return 0
+	}
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+	n := 0
+	for id, ps := range m.pending {
+		if ps.timer != nil {
+			ps.timer.Stop()
+		}
+		delete(m.pending, id)
+		n++
+	}
+	return n
+}
+
+// RecoverPendingExits 在恢复监控前调用：合并持久化的平仓等待记录与本地 closing_* 订单，
+// 逐笔向 freqtrade 查询真实状态并对账——已成交的补记 exit_fill，平仓单仍挂着的按剩余时长续等，
+// 其余回退为 open，避免重启丢失 pending 后分段止盈记账错位。
+func (m *Manager) RecoverPendingExits(ctx context.Context) error {
+	if m == nil || m.client == nil || m.posRepo == nil {
+		return nil
+	}
+	requested := make(map[int]time.Time)
+	if m.pendingExits != nil {
+		recs, err := m.pendingExits.ListPendingExits(ctx)
+		if err != nil {
+			return fmt.Errorf("读取平仓等待记录失败: %w", err)
+		}
+		for _, rec := range recs {
+			requested[rec.TradeID] = rec.RequestedAt
+		}
+	}
+	orders, err := m.posRepo.ListActivePositions(ctx, 500)
+	if err != nil {
+		return fmt.Errorf("读取持仓失败: %w", err)
+	}
+	closing := make(map[int]database.LiveOrderRecord)
+	for _, rec := range orders {
+		if rec.Status == database.LiveOrderStatusClosingPartial || rec.Status == database.LiveOrderStatusClosingFull {
+			closing[rec.FreqtradeID] = rec
+		}
+	}
+	for id := range requested {
+		if _, ok := closing[id]; ok {
+			continue
+		}
+		rec, ok, err := m.posStore.GetLivePosition(ctx, id)
+		if err != nil {
+			logger.Warnf("freqtrade: 平仓恢复读取 trade=%d 失败: %v", id, err)
+			continue
+		}
+		if !ok || rec.Status == database.LiveOrderStatusClosed || rec.Status == database.LiveOrderStatusCanceled {
+			m.forgetPendingExit(id)
+			continue
+		}
+		closing[id] = rec
+	}
+	if len(closing) == 0 {
+		return nil
+	}
+	var replayed, rearmed, reverted int
+	for id, rec := range closing {
+		if m.routedToDirect(rec.Symbol) {
+			continue
+		}
+		reqAt, ok := requested[id]
+		if !ok || reqAt.IsZero() {
+			reqAt = rec.UpdatedAt
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, closingWatchdogTimeout)
+		switch m.reconcileClosing(checkCtx, rec, false, m.alertPendingRecovery) {
+		case closingReplayed:
+			replayed++
+		case closingWaiting, closingQueryFailed:
+			// 平仓单仍挂着或 freqtrade 暂不可达：按原超时剩余时长续等，超时后走常规回退。
+			wait := remainingPendingWait(reqAt)
+			m.armPending(id, pendingStageClosing, wait)
+			logger.Infof("freqtrade: 平仓恢复 trade=%d %s 继续等待 %s", id, rec.Symbol, wait.Round(time.Second))
+			rearmed++
+		case closingReverted:
+			reverted++
+		case closingMissing:
+			m.alertPendingRecovery(rec, "freqtrade 中查无此交易，需人工确认")
+		}
+		cancel()
+	}
+	logger.Infof("✓ 平仓等待恢复完成: 共 %d 笔，补记成交 %d，继续等待 %d，回退 open %d",
+		len(closing), replayed, rearmed, reverted)
+	return nil
+}
+
+type closingResult int
+
+const (
+	closingMissing closingResult = iota
+	closingQueryFailed
+	closingReplayed
+	closingWaiting
+	closingReissued
+	closingReverted
+)
+
+// reconcileClosing 以 freqtrade 的真实状态对账一笔 closing_* 订单，供重启恢复与卡单巡检共用：
+// 已平仓/部分平仓 → 补记 exit_fill；平仓单仍挂着 → closingWaiting；
+// 否则 reissue 且为全平时重新下发平仓，其余回退为 open。
+// 查无此交易、查询失败与继续等待不产生副作用也不告警，由调用方处理。
+func (m *Manager) reconcileClosing(ctx context.Context, rec database.LiveOrderRecord, reissue bool, alert func(database.LiveOrderRecord, string)) closingResult {
+	tradeID := rec.FreqtradeID
+	trade, err := m.client.GetOpenTrade(ctx, tradeID)
+	if errors.Is(err, errTradeNotFound) {
+		trade, err = m.client.GetTrade(ctx, tradeID)
+	}
+	if errors.Is(err, errTradeNotFound) || (err == nil && trade == nil) {
+		return closingMissing
+	}
+	if err != nil {
+		logger.Warnf("freqtrade: 平仓对账查询 trade=%d 失败: %v", tradeID, err)
+		return closingQueryFailed
+	}
+
+	localAmt := valOrZero(rec.Amount)
+	isClosed := !trade.IsOpen && strings.TrimSpace(trade.CloseDate) != ""
+	switch {
+	case isClosed:
+		m.replayExitFill(ctx, trade, localAmt, trade.CloseRate, trade.CloseProfitAbs, trade.CloseProfit)
+		alert(rec, fmt.Sprintf("freqtrade 已平仓 @ %.4f，已补记平仓", trade.CloseRate))
+		return closingReplayed
+	case localAmt > 0 && trade.Amount < localAmt-closingAmountEpsilon:
+		m.replayExitFill(ctx, trade, localAmt-trade.Amount, firstNonZero(trade.CloseRate, trade.CurrentRate), 0, 0)
+		alert(rec, fmt.Sprintf("freqtrade 已部分平仓 %.6f → %.6f，已补记成交", localAmt, trade.Amount))
+		return closingReplayed
+	case hasOpenExitOrder(trade):
+		return closingWaiting
+	case reissue && rec.Status == database.LiveOrderStatusClosingFull:
+		if err := m.client.ForceExit(ctx, ForceExitPayload{TradeID: strconv.Itoa(tradeID)}); err != nil {
+			m.updateOrderStatus(tradeID, database.LiveOrderStatusOpen)
+			m.forgetPendingExit(tradeID)
+			alert(rec, fmt.Sprintf("重新平仓失败（已回退为 open）: %v", err))
+			return closingReverted
+		}
+		m.startPending(tradeID, pendingStageClosing)
+		m.updateOrderStatus(tradeID, database.LiveOrderStatusClosingFull)
+		alert(rec, "未收到平仓成交，已重新下发全平")
+		return closingReissued
+	default:
+		m.updateOrderStatus(tradeID, database.LiveOrderStatusOpen)
+		m.forgetPendingExit(tradeID)
+		alert(rec, "freqtrade 无挂单且持仓未变化，已回退为 open")
+		return closingReverted
+	}
+}
+
+// routedToDirect 判断 symbol 是否由直连执行器下单；这类交易不在 freqtrade 中，不参与平仓对账。
+func (m *Manager) routedToDirect(symbol string) bool {
+	router, ok := m.executor.(*exchange.Router)
+	return ok && !router.UsesDefault(symbol)
+}
+
+// hasOpenExitOrder 判断 freqtrade 交易上是否还有未完成的平仓单。
+func hasOpenExitOrder(trade *Trade) bool {
+	if trade == nil {
+		return false
+	}
+	exitSide := exitOrderSide(normalizeTradeSide(trade))
+	for _, ord := range trade.Orders {
+		if !ord.IsOpen {
+			continue
+		}
+		side := strings.ToLower(strings.TrimSpace(ord.FTOrderSide))
+		tag := strings.ToLower(strings.TrimSpace(ord.FTOrderTag))
+		if isExitOrder(side, tag, exitSide) {
+			return true
+		}
+	}
+	return false
+}
+
+// remainingPendingWait 返回原超时的剩余时长，至少 pendingRecoveryMinWait，给挂单留出成交回报时间。
+func remainingPendingWait(requestedAt time.Time) time.Duration {
+	wait := pendingTimeout
+	if !requestedAt.IsZero() {
+		wait -= time.Since(requestedAt)
+	}
+	return max(wait, pendingRecoveryMinWait)
+}
+
+func (m *Manager) alertPendingRecovery(rec database.LiveOrderRecord, action string) {
+	stage := liveOrderStatusText(rec.Status)
+	logger.Warnf("freqtrade: 平仓等待恢复 trade=%d %s status=%s: %s", rec.FreqtradeID, rec.Symbol, stage, action)
+	if m.notifier == nil {
+		return
+	}
+	text := fmt.Sprintf("⚠️ 重启后平仓对账\n%s %s trade=%d\n状态 %s\n%s",
+		rec.Symbol, rec.Side, rec.FreqtradeID, stage, action)
+	if err := notifier.SendCategoryText(m.notifier, notifier.CategoryError, text); err != nil {
+		logger.Warnf("Telegram 推送失败(pending exit recovery): %v", err)
+	}
+}
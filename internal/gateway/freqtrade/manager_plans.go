@@ -72,6 +72,7 @@ func (m *Manager) PublishPlanStateUpdate(ctx context.Context, payload exchange.P
 
 func (m *Manager) CacheDecision(key string, d decision.Decision) string {
 	m.cacheOpenExitPlan(key, d)
+	m.cacheEntryDrift(key, d)
 	return key
 }
 
@@ -61,6 +61,14 @@ func (m *Manager) startPending(tradeID int, stage string) {
 	if tradeID <= 0 {
 		return
 	}
+	m.armPending(tradeID, stage, pendingTimeout)
+	if stage == pendingStageClosing {
+		m.persistPendingExit(tradeID, time.Now())
+	}
+}
+
+// armPending 只设置内存计时器；重启恢复时以剩余时长续等，不改写持久化记录。
+func (m *Manager) armPending(tradeID int, stage string, timeout time.Duration) {
 	m.pendingMu.Lock()
 	defer m.pendingMu.Unlock()
 	if m.pending == nil {
@@ -71,13 +79,16 @@ func (m *Manager) startPending(tradeID int, stage string) {
 			prev.timer.Stop()
 		}
 	}
-	timer := time.AfterFunc(pendingTimeout, func() {
+	timer := time.AfterFunc(timeout, func() {
 		m.handlePendingTimeout(tradeID, stage)
 	})
 	m.pending[tradeID] = &pendingState{stage: stage, timer: timer}
 }
 
 func (m *Manager) clearPending(tradeID int, stage string) {
+	if stage == pendingStageClosing {
+		m.forgetPendingExit(tradeID)
+	}
 	m.pendingMu.Lock()
 	defer m.pendingMu.Unlock()
 	if m.pending == nil {
@@ -91,6 +102,17 @@ func (m *Manager) clearPending(tradeID int, stage string) {
 	}
 }
 
+// PendingStages 返回等待成交回报的交易（trade_id → opening/closing）。
+func (m *Manager) PendingStages() map[int]string {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+	out := make(map[int]string, len(m.pending))
+	for id, ps := range m.pending {
+		out[id] = ps.stage
+	}
+	return out
+}
+
 func (m *Manager) handlePendingTimeout(tradeID int, stage string) {
 	switch stage {
 	case pendingStageOpening:
@@ -99,6 +121,7 @@ func (m *Manager) handlePendingTimeout(tradeID int, stage string) {
 	case pendingStageClosing:
 		logger.Warnf("freqtrade: 平仓超时 trade=%d，回退状态为 open", tradeID)
 		m.updateOrderStatus(tradeID, database.LiveOrderStatusOpen)
+		m.forgetPendingExit(tradeID)
 	default:
 	}
 	m.pendingMu.Lock()
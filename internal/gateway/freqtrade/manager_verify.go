@@ -0,0 +1,62 @@
+package freqtrade
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"brale/internal/gateway/exchange"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	"brale/internal/trader"
+)
+
+const (
+	fillVerifyDelay     = 3 * time.Second
+	fillVerifyTolerance = 0.005
+)
+
+// SetPositionVerifier 设置交易所持仓核对源；设置后 freqtrade 的成交回报会与交易所实际持仓比对。
+func (m *Manager) SetPositionVerifier(v exchange.PositionVerifier) {
+	if m == nil {
+		return
+	}
+	m.verifier = v
+}
+
+// verifyFillAsync 在成交回报后延迟读取交易所持仓：开仓比对成交后数量，平仓比对剩余数量。
+func (m *Manager) verifyFillAsync(msg exchange.WebhookMessage, payload any) {
+	if m == nil || m.verifier == nil {
+		return
+	}
+	var expected float64
+	switch p := payload.(type) {
+	case trader.PositionOpenedPayload:
+		expected = p.Amount
+	case trader.PositionClosedPayload:
+		expected = p.RemainingAmount
+	default:
+		return
+	}
+	go func() {
+		time.Sleep(fillVerifyDelay)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		actual, err := m.verifier.VerifyPosition(ctx, msg.Pair, msg.Direction)
+		if err != nil {
+			logger.Warnf("freqtrade: 成交核对失败 trade=%d %s: %v", msg.TradeID, msg.Pair, err)
+			return
+		}
+		if math.Abs(actual-expected) <= math.Max(expected*fillVerifyTolerance, 1e-9) {
+			logger.Debugf("freqtrade: 成交核对一致 trade=%d %s amount=%.6f", msg.TradeID, msg.Pair, actual)
+			return
+		}
+		text := fmt.Sprintf("⚠️ 成交数量不一致\n%s %s trade=%d (%s)\nfreqtrade=%.6f 交易所=%.6f",
+			msg.Pair, msg.Direction, msg.TradeID, msg.Type, expected, actual)
+		logger.Warnf("freqtrade: 成交数量不一致 trade=%d %s %s freqtrade=%.6f exchange=%.6f", msg.TradeID, msg.Pair, msg.Type, expected, actual)
+		if m.notifier != nil {
+			_ = notifier.SendCategoryText(m.notifier, notifier.CategoryError, text)
+		}
+	}()
+}
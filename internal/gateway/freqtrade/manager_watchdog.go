@@ -0,0 +1,141 @@
+package freqtrade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/database"
+	"brale/internal/gateway/exchange"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+)
+
+const (
+	closingWatchdogInterval = time.Minute
+	closingWatchdogTimeout  = 30 * time.Second
+	closingAmountEpsilon    = 1e-9
+)
+
+// StartClosingWatchdog 周期扫描长时间停留在 closing_partial/closing_full 且未收到 exit_fill 的订单，
+// 以 freqtrade 的真实状态修正本地记录（必要时重新下发平仓）并告警。
+// 内存中的 pending 计时器在重启后会丢失，这里以数据库状态为准兜底。
+func (m *Manager) StartClosingWatchdog(ctx context.Context) {
+	if m == nil || m.client == nil || m.posRepo == nil || m.cfg.StuckClosingMinutes <= 0 {
+		return
+	}
+	stuckAfter := time.Duration(m.cfg.StuckClosingMinutes) * time.Minute
+	logger.Infof("✓ 平仓卡单巡检已启用 timeout=%s reissue=%v", stuckAfter, m.cfg.ReissueStuckExit)
+	go func() {
+		ticker := time.NewTicker(closingWatchdogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.sweepStuckClosing(ctx, stuckAfter)
+			}
+		}
+	}()
+}
+
+func (m *Manager) sweepStuckClosing(ctx context.Context, stuckAfter time.Duration) {
+	orders, err := m.posRepo.ListActivePositions(ctx, 500)
+	if err != nil {
+		logger.Warnf("freqtrade: 平仓巡检读取持仓失败: %v", err)
+		return
+	}
+	cutoff := time.Now().Add(-stuckAfter)
+	stuck := make(map[int]struct{})
+	for _, rec := range orders {
+		if rec.Status != database.LiveOrderStatusClosingPartial && rec.Status != database.LiveOrderStatusClosingFull {
+			continue
+		}
+		if rec.UpdatedAt.After(cutoff) || m.hasPending(rec.FreqtradeID) || m.routedToDirect(rec.Symbol) {
+			continue
+		}
+		stuck[rec.FreqtradeID] = struct{}{}
+		checkCtx, cancel := context.WithTimeout(ctx, closingWatchdogTimeout)
+		m.resolveStuckClosing(checkCtx, rec)
+		cancel()
+	}
+	for id := range m.stuckAlerts {
+		if _, ok := stuck[id]; !ok {
+			delete(m.stuckAlerts, id)
+		}
+	}
+}
+
+func (m *Manager) hasPending(tradeID int) bool {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+	_, ok := m.pending[tradeID]
+	return ok
+}
+
+// resolveStuckClosing 以 reconcileClosing 对账卡单；查无此交易与平仓单仍挂着时保持 closing 状态，
+// 同一交易的同类告警只发一次，直到状态变化或离开卡单列表。
+func (m *Manager) resolveStuckClosing(ctx context.Context, rec database.LiveOrderRecord) {
+	stage := liveOrderStatusText(rec.Status)
+	alert := func(rec database.LiveOrderRecord, action string) {
+		m.alertStuckClosing(rec, stage, action)
+	}
+	result := m.reconcileClosing(ctx, rec, m.cfg.ReissueStuckExit, alert)
+	switch result {
+	case closingMissing, closingWaiting:
+		if prev, ok := m.stuckAlerts[rec.FreqtradeID]; ok && prev == result {
+			return
+		}
+		if m.stuckAlerts == nil {
+			m.stuckAlerts = make(map[int]closingResult)
+		}
+		m.stuckAlerts[rec.FreqtradeID] = result
+		if result == closingMissing {
+			alert(rec, "freqtrade 中查无此交易，需人工确认")
+		} else {
+			alert(rec, "平仓单仍在 freqtrade 挂单中，继续等待成交")
+		}
+	case closingQueryFailed:
+		// freqtrade 暂不可达，下一轮巡检重试。
+	default:
+		delete(m.stuckAlerts, rec.FreqtradeID)
+	}
+}
+
+// replayExitFill 以 freqtrade 的交易数据构造 exit_fill，走与 webhook 相同的链路更新 trader 状态与策略。
+func (m *Manager) replayExitFill(ctx context.Context, trade *Trade, amount, rate, profitAbs, profitRatio float64) {
+	closeDate := strings.TrimSpace(trade.CloseDate)
+	if closeDate == "" {
+		closeDate = time.Now().UTC().Format(time.RFC3339)
+	}
+	m.clearPending(trade.ID, pendingStageClosing)
+	m.handleWebhook(ctx, exchange.WebhookMessage{
+		Type:        "exit_fill",
+		TradeID:     int64(trade.ID),
+		Pair:        trade.Pair,
+		Direction:   normalizeTradeSide(trade),
+		Amount:      amount,
+		StakeAmount: trade.StakeAmount,
+		CloseRate:   rate,
+		CloseDate:   closeDate,
+		ProfitAbs:   profitAbs,
+		ProfitRatio: profitRatio,
+		ExitReason:  "watchdog_reconcile",
+		Leverage:    int(trade.Leverage),
+	}, false)
+}
+
+func (m *Manager) alertStuckClosing(rec database.LiveOrderRecord, stage, action string) {
+	since := time.Since(rec.UpdatedAt).Round(time.Minute)
+	logger.Warnf("freqtrade: 平仓卡单 trade=%d %s status=%s 持续 %s: %s", rec.FreqtradeID, rec.Symbol, stage, since, action)
+	if m.notifier == nil {
+		return
+	}
+	text := fmt.Sprintf("⚠️ 平仓卡单巡检\n%s %s trade=%d\n状态 %s 已持续 %s\n%s",
+		rec.Symbol, rec.Side, rec.FreqtradeID, stage, since, action)
+	if err := notifier.SendCategoryText(m.notifier, notifier.CategoryError, text); err != nil {
+		logger.Warnf("Telegram 推送失败(closing watchdog): %v", err)
+	}
+}
@@ -8,12 +8,32 @@ import (
 	"time"
 
 	"brale/internal/gateway/exchange"
+	"brale/internal/gateway/webhook"
 	"brale/internal/logger"
 	"brale/internal/pkg/convert"
 	"brale/internal/trader"
 )
 
 func (m *Manager) HandleWebhook(ctx context.Context, msg exchange.WebhookMessage) {
+	m.handleWebhook(ctx, msg, true)
+}
+
+// HandleExchangeFill 处理直连执行器 user-data stream 推送的成交；消息本身来自交易所，无需再核对。
+func (m *Manager) HandleExchangeFill(ctx context.Context, msg exchange.WebhookMessage) {
+	m.handleWebhook(context.WithValue(ctx, exchangeFillKey{}, true), msg, false)
+}
+
+type exchangeFillKey struct{}
+
+func isExchangeFill(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(exchangeFillKey{}).(bool)
+	return v
+}
+
+func (m *Manager) handleWebhook(ctx context.Context, msg exchange.WebhookMessage, verify bool) {
 	logger.Debugf("Freqtrade Webhook received: %s trade=%d", msg.Type, msg.TradeID)
 
 	if m.trader == nil {
@@ -41,6 +61,10 @@ func (m *Manager) HandleWebhook(ctx context.Context, msg exchange.WebhookMessage
 		Symbol:    strings.ToUpper(strings.TrimSpace(msg.Pair)),
 	})
 	evt.afterSend()
+	m.publishFill(msg)
+	if verify {
+		m.verifyFillAsync(msg, evt.payload)
+	}
 }
 
 type webhookEvent struct {
@@ -105,7 +129,8 @@ func (m *Manager) buildEntryFillEvent(ctx context.Context, msg exchange.WebhookM
 		evtType: trader.EvtPositionOpened,
 		payload: openedPayload,
 		afterSend: func() {
-			m.reconcileAfterDelay(tradeID)
+			m.reconcileAfterDelay(ctx, tradeID)
+			m.recordEntryDrift(ctx, tradeID, msg.Pair, float64(msg.OpenRate))
 			m.initExitPlanOnEntryFill(ctx, tradeID, msg.Pair, float64(msg.OpenRate))
 			if m.notifier != nil {
 				go m.sendEntryFillNotification(ctx, msg, openedPayload)
@@ -129,7 +154,7 @@ func (m *Manager) buildExitRequestEvent(msg exchange.WebhookMessage, tradeID int
 	return webhookEvent{
 		evtType:   trader.EvtPositionClosing,
 		payload:   payload,
-		afterSend: func() { m.reconcileAfterDelay(tradeID) },
+		afterSend: func() { m.reconcileAfterDelay(context.Background(), tradeID) },
 	}
 }
 
@@ -156,12 +181,13 @@ func (m *Manager) buildExitFillEvent(ctx context.Context, msg exchange.WebhookMe
 		Reason:          reason,
 		PnL:             profitAbs,
 		PnLPct:          profitRatio,
+		Fee:             msg.Fee,
 		ClosedAt:        closedAt,
 	}
 	m.clearPending(tradeID, pendingStageClosing)
 
 	afterSend := func() {
-		m.reconcileAfterDelay(tradeID)
+		m.reconcileAfterDelay(ctx, tradeID)
 		m.finalizeStrategiesOnExit(ctx, msg, closedPayload)
 		if closedPayload.Amount > 0 && m.notifier != nil {
 			go m.sendExitFillNotification(ctx, msg, closedPayload)
@@ -170,8 +196,9 @@ func (m *Manager) buildExitFillEvent(ctx context.Context, msg exchange.WebhookMe
 	return webhookEvent{evtType: trader.EvtPositionClosed, payload: closedPayload, afterSend: afterSend}
 }
 
-func (m *Manager) reconcileAfterDelay(tradeID int) {
-	if tradeID <= 0 {
+func (m *Manager) reconcileAfterDelay(ctx context.Context, tradeID int) {
+	// 直连执行器的成交不在 freqtrade 中，无法按 trade id 对账。
+	if tradeID <= 0 || isExchangeFill(ctx) {
 		return
 	}
 	m.reconcileTradeAsyncWithDelay(tradeID, reconcileDelay)
@@ -196,3 +223,30 @@ func (m *Manager) finalizeStrategiesOnExit(ctx context.Context, msg exchange.Web
 		m.planUpdateHook.NotifyPlanUpdated(context.Background(), int(msg.TradeID))
 	}
 }
+
+// SetWebhooks 设置对外信号 webhook；开仓成交后推送 entry_fill 事件。
+func (m *Manager) SetWebhooks(d *webhook.Dispatcher) {
+	if m == nil {
+		return
+	}
+	m.webhooks = d
+}
+
+func (m *Manager) publishFill(msg exchange.WebhookMessage) {
+	if m.webhooks == nil || strings.ToLower(strings.TrimSpace(msg.Type)) != "entry_fill" {
+		return
+	}
+	m.webhooks.Publish(webhook.Event{
+		Type:    webhook.EventEntryFill,
+		Symbol:  strings.ToUpper(strings.TrimSpace(msg.Pair)),
+		TradeID: int(msg.TradeID),
+		Data: map[string]any{
+			"side":         msg.Direction,
+			"amount":       msg.Amount,
+			"open_rate":    msg.OpenRate,
+			"stake_amount": msg.StakeAmount,
+			"leverage":     msg.Leverage,
+			"open_date":    msg.OpenDate,
+		},
+	})
+}
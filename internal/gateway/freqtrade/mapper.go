@@ -75,6 +75,9 @@ func toOrderModel(rec database.LiveOrderRecord) *model.LiveOrderModel {
 		UnrealizedUSD:     deref(rec.UnrealizedPnLUSD),
 		RealizedRatio:     deref(rec.RealizedPnLRatio),
 		RealizedUSD:       deref(rec.RealizedPnLUSD),
+		FeeUSD:            deref(rec.FeeUSD),
+		FundingUSD:        deref(rec.FundingUSD),
+		GrossRealizedUSD:  deref(rec.GrossRealizedPnLUSD),
 		LastStatusSync:    derefUnixMillis(rec.LastStatusSync),
 	}
 }
@@ -102,33 +105,36 @@ func fromOrderModel(m *model.LiveOrderModel) database.LiveOrderRecord {
 	}
 
 	return database.LiveOrderRecord{
-		FreqtradeID:        m.FreqtradeID,
-		Symbol:             m.Symbol,
-		Side:               m.Side,
-		Amount:             &m.Amount,
-		InitialAmount:      &m.InitialAmount,
-		StakeAmount:        &m.StakeAmount,
-		Leverage:           &m.Leverage,
-		PositionValue:      &m.PositionValue,
-		Price:              &m.Price,
-		ClosedAmount:       &m.ClosedAmount,
-		IsSimulated:        &isSimulated,
-		Status:             database.LiveOrderStatus(m.Status),
-		StartTime:          startPtr,
-		EndTime:            endTime,
-		RawData:            m.RawData,
-		CreatedAt:          timeFromUnixMillis(m.CreatedAtUnix),
-		UpdatedAt:          timeFromUnixMillis(m.UpdatedAtUnix),
-		PnLRatio:           &m.PnLRatio,
-		PnLUSD:             &m.PnLUSD,
-		CurrentPrice:       &m.CurrentPrice,
-		CurrentProfitRatio: &m.CurrentProfitRate,
-		CurrentProfitAbs:   &m.CurrentProfitAbs,
-		UnrealizedPnLRatio: &m.UnrealizedRatio,
-		UnrealizedPnLUSD:   &m.UnrealizedUSD,
-		RealizedPnLRatio:   &m.RealizedRatio,
-		RealizedPnLUSD:     &m.RealizedUSD,
-		LastStatusSync:     lastSync,
+		FreqtradeID:         m.FreqtradeID,
+		Symbol:              m.Symbol,
+		Side:                m.Side,
+		Amount:              &m.Amount,
+		InitialAmount:       &m.InitialAmount,
+		StakeAmount:         &m.StakeAmount,
+		Leverage:            &m.Leverage,
+		PositionValue:       &m.PositionValue,
+		Price:               &m.Price,
+		ClosedAmount:        &m.ClosedAmount,
+		IsSimulated:         &isSimulated,
+		Status:              database.LiveOrderStatus(m.Status),
+		StartTime:           startPtr,
+		EndTime:             endTime,
+		RawData:             m.RawData,
+		CreatedAt:           timeFromUnixMillis(m.CreatedAtUnix),
+		UpdatedAt:           timeFromUnixMillis(m.UpdatedAtUnix),
+		PnLRatio:            &m.PnLRatio,
+		PnLUSD:              &m.PnLUSD,
+		CurrentPrice:        &m.CurrentPrice,
+		CurrentProfitRatio:  &m.CurrentProfitRate,
+		CurrentProfitAbs:    &m.CurrentProfitAbs,
+		UnrealizedPnLRatio:  &m.UnrealizedRatio,
+		UnrealizedPnLUSD:    &m.UnrealizedUSD,
+		RealizedPnLRatio:    &m.RealizedRatio,
+		RealizedPnLUSD:      &m.RealizedUSD,
+		FeeUSD:              &m.FeeUSD,
+		FundingUSD:          &m.FundingUSD,
+		GrossRealizedPnLUSD: &m.GrossRealizedUSD,
+		LastStatusSync:      lastSync,
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"brale/internal/decision"
 	"brale/internal/gateway/database"
+	"brale/internal/gateway/exchange"
 	"brale/internal/logger"
 	symbolpkg "brale/internal/pkg/symbol"
 	"brale/internal/strategy/exit"
@@ -211,6 +212,34 @@ func (m *Manager) initExitPlanOnEntryFill(ctx context.Context, tradeID int, symb
 	if m.planUpdateHook != nil {
 		m.planUpdateHook.NotifyPlanUpdated(baseCtx, tradeID)
 	}
+	m.registerScaleIn(baseCtx, tradeID, keySymbol, entry, args.EntryPrice)
+}
+
+// registerScaleIn 把开仓决策中的加仓价位交给持仓监控（需实现 exchange.ScaleInHook）。
+func (m *Manager) registerScaleIn(ctx context.Context, tradeID int, symbol string, entry cachedOpenPlan, entryPrice float64) {
+	levels := entry.Decision.ScaleIn
+	if len(levels) == 0 || entryPrice <= 0 {
+		return
+	}
+	hook, ok := m.planUpdateHook.(exchange.ScaleInHook)
+	if !ok {
+		logger.Warnf("initExitPlanOnEntryFill: 未注册加仓监控，忽略 scale_in trade=%d symbol=%s", tradeID, symbol)
+		return
+	}
+	reg := exchange.ScaleInRegistration{
+		TradeID:    tradeID,
+		Symbol:     symbol,
+		Side:       strings.ToLower(strings.TrimSpace(entry.Side)),
+		EntryPrice: entryPrice,
+		Stake:      entry.Decision.PositionSizeUSD,
+		Leverage:   float64(entry.Decision.Leverage),
+		TraceID:    entry.TraceID,
+		Levels:     make([]exchange.ScaleInLevel, 0, len(levels)),
+	}
+	for _, lv := range levels {
+		reg.Levels = append(reg.Levels, exchange.ScaleInLevel{Price: lv.Price, ATROffset: lv.ATROffset, SizeUSD: lv.SizeUSD})
+	}
+	hook.RegisterScaleIn(ctx, reg)
 }
 
 func (m *Manager) exitPlanAlreadyInitialized(ctx context.Context, tradeID int) bool {
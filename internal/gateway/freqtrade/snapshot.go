@@ -198,6 +198,8 @@ func normalizeTradeOperation(rec database.TradeOperationRecord) database.Operati
 		return database.OperationStopLoss
 	case exit.PlanEventTypeFinalStopLoss:
 		return database.OperationFinalStop
+	case exit.PlanEventTypeTimeExit:
+		return database.OperationTimeExit
 	default:
 		if op == database.OperationTakeProfit && isStopLossEvent(mode, component, alias) {
 			return database.OperationStopLoss
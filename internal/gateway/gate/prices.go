@@ -0,0 +1,55 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	symbolpkg "brale/internal/pkg/symbol"
+
+	"github.com/antihax/optional"
+	gateapi "github.com/gateio/gateapi-go/v7"
+)
+
+func (s *Source) IndexPrice(ctx context.Context, sym string) (float64, error) {
+	ticker, err := s.fetchTicker(ctx, sym)
+	if err != nil {
+		return 0, err
+	}
+	if price := parseFloat(ticker.IndexPrice); price > 0 {
+		return price, nil
+	}
+	return 0, fmt.Errorf("index price not available for %s", sym)
+}
+
+func (s *Source) LastPrice(ctx context.Context, sym string) (float64, error) {
+	ticker, err := s.fetchTicker(ctx, sym)
+	if err != nil {
+		return 0, err
+	}
+	if price := parseFloat(ticker.Last); price > 0 {
+		return price, nil
+	}
+	return 0, fmt.Errorf("last price not available for %s", sym)
+}
+
+func (s *Source) fetchTicker(ctx context.Context, sym string) (gateapi.FuturesTicker, error) {
+	if s == nil || s.rest == nil {
+		return gateapi.FuturesTicker{}, fmt.Errorf("gate source not initialized")
+	}
+	contract := symbolpkg.Gate.ToExchange(sym)
+	if strings.TrimSpace(contract) == "" {
+		return gateapi.FuturesTicker{}, fmt.Errorf("invalid symbol: %s", sym)
+	}
+	opts := &gateapi.ListFuturesTickersOpts{Contract: optional.NewString(contract)}
+	tickers, _, err := s.rest.FuturesApi.ListFuturesTickers(ctx, gateSettle, opts)
+	if err != nil {
+		return gateapi.FuturesTicker{}, err
+	}
+	for _, t := range tickers {
+		if strings.EqualFold(t.Contract, contract) {
+			return t, nil
+		}
+	}
+	return gateapi.FuturesTicker{}, fmt.Errorf("ticker not available for %s", sym)
+}
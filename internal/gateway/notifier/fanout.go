@@ -0,0 +1,69 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"brale/internal/logger"
+)
+
+// Fanout 把消息同时发给主通道（Telegram）与订阅了该类别的推送通道。
+// 主通道同步发送并返回其错误；推送通道异步投递，失败只记日志，避免外部 webhook 拖慢交易流程。
+type Fanout struct {
+	primary Notifier
+	sinks   []*WebhookSink
+}
+
+// NewFanout 中 primary 可为 nil（仅使用推送通道）。
+func NewFanout(primary Notifier, sinks []*WebhookSink) *Fanout {
+	return &Fanout{primary: primary, sinks: sinks}
+}
+
+var _ Notifier = (*Fanout)(nil)
+var _ CategoryNotifier = (*Fanout)(nil)
+
+func (f *Fanout) SendText(text string) error {
+	return f.SendCategory(CategoryGeneral, StructuredMessage{Text: text})
+}
+
+func (f *Fanout) SendStructured(msg StructuredMessage) error {
+	return f.SendCategory(CategoryGeneral, msg)
+}
+
+func (f *Fanout) SendCategory(category string, msg StructuredMessage) error {
+	if f == nil {
+		return nil
+	}
+	if category == "" {
+		category = CategoryGeneral
+	}
+	text := msg.RenderMarkdown()
+	ts := msg.Timestamp
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+	out := SinkMessage{
+		Category:  category,
+		Icon:      msg.Icon,
+		Title:     msg.Title,
+		Text:      text,
+		Sections:  msg.Sections,
+		Timestamp: ts,
+	}
+	for _, sink := range f.sinks {
+		if !sink.Accepts(category) {
+			continue
+		}
+		go func(sink *WebhookSink) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := sink.Send(ctx, out); err != nil {
+				logger.Warnf("推送通道 %s 发送失败(%s): %v", sink.Name(), category, err)
+			}
+		}(sink)
+	}
+	if f.primary == nil {
+		return nil
+	}
+	return f.primary.SendText(text)
+}
@@ -3,3 +3,49 @@ package notifier
 type TextNotifier interface {
 	SendText(text string) error
 }
+
+// Notifier 同时支持纯文本与结构化消息，Telegram 与 Fanout 均实现该接口。
+type Notifier interface {
+	TextNotifier
+	SendStructured(msg StructuredMessage) error
+}
+
+// CategoryNotifier 按事件类别发送，Fanout 据此把消息路由到订阅了该类别的推送通道。
+type CategoryNotifier interface {
+	SendCategory(category string, msg StructuredMessage) error
+}
+
+// 事件类别：open/close/tier 为成交，error 为异常告警，ws_status 为行情连接状态，其余消息归为 general。
+const (
+	CategoryOpen     = "open"
+	CategoryClose    = "close"
+	CategoryTier     = "tier"
+	CategoryError    = "error"
+	CategoryWSStatus = "ws_status"
+	CategoryGeneral  = "general"
+)
+
+// Categories 为可在推送通道上订阅的事件类别。
+var Categories = []string{CategoryOpen, CategoryClose, CategoryTier, CategoryError, CategoryWSStatus, CategoryGeneral}
+
+// SendCategory 在 n 支持类别路由时按类别发送，否则退化为发送渲染后的文本。
+func SendCategory(n TextNotifier, category string, msg StructuredMessage) error {
+	if n == nil {
+		return nil
+	}
+	if cn, ok := n.(CategoryNotifier); ok {
+		return cn.SendCategory(category, msg)
+	}
+	return n.SendText(msg.RenderMarkdown())
+}
+
+// SendCategoryText 与 SendCategory 相同，用于只有一段文本的消息。
+func SendCategoryText(n TextNotifier, category, text string) error {
+	if n == nil {
+		return nil
+	}
+	if cn, ok := n.(CategoryNotifier); ok {
+		return cn.SendCategory(category, StructuredMessage{Text: text})
+	}
+	return n.SendText(text)
+}
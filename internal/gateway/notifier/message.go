@@ -8,19 +8,24 @@ import (
 const maxStructuredMessageLen = 3800
 
 type MessageSection struct {
-	Title string
-	Lines []string
+	Title string   `json:"title"`
+	Lines []string `json:"lines"`
 }
 
+// StructuredMessage 的 Text 为已排版好的纯文本消息（经 SendCategoryText 发送），非空时 RenderMarkdown 直接返回它。
 type StructuredMessage struct {
 	Icon      string
 	Title     string
 	Sections  []MessageSection
 	Footer    string
 	Timestamp time.Time
+	Text      string
 }
 
 func (m StructuredMessage) RenderMarkdown() string {
+	if m.Text != "" {
+		return m.Text
+	}
 	var b strings.Builder
 	header := strings.TrimSpace(strings.TrimSpace(m.Icon + " " + m.Title))
 	if header != "" {
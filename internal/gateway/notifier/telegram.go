@@ -38,11 +38,16 @@ func (t *Telegram) sendMessage(payload map[string]any) (int, string, error) {
 }
 
 func (t *Telegram) SendText(text string) error {
-	if t.BotToken == "" || t.ChatID == "" {
+	return t.SendTextTo(t.ChatID, text)
+}
+
+// SendTextTo 向指定 chat 发送消息（命令回复等），Markdown 解析失败时回退为纯文本。
+func (t *Telegram) SendTextTo(chatID, text string) error {
+	if t.BotToken == "" || chatID == "" {
 		return fmt.Errorf("Telegram 配置不完整")
 	}
 	payload := map[string]any{
-		"chat_id":    t.ChatID,
+		"chat_id":    chatID,
 		"text":       text,
 		"parse_mode": "Markdown",
 	}
@@ -61,7 +66,7 @@ func (t *Telegram) SendText(text string) error {
 
 		if status == http.StatusBadRequest && strings.Contains(desc, "can't parse entities") {
 			fallback := map[string]any{
-				"chat_id": t.ChatID,
+				"chat_id": chatID,
 				"text":    text,
 			}
 			if status2, desc2, err2 := t.sendMessage(fallback); err2 == nil && status2/100 == 2 {
@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TelegramUpdate 为 getUpdates 返回的单条更新，仅保留命令处理需要的消息字段。
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message,omitempty"`
+}
+
+type TelegramMessage struct {
+	MessageID int64 `json:"message_id"`
+	Chat      struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	} `json:"chat"`
+	From *struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"from,omitempty"`
+	Text string `json:"text"`
+}
+
+// GetUpdates 以长轮询方式拉取 offset 之后的更新；timeout 为服务端挂起等待的时长。
+func (t *Telegram) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]TelegramUpdate, error) {
+	if t == nil || t.BotToken == "" {
+		return nil, fmt.Errorf("telegram client not initialized")
+	}
+	q := url.Values{}
+	q.Set("offset", strconv.FormatInt(offset, 10))
+	q.Set("timeout", strconv.Itoa(int(timeout.Seconds())))
+	q.Set("allowed_updates", `["message"]`)
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?%s", t.BotToken, q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	// 长轮询需要比服务端挂起时间更长的客户端超时，不能复用发送消息的 Client。
+	client := &http.Client{Timeout: timeout + 15*time.Second}
+	if t.Client != nil && t.Client.Transport != nil {
+		client.Transport = t.Client.Transport
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []TelegramUpdate `json:"result"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("telegram getUpdates status=%d: %w", resp.StatusCode, err)
+	}
+	if !out.OK {
+		return nil, fmt.Errorf("telegram getUpdates status=%d body=%s", resp.StatusCode, out.Description)
+	}
+	return out.Result, nil
+}
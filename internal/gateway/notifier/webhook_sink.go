@@ -0,0 +1,171 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 推送通道类型：discord/slack 为各自的 incoming webhook，generic 为通用 JSON POST。
+const (
+	SinkDiscord = "discord"
+	SinkSlack   = "slack"
+	SinkGeneric = "generic"
+)
+
+// Discord 单条消息上限 2000 字符，Slack 文本超过约 4000 字符会被截断。
+const (
+	maxDiscordContentLen = 2000
+	maxSlackTextLen      = 3900
+)
+
+// SinkMessage 为模板渲染与 generic 通道的数据：Text 为默认渲染的消息正文。
+type SinkMessage struct {
+	Category  string           `json:"category"`
+	Icon      string           `json:"icon,omitempty"`
+	Title     string           `json:"title,omitempty"`
+	Text      string           `json:"text"`
+	Sections  []MessageSection `json:"sections,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// SinkConfig 为单个推送通道；Categories 为空表示接收全部类别，Templates 按类别覆盖正文（"default" 为兜底）。
+type SinkConfig struct {
+	Name       string
+	Kind       string
+	URL        string
+	Categories []string
+	Templates  map[string]string
+	Headers    map[string]string
+	Timeout    time.Duration
+}
+
+// WebhookSink 把消息按通道类型封装后 POST 到 URL。
+type WebhookSink struct {
+	name       string
+	kind       string
+	url        string
+	categories map[string]bool
+	templates  map[string]*template.Template
+	headers    map[string]string
+	client     *http.Client
+}
+
+func NewWebhookSink(cfg SinkConfig) (*WebhookSink, error) {
+	kind := strings.ToLower(strings.TrimSpace(cfg.Kind))
+	switch kind {
+	case SinkDiscord, SinkSlack, SinkGeneric:
+	default:
+		return nil, fmt.Errorf("未知推送通道类型: %s", cfg.Kind)
+	}
+	url := strings.TrimSpace(cfg.URL)
+	if url == "" {
+		return nil, fmt.Errorf("推送通道 %s 缺少 url", cfg.Name)
+	}
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = kind
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	s := &WebhookSink{
+		name:      name,
+		kind:      kind,
+		url:       url,
+		templates: make(map[string]*template.Template, len(cfg.Templates)),
+		headers:   cfg.Headers,
+		client:    &http.Client{Timeout: timeout},
+	}
+	if len(cfg.Categories) > 0 {
+		s.categories = make(map[string]bool, len(cfg.Categories))
+		for _, c := range cfg.Categories {
+			s.categories[strings.ToLower(strings.TrimSpace(c))] = true
+		}
+	}
+	for category, text := range cfg.Templates {
+		category = strings.ToLower(strings.TrimSpace(category))
+		tpl, err := template.New(name + "_" + category).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("推送通道 %s 模板 %s 解析失败: %w", name, category, err)
+		}
+		s.templates[category] = tpl
+	}
+	return s, nil
+}
+
+func (s *WebhookSink) Name() string { return s.name }
+
+func (s *WebhookSink) Accepts(category string) bool {
+	return len(s.categories) == 0 || s.categories[category]
+}
+
+// Send 渲染正文并按通道类型投递，非 2xx 视为失败。
+func (s *WebhookSink) Send(ctx context.Context, msg SinkMessage) error {
+	text, err := s.render(msg)
+	if err != nil {
+		return err
+	}
+	msg.Text = text
+	var payload any
+	switch s.kind {
+	case SinkDiscord:
+		payload = map[string]any{"content": truncateText(text, maxDiscordContentLen)}
+	case SinkSlack:
+		payload = map[string]any{"text": truncateText(text, maxSlackTextLen)}
+	default:
+		payload = msg
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4*1024))
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s status=%d body=%s", s.name, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+func (s *WebhookSink) render(msg SinkMessage) (string, error) {
+	tpl := s.templates[msg.Category]
+	if tpl == nil {
+		tpl = s.templates["default"]
+	}
+	if tpl == nil {
+		return msg.Text, nil
+	}
+	var b strings.Builder
+	if err := tpl.Execute(&b, msg); err != nil {
+		return "", fmt.Errorf("推送通道 %s 模板渲染失败: %w", s.name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-3]) + "..."
+}
@@ -0,0 +1,40 @@
+package okx
+
+import (
+	"strings"
+	"time"
+)
+
+type Config struct {
+	RESTBaseURL   string
+	WSPublicURL   string
+	WSBusinessURL string
+	HTTPTimeout   time.Duration
+
+	ProxyEnabled bool
+	RESTProxyURL string
+	WSProxyURL   string
+}
+
+func (c *Config) withDefaults() Config {
+	out := *c
+	out.RESTBaseURL = strings.TrimRight(strings.TrimSpace(out.RESTBaseURL), "/")
+	if out.RESTBaseURL == "" {
+		out.RESTBaseURL = "https://www.okx.com"
+	}
+	out.WSPublicURL = strings.TrimSpace(out.WSPublicURL)
+	if out.WSPublicURL == "" {
+		out.WSPublicURL = "wss://ws.okx.com:8443/ws/v5/public"
+	}
+	// K 线频道在 OKX v5 中只在 business 端点提供。
+	out.WSBusinessURL = strings.TrimSpace(out.WSBusinessURL)
+	if out.WSBusinessURL == "" {
+		out.WSBusinessURL = "wss://ws.okx.com:8443/ws/v5/business"
+	}
+	if out.HTTPTimeout <= 0 {
+		out.HTTPTimeout = 15 * time.Second
+	}
+	out.RESTProxyURL = strings.TrimSpace(out.RESTProxyURL)
+	out.WSProxyURL = strings.TrimSpace(out.WSProxyURL)
+	return out
+}
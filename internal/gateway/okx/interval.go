@@ -0,0 +1,42 @@
+package okx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// toBar 把内部周期（1m/1h/4h/1d...）映射为 OKX bar 参数。
+// OKX 默认按 UTC+8 切分 6h 及以上的 K 线，这里统一使用 utc 变体，与 Binance 的 UTC 对齐保持一致。
+func toBar(interval string) (string, error) {
+	iv := strings.ToLower(strings.TrimSpace(interval))
+	if len(iv) < 2 {
+		return "", fmt.Errorf("okx: invalid interval %q", interval)
+	}
+	num, unit := iv[:len(iv)-1], iv[len(iv)-1]
+	switch unit {
+	case 'm':
+		return num + "m", nil
+	case 'h':
+		switch num {
+		case "1", "2", "4":
+			return num + "H", nil
+		case "6", "12":
+			return num + "Hutc", nil
+		}
+	case 'd':
+		switch num {
+		case "1", "2", "3":
+			return num + "Dutc", nil
+		}
+	case 'w':
+		if num == "1" {
+			return "1Wutc", nil
+		}
+	}
+	return "", fmt.Errorf("okx: unsupported interval %q", interval)
+}
+
+// fromBar 把 OKX bar（或 candle 频道后缀）还原为内部周期。
+func fromBar(bar string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(bar), "utc"))
+}
@@ -0,0 +1,89 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+const (
+	fundingRatePath  = "/api/v5/public/funding-rate"
+	openInterestPath = "/api/v5/rubik/stat/contracts/open-interest-history"
+	oiHistoryLimit   = 100
+)
+
+var supportedOIPeriods = []string{"5m", "15m", "30m", "1h", "2h", "4h", "6h", "12h", "1d"}
+
+func (s *Source) GetFundingRate(ctx context.Context, sym string) (float64, error) {
+	instID := symbolpkg.OKX.ToExchange(sym)
+	if instID == "" {
+		return 0, fmt.Errorf("invalid symbol: %s", sym)
+	}
+	var data []struct {
+		FundingRate string `json:"fundingRate"`
+	}
+	q := url.Values{}
+	q.Set("instId", instID)
+	if err := s.get(ctx, fundingRatePath, q, &data); err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("funding rate not available for %s", sym)
+	}
+	return parseFloat(data[0].FundingRate), nil
+}
+
+// GetOpenInterestHistory 返回按时间升序的持仓量；SumOpenInterest 取币数量（oiCcy），SumOpenInterestValue 取 USD 价值。
+func (s *Source) GetOpenInterestHistory(ctx context.Context, sym, period string, limit int) ([]market.OpenInterestPoint, error) {
+	if limit <= 0 {
+		limit = 30
+	}
+	if limit > oiHistoryLimit {
+		limit = oiHistoryLimit
+	}
+	instID := symbolpkg.OKX.ToExchange(sym)
+	period = strings.ToLower(strings.TrimSpace(period))
+	if instID == "" || period == "" {
+		return nil, fmt.Errorf("symbol and period are required")
+	}
+	bar, err := toBar(period)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("instId", instID)
+	q.Set("period", bar)
+	q.Set("limit", strconv.Itoa(limit))
+	var rows [][]string
+	if err := s.get(ctx, openInterestPath, q, &rows); err != nil {
+		return nil, err
+	}
+	points := make([]market.OpenInterestPoint, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 4 {
+			continue
+		}
+		ts, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, market.OpenInterestPoint{
+			Symbol:               instID,
+			SumOpenInterest:      parseFloat(row[2]),
+			SumOpenInterestValue: parseFloat(row[3]),
+			Timestamp:            ts,
+		})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp < points[j].Timestamp })
+	return points, nil
+}
+
+func (s *Source) SupportedOIPeriods() []string {
+	return append([]string(nil), supportedOIPeriods...)
+}
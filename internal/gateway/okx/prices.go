@@ -0,0 +1,57 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+const (
+	tickerPath      = "/api/v5/market/ticker"
+	indexTickerPath = "/api/v5/market/index-tickers"
+)
+
+func (s *Source) IndexPrice(ctx context.Context, sym string) (float64, error) {
+	instID := symbolpkg.OKX.ToExchange(sym)
+	if instID == "" {
+		return 0, fmt.Errorf("invalid symbol: %s", sym)
+	}
+	var data []struct {
+		IdxPx string `json:"idxPx"`
+	}
+	q := url.Values{}
+	q.Set("instId", strings.TrimSuffix(instID, "-SWAP"))
+	if err := s.get(ctx, indexTickerPath, q, &data); err != nil {
+		return 0, err
+	}
+	if len(data) > 0 {
+		if price := parseFloat(data[0].IdxPx); price > 0 {
+			return price, nil
+		}
+	}
+	return 0, fmt.Errorf("index price not available for %s", sym)
+}
+
+func (s *Source) LastPrice(ctx context.Context, sym string) (float64, error) {
+	instID := symbolpkg.OKX.ToExchange(sym)
+	if instID == "" {
+		return 0, fmt.Errorf("invalid symbol: %s", sym)
+	}
+	var data []struct {
+		Last string `json:"last"`
+	}
+	q := url.Values{}
+	q.Set("instId", instID)
+	if err := s.get(ctx, tickerPath, q, &data); err != nil {
+		return 0, err
+	}
+	if len(data) > 0 {
+		if price := parseFloat(data[0].Last); price > 0 {
+			return price, nil
+		}
+	}
+	return 0, fmt.Errorf("last price not available for %s", sym)
+}
@@ -0,0 +1,631 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+	"brale/internal/scheduler"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	maxHistoryLimit      = 1500
+	candlesPageLimit     = 300
+	historyPageLimit     = 100
+	defaultCandleBufSize = 512
+	defaultTradeBufSize  = 1024
+	defaultWSBatchSize   = 50
+
+	candlesPath        = "/api/v5/market/candles"
+	historyCandlesPath = "/api/v5/market/history-candles"
+	instrumentsPath    = "/api/v5/public/instruments"
+
+	// OKX 在 30s 无消息时断开连接，按文档定期发送文本 ping。
+	wsPingInterval = 20 * time.Second
+	wsReadTimeout  = 40 * time.Second
+)
+
+// Source 为 OKX USDT 永续行情源：REST K 线 + WS candle/trades 频道。
+type Source struct {
+	cfg    Config
+	client *http.Client
+	dialer *websocket.Dialer
+
+	mu           sync.Mutex
+	candleCancel context.CancelFunc
+	tradeCancel  context.CancelFunc
+
+	statsMu sync.Mutex
+	stats   market.SourceStats
+
+	ctMu  sync.Mutex
+	ctVal map[string]float64
+}
+
+func New(cfg Config) (*Source, error) {
+	final := cfg.withDefaults()
+	httpClient := &http.Client{Timeout: final.HTTPTimeout}
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: final.HTTPTimeout,
+	}
+	if final.ProxyEnabled && final.RESTProxyURL != "" {
+		proxyURL, err := url.Parse(final.RESTProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid okx REST proxy url: %w", err)
+		}
+		baseTransport, ok := http.DefaultTransport.(*http.Transport)
+		if !ok || baseTransport == nil {
+			return nil, fmt.Errorf("http DefaultTransport is not *http.Transport")
+		}
+		transport := baseTransport.Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		httpClient.Transport = transport
+	}
+	if final.ProxyEnabled {
+		wsProxy := final.WSProxyURL
+		if wsProxy == "" {
+			wsProxy = final.RESTProxyURL
+		}
+		if wsProxy != "" {
+			proxyURL, err := url.Parse(wsProxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid okx WS proxy url: %w", err)
+			}
+			dialer.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+	return &Source{
+		cfg:    final,
+		client: httpClient,
+		dialer: dialer,
+		ctVal:  make(map[string]float64),
+	}, nil
+}
+
+type apiResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// get 调用 OKX 公共 REST 接口并把 data 字段解码到 out。
+func (s *Source) get(ctx context.Context, path string, query url.Values, out any) error {
+	endpoint := s.cfg.RESTBaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var body apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("okx %s: decode response (status=%d): %w", path, resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Code != "0" {
+		return fmt.Errorf("okx %s: status=%d code=%s msg=%s", path, resp.StatusCode, body.Code, body.Msg)
+	}
+	if out == nil || len(body.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body.Data, out)
+}
+
+func (s *Source) FetchHistory(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	instID := symbolpkg.OKX.ToExchange(symbol)
+	if instID == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	interval = strings.ToLower(strings.TrimSpace(interval))
+	bar, err := toBar(interval)
+	if err != nil {
+		return nil, err
+	}
+	dur, _ := scheduler.ParseIntervalDuration(interval)
+
+	// candles 仅覆盖最近约 1440 根，不足时改用 history-candles 继续向前翻页（after 为更早的时间戳游标）。
+	var rows [][]string
+	path, pageLimit, after := candlesPath, candlesPageLimit, ""
+	for len(rows) < limit {
+		page := min(limit-len(rows), pageLimit)
+		q := url.Values{}
+		q.Set("instId", instID)
+		q.Set("bar", bar)
+		q.Set("limit", strconv.Itoa(page))
+		if after != "" {
+			q.Set("after", after)
+		}
+		var data [][]string
+		if err := s.get(ctx, path, q, &data); err != nil {
+			logger.Errorf("[okx] fetch kline failed %s %s limit=%d: %v", symbol, interval, limit, err)
+			return nil, err
+		}
+		if len(data) > 0 {
+			rows = append(rows, data...)
+			after = data[len(data)-1][0]
+		}
+		if len(data) < page {
+			if path == historyCandlesPath {
+				break
+			}
+			path, pageLimit = historyCandlesPath, historyPageLimit
+		}
+	}
+
+	out := make([]market.Candle, 0, len(rows))
+	seen := make(map[int64]struct{}, len(rows))
+	for _, row := range rows {
+		c, confirmed, ok := parseCandleRow(row, dur)
+		if !ok || !confirmed {
+			continue
+		}
+		if _, dup := seen[c.OpenTime]; dup {
+			continue
+		}
+		seen[c.OpenTime] = struct{}{}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].OpenTime < out[j].OpenTime })
+	if len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	if dur > 0 {
+		out = scheduler.DropUnclosedBinanceKline(out, dur)
+	}
+	return out, nil
+}
+
+// parseCandleRow 解析 [ts,o,h,l,c,vol,volCcy,volCcyQuote,confirm]；成交量取 volCcy（币本位数量），与 Binance 的 base volume 口径一致。
+func parseCandleRow(row []string, dur time.Duration) (market.Candle, bool, bool) {
+	if len(row) < 7 {
+		return market.Candle{}, false, false
+	}
+	openTime, err := strconv.ParseInt(row[0], 10, 64)
+	if err != nil || openTime <= 0 {
+		return market.Candle{}, false, false
+	}
+	closeTime := openTime
+	if dur > 0 {
+		closeTime = openTime + dur.Milliseconds() - 1
+	}
+	confirmed := true
+	if len(row) >= 9 {
+		confirmed = strings.TrimSpace(row[8]) == "1"
+	}
+	return market.Candle{
+		OpenTime:  openTime,
+		CloseTime: closeTime,
+		Open:      parseFloat(row[1]),
+		High:      parseFloat(row[2]),
+		Low:       parseFloat(row[3]),
+		Close:     parseFloat(row[4]),
+		Volume:    parseFloat(row[6]),
+	}, confirmed, true
+}
+
+func (s *Source) Subscribe(ctx context.Context, symbols, intervals []string, opts market.SubscribeOptions) (<-chan market.CandleEvent, error) {
+	instIDs, symbolMap := normalizeSymbols(symbols)
+	channels := make(map[string]string)
+	for _, iv := range intervals {
+		iv = strings.ToLower(strings.TrimSpace(iv))
+		if iv == "" {
+			continue
+		}
+		bar, err := toBar(iv)
+		if err != nil {
+			logger.Warnf("[okx] 跳过不支持的周期 %s: %v", iv, err)
+			continue
+		}
+		channels["candle"+bar] = iv
+	}
+	var args []wsArg
+	for _, inst := range instIDs {
+		for ch := range channels {
+			args = append(args, wsArg{Channel: ch, InstID: inst})
+		}
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no valid symbols or intervals for subscription")
+	}
+	sort.Slice(args, func(i, j int) bool {
+		if args[i].InstID != args[j].InstID {
+			return args[i].InstID < args[j].InstID
+		}
+		return args[i].Channel < args[j].Channel
+	})
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = defaultCandleBufSize
+	}
+	out := make(chan market.CandleEvent, buffer)
+	subCtx, cancel := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	if s.candleCancel != nil {
+		s.candleCancel()
+	}
+	s.candleCancel = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer close(out)
+		s.runWSLoop(subCtx, "[okx] candle", s.cfg.WSBusinessURL, args, opts, func(msg wsMessage) {
+			interval, ok := channels[msg.Arg.Channel]
+			if !ok {
+				interval = fromBar(strings.TrimPrefix(msg.Arg.Channel, "candle"))
+			}
+			dur, _ := scheduler.ParseIntervalDuration(interval)
+			var rows [][]string
+			if err := json.Unmarshal(msg.Data, &rows); err != nil {
+				return
+			}
+			symbol := symbolMap[msg.Arg.InstID]
+			if symbol == "" {
+				symbol = symbolpkg.OKX.FromExchange(msg.Arg.InstID)
+			}
+			for _, row := range rows {
+				c, _, ok := parseCandleRow(row, dur)
+				if !ok {
+					continue
+				}
+				evt := market.CandleEvent{Symbol: symbol, Interval: interval, Candle: c}
+				select {
+				case <-subCtx.Done():
+					return
+				case out <- evt:
+				default:
+					logger.Warnf("[okx] kline channel full, drop %s %s", evt.Symbol, evt.Interval)
+				}
+			}
+		})
+	}()
+	return out, nil
+}
+
+func (s *Source) SubscribeTrades(ctx context.Context, symbols []string, opts market.SubscribeOptions) (<-chan market.TickEvent, error) {
+	instIDs, symbolMap := normalizeSymbols(symbols)
+	if len(instIDs) == 0 {
+		return nil, fmt.Errorf("no valid symbols for trade subscription")
+	}
+	if err := s.loadContractValues(ctx); err != nil {
+		logger.Warnf("[okx] 获取合约面值失败，成交数量将以张数计: %v", err)
+	}
+	args := make([]wsArg, 0, len(instIDs))
+	for _, inst := range instIDs {
+		args = append(args, wsArg{Channel: "trades", InstID: inst})
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = defaultTradeBufSize
+	}
+	out := make(chan market.TickEvent, buffer)
+	subCtx, cancel := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	if s.tradeCancel != nil {
+		s.tradeCancel()
+	}
+	s.tradeCancel = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer close(out)
+		s.runWSLoop(subCtx, "[okx] trade", s.cfg.WSPublicURL, args, opts, func(msg wsMessage) {
+			var trades []wsTrade
+			if err := json.Unmarshal(msg.Data, &trades); err != nil {
+				return
+			}
+			for _, tr := range trades {
+				evt, ok := s.convertTrade(tr, symbolMap)
+				if !ok {
+					continue
+				}
+				select {
+				case <-subCtx.Done():
+					return
+				case out <- evt:
+				default:
+					logger.Warnf("[okx] trade channel full, drop %s", evt.Symbol)
+				}
+			}
+		})
+	}()
+	return out, nil
+}
+
+type wsArg struct {
+	Channel string `json:"channel"`
+	InstID  string `json:"instId"`
+}
+
+type wsRequest struct {
+	Op   string  `json:"op"`
+	Args []wsArg `json:"args"`
+}
+
+type wsMessage struct {
+	Event string          `json:"event"`
+	Code  string          `json:"code"`
+	Msg   string          `json:"msg"`
+	Arg   wsArg           `json:"arg"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// wsTrade 为 trades 频道推送；同一 taker 订单的多笔成交已聚合，语义接近 Binance aggTrade。sz 单位为合约张数。
+type wsTrade struct {
+	InstID string `json:"instId"`
+	Px     string `json:"px"`
+	Sz     string `json:"sz"`
+	Ts     string `json:"ts"`
+}
+
+func (s *Source) convertTrade(tr wsTrade, symbolMap map[string]string) (market.TickEvent, bool) {
+	price := parseFloat(tr.Px)
+	if price <= 0 {
+		return market.TickEvent{}, false
+	}
+	inst := strings.ToUpper(strings.TrimSpace(tr.InstID))
+	symbol := symbolMap[inst]
+	if symbol == "" {
+		symbol = symbolpkg.OKX.FromExchange(inst)
+	}
+	if symbol == "" {
+		return market.TickEvent{}, false
+	}
+	qty := parseFloat(tr.Sz)
+	if ct := s.contractValue(inst); ct > 0 {
+		qty *= ct
+	}
+	ts, _ := strconv.ParseInt(tr.Ts, 10, 64)
+	return market.TickEvent{
+		Symbol:    symbol,
+		Price:     price,
+		Quantity:  qty,
+		EventTime: ts,
+		TradeTime: ts,
+	}, true
+}
+
+// runWSLoop 维持单条 WS 连接：断线后指数退避重连并重新订阅全部频道。
+func (s *Source) runWSLoop(ctx context.Context, label, endpoint string, args []wsArg, opts market.SubscribeOptions, onData func(wsMessage)) {
+	delay := time.Second
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		connected, err := s.serveWS(ctx, endpoint, args, opts, onData)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = time.Second
+			s.recordReconnect(err)
+		} else {
+			s.recordSubscribeError(err)
+		}
+		logger.Warnf("%s ws 断开，%s 后重连: %v", label, delay, err)
+		if opts.OnDisconnect != nil {
+			opts.OnDisconnect(err)
+		}
+		if !sleepWithContext(ctx, delay) {
+			return
+		}
+		delay = nextDelay(delay)
+	}
+}
+
+func (s *Source) serveWS(ctx context.Context, endpoint string, args []wsArg, opts market.SubscribeOptions, onData func(wsMessage)) (bool, error) {
+	conn, _, err := s.dialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	batch := opts.BatchSize
+	if batch <= 0 {
+		batch = defaultWSBatchSize
+	}
+	for start := 0; start < len(args); start += batch {
+		end := min(start+batch, len(args))
+		if err := conn.WriteJSON(wsRequest{Op: "subscribe", Args: args[start:end]}); err != nil {
+			return false, err
+		}
+	}
+	if opts.OnConnect != nil {
+		opts.OnConnect()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				_ = conn.Close()
+				return
+			case <-ticker.C:
+				if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+					_ = conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		if string(raw) == "pong" {
+			continue
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			continue
+		}
+		switch msg.Event {
+		case "":
+			if len(msg.Data) > 0 {
+				onData(msg)
+			}
+		case "error":
+			s.recordSubscribeError(fmt.Errorf("okx ws error code=%s msg=%s", msg.Code, msg.Msg))
+		}
+	}
+}
+
+// loadContractValues 拉取 USDT 永续合约面值（ctVal），用于把成交张数换算为币数量。
+func (s *Source) loadContractValues(ctx context.Context) error {
+	s.ctMu.Lock()
+	loaded := len(s.ctVal) > 0
+	s.ctMu.Unlock()
+	if loaded {
+		return nil
+	}
+	var insts []struct {
+		InstID string `json:"instId"`
+		CtVal  string `json:"ctVal"`
+	}
+	q := url.Values{}
+	q.Set("instType", "SWAP")
+	if err := s.get(ctx, instrumentsPath, q, &insts); err != nil {
+		return err
+	}
+	s.ctMu.Lock()
+	defer s.ctMu.Unlock()
+	for _, inst := range insts {
+		if v := parseFloat(inst.CtVal); v > 0 {
+			s.ctVal[strings.ToUpper(inst.InstID)] = v
+		}
+	}
+	return nil
+}
+
+func (s *Source) contractValue(instID string) float64 {
+	s.ctMu.Lock()
+	defer s.ctMu.Unlock()
+	return s.ctVal[instID]
+}
+
+func normalizeSymbols(symbols []string) ([]string, map[string]string) {
+	symbolMap := make(map[string]string)
+	out := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		norm := symbolpkg.Normalize(sym)
+		if norm == "" {
+			continue
+		}
+		inst := symbolpkg.OKX.ToExchange(norm)
+		if inst == "" {
+			continue
+		}
+		if _, ok := symbolMap[inst]; ok {
+			continue
+		}
+		symbolMap[inst] = norm
+		out = append(out, inst)
+	}
+	return out, symbolMap
+}
+
+func (s *Source) Stats() market.SourceStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
+
+func (s *Source) ClearLastError() {
+	s.statsMu.Lock()
+	s.stats.LastError = ""
+	s.statsMu.Unlock()
+}
+
+func (s *Source) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.candleCancel != nil {
+		s.candleCancel()
+		s.candleCancel = nil
+	}
+	if s.tradeCancel != nil {
+		s.tradeCancel()
+		s.tradeCancel = nil
+	}
+	return nil
+}
+
+func parseFloat(v string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	return f
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func nextDelay(current time.Duration) time.Duration {
+	if current <= 0 {
+		return time.Second
+	}
+	next := current * 2
+	if next > 30*time.Second {
+		next = 30 * time.Second
+	}
+	return next
+}
+
+func (s *Source) recordSubscribeError(err error) {
+	if err == nil {
+		return
+	}
+	s.statsMu.Lock()
+	s.stats.SubscribeErrors++
+	s.stats.LastError = err.Error()
+	s.statsMu.Unlock()
+}
+
+func (s *Source) recordReconnect(err error) {
+	s.statsMu.Lock()
+	s.stats.Reconnects++
+	if err != nil && err.Error() != "" {
+		s.stats.LastError = err.Error()
+	}
+	s.statsMu.Unlock()
+}
@@ -7,12 +7,23 @@ type ImagePayload struct {
 	Description string
 }
 
+// ToolSpec 为 OpenAI function-calling 的函数定义；Parameters 为 JSON Schema。
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
 type ChatPayload struct {
 	System     string
 	User       string
 	Images     []ImagePayload
 	ExpectJSON bool
 	MaxTokens  int
+	// Tools 非空时随请求下发函数定义；模型以 tool_calls 回复时返回函数参数 JSON。
+	Tools []ToolSpec
+	// ToolChoice 为 auto/required/none 或具体函数名，留空由服务端决定。
+	ToolChoice string
 }
 
 type ModelProvider interface {
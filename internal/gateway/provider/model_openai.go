@@ -20,6 +20,10 @@ type OpenAIChatClient struct {
 	Timeout      time.Duration
 	MaxRetries   int
 	ExtraHeaders map[string]string
+	// Stream 为 true 时以 SSE 流式读取响应，重试与 Retry-After 处理与非流式一致。
+	Stream bool
+	// OnUsage 在成功响应携带用量字段时回调。
+	OnUsage func(Usage)
 }
 
 func (c *OpenAIChatClient) Call(ctx context.Context, payload ChatPayload) (string, error) {
@@ -28,7 +32,7 @@ func (c *OpenAIChatClient) Call(ctx context.Context, payload ChatPayload) (strin
 	maxRetries := normalizeRetries(c.MaxRetries)
 	url := c.chatCompletionsURL()
 
-	bodyBytes := buildChatBodyBytes(c.Model, payload)
+	bodyBytes := buildChatBodyBytes(c.Model, payload, c.Stream)
 	logger.LogLLMPayload(c.Model, string(bodyBytes))
 
 	httpc := &http.Client{Timeout: timeout}
@@ -65,7 +69,7 @@ func (c *OpenAIChatClient) chatCompletionsURL() string {
 	return url + "/chat/completions"
 }
 
-func buildChatBodyBytes(model string, payload ChatPayload) []byte {
+func buildChatBodyBytes(model string, payload ChatPayload, stream bool) []byte {
 	messages := make([]map[string]any, 0, 3)
 	if payload.System != "" {
 		messages = append(messages, map[string]any{
@@ -85,9 +89,19 @@ func buildChatBodyBytes(model string, payload ChatPayload) []byte {
 		"temperature": 0.4,
 		"max_tokens":  maxTokens,
 	}
-	if payload.ExpectJSON {
+	if payload.ExpectJSON && len(payload.Tools) == 0 {
 		body["response_format"] = map[string]string{"type": "json_object"}
 	}
+	if len(payload.Tools) > 0 {
+		body["tools"] = buildTools(payload.Tools)
+		if choice := buildToolChoice(payload.ToolChoice); choice != nil {
+			body["tool_choice"] = choice
+		}
+	}
+	if stream {
+		body["stream"] = true
+		body["stream_options"] = map[string]any{"include_usage": true}
+	}
 	b, _ := json.Marshal(body)
 	return b
 }
@@ -107,16 +121,26 @@ func (c *OpenAIChatClient) doChatCompletions(ctx context.Context, httpc *http.Cl
 		}
 		resp, err := httpc.Do(req)
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", fmt.Errorf("请求已取消: %w", ctxErr)
+			}
 			lastErr = err
 			break
 		}
 
 		if resp.StatusCode/100 == 2 {
-			content, err := decodeChatContent(resp)
+			decode := decodeChatContent
+			if c.Stream {
+				decode = decodeChatStream
+			}
+			content, usage, err := decode(resp)
 			if err != nil {
 				lastErr = err
 				break
 			}
+			if c.OnUsage != nil && !usage.Empty() {
+				c.OnUsage(usage)
+			}
 			return content, nil
 		}
 
@@ -124,7 +148,9 @@ func (c *OpenAIChatClient) doChatCompletions(ctx context.Context, httpc *http.Cl
 		lastErr = fmt.Errorf("status=%d: %s", resp.StatusCode, msg)
 		if shouldRetry(resp.StatusCode) && attempt < maxRetries {
 			wait := parseRetryAfter(resp.Header.Get("Retry-After"), attempt)
-			time.Sleep(wait)
+			if err := sleepCtx(ctx, wait); err != nil {
+				return "", fmt.Errorf("重试等待被取消: %w (上次错误: %v)", err, lastErr)
+			}
 			continue
 		}
 		break
@@ -132,7 +158,22 @@ func (c *OpenAIChatClient) doChatCompletions(ctx context.Context, httpc *http.Cl
 	return "", lastErr
 }
 
-func decodeChatContent(resp *http.Response) (string, error) {
+// sleepCtx 在退避等待期间响应取消；若截止时间早于等待结束则直接返回，不做无意义的等待。
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func decodeChatContent(resp *http.Response) (string, Usage, error) {
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil {
 			logger.Debugf("[AI] response body close failed: %v", cerr)
@@ -141,17 +182,26 @@ func decodeChatContent(resp *http.Response) (string, error) {
 	var r struct {
 		Choices []struct {
 			Message struct {
-				Content string `json:"content"`
+				Content   string         `json:"content"`
+				ToolCalls []chatToolCall `json:"tool_calls"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage         *chatUsage   `json:"usage"`
+		UsageMetadata *geminiUsage `json:"usageMetadata"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
+	usage := responseUsage(r.Usage, r.UsageMetadata)
 	if len(r.Choices) == 0 {
-		return "", fmt.Errorf("empty choices")
+		return "", usage, fmt.Errorf("empty choices")
+	}
+	msg := r.Choices[0].Message
+	if len(msg.ToolCalls) > 0 {
+		content, err := toolCallContent(msg.ToolCalls)
+		return content, usage, err
 	}
-	return r.Choices[0].Message.Content, nil
+	return msg.Content, usage, nil
 }
 
 func (c *OpenAIChatClient) headers() map[string]string {
@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"brale/internal/logger"
+)
+
+// SSE 单行上限；部分自建端点会把整段 tool_calls 参数放在一个 chunk 里。
+const maxStreamLineBytes = 4 << 20
+
+type chatToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func buildTools(tools []ToolSpec) []map[string]any {
+	out := make([]map[string]any, 0, len(tools))
+	for _, t := range tools {
+		name := strings.TrimSpace(t.Name)
+		if name == "" {
+			continue
+		}
+		fn := map[string]any{"name": name}
+		if desc := strings.TrimSpace(t.Description); desc != "" {
+			fn["description"] = desc
+		}
+		params := t.Parameters
+		if params == nil {
+			params = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		fn["parameters"] = params
+		out = append(out, map[string]any{"type": "function", "function": fn})
+	}
+	return out
+}
+
+// buildToolChoice 把 auto/required/none 原样下发，其它值视为指定函数名。
+func buildToolChoice(choice string) any {
+	choice = strings.TrimSpace(choice)
+	switch strings.ToLower(choice) {
+	case "":
+		return nil
+	case "auto", "required", "none":
+		return strings.ToLower(choice)
+	default:
+		return map[string]any{"type": "function", "function": map[string]any{"name": choice}}
+	}
+}
+
+// toolCallContent 把函数调用转换为 Call 的文本结果：单个调用返回其参数 JSON，
+// 多个调用返回 [{"name":..,"arguments":{..}}] 数组，便于沿用现有 JSON 解析。
+func toolCallContent(calls []chatToolCall) (string, error) {
+	if len(calls) == 1 {
+		args := strings.TrimSpace(calls[0].Function.Arguments)
+		if args == "" {
+			args = "{}"
+		}
+		return args, nil
+	}
+	type namedCall struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	out := make([]namedCall, 0, len(calls))
+	for _, call := range calls {
+		args := strings.TrimSpace(call.Function.Arguments)
+		if args == "" {
+			args = "{}"
+		}
+		if !json.Valid([]byte(args)) {
+			return "", fmt.Errorf("tool_call %s 参数不是合法 JSON", call.Function.Name)
+		}
+		out = append(out, namedCall{Name: call.Function.Name, Arguments: json.RawMessage(args)})
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// decodeChatStream 读取 chat/completions 的 SSE 响应，拼接 delta.content 与按 index 累积的 tool_calls；
+// 用量取自最后一个携带 usage 的 chunk（请求中已设置 stream_options.include_usage）。
+func decodeChatStream(resp *http.Response) (string, Usage, error) {
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			logger.Debugf("[AI] response body close failed: %v", cerr)
+		}
+	}()
+	var (
+		content  strings.Builder
+		calls    = make(map[int]*chatToolCall)
+		finished bool
+		chunks   int
+		usage    Usage
+	)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineBytes)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			finished = true
+			break
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content   string         `json:"content"`
+					ToolCalls []chatToolCall `json:"tool_calls"`
+				} `json:"delta"`
+				FinishReason string `json:"finish_reason"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+			Usage         *chatUsage   `json:"usage"`
+			UsageMetadata *geminiUsage `json:"usageMetadata"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", usage, fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.Error != nil {
+			return "", usage, fmt.Errorf("流式响应错误: %s", chunk.Error.Message)
+		}
+		chunks++
+		if u := responseUsage(chunk.Usage, chunk.UsageMetadata); !u.Empty() {
+			usage = u
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			for _, delta := range choice.Delta.ToolCalls {
+				call, ok := calls[delta.Index]
+				if !ok {
+					call = &chatToolCall{Index: delta.Index}
+					calls[delta.Index] = call
+				}
+				if delta.ID != "" {
+					call.ID = delta.ID
+				}
+				if delta.Function.Name != "" {
+					call.Function.Name = delta.Function.Name
+				}
+				call.Function.Arguments += delta.Function.Arguments
+			}
+			if choice.FinishReason != "" {
+				finished = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", usage, fmt.Errorf("读取流式响应中断: %w", err)
+	}
+	if chunks == 0 {
+		return "", usage, fmt.Errorf("empty stream")
+	}
+	if !finished {
+		logger.Warnf("[AI] 流式响应未收到结束标记，按已接收内容返回 chunks=%d", chunks)
+	}
+	if len(calls) > 0 {
+		ordered := make([]chatToolCall, 0, len(calls))
+		for _, call := range calls {
+			ordered = append(ordered, *call)
+		}
+		sort.Slice(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+		out, err := toolCallContent(ordered)
+		return out, usage, err
+	}
+	return content.String(), usage, nil
+}
@@ -14,9 +14,14 @@ type ModelCfg struct {
 	Headers                             map[string]string
 	SupportsVision                      bool
 	ExpectJSON                          bool
+	// Redact 为发往该 provider 的 prompt 脱敏模式（strip/hash），空表示不处理。
+	Redact string
+	// Stream 以 SSE 流式接收响应，适合 vLLM/Ollama 等长输出的自建端点。
+	Stream bool
 }
 
-func BuildProvidersFromConfig(models []ModelCfg, timeout time.Duration) []ModelProvider {
+// BuildProvidersFromConfig 构建启用的模型；meter 非空时记录每次调用的用量并在预算超出时拒绝调用。
+func BuildProvidersFromConfig(models []ModelCfg, timeout time.Duration, meter UsageMeter) []ModelProvider {
 	out := make([]ModelProvider, 0, len(models))
 	for _, m := range models {
 		if !m.Enabled {
@@ -41,11 +46,27 @@ func BuildProvidersFromConfig(models []ModelCfg, timeout time.Duration) []ModelP
 			APIKey:       m.APIKey,
 			Model:        m.Model,
 			ExtraHeaders: m.Headers,
+			Stream:       m.Stream,
 		}
 		if timeout > 0 {
 			client.Timeout = timeout
 		}
-		out = append(out, NewOpenAIModelProvider(id, true, m.SupportsVision, m.ExpectJSON, client))
+		if meter != nil {
+			modelID := id
+			client.OnUsage = func(u Usage) { meter.Record(modelID, u) }
+		}
+		var p ModelProvider = NewOpenAIModelProvider(id, true, m.SupportsVision, m.ExpectJSON, client)
+		p = WithUsageMeter(p, meter)
+		if strings.TrimSpace(m.Redact) != "" {
+			redactor, err := NewRedactor(m.Redact)
+			if err != nil {
+				logger.Warnf("模型 %s 脱敏配置无效，已跳过脱敏: %v", id, err)
+			} else {
+				p = WithRedaction(p, redactor)
+				logger.Infof("模型 %s 已启用 prompt 脱敏: %s", id, redactor.Mode())
+			}
+		}
+		out = append(out, p)
 	}
 	return out
 }
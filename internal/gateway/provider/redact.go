@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 脱敏模式：strip 把金额替换为占位符，hash 替换为带盐摘要（同一进程内同值同摘要，便于模型对比但无法还原）。
+const (
+	RedactNone  = ""
+	RedactStrip = "strip"
+	RedactHash  = "hash"
+)
+
+const redactPlaceholder = "[redacted]"
+
+// accountAmountPattern 匹配 prompt 中可识别账户规模的数值：账户资金行、持仓 stake 以及 JSON/键值形式的余额与数量字段。
+// 价格、指标、比例等分析数据不在此列。
+var accountAmountPattern = regexp.MustCompile(`(?i)((?:权益|可用|已使用)\s*[:：]\s*|\b(?:stake|stake_amount|amount|initial_amount|balance|equity|available|used|qty|quantity|position_size|position_value)"?\s*[=:]\s*"?)(-?\d[\d,]*(?:\.\d+)?)`)
+
+// NormalizeRedactMode 校验并规范化脱敏模式，none/off 视为关闭。
+func NormalizeRedactMode(mode string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case "", "none", "off":
+		return RedactNone, nil
+	case RedactStrip, RedactHash:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported redact mode %q (strip/hash)", mode)
+	}
+}
+
+// Redactor 对发往第三方模型的文本做账户信息脱敏。
+type Redactor struct {
+	mode string
+	salt []byte
+}
+
+// NewRedactor 创建脱敏器；hash 模式使用进程级随机盐，避免通过穷举小数值反推原值。
+func NewRedactor(mode string) (*Redactor, error) {
+	m, err := NormalizeRedactMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	r := &Redactor{mode: m}
+	if m == RedactHash {
+		r.salt = make([]byte, 16)
+		if _, err := rand.Read(r.salt); err != nil {
+			return nil, fmt.Errorf("生成脱敏盐失败: %w", err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Redactor) Mode() string {
+	if r == nil {
+		return RedactNone
+	}
+	return r.mode
+}
+
+// Apply 返回脱敏后的文本，非金额内容保持不变。
+func (r *Redactor) Apply(text string) string {
+	if r == nil || r.mode == RedactNone || text == "" {
+		return text
+	}
+	return accountAmountPattern.ReplaceAllStringFunc(text, func(match string) string {
+		sub := accountAmountPattern.FindStringSubmatch(match)
+		if len(sub) < 3 {
+			return match
+		}
+		return sub[1] + r.replacement(sub[2])
+	})
+}
+
+func (r *Redactor) replacement(value string) string {
+	if r.mode != RedactHash {
+		return redactPlaceholder
+	}
+	h := sha256.New()
+	h.Write(r.salt)
+	h.Write([]byte(strings.ReplaceAll(value, ",", "")))
+	return "#" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// redactingProvider 在调用前对 System/User 文本脱敏，其余行为透传给内部 provider。
+type redactingProvider struct {
+	ModelProvider
+	redactor *Redactor
+}
+
+// WithRedaction 为 provider 包装脱敏；redactor 为空或模式关闭时原样返回。
+func WithRedaction(p ModelProvider, redactor *Redactor) ModelProvider {
+	if p == nil || redactor == nil || redactor.Mode() == RedactNone {
+		return p
+	}
+	return &redactingProvider{ModelProvider: p, redactor: redactor}
+}
+
+func (p *redactingProvider) Call(ctx context.Context, payload ChatPayload) (string, error) {
+	payload.System = p.redactor.Apply(payload.System)
+	payload.User = p.redactor.Apply(payload.User)
+	if len(payload.Images) > 0 {
+		// 图片切片与其他 provider 共享，复制后再改写描述。
+		images := make([]ImagePayload, len(payload.Images))
+		for i, img := range payload.Images {
+			img.Description = p.redactor.Apply(img.Description)
+			images[i] = img
+		}
+		payload.Images = images
+	}
+	return p.ModelProvider.Call(ctx, payload)
+}
@@ -0,0 +1,67 @@
+package provider
+
+import "context"
+
+// Usage 为一次模型调用的 token 用量。
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+func (u Usage) Empty() bool {
+	return u.InputTokens <= 0 && u.OutputTokens <= 0
+}
+
+// chatUsage 兼容 OpenAI 格式（prompt_tokens/completion_tokens）与 Anthropic 格式（input_tokens/output_tokens）。
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	InputTokens      int `json:"input_tokens"`
+	OutputTokens     int `json:"output_tokens"`
+}
+
+// geminiUsage 为 Gemini 响应中的 usageMetadata。
+type geminiUsage struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+}
+
+func responseUsage(u *chatUsage, g *geminiUsage) Usage {
+	var out Usage
+	if u != nil {
+		out.InputTokens = max(u.PromptTokens, u.InputTokens)
+		out.OutputTokens = max(u.CompletionTokens, u.OutputTokens)
+	}
+	if out.Empty() && g != nil {
+		out.InputTokens = g.PromptTokenCount
+		out.OutputTokens = g.CandidatesTokenCount
+	}
+	return out
+}
+
+// UsageMeter 在调用前检查预算、调用后记录用量。
+type UsageMeter interface {
+	Allow(modelID string) error
+	Record(modelID string, usage Usage)
+}
+
+// meteredProvider 在预算超出时拒绝调用，其余行为透传给内部 provider；用量由客户端的 OnUsage 回调记录。
+type meteredProvider struct {
+	ModelProvider
+	meter UsageMeter
+}
+
+// WithUsageMeter 为 provider 包装预算检查；meter 为空时原样返回。
+func WithUsageMeter(p ModelProvider, meter UsageMeter) ModelProvider {
+	if p == nil || meter == nil {
+		return p
+	}
+	return &meteredProvider{ModelProvider: p, meter: meter}
+}
+
+func (p *meteredProvider) Call(ctx context.Context, payload ChatPayload) (string, error) {
+	if err := p.meter.Allow(p.ID()); err != nil {
+		return "", err
+	}
+	return p.ModelProvider.Call(ctx, payload)
+}
@@ -0,0 +1,347 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	brcfg "brale/internal/config"
+	"brale/internal/logger"
+	"brale/internal/market"
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+// NewSourceWithRoutes 构建默认行情源，并按 routes（symbol → 行情源名称，来自 profile.market_source）
+// 把部分交易对路由到其他已注册行情源；routes 为空或全部指向默认源时直接返回默认源。
+func NewSourceWithRoutes(cfg *brcfg.Config, routes map[string]string) (market.Source, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("nil config")
+	}
+	active := cfg.Market.ResolveActiveSource()
+	def, err := newSource(active)
+	if err != nil {
+		return nil, err
+	}
+	activeName := strings.ToLower(strings.TrimSpace(active.Name))
+	named := make(map[string]market.Source)
+	symbolRoutes := make(map[string]market.Source)
+	for sym, name := range routes {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == activeName {
+			continue
+		}
+		src, ok := named[name]
+		if !ok {
+			src, err = NewSourceByName(cfg, name)
+			if err != nil {
+				_ = def.Close()
+				for _, s := range named {
+					_ = s.Close()
+				}
+				return nil, fmt.Errorf("symbol %s: %w", sym, err)
+			}
+			named[name] = src
+			logger.Infof("✓ 行情源 %s 已初始化（profile 路由）", name)
+		}
+		symbolRoutes[routeKey(sym)] = src
+	}
+	if len(symbolRoutes) == 0 {
+		return def, nil
+	}
+	return newRoutedSource(def, symbolRoutes), nil
+}
+
+// RoutedSource 按 symbol 把请求分发到不同行情源，对 PriceMonitor / CandleFetcher 表现为单一 market.Source。
+type RoutedSource struct {
+	def    market.Source
+	routes map[string]market.Source
+	all    []market.Source
+}
+
+func newRoutedSource(def market.Source, routes map[string]market.Source) *RoutedSource {
+	all := []market.Source{def}
+	seen := map[market.Source]struct{}{def: {}}
+	for _, src := range routes {
+		if _, ok := seen[src]; ok {
+			continue
+		}
+		seen[src] = struct{}{}
+		all = append(all, src)
+	}
+	return &RoutedSource{def: def, routes: routes, all: all}
+}
+
+func routeKey(sym string) string {
+	if norm := symbolpkg.Normalize(sym); norm != "" {
+		return norm
+	}
+	return strings.ToUpper(strings.TrimSpace(sym))
+}
+
+func (r *RoutedSource) pick(symbol string) market.Source {
+	if src, ok := r.routes[routeKey(symbol)]; ok {
+		return src
+	}
+	return r.def
+}
+
+// group 按行情源拆分 symbol 列表，保持 r.all 的顺序。
+func (r *RoutedSource) group(symbols []string) ([]market.Source, map[market.Source][]string) {
+	groups := make(map[market.Source][]string)
+	for _, sym := range symbols {
+		src := r.pick(sym)
+		groups[src] = append(groups[src], sym)
+	}
+	order := make([]market.Source, 0, len(groups))
+	for _, src := range r.all {
+		if _, ok := groups[src]; ok {
+			order = append(order, src)
+		}
+	}
+	return order, groups
+}
+
+func (r *RoutedSource) FetchHistory(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error) {
+	return r.pick(symbol).FetchHistory(ctx, symbol, interval, limit)
+}
+
+func (r *RoutedSource) Subscribe(ctx context.Context, symbols, intervals []string, opts market.SubscribeOptions) (<-chan market.CandleEvent, error) {
+	order, groups := r.group(symbols)
+	chans := make([]<-chan market.CandleEvent, 0, len(order))
+	for _, src := range order {
+		ch, err := src.Subscribe(ctx, groups[src], intervals, opts)
+		if err != nil {
+			return nil, err
+		}
+		chans = append(chans, ch)
+	}
+	return mergeChannels(chans, opts.Buffer), nil
+}
+
+func (r *RoutedSource) SubscribeTrades(ctx context.Context, symbols []string, opts market.SubscribeOptions) (<-chan market.TickEvent, error) {
+	order, groups := r.group(symbols)
+	chans := make([]<-chan market.TickEvent, 0, len(order))
+	for _, src := range order {
+		ch, err := src.SubscribeTrades(ctx, groups[src], opts)
+		if err != nil {
+			return nil, err
+		}
+		chans = append(chans, ch)
+	}
+	return mergeChannels(chans, opts.Buffer), nil
+}
+
+// SubscribeDepth 按 symbol 所属行情源订阅部分深度；不支持深度推送的行情源跳过其 symbol。
+func (r *RoutedSource) SubscribeDepth(ctx context.Context, symbols []string, opts market.SubscribeOptions) (<-chan market.OrderBook, error) {
+	order, groups := r.group(symbols)
+	chans := make([]<-chan market.OrderBook, 0, len(order))
+	for _, src := range order {
+		provider, ok := src.(market.OrderBookProvider)
+		if !ok {
+			logger.Warnf("行情源不支持深度推送，跳过 %v", groups[src])
+			continue
+		}
+		ch, err := provider.SubscribeDepth(ctx, groups[src], opts)
+		if err != nil {
+			return nil, err
+		}
+		chans = append(chans, ch)
+	}
+	if len(chans) == 0 {
+		return nil, fmt.Errorf("no market source supports depth stream")
+	}
+	return mergeChannels(chans, opts.Buffer), nil
+}
+
+// mergeChannels 合并多个订阅流，全部上游关闭后关闭输出。
+func mergeChannels[T any](in []<-chan T, buffer int) <-chan T {
+	if len(in) == 1 {
+		return in[0]
+	}
+	if buffer <= 0 {
+		buffer = 512
+	}
+	out := make(chan T, buffer)
+	var wg sync.WaitGroup
+	for _, ch := range in {
+		wg.Add(1)
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for evt := range ch {
+				out <- evt
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+func (r *RoutedSource) GetFundingRate(ctx context.Context, symbol string) (float64, error) {
+	return r.pick(symbol).GetFundingRate(ctx, symbol)
+}
+
+func (r *RoutedSource) FundingInfo(ctx context.Context, symbol string) (market.FundingInfo, error) {
+	src := r.pick(symbol)
+	if provider, ok := src.(market.FundingInfoProvider); ok {
+		return provider.FundingInfo(ctx, symbol)
+	}
+	rate, err := src.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return market.FundingInfo{}, err
+	}
+	return market.FundingInfo{Symbol: symbol, Rate: rate, PredictedRate: rate}, nil
+}
+
+func (r *RoutedSource) GetOpenInterestHistory(ctx context.Context, symbol, period string, limit int) ([]market.OpenInterestPoint, error) {
+	return r.pick(symbol).GetOpenInterestHistory(ctx, symbol, period, limit)
+}
+
+// SupportedOIPeriods 取各行情源支持周期的交集；未声明的行情源视为不限制。
+func (r *RoutedSource) SupportedOIPeriods() []string {
+	var out []string
+	limited := false
+	for _, src := range r.all {
+		provider, ok := src.(interface{ SupportedOIPeriods() []string })
+		if !ok {
+			continue
+		}
+		periods := provider.SupportedOIPeriods()
+		if !limited {
+			out, limited = append([]string(nil), periods...), true
+			continue
+		}
+		keep := make(map[string]struct{}, len(periods))
+		for _, p := range periods {
+			keep[strings.ToLower(p)] = struct{}{}
+		}
+		filtered := out[:0]
+		for _, p := range out {
+			if _, ok := keep[strings.ToLower(p)]; ok {
+				filtered = append(filtered, p)
+			}
+		}
+		out = filtered
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (r *RoutedSource) IndexPrice(ctx context.Context, symbol string) (float64, error) {
+	provider, ok := r.pick(symbol).(market.ReferencePriceProvider)
+	if !ok {
+		return 0, fmt.Errorf("market source for %s does not support index price", symbol)
+	}
+	return provider.IndexPrice(ctx, symbol)
+}
+
+func (r *RoutedSource) LastPrice(ctx context.Context, symbol string) (float64, error) {
+	provider, ok := r.pick(symbol).(market.ReferencePriceProvider)
+	if !ok {
+		return 0, fmt.Errorf("market source for %s does not support last price", symbol)
+	}
+	return provider.LastPrice(ctx, symbol)
+}
+
+func (r *RoutedSource) ratioProvider(symbol string) (market.LongShortRatioProvider, error) {
+	provider, ok := r.pick(symbol).(market.LongShortRatioProvider)
+	if !ok {
+		return nil, fmt.Errorf("market source for %s does not support long/short ratio", symbol)
+	}
+	return provider, nil
+}
+
+func (r *RoutedSource) TopPositionRatio(ctx context.Context, symbol, period string, limit int) ([]market.LongShortRatioPoint, error) {
+	provider, err := r.ratioProvider(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return provider.TopPositionRatio(ctx, symbol, period, limit)
+}
+
+func (r *RoutedSource) TopAccountRatio(ctx context.Context, symbol, period string, limit int) ([]market.LongShortRatioPoint, error) {
+	provider, err := r.ratioProvider(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return provider.TopAccountRatio(ctx, symbol, period, limit)
+}
+
+func (r *RoutedSource) GlobalAccountRatio(ctx context.Context, symbol, period string, limit int) ([]market.LongShortRatioPoint, error) {
+	provider, err := r.ratioProvider(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GlobalAccountRatio(ctx, symbol, period, limit)
+}
+
+// SubscribeLiquidations 全市场强平流只取默认行情源。
+func (r *RoutedSource) SubscribeLiquidations(ctx context.Context, opts market.SubscribeOptions) (<-chan market.LiquidationEvent, error) {
+	provider, ok := r.def.(market.LiquidationProvider)
+	if !ok {
+		return nil, fmt.Errorf("default market source does not support liquidation stream")
+	}
+	return provider.SubscribeLiquidations(ctx, opts)
+}
+
+func (r *RoutedSource) Stats() market.SourceStats {
+	var out market.SourceStats
+	for _, src := range r.all {
+		st := src.Stats()
+		out.Reconnects += st.Reconnects
+		out.SubscribeErrors += st.SubscribeErrors
+		if out.LastError == "" {
+			out.LastError = st.LastError
+		}
+	}
+	return out
+}
+
+func (r *RoutedSource) ClearLastError() {
+	for _, src := range r.all {
+		if resetter, ok := src.(interface{ ClearLastError() }); ok {
+			resetter.ClearLastError()
+		}
+	}
+}
+
+func (r *RoutedSource) Close() error {
+	var errs []error
+	for _, src := range r.all {
+		if err := src.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SymbolInfos 汇总各行情源的交易规则，每个 symbol 只保留其路由目标源给出的规则。
+func (r *RoutedSource) SymbolInfos(ctx context.Context) ([]market.SymbolInfo, error) {
+	var out []market.SymbolInfo
+	var lastErr error
+	for _, src := range r.all {
+		provider, ok := src.(market.SymbolInfoProvider)
+		if !ok {
+			continue
+		}
+		infos, err := provider.SymbolInfos(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, info := range infos {
+			if r.pick(info.Symbol) == src {
+				out = append(out, info)
+			}
+		}
+	}
+	if len(out) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return out, nil
+}
@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventDecision   = "decision"
+	EventEntryFill  = "entry_fill"
+	EventTierHit    = "tier_hit"
+	EventStopHit    = "stop_hit"
+	EventDivergence = "divergence"
+
+	HeaderEvent     = "X-Brale-Event"
+	HeaderDelivery  = "X-Brale-Delivery"
+	HeaderTimestamp = "X-Brale-Timestamp"
+	HeaderSignature = "X-Brale-Signature"
+
+	maxConcurrentDeliveries = 4
+	maxRetryBackoff         = time.Minute
+)
+
+// Event 为推送给外部系统的结构化信号。
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Symbol  string    `json:"symbol,omitempty"`
+	TradeID int       `json:"trade_id,omitempty"`
+	Data    any       `json:"data,omitempty"`
+}
+
+type Endpoint struct {
+	Name   string
+	URL    string
+	Secret string
+	Events []string
+}
+
+func (e Endpoint) accepts(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, evt := range e.Events {
+		if evt == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type Config struct {
+	Endpoints []Endpoint
+	Retries   int
+	Timeout   time.Duration
+	QueueSize int
+	Log       database.WebhookDeliveryLog
+}
+
+// Dispatcher 异步投递事件：Publish 只入队，后台按 endpoint 订阅过滤、签名并重试。
+// nil Dispatcher 的所有方法均为空操作，调用方无需判空。
+type Dispatcher struct {
+	endpoints []Endpoint
+	retries   int
+	client    *http.Client
+	log       database.WebhookDeliveryLog
+	queue     chan Event
+	sem       chan struct{}
+}
+
+func New(cfg Config) *Dispatcher {
+	if len(cfg.Endpoints) == 0 {
+		return nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = 256
+	}
+	retries := cfg.Retries
+	if retries < 0 {
+		retries = 0
+	}
+	return &Dispatcher{
+		endpoints: append([]Endpoint(nil), cfg.Endpoints...),
+		retries:   retries,
+		client:    &http.Client{Timeout: timeout},
+		log:       cfg.Log,
+		queue:     make(chan Event, size),
+		sem:       make(chan struct{}, maxConcurrentDeliveries),
+	}
+}
+
+// Publish 把事件放入投递队列；队列满时丢弃并告警，不阻塞交易主流程。
+func (d *Dispatcher) Publish(evt Event) {
+	if d == nil || strings.TrimSpace(evt.Type) == "" {
+		return
+	}
+	if evt.ID == "" {
+		evt.ID = uuid.NewString()
+	}
+	if evt.Time.IsZero() {
+		evt.Time = time.Now().UTC()
+	}
+	select {
+	case d.queue <- evt:
+	default:
+		logger.Warnf("webhook 队列已满，丢弃事件 type=%s symbol=%s", evt.Type, evt.Symbol)
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	if d == nil {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt := <-d.queue:
+				d.dispatch(ctx, evt)
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, evt Event) {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		logger.Warnf("webhook 事件序列化失败 type=%s: %v", evt.Type, err)
+		return
+	}
+	for _, ep := range d.endpoints {
+		if !ep.accepts(evt.Type) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case d.sem <- struct{}{}:
+		}
+		go func(ep Endpoint) {
+			defer func() { <-d.sem }()
+			d.deliver(ctx, ep, evt, body)
+		}(ep)
+	}
+}
+
+// deliver 投递单个 endpoint：网络错误、5xx 与 429 按指数退避重试，其余 4xx 视为永久失败。
+func (d *Dispatcher) deliver(ctx context.Context, ep Endpoint, evt Event, body []byte) {
+	backoff := time.Second
+	for attempt := 1; attempt <= d.retries+1; attempt++ {
+		status, err := d.post(ctx, ep, evt, body)
+		ok := err == nil && status >= 200 && status < 300
+		d.record(ctx, ep, evt, attempt, status, err, ok)
+		if ok {
+			return
+		}
+		if err == nil && status < 500 && status != http.StatusTooManyRequests {
+			logger.Warnf("webhook 投递被拒绝 endpoint=%s type=%s status=%d", ep.Name, evt.Type, status)
+			return
+		}
+		if attempt > d.retries {
+			logger.Warnf("webhook 投递失败 endpoint=%s type=%s attempts=%d status=%d err=%v", ep.Name, evt.Type, attempt, status, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, ep Endpoint, evt Event, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, evt.Type)
+	req.Header.Set(HeaderDelivery, evt.ID)
+	req.Header.Set(HeaderTimestamp, ts)
+	if ep.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(ep.Secret, ts, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) record(ctx context.Context, ep Endpoint, evt Event, attempt, status int, err error, ok bool) {
+	if d.log == nil {
+		return
+	}
+	rec := database.WebhookDeliveryRecord{
+		EventID:    evt.ID,
+		EventType:  evt.Type,
+		Symbol:     evt.Symbol,
+		TradeID:    evt.TradeID,
+		Endpoint:   ep.Name,
+		URL:        ep.URL,
+		Attempt:    attempt,
+		StatusCode: status,
+		Success:    ok,
+		CreatedAt:  time.Now(),
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	} else if !ok {
+		rec.Error = fmt.Sprintf("unexpected status %d", status)
+	}
+	if logErr := d.log.AppendWebhookDelivery(context.WithoutCancel(ctx), rec); logErr != nil {
+		logger.Warnf("webhook 投递记录写入失败: %v", logErr)
+	}
+}
+
+// Sign 返回 HMAC-SHA256(secret, "<timestamp>.<body>") 的十六进制摘要，接收方按同样方式校验。
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliveries 查询投递记录。
+func (d *Dispatcher) Deliveries(ctx context.Context, q database.WebhookDeliveryQuery) ([]database.WebhookDeliveryRecord, error) {
+	if d == nil || d.log == nil {
+		return nil, fmt.Errorf("webhook 投递记录未启用")
+	}
+	return d.log.ListWebhookDeliveries(ctx, q)
+}
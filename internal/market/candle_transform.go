@@ -0,0 +1,160 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CandleType 为指标计算所用的 K 线形态。
+type CandleType string
+
+const (
+	CandleRaw        CandleType = "raw"
+	CandleHeikinAshi CandleType = "heikin_ashi"
+	CandleRenko      CandleType = "renko"
+)
+
+// ParseCandleType 解析配置中的 candle_type；空值视为原始 K 线，支持 ha / heikin-ashi 等简写。
+func ParseCandleType(raw string) (CandleType, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "raw", "candles", "ohlc":
+		return CandleRaw, nil
+	case "ha", "heikin_ashi", "heikin-ashi", "heikinashi":
+		return CandleHeikinAshi, nil
+	case "renko":
+		return CandleRenko, nil
+	default:
+		return "", fmt.Errorf("未知 candle_type: %s（raw/heikin_ashi/renko）", raw)
+	}
+}
+
+// CandleTransform 描述 K 线变换参数。Renko 砖块优先使用固定 BrickSize，否则取 ATR(ATRPeriod)*ATRMultiplier。
+type CandleTransform struct {
+	Type          CandleType
+	BrickSize     float64
+	ATRPeriod     int
+	ATRMultiplier float64
+}
+
+// Apply 按配置变换 K 线；原始类型直接返回副本。
+func (t CandleTransform) Apply(candles []Candle) []Candle {
+	switch t.Type {
+	case CandleHeikinAshi:
+		return HeikinAshi(candles)
+	case CandleRenko:
+		brick := t.BrickSize
+		if brick <= 0 {
+			brick = RenkoATRBrick(candles, t.ATRPeriod, t.ATRMultiplier)
+		}
+		return Renko(candles, brick)
+	default:
+		out := make([]Candle, len(candles))
+		copy(out, candles)
+		return out
+	}
+}
+
+// HeikinAshi 生成平均 K 线：close=(O+H+L+C)/4，open=前一根 HA 的 (open+close)/2，
+// high/low 取原始高低与 HA 开收的极值；时间与成交量沿用原 K 线。
+func HeikinAshi(candles []Candle) []Candle {
+	out := make([]Candle, len(candles))
+	for i, c := range candles {
+		ha := c
+		ha.Close = (c.Open + c.High + c.Low + c.Close) / 4
+		if i == 0 {
+			ha.Open = (c.Open + c.Close) / 2
+		} else {
+			ha.Open = (out[i-1].Open + out[i-1].Close) / 2
+		}
+		ha.High = math.Max(c.High, math.Max(ha.Open, ha.Close))
+		ha.Low = math.Min(c.Low, math.Min(ha.Open, ha.Close))
+		out[i] = ha
+	}
+	return out
+}
+
+// Renko 按收盘价生成固定砖块：价格较上一块收盘偏离一个砖块即新增一块，反转需偏离两个砖块。
+// 每块的时间取完成该块的原 K 线，成交量为自上一块以来的累计量（同一根 K 线生成多块时记在第一块）。
+func Renko(candles []Candle, brick float64) []Candle {
+	if len(candles) == 0 || brick <= 0 || math.IsNaN(brick) || math.IsInf(brick, 0) {
+		return nil
+	}
+	out := make([]Candle, 0, len(candles))
+	last := math.Floor(candles[0].Close/brick) * brick
+	dir := 0
+	var volume, takerBuy, takerSell float64
+	var trades int64
+	for _, c := range candles[1:] {
+		volume += c.Volume
+		takerBuy += c.TakerBuyVolume
+		takerSell += c.TakerSellVolume
+		trades += c.Trades
+		for {
+			var open, closePrice float64
+			switch {
+			case c.Close >= last+brick && dir >= 0:
+				open, closePrice = last, last+brick
+			case c.Close <= last-brick && dir <= 0:
+				open, closePrice = last, last-brick
+			case dir > 0 && c.Close <= last-2*brick:
+				open, closePrice = last-brick, last-2*brick
+			case dir < 0 && c.Close >= last+2*brick:
+				open, closePrice = last+brick, last+2*brick
+			default:
+				open, closePrice = math.NaN(), math.NaN()
+			}
+			if math.IsNaN(closePrice) {
+				break
+			}
+			out = append(out, Candle{
+				OpenTime:        c.OpenTime,
+				CloseTime:       c.CloseTime,
+				Open:            open,
+				High:            math.Max(open, closePrice),
+				Low:             math.Min(open, closePrice),
+				Close:           closePrice,
+				Volume:          volume,
+				TakerBuyVolume:  takerBuy,
+				TakerSellVolume: takerSell,
+				Trades:          trades,
+			})
+			volume, takerBuy, takerSell, trades = 0, 0, 0, 0
+			if closePrice > open {
+				dir = 1
+			} else {
+				dir = -1
+			}
+			last = closePrice
+		}
+	}
+	return out
+}
+
+// RenkoATRBrick 以 Wilder ATR 的最新值乘以倍数作为砖块大小；period 默认 14，mult 默认 1。
+func RenkoATRBrick(candles []Candle, period int, mult float64) float64 {
+	if period <= 0 {
+		period = 14
+	}
+	if mult <= 0 {
+		mult = 1
+	}
+	if len(candles) <= period {
+		return 0
+	}
+	var atr float64
+	for i := 1; i < len(candles); i++ {
+		prev := candles[i-1].Close
+		c := candles[i]
+		tr := math.Max(c.High-c.Low, math.Max(math.Abs(c.High-prev), math.Abs(c.Low-prev)))
+		switch {
+		case i < period:
+			atr += tr
+		case i == period:
+			atr = (atr + tr) / float64(period)
+		default:
+			atr = (atr*float64(period-1) + tr) / float64(period)
+		}
+	}
+	return atr * mult
+}
@@ -0,0 +1,173 @@
+package market
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+)
+
+const (
+	defaultFundingPoll   = 5 * time.Minute
+	defaultFundingWindow = 7 * 24 * time.Hour
+	fundingFetchTimeout  = 10 * time.Second
+)
+
+// FundingInfo 为永续合约的资金费率信息；Rate 为最近一次结算的费率，PredictedRate 为下次结算的预测费率，
+// NextFundingTime 为零值表示行情源未提供。
+type FundingInfo struct {
+	Symbol          string    `json:"symbol"`
+	Rate            float64   `json:"rate"`
+	PredictedRate   float64   `json:"predicted_rate"`
+	NextFundingTime time.Time `json:"next_funding_time"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// FundingInfoProvider 提供含预测费率与下次结算时间的资金费率（如 Binance premiumIndex）；
+// 不支持的行情源退化为 Source.GetFundingRate。
+type FundingInfoProvider interface {
+	FundingInfo(ctx context.Context, symbol string) (FundingInfo, error)
+}
+
+// FundingReader 返回 symbol 最新的资金费率及其在全部监控 symbol 近期费率样本中的分位（0~1）。
+type FundingReader interface {
+	LatestFunding(symbol string) (FundingInfo, bool)
+	FundingPercentile(rate float64) (float64, int)
+}
+
+type fundingSample struct {
+	at   time.Time
+	rate float64
+}
+
+// FundingTracker 定期轮询监控 symbol 的资金费率，保留 window 内的样本用于计算极端费率分位。
+type FundingTracker struct {
+	source   Source
+	provider FundingInfoProvider
+	symbols  []string
+	poll     time.Duration
+	window   time.Duration
+
+	mu      sync.RWMutex
+	latest  map[string]FundingInfo
+	samples []fundingSample
+}
+
+// NewFundingTracker 在 symbols 为空时返回 nil；poll/window<=0 时分别为 5 分钟与 7 天。
+func NewFundingTracker(src Source, symbols []string, poll, window time.Duration) *FundingTracker {
+	if src == nil || len(symbols) == 0 {
+		return nil
+	}
+	if poll <= 0 {
+		poll = defaultFundingPoll
+	}
+	if window <= 0 {
+		window = defaultFundingWindow
+	}
+	t := &FundingTracker{
+		source:  src,
+		symbols: append([]string(nil), symbols...),
+		poll:    poll,
+		window:  window,
+		latest:  make(map[string]FundingInfo, len(symbols)),
+	}
+	if provider, ok := src.(FundingInfoProvider); ok {
+		t.provider = provider
+	}
+	return t
+}
+
+func (t *FundingTracker) Start(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(t.poll)
+		defer ticker.Stop()
+		for {
+			t.refresh(ctx, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (t *FundingTracker) refresh(ctx context.Context, now time.Time) {
+	fresh := make([]FundingInfo, 0, len(t.symbols))
+	for _, sym := range t.symbols {
+		fetchCtx, cancel := context.WithTimeout(ctx, fundingFetchTimeout)
+		info, err := t.fetch(fetchCtx, sym)
+		cancel()
+		if err != nil {
+			logger.Debugf("FundingTracker: 获取 %s 资金费率失败: %v", sym, err)
+			continue
+		}
+		info.Symbol = sym
+		info.UpdatedAt = now
+		fresh = append(fresh, info)
+	}
+	cutoff := now.Add(-t.window)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, info := range fresh {
+		t.latest[fundingKey(info.Symbol)] = info
+		t.samples = append(t.samples, fundingSample{at: now, rate: info.PredictedRate})
+	}
+	kept := t.samples[:0]
+	for _, s := range t.samples {
+		if s.at.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	t.samples = kept
+}
+
+func (t *FundingTracker) fetch(ctx context.Context, symbol string) (FundingInfo, error) {
+	if t.provider != nil {
+		return t.provider.FundingInfo(ctx, symbol)
+	}
+	rate, err := t.source.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return FundingInfo{}, err
+	}
+	return FundingInfo{Rate: rate, PredictedRate: rate}, nil
+}
+
+func (t *FundingTracker) LatestFunding(symbol string) (FundingInfo, bool) {
+	if t == nil {
+		return FundingInfo{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	info, ok := t.latest[fundingKey(symbol)]
+	return info, ok
+}
+
+// FundingPercentile 返回 rate 在样本中的分位（不大于 rate 的样本占比）与样本数；样本为空时返回 (0.5, 0)。
+func (t *FundingTracker) FundingPercentile(rate float64) (float64, int) {
+	if t == nil {
+		return 0.5, 0
+	}
+	t.mu.RLock()
+	rates := make([]float64, len(t.samples))
+	for i, s := range t.samples {
+		rates[i] = s.rate
+	}
+	t.mu.RUnlock()
+	if len(rates) == 0 {
+		return 0.5, 0
+	}
+	sort.Float64s(rates)
+	n := sort.Search(len(rates), func(i int) bool { return rates[i] > rate })
+	return float64(n) / float64(len(rates)), len(rates)
+}
+
+func fundingKey(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,145 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+)
+
+// maxBackfillBars 为单个 symbol/interval 一次补数据的上限，与交易所单次 kline 接口上限一致。
+const maxBackfillBars = 1500
+
+// GapFillSummary 为一次断线恢复后的补数据结果。
+type GapFillSummary struct {
+	Streams   int           // 检查的 symbol×interval 数
+	Gapped    int           // 存在缺口的 stream 数
+	Missing   int           // 检测到的缺失根数
+	Recovered int           // 实际补回的根数
+	Failed    []string      // 补数据失败的 stream（SYMBOL@interval）
+	Duration  time.Duration // 补数据耗时
+}
+
+// DetectKlineGap 按周期节奏统计缓存中缺失的已收盘 K 线根数：包括相邻 K 线之间的空洞，
+// 以及最后一根 CloseTime 与 now 之前最近一个已收盘周期之间的缺口。interval 无法解析时返回 0。
+func DetectKlineGap(candles []Candle, interval string, now time.Time) int {
+	step := intervalMillis(interval)
+	if step <= 0 || len(candles) == 0 {
+		return 0
+	}
+	missing := 0
+	for i := 1; i < len(candles); i++ {
+		if diff := candles[i].OpenTime - candles[i-1].OpenTime; diff > step {
+			missing += int(diff/step) - 1
+		}
+	}
+	// 最近一个已收盘周期的 CloseTime（交易所约定为下一根 OpenTime-1）。
+	expectedClose := now.UnixMilli()/step*step - 1
+	if last := candles[len(candles)-1].CloseTime; expectedClose > last {
+		missing += int((expectedClose - last) / step)
+	}
+	return missing
+}
+
+// Backfill 比对缓存与周期节奏，对存在缺口的 symbol/interval 通过 FetchHistory 补齐缺失 K 线。
+// 缓存为空的 stream 交给预热逻辑处理，不在此补数据。
+func (u *WSUpdater) Backfill(ctx context.Context, symbols, intervals []string) GapFillSummary {
+	start := time.Now()
+	var sum GapFillSummary
+	if u.Store == nil || u.Source == nil {
+		return sum
+	}
+	for _, sym := range symbols {
+		symbol := strings.ToUpper(strings.TrimSpace(sym))
+		for _, iv := range intervals {
+			if ctx.Err() != nil {
+				sum.Duration = time.Since(start)
+				return sum
+			}
+			sum.Streams++
+			before, err := u.Store.Get(ctx, symbol, iv)
+			if err != nil || len(before) == 0 {
+				continue
+			}
+			missing := DetectKlineGap(before, iv, time.Now())
+			if missing <= 0 {
+				continue
+			}
+			sum.Gapped++
+			sum.Missing += missing
+			recovered, err := u.fillGap(ctx, symbol, iv, before, missing)
+			if err != nil {
+				logger.Warnf("[WS] 补数据 %s %s 失败 (缺 %d 根): %v", symbol, iv, missing, err)
+				sum.Failed = append(sum.Failed, symbol+"@"+iv)
+				continue
+			}
+			sum.Recovered += recovered
+			logger.Infof("[WS] 补数据 %s %s 缺 %d 根，补回 %d 根", symbol, iv, missing, recovered)
+		}
+	}
+	sum.Duration = time.Since(start)
+	return sum
+}
+
+// fillGap 拉取覆盖缺口的最新 K 线写入缓存，返回缓存中原本不存在的根数。
+func (u *WSUpdater) fillGap(ctx context.Context, symbol, interval string, before []Candle, missing int) (int, error) {
+	// 中间空洞可能位于缓存较早的位置，拉取长度覆盖整个缓存窗口以便一并补齐。
+	limit := len(before) + missing + 1
+	if limit > maxBackfillBars {
+		limit = maxBackfillBars
+	}
+	batch, err := u.Source.FetchHistory(ctx, symbol, interval, limit)
+	if err != nil {
+		return 0, err
+	}
+	if len(batch) == 0 {
+		return 0, fmt.Errorf("返回空数据")
+	}
+	seen := make(map[int64]struct{}, len(before))
+	for _, c := range before {
+		seen[c.OpenTime] = struct{}{}
+	}
+	keep := u.Max
+	if keep < len(before) {
+		keep = len(before)
+	}
+	if err := u.Store.Put(ctx, symbol, interval, batch, keep); err != nil {
+		return 0, err
+	}
+	oldest := before[0].OpenTime
+	recovered := 0
+	for _, c := range batch {
+		if _, ok := seen[c.OpenTime]; !ok && c.OpenTime > oldest {
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+func intervalMillis(interval string) int64 {
+	interval = strings.ToLower(strings.TrimSpace(interval))
+	if len(interval) < 2 {
+		return 0
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0
+	}
+	var unit time.Duration
+	switch interval[len(interval)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+	return (time.Duration(n) * unit).Milliseconds()
+}
@@ -0,0 +1,196 @@
+package market
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+)
+
+// OrderBookLevel 为盘口一档（价格与数量）。
+type OrderBookLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// OrderBook 为部分深度快照：Bids 价格从高到低，Asks 价格从低到高。
+type OrderBook struct {
+	Symbol    string
+	Bids      []OrderBookLevel
+	Asks      []OrderBookLevel
+	EventTime int64
+}
+
+// OrderBookProvider 提供部分深度推送（如 Binance @depth20）。
+type OrderBookProvider interface {
+	SubscribeDepth(ctx context.Context, symbols []string, opts SubscribeOptions) (<-chan OrderBook, error)
+}
+
+// OrderBookReader 返回 symbol 最近一次收到的盘口快照。
+type OrderBookReader interface {
+	LatestOrderBook(symbol string) (OrderBook, bool)
+}
+
+// OrderBookWall 为距当前价 band 内、数量明显高于同侧平均的挂单。
+type OrderBookWall struct {
+	Price       float64 `json:"price"`
+	Quantity    float64 `json:"quantity"`
+	Notional    float64 `json:"notional"`
+	DistancePct float64 `json:"distance_pct"`
+	Multiple    float64 `json:"multiple"`
+}
+
+// OrderBookMetrics 为盘口派生指标：Imbalance=(买量-卖量)/(买量+卖量)，只统计距中间价 BandPct 以内的档位；
+// SpreadBps 为买一卖一价差（基点）；BidWall/AskWall 为 band 内数量最大且不低于同侧均值 WallMultiple 倍的挂单。
+type OrderBookMetrics struct {
+	BestBid      float64        `json:"best_bid"`
+	BestAsk      float64        `json:"best_ask"`
+	Mid          float64        `json:"mid"`
+	SpreadBps    float64        `json:"spread_bps"`
+	BandPct      float64        `json:"band_pct"`
+	BidDepth     float64        `json:"bid_depth"`
+	AskDepth     float64        `json:"ask_depth"`
+	Imbalance    float64        `json:"imbalance"`
+	WallMultiple float64        `json:"wall_multiple"`
+	BidWall      *OrderBookWall `json:"bid_wall,omitempty"`
+	AskWall      *OrderBookWall `json:"ask_wall,omitempty"`
+	AgeSec       int64          `json:"age_sec"`
+}
+
+const (
+	DefaultOrderBookBandPct      = 0.5
+	DefaultOrderBookWallMultiple = 3.0
+)
+
+// ComputeOrderBookMetrics 以买一卖一中间价为参考价计算盘口指标；bandPct/wallMultiple<=0 时取默认值。
+func ComputeOrderBookMetrics(book OrderBook, bandPct, wallMultiple float64, now time.Time) (OrderBookMetrics, bool) {
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return OrderBookMetrics{}, false
+	}
+	if bandPct <= 0 {
+		bandPct = DefaultOrderBookBandPct
+	}
+	if wallMultiple <= 0 {
+		wallMultiple = DefaultOrderBookWallMultiple
+	}
+	bestBid, bestAsk := book.Bids[0].Price, book.Asks[0].Price
+	if bestBid <= 0 || bestAsk <= 0 {
+		return OrderBookMetrics{}, false
+	}
+	mid := (bestBid + bestAsk) / 2
+	out := OrderBookMetrics{
+		BestBid:      bestBid,
+		BestAsk:      bestAsk,
+		Mid:          mid,
+		SpreadBps:    (bestAsk - bestBid) / mid * 10000,
+		BandPct:      bandPct,
+		WallMultiple: wallMultiple,
+	}
+	if book.EventTime > 0 {
+		out.AgeSec = max(int64(now.Sub(time.UnixMilli(book.EventTime)).Seconds()), 0)
+	}
+	var bidWall, askWall *OrderBookWall
+	out.BidDepth, bidWall = bookSide(book.Bids, mid, bandPct, wallMultiple)
+	out.AskDepth, askWall = bookSide(book.Asks, mid, bandPct, wallMultiple)
+	out.BidWall, out.AskWall = bidWall, askWall
+	if total := out.BidDepth + out.AskDepth; total > 0 {
+		out.Imbalance = (out.BidDepth - out.AskDepth) / total
+	}
+	return out, true
+}
+
+// bookSide 汇总一侧 band 内的挂单量，并找出最大的挂单墙。
+func bookSide(levels []OrderBookLevel, mid, bandPct, wallMultiple float64) (float64, *OrderBookWall) {
+	var depth float64
+	var n int
+	var top OrderBookLevel
+	for _, lv := range levels {
+		if lv.Price <= 0 || lv.Quantity <= 0 {
+			continue
+		}
+		if math.Abs(lv.Price-mid)/mid*100 > bandPct {
+			continue
+		}
+		depth += lv.Quantity
+		n++
+		if lv.Quantity > top.Quantity {
+			top = lv
+		}
+	}
+	if n < 2 {
+		return depth, nil
+	}
+	avg := depth / float64(n)
+	if avg <= 0 || top.Quantity < avg*wallMultiple {
+		return depth, nil
+	}
+	return depth, &OrderBookWall{
+		Price:       top.Price,
+		Quantity:    top.Quantity,
+		Notional:    top.Price * top.Quantity,
+		DistancePct: (top.Price - mid) / mid * 100,
+		Multiple:    top.Quantity / avg,
+	}
+}
+
+// OrderBookTracker 订阅部分深度并保留每个 symbol 的最新快照，供中间件与快照读取。
+type OrderBookTracker struct {
+	provider OrderBookProvider
+	symbols  []string
+
+	mu    sync.RWMutex
+	books map[string]OrderBook
+}
+
+// NewOrderBookTracker 在行情源不支持深度推送或 symbols 为空时返回 nil。
+func NewOrderBookTracker(src Source, symbols []string) *OrderBookTracker {
+	provider, ok := src.(OrderBookProvider)
+	if !ok || len(symbols) == 0 {
+		return nil
+	}
+	return &OrderBookTracker{
+		provider: provider,
+		symbols:  append([]string(nil), symbols...),
+		books:    make(map[string]OrderBook, len(symbols)),
+	}
+}
+
+func (t *OrderBookTracker) Start(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	events, err := t.provider.SubscribeDepth(ctx, t.symbols, SubscribeOptions{})
+	if err != nil {
+		logger.Warnf("OrderBook: 订阅深度失败: %v", err)
+		return
+	}
+	logger.Infof("OrderBook: 已订阅 %d 个交易对的部分深度", len(t.symbols))
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case book, ok := <-events:
+				if !ok {
+					return
+				}
+				t.mu.Lock()
+				t.books[strings.ToUpper(book.Symbol)] = book
+				t.mu.Unlock()
+			}
+		}
+	}()
+}
+
+func (t *OrderBookTracker) LatestOrderBook(symbol string) (OrderBook, bool) {
+	if t == nil {
+		return OrderBook{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	book, ok := t.books[strings.ToUpper(strings.TrimSpace(symbol))]
+	return book, ok
+}
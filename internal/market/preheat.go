@@ -33,6 +33,11 @@ func (p *Preheater) Preheat(ctx context.Context, symbols, intervals []string, li
 	}
 	for _, sym := range symbols {
 		for _, iv := range intervals {
+			// 已从磁盘快照加载且补齐缺口的 stream 无需整窗重拉。
+			if cur, err := p.Store.Get(ctx, sym, iv); err == nil && len(cur) >= limit && DetectKlineGap(cur, iv, time.Now()) == 0 {
+				logger.Debugf("[预热] %s %s 缓存已就绪（%d 根），跳过", sym, iv, len(cur))
+				continue
+			}
 			batch, err := p.Source.FetchHistory(ctx, sym, iv, limit)
 			if err != nil {
 				logger.Errorf("[预热] 获取 %s %s 失败: %v", sym, iv, err)
@@ -89,7 +94,8 @@ func (p *Preheater) Warmup(ctx context.Context, symbols []string, lookbacks map[
 					logger.Infof("[warmup] %s %s ready (%d/%d)", sym, tf, len(cur), needBars)
 					break
 				}
-				limit := needBars - len(cur)
+				// FetchHistory 返回最新的 limit 根，缓存按 openTime 去重，因此需一次拉满目标条数。
+				limit := needBars
 				if limit < 50 {
 					limit = 50
 				}
@@ -113,7 +119,12 @@ func (p *Preheater) Warmup(ctx context.Context, symbols []string, lookbacks map[
 					logger.Warnf("[warmup] 写入 %s %s 失败: %v", sym, tf, err)
 					break
 				}
-				logger.Debugf("[warmup] %s %s 拉取 %d 条，目前=%d/%d", sym, tf, len(batch), len(cur)+len(batch), needBars)
+				after, _ := p.Store.Get(ctx, sym, tf)
+				logger.Debugf("[warmup] %s %s 拉取 %d 条，目前=%d/%d", sym, tf, len(batch), len(after), needBars)
+				if len(after) <= len(cur) {
+					logger.Warnf("[warmup] %s %s 无法获取更多历史（%d/%d），以现有数据继续", sym, tf, len(after), needBars)
+					break
+				}
 			}
 		}
 	}
@@ -0,0 +1,104 @@
+package market
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekAnchorMillis 把周线桶对齐到周一 00:00 UTC（1970-01-01 为周四），与交易所周线一致。
+const weekAnchorMillis = int64(3 * 24 * time.Hour / time.Millisecond)
+
+// ResampleSource 在 available 中选出能整除 target 的最大周期作为重采样源，返回源周期与倍数；
+// target 本身在 available 中或没有可整除的周期时返回 false。
+func ResampleSource(target string, available []string) (string, int, bool) {
+	targetMs := intervalMillis(target)
+	if targetMs <= 0 {
+		return "", 0, false
+	}
+	best, bestMs := "", int64(0)
+	for _, iv := range available {
+		iv = strings.ToLower(strings.TrimSpace(iv))
+		ms := intervalMillis(iv)
+		if ms == targetMs {
+			return "", 0, false
+		}
+		if ms <= 0 || ms > targetMs || targetMs%ms != 0 || !alignedBuckets(iv, target) {
+			continue
+		}
+		if ms > bestMs {
+			best, bestMs = iv, ms
+		}
+	}
+	if best == "" {
+		return "", 0, false
+	}
+	return best, int(targetMs / bestMs), true
+}
+
+// alignedBuckets 判断源周期的 K 线边界是否落在目标周期的桶边界上：周线桶从周一开始，只能由不超过 1d 的周期合成。
+func alignedBuckets(source, target string) bool {
+	if !strings.HasSuffix(target, "w") {
+		return true
+	}
+	return !strings.HasSuffix(source, "w") && intervalMillis(source) <= int64(24*time.Hour/time.Millisecond)
+}
+
+// Resample 把 source 周期的 K 线按 UTC 对齐的 target 周期聚合：开盘取桶内首根、收盘取末根、高低取极值、成交量与笔数累加。
+// 开头不完整的桶（历史从桶中间开始）被丢弃；末尾的桶即使不完整也保留，对应交易所返回的当前未收盘 K 线。
+func Resample(candles []Candle, source, target string) ([]Candle, error) {
+	srcMs, dstMs := intervalMillis(source), intervalMillis(target)
+	if srcMs <= 0 || dstMs <= 0 {
+		return nil, fmt.Errorf("无效周期: %s -> %s", source, target)
+	}
+	if dstMs < srcMs || dstMs%srcMs != 0 || !alignedBuckets(source, target) {
+		return nil, fmt.Errorf("%s 无法由 %s 重采样", target, source)
+	}
+	if len(candles) == 0 {
+		return nil, nil
+	}
+	anchor := int64(0)
+	if strings.HasSuffix(target, "w") {
+		anchor = weekAnchorMillis
+	}
+	bucketOf := func(openTime int64) int64 {
+		shifted := openTime + anchor
+		start := shifted - ((shifted%dstMs)+dstMs)%dstMs
+		return start - anchor
+	}
+	out := make([]Candle, 0, len(candles)*int(srcMs)/int(dstMs)+1)
+	var cur Candle
+	for i, c := range candles {
+		start := bucketOf(c.OpenTime)
+		if i == 0 || start != cur.OpenTime {
+			if i > 0 {
+				out = append(out, cur)
+			}
+			cur = Candle{
+				OpenTime:        start,
+				CloseTime:       start + dstMs - 1,
+				Open:            c.Open,
+				High:            c.High,
+				Low:             c.Low,
+				Close:           c.Close,
+				Volume:          c.Volume,
+				TakerBuyVolume:  c.TakerBuyVolume,
+				TakerSellVolume: c.TakerSellVolume,
+				Trades:          c.Trades,
+			}
+			continue
+		}
+		cur.High = max(cur.High, c.High)
+		cur.Low = min(cur.Low, c.Low)
+		cur.Close = c.Close
+		cur.Volume += c.Volume
+		cur.TakerBuyVolume += c.TakerBuyVolume
+		cur.TakerSellVolume += c.TakerSellVolume
+		cur.Trades += c.Trades
+	}
+	out = append(out, cur)
+	if candles[0].OpenTime != bucketOf(candles[0].OpenTime) {
+		out = out[1:]
+	}
+	return out, nil
+}
@@ -64,3 +64,24 @@ type Source interface {
 
 	Close() error
 }
+
+// ReferencePriceProvider 提供交叉校验用的参考价格（指数价或最新成交价）。
+type ReferencePriceProvider interface {
+	IndexPrice(ctx context.Context, symbol string) (float64, error)
+	LastPrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// LiquidationEvent 为全市场强平推送（Notional = 成交均价 × 成交数量）。
+type LiquidationEvent struct {
+	Symbol    string
+	Side      string
+	Price     float64
+	Quantity  float64
+	Notional  float64
+	TradeTime int64
+}
+
+// LiquidationProvider 提供全市场强平流，用于波动熔断等风控判断。
+type LiquidationProvider interface {
+	SubscribeLiquidations(ctx context.Context, opts SubscribeOptions) (<-chan LiquidationEvent, error)
+}
@@ -0,0 +1,64 @@
+package market
+
+import (
+	"sort"
+	"strings"
+
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+// StreamPriority 返回 symbol 的订阅优先级，数值越大越优先保留。
+type StreamPriority func(symbol string) int
+
+// PrioritizeSymbols 按「持仓 symbol → profile 优先级 → 原始顺序」排序，
+// 当 len(symbols)*perSymbol 超过 limit 时淘汰末尾的低优先级 symbol。
+// 持仓 symbol 即使超出 limit 也始终保留；limit<=0 表示不限制。
+func PrioritizeSymbols(symbols []string, held map[string]bool, priority StreamPriority, perSymbol, limit int) (kept, evicted []string) {
+	if perSymbol <= 0 {
+		perSymbol = 1
+	}
+	type ranked struct {
+		symbol string
+		held   bool
+		prio   int
+		order  int
+	}
+	list := make([]ranked, 0, len(symbols))
+	for i, sym := range symbols {
+		r := ranked{symbol: sym, order: i}
+		r.held = held[StreamKey(sym)]
+		if priority != nil {
+			r.prio = priority(sym)
+		}
+		list = append(list, r)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].held != list[j].held {
+			return list[i].held
+		}
+		if list[i].prio != list[j].prio {
+			return list[i].prio > list[j].prio
+		}
+		return list[i].order < list[j].order
+	})
+	maxSymbols := len(list)
+	if limit > 0 {
+		maxSymbols = limit / perSymbol
+	}
+	for _, r := range list {
+		if r.held || len(kept) < maxSymbols {
+			kept = append(kept, r.symbol)
+			continue
+		}
+		evicted = append(evicted, r.symbol)
+	}
+	return kept, evicted
+}
+
+// StreamKey 统一 symbol 写法（BTCUSDT / BTC/USDT:USDT → BTC/USDT），用于持仓与订阅列表比对。
+func StreamKey(symbol string) string {
+	if norm := symbolpkg.Normalize(symbol); norm != "" {
+		return norm
+	}
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,295 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+// SymbolInfo 为交易所对 symbol 的下单规则；各字段为 0 表示交易所未提供、对应检查跳过。
+type SymbolInfo struct {
+	Symbol      string  `json:"symbol"`
+	TickSize    float64 `json:"tick_size"`
+	StepSize    float64 `json:"step_size"`
+	MinQty      float64 `json:"min_qty"`
+	MinNotional float64 `json:"min_notional"`
+	MaxLeverage float64 `json:"max_leverage,omitempty"`
+	// Brackets 为按名义价值分档的杠杆上限（如 Binance leverageBracket），为空时只按 MaxLeverage 封顶。
+	Brackets []LeverageBracket `json:"brackets,omitempty"`
+}
+
+// LeverageBracket 为一档名义价值区间 [NotionalFloor, NotionalCap) 内允许的最大杠杆。
+type LeverageBracket struct {
+	NotionalFloor float64 `json:"notional_floor"`
+	NotionalCap   float64 `json:"notional_cap"`
+	MaxLeverage   float64 `json:"max_leverage"`
+}
+
+// SymbolInfoProvider 提供全市场的下单规则（如 Binance /fapi/v1/exchangeInfo）。
+type SymbolInfoProvider interface {
+	SymbolInfos(ctx context.Context) ([]SymbolInfo, error)
+}
+
+// LeverageBracketProvider 提供全市场的杠杆档位表（如 Binance /fapi/v1/leverageBracket，需签名接口），键为交易所 symbol。
+type LeverageBracketProvider interface {
+	LeverageBrackets(ctx context.Context) (map[string][]LeverageBracket, error)
+}
+
+// RoundPrice 把价格取整到最近的价格步长。
+func (i SymbolInfo) RoundPrice(price float64) float64 {
+	if i.TickSize <= 0 || price <= 0 {
+		return price
+	}
+	return roundToStep(math.Round(price/i.TickSize)*i.TickSize, i.TickSize)
+}
+
+// FloorQty 把数量向下取整到数量步长。
+func (i SymbolInfo) FloorQty(qty float64) float64 {
+	if i.StepSize <= 0 || qty <= 0 {
+		return qty
+	}
+	return roundToStep(math.Floor(qty/i.StepSize+1e-9)*i.StepSize, i.StepSize)
+}
+
+// ClampLeverage 返回不超过 leverage、且 stake×杠杆 所在档位允许的最大整数杠杆；没有档位时按 MaxLeverage 封顶。
+// 逐档取 min(leverage, 档位最大杠杆, 档位上限/stake)，名义价值需落在该档内才有效，取所有档位中的最大值。
+func (i SymbolInfo) ClampLeverage(stake, leverage float64) float64 {
+	if stake <= 0 || leverage <= 0 {
+		return leverage
+	}
+	if len(i.Brackets) == 0 {
+		if i.MaxLeverage > 0 && leverage > i.MaxLeverage {
+			return math.Floor(i.MaxLeverage)
+		}
+		return leverage
+	}
+	best := 0.0
+	for _, b := range i.Brackets {
+		lev := leverage
+		if b.MaxLeverage > 0 {
+			lev = math.Min(lev, b.MaxLeverage)
+		}
+		if b.NotionalCap > 0 {
+			lev = math.Min(lev, b.NotionalCap/stake)
+		}
+		if stake*lev < b.NotionalFloor {
+			continue
+		}
+		best = math.Max(best, lev)
+	}
+	if best >= leverage {
+		return leverage
+	}
+	return math.Max(math.Floor(best+1e-9), 1)
+}
+
+// SizeOpen 按杠杆档位封顶杠杆，按数量步长向下取整 stake×杠杆/price 对应的数量并反推保证金，返回调整后的保证金与杠杆；
+// leverage<=0 时按 1 倍计算数量、杠杆原样返回；取整后低于最小数量或最小名义价值时返回错误。
+func (i SymbolInfo) SizeOpen(stake, leverage, price float64) (float64, float64, error) {
+	if stake <= 0 || price <= 0 {
+		return stake, leverage, nil
+	}
+	leverage = i.ClampLeverage(stake, leverage)
+	lev := math.Max(leverage, 1)
+	qty := i.FloorQty(stake * lev / price)
+	if qty <= 0 || (i.MinQty > 0 && qty < i.MinQty) {
+		return stake, leverage, fmt.Errorf("%s 数量 %.8f 低于最小下单量 %.8f", i.Symbol, qty, i.MinQty)
+	}
+	notional := qty * price
+	if i.MinNotional > 0 && notional < i.MinNotional {
+		return stake, leverage, fmt.Errorf("%s 名义价值 %.4f 低于交易所最小值 %.4f", i.Symbol, notional, i.MinNotional)
+	}
+	return notional / lev, leverage, nil
+}
+
+// roundToStep 按步长的小数位截掉浮点误差（如 0.1*3 → 0.3）。
+func roundToStep(v, step float64) float64 {
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+	}
+	out, err := strconv.ParseFloat(strconv.FormatFloat(v, 'f', decimals, 64), 64)
+	if err != nil {
+		return v
+	}
+	return out
+}
+
+// lookupRetryInterval 为 Lookup 缓存未命中时两次补拉之间的最短间隔，避免未知 symbol 反复整表拉取。
+const lookupRetryInterval = time.Minute
+
+// SymbolInfoService 缓存下单规则并定期整表刷新；刷新失败时保留上一次的结果。
+// 决策执行前的取整与直连执行器下单共用同一份缓存。
+type SymbolInfoService struct {
+	provider SymbolInfoProvider
+	interval time.Duration
+
+	mu        sync.RWMutex
+	infos     map[string]SymbolInfo
+	updatedAt time.Time
+	attemptAt time.Time
+
+	brackets LeverageBracketProvider
+}
+
+// NewSymbolInfoService 在 src 不支持 SymbolInfoProvider 时返回 nil；src 同时实现 LeverageBracketProvider 时每次刷新一并拉取杠杆档位。
+// interval<=0 时为 1 小时。
+func NewSymbolInfoService(src any, interval time.Duration) *SymbolInfoService {
+	provider, ok := src.(SymbolInfoProvider)
+	if !ok || provider == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	brackets, _ := src.(LeverageBracketProvider)
+	return &SymbolInfoService{provider: provider, interval: interval, infos: make(map[string]SymbolInfo), brackets: brackets}
+}
+
+// Start 立即刷新一次，之后按 interval 周期刷新，直到 ctx 结束。
+func (s *SymbolInfoService) Start(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	go func() {
+		if err := s.Refresh(ctx); err != nil {
+			logger.Warnf("SymbolInfoService: 首次拉取交易规则失败: %v", err)
+		}
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Refresh(ctx); err != nil {
+					logger.Warnf("SymbolInfoService: 刷新交易规则失败，沿用缓存: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Refresh 整表拉取交易规则并替换缓存。
+func (s *SymbolInfoService) Refresh(ctx context.Context) error {
+	if s == nil {
+		return fmt.Errorf("symbol info service not initialized")
+	}
+	list, err := s.provider.SymbolInfos(ctx)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("exchange returned no symbol info")
+	}
+	infos := make(map[string]SymbolInfo, len(list))
+	for _, info := range list {
+		if key := symbolInfoKey(info.Symbol); key != "" {
+			infos[key] = info
+		}
+	}
+	if s.brackets != nil {
+		for key, brackets := range s.bracketTable(ctx) {
+			info, ok := infos[key]
+			if !ok {
+				continue
+			}
+			info.Brackets = brackets
+			if info.MaxLeverage <= 0 {
+				for _, b := range brackets {
+					info.MaxLeverage = math.Max(info.MaxLeverage, b.MaxLeverage)
+				}
+			}
+			infos[key] = info
+		}
+	}
+	s.mu.Lock()
+	s.infos = infos
+	s.updatedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// bracketTable 拉取杠杆档位整表并按缓存键归一；失败时沿用上一次缓存中的档位。
+func (s *SymbolInfoService) bracketTable(ctx context.Context) map[string][]LeverageBracket {
+	table, err := s.brackets.LeverageBrackets(ctx)
+	out := make(map[string][]LeverageBracket, len(table))
+	if err == nil {
+		for sym, list := range table {
+			if key := symbolInfoKey(sym); key != "" && len(list) > 0 {
+				out[key] = list
+			}
+		}
+		return out
+	}
+	logger.Warnf("SymbolInfoService: 杠杆档位刷新失败，沿用缓存: %v", err)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for key, info := range s.infos {
+		if len(info.Brackets) > 0 {
+			out[key] = info.Brackets
+		}
+	}
+	return out
+}
+
+// SymbolInfo 返回缓存中的下单规则。
+func (s *SymbolInfoService) SymbolInfo(symbol string) (SymbolInfo, bool) {
+	if s == nil {
+		return SymbolInfo{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	info, ok := s.infos[symbolInfoKey(symbol)]
+	return info, ok
+}
+
+// Lookup 返回 symbol 的下单规则；缓存未命中（如首次拉取失败或新上线 symbol）时立即整表刷新一次，
+// 补拉间隔不少于 lookupRetryInterval。
+func (s *SymbolInfoService) Lookup(ctx context.Context, symbol string) (SymbolInfo, error) {
+	if s == nil {
+		return SymbolInfo{}, fmt.Errorf("symbol info service not initialized")
+	}
+	if info, ok := s.SymbolInfo(symbol); ok {
+		return info, nil
+	}
+	s.mu.Lock()
+	retry := time.Since(s.attemptAt) >= lookupRetryInterval
+	if retry {
+		s.attemptAt = time.Now()
+	}
+	s.mu.Unlock()
+	if retry {
+		if err := s.Refresh(ctx); err != nil {
+			return SymbolInfo{}, err
+		}
+		if info, ok := s.SymbolInfo(symbol); ok {
+			return info, nil
+		}
+	}
+	return SymbolInfo{}, fmt.Errorf("%s 缺少交易规则", symbol)
+}
+
+// UpdatedAt 返回最近一次成功刷新的时间。
+func (s *SymbolInfoService) UpdatedAt() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+func symbolInfoKey(symbol string) string {
+	if norm := symbolpkg.Normalize(symbol); norm != "" {
+		return norm
+	}
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -18,8 +18,21 @@ type WSUpdater struct {
 	OnDisconnected func(error)
 
 	OnEvent func(CandleEvent)
+	// OnBackfill 在断线重连后的补数据完成时回调，用于推送恢复摘要。
+	OnBackfill func(GapFillSummary)
 
-	startOnce sync.Once
+	// MaxStreams 为组合连接的 stream 上限（symbol×interval），<=0 不限制。
+	MaxStreams int
+	Priority   StreamPriority
+	// HeldSymbols 返回当前持仓 symbol（StreamKey 写法），其订阅不会被淘汰。
+	HeldSymbols func(context.Context) map[string]bool
+
+	mu           sync.Mutex
+	active       []string
+	intervals    []string
+	disconnected bool
+	// backfilling 非 nil 表示断线补数据进行中，完成时关闭。
+	backfilling chan struct{}
 }
 
 type WSUpdaterOption func(*WSUpdater)
@@ -58,21 +71,98 @@ func (u *WSUpdater) Start(ctx context.Context, symbols []string, intervals []str
 	if len(symbols) == 0 || len(intervals) == 0 {
 		return fmt.Errorf("ws updater requires symbols & intervals")
 	}
+	var held map[string]bool
+	if u.HeldSymbols != nil {
+		held = u.HeldSymbols(ctx)
+	}
+	kept, evicted := PrioritizeSymbols(symbols, held, u.Priority, len(intervals), u.MaxStreams)
+	if len(evicted) > 0 {
+		logger.Warnf("[WS] stream 数超出上限 %d，淘汰低优先级订阅: %v", u.MaxStreams, evicted)
+	}
 	opts := SubscribeOptions{
-		OnConnect:    u.OnConnected,
-		OnDisconnect: u.OnDisconnected,
+		OnConnect:    u.handleConnect(ctx),
+		OnDisconnect: u.handleDisconnect,
 	}
-	events, err := u.Source.Subscribe(ctx, symbols, intervals, opts)
+	// 重复调用会替换上一次订阅，旧 channel 由 Source 关闭后 consume 自然退出。
+	events, err := u.Source.Subscribe(ctx, kept, intervals, opts)
 	if err != nil {
 		return err
 	}
-	u.startOnce.Do(func() {
-		go u.consume(ctx, events)
-	})
-	logger.Infof("[WS] 订阅已启动 symbols=%v intervals=%v", symbols, intervals)
+	u.mu.Lock()
+	u.active = kept
+	u.intervals = append([]string(nil), intervals...)
+	u.mu.Unlock()
+	go u.consume(ctx, events)
+	logger.Infof("[WS] 订阅已启动 symbols=%v intervals=%v", kept, intervals)
 	return nil
 }
 
+// ActiveSymbols 返回当前实际订阅的 symbol。
+func (u *WSUpdater) ActiveSymbols() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.active...)
+}
+
+func (u *WSUpdater) handleDisconnect(err error) {
+	u.mu.Lock()
+	u.disconnected = true
+	u.mu.Unlock()
+	if u.OnDisconnected != nil {
+		u.OnDisconnected(err)
+	}
+}
+
+// handleConnect 在断线后重新连接时，先补齐断线期间缺失的 K 线，再通知 OnBackfill；补数据期间 WaitBackfill 阻塞。
+func (u *WSUpdater) handleConnect(ctx context.Context) func() {
+	return func() {
+		if u.OnConnected != nil {
+			u.OnConnected()
+		}
+		u.mu.Lock()
+		if !u.disconnected || u.backfilling != nil {
+			u.mu.Unlock()
+			return
+		}
+		u.disconnected = false
+		done := make(chan struct{})
+		u.backfilling = done
+		symbols := append([]string(nil), u.active...)
+		intervals := append([]string(nil), u.intervals...)
+		u.mu.Unlock()
+		go func() {
+			defer func() {
+				u.mu.Lock()
+				u.backfilling = nil
+				u.mu.Unlock()
+				close(done)
+			}()
+			sum := u.Backfill(ctx, symbols, intervals)
+			logger.Infof("[WS] 重连补数据完成 streams=%d gapped=%d missing=%d recovered=%d failed=%d duration=%s",
+				sum.Streams, sum.Gapped, sum.Missing, sum.Recovered, len(sum.Failed), sum.Duration)
+			if u.OnBackfill != nil && sum.Gapped > 0 {
+				u.OnBackfill(sum)
+			}
+		}()
+	}
+}
+
+// WaitBackfill 在断线补数据进行中时阻塞至完成或 ctx 结束，避免基于残缺 K 线做分析。
+func (u *WSUpdater) WaitBackfill(ctx context.Context) error {
+	u.mu.Lock()
+	done := u.backfilling
+	u.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (u *WSUpdater) consume(ctx context.Context, events <-chan CandleEvent) {
 	for {
 		select {
@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"brale/internal/logger"
+)
+
+// CapabilityCandles 表示 AnalysisContext 中已写入 K 线（由 kline_fetcher 提供）。
+const CapabilityCandles = "candles"
+
+// OrderByDependencies 校验每个中间件的 Requires 都有其它中间件 Provides，缺失时返回错误；
+// 依赖方的 Stage 不晚于任一提供方时自动调整为「提供方最大 Stage + 1」，配置的 Stage 只会被推后、不会提前。
+// 依赖成环时返回错误。
+func OrderByDependencies(mws []Middleware) ([]Middleware, error) {
+	providers := make(map[string][]int)
+	for i, mw := range mws {
+		if mw == nil {
+			continue
+		}
+		for _, c := range mw.Meta().Provides {
+			if c = normalizeCapability(c); c != "" {
+				providers[c] = append(providers[c], i)
+			}
+		}
+	}
+	for i, mw := range mws {
+		if mw == nil {
+			continue
+		}
+		meta := mw.Meta()
+		for _, req := range meta.Requires {
+			if hasOtherProvider(providers[normalizeCapability(req)], i) {
+				continue
+			}
+			if normalizeCapability(req) == CapabilityCandles {
+				return nil, fmt.Errorf("middleware %s 依赖 %s，但没有中间件提供，请在其之前配置 kline_fetcher", meta.Name, req)
+			}
+			return nil, fmt.Errorf("middleware %s 依赖 %s，但没有中间件提供", meta.Name, req)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(mws))
+	stages := make([]int, len(mws))
+	var resolve func(i int) error
+	resolve = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("middleware %s 的依赖成环", mws[i].Meta().Name)
+		}
+		state[i] = visiting
+		meta := mws[i].Meta()
+		stage := meta.Stage
+		for _, req := range meta.Requires {
+			for _, p := range providers[normalizeCapability(req)] {
+				if p == i {
+					continue
+				}
+				if err := resolve(p); err != nil {
+					return err
+				}
+				stage = max(stage, stages[p]+1)
+			}
+		}
+		stages[i] = stage
+		state[i] = done
+		return nil
+	}
+
+	out := make([]Middleware, 0, len(mws))
+	for i, mw := range mws {
+		if mw == nil {
+			continue
+		}
+		if err := resolve(i); err != nil {
+			return nil, err
+		}
+		meta := mw.Meta()
+		if stages[i] != meta.Stage {
+			logger.Infof("[pipeline] middleware %s stage %d → %d（排在 %s 的提供者之后）",
+				meta.Name, meta.Stage, stages[i], strings.Join(meta.Requires, ","))
+			mw = &stagedMiddleware{Middleware: mw, stage: stages[i]}
+		}
+		out = append(out, mw)
+	}
+	return out, nil
+}
+
+func hasOtherProvider(idx []int, self int) bool {
+	for _, i := range idx {
+		if i != self {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeCapability(c string) string {
+	return strings.ToLower(strings.TrimSpace(c))
+}
+
+// stagedMiddleware 只改写 Meta().Stage，其余行为委托给原中间件。
+type stagedMiddleware struct {
+	Middleware
+	stage int
+}
+
+func (m *stagedMiddleware) Meta() MiddlewareMeta {
+	meta := m.Middleware.Meta()
+	meta.Stage = m.stage
+	return meta
+}
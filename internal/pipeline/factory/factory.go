@@ -6,8 +6,12 @@ import (
 	"strings"
 	"time"
 
+	"brale/internal/analysis/formula"
+	"brale/internal/analysis/indicator"
+	"brale/internal/analysis/screen"
 	"brale/internal/config/loader"
 	"brale/internal/logger"
+	"brale/internal/market"
 	"brale/internal/pipeline"
 	"brale/internal/pipeline/middlewares"
 	"brale/internal/store"
@@ -17,9 +21,57 @@ type Factory struct {
 	Exporter         store.SnapshotExporter
 	DefaultIntervals []string
 	DefaultLimit     int
+	// OrderBook 为部分深度快照来源，未订阅深度时为 nil，order_book 中间件会在运行时报错。
+	OrderBook market.OrderBookReader
+	// Funding 为监控永续合约的资金费率跟踪，未启用时为 nil，funding_rate 中间件会在运行时报错。
+	Funding market.FundingReader
 }
 
 func (f *Factory) Build(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	mw, err := f.build(cfg, profile)
+	if err != nil {
+		return nil, err
+	}
+	return f.withCandleType(mw, cfg)
+}
+
+// Order 校验 profile 中间件列表的依赖（如指标中间件需要 kline_fetcher 提供 K 线），
+// 并按依赖关系自动推后 Stage；缺少提供者或依赖成环时返回错误。
+func (f *Factory) Order(profile loader.ProfileDefinition, mws []pipeline.Middleware) ([]pipeline.Middleware, error) {
+	ordered, err := pipeline.OrderByDependencies(mws)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", profile.Name, err)
+	}
+	return ordered, nil
+}
+
+// withCandleType 处理所有指标中间件通用的 candle_type 参数（raw/heikin_ashi/renko），
+// renko 可用 renko_brick 固定砖块，或 renko_atr_period/renko_atr_mult 按 ATR 计算。
+func (f *Factory) withCandleType(mw pipeline.Middleware, cfg loader.MiddlewareConfig) (pipeline.Middleware, error) {
+	raw := stringFromCfg(cfg.Params, "candle_type")
+	if raw == "" {
+		return mw, nil
+	}
+	ct, err := market.ParseCandleType(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.Name, err)
+	}
+	if ct == market.CandleRaw {
+		return mw, nil
+	}
+	switch strings.TrimSpace(cfg.Name) {
+	case "", "kline_fetcher":
+		return nil, fmt.Errorf("kline_fetcher 不支持 candle_type")
+	}
+	return middlewares.NewCandleTransformMiddleware(mw, market.CandleTransform{
+		Type:          ct,
+		BrickSize:     floatFromCfg(cfg.Params, "renko_brick"),
+		ATRPeriod:     intFromCfg(cfg.Params, "renko_atr_period"),
+		ATRMultiplier: floatFromCfg(cfg.Params, "renko_atr_mult"),
+	}), nil
+}
+
+func (f *Factory) build(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
 	name := strings.TrimSpace(cfg.Name)
 	switch name {
 	case "", "kline_fetcher":
@@ -30,6 +82,24 @@ func (f *Factory) Build(cfg loader.MiddlewareConfig, profile loader.ProfileDefin
 		return f.buildRSI(cfg, profile)
 	case "macd_trend":
 		return f.buildMACD(cfg, profile)
+	case "bb_squeeze":
+		return f.buildBBSqueeze(cfg, profile)
+	case "mtf_confluence":
+		return f.buildMTFConfluence(cfg, profile)
+	case "adx_trend":
+		return f.buildADXTrend(cfg, profile)
+	case "supertrend":
+		return f.buildSupertrend(cfg, profile)
+	case "liquidity_sweep":
+		return f.buildLiquiditySweep(cfg, profile)
+	case "rsi_divergence":
+		return f.buildRSIDivergence(cfg, profile)
+	case "custom_indicator":
+		return f.buildCustomIndicator(cfg, profile)
+	case "order_book":
+		return f.buildOrderBook(cfg)
+	case "funding_rate":
+		return f.buildFundingRate(cfg)
 	default:
 		return nil, fmt.Errorf("unknown middleware: %s", cfg.Name)
 	}
@@ -52,6 +122,20 @@ func (f *Factory) buildCandleFetcher(cfg loader.MiddlewareConfig, profile loader
 	if limit <= 0 {
 		return nil, fmt.Errorf("kline_fetcher 缺少有效的 limit")
 	}
+	if warmup := profile.IndicatorWarmupBars(); limit < warmup {
+		logger.Warnf("profile %s kline_fetcher limit=%d 低于指标预热需求 %d，已自动提高", profile.Name, limit, warmup)
+		limit = warmup
+	}
+	resample := sliceFromCfg(cfg.Params, "resample")
+	for _, iv := range resample {
+		source, ratio, ok := market.ResampleSource(iv, intervals)
+		if !ok {
+			return nil, fmt.Errorf("kline_fetcher resample %s 无法由 intervals %v 合成（需为某个已订阅周期的整数倍且不在 intervals 中）", iv, intervals)
+		}
+		if need := (limit + 1) * ratio; f.DefaultLimit > 0 && need > f.DefaultLimit {
+			logger.Warnf("profile %s kline_fetcher resample %s 需要 %d 根 %s，超过 kline.max_cached=%d，合成 K 线将少于 limit", profile.Name, iv, need, source, f.DefaultLimit)
+		}
+	}
 	mw := middlewares.NewCandleFetcher(middlewares.CandleFetcherConfig{
 		Name:      cfg.Name,
 		Stage:     cfg.Stage,
@@ -59,6 +143,7 @@ func (f *Factory) buildCandleFetcher(cfg loader.MiddlewareConfig, profile loader
 		Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
 		Intervals: intervals,
 		Limit:     limit,
+		Resample:  resample,
 	}, f.Exporter)
 	return mw, nil
 }
@@ -167,6 +252,212 @@ func (f *Factory) buildMACD(cfg loader.MiddlewareConfig, profile loader.ProfileD
 	return mw, nil
 }
 
+func (f *Factory) buildBBSqueeze(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	interval := stringFromCfg(cfg.Params, "interval")
+	if interval == "" {
+		if ints := profile.IntervalsLower(); len(ints) > 0 {
+			interval = ints[0]
+		}
+	}
+	if interval == "" {
+		return nil, fmt.Errorf("bb_squeeze 缺少 interval")
+	}
+	mw := middlewares.NewBBSqueezeMiddleware(middlewares.BBSqueezeConfig{
+		Name:             cfg.Name,
+		Stage:            cfg.Stage,
+		Critical:         cfg.Critical,
+		Timeout:          time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Interval:         interval,
+		BBPeriod:         intFromCfg(cfg.Params, "bb_period"),
+		BBStd:            floatFromCfg(cfg.Params, "bb_std"),
+		KCPeriod:         intFromCfg(cfg.Params, "kc_period"),
+		KCMult:           floatFromCfg(cfg.Params, "kc_mult"),
+		PercentileWindow: intFromCfg(cfg.Params, "percentile_window"),
+	})
+	return mw, nil
+}
+
+func (f *Factory) buildMTFConfluence(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	intervals := sliceFromCfg(cfg.Params, "intervals")
+	if len(intervals) == 0 {
+		intervals = profile.IntervalsLower()
+	}
+	if len(intervals) == 0 {
+		return nil, fmt.Errorf("mtf_confluence 缺少 intervals")
+	}
+	weights := profile.Confluence.Weights
+	if len(weights) == 0 {
+		weights = profile.Composite.Weights
+	}
+	mw := middlewares.NewMTFConfluenceMiddleware(middlewares.MTFConfluenceConfig{
+		Name:              cfg.Name,
+		Stage:             cfg.Stage,
+		Critical:          cfg.Critical,
+		Timeout:           time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Intervals:         intervals,
+		Weights:           weights,
+		DivergenceScoring: screen.DivergenceScoring(profile.DivergenceScoring),
+	})
+	return mw, nil
+}
+
+func (f *Factory) buildADXTrend(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	interval := stringFromCfg(cfg.Params, "interval")
+	if interval == "" {
+		if ints := profile.IntervalsLower(); len(ints) > 0 {
+			interval = ints[0]
+		}
+	}
+	if interval == "" {
+		return nil, fmt.Errorf("adx_trend 缺少 interval")
+	}
+	mw := middlewares.NewADXTrendMiddleware(middlewares.ADXTrendConfig{
+		Name:     cfg.Name,
+		Stage:    cfg.Stage,
+		Critical: cfg.Critical,
+		Timeout:  time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Interval: interval,
+		Period:   intFromCfg(cfg.Params, "period"),
+	})
+	return mw, nil
+}
+
+func (f *Factory) buildSupertrend(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	interval := stringFromCfg(cfg.Params, "interval")
+	if interval == "" {
+		if ints := profile.IntervalsLower(); len(ints) > 0 {
+			interval = ints[0]
+		}
+	}
+	if interval == "" {
+		return nil, fmt.Errorf("supertrend 缺少 interval")
+	}
+	mw := middlewares.NewSupertrendMiddleware(middlewares.SupertrendConfig{
+		Name:       cfg.Name,
+		Stage:      cfg.Stage,
+		Critical:   cfg.Critical,
+		Timeout:    time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Interval:   interval,
+		Period:     intFromCfg(cfg.Params, "period"),
+		Multiplier: floatFromCfg(cfg.Params, "multiplier"),
+		Alert:      strings.EqualFold(stringFromCfg(cfg.Params, "alert"), "true"),
+	})
+	return mw, nil
+}
+
+func (f *Factory) buildLiquiditySweep(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	interval := stringFromCfg(cfg.Params, "interval")
+	if interval == "" {
+		if ints := profile.IntervalsLower(); len(ints) > 0 {
+			interval = ints[0]
+		}
+	}
+	if interval == "" {
+		return nil, fmt.Errorf("liquidity_sweep 缺少 interval")
+	}
+	mw := middlewares.NewLiquiditySweepMiddleware(middlewares.LiquiditySweepConfig{
+		Name:        cfg.Name,
+		Stage:       cfg.Stage,
+		Critical:    cfg.Critical,
+		Timeout:     time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Interval:    interval,
+		FractalSpan: intFromCfg(cfg.Params, "fractal_span"),
+		ReclaimBars: intFromCfg(cfg.Params, "reclaim_bars"),
+		VolumeZ:     floatFromCfg(cfg.Params, "volume_z"),
+		MaxAge:      intFromCfg(cfg.Params, "max_age"),
+	})
+	return mw, nil
+}
+
+func (f *Factory) buildRSIDivergence(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	interval := stringFromCfg(cfg.Params, "interval")
+	if interval == "" {
+		if ints := profile.IntervalsLower(); len(ints) > 0 {
+			interval = ints[0]
+		}
+	}
+	if interval == "" {
+		return nil, fmt.Errorf("rsi_divergence 缺少 interval")
+	}
+	mw := middlewares.NewRSIDivergenceMiddleware(middlewares.RSIDivergenceConfig{
+		Name:     cfg.Name,
+		Stage:    cfg.Stage,
+		Critical: cfg.Critical,
+		Timeout:  time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Interval: interval,
+		Settings: indicator.RSIDivergenceSettings{
+			Period:     intFromCfg(cfg.Params, "period"),
+			PivotLeft:  intFromCfg(cfg.Params, "pivot_left"),
+			PivotRight: intFromCfg(cfg.Params, "pivot_right"),
+			MinGap:     intFromCfg(cfg.Params, "min_gap"),
+			MaxGap:     intFromCfg(cfg.Params, "max_gap"),
+			MaxAge:     intFromCfg(cfg.Params, "max_age"),
+			Overbought: floatFromCfg(cfg.Params, "overbought"),
+			Oversold:   floatFromCfg(cfg.Params, "oversold"),
+			Hidden:     strings.EqualFold(stringFromCfg(cfg.Params, "hidden"), "true"),
+		},
+	})
+	return mw, nil
+}
+
+func (f *Factory) buildCustomIndicator(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error) {
+	interval := stringFromCfg(cfg.Params, "interval")
+	if interval == "" {
+		if ints := profile.IntervalsLower(); len(ints) > 0 {
+			interval = ints[0]
+		}
+	}
+	if interval == "" {
+		return nil, fmt.Errorf("custom_indicator 缺少 interval")
+	}
+	feature := strings.ToLower(stringFromCfg(cfg.Params, "feature"))
+	if !formula.ValidName(feature) {
+		return nil, fmt.Errorf("custom_indicator 的 feature 需为字母/数字/下划线组成的名称，got %q", feature)
+	}
+	program, err := formula.Compile(stringFromCfg(cfg.Params, "formula"))
+	if err != nil {
+		return nil, fmt.Errorf("custom_indicator %s: %w", feature, err)
+	}
+	mw := middlewares.NewCustomIndicatorMiddleware(middlewares.CustomIndicatorConfig{
+		Name:        cfg.Name,
+		Stage:       cfg.Stage,
+		Critical:    cfg.Critical,
+		Timeout:     time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Interval:    interval,
+		Feature:     feature,
+		Label:       stringFromCfg(cfg.Params, "label"),
+		Description: stringFromCfg(cfg.Params, "description"),
+		Program:     program,
+	})
+	return mw, nil
+}
+
+func (f *Factory) buildOrderBook(cfg loader.MiddlewareConfig) (pipeline.Middleware, error) {
+	mw := middlewares.NewOrderBookMiddleware(middlewares.OrderBookConfig{
+		Name:         cfg.Name,
+		Stage:        cfg.Stage,
+		Critical:     cfg.Critical,
+		Timeout:      time.Duration(cfg.TimeoutSeconds) * time.Second,
+		BandPct:      floatFromCfg(cfg.Params, "band_pct"),
+		WallMultiple: floatFromCfg(cfg.Params, "wall_multiple"),
+		MaxAge:       time.Duration(intFromCfg(cfg.Params, "max_age_seconds")) * time.Second,
+	}, f.OrderBook)
+	return mw, nil
+}
+
+func (f *Factory) buildFundingRate(cfg loader.MiddlewareConfig) (pipeline.Middleware, error) {
+	mw := middlewares.NewFundingRateMiddleware(middlewares.FundingRateConfig{
+		Name:       cfg.Name,
+		Stage:      cfg.Stage,
+		Critical:   cfg.Critical,
+		Timeout:    time.Duration(cfg.TimeoutSeconds) * time.Second,
+		Percentile: floatFromCfg(cfg.Params, "percentile"),
+		MinSamples: intFromCfg(cfg.Params, "min_samples"),
+		MaxAge:     time.Duration(intFromCfg(cfg.Params, "max_age_minutes")) * time.Minute,
+	}, f.Funding)
+	return mw, nil
+}
+
 func sliceFromCfg(params map[string]interface{}, key string) []string {
 	if params == nil {
 		return nil
@@ -10,11 +10,15 @@ type Middleware interface {
 	Handle(ctx context.Context, ac *AnalysisContext) error
 }
 
+// MiddlewareMeta 描述中间件的调度信息；Requires/Provides 为能力名（如 CapabilityCandles），
+// 由 OrderByDependencies 校验依赖是否有提供者，并把依赖方排到提供方之后的 Stage。
 type MiddlewareMeta struct {
 	Name     string
 	Stage    int
 	Critical bool
 	Timeout  time.Duration
+	Requires []string
+	Provides []string
 }
 
 type MiddlewareError struct {
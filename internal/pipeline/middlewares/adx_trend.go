@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/pipeline"
+)
+
+type ADXTrendConfig struct {
+	Name     string
+	Stage    int
+	Critical bool
+	Timeout  time.Duration
+	Interval string
+	Period   int
+}
+
+// ADXTrendMiddleware 输出 +DI/-DI/ADX 与趋势强度分档，用于区分震荡与趋势行情。
+type ADXTrendMiddleware struct {
+	meta     pipeline.MiddlewareMeta
+	interval string
+	period   int
+}
+
+func NewADXTrendMiddleware(cfg ADXTrendConfig) *ADXTrendMiddleware {
+	if cfg.Period <= 0 {
+		cfg.Period = 14
+	}
+	return &ADXTrendMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "adx_trend"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
+		},
+		interval: strings.ToLower(strings.TrimSpace(cfg.Interval)),
+		period:   cfg.Period,
+	}
+}
+
+func (m *ADXTrendMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *ADXTrendMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	interval := m.interval
+	if interval == "" {
+		interval = "1h"
+	}
+	candles := ac.Candles(interval)
+	highs, lows := highsLows(candles)
+	adx, err := indicator.ComputeADX(highs, lows, closes(candles), m.period)
+	if err != nil {
+		return fmt.Errorf("adx_trend: %s %w", interval, err)
+	}
+	desc := fmt.Sprintf("周期 %s 的 ADX(%d)=%.2f（%s），+DI=%.2f，-DI=%.2f，方向 %s",
+		strings.ToUpper(interval), m.period, adx.ADX, adx.Strength, adx.PlusDI, adx.MinusDI, adx.Direction)
+	ac.AddFeature(pipeline.Feature{
+		Key:         "adx_trend",
+		Label:       fmt.Sprintf("%s ADX", strings.ToUpper(interval)),
+		Value:       adx.ADX,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata: map[string]any{
+			"interval":    interval,
+			"period":      m.period,
+			"plus_di":     adx.PlusDI,
+			"minus_di":    adx.MinusDI,
+			"strength":    adx.Strength,
+			"direction":   adx.Direction,
+			"series_tail": seriesTail(adx.Series, 5),
+		},
+	})
+	return nil
+}
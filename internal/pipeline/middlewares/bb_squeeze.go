@@ -0,0 +1,234 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"brale/internal/market"
+	"brale/internal/pipeline"
+
+	talib "github.com/markcheno/go-talib"
+)
+
+type BBSqueezeConfig struct {
+	Name     string
+	Stage    int
+	Critical bool
+	Timeout  time.Duration
+	Interval string
+	// BBPeriod/BBStd 为布林带周期与标准差倍数，KCPeriod/KCMult 为肯特纳通道周期与 ATR 倍数。
+	BBPeriod int
+	BBStd    float64
+	KCPeriod int
+	KCMult   float64
+	// PercentileWindow 为带宽分位的回看根数。
+	PercentileWindow int
+}
+
+// BBSqueezeMiddleware 计算 TTM Squeeze：布林带完全收进肯特纳通道视为挤压（squeeze on），
+// 挤压结束当根（fired）按动量方向给出突破方向。
+type BBSqueezeMiddleware struct {
+	meta      pipeline.MiddlewareMeta
+	interval  string
+	bbPeriod  int
+	bbStd     float64
+	kcPeriod  int
+	kcMult    float64
+	pctWindow int
+}
+
+func NewBBSqueezeMiddleware(cfg BBSqueezeConfig) *BBSqueezeMiddleware {
+	if cfg.BBPeriod <= 0 {
+		cfg.BBPeriod = 20
+	}
+	if cfg.BBStd <= 0 {
+		cfg.BBStd = 2
+	}
+	if cfg.KCPeriod <= 0 {
+		cfg.KCPeriod = 20
+	}
+	if cfg.KCMult <= 0 {
+		cfg.KCMult = 1.5
+	}
+	if cfg.PercentileWindow <= 0 {
+		cfg.PercentileWindow = 120
+	}
+	return &BBSqueezeMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "bb_squeeze"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
+		},
+		interval:  strings.ToLower(strings.TrimSpace(cfg.Interval)),
+		bbPeriod:  cfg.BBPeriod,
+		bbStd:     cfg.BBStd,
+		kcPeriod:  cfg.KCPeriod,
+		kcMult:    cfg.KCMult,
+		pctWindow: cfg.PercentileWindow,
+	}
+}
+
+func (m *BBSqueezeMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *BBSqueezeMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	interval := m.interval
+	if interval == "" {
+		interval = "1h"
+	}
+	candles := ac.Candles(interval)
+	required := max(m.bbPeriod, m.kcPeriod) + 2
+	if len(candles) < required {
+		return fmt.Errorf("bb_squeeze: %s 蜡烛不足，需 >= %d", interval, required)
+	}
+	closes := closes(candles)
+	highs, lows := highsLows(candles)
+	bbUpper, bbMid, bbLower := talib.BBands(closes, m.bbPeriod, m.bbStd, m.bbStd, talib.SMA)
+	kcMid := talib.Ema(closes, m.kcPeriod)
+	atr := talib.Atr(highs, lows, closes, m.kcPeriod)
+	momentum := squeezeMomentum(closes, highs, lows, m.bbPeriod)
+
+	start := required - 1
+	n := len(closes)
+	squeeze := make([]bool, n)
+	bandwidth := make([]float64, 0, n-start)
+	for i := start; i < n; i++ {
+		kcUpper := kcMid[i] + m.kcMult*atr[i]
+		kcLower := kcMid[i] - m.kcMult*atr[i]
+		squeeze[i] = bbUpper[i] < kcUpper && bbLower[i] > kcLower
+		if bbMid[i] != 0 {
+			bandwidth = append(bandwidth, (bbUpper[i]-bbLower[i])/bbMid[i])
+		}
+	}
+	last := n - 1
+	on := squeeze[last]
+	fired := !on && squeeze[last-1]
+	bars := 0
+	for i := last; i >= start && squeeze[i]; i-- {
+		bars++
+	}
+	mom := momentum[last]
+	direction := "none"
+	if fired {
+		direction = "up"
+		if mom < 0 {
+			direction = "down"
+		}
+	}
+	bw, pct := 0.0, 0.0
+	if len(bandwidth) > 0 {
+		bw = bandwidth[len(bandwidth)-1]
+		pct = percentileRank(bandwidth, m.pctWindow)
+	}
+	state := "off"
+	switch {
+	case on:
+		state = "on"
+	case fired:
+		state = "fired"
+	}
+	kcUpper := kcMid[last] + m.kcMult*atr[last]
+	kcLower := kcMid[last] - m.kcMult*atr[last]
+	desc := fmt.Sprintf("周期 %s 的 BB(%d,%.1f)/KC(%d,%.1f) 挤压状态 %s（持续 %d 根），带宽 %.4f 处于近 %d 根 %.0f%% 分位，动量 %.4f，突破方向 %s",
+		strings.ToUpper(interval), m.bbPeriod, m.bbStd, m.kcPeriod, m.kcMult, state, bars, bw, m.pctWindow, pct*100, mom, direction)
+	value := 0.0
+	if on {
+		value = 1
+	}
+	ac.AddFeature(pipeline.Feature{
+		Key:         "bb_squeeze",
+		Label:       fmt.Sprintf("%s BB Squeeze", strings.ToUpper(interval)),
+		Value:       value,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata: map[string]any{
+			"interval":             interval,
+			"squeeze":              on,
+			"state":                state,
+			"squeeze_bars":         bars,
+			"bandwidth":            bw,
+			"bandwidth_percentile": pct,
+			"momentum":             mom,
+			"direction":            direction,
+			"bb_upper":             bbUpper[last],
+			"bb_middle":            bbMid[last],
+			"bb_lower":             bbLower[last],
+			"kc_upper":             kcUpper,
+			"kc_lower":             kcLower,
+			"last_fired":           lastSqueezeFire(squeeze, candles, start),
+		},
+	})
+	return nil
+}
+
+func highsLows(candles []market.Candle) ([]float64, []float64) {
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	for i, c := range candles {
+		highs[i] = c.High
+		lows[i] = c.Low
+	}
+	return highs, lows
+}
+
+// squeezeMomentum 为 TTM 动量：close 减去 (唐奇安中轴 + SMA)/2 后做线性回归平滑。
+func squeezeMomentum(closes, highs, lows []float64, period int) []float64 {
+	sma := talib.Sma(closes, period)
+	hh := talib.Max(highs, period)
+	ll := talib.Min(lows, period)
+	delta := make([]float64, len(closes))
+	for i := range closes {
+		if i < period-1 {
+			continue
+		}
+		delta[i] = closes[i] - ((hh[i]+ll[i])/2+sma[i])/2
+	}
+	valid := delta[period-1:]
+	if len(valid) < period {
+		return delta
+	}
+	out := make([]float64, len(closes))
+	copy(out[period-1:], talib.LinearReg(valid, period))
+	return out
+}
+
+// percentileRank 返回最后一个值在最近 window 个值中的分位（0-1）。
+func percentileRank(series []float64, window int) float64 {
+	if len(series) == 0 {
+		return 0
+	}
+	if window > 0 && len(series) > window {
+		series = series[len(series)-window:]
+	}
+	last := series[len(series)-1]
+	below := 0
+	for _, v := range series {
+		if v < last {
+			below++
+		}
+	}
+	if len(series) == 1 {
+		return 0
+	}
+	return math.Min(1, float64(below)/float64(len(series)-1))
+}
+
+func lastSqueezeFire(squeeze []bool, candles []market.Candle, start int) string {
+	for i := len(squeeze) - 1; i > start; i-- {
+		if squeeze[i] || !squeeze[i-1] {
+			continue
+		}
+		ts := candles[i].CloseTime
+		if ts == 0 {
+			ts = candles[i].OpenTime
+		}
+		if ts == 0 {
+			return ""
+		}
+		return time.UnixMilli(ts).UTC().Format(time.RFC3339)
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+
+	"brale/internal/market"
+	"brale/internal/pipeline"
+)
+
+// CandleTransformMiddleware 让任意指标中间件在变换后的 K 线（Heikin-Ashi / Renko）上运行：
+// 内层中间件读到的是变换后的子上下文，产出的特征以 <key>_<candle_type> 写回原上下文，避免与原始 K 线特征冲突。
+type CandleTransformMiddleware struct {
+	inner     pipeline.Middleware
+	transform market.CandleTransform
+}
+
+func NewCandleTransformMiddleware(inner pipeline.Middleware, transform market.CandleTransform) *CandleTransformMiddleware {
+	return &CandleTransformMiddleware{inner: inner, transform: transform}
+}
+
+func (m *CandleTransformMiddleware) Meta() pipeline.MiddlewareMeta { return m.inner.Meta() }
+
+func (m *CandleTransformMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	child := pipeline.NewContext(ac.Symbol)
+	child.Profile = ac.Profile
+	child.ContextTag = ac.ContextTag
+	child.TraceID = ac.TraceID
+	child.StartedAt = ac.StartedAt
+	for k, v := range ac.Metadata() {
+		child.SetMetadata(k, v)
+	}
+	for _, iv := range ac.Intervals() {
+		transformed := m.transform.Apply(ac.Candles(iv))
+		if len(transformed) == 0 {
+			continue
+		}
+		child.SetCandles(iv, transformed)
+	}
+	err := m.inner.Handle(ctx, child)
+
+	suffix := string(m.transform.Type)
+	for _, f := range child.Features() {
+		f.Key = f.Key + "_" + suffix
+		f.Label = fmt.Sprintf("%s (%s)", f.Label, suffix)
+		meta := make(map[string]any, len(f.Metadata)+1)
+		for k, v := range f.Metadata {
+			meta[k] = v
+		}
+		meta["candle_type"] = suffix
+		if m.transform.Type == market.CandleRenko && m.transform.BrickSize > 0 {
+			meta["renko_brick"] = m.transform.BrickSize
+		}
+		f.Metadata = meta
+		ac.AddFeature(f)
+	}
+	for sec, lines := range child.PromptParts() {
+		ac.AppendPromptPart(sec, lines...)
+	}
+	for _, w := range child.Warnings() {
+		ac.AddWarning(w)
+	}
+	if err != nil {
+		return fmt.Errorf("candle_type=%s: %w", suffix, err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"brale/internal/market"
 	"brale/internal/pipeline"
 	"brale/internal/store"
 )
@@ -17,6 +18,8 @@ type CandleFetcherConfig struct {
 	Timeout   time.Duration
 	Intervals []string
 	Limit     int
+	// Resample 为合成周期（如 2h/6h/12h），由 Intervals 中能整除它的最大周期聚合得到，无需单独订阅。
+	Resample []string
 }
 
 type CandleFetcher struct {
@@ -24,22 +27,39 @@ type CandleFetcher struct {
 	exporter  store.SnapshotExporter
 	intervals []string
 	limit     int
+	resample  []resampleTarget
+}
+
+// resampleTarget 为一个合成周期及其源周期，Ratio 为每根合成 K 线包含的源 K 线数。
+type resampleTarget struct {
+	Interval string
+	Source   string
+	Ratio    int
 }
 
 func NewCandleFetcher(cfg CandleFetcherConfig, exporter store.SnapshotExporter) *CandleFetcher {
 	if cfg.Limit <= 0 {
 		cfg.Limit = 240
 	}
+	var targets []resampleTarget
+	for _, iv := range cfg.Resample {
+		iv = strings.ToLower(strings.TrimSpace(iv))
+		if src, ratio, ok := market.ResampleSource(iv, cfg.Intervals); ok {
+			targets = append(targets, resampleTarget{Interval: iv, Source: src, Ratio: ratio})
+		}
+	}
 	return &CandleFetcher{
 		meta: pipeline.MiddlewareMeta{
 			Name:     nameOrDefault(cfg.Name, "kline_fetcher"),
 			Stage:    cfg.Stage,
 			Critical: cfg.Critical,
 			Timeout:  cfg.Timeout,
+			Provides: []string{pipeline.CapabilityCandles},
 		},
 		exporter:  exporter,
 		intervals: append([]string(nil), cfg.Intervals...),
 		limit:     cfg.Limit,
+		resample:  targets,
 	}
 }
 
@@ -70,6 +90,36 @@ func (c *CandleFetcher) Handle(ctx context.Context, ac *pipeline.AnalysisContext
 		}
 		ac.SetCandles(iv, candles)
 	}
+	for _, rt := range c.resample {
+		if err := c.setResampled(ctx, ac, rt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setResampled 从 KlineStore 取足量源周期 K 线聚合为合成周期，保留最近 limit 根；多取一组源 K 线以抵消开头被丢弃的不完整桶。
+func (c *CandleFetcher) setResampled(ctx context.Context, ac *pipeline.AnalysisContext, rt resampleTarget) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	src, err := c.exporter.Export(ctx, ac.Symbol, rt.Source, (c.limit+1)*rt.Ratio)
+	if err != nil {
+		return fmt.Errorf("export %s %s: %w", ac.Symbol, rt.Source, err)
+	}
+	candles, err := market.Resample(src, rt.Source, rt.Interval)
+	if err != nil {
+		return fmt.Errorf("resample %s %s: %w", ac.Symbol, rt.Interval, err)
+	}
+	if len(candles) == 0 {
+		return nil
+	}
+	if len(candles) > c.limit {
+		candles = candles[len(candles)-c.limit:]
+	}
+	ac.SetCandles(rt.Interval, candles)
 	return nil
 }
 
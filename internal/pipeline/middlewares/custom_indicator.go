@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/formula"
+	"brale/internal/pipeline"
+)
+
+type CustomIndicatorConfig struct {
+	Name        string
+	Stage       int
+	Critical    bool
+	Timeout     time.Duration
+	Interval    string
+	Feature     string
+	Label       string
+	Description string
+	Program     *formula.Program
+}
+
+// CustomIndicatorMiddleware 按 profile 中的表达式计算自定义指标，输出名为 Feature 的特征。
+// value：最新一根 K 线的表达式值（比较/逻辑表达式为 1/0），metadata 附带上一根的值与变化量。
+type CustomIndicatorMiddleware struct {
+	meta        pipeline.MiddlewareMeta
+	interval    string
+	feature     string
+	label       string
+	description string
+	program     *formula.Program
+}
+
+func NewCustomIndicatorMiddleware(cfg CustomIndicatorConfig) *CustomIndicatorMiddleware {
+	return &CustomIndicatorMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "custom_indicator"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
+		},
+		interval:    strings.ToLower(strings.TrimSpace(cfg.Interval)),
+		feature:     strings.ToLower(strings.TrimSpace(cfg.Feature)),
+		label:       strings.TrimSpace(cfg.Label),
+		description: strings.TrimSpace(cfg.Description),
+		program:     cfg.Program,
+	}
+}
+
+func (m *CustomIndicatorMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *CustomIndicatorMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	interval := m.interval
+	if interval == "" {
+		interval = "1h"
+	}
+	candles := ac.Candles(interval)
+	series := m.program.Eval(candles)
+	value, ok := formula.Last(series)
+	if !ok {
+		return fmt.Errorf("custom_indicator %s: %s 无有效值（K 线 %d 根，预热不足或除零）", m.feature, interval, len(candles))
+	}
+	meta := map[string]any{
+		"interval": interval,
+		"formula":  m.program.Source(),
+	}
+	desc := fmt.Sprintf("周期 %s %s = %.4f", strings.ToUpper(interval), m.feature, value)
+	if prev, ok := formula.Last(series[:len(series)-1]); ok {
+		meta["prev"] = prev
+		meta["change"] = value - prev
+		desc += fmt.Sprintf("（上一根 %.4f）", prev)
+	}
+	if m.description != "" {
+		desc += "：" + m.description
+	}
+	label := m.label
+	if label == "" {
+		label = fmt.Sprintf("%s %s", strings.ToUpper(interval), m.feature)
+	}
+	ac.AddFeature(pipeline.Feature{
+		Key:         m.feature,
+		Label:       label,
+		Value:       value,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata:    meta,
+	})
+	return nil
+}
@@ -39,6 +39,7 @@ func NewEMATrend(cfg EMATrendConfig) *EMATrendMiddleware {
 			Stage:    cfg.Stage,
 			Critical: cfg.Critical,
 			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
 		},
 		interval: cfg.Interval,
 		fast:     cfg.Fast,
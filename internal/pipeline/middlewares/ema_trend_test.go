@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"testing"
+
+	"brale/internal/pipeline/testkit"
+)
+
+func TestEMATrend_ReplayGolden(t *testing.T) {
+	fx := testkit.MustLoad(t, "testdata/fixtures/btcusdt_1h.json")
+	mw := NewEMATrend(EMATrendConfig{Name: "ema_trend", Interval: "1h", Fast: 21, Mid: 50, Slow: 200})
+
+	res := testkit.MustReplay(t, fx, mw)
+
+	f, ok := res.Feature("ema_trend")
+	if !ok {
+		t.Fatalf("ema_trend feature not produced")
+	}
+	meta := f.Metadata
+	fast, mid, slow := meta["ema_fast"].(float64), meta["ema_mid"].(float64), meta["ema_slow"].(float64)
+	testkit.AssertFeature(t, res, "ema_trend", fast-slow, 1e-9)
+	if meta["interval"] != "1h" {
+		t.Fatalf("interval = %v, want 1h", meta["interval"])
+	}
+	if trend := meta["trend"]; (trend == "UP") != (fast > mid && mid > slow) {
+		t.Fatalf("trend = %v inconsistent with fast=%.2f mid=%.2f slow=%.2f", trend, fast, mid, slow)
+	}
+	testkit.AssertGolden(t, "testdata/golden/ema_trend_btcusdt_1h.json", res.Round(6))
+}
+
+func TestEMATrend_ReplayMissingInterval(t *testing.T) {
+	fx := testkit.MustLoad(t, "testdata/fixtures/btcusdt_1h.json")
+	mw := NewEMATrend(EMATrendConfig{Name: "ema_trend", Critical: true, Interval: "4h", Fast: 21, Mid: 50, Slow: 200})
+
+	res, err := testkit.Replay(t.Context(), fx, mw)
+	if err == nil {
+		t.Fatalf("expected error for missing 4h candles on a critical middleware")
+	}
+	if len(res.Features) != 0 {
+		t.Fatalf("unexpected features: %+v", res.Features)
+	}
+}
@@ -0,0 +1,114 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"brale/internal/market"
+	"brale/internal/pipeline"
+)
+
+type FundingRateConfig struct {
+	Name       string
+	Stage      int
+	Critical   bool
+	Timeout    time.Duration
+	Percentile float64
+	MinSamples int
+	MaxAge     time.Duration
+}
+
+// FundingRateMiddleware 按全部监控永续合约近期的预测资金费率分布标记极端费率。
+// value 为预测费率；metadata.extreme 为 high/low/none，high 表示多头拥挤（bias=short，avoid_side=long），low 反之。
+type FundingRateMiddleware struct {
+	meta       pipeline.MiddlewareMeta
+	reader     market.FundingReader
+	percentile float64
+	minSamples int
+	maxAge     time.Duration
+}
+
+func NewFundingRateMiddleware(cfg FundingRateConfig, reader market.FundingReader) *FundingRateMiddleware {
+	if cfg.Percentile <= 0.5 || cfg.Percentile >= 1 {
+		cfg.Percentile = 0.9
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 20
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 15 * time.Minute
+	}
+	return &FundingRateMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "funding_rate"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+		},
+		reader:     reader,
+		percentile: cfg.Percentile,
+		minSamples: cfg.MinSamples,
+		maxAge:     cfg.MaxAge,
+	}
+}
+
+func (m *FundingRateMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *FundingRateMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	if m.reader == nil {
+		return fmt.Errorf("funding_rate: 资金费率跟踪未启用")
+	}
+	info, ok := m.reader.LatestFunding(ac.Symbol)
+	if !ok {
+		return fmt.Errorf("funding_rate: %s 暂无资金费率", ac.Symbol)
+	}
+	now := time.Now()
+	if age := now.Sub(info.UpdatedAt); age > m.maxAge {
+		return fmt.Errorf("funding_rate: %s 资金费率已过期 %s", ac.Symbol, age.Round(time.Second))
+	}
+	rank, samples := m.reader.FundingPercentile(info.PredictedRate)
+
+	extreme, bias, avoid := "none", "none", "none"
+	if samples >= m.minSamples {
+		switch {
+		case rank >= m.percentile && info.PredictedRate > 0:
+			extreme, bias, avoid = "high", "short", "long"
+		case rank <= 1-m.percentile && info.PredictedRate < 0:
+			extreme, bias, avoid = "low", "long", "short"
+		}
+	}
+	meta := map[string]any{
+		"rate":           info.Rate,
+		"predicted_rate": info.PredictedRate,
+		"percentile":     math.Round(rank*1000) / 1000,
+		"threshold":      m.percentile,
+		"samples":        samples,
+		"extreme":        extreme,
+		"bias":           bias,
+		"avoid_side":     avoid,
+	}
+	desc := fmt.Sprintf("资金费率 %.4f%%，预测下期 %.4f%%（近期全市场分位 %.0f%%）",
+		info.Rate*100, info.PredictedRate*100, rank*100)
+	if !info.NextFundingTime.IsZero() {
+		minutes := int(math.Max(0, info.NextFundingTime.Sub(now).Minutes()))
+		meta["next_funding_time"] = info.NextFundingTime.UTC().Format(time.RFC3339)
+		meta["minutes_to_funding"] = minutes
+		desc += fmt.Sprintf("，距下次结算 %d 分钟", minutes)
+	}
+	switch extreme {
+	case "high":
+		desc += "；费率极端偏高，多头拥挤，开多需支付高额资金费，偏向做空或放弃开多"
+	case "low":
+		desc += "；费率极端偏低，空头拥挤，开空需支付高额资金费，偏向做多或放弃开空"
+	}
+	ac.AddFeature(pipeline.Feature{
+		Key:         "funding_rate",
+		Label:       "Funding Rate Extreme",
+		Value:       info.PredictedRate,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata:    meta,
+	})
+	return nil
+}
@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/pipeline"
+)
+
+type LiquiditySweepConfig struct {
+	Name        string
+	Stage       int
+	Critical    bool
+	Timeout     time.Duration
+	Interval    string
+	FractalSpan int
+	ReclaimBars int
+	VolumeZ     float64
+	MaxAge      int
+}
+
+// LiquiditySweepMiddleware 输出最近一次流动性扫单（影线刺破分形高/低点后收回且放量）。
+// value：+1 为扫下方流动性（偏多），-1 为扫上方流动性（偏空），0 为 MaxAge 根内无扫单。
+type LiquiditySweepMiddleware struct {
+	meta        pipeline.MiddlewareMeta
+	interval    string
+	fractalSpan int
+	reclaimBars int
+	volumeZ     float64
+	maxAge      int
+}
+
+func NewLiquiditySweepMiddleware(cfg LiquiditySweepConfig) *LiquiditySweepMiddleware {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 5
+	}
+	return &LiquiditySweepMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "liquidity_sweep"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
+		},
+		interval:    strings.ToLower(strings.TrimSpace(cfg.Interval)),
+		fractalSpan: cfg.FractalSpan,
+		reclaimBars: cfg.ReclaimBars,
+		volumeZ:     cfg.VolumeZ,
+		maxAge:      cfg.MaxAge,
+	}
+}
+
+func (m *LiquiditySweepMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *LiquiditySweepMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	interval := m.interval
+	if interval == "" {
+		interval = "1h"
+	}
+	candles := ac.Candles(interval)
+	if len(candles) == 0 {
+		return fmt.Errorf("liquidity_sweep: %s 无 K 线", interval)
+	}
+	opts := decision.DefaultTrendCompressOptions()
+	if m.fractalSpan > 0 {
+		opts.FractalSpan = m.fractalSpan
+	}
+	if m.reclaimBars > 0 {
+		opts.SweepReclaimBars = m.reclaimBars
+	}
+	if m.volumeZ > 0 {
+		opts.SweepVolumeZ = m.volumeZ
+	}
+	events := decision.DetectLiquiditySweeps(candles, opts)
+
+	label := fmt.Sprintf("%s Liquidity Sweep", strings.ToUpper(interval))
+	meta := map[string]any{
+		"interval":     interval,
+		"reclaim_bars": opts.SweepReclaimBars,
+		"volume_z_min": opts.SweepVolumeZ,
+		"max_age":      m.maxAge,
+		"events":       len(events),
+		"bias":         "none",
+	}
+	var last *decision.TrendStructureEvent
+	if len(events) > 0 && events[len(events)-1].AgeCandles <= m.maxAge {
+		last = &events[len(events)-1]
+	}
+	if last == nil {
+		ac.AddFeature(pipeline.Feature{
+			Key:         "liquidity_sweep",
+			Label:       label,
+			Value:       0,
+			Description: formatFeature(ac.Symbol, fmt.Sprintf("周期 %s 最近 %d 根无流动性扫单", strings.ToUpper(interval), m.maxAge)),
+			Metadata:    meta,
+		})
+		return nil
+	}
+	value := 1.0
+	side := "下方"
+	if last.Bias == "bearish" {
+		value = -1
+		side = "上方"
+	}
+	meta["bias"] = last.Bias
+	meta["type"] = last.Type
+	meta["level"] = last.Level
+	meta["extreme"] = last.Extreme
+	meta["volume_z"] = last.VolumeZ
+	meta["age"] = last.AgeCandles
+	desc := fmt.Sprintf("周期 %s 扫%s流动性：影线刺破结构位 %.4f 至 %.4f 后 %d 根内收回，量能 z=%.2f，%d 根前确认（%s）",
+		strings.ToUpper(interval), side, last.Level, last.Extreme, last.ReclaimBars, last.VolumeZ, last.AgeCandles, last.Bias)
+	ac.AddFeature(pipeline.Feature{
+		Key:         "liquidity_sweep",
+		Label:       label,
+		Value:       value,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata:    meta,
+	})
+	return nil
+}
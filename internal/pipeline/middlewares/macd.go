@@ -47,6 +47,7 @@ func NewMACDMiddleware(cfg MACDConfig) *MACDMiddleware {
 			Stage:    cfg.Stage,
 			Critical: cfg.Critical,
 			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
 		},
 		interval: strings.ToLower(strings.TrimSpace(cfg.Interval)),
 		fast:     cfg.Fast,
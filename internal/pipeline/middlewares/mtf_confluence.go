@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/screen"
+	"brale/internal/pipeline"
+)
+
+type MTFConfluenceConfig struct {
+	Name      string
+	Stage     int
+	Critical  bool
+	Timeout   time.Duration
+	Intervals []string
+	// Weights 为各周期权重，未配置的周期按 1。
+	Weights map[string]float64
+	// DivergenceScoring 为各周期背离判定的指标权重与阈值。
+	DivergenceScoring screen.DivergenceScoring
+}
+
+// MTFConfluenceMiddleware 汇总多个周期的 EMA 排列、WT/MFI 状态与背离方向，输出多空共振分，
+// 供 rules / screening 等前置闸门按共振程度放行交易。
+type MTFConfluenceMiddleware struct {
+	meta      pipeline.MiddlewareMeta
+	intervals []string
+	weights   map[string]float64
+	scoring   screen.DivergenceScoring
+}
+
+func NewMTFConfluenceMiddleware(cfg MTFConfluenceConfig) *MTFConfluenceMiddleware {
+	intervals := make([]string, 0, len(cfg.Intervals))
+	for _, iv := range cfg.Intervals {
+		if iv = strings.ToLower(strings.TrimSpace(iv)); iv != "" {
+			intervals = append(intervals, iv)
+		}
+	}
+	return &MTFConfluenceMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "mtf_confluence"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
+		},
+		intervals: intervals,
+		weights:   cfg.Weights,
+		scoring:   cfg.DivergenceScoring,
+	}
+}
+
+func (m *MTFConfluenceMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *MTFConfluenceMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	conf := screen.ConfluenceFromCandles(m.intervals, ac.Candles, m.weights, m.scoring)
+	if len(conf.Frames) == 0 {
+		return fmt.Errorf("mtf_confluence: %v 均无蜡烛", m.intervals)
+	}
+	frames := make([]string, 0, len(conf.Frames))
+	for _, f := range conf.Frames {
+		frames = append(frames, fmt.Sprintf("%s(EMA %s, WT %s, MFI %s, 背离 %s)", strings.ToUpper(f.Interval), f.EMAAlign, f.WTState, f.MFIState, f.Divergence))
+	}
+	desc := fmt.Sprintf("多周期共振 多 %.1f / 空 %.1f，偏向 %s；%s", conf.Long, conf.Short, conf.Bias, strings.Join(frames, "；"))
+	ac.AddFeature(pipeline.Feature{
+		Key:         "mtf_confluence",
+		Label:       "MTF Confluence",
+		Value:       conf.Long - conf.Short,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata: map[string]any{
+			"long":          conf.Long,
+			"short":         conf.Short,
+			"bias":          conf.Bias,
+			"long_aligned":  conf.LongAligned,
+			"short_aligned": conf.ShortAligned,
+			"intervals":     len(conf.Frames),
+		},
+	})
+	return nil
+}
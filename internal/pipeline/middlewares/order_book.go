@@ -0,0 +1,96 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"brale/internal/market"
+	"brale/internal/pipeline"
+)
+
+type OrderBookConfig struct {
+	Name         string
+	Stage        int
+	Critical     bool
+	Timeout      time.Duration
+	BandPct      float64
+	WallMultiple float64
+	MaxAge       time.Duration
+}
+
+// OrderBookMiddleware 基于最新部分深度输出盘口买卖失衡、价差与近价挂单墙。
+// value 为 band 内的失衡度 (买量-卖量)/(买量+卖量)，范围 [-1, 1]，正值表示买盘更厚。
+type OrderBookMiddleware struct {
+	meta         pipeline.MiddlewareMeta
+	reader       market.OrderBookReader
+	bandPct      float64
+	wallMultiple float64
+	maxAge       time.Duration
+}
+
+func NewOrderBookMiddleware(cfg OrderBookConfig, reader market.OrderBookReader) *OrderBookMiddleware {
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 30 * time.Second
+	}
+	return &OrderBookMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "order_book"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+		},
+		reader:       reader,
+		bandPct:      cfg.BandPct,
+		wallMultiple: cfg.WallMultiple,
+		maxAge:       cfg.MaxAge,
+	}
+}
+
+func (m *OrderBookMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *OrderBookMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	if m.reader == nil {
+		return fmt.Errorf("order_book: 深度订阅未启用")
+	}
+	book, ok := m.reader.LatestOrderBook(ac.Symbol)
+	if !ok {
+		return fmt.Errorf("order_book: %s 暂无深度数据", ac.Symbol)
+	}
+	metrics, ok := market.ComputeOrderBookMetrics(book, m.bandPct, m.wallMultiple, time.Now())
+	if !ok {
+		return fmt.Errorf("order_book: %s 深度数据无效", ac.Symbol)
+	}
+	if time.Duration(metrics.AgeSec)*time.Second > m.maxAge {
+		return fmt.Errorf("order_book: %s 深度数据已过期 %ds", ac.Symbol, metrics.AgeSec)
+	}
+
+	meta := map[string]any{
+		"best_bid":      metrics.BestBid,
+		"best_ask":      metrics.BestAsk,
+		"spread_bps":    metrics.SpreadBps,
+		"band_pct":      metrics.BandPct,
+		"bid_depth":     metrics.BidDepth,
+		"ask_depth":     metrics.AskDepth,
+		"wall_multiple": metrics.WallMultiple,
+		"age_sec":       metrics.AgeSec,
+	}
+	desc := fmt.Sprintf("盘口 ±%.2f%% 内买卖失衡 %.2f（买 %.4f / 卖 %.4f），价差 %.2f bps",
+		metrics.BandPct, metrics.Imbalance, metrics.BidDepth, metrics.AskDepth, metrics.SpreadBps)
+	if w := metrics.BidWall; w != nil {
+		meta["bid_wall"] = map[string]any{"price": w.Price, "quantity": w.Quantity, "distance_pct": w.DistancePct, "multiple": w.Multiple}
+		desc += fmt.Sprintf("；买墙 %.4f（%.2f%%，%.1f 倍均量）", w.Price, w.DistancePct, w.Multiple)
+	}
+	if w := metrics.AskWall; w != nil {
+		meta["ask_wall"] = map[string]any{"price": w.Price, "quantity": w.Quantity, "distance_pct": w.DistancePct, "multiple": w.Multiple}
+		desc += fmt.Sprintf("；卖墙 %.4f（+%.2f%%，%.1f 倍均量）", w.Price, w.DistancePct, w.Multiple)
+	}
+	ac.AddFeature(pipeline.Feature{
+		Key:         "order_book",
+		Label:       "Order Book Imbalance",
+		Value:       metrics.Imbalance,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata:    meta,
+	})
+	return nil
+}
@@ -48,6 +48,7 @@ func NewRSIMiddleware(cfg RSIConfig) *RSIMiddleware {
 			Stage:    cfg.Stage,
 			Critical: cfg.Critical,
 			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
 		},
 		interval:   strings.ToLower(strings.TrimSpace(cfg.Interval)),
 		period:     cfg.Period,
@@ -0,0 +1,123 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/pipeline"
+)
+
+type RSIDivergenceConfig struct {
+	Name     string
+	Stage    int
+	Critical bool
+	Timeout  time.Duration
+	Interval string
+	Settings indicator.RSIDivergenceSettings
+}
+
+// RSIDivergenceMiddleware 输出最近确认的全部 RSI 背离及 A/B/C 强度分级。
+// value：最强背离的带符号得分（底背离为正、顶背离为负，A=±3），0 为无背离。
+type RSIDivergenceMiddleware struct {
+	meta     pipeline.MiddlewareMeta
+	interval string
+	settings indicator.RSIDivergenceSettings
+}
+
+func NewRSIDivergenceMiddleware(cfg RSIDivergenceConfig) *RSIDivergenceMiddleware {
+	return &RSIDivergenceMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "rsi_divergence"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
+		},
+		interval: strings.ToLower(strings.TrimSpace(cfg.Interval)),
+		settings: cfg.Settings.WithDefaults(),
+	}
+}
+
+func (m *RSIDivergenceMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *RSIDivergenceMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	interval := m.interval
+	if interval == "" {
+		interval = "1h"
+	}
+	candles := ac.Candles(interval)
+	if len(candles) <= m.settings.Period+m.settings.MaxGap {
+		return fmt.Errorf("rsi_divergence: insufficient candles %s need %d got %d", interval, m.settings.Period+m.settings.MaxGap+1, len(candles))
+	}
+	divs := indicator.DetectRSIDivergences(candles, m.settings)
+	grades := map[string]int{indicator.RSIDivergenceGradeA: 0, indicator.RSIDivergenceGradeB: 0, indicator.RSIDivergenceGradeC: 0}
+	for _, d := range divs {
+		grades[d.Grade]++
+	}
+	label := fmt.Sprintf("%s RSI Divergence", strings.ToUpper(interval))
+	meta := map[string]any{
+		"interval":    interval,
+		"period":      m.settings.Period,
+		"max_age":     m.settings.MaxAge,
+		"hidden":      m.settings.Hidden,
+		"count":       len(divs),
+		"grade_a":     grades[indicator.RSIDivergenceGradeA],
+		"grade_b":     grades[indicator.RSIDivergenceGradeB],
+		"grade_c":     grades[indicator.RSIDivergenceGradeC],
+		"bias":        indicator.RSIDivergenceBias(divs),
+		"divergences": divs,
+	}
+	best, ok := indicator.StrongestRSIDivergence(divs)
+	if !ok {
+		ac.AddFeature(pipeline.Feature{
+			Key:         "rsi_divergence",
+			Label:       label,
+			Value:       0,
+			Description: formatFeature(ac.Symbol, fmt.Sprintf("周期 %s 最近 %d 根无 RSI(%d) 背离", strings.ToUpper(interval), m.settings.MaxAge, m.settings.Period)),
+			Metadata:    meta,
+		})
+		return nil
+	}
+	value := float64(best.Score)
+	if best.Bias == "bearish" {
+		value = -value
+	}
+	meta["grade"] = best.Grade
+	meta["strongest_bias"] = best.Bias
+	meta["strongest_kind"] = best.Kind
+	meta["age"] = best.AgeBars
+	parts := make([]string, 0, 3)
+	for i, d := range divs {
+		if i >= 3 {
+			break
+		}
+		parts = append(parts, describeRSIDivergence(d))
+	}
+	desc := fmt.Sprintf("周期 %s RSI(%d) 背离 %d 组（A %d / B %d / C %d）：%s",
+		strings.ToUpper(interval), m.settings.Period, len(divs),
+		grades[indicator.RSIDivergenceGradeA], grades[indicator.RSIDivergenceGradeB], grades[indicator.RSIDivergenceGradeC],
+		strings.Join(parts, "；"))
+	ac.AddFeature(pipeline.Feature{
+		Key:         "rsi_divergence",
+		Label:       label,
+		Value:       value,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata:    meta,
+	})
+	return nil
+}
+
+func describeRSIDivergence(d indicator.RSIDivergence) string {
+	side := "底背离"
+	if d.Bias == "bearish" {
+		side = "顶背离"
+	}
+	if d.Kind == indicator.RSIDivergenceHidden {
+		side = "隐藏" + side
+	}
+	return fmt.Sprintf("%s级%s 价格 %.4f→%.4f RSI %.1f→%.1f 间隔 %d 根，%d 根前确认",
+		d.Grade, side, d.PriceFrom, d.PriceTo, d.RSIFrom, d.RSITo, d.BarsApart, d.AgeBars)
+}
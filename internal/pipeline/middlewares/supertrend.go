@@ -0,0 +1,97 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/pipeline"
+)
+
+type SupertrendConfig struct {
+	Name       string
+	Stage      int
+	Critical   bool
+	Timeout    time.Duration
+	Interval   string
+	Period     int
+	Multiplier float64
+	Alert      bool
+}
+
+// SupertrendMiddleware 输出超级趋势的轨道价、方向与距上次翻转的根数；Alert 为 true 时由引擎在方向翻转时推送提醒。
+type SupertrendMiddleware struct {
+	meta       pipeline.MiddlewareMeta
+	interval   string
+	period     int
+	multiplier float64
+	alert      bool
+}
+
+func NewSupertrendMiddleware(cfg SupertrendConfig) *SupertrendMiddleware {
+	if cfg.Period <= 0 {
+		cfg.Period = 10
+	}
+	if cfg.Multiplier <= 0 {
+		cfg.Multiplier = 3
+	}
+	return &SupertrendMiddleware{
+		meta: pipeline.MiddlewareMeta{
+			Name:     nameOrDefault(cfg.Name, "supertrend"),
+			Stage:    cfg.Stage,
+			Critical: cfg.Critical,
+			Timeout:  cfg.Timeout,
+			Requires: []string{pipeline.CapabilityCandles},
+		},
+		interval:   strings.ToLower(strings.TrimSpace(cfg.Interval)),
+		period:     cfg.Period,
+		multiplier: cfg.Multiplier,
+		alert:      cfg.Alert,
+	}
+}
+
+func (m *SupertrendMiddleware) Meta() pipeline.MiddlewareMeta { return m.meta }
+
+func (m *SupertrendMiddleware) Handle(ctx context.Context, ac *pipeline.AnalysisContext) error {
+	interval := m.interval
+	if interval == "" {
+		interval = "1h"
+	}
+	candles := ac.Candles(interval)
+	highs, lows := highsLows(candles)
+	st, err := indicator.ComputeSupertrend(highs, lows, closes(candles), m.period, m.multiplier)
+	if err != nil {
+		return fmt.Errorf("supertrend: %s %w", interval, err)
+	}
+	desc := fmt.Sprintf("周期 %s 的 Supertrend(%d,%.1f) 方向 %s，轨道 %.4f，距上次翻转 %d 根",
+		strings.ToUpper(interval), m.period, m.multiplier, st.Direction, st.Band, st.BarsSinceFlip)
+	if st.Flipped {
+		desc += "（本根刚翻转）"
+	}
+	value := st.Band
+	var flipAt int64
+	if len(candles) > 0 {
+		flipAt = candles[len(candles)-1-st.BarsSinceFlip].OpenTime
+	}
+	ac.AddFeature(pipeline.Feature{
+		Key:         "supertrend",
+		Label:       fmt.Sprintf("%s Supertrend", strings.ToUpper(interval)),
+		Value:       value,
+		Description: formatFeature(ac.Symbol, desc),
+		Metadata: map[string]any{
+			"interval":        interval,
+			"period":          m.period,
+			"multiplier":      m.multiplier,
+			"band":            st.Band,
+			"direction":       st.Direction,
+			"bars_since_flip": st.BarsSinceFlip,
+			"flipped":         st.Flipped,
+			"flip_open_time":  flipAt,
+			"alert":           m.alert,
+			"series_tail":     seriesTail(st.Series, 5),
+		},
+	})
+	return nil
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency 为未配置并发上限时同时执行的 symbol 管道数。
+const DefaultConcurrency = 4
+
+// Job 为一次按 symbol 的管道执行。
+type Job struct {
+	Symbol   string
+	Profile  string
+	Pipeline *Pipeline
+}
+
+// JobRun 为单个 Job 的执行结果，Context 在失败时仍保留已产出的特征与警告。
+type JobRun struct {
+	Symbol   string
+	Profile  string
+	Context  *AnalysisContext
+	Duration time.Duration
+	Err      error
+}
+
+// RunReport 汇总一轮并发执行：总耗时、并发度以及各 symbol 的耗时与失败原因。
+type RunReport struct {
+	StartedAt   time.Time         `json:"started_at"`
+	DurationMs  int64             `json:"duration_ms"`
+	Concurrency int               `json:"concurrency"`
+	Total       int               `json:"total"`
+	Failed      int               `json:"failed"`
+	Symbols     []SymbolRunReport `json:"symbols"`
+}
+
+type SymbolRunReport struct {
+	Symbol     string   `json:"symbol"`
+	Profile    string   `json:"profile"`
+	DurationMs int64    `json:"duration_ms"`
+	Error      string   `json:"error,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// RunAll 以最多 concurrency 个 worker 并发执行 jobs，结果与 jobs 顺序一致；
+// 各中间件超时仍由 Pipeline.Run 按 MiddlewareMeta.Timeout 控制，单个 symbol 失败不影响其余 symbol。
+func RunAll(ctx context.Context, jobs []Job, concurrency int) ([]JobRun, RunReport) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	started := time.Now()
+	runs := make([]JobRun, len(jobs))
+	var eg errgroup.Group
+	eg.SetLimit(concurrency)
+	for i, job := range jobs {
+		i, job := i, job
+		eg.Go(func() error {
+			runs[i] = runJob(ctx, job)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	report := RunReport{
+		StartedAt:   started,
+		DurationMs:  time.Since(started).Milliseconds(),
+		Concurrency: min(concurrency, max(len(jobs), 1)),
+		Total:       len(jobs),
+		Symbols:     make([]SymbolRunReport, 0, len(runs)),
+	}
+	for _, run := range runs {
+		item := SymbolRunReport{
+			Symbol:     run.Symbol,
+			Profile:    run.Profile,
+			DurationMs: run.Duration.Milliseconds(),
+		}
+		if run.Context != nil {
+			item.Warnings = run.Context.Warnings()
+		}
+		if run.Err != nil {
+			item.Error = run.Err.Error()
+			report.Failed++
+		}
+		report.Symbols = append(report.Symbols, item)
+	}
+	return runs, report
+}
+
+func runJob(ctx context.Context, job Job) JobRun {
+	run := JobRun{Symbol: job.Symbol, Profile: job.Profile}
+	if ctx.Err() != nil {
+		run.Err = ctx.Err()
+		return run
+	}
+	start := time.Now()
+	ac := NewContext(job.Symbol)
+	ac.Profile = job.Profile
+	run.Context = ac
+	if job.Pipeline != nil {
+		run.Err = job.Pipeline.Run(ctx, ac)
+	}
+	run.Duration = time.Since(start)
+	return run
+}
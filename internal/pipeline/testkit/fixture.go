@@ -0,0 +1,215 @@
+// Package testkit 把 pipeline.AnalysisContext 录制为 JSON fixture，并在测试中回放给任意 middleware，
+// 用真实行情替代手写 K 线数组，便于为 ema_trend、rsi 等指标计算编写回归测试。
+// 运行中的实例可通过 GET /api/live/pipeline/fixture?symbol=BTCUSDT&trim=300 下载 fixture，
+// 放入测试包的 testdata 目录后用 MustLoad/MustReplay/AssertGolden 回放（示例见 middlewares/ema_trend_test.go）。
+package testkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/market"
+	"brale/internal/pipeline"
+)
+
+// FixtureVersion 为 fixture 文件格式版本，结构不兼容变化时递增。
+const FixtureVersion = 1
+
+// Fixture 为一次 AnalysisContext 的快照：各周期 K 线与录制时已有的特征、提示词片段和告警。
+type Fixture struct {
+	Version    int                        `json:"version"`
+	Symbol     string                     `json:"symbol"`
+	Profile    string                     `json:"profile,omitempty"`
+	ContextTag string                     `json:"context_tag,omitempty"`
+	RecordedAt time.Time                  `json:"recorded_at"`
+	Intervals  map[string][]market.Candle `json:"intervals"`
+	Features   []FixtureFeature           `json:"features,omitempty"`
+	Prompts    map[string][]string        `json:"prompts,omitempty"`
+	Warnings   []string                   `json:"warnings,omitempty"`
+	Metadata   map[string]json.RawMessage `json:"metadata,omitempty"`
+}
+
+// FixtureFeature 为可序列化的 pipeline.Feature；Metadata 回放后数值统一为 float64。
+type FixtureFeature struct {
+	Key         string         `json:"key"`
+	Label       string         `json:"label,omitempty"`
+	Value       float64        `json:"value"`
+	Description string         `json:"description,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+// Record 录制 ac 的当前状态；无法 JSON 序列化的 metadata 值会被跳过。
+func Record(ac *pipeline.AnalysisContext) Fixture {
+	fx := Fixture{
+		Version:   FixtureVersion,
+		Intervals: make(map[string][]market.Candle),
+	}
+	if ac == nil {
+		return fx
+	}
+	fx.Symbol = ac.Symbol
+	fx.Profile = ac.Profile
+	fx.ContextTag = ac.ContextTag
+	fx.RecordedAt = time.Now().UTC()
+	for _, iv := range ac.Intervals() {
+		fx.Intervals[iv] = ac.Candles(iv)
+	}
+	for _, f := range ac.Features() {
+		fx.Features = append(fx.Features, fromFeature(f))
+	}
+	if prompts := ac.PromptParts(); len(prompts) > 0 {
+		fx.Prompts = prompts
+	}
+	fx.Warnings = ac.Warnings()
+	for k, v := range ac.Metadata() {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		if fx.Metadata == nil {
+			fx.Metadata = make(map[string]json.RawMessage)
+		}
+		fx.Metadata[k] = raw
+	}
+	return fx
+}
+
+// SaveFixture 录制 ac 并写入 path（缩进 JSON），父目录不存在时自动创建。
+func SaveFixture(path string, ac *pipeline.AnalysisContext) error {
+	return WriteFixture(path, Record(ac))
+}
+
+// WriteFixture 把 fx 写入 path。
+func WriteFixture(path string, fx Fixture) error {
+	data, err := json.MarshalIndent(fx, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadFixture 读取 fixture 文件。
+func LoadFixture(path string) (Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+	var fx Fixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return Fixture{}, fmt.Errorf("解析 fixture %s 失败: %w", path, err)
+	}
+	if fx.Version > FixtureVersion {
+		return Fixture{}, fmt.Errorf("fixture %s 版本 %d 高于支持的 %d", path, fx.Version, FixtureVersion)
+	}
+	return fx, nil
+}
+
+// Context 按 fixture 重建一个新的 AnalysisContext，每次调用互不影响。
+// 录制时的 metadata 以 json.RawMessage 形式放回，middleware 需自行解码。
+func (fx Fixture) Context() *pipeline.AnalysisContext {
+	ac := pipeline.NewContext(fx.Symbol)
+	ac.Profile = fx.Profile
+	if tag := strings.TrimSpace(fx.ContextTag); tag != "" {
+		ac.ContextTag = tag
+	}
+	if !fx.RecordedAt.IsZero() {
+		ac.StartedAt = fx.RecordedAt
+	}
+	for iv, candles := range fx.Intervals {
+		ac.SetCandles(iv, candles)
+	}
+	for _, f := range fx.Features {
+		ac.AddFeature(f.toFeature())
+	}
+	sections := make([]string, 0, len(fx.Prompts))
+	for sec := range fx.Prompts {
+		sections = append(sections, sec)
+	}
+	sort.Strings(sections)
+	for _, sec := range sections {
+		ac.AppendPromptPart(sec, fx.Prompts[sec]...)
+	}
+	for _, w := range fx.Warnings {
+		ac.AddWarning(w)
+	}
+	for k, raw := range fx.Metadata {
+		ac.SetMetadata(k, raw)
+	}
+	return ac
+}
+
+// Trim 只保留每个周期最后 n 根 K 线，用于把录制的长序列裁剪成精简 fixture。
+func (fx Fixture) Trim(n int) Fixture {
+	if n <= 0 {
+		return fx
+	}
+	out := fx
+	out.Intervals = make(map[string][]market.Candle, len(fx.Intervals))
+	for iv, candles := range fx.Intervals {
+		if len(candles) > n {
+			candles = candles[len(candles)-n:]
+		}
+		cp := make([]market.Candle, len(candles))
+		copy(cp, candles)
+		out.Intervals[iv] = cp
+	}
+	return out
+}
+
+// CandlesOnly 只保留各周期 K 线，去掉录制时已有的特征、提示词、告警与 metadata，
+// 用于从完整 pipeline 运行中录制的 fixture 回放单个 middleware。
+func (fx Fixture) CandlesOnly() Fixture {
+	out := fx
+	out.Features, out.Prompts, out.Warnings, out.Metadata = nil, nil, nil, nil
+	return out
+}
+
+func fromFeature(f pipeline.Feature) FixtureFeature {
+	return FixtureFeature{
+		Key:         f.Key,
+		Label:       f.Label,
+		Value:       f.Value,
+		Description: f.Description,
+		Metadata:    jsonSafeMetadata(f.Metadata),
+	}
+}
+
+// jsonSafeMetadata 经 JSON 往返归一化特征 metadata，丢弃无法序列化的值，保证录制与回放结果可直接比较。
+func jsonSafeMetadata(in map[string]any) map[string]any {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(in))
+	for k, v := range in {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		var decoded any
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			continue
+		}
+		out[k] = decoded
+	}
+	return out
+}
+
+func (f FixtureFeature) toFeature() pipeline.Feature {
+	return pipeline.Feature{
+		Key:         f.Key,
+		Label:       f.Label,
+		Value:       f.Value,
+		Description: f.Description,
+		Metadata:    f.Metadata,
+	}
+}
@@ -0,0 +1,166 @@
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+
+	"brale/internal/pipeline"
+)
+
+// UpdateGoldenEnv 非空时 AssertGolden 用本次回放结果覆盖 golden 文件，而不是比较。
+const UpdateGoldenEnv = "BRALE_UPDATE_GOLDEN"
+
+// TB 为 testing.TB 中断言辅助函数用到的子集；不直接依赖 testing，live 服务录制 fixture 时可安全引入本包。
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// Result 为一次回放新增的输出：Features 按 Key 排序，Prompts/Warnings 只含 middleware 新写入的部分。
+type Result struct {
+	Features []FixtureFeature    `json:"features"`
+	Prompts  map[string][]string `json:"prompts,omitempty"`
+	Warnings []string            `json:"warnings,omitempty"`
+
+	Context *pipeline.AnalysisContext `json:"-"`
+}
+
+// Replay 用 fixture 重建上下文，按 Stage 顺序运行 mws（与线上 pipeline 相同的分组并发语义），返回新增输出。
+func Replay(ctx context.Context, fx Fixture, mws ...pipeline.Middleware) (Result, error) {
+	ac := fx.Context()
+	baseFeatures := len(ac.Features())
+	basePrompts := ac.PromptParts()
+	baseWarnings := len(ac.Warnings())
+
+	err := pipeline.New("testkit", mws...).Run(ctx, ac)
+
+	res := Result{Context: ac}
+	for _, f := range ac.Features()[baseFeatures:] {
+		res.Features = append(res.Features, fromFeature(f))
+	}
+	sort.SliceStable(res.Features, func(i, j int) bool { return res.Features[i].Key < res.Features[j].Key })
+	for sec, lines := range ac.PromptParts() {
+		if added := lines[len(basePrompts[sec]):]; len(added) > 0 {
+			if res.Prompts == nil {
+				res.Prompts = make(map[string][]string)
+			}
+			res.Prompts[sec] = added
+		}
+	}
+	if warnings := ac.Warnings(); len(warnings) > baseWarnings {
+		res.Warnings = warnings[baseWarnings:]
+	}
+	return res, err
+}
+
+// Feature 按 Key 查找回放产生的特征。
+func (r Result) Feature(key string) (FixtureFeature, bool) {
+	for _, f := range r.Features {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return FixtureFeature{}, false
+}
+
+// Round 把特征数值及 metadata 中的浮点数四舍五入到 places 位小数，
+// 使 golden 文件不受不同架构浮点运算（如 FMA）末位差异的影响。
+func (r Result) Round(places int) Result {
+	out := r
+	out.Features = make([]FixtureFeature, len(r.Features))
+	for i, f := range r.Features {
+		f.Value = roundFloat(f.Value, places)
+		if f.Metadata != nil {
+			f.Metadata = roundAny(f.Metadata, places).(map[string]any)
+		}
+		out.Features[i] = f
+	}
+	return out
+}
+
+func roundAny(v any, places int) any {
+	switch val := v.(type) {
+	case float64:
+		return roundFloat(val, places)
+	case map[string]any:
+		cp := make(map[string]any, len(val))
+		for k, item := range val {
+			cp[k] = roundAny(item, places)
+		}
+		return cp
+	case []any:
+		cp := make([]any, len(val))
+		for i, item := range val {
+			cp[i] = roundAny(item, places)
+		}
+		return cp
+	}
+	return v
+}
+
+func roundFloat(v float64, places int) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	scale := math.Pow10(places)
+	return math.Round(v*scale) / scale
+}
+
+// MustLoad 读取 fixture，失败时终止测试。
+func MustLoad(t TB, path string) Fixture {
+	t.Helper()
+	fx, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	return fx
+}
+
+// MustReplay 回放并在 pipeline 返回错误时终止测试。
+func MustReplay(t TB, fx Fixture, mws ...pipeline.Middleware) Result {
+	t.Helper()
+	res, err := Replay(context.Background(), fx, mws...)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	return res
+}
+
+// AssertFeature 断言特征存在且数值与 want 的差不超过 tol。
+func AssertFeature(t TB, r Result, key string, want, tol float64) {
+	t.Helper()
+	f, ok := r.Feature(key)
+	if !ok {
+		t.Fatalf("feature %s not produced; got %d features", key, len(r.Features))
+	}
+	if math.Abs(f.Value-want) > tol {
+		t.Fatalf("feature %s = %.8f, want %.8f (tol %.g)", key, f.Value, want, tol)
+	}
+}
+
+// AssertGolden 把回放结果与 golden JSON 比较；设置 BRALE_UPDATE_GOLDEN 时改为写入 golden 文件。
+func AssertGolden(t TB, path string, r Result) {
+	t.Helper()
+	got, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	got = append(got, '\n')
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden %s: %v (set %s=1 to create it)", path, err, UpdateGoldenEnv)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(want)) {
+		t.Fatalf("golden mismatch %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 交割合约默认在到期日 08:00 UTC 结算（Binance / OKX 一致）。
+const settlementHourUTC = 8
+
+// Calendar 维护交割合约的到期时间：优先使用显式配置，否则从 symbol 后缀（BTCUSDT_240628、BTC-USD-240628、
+// BTC/USDT:USDT-240628）解析；无到期日的永续合约不受任何限制。
+type Calendar struct {
+	entryCutoff time.Duration
+	closeBefore time.Duration
+
+	mu        sync.RWMutex
+	overrides map[string]time.Time
+}
+
+// NewCalendar 创建合约日历；entryCutoff 为到期前停止开仓的时长，closeBefore 为到期前强制平仓的时长，<=0 表示不启用对应规则。
+func NewCalendar(entryCutoff, closeBefore time.Duration, overrides map[string]time.Time) *Calendar {
+	c := &Calendar{
+		entryCutoff: entryCutoff,
+		closeBefore: closeBefore,
+		overrides:   make(map[string]time.Time, len(overrides)),
+	}
+	for sym, ts := range overrides {
+		c.SetExpiry(sym, ts)
+	}
+	return c
+}
+
+// SetExpiry 覆盖 symbol 的到期时间（例如从交易所 deliveryDate 同步）；零值表示删除覆盖。
+func (c *Calendar) SetExpiry(symbol string, expiry time.Time) {
+	if c == nil {
+		return
+	}
+	key := normalize(symbol)
+	if key == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if expiry.IsZero() {
+		delete(c.overrides, key)
+		return
+	}
+	c.overrides[key] = expiry.UTC()
+}
+
+// Expiry 返回 symbol 的到期时间；永续合约返回 false。
+func (c *Calendar) Expiry(symbol string) (time.Time, bool) {
+	if c == nil {
+		return time.Time{}, false
+	}
+	c.mu.RLock()
+	ts, ok := c.overrides[normalize(symbol)]
+	c.mu.RUnlock()
+	if ok {
+		return ts, true
+	}
+	return ParseExpiry(symbol)
+}
+
+// TimeToExpiry 返回距到期的剩余时长（已过期为负）；永续合约返回 false。
+func (c *Calendar) TimeToExpiry(symbol string, now time.Time) (time.Duration, bool) {
+	expiry, ok := c.Expiry(symbol)
+	if !ok {
+		return 0, false
+	}
+	return expiry.Sub(now), true
+}
+
+// DaysToExpiry 返回距到期的剩余天数（小数）；永续合约返回 false。
+func (c *Calendar) DaysToExpiry(symbol string, now time.Time) (float64, bool) {
+	left, ok := c.TimeToExpiry(symbol, now)
+	if !ok {
+		return 0, false
+	}
+	return left.Hours() / 24, true
+}
+
+// EntryBlocked 判断是否已进入到期前禁止开仓的窗口，返回原因供日志使用。
+func (c *Calendar) EntryBlocked(symbol string, now time.Time) (bool, string) {
+	if c == nil || c.entryCutoff <= 0 {
+		return false, ""
+	}
+	left, ok := c.TimeToExpiry(symbol, now)
+	if !ok || left > c.entryCutoff {
+		return false, ""
+	}
+	if left <= 0 {
+		return true, "合约已到期"
+	}
+	return true, fmt.Sprintf("距交割 %s，低于开仓截止 %s", formatDuration(left), formatDuration(c.entryCutoff))
+}
+
+// ShouldClose 判断持仓是否已进入交割前强制平仓窗口。
+func (c *Calendar) ShouldClose(symbol string, now time.Time) bool {
+	if c == nil || c.closeBefore <= 0 {
+		return false
+	}
+	left, ok := c.TimeToExpiry(symbol, now)
+	return ok && left <= c.closeBefore
+}
+
+// ParseExpiry 从 symbol 末尾的 YYMMDD 后缀解析交割时间（结算日 08:00 UTC）。
+func ParseExpiry(symbol string) (time.Time, bool) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+	idx := strings.LastIndexAny(s, "_-")
+	if idx < 0 || len(s)-idx-1 != 6 {
+		return time.Time{}, false
+	}
+	day, err := time.Parse("060102", s[idx+1:])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day.Add(settlementHourUTC * time.Hour), true
+}
+
+func normalize(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+func formatDuration(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%.1fd", d.Hours()/24)
+	}
+	return fmt.Sprintf("%.1fh", d.Hours())
+}
@@ -9,6 +9,8 @@ const DefaultStakeCurrency = "USDT"
 
 type FreqtradeConverter struct {
 	StakeCurrency string
+	// Spot 为 true 时输出现货/杠杆现货交易对（BTC/USDT），否则输出合约交易对（BTC/USDT:USDT）。
+	Spot bool
 }
 
 func NewFreqtradeConverter(stakeCurrency string) FreqtradeConverter {
@@ -28,6 +30,13 @@ func (c FreqtradeConverter) ToExchange(internal string) string {
 		stake = DefaultStakeCurrency
 	}
 
+	if c.Spot {
+		if sym := Parse(s); sym.Base != "" && sym.Quote != "" {
+			return sym.Internal()
+		}
+		return s
+	}
+
 	if strings.Contains(s, ":") {
 		return s
 	}
@@ -72,3 +81,10 @@ func (c FreqtradeConverter) Format() Format {
 func Freqtrade(stakeCurrency string) FreqtradeConverter {
 	return NewFreqtradeConverter(stakeCurrency)
 }
+
+// FreqtradeSpot 返回现货交易对格式的转换器（不带结算币后缀）。
+func FreqtradeSpot(stakeCurrency string) FreqtradeConverter {
+	c := NewFreqtradeConverter(stakeCurrency)
+	c.Spot = true
+	return c
+}
@@ -0,0 +1,34 @@
+package symbol
+
+import "strings"
+
+// okxSwapSuffix 为 OKX 永续合约 instId 后缀，例如 BTC-USDT-SWAP。
+const okxSwapSuffix = "-SWAP"
+
+type OKXConverter struct{}
+
+func (OKXConverter) ToExchange(internal string) string {
+	sym := Parse(internal)
+	if sym.Base == "" || sym.Quote == "" {
+		return ""
+	}
+	return sym.Base + "-" + sym.Quote + okxSwapSuffix
+}
+
+func (OKXConverter) FromExchange(raw string) string {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	if s == "" {
+		return ""
+	}
+	s = strings.TrimSuffix(s, okxSwapSuffix)
+	if parts := strings.SplitN(s, "-", 2); len(parts) == 2 {
+		return parts[0] + "/" + parts[1]
+	}
+	return Parse(s).Internal()
+}
+
+func (OKXConverter) Format() Format {
+	return FormatOKX
+}
+
+var OKX = OKXConverter{}
@@ -11,6 +11,7 @@ const (
 	FormatBinance   Format = "binance"
 	FormatFreqtrade Format = "freqtrade"
 	FormatGate      Format = "gate"
+	FormatOKX       Format = "okx"
 )
 
 type Converter interface {
@@ -0,0 +1,100 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultImpactRefUSD   = 10000
+	defaultImpactExponent = 0.5
+)
+
+// ExecutionModel 描述模拟成交的非理想因素，供回测与模拟盘共用，保证两者结果可比：
+//   - 入场延迟：信号后延迟 EntryDelayBars 根 K 线，再在该根内延迟 EntryDelaySeconds 秒成交；
+//   - 滑点：半个点差 + 冲击成本，冲击 = ImpactBps × (名义金额 / ImpactRefUSD)^ImpactExponent；
+//   - 手续费：市价单按 TakerFeeRate，止盈限价单（TakeProfitMaker=true）按 MakerFeeRate。
+type ExecutionModel struct {
+	EntryDelayBars    int     `json:"entry_delay_bars"`
+	EntryDelaySeconds int     `json:"entry_delay_seconds"`
+	SpreadBps         float64 `json:"spread_bps"`
+	ImpactBps         float64 `json:"impact_bps"`
+	ImpactRefUSD      float64 `json:"impact_ref_usd"`
+	ImpactExponent    float64 `json:"impact_exponent"`
+	MakerFeeRate      float64 `json:"maker_fee_rate"`
+	TakerFeeRate      float64 `json:"taker_fee_rate"`
+	TakeProfitMaker   bool    `json:"take_profit_maker"`
+}
+
+// Normalize 补齐冲击函数默认值（1 万 USD 参考名义、平方根冲击）。
+func (m *ExecutionModel) Normalize() {
+	if m.ImpactRefUSD <= 0 {
+		m.ImpactRefUSD = defaultImpactRefUSD
+	}
+	if m.ImpactExponent <= 0 {
+		m.ImpactExponent = defaultImpactExponent
+	}
+	if m.EntryDelayBars < 0 {
+		m.EntryDelayBars = 0
+	}
+	if m.EntryDelaySeconds < 0 {
+		m.EntryDelaySeconds = 0
+	}
+}
+
+func (m ExecutionModel) Validate() error {
+	if m.SpreadBps < 0 || m.ImpactBps < 0 {
+		return fmt.Errorf("execution: spread_bps / impact_bps 需 >= 0")
+	}
+	if m.MakerFeeRate < -0.01 || m.MakerFeeRate > 0.01 || m.TakerFeeRate < 0 || m.TakerFeeRate > 0.01 {
+		return fmt.Errorf("execution: 手续费率超出合理范围 (maker=%.6f taker=%.6f)", m.MakerFeeRate, m.TakerFeeRate)
+	}
+	return nil
+}
+
+// SlippageRatio 返回相对参考价的不利偏移比例。
+func (m ExecutionModel) SlippageRatio(notionalUSD float64) float64 {
+	slip := m.SpreadBps / 2 / 1e4
+	if m.ImpactBps > 0 && notionalUSD > 0 {
+		ref, exp := m.ImpactRefUSD, m.ImpactExponent
+		if ref <= 0 {
+			ref = defaultImpactRefUSD
+		}
+		if exp <= 0 {
+			exp = defaultImpactExponent
+		}
+		slip += m.ImpactBps / 1e4 * math.Pow(notionalUSD/ref, exp)
+	}
+	return slip
+}
+
+// FillPrice 在参考价上施加滑点：买入上移、卖出下移。
+func (m ExecutionModel) FillPrice(ref float64, buy bool, notionalUSD float64) float64 {
+	if ref <= 0 {
+		return ref
+	}
+	slip := m.SlippageRatio(notionalUSD)
+	if buy {
+		return ref * (1 + slip)
+	}
+	return ref * (1 - slip)
+}
+
+// Fee 返回一次成交的手续费。
+func (m ExecutionModel) Fee(notionalUSD float64, maker bool) float64 {
+	if maker {
+		return notionalUSD * m.MakerFeeRate
+	}
+	return notionalUSD * m.TakerFeeRate
+}
+
+// DelayedEntryRef 估算延迟成交的参考价：在 K 线内按延迟占周期的比例在开盘与收盘之间线性插值。
+// 只有 OHLC 时无法还原真实路径，该近似偏向保守且与模拟盘按秒延迟的效果一致。
+func (m ExecutionModel) DelayedEntryRef(open, close float64, barDur time.Duration) float64 {
+	if m.EntryDelaySeconds <= 0 || barDur <= 0 {
+		return open
+	}
+	frac := math.Min(float64(m.EntryDelaySeconds)*float64(time.Second)/float64(barDur), 1)
+	return open + (close-open)*frac
+}
@@ -1,6 +1,9 @@
 package profile
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"text/template"
@@ -8,12 +11,18 @@ import (
 	"brale/internal/config/loader"
 	"brale/internal/logger"
 	"brale/internal/pipeline"
+	"brale/internal/strategy"
 )
 
 type MiddlewareFactory interface {
 	Build(cfg loader.MiddlewareConfig, profile loader.ProfileDefinition) (pipeline.Middleware, error)
 }
 
+// MiddlewareOrderer 由支持依赖校验的工厂实现，构建完整个中间件列表后调用。
+type MiddlewareOrderer interface {
+	Order(profile loader.ProfileDefinition, mws []pipeline.Middleware) ([]pipeline.Middleware, error)
+}
+
 type Runtime struct {
 	Definition           loader.ProfileDefinition
 	Pipeline             *pipeline.Pipeline
@@ -26,6 +35,8 @@ type Runtime struct {
 	Derivatives          loader.DerivativesConfig
 	AgentEnabled         bool
 	KlineWindowsEnabled  bool
+	// PromptVersion 为已加载 system/user 提示词的内容哈希，随决策审计记录。
+	PromptVersion string
 }
 
 type Manager struct {
@@ -36,6 +47,8 @@ type Manager struct {
 	profiles    map[string]*Runtime
 	symbolIndex map[string]*Runtime
 	defaultProf *Runtime
+	// overrides 为运行时手动切换的 symbol→profile 名称，优先于 targets 索引；不写回配置文件，重启后失效。
+	overrides map[string]string
 }
 
 func NewManager(ld *loader.ProfileLoader, factory MiddlewareFactory, promptLoader PromptLoader) *Manager {
@@ -55,6 +68,11 @@ func (m *Manager) Resolve(symbol string) (*Runtime, bool) {
 	sym := strings.ToUpper(strings.TrimSpace(symbol))
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	if name, ok := m.overrides[sym]; ok {
+		if rt, ok := m.profiles[name]; ok && rt != nil {
+			return rt, true
+		}
+	}
 	if rt, ok := m.symbolIndex[sym]; ok {
 		return rt, true
 	}
@@ -64,6 +82,56 @@ func (m *Manager) Resolve(symbol string) (*Runtime, bool) {
 	return nil, false
 }
 
+// SwitchProfile 把 symbol 运行时切换到指定 profile；name 为空时取消切换、恢复按 targets 匹配。
+// 仅允许已被某个 profile 监控的 symbol，避免切到未订阅行情的交易对。
+func (m *Manager) SwitchProfile(symbol, name string) error {
+	if m == nil {
+		return fmt.Errorf("profile manager 未初始化")
+	}
+	sym := strings.ToUpper(strings.TrimSpace(symbol))
+	name = strings.TrimSpace(name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.symbolIndex[sym]; !ok {
+		return fmt.Errorf("symbol %s 不在任何 profile 的 targets 中", sym)
+	}
+	if name == "" {
+		delete(m.overrides, sym)
+		return nil
+	}
+	if _, ok := m.profiles[name]; !ok {
+		return fmt.Errorf("profile %s 不存在", name)
+	}
+	if m.overrides == nil {
+		m.overrides = make(map[string]string)
+	}
+	m.overrides[sym] = name
+	logger.Infof("profile manager: %s 手动切换到 profile %s", sym, name)
+	return nil
+}
+
+// Overrides 返回运行时手动切换的 symbol→profile。
+func (m *Manager) Overrides() map[string]string {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]string, len(m.overrides))
+	for sym, name := range m.overrides {
+		out[sym] = name
+	}
+	return out
+}
+
+// StreamPriority 返回 symbol 所属 profile 的 WS 订阅优先级。
+func (m *Manager) StreamPriority(symbol string) int {
+	if rt, ok := m.Resolve(symbol); ok && rt != nil {
+		return rt.Definition.Priority
+	}
+	return 0
+}
+
 func (m *Manager) Profiles() []*Runtime {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -74,42 +142,29 @@ func (m *Manager) Profiles() []*Runtime {
 	return out
 }
 
+// rebuild 只重建定义发生变化的 profile；未变化的 Runtime（含 pipeline 实例）原样复用。
 func (m *Manager) rebuild(snapshot loader.ProfileSnapshot) {
 	if m.factory == nil {
 		logger.Warnf("profile manager skip rebuild: no factory")
 		return
 	}
+	m.mu.RLock()
+	prev := m.profiles
+	m.mu.RUnlock()
 	newProfiles := make(map[string]*Runtime)
 	newIndex := make(map[string]*Runtime)
 	var defaultRt *Runtime
+	var rebuilt, reused []string
 	for name, def := range snapshot.Profiles {
-		mws := buildMiddlewares(m.factory, def)
-		if len(mws) == 0 {
-			logger.Warnf("profile %s has no valid middlewares", name)
-			continue
-		}
-		sysPrompts := m.loadSystemPrompts(def.Name, def.Prompts.SystemByModel)
-		userPrompt := m.loadPrompt(def.Name, def.Prompts.User)
-		var userTpl *template.Template
-		if strings.TrimSpace(userPrompt) != "" {
-			var err error
-			userTpl, err = template.New(def.Name + "_user_prompt").Parse(userPrompt)
-			if err != nil {
-				logger.Warnf("profile %s user prompt 模板解析失败: %v", def.Name, err)
+		rt, ok := prev[name]
+		if ok && rt != nil && reflect.DeepEqual(rt.Definition, def) {
+			reused = append(reused, name)
+		} else {
+			rt = m.buildRuntime(name, def)
+			if rt == nil {
+				continue
 			}
-		}
-		rt := &Runtime{
-			Definition:           def,
-			Pipeline:             pipeline.New(name, mws...),
-			SystemPromptsByModel: sysPrompts,
-			UserPrompt:           userPrompt,
-			UserTemplate:         userTpl,
-			AnalysisSlice:        def.AnalysisSlice,
-			SliceDropTail:        def.SliceDropTail,
-			IndicatorBars:        estimateIndicatorBars(def),
-			Derivatives:          def.Derivatives,
-			AgentEnabled:         def.AgentEnabled(),
-			KlineWindowsEnabled:  def.KlineWindowsEnabled(),
+			rebuilt = append(rebuilt, name)
 		}
 		newProfiles[name] = rt
 		if def.Default {
@@ -119,12 +174,132 @@ func (m *Manager) rebuild(snapshot loader.ProfileSnapshot) {
 			newIndex[sym] = rt
 		}
 	}
+	var removed []string
+	for name := range prev {
+		if _, ok := newProfiles[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
 	m.mu.Lock()
 	m.profiles = newProfiles
 	m.symbolIndex = newIndex
 	m.defaultProf = defaultRt
 	m.mu.Unlock()
-	logger.Infof("profile manager rebuilt %d profiles (default=%v)", len(newProfiles), defaultRt != nil)
+	sort.Strings(rebuilt)
+	sort.Strings(removed)
+	logger.Infof("profile manager rebuilt %d profiles (default=%v) rebuilt=%v reused=%d removed=%v", len(newProfiles), defaultRt != nil, rebuilt, len(reused), removed)
+}
+
+func (m *Manager) buildRuntime(name string, def loader.ProfileDefinition) *Runtime {
+	mws := buildMiddlewares(m.factory, def)
+	if len(mws) == 0 {
+		logger.Warnf("profile %s has no valid middlewares", name)
+		return nil
+	}
+	if orderer, ok := m.factory.(MiddlewareOrderer); ok {
+		ordered, err := orderer.Order(def, mws)
+		if err != nil {
+			logger.Errorf("profile %s 中间件依赖校验失败，已跳过: %v", name, err)
+			return nil
+		}
+		mws = ordered
+	}
+	rt := &Runtime{
+		Definition:          def,
+		Pipeline:            pipeline.New(name, mws...),
+		AnalysisSlice:       def.AnalysisSlice,
+		SliceDropTail:       def.SliceDropTail,
+		IndicatorBars:       estimateIndicatorBars(def),
+		Derivatives:         def.Derivatives,
+		AgentEnabled:        def.AgentEnabled(),
+		KlineWindowsEnabled: def.KlineWindowsEnabled(),
+	}
+	m.applyPrompts(rt)
+	return rt
+}
+
+// applyPrompts 按 profile 定义加载提示词并计算版本哈希。
+func (m *Manager) applyPrompts(rt *Runtime) {
+	def := rt.Definition
+	rt.SystemPromptsByModel = m.loadSystemPrompts(def.Name, def.Prompts.SystemByModel)
+	rt.UserPrompt = m.loadPrompt(def.Name, def.Prompts.User)
+	rt.UserTemplate = nil
+	if strings.TrimSpace(rt.UserPrompt) != "" {
+		tpl, err := template.New(def.Name + "_user_prompt").Parse(rt.UserPrompt)
+		if err != nil {
+			logger.Warnf("profile %s user prompt 模板解析失败: %v", def.Name, err)
+		}
+		rt.UserTemplate = tpl
+	}
+	rt.PromptVersion = runtimePromptVersion(rt.SystemPromptsByModel, rt.UserPrompt)
+}
+
+// RefreshPrompts 在提示词文件变化后重新加载各 profile 的提示词；pipeline 实例原样复用，
+// 只有版本变化的 profile 会替换为新的 Runtime 副本，避免与正在进行的决策共享可变字段。
+func (m *Manager) RefreshPrompts() {
+	if m == nil {
+		return
+	}
+	m.mu.RLock()
+	prev := m.profiles
+	m.mu.RUnlock()
+	replaced := make(map[*Runtime]*Runtime)
+	var refreshed []string
+	for name, rt := range prev {
+		if rt == nil {
+			continue
+		}
+		next := *rt
+		m.applyPrompts(&next)
+		if next.PromptVersion == rt.PromptVersion {
+			continue
+		}
+		replaced[rt] = &next
+		refreshed = append(refreshed, name)
+	}
+	if len(replaced) == 0 {
+		return
+	}
+	m.mu.Lock()
+	profiles := make(map[string]*Runtime, len(m.profiles))
+	for name, rt := range m.profiles {
+		if next, ok := replaced[rt]; ok {
+			rt = next
+		}
+		profiles[name] = rt
+	}
+	index := make(map[string]*Runtime, len(m.symbolIndex))
+	for sym, rt := range m.symbolIndex {
+		if next, ok := replaced[rt]; ok {
+			rt = next
+		}
+		index[sym] = rt
+	}
+	if next, ok := replaced[m.defaultProf]; ok {
+		m.defaultProf = next
+	}
+	m.profiles = profiles
+	m.symbolIndex = index
+	m.mu.Unlock()
+	sort.Strings(refreshed)
+	logger.Infof("profile manager: 提示词已热更新 profiles=%v", refreshed)
+}
+
+func runtimePromptVersion(sysPrompts map[string]string, userPrompt string) string {
+	if len(sysPrompts) == 0 && strings.TrimSpace(userPrompt) == "" {
+		return ""
+	}
+	models := make([]string, 0, len(sysPrompts))
+	for model := range sysPrompts {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	var b strings.Builder
+	for _, model := range models {
+		b.WriteString("system:" + model + "\n" + sysPrompts[model] + "\n")
+	}
+	b.WriteString("user\n" + userPrompt)
+	return strategy.PromptVersion(b.String())
 }
 
 func buildMiddlewares(factory MiddlewareFactory, def loader.ProfileDefinition) []pipeline.Middleware {
@@ -182,15 +357,7 @@ func (m *Manager) loadSystemPrompts(profileName string, refs map[string]string)
 	return out
 }
 
-const defaultIndicatorBars = 240
-
+// estimateIndicatorBars 取分析窗口与指标预热需求（按中间件周期自动推算）的较大值。
 func estimateIndicatorBars(def loader.ProfileDefinition) int {
-	need := def.AnalysisSlice + def.SliceDropTail
-	if need < defaultIndicatorBars {
-		need = defaultIndicatorBars
-	}
-	if need <= 0 {
-		need = defaultIndicatorBars
-	}
-	return need
+	return max(def.AnalysisSlice+def.SliceDropTail, def.IndicatorWarmupBars())
 }
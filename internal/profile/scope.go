@@ -0,0 +1,48 @@
+package profile
+
+import (
+	"context"
+	"strings"
+)
+
+type scopedProfileKey struct{}
+
+// WithProfile 让 ctx 内的 ResolveContext 固定解析到 name 对应的 profile，不改动全局 overrides（用于 dry-run 等临时评估）。
+func WithProfile(ctx context.Context, name string) context.Context {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, scopedProfileKey{}, name)
+}
+
+// ResolveContext 优先使用 ctx 中 WithProfile 指定的 profile，未指定或不存在时同 Resolve。
+func (m *Manager) ResolveContext(ctx context.Context, symbol string) (*Runtime, bool) {
+	if ctx != nil {
+		if name, ok := ctx.Value(scopedProfileKey{}).(string); ok {
+			if rt, ok := m.Lookup(name); ok {
+				return rt, true
+			}
+		}
+	}
+	return m.Resolve(symbol)
+}
+
+// Lookup 按名称（忽略大小写）查找 profile。
+func (m *Manager) Lookup(name string) (*Runtime, bool) {
+	if m == nil {
+		return nil, false
+	}
+	name = strings.TrimSpace(name)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if rt, ok := m.profiles[name]; ok && rt != nil {
+		return rt, true
+	}
+	for key, rt := range m.profiles {
+		if rt != nil && strings.EqualFold(key, name) {
+			return rt, true
+		}
+	}
+	return nil, false
+}
@@ -199,6 +199,7 @@ func listComponents() []comboComponent {
 	var res []comboComponent
 	res = append(res, listTakeProfitComponents()...)
 	res = append(res, listStopLossComponents()...)
+	res = append(res, listTimeComponents()...)
 	return res
 }
 
@@ -294,6 +295,36 @@ func listStopLossComponents() []comboComponent {
 	}
 }
 
+// listTimeComponents 为时间退出组件，只能追加在止盈/止损组合之后（如 tp_tiers__sl_single__time_max_hold）。
+func listTimeComponents() []comboComponent {
+	return []comboComponent{
+		{
+			Key:         "time_max_hold",
+			Alias:       "time_max_hold",
+			Handler:     "time_exit",
+			Stage:       "max_hold",
+			Kind:        "time",
+			DisplayName: "最长持仓时间",
+			Description: "持仓超过指定小时数且止盈/止损均未触发时整仓平出",
+			Constraints: []string{
+				"max_hold_hours 需 >0，按开仓成交时间起算",
+			},
+		},
+		{
+			Key:         "time_session_flat",
+			Alias:       "time_session_flat",
+			Handler:     "time_exit",
+			Stage:       "session_flat",
+			Kind:        "time",
+			DisplayName: "收盘前平仓",
+			Description: "到达每日指定 UTC 时刻时整仓平出，不持仓过夜",
+			Constraints: []string{
+				"session_close_utc 为 HH:MM 格式的 UTC 时刻",
+			},
+		},
+	}
+}
+
 func buildChildren(components []comboComponent) []map[string]any {
 	children := make([]map[string]any, 0, len(components))
 	for _, comp := range components {
@@ -345,6 +376,10 @@ func buildParams(comp comboComponent) map[string]any {
 		params["trigger_multiplier"] = placeholder(fmt.Sprintf("%s_TRIGGER_MULTIPLIER", prefix))
 		params["trail_multiplier"] = placeholder(fmt.Sprintf("%s_TRAIL_MULTIPLIER", prefix))
 		params["initial_stop_multiplier"] = placeholder(fmt.Sprintf("%s_INITIAL_STOP_MULTIPLIER", prefix))
+	case "max_hold":
+		params["max_hold_hours"] = placeholder(fmt.Sprintf("%s_MAX_HOLD_HOURS", prefix))
+	case "session_flat":
+		params["session_close_utc"] = placeholder(fmt.Sprintf("%s_SESSION_CLOSE_UTC", prefix))
 	}
 	return params
 }
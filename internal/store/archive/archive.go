@@ -0,0 +1,241 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/store/artifact"
+)
+
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// Source 由底层存储实现：取出 cutoff 前的记录交给 sink，归档成功后删除。
+type Source interface {
+	ArchiveBefore(ctx context.Context, table string, cutoff time.Time, batch int, sink func([]map[string]any) error) (int, error)
+}
+
+type Params struct {
+	Source Source
+	// Objects 非空时每批记录作为一个对象写入（如 S3），Dir 下只保留对象索引。
+	Objects   artifact.Store
+	Dir       string
+	Format    string
+	BatchSize int
+	Interval  time.Duration
+	// Tables 为 表名 -> 保留时长，<=0 的表不清理。
+	Tables map[string]time.Duration
+}
+
+// Service 周期性地把过期的操作/事件记录写入归档目录并从数据库删除，同时支持查询归档。
+type Service struct {
+	source   Source
+	objects  artifact.Store
+	dir      string
+	format   string
+	batch    int
+	interval time.Duration
+	tables   map[string]time.Duration
+	now      func() time.Time
+}
+
+func NewService(p Params) *Service {
+	format := strings.ToLower(strings.TrimSpace(p.Format))
+	if format != FormatCSV {
+		format = FormatJSONL
+	}
+	interval := p.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	tables := make(map[string]time.Duration, len(p.Tables))
+	for name, keep := range p.Tables {
+		tables[name] = keep
+	}
+	return &Service{
+		source:   p.Source,
+		objects:  p.Objects,
+		dir:      strings.TrimSpace(p.Dir),
+		format:   format,
+		batch:    p.BatchSize,
+		interval: interval,
+		tables:   tables,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) Start(ctx context.Context) {
+	if s == nil || s.source == nil {
+		return
+	}
+	go func() {
+		s.RunOnce(ctx)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce 依次处理各表，单表失败不影响其他表。
+func (s *Service) RunOnce(ctx context.Context) map[string]int {
+	out := make(map[string]int, len(s.tables))
+	if s == nil || s.source == nil {
+		return out
+	}
+	names := make([]string, 0, len(s.tables))
+	for name := range s.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		keep := s.tables[name]
+		if keep <= 0 {
+			continue
+		}
+		cutoff := s.now().Add(-keep)
+		table := name
+		n, err := s.source.ArchiveBefore(ctx, table, cutoff, s.batch, func(rows []map[string]any) error {
+			return s.write(ctx, table, rows)
+		})
+		if err != nil {
+			logger.Warnf("retention: 归档 %s 失败: %v", table, err)
+		}
+		if n > 0 {
+			logger.Infof("retention: %s 已归档并清理 %d 条 (cutoff=%s)", table, n, cutoff.Format(time.RFC3339))
+		}
+		out[table] = n
+	}
+	return out
+}
+
+func (s *Service) tableDir(table string) string {
+	return filepath.Join(s.dir, table)
+}
+
+func (s *Service) write(ctx context.Context, table string, rows []map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	dir := s.tableDir(table)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	day := s.now().UTC().Format("2006-01-02")
+	for _, row := range rows {
+		normalizeRow(row)
+	}
+	if s.objects != nil {
+		return s.writeObject(ctx, table, day, rows)
+	}
+	path := filepath.Join(dir, day+"."+s.format)
+	if s.format == FormatCSV {
+		return appendCSV(path, rows)
+	}
+	return appendJSONL(path, rows)
+}
+
+// normalizeRow 解开驱动返回的 *interface{}，并把 []byte（JSON 列）转为字符串，避免编码成 base64。
+func normalizeRow(row map[string]any) {
+	for k, v := range row {
+		if p, ok := v.(*any); ok {
+			if p == nil {
+				v = nil
+			} else {
+				v = *p
+			}
+		}
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		row[k] = v
+	}
+}
+
+func appendJSONL(path string, rows []map[string]any) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	if err := encodeJSONL(w, rows); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func appendCSV(path string, rows []map[string]any) error {
+	_, statErr := os.Stat(path)
+	isNew := os.IsNotExist(statErr)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := encodeCSV(f, rows, isNew); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func encodeJSONL(w io.Writer, rows []map[string]any) error {
+	enc := json.NewEncoder(w)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeCSV 以首行的列名排序输出；header 为 false 时用于向已有文件追加。
+func encodeCSV(w io.Writer, rows []map[string]any, header bool) error {
+	cols := make([]string, 0, len(rows[0]))
+	for k := range rows[0] {
+		cols = append(cols, k)
+	}
+	sort.Strings(cols)
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(cols); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(cols))
+	for _, row := range rows {
+		for i, c := range cols {
+			if v, ok := row[c]; ok && v != nil {
+				record[i] = fmt.Sprint(v)
+			} else {
+				record[i] = ""
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,110 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"brale/internal/store/artifact"
+)
+
+const indexFile = "index.jsonl"
+
+// objectEntry 为对象存储归档的本地索引行：一批记录对应一个对象。
+type objectEntry struct {
+	Day  string `json:"day"`
+	Ref  string `json:"ref"`
+	Rows int    `json:"rows"`
+}
+
+// writeObject 把一批记录编码后写入对象存储，成功后再追加索引；任一步失败都返回错误，记录不会被删除。
+func (s *Service) writeObject(ctx context.Context, table, day string, rows []map[string]any) error {
+	var buf bytes.Buffer
+	var err error
+	if s.format == FormatCSV {
+		err = encodeCSV(&buf, rows, true)
+	} else {
+		err = encodeJSONL(&buf, rows)
+	}
+	if err != nil {
+		return err
+	}
+	id, err := s.objects.Put(ctx, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("上传归档对象失败: %w", err)
+	}
+	line, err := json.Marshal(objectEntry{Day: day, Ref: artifact.Ref(id), Rows: len(rows)})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.tableDir(table), indexFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// queryObjects 按索引倒序拉取对象，直到凑满 Limit 条。
+func (s *Service) queryObjects(ctx context.Context, table string, q Query) ([]map[string]any, error) {
+	entries, err := s.readIndex(table)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]map[string]any, 0, q.Limit)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if !q.inRange(entry.Day) {
+			continue
+		}
+		id, ok := artifact.ParseRef(entry.Ref)
+		if !ok {
+			continue
+		}
+		data, err := s.objects.Get(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("读取归档对象 %s 失败: %w", id, err)
+		}
+		rows, err := s.decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("解析归档对象 %s 失败: %w", id, err)
+		}
+		if out = q.collect(out, rows); len(out) >= q.Limit {
+			return out, nil
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) readIndex(table string) ([]objectEntry, error) {
+	f, err := os.Open(filepath.Join(s.tableDir(table), indexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []objectEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var entry objectEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("归档索引损坏: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, sc.Err()
+}
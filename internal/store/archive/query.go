@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Query struct {
+	TradeID int
+	Symbol  string
+	// From/To 为归档文件日期 (YYYY-MM-DD)，闭区间，留空不限。
+	From  string
+	To    string
+	Limit int
+}
+
+// Query 从归档读取记录，按文件日期倒序返回最近的 Limit 条。
+func (s *Service) Query(ctx context.Context, table string, q Query) ([]map[string]any, error) {
+	if s == nil {
+		return nil, fmt.Errorf("归档未启用")
+	}
+	if _, ok := s.tables[table]; !ok {
+		return nil, fmt.Errorf("table %s 未配置归档", table)
+	}
+	if q.Limit <= 0 || q.Limit > 1000 {
+		q.Limit = 200
+	}
+	q.Symbol = strings.ToUpper(strings.TrimSpace(q.Symbol))
+	if s.objects != nil {
+		return s.queryObjects(ctx, table, q)
+	}
+	files, err := filepath.Glob(filepath.Join(s.tableDir(table), "*."+s.format))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	out := make([]map[string]any, 0, q.Limit)
+	for _, path := range files {
+		if filepath.Base(path) == indexFile {
+			continue
+		}
+		day := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if !q.inRange(day) {
+			continue
+		}
+		rows, err := s.readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取归档 %s 失败: %w", filepath.Base(path), err)
+		}
+		if out = q.collect(out, rows); len(out) >= q.Limit {
+			return out, nil
+		}
+	}
+	return out, nil
+}
+
+func (q Query) inRange(day string) bool {
+	return (q.From == "" || day >= q.From) && (q.To == "" || day <= q.To)
+}
+
+// collect 倒序追加匹配的记录，最多到 Limit 条。
+func (q Query) collect(out, rows []map[string]any) []map[string]any {
+	for i := len(rows) - 1; i >= 0 && len(out) < q.Limit; i-- {
+		if q.match(rows[i]) {
+			out = append(out, rows[i])
+		}
+	}
+	return out
+}
+
+func (q Query) match(row map[string]any) bool {
+	if q.TradeID > 0 {
+		id, ok := row["trade_id"]
+		if !ok {
+			id = row["freqtrade_id"]
+		}
+		if fmt.Sprint(id) != strconv.Itoa(q.TradeID) {
+			return false
+		}
+	}
+	if q.Symbol != "" {
+		if sym, _ := row["symbol"].(string); !strings.EqualFold(sym, q.Symbol) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) readFile(path string) ([]map[string]any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return s.decode(f)
+}
+
+func (s *Service) decode(r io.Reader) ([]map[string]any, error) {
+	if s.format == FormatCSV {
+		return readCSV(r)
+	}
+	var rows []map[string]any
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		row := make(map[string]any)
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, sc.Err()
+}
+
+func readCSV(r io.Reader) ([]map[string]any, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]any
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(header))
+		for i, col := range header {
+			if i < len(rec) {
+				row[col] = rec[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+}
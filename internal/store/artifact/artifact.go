@@ -0,0 +1,77 @@
+// Package artifact 把大体积的 prompt/response 文本移出 SQLite，按内容寻址压缩存储，
+// 决策日志中只保留 "artifact://<id>" 引用，读取时透明还原。
+package artifact
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const RefPrefix = "artifact://"
+
+// Store 为压缩后的内容寻址存储；Put 对相同内容返回相同 ID，Delete 对不存在的 ID 不报错。
+type Store interface {
+	Name() string
+	Put(ctx context.Context, data []byte) (string, error)
+	Get(ctx context.Context, id string) ([]byte, error)
+	Delete(ctx context.Context, id string) error
+}
+
+func Ref(id string) string {
+	return RefPrefix + id
+}
+
+// ParseRef 返回引用中的 ID；非引用文本返回 false。
+func ParseRef(s string) (string, bool) {
+	if !strings.HasPrefix(s, RefPrefix) {
+		return "", false
+	}
+	id := strings.TrimPrefix(s, RefPrefix)
+	if !validID(id) {
+		return "", false
+	}
+	return id, true
+}
+
+func contentID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func validID(id string) bool {
+	if len(id) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(r io.Reader) ([]byte, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("artifact 解压失败: %w", err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,85 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FSStore 把 artifact 以 gzip 压缩写入本地目录，按 ID 前两位分桶。
+type FSStore struct {
+	dir string
+}
+
+func NewFSStore(dir string) (*FSStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("artifact 目录不能为空")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FSStore{dir: dir}, nil
+}
+
+func (s *FSStore) Name() string { return "fs" }
+
+func (s *FSStore) path(id string) string {
+	return filepath.Join(s.dir, id[:2], id+".gz")
+}
+
+func (s *FSStore) Put(ctx context.Context, data []byte) (string, error) {
+	id := contentID(data)
+	target := s.path(id)
+	if _, err := os.Stat(target); err == nil {
+		return id, nil
+	}
+	blob, err := compress(data)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+	// 先写临时文件再改名，避免并发读到半截内容。
+	tmp, err := os.CreateTemp(filepath.Dir(target), id+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(blob); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *FSStore) Get(ctx context.Context, id string) ([]byte, error) {
+	if !validID(id) {
+		return nil, fmt.Errorf("invalid artifact id %q", id)
+	}
+	blob, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, err
+	}
+	return decompress(bytes.NewReader(blob))
+}
+
+func (s *FSStore) Delete(ctx context.Context, id string) error {
+	if !validID(id) {
+		return fmt.Errorf("invalid artifact id %q", id)
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
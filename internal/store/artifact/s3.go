@@ -0,0 +1,182 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	Timeout   time.Duration
+}
+
+// S3Store 通过 path-style URL 与 SigV4 签名访问 S3 兼容存储（AWS S3、MinIO、R2 等）。
+type S3Store struct {
+	endpoint *url.URL
+	bucket   string
+	region   string
+	prefix   string
+	access   string
+	secret   string
+	client   *http.Client
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimRight(strings.TrimSpace(cfg.Endpoint), "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if strings.TrimSpace(cfg.Bucket) == "" {
+		return nil, fmt.Errorf("s3 bucket 不能为空")
+	}
+	region := strings.TrimSpace(cfg.Region)
+	if region == "" {
+		region = "us-east-1"
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return &S3Store{
+		endpoint: endpoint,
+		bucket:   strings.TrimSpace(cfg.Bucket),
+		region:   region,
+		prefix:   strings.Trim(strings.TrimSpace(cfg.Prefix), "/"),
+		access:   strings.TrimSpace(cfg.AccessKey),
+		secret:   strings.TrimSpace(cfg.SecretKey),
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *S3Store) Name() string { return "s3" }
+
+func (s *S3Store) objectURL(id string) *url.URL {
+	key := id + ".gz"
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	u := *s.endpoint
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + s.bucket + "/" + key
+	return &u
+}
+
+func (s *S3Store) Put(ctx context.Context, data []byte) (string, error) {
+	id := contentID(data)
+	blob, err := compress(data)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(id).String(), bytes.NewReader(blob))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	s.sign(req, blob, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("s3 put %s failed: status=%d %s", id, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return id, nil
+}
+
+func (s *S3Store) Get(ctx context.Context, id string) ([]byte, error) {
+	if !validID(id) {
+		return nil, fmt.Errorf("invalid artifact id %q", id)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(id).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("s3 get %s failed: status=%d", id, resp.StatusCode)
+	}
+	return decompress(resp.Body)
+}
+
+func (s *S3Store) Delete(ctx context.Context, id string) error {
+	if !validID(id) {
+		return fmt.Errorf("invalid artifact id %q", id)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(id).String(), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, nil, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("s3 delete %s failed: status=%d", id, resp.StatusCode)
+	}
+	return nil
+}
+
+// sign 按 AWS Signature V4 为请求添加鉴权头；未配置密钥时按匿名访问处理。
+func (s *S3Store) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.access == "" || s.secret == "" {
+		return
+	}
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+	key := hmacSHA256([]byte("AWS4"+s.secret), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.access, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,292 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/store/artifact"
+)
+
+const (
+	artifactQueueSize      = 256
+	artifactOffloadTimeout = 30 * time.Second
+	decisionPurgeBatch     = 200
+)
+
+// artifactColumns 为可能外置到 artifact 存储的大字段列。
+var artifactColumns = []string{"system_prompt", "user_prompt", "raw_output", "raw_json", "images_json"}
+
+// offloadJob 为一条已内联落库、待外置的决策日志：列名 -> 原文。
+type offloadJob struct {
+	id     int64
+	fields map[string]string
+}
+
+// SetArtifactStore 启用外部 artifact 存储：长度不小于 minBytes 的 prompt、模型输出与图片字段
+// 先内联落库，再由后台队列写入 st 并把列改写为引用；队列已满时保持内联。
+// GetDecision 会透明还原引用，列表查询保留引用文本。st 为 nil 时恢复内联存储。
+func (s *DecisionLogStore) SetArtifactStore(st artifact.Store, minBytes int) {
+	if s == nil {
+		return
+	}
+	if minBytes <= 0 {
+		minBytes = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artifacts = st
+	s.artifactMin = minBytes
+	if st != nil && s.offloadQ == nil {
+		s.offloadQ = make(chan offloadJob, artifactQueueSize)
+		go s.runOffload(s.offloadQ)
+	}
+}
+
+func (s *DecisionLogStore) artifactStore() (artifact.Store, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.artifacts, s.artifactMin
+}
+
+// enqueueOffload 把超过阈值的字段交给后台外置；不阻塞写入路径。
+func (s *DecisionLogStore) enqueueOffload(id int64, fields map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.offloadQ == nil || s.artifacts == nil {
+		return
+	}
+	job := offloadJob{id: id, fields: make(map[string]string, len(fields))}
+	for col, v := range fields {
+		if len(v) >= s.artifactMin {
+			if _, ok := artifact.ParseRef(v); !ok {
+				job.fields[col] = v
+			}
+		}
+	}
+	if len(job.fields) == 0 {
+		return
+	}
+	select {
+	case s.offloadQ <- job:
+	default:
+		logger.Warnf("决策日志 artifact 队列已满，id=%d 保持内联存储", id)
+	}
+}
+
+func (s *DecisionLogStore) stopOffload() {
+	if s.offloadQ != nil {
+		close(s.offloadQ)
+		s.offloadQ = nil
+	}
+}
+
+func (s *DecisionLogStore) runOffload(q <-chan offloadJob) {
+	for job := range q {
+		s.offloadRow(job)
+	}
+}
+
+// offloadRow 写入 artifact 后把对应列改写为引用；单列写入失败时该列保持内联，不影响其他列。
+func (s *DecisionLogStore) offloadRow(job offloadJob) {
+	st, _ := s.artifactStore()
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if st == nil || db == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), artifactOffloadTimeout)
+	defer cancel()
+	s.artifactGCMu.Lock()
+	defer s.artifactGCMu.Unlock()
+	var (
+		sets []string
+		args []any
+	)
+	for _, col := range artifactColumns {
+		v, ok := job.fields[col]
+		if !ok {
+			continue
+		}
+		id, err := st.Put(ctx, []byte(v))
+		if err != nil {
+			logger.Warnf("决策日志 artifact 写入失败(%s)，id=%d %s 保持内联存储: %v", st.Name(), job.id, col, err)
+			continue
+		}
+		sets = append(sets, col+" = ?")
+		args = append(args, artifact.Ref(id))
+	}
+	if len(sets) == 0 {
+		return
+	}
+	args = append(args, job.id)
+	if _, err := db.ExecContext(ctx, `UPDATE live_decision_logs SET `+strings.Join(sets, ", ")+` WHERE id = ?`, args...); err != nil {
+		logger.Warnf("决策日志 artifact 引用回写失败 id=%d: %v", job.id, err)
+	}
+}
+
+// inflate 还原 artifact 引用；读取失败时保留引用文本，便于排查。
+func (s *DecisionLogStore) inflate(ctx context.Context, v string) string {
+	id, ok := artifact.ParseRef(v)
+	if !ok {
+		return v
+	}
+	st, _ := s.artifactStore()
+	if st == nil {
+		return v
+	}
+	data, err := st.Get(ctx, id)
+	if err != nil {
+		logger.Warnf("决策日志 artifact 读取失败 id=%s: %v", id, err)
+		return v
+	}
+	return string(data)
+}
+
+func (s *DecisionLogStore) loader(ctx context.Context) func(string) string {
+	return func(v string) string { return s.inflate(ctx, v) }
+}
+
+// keepRefs 用于列表查询：不拉取 artifact，引用原样返回，详情接口再按需还原。
+func keepRefs(v string) string {
+	return v
+}
+
+// StartRetention 周期删除 keep 之前的决策日志及其审计记录，并回收不再被引用的 artifact；keep<=0 时不启用。
+func (s *DecisionLogStore) StartRetention(ctx context.Context, keep, interval time.Duration) {
+	if s == nil || keep <= 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n, err := s.PurgeBefore(ctx, time.Now().Add(-keep))
+			if err != nil {
+				logger.Warnf("决策日志清理失败: %v", err)
+			} else if n > 0 {
+				logger.Infof("决策日志清理 %d 条 (保留 %s)", n, keep)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PurgeBefore 分批删除 cutoff 之前的决策日志；每批删除后检查其引用的 artifact，
+// 已无其他行引用的（内容寻址下同一 prompt 可能被多行共享）一并删除。返回删除的行数。
+func (s *DecisionLogStore) PurgeBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return 0, fmt.Errorf("decision log store 未初始化")
+	}
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		ids, refs, err := s.expiredDecisions(ctx, db, cutoff.UnixMilli())
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+		args := make([]any, 0, len(ids))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return total, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM decision_audit WHERE decision_log_id IN (`+placeholders+`)`, args...); err != nil {
+			_ = tx.Rollback()
+			return total, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM live_decision_logs WHERE id IN (`+placeholders+`)`, args...); err != nil {
+			_ = tx.Rollback()
+			return total, err
+		}
+		if err := tx.Commit(); err != nil {
+			return total, err
+		}
+		total += len(ids)
+		s.collectArtifacts(ctx, db, refs)
+		if len(ids) < decisionPurgeBatch {
+			return total, nil
+		}
+	}
+}
+
+func (s *DecisionLogStore) expiredDecisions(ctx context.Context, db *sqlDB, cutoffMs int64) ([]int64, map[string]struct{}, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, `+strings.Join(artifactColumns, ", ")+`
+		FROM live_decision_logs WHERE ts < ? ORDER BY id ASC LIMIT ?`, cutoffMs, decisionPurgeBatch)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var ids []int64
+	refs := make(map[string]struct{})
+	for rows.Next() {
+		var (
+			id   int64
+			cols = make([]sql.NullString, len(artifactColumns))
+			dest = []any{&id}
+		)
+		for i := range cols {
+			dest = append(dest, &cols[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		for _, v := range cols {
+			if _, ok := artifact.ParseRef(v.String); ok {
+				refs[v.String] = struct{}{}
+			}
+		}
+	}
+	return ids, refs, rows.Err()
+}
+
+// collectArtifacts 删除已无行引用的 artifact（逐个全表检查，随清理批次低频执行）；删除失败只记录日志。
+func (s *DecisionLogStore) collectArtifacts(ctx context.Context, db *sqlDB, refs map[string]struct{}) {
+	st, _ := s.artifactStore()
+	if st == nil || len(refs) == 0 {
+		return
+	}
+	s.artifactGCMu.Lock()
+	defer s.artifactGCMu.Unlock()
+	where := strings.Join(artifactColumns, " = ? OR ") + " = ?"
+	for ref := range refs {
+		args := make([]any, len(artifactColumns))
+		for i := range args {
+			args[i] = ref
+		}
+		var one int
+		err := db.QueryRowContext(ctx, `SELECT 1 FROM live_decision_logs WHERE `+where+` LIMIT 1`, args...).Scan(&one)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warnf("决策日志 artifact 引用检查失败 %s: %v", ref, err)
+			continue
+		}
+		id, _ := artifact.ParseRef(ref)
+		if err := st.Delete(ctx, id); err != nil {
+			logger.Warnf("决策日志 artifact 删除失败(%s) id=%s: %v", st.Name(), id, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/logger"
+)
+
+// DecisionAuditRecord 为单个模型（或 final 聚合）一次决策的完整审计快照：渲染后的提示词、原始输出、
+// 解析后的决策 JSON、指标快照哈希、提示词版本与调用耗时，便于复盘单笔交易而无需翻日志。
+type DecisionAuditRecord struct {
+	ID            int64           `json:"id"`
+	DecisionLogID int64           `json:"decision_log_id"`
+	TraceID       string          `json:"trace_id"`
+	Stage         string          `json:"stage"`
+	ProviderID    string          `json:"provider_id"`
+	SystemPrompt  string          `json:"system_prompt"`
+	UserPrompt    string          `json:"user_prompt"`
+	RawOutput     string          `json:"raw_output"`
+	DecisionJSON  json.RawMessage `json:"decision_json,omitempty"`
+	SnapshotHash  string          `json:"snapshot_hash,omitempty"`
+	PromptVersion string          `json:"prompt_version,omitempty"`
+	LatencyMs     int64           `json:"latency_ms"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     int64           `json:"created_at"`
+}
+
+func (s *DecisionLogStore) InsertDecisionAudit(ctx context.Context, rec DecisionAuditRecord) error {
+	if s == nil {
+		return fmt.Errorf("decision log store 未初始化")
+	}
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return fmt.Errorf("decision log store 未初始化")
+	}
+	created := rec.CreatedAt
+	if created <= 0 {
+		created = time.Now().UnixMilli()
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO decision_audit (decision_log_id, trace_id, stage, provider_id,
+		system_prompt, user_prompt, raw_output, decision_json, snapshot_hash, prompt_version, latency_ms, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.DecisionLogID,
+		strings.TrimSpace(rec.TraceID),
+		rec.Stage,
+		rec.ProviderID,
+		rec.SystemPrompt,
+		rec.UserPrompt,
+		rec.RawOutput,
+		string(rec.DecisionJSON),
+		rec.SnapshotHash,
+		rec.PromptVersion,
+		rec.LatencyMs,
+		rec.Error,
+		created,
+	)
+	return err
+}
+
+// ListDecisionAudit 返回与决策日志 id 同一 trace 的全部审计记录（各模型 + final）；旧数据无 trace_id 时只返回该条。
+func (s *DecisionLogStore) ListDecisionAudit(ctx context.Context, decisionID int64) ([]DecisionAuditRecord, error) {
+	if s == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	var traceID sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT trace_id FROM live_decision_logs WHERE id = ?`, decisionID).Scan(&traceID); err != nil {
+		return nil, err
+	}
+	query := `SELECT id, decision_log_id, trace_id, stage, provider_id, system_prompt, user_prompt, raw_output,
+		decision_json, snapshot_hash, prompt_version, latency_ms, error, created_at FROM decision_audit `
+	var rows *sql.Rows
+	var err error
+	if trace := strings.TrimSpace(traceID.String); trace != "" {
+		rows, err = db.QueryContext(ctx, query+`WHERE trace_id = ? ORDER BY id ASC`, trace)
+	} else {
+		rows, err = db.QueryContext(ctx, query+`WHERE decision_log_id = ? ORDER BY id ASC`, decisionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []DecisionAuditRecord
+	for rows.Next() {
+		var rec DecisionAuditRecord
+		var trace, decisionJSON, hash, promptVersion, errText sql.NullString
+		if err := rows.Scan(&rec.ID, &rec.DecisionLogID, &trace, &rec.Stage, &rec.ProviderID, &rec.SystemPrompt,
+			&rec.UserPrompt, &rec.RawOutput, &decisionJSON, &hash, &promptVersion, &rec.LatencyMs, &errText, &rec.CreatedAt); err != nil {
+			return nil, err
+		}
+		rec.TraceID = trace.String
+		if raw := strings.TrimSpace(decisionJSON.String); raw != "" && json.Valid([]byte(raw)) {
+			rec.DecisionJSON = json.RawMessage(raw)
+		}
+		rec.SnapshotHash = hash.String
+		rec.PromptVersion = promptVersion.String
+		rec.Error = errText.String
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// insertAudit 在决策日志写入后追加审计记录；失败只记日志，不影响主流程。
+func (o *DecisionLogObserver) insertAudit(ctx context.Context, logID int64, rec DecisionLogRecord, out decision.ModelOutput, trace decision.DecisionTrace) {
+	audit := DecisionAuditRecord{
+		DecisionLogID: logID,
+		TraceID:       rec.TraceID,
+		Stage:         rec.Stage,
+		ProviderID:    rec.ProviderID,
+		SystemPrompt:  rec.System,
+		UserPrompt:    rec.User,
+		RawOutput:     rec.RawOutput,
+		SnapshotHash:  trace.SnapshotHash,
+		PromptVersion: trace.PromptVersion,
+		LatencyMs:     out.Latency.Milliseconds(),
+		Error:         rec.Error,
+		CreatedAt:     rec.Timestamp,
+	}
+	if len(rec.Decisions) > 0 {
+		if payload, err := json.Marshal(rec.Decisions); err == nil {
+			audit.DecisionJSON = payload
+		}
+	}
+	if err := o.store.InsertDecisionAudit(ctx, audit); err != nil {
+		logger.Warnf("写入决策审计失败(%s %s): %v", rec.Stage, rec.ProviderID, err)
+	}
+}
@@ -0,0 +1,136 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	storemodel "brale/internal/store/model"
+)
+
+// 每条目标决策最多扫描的 final 记录数；hold 占多数，按 limit 放大扫描。
+const recentDecisionScanFactor = 20
+
+// RecentDecisions 返回 symbol 在 since 之后最近 limit 条非 hold 最终决策（新→旧），
+// 开仓决策通过 strategy_instances.decision_trace_id 关联 live_orders 得到结果。
+func (s *DecisionLogStore) RecentDecisions(ctx context.Context, symbol string, limit int, since time.Time) ([]decision.DecisionHistoryEntry, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" || limit <= 0 {
+		return nil, nil
+	}
+	var sinceMs int64
+	if !since.IsZero() {
+		sinceMs = since.UnixMilli()
+	}
+	rows, err := db.QueryContext(ctx, `SELECT COALESCE(trace_id, ''), ts, decisions_json FROM live_decision_logs
+		WHERE stage = 'final' AND symbols LIKE ? AND ts >= ?
+		ORDER BY ts DESC, id DESC
+		LIMIT ?`, symbolLikePattern(symbol), sinceMs, limit*recentDecisionScanFactor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []decision.DecisionHistoryEntry
+	for rows.Next() && len(out) < limit {
+		var (
+			traceID   string
+			ts        int64
+			decisions sql.NullString
+		)
+		if err := rows.Scan(&traceID, &ts, &decisions); err != nil {
+			return nil, err
+		}
+		for _, d := range filterDecisionsBySymbol(decodeDecisionArray(decisions.String), symbol) {
+			action := strings.ToLower(strings.TrimSpace(d.Action))
+			if action == "" || action == "hold" {
+				continue
+			}
+			out = append(out, decision.DecisionHistoryEntry{
+				Timestamp: ts,
+				Action:    action,
+				Reasoning: strings.TrimSpace(d.Reasoning),
+				TraceID:   traceID,
+			})
+			if len(out) >= limit {
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.attachDecisionOutcomes(ctx, db, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *DecisionLogStore) attachDecisionOutcomes(ctx context.Context, db *sqlDB, entries []decision.DecisionHistoryEntry) error {
+	idx := make(map[string][]int)
+	args := make([]any, 0, len(entries))
+	for i, ent := range entries {
+		if ent.TraceID == "" || !strings.HasPrefix(ent.Action, "open_") {
+			continue
+		}
+		if _, ok := idx[ent.TraceID]; !ok {
+			args = append(args, ent.TraceID)
+		}
+		idx[ent.TraceID] = append(idx[ent.TraceID], i)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, `SELECT si.trace_id, o.status, COALESCE(o.pnl_ratio, 0), COALESCE(o.current_profit_ratio, 0), COALESCE(o.end_timestamp, 0)
+		FROM live_orders o
+		JOIN (SELECT trade_id, MIN(decision_trace_id) AS trace_id FROM strategy_instances
+			WHERE decision_trace_id IN (`+placeholders(len(args))+`) GROUP BY trade_id) si
+			ON si.trade_id = o.freqtrade_id`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			traceID      string
+			status       int
+			pnl, current float64
+			endTS        int64
+		)
+		if err := rows.Scan(&traceID, &status, &pnl, &current, &endTS); err != nil {
+			return err
+		}
+		outcome, ratio := classifyDecisionOutcome(storemodel.LiveOrderStatus(status), pnl, current)
+		for _, i := range idx[traceID] {
+			entries[i].Outcome = outcome
+			entries[i].PnLRatio = ratio
+			if outcome != decision.DecisionOutcomeOpen {
+				entries[i].ClosedAt = endTS
+			}
+		}
+	}
+	return rows.Err()
+}
+
+func classifyDecisionOutcome(status storemodel.LiveOrderStatus, pnl, current float64) (string, float64) {
+	switch status {
+	case storemodel.LiveOrderStatusClosed:
+		switch {
+		case pnl > 0:
+			return decision.DecisionOutcomeWin, pnl
+		case pnl < 0:
+			return decision.DecisionOutcomeLoss, pnl
+		default:
+			return decision.DecisionOutcomeFlat, 0
+		}
+	case storemodel.LiveOrderStatusCanceled:
+		return decision.DecisionOutcomeCanceled, 0
+	default:
+		return decision.DecisionOutcomeOpen, current
+	}
+}
@@ -0,0 +1,131 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	"brale/internal/logger"
+	symbolpkg "brale/internal/pkg/symbol"
+)
+
+// DecisionRationaleRecord 为单条决策的结构化理由，按 trace_id + stage + provider 与决策日志关联；
+// Drivers 以逗号分隔存储，便于按指标筛选统计。
+type DecisionRationaleRecord struct {
+	ID           int64    `json:"id"`
+	TraceID      string   `json:"trace_id"`
+	Stage        string   `json:"stage"`
+	ProviderID   string   `json:"provider_id"`
+	Symbol       string   `json:"symbol"`
+	Action       string   `json:"action"`
+	Drivers      []string `json:"drivers"`
+	Invalidation float64  `json:"invalidation,omitempty"`
+	Confidence   int      `json:"confidence,omitempty"`
+	Source       string   `json:"source,omitempty"`
+	CreatedAt    int64    `json:"created_at"`
+}
+
+func (s *DecisionLogStore) InsertDecisionRationale(ctx context.Context, rec DecisionRationaleRecord) error {
+	if s == nil {
+		return fmt.Errorf("decision log store 未初始化")
+	}
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return fmt.Errorf("decision log store 未初始化")
+	}
+	created := rec.CreatedAt
+	if created <= 0 {
+		created = time.Now().UnixMilli()
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO decision_rationale (trace_id, stage, provider_id, symbol, action,
+		drivers, invalidation, confidence, source, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		strings.TrimSpace(rec.TraceID),
+		rec.Stage,
+		rec.ProviderID,
+		symbolpkg.Normalize(rec.Symbol),
+		strings.ToLower(strings.TrimSpace(rec.Action)),
+		strings.Join(rec.Drivers, ","),
+		rec.Invalidation,
+		rec.Confidence,
+		rec.Source,
+		created,
+	)
+	return err
+}
+
+// ListDecisionRationales 返回与决策日志 id 同一 trace 的结构化理由（各模型 + final）。
+func (s *DecisionLogStore) ListDecisionRationales(ctx context.Context, decisionID int64) ([]DecisionRationaleRecord, error) {
+	if s == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	var traceID sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT trace_id FROM live_decision_logs WHERE id = ?`, decisionID).Scan(&traceID); err != nil {
+		return nil, err
+	}
+	trace := strings.TrimSpace(traceID.String)
+	if trace == "" {
+		return nil, nil
+	}
+	rows, err := db.QueryContext(ctx, `SELECT id, trace_id, stage, provider_id, symbol, action, drivers, invalidation,
+		confidence, source, created_at FROM decision_rationale WHERE trace_id = ? ORDER BY id ASC`, trace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []DecisionRationaleRecord
+	for rows.Next() {
+		var rec DecisionRationaleRecord
+		var drivers, source sql.NullString
+		if err := rows.Scan(&rec.ID, &rec.TraceID, &rec.Stage, &rec.ProviderID, &rec.Symbol, &rec.Action, &drivers,
+			&rec.Invalidation, &rec.Confidence, &source, &rec.CreatedAt); err != nil {
+			return nil, err
+		}
+		rec.Drivers = []string{}
+		if d := strings.TrimSpace(drivers.String); d != "" {
+			rec.Drivers = strings.Split(d, ",")
+		}
+		rec.Source = source.String
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// insertRationales 为带结构化理由的决策逐条写入 decision_rationale；失败只记日志。
+func (o *DecisionLogObserver) insertRationales(ctx context.Context, rec DecisionLogRecord) {
+	for _, d := range rec.Decisions {
+		if d.Rationale == nil || strings.EqualFold(strings.TrimSpace(d.Action), "hold") {
+			continue
+		}
+		row := decisionRationaleRow(rec, d)
+		if err := o.store.InsertDecisionRationale(ctx, row); err != nil {
+			logger.Warnf("写入决策理由失败(%s %s %s): %v", rec.Stage, rec.ProviderID, d.Symbol, err)
+		}
+	}
+}
+
+func decisionRationaleRow(rec DecisionLogRecord, d decision.Decision) DecisionRationaleRecord {
+	return DecisionRationaleRecord{
+		TraceID:      rec.TraceID,
+		Stage:        rec.Stage,
+		ProviderID:   rec.ProviderID,
+		Symbol:       d.Symbol,
+		Action:       d.Action,
+		Drivers:      d.Rationale.Drivers,
+		Invalidation: d.Rationale.Invalidation,
+		Confidence:   d.Rationale.Confidence,
+		Source:       d.Rationale.Source,
+		CreatedAt:    rec.Timestamp,
+	}
+}
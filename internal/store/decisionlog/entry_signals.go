@@ -0,0 +1,132 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	symbolpkg "brale/internal/pkg/symbol"
+	storemodel "brale/internal/store/model"
+)
+
+// EntrySignalRecord 为开仓时刻的指标信号快照（如各周期背离方向），按 trace_id + symbol 与交易关联。
+type EntrySignalRecord struct {
+	TraceID   string
+	Symbol    string
+	Side      string
+	Signals   map[string]string
+	CreatedAt time.Time
+}
+
+// EntrySignalOutcome 为已平仓交易及其开仓快照。
+type EntrySignalOutcome struct {
+	TradeID  int
+	Symbol   string
+	Side     string
+	PnLUSD   float64
+	PnLRatio float64
+	ClosedAt time.Time
+	Signals  map[string]string
+}
+
+func (s *DecisionLogStore) InsertEntrySignals(ctx context.Context, rec EntrySignalRecord) error {
+	if s == nil {
+		return fmt.Errorf("decision log store 未初始化")
+	}
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return fmt.Errorf("decision log store 未初始化")
+	}
+	if strings.TrimSpace(rec.TraceID) == "" || strings.TrimSpace(rec.Symbol) == "" {
+		return fmt.Errorf("trace_id 与 symbol 必填")
+	}
+	payload, err := json.Marshal(rec.Signals)
+	if err != nil {
+		return err
+	}
+	created := rec.CreatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO entry_signals (trace_id, symbol, side, signals_json, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		strings.TrimSpace(rec.TraceID),
+		symbolpkg.Normalize(rec.Symbol),
+		strings.ToLower(strings.TrimSpace(rec.Side)),
+		string(payload),
+		created.UnixMilli(),
+	)
+	return err
+}
+
+// ListEntrySignalOutcomes 通过 strategy_instances.decision_trace_id 把已平仓交易关联回开仓快照；
+// since 为零值时不限制平仓时间。没有快照的交易不返回。
+func (s *DecisionLogStore) ListEntrySignalOutcomes(ctx context.Context, since time.Time, limit int) ([]EntrySignalOutcome, error) {
+	if s == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	if limit <= 0 || limit > 5000 {
+		limit = 5000
+	}
+	var sinceMs int64
+	if !since.IsZero() {
+		sinceMs = since.UnixMilli()
+	}
+	rows, err := db.QueryContext(ctx, `SELECT o.freqtrade_id, o.symbol, o.side, COALESCE(o.pnl_usd, 0), COALESCE(o.pnl_ratio, 0),
+			COALESCE(o.end_timestamp, 0), es.symbol, es.signals_json
+		FROM live_orders o
+		JOIN (SELECT trade_id, MIN(decision_trace_id) AS trace_id FROM strategy_instances
+			WHERE decision_trace_id IS NOT NULL AND decision_trace_id != '' GROUP BY trade_id) si
+			ON si.trade_id = o.freqtrade_id
+		JOIN entry_signals es ON es.trace_id = si.trace_id
+		WHERE o.status = ? AND COALESCE(o.end_timestamp, 0) >= ?
+		ORDER BY o.end_timestamp DESC, es.id DESC
+		LIMIT ?`, int(storemodel.LiveOrderStatusClosed), sinceMs, limit*4)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	// 同一 trace 可能同时开多个 symbol，按 symbol 精确匹配，每笔交易只取最新一条快照。
+	seen := make(map[int]struct{})
+	var out []EntrySignalOutcome
+	for rows.Next() {
+		var rec EntrySignalOutcome
+		var endTS int64
+		var sigSymbol string
+		var payload sql.NullString
+		if err := rows.Scan(&rec.TradeID, &rec.Symbol, &rec.Side, &rec.PnLUSD, &rec.PnLRatio, &endTS, &sigSymbol, &payload); err != nil {
+			return nil, err
+		}
+		if _, ok := seen[rec.TradeID]; ok {
+			continue
+		}
+		if symbolpkg.Normalize(rec.Symbol) != sigSymbol {
+			continue
+		}
+		if payload.Valid && payload.String != "" {
+			if err := json.Unmarshal([]byte(payload.String), &rec.Signals); err != nil {
+				continue
+			}
+		}
+		if endTS > 0 {
+			rec.ClosedAt = time.UnixMilli(endTS)
+		}
+		seen[rec.TradeID] = struct{}{}
+		out = append(out, rec)
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out, rows.Err()
+}
@@ -22,6 +22,9 @@ func (s *DecisionLogStore) AddOrderPnLColumns() error {
 		"ALTER TABLE live_orders ADD COLUMN unrealized_pnl_usd REAL DEFAULT 0",
 		"ALTER TABLE live_orders ADD COLUMN realized_pnl_ratio REAL DEFAULT 0",
 		"ALTER TABLE live_orders ADD COLUMN realized_pnl_usd REAL DEFAULT 0",
+		"ALTER TABLE live_orders ADD COLUMN fee_usd REAL DEFAULT 0",
+		"ALTER TABLE live_orders ADD COLUMN funding_usd REAL DEFAULT 0",
+		"ALTER TABLE live_orders ADD COLUMN gross_realized_pnl_usd REAL DEFAULT 0",
 		"ALTER TABLE live_orders ADD COLUMN last_status_sync INTEGER",
 	}
 	for _, q := range queries {
@@ -0,0 +1,72 @@
+package decisionlog
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ModelUsageRecord 为单个模型一天（UTC，Day 形如 2006-01-02）的累计调用次数、token 用量与估算费用。
+type ModelUsageRecord struct {
+	Day          string  `json:"day"`
+	ModelID      string  `json:"model_id"`
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	UpdatedAt    int64   `json:"updated_at"`
+}
+
+// AddModelUsage 把 rec 的增量累加到 (day, model_id) 行，不存在时插入。
+func (s *DecisionLogStore) AddModelUsage(ctx context.Context, rec ModelUsageRecord) error {
+	db, err := s.handle()
+	if err != nil {
+		return err
+	}
+	updated := rec.UpdatedAt
+	if updated <= 0 {
+		updated = time.Now().UnixMilli()
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO model_usage_daily
+		(day, model_id, calls, input_tokens, output_tokens, cost_usd, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(day, model_id)
+		DO UPDATE SET
+			calls=model_usage_daily.calls+excluded.calls,
+			input_tokens=model_usage_daily.input_tokens+excluded.input_tokens,
+			output_tokens=model_usage_daily.output_tokens+excluded.output_tokens,
+			cost_usd=model_usage_daily.cost_usd+excluded.cost_usd,
+			updated_at=excluded.updated_at`,
+		rec.Day,
+		strings.TrimSpace(rec.ModelID),
+		rec.Calls,
+		rec.InputTokens,
+		rec.OutputTokens,
+		rec.CostUSD,
+		updated,
+	)
+	return err
+}
+
+// ListModelUsage 返回 [fromDay, toDay] 内的每日用量，按日期、模型排序。
+func (s *DecisionLogStore) ListModelUsage(ctx context.Context, fromDay, toDay string) ([]ModelUsageRecord, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, `SELECT day, model_id, calls, input_tokens, output_tokens, cost_usd, updated_at
+		FROM model_usage_daily WHERE day >= ? AND day <= ? ORDER BY day, model_id`, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ModelUsageRecord
+	for rows.Next() {
+		var rec ModelUsageRecord
+		if err := rows.Scan(&rec.Day, &rec.ModelID, &rec.Calls, &rec.InputTokens, &rec.OutputTokens, &rec.CostUSD, &rec.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,222 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/decision"
+	storemodel "brale/internal/store/model"
+)
+
+// DecisionRoundStat 为一次决策轮次（stage=final 记录）的摘要，ModelCalls 为同一 trace 下 provider/agent 阶段的调用数。
+type DecisionRoundStat struct {
+	TraceID    string
+	Timestamp  time.Time
+	Candidates []string
+	Decisions  []decision.Decision
+	ModelCalls int
+}
+
+// ClosedTradeStat 为已平仓交易及其开仓决策 trace；TraceID 为空表示无法关联回决策。
+type ClosedTradeStat struct {
+	TradeID       int
+	Symbol        string
+	Side          string
+	EntryPrice    float64
+	InitialAmount float64
+	PnLUSD        float64
+	PnLRatio      float64
+	ClosedAt      time.Time
+	TraceID       string
+}
+
+// ListDecisionRounds 返回 [from, to) 内的决策轮次；to 为零值时不设上限。
+func (s *DecisionLogStore) ListDecisionRounds(ctx context.Context, from, to time.Time) ([]DecisionRoundStat, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	fromMs, toMs := timeRangeMillis(from, to)
+	calls := make(map[string]int)
+	callRows, err := db.QueryContext(ctx, `SELECT trace_id, COUNT(*) FROM live_decision_logs
+		WHERE stage != 'final' AND ts >= ? AND ts < ? AND trace_id IS NOT NULL AND trace_id != ''
+		GROUP BY trace_id`, fromMs, toMs)
+	if err != nil {
+		return nil, err
+	}
+	for callRows.Next() {
+		var traceID string
+		var n int
+		if err := callRows.Scan(&traceID, &n); err != nil {
+			_ = callRows.Close()
+			return nil, err
+		}
+		calls[traceID] = n
+	}
+	if err := callRows.Close(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT COALESCE(trace_id, ''), ts, candidates, decisions_json FROM live_decision_logs
+		WHERE stage = 'final' AND ts >= ? AND ts < ?
+		ORDER BY ts ASC`, fromMs, toMs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []DecisionRoundStat
+	for rows.Next() {
+		var rec DecisionRoundStat
+		var ts int64
+		var candidates, decisions sql.NullString
+		if err := rows.Scan(&rec.TraceID, &ts, &candidates, &decisions); err != nil {
+			return nil, err
+		}
+		rec.Timestamp = time.UnixMilli(ts)
+		rec.Candidates = decodeStringArray(candidates.String)
+		rec.Decisions = decodeDecisionArray(decisions.String)
+		rec.ModelCalls = calls[rec.TraceID]
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// ListClosedTrades 返回平仓时间在 [from, to) 内的交易，并通过 strategy_instances 关联开仓 trace。
+func (s *DecisionLogStore) ListClosedTrades(ctx context.Context, from, to time.Time) ([]ClosedTradeStat, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	fromMs, toMs := timeRangeMillis(from, to)
+	rows, err := db.QueryContext(ctx, `SELECT o.freqtrade_id, o.symbol, o.side, COALESCE(o.price, 0), COALESCE(o.initial_amount, 0),
+			COALESCE(o.pnl_usd, 0), COALESCE(o.pnl_ratio, 0), COALESCE(o.end_timestamp, 0), COALESCE(si.trace_id, '')
+		FROM live_orders o
+		LEFT JOIN (SELECT trade_id, MIN(decision_trace_id) AS trace_id FROM strategy_instances
+			WHERE decision_trace_id IS NOT NULL AND decision_trace_id != '' GROUP BY trade_id) si
+			ON si.trade_id = o.freqtrade_id
+		WHERE o.status = ? AND COALESCE(o.end_timestamp, 0) >= ? AND COALESCE(o.end_timestamp, 0) < ?
+		ORDER BY o.end_timestamp ASC`, int(storemodel.LiveOrderStatusClosed), fromMs, toMs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ClosedTradeStat
+	for rows.Next() {
+		var rec ClosedTradeStat
+		var endTS int64
+		if err := rows.Scan(&rec.TradeID, &rec.Symbol, &rec.Side, &rec.EntryPrice, &rec.InitialAmount,
+			&rec.PnLUSD, &rec.PnLRatio, &endTS, &rec.TraceID); err != nil {
+			return nil, err
+		}
+		if endTS > 0 {
+			rec.ClosedAt = time.UnixMilli(endTS)
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// ListRecentClosedTrades 返回 symbol 最近 limit 笔已平仓交易，按平仓时间倒序（不关联开仓 trace）。
+func (s *DecisionLogStore) ListRecentClosedTrades(ctx context.Context, symbol string, limit int) ([]ClosedTradeStat, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+	rows, err := db.QueryContext(ctx, `SELECT freqtrade_id, symbol, side, COALESCE(price, 0), COALESCE(initial_amount, 0),
+			COALESCE(pnl_usd, 0), COALESCE(pnl_ratio, 0), COALESCE(end_timestamp, 0)
+		FROM live_orders
+		WHERE status = ? AND symbol = ?
+		ORDER BY COALESCE(end_timestamp, 0) DESC LIMIT ?`, int(storemodel.LiveOrderStatusClosed), strings.ToUpper(strings.TrimSpace(symbol)), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []ClosedTradeStat
+	for rows.Next() {
+		var rec ClosedTradeStat
+		var endTS int64
+		if err := rows.Scan(&rec.TradeID, &rec.Symbol, &rec.Side, &rec.EntryPrice, &rec.InitialAmount,
+			&rec.PnLUSD, &rec.PnLRatio, &endTS); err != nil {
+			return nil, err
+		}
+		if endTS > 0 {
+			rec.ClosedAt = time.UnixMilli(endTS)
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// FinalDecisionsByTrace 批量读取各 trace 的最终决策，用于把交易归属到开仓时的 profile 与止损。
+func (s *DecisionLogStore) FinalDecisionsByTrace(ctx context.Context, traceIDs []string) (map[string][]decision.Decision, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]decision.Decision, len(traceIDs))
+	const batch = 200
+	for start := 0; start < len(traceIDs); start += batch {
+		end := min(start+batch, len(traceIDs))
+		ids := traceIDs[start:end]
+		args := make([]any, 0, len(ids))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		rows, err := db.QueryContext(ctx, `SELECT trace_id, decisions_json FROM live_decision_logs
+			WHERE stage = 'final' AND trace_id IN (`+placeholders(len(ids))+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var traceID string
+			var decisions sql.NullString
+			if err := rows.Scan(&traceID, &decisions); err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+			out[traceID] = decodeDecisionArray(decisions.String)
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func (s *DecisionLogStore) handle() (*sqlDB, error) {
+	if s == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	s.mu.Lock()
+	db := s.db
+	s.mu.Unlock()
+	if db == nil {
+		return nil, fmt.Errorf("decision log store 未初始化")
+	}
+	return db, nil
+}
+
+func timeRangeMillis(from, to time.Time) (int64, int64) {
+	var fromMs int64
+	if !from.IsZero() {
+		fromMs = from.UnixMilli()
+	}
+	toMs := int64(1<<63 - 1)
+	if !to.IsZero() {
+		toMs = to.UnixMilli()
+	}
+	return fromMs, toMs
+}
@@ -15,18 +15,25 @@ import (
 	"brale/internal/decision"
 	"brale/internal/gateway/provider"
 	"brale/internal/logger"
+	"brale/internal/store/artifact"
 
 	_ "modernc.org/sqlite"
 )
 
 type DecisionLogStore struct {
 	mu     sync.Mutex
-	db     *sql.DB
+	db     *sqlDB
 	path   string
 	ownsDB bool
 
 	agentCacheMu     sync.RWMutex
 	agentOutputCache map[agentOutputCacheKey]agentOutputCacheEntry
+
+	artifacts   artifact.Store
+	artifactMin int
+	offloadQ    chan offloadJob
+	// artifactGCMu 串行化外置回写与回收，避免回收删掉刚被新行引用的同内容 artifact。
+	artifactGCMu sync.Mutex
 }
 
 type agentOutputCacheKey struct {
@@ -133,14 +140,15 @@ func NewDecisionLogStore(path string) (*DecisionLogStore, error) {
 	}
 	db.SetMaxOpenConns(2)
 	db.SetMaxIdleConns(2)
-	if err := ensureDecisionLogSchema(db); err != nil {
+	wrapped := newSQLDB(db, DialectSQLite)
+	if err := ensureDecisionLogSchema(wrapped); err != nil {
 		if cerr := db.Close(); cerr != nil {
 			logger.Warnf("decision log db close failed: %v", cerr)
 		}
 		return nil, err
 	}
 	return &DecisionLogStore{
-		db:               db,
+		db:               wrapped,
 		path:             path,
 		ownsDB:           true,
 		agentOutputCache: make(map[agentOutputCacheKey]agentOutputCacheEntry),
@@ -148,21 +156,27 @@ func NewDecisionLogStore(path string) (*DecisionLogStore, error) {
 }
 
 func (s *DecisionLogStore) UseExternalDB(db *sql.DB) error {
+	return s.UseExternalDBWithDialect(db, DialectSQLite)
+}
+
+// UseExternalDBWithDialect 改用外部连接（如与 live 库共享的 Postgres），并按方言建表。
+func (s *DecisionLogStore) UseExternalDBWithDialect(db *sql.DB, dialect Dialect) error {
 	if s == nil {
 		return fmt.Errorf("decision log store 未初始化")
 	}
 	if db == nil {
 		return fmt.Errorf("external db 不能为空")
 	}
-	if err := ensureDecisionLogSchema(db); err != nil {
+	wrapped := newSQLDB(db, dialect)
+	if err := ensureDecisionLogSchema(wrapped); err != nil {
 		return err
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.ownsDB && s.db != nil && s.db != db {
+	if s.ownsDB && s.db != nil && s.db.DB != db {
 		_ = s.db.Close()
 	}
-	s.db = db
+	s.db = wrapped
 	s.ownsDB = false
 	if s.agentOutputCache == nil {
 		s.agentOutputCache = make(map[agentOutputCacheKey]agentOutputCacheEntry)
@@ -177,6 +191,7 @@ func (s *DecisionLogStore) Close() error {
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.stopOffload()
 	if s.db == nil {
 		return nil
 	}
@@ -189,7 +204,7 @@ func (s *DecisionLogStore) Close() error {
 	return err
 }
 
-func ensureDecisionLogSchema(db *sql.DB) error {
+func ensureDecisionLogSchema(db *sqlDB) error {
 	stmts := []string{
 		`CREATE TABLE IF NOT EXISTS live_decision_logs (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -239,6 +254,9 @@ func ensureDecisionLogSchema(db *sql.DB) error {
 			unrealized_pnl_usd REAL DEFAULT 0,
 			realized_pnl_ratio REAL DEFAULT 0,
 			realized_pnl_usd REAL DEFAULT 0,
+			fee_usd REAL DEFAULT 0,
+			funding_usd REAL DEFAULT 0,
+			gross_realized_pnl_usd REAL DEFAULT 0,
 			is_simulated INTEGER NOT NULL DEFAULT 0,
 			status INTEGER NOT NULL,
 			start_timestamp INTEGER NOT NULL,
@@ -293,6 +311,61 @@ func ensureDecisionLogSchema(db *sql.DB) error {
 			timestamp INTEGER NOT NULL
 		);
 		`,
+		`CREATE TABLE IF NOT EXISTS entry_signals (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trace_id TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			side TEXT,
+			signals_json TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+		`,
+		`CREATE INDEX IF NOT EXISTS idx_entry_signals_trace ON entry_signals(trace_id);`,
+		`CREATE TABLE IF NOT EXISTS decision_audit (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			decision_log_id INTEGER NOT NULL,
+			trace_id TEXT,
+			stage TEXT NOT NULL,
+			provider_id TEXT NOT NULL,
+			system_prompt TEXT NOT NULL,
+			user_prompt TEXT NOT NULL,
+			raw_output TEXT NOT NULL,
+			decision_json TEXT,
+			snapshot_hash TEXT,
+			prompt_version TEXT,
+			latency_ms INTEGER NOT NULL DEFAULT 0,
+			error TEXT,
+			created_at INTEGER NOT NULL
+		);
+		`,
+		`CREATE INDEX IF NOT EXISTS idx_decision_audit_trace ON decision_audit(trace_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_decision_audit_log ON decision_audit(decision_log_id);`,
+		`CREATE TABLE IF NOT EXISTS decision_rationale (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trace_id TEXT,
+			stage TEXT NOT NULL,
+			provider_id TEXT NOT NULL,
+			symbol TEXT NOT NULL,
+			action TEXT NOT NULL,
+			drivers TEXT,
+			invalidation REAL NOT NULL DEFAULT 0,
+			confidence INTEGER NOT NULL DEFAULT 0,
+			source TEXT,
+			created_at INTEGER NOT NULL
+		);
+		`,
+		`CREATE INDEX IF NOT EXISTS idx_decision_rationale_trace ON decision_rationale(trace_id);`,
+		`CREATE TABLE IF NOT EXISTS model_usage_daily (
+			day TEXT NOT NULL,
+			model_id TEXT NOT NULL,
+			calls INTEGER NOT NULL DEFAULT 0,
+			input_tokens INTEGER NOT NULL DEFAULT 0,
+			output_tokens INTEGER NOT NULL DEFAULT 0,
+			cost_usd REAL NOT NULL DEFAULT 0,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (day, model_id)
+		);
+		`,
 		`CREATE INDEX IF NOT EXISTS idx_live_logs_ts ON live_decision_logs(ts);`,
 		`CREATE INDEX IF NOT EXISTS idx_live_logs_provider ON live_decision_logs(provider_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_live_logs_symbol ON live_decision_logs(symbols);`,
@@ -308,14 +381,14 @@ func ensureDecisionLogSchema(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_trade_operation_freqtrade ON trade_operation_log(freqtrade_id);`,
 	}
 	for _, stmt := range stmts {
-		if _, err := db.Exec(stmt); err != nil {
+		if _, err := db.Exec(db.ddl(stmt)); err != nil {
 			return err
 		}
 	}
 	return ensureDecisionLogColumns(db)
 }
 
-func ensureDecisionLogColumns(db *sql.DB) error {
+func ensureDecisionLogColumns(db *sqlDB) error {
 	cols := []struct {
 		table  string
 		column string
@@ -326,6 +399,7 @@ func ensureDecisionLogColumns(db *sql.DB) error {
 		{"live_decision_logs", "images_json", "TEXT"},
 		{"live_decision_logs", "vision_supported", "INTEGER"},
 		{"live_decision_logs", "image_count", "INTEGER"},
+		{"decision_audit", "prompt_version", "TEXT"},
 		{"live_orders", "position_value", "REAL NOT NULL DEFAULT 0"},
 		{"live_orders", "pnl_ratio", "REAL DEFAULT 0"},
 		{"live_orders", "pnl_usd", "REAL DEFAULT 0"},
@@ -336,45 +410,19 @@ func ensureDecisionLogColumns(db *sql.DB) error {
 		{"live_orders", "unrealized_pnl_usd", "REAL DEFAULT 0"},
 		{"live_orders", "realized_pnl_ratio", "REAL DEFAULT 0"},
 		{"live_orders", "realized_pnl_usd", "REAL DEFAULT 0"},
+		{"live_orders", "fee_usd", "REAL DEFAULT 0"},
+		{"live_orders", "funding_usd", "REAL DEFAULT 0"},
+		{"live_orders", "gross_realized_pnl_usd", "REAL DEFAULT 0"},
 		{"live_orders", "last_status_sync", "INTEGER"},
 	}
 	for _, col := range cols {
-		if err := addColumnIfMissing(db, col.table, col.column, col.typ); err != nil {
+		if err := db.addColumnIfMissing(col.table, col.column, col.typ); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func addColumnIfMissing(db *sql.DB, table, column, typ string) error {
-	query := fmt.Sprintf("PRAGMA table_info(%s)", table)
-	rows, err := db.Query(query)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = rows.Close() }()
-	exists := false
-	for rows.Next() {
-		var cid int
-		var name, ctype string
-		var notnull, pk int
-		var dflt sql.NullString
-		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk); err != nil {
-			return err
-		}
-		if strings.EqualFold(name, column) {
-			exists = true
-			break
-		}
-	}
-	if exists {
-		return nil
-	}
-	stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, typ)
-	_, err = db.Exec(stmt)
-	return err
-}
-
 func (s *DecisionLogStore) Insert(ctx context.Context, rec DecisionLogRecord) (int64, error) {
 	s.mu.Lock()
 	db := s.db
@@ -401,7 +449,8 @@ func (s *DecisionLogStore) Insert(ctx context.Context, rec DecisionLogRecord) (i
 		}
 		return string(b)
 	}
-	res, err := db.ExecContext(ctx, `
+	images := enc(rec.Images)
+	id, err := db.insertReturningID(ctx, `
 		INSERT INTO live_decision_logs
 			(ts, candidates, timeframes, horizon, provider_id, stage, system_prompt, user_prompt,
 			 raw_output, raw_json, meta_summary, decisions_json, positions_json, symbols, images_json,
@@ -421,7 +470,7 @@ func (s *DecisionLogStore) Insert(ctx context.Context, rec DecisionLogRecord) (i
 		enc(rec.Decisions),
 		enc(rec.Positions),
 		symbolBlob,
-		enc(rec.Images),
+		images,
 		boolToInt(rec.VisionSupported),
 		rec.ImageCount,
 		rec.Error,
@@ -432,7 +481,13 @@ func (s *DecisionLogStore) Insert(ctx context.Context, rec DecisionLogRecord) (i
 	if err != nil {
 		return 0, err
 	}
-	id, _ := res.LastInsertId()
+	s.enqueueOffload(id, map[string]string{
+		"system_prompt": rec.System,
+		"user_prompt":   rec.User,
+		"raw_output":    rec.RawOutput,
+		"raw_json":      rec.RawJSON,
+		"images_json":   images,
+	})
 	s.maybeCacheAgentOutput(rec, ts)
 	return id, nil
 }
@@ -518,7 +573,8 @@ type rowScanner interface {
 	Scan(dest ...interface{}) error
 }
 
-func scanDecisionLogRecord(scanner rowScanner) (DecisionLogRecord, error) {
+// scanDecisionLogRecord 扫描一行决策日志；load 用于还原 artifact 引用字段。
+func scanDecisionLogRecord(scanner rowScanner, load func(string) string) (DecisionLogRecord, error) {
 	var (
 		rec        DecisionLogRecord
 		candidates sql.NullString
@@ -542,10 +598,10 @@ func scanDecisionLogRecord(scanner rowScanner) (DecisionLogRecord, error) {
 		&decisions, &positions, &symbols, &images, &vision, &imageCount, &errorStr, &noteStr); err != nil {
 		return rec, err
 	}
-	rec.System = system.String
-	rec.User = user.String
-	rec.RawOutput = rawOut.String
-	rec.RawJSON = rawJSON.String
+	rec.System = load(system.String)
+	rec.User = load(user.String)
+	rec.RawOutput = load(rawOut.String)
+	rec.RawJSON = load(rawJSON.String)
 	rec.Meta = meta.String
 	rec.Error = errorStr.String
 	rec.Note = noteStr.String
@@ -554,7 +610,7 @@ func scanDecisionLogRecord(scanner rowScanner) (DecisionLogRecord, error) {
 	rec.Decisions = decodeDecisionArray(decisions.String)
 	rec.Positions = decodePositionArray(positions.String)
 	rec.Symbols = decodeSymbolBlob(symbols.String)
-	rec.Images = decodeImageArray(images.String)
+	rec.Images = decodeImageArray(load(images.String))
 	rec.VisionSupported = nullIntToBool(vision)
 	if imageCount.Valid {
 		rec.ImageCount = int(imageCount.Int64)
@@ -579,7 +635,7 @@ func (s *DecisionLogStore) GetDecision(ctx context.Context, id int64) (DecisionL
 		system_prompt, user_prompt, raw_output, raw_json, meta_summary, decisions_json,
 		positions_json, symbols, images_json, vision_supported, image_count, error, note
 		FROM live_decision_logs WHERE id = ?`, id)
-	return scanDecisionLogRecord(row)
+	return scanDecisionLogRecord(row, s.loader(ctx))
 }
 
 func (s *DecisionLogStore) ListDecisions(ctx context.Context, q LiveDecisionQuery) ([]DecisionLogRecord, error) {
@@ -613,7 +669,7 @@ func (s *DecisionLogStore) ListDecisions(ctx context.Context, q LiveDecisionQuer
 	defer func() { _ = rows.Close() }()
 	var list []DecisionLogRecord
 	for rows.Next() {
-		rec, err := scanDecisionLogRecord(rows)
+		rec, err := scanDecisionLogRecord(rows, keepRefs)
 		if err != nil {
 			return nil, err
 		}
@@ -663,7 +719,7 @@ func (s *DecisionLogStore) ListDecisionsByTraceID(ctx context.Context, traceID s
 	defer func() { _ = rows.Close() }()
 	var list []DecisionLogRecord
 	for rows.Next() {
-		rec, err := scanDecisionLogRecord(rows)
+		rec, err := scanDecisionLogRecord(rows, keepRefs)
 		if err != nil {
 			return nil, err
 		}
@@ -750,7 +806,7 @@ func (s *DecisionLogStore) LatestProviderOutputs(ctx context.Context, symbol str
 		out := decision.ProviderOutputSnapshot{
 			ProviderID: providerID,
 			Decisions:  filterDecisionsBySymbol(decodeDecisionArray(decisions.String), symbol),
-			RawOutput:  strings.TrimSpace(rawOutput.String),
+			RawOutput:  strings.TrimSpace(s.inflate(ctx, rawOutput.String)),
 			Timestamp:  ts.Int64,
 		}
 		if prev, ok := byProvider[providerID]; !ok || out.Timestamp >= prev.Timestamp {
@@ -865,7 +921,7 @@ func (s *DecisionLogStore) queryLatestAgentOutput(ctx context.Context, key agent
 		return decision.AgentOutputSnapshot{}, err
 	}
 	return decision.AgentOutputSnapshot{
-		Output:      strings.TrimSpace(raw.String),
+		Output:      strings.TrimSpace(s.inflate(ctx, raw.String)),
 		Fingerprint: parseAgentFingerprint(note.String),
 		Timestamp:   ts.Int64,
 	}, nil
@@ -901,7 +957,7 @@ func (s *DecisionLogStore) ListDecisionsByTraceIDs(ctx context.Context, traceIDs
 	defer func() { _ = rows.Close() }()
 	result := make(map[string][]DecisionLogRecord, len(clean))
 	for rows.Next() {
-		rec, err := scanDecisionLogRecord(rows)
+		rec, err := scanDecisionLogRecord(rows, keepRefs)
 		if err != nil {
 			return nil, err
 		}
@@ -940,14 +996,30 @@ func (o *DecisionLogObserver) AfterDecide(ctx context.Context, trace decision.De
 		User:       trace.UserPrompt,
 		Positions:  cloneSnapshots(trace.Positions),
 	}
+	if reason := strings.TrimSpace(trace.SkipReason); reason != "" {
+		o.logSkipped(ctx, base, reason, candidateSymbols)
+		return
+	}
 	for _, out := range trace.Outputs {
-		o.logProviderDecision(ctx, base, out, candidateSymbols)
+		o.logProviderDecision(ctx, base, out, candidateSymbols, trace)
 	}
 	o.logFinalDecision(ctx, base, trace, candidateSymbols)
 	o.logAgentInsights(ctx, base, trace.AgentInsights, candidateSymbols)
 }
 
-func (o *DecisionLogObserver) logProviderDecision(ctx context.Context, base DecisionLogRecord, out decision.ModelOutput, candidateSymbols []string) {
+// logSkipped 记录未调用模型的轮次，stage=skipped，note 为跳过原因。
+func (o *DecisionLogObserver) logSkipped(ctx context.Context, base DecisionLogRecord, reason string, candidateSymbols []string) {
+	rec := base
+	rec.Stage = "skipped"
+	rec.ProviderID = "scheduler"
+	rec.Symbols = candidateSymbols
+	rec.Note = reason
+	if _, err := o.store.Insert(ctx, rec); err != nil {
+		logger.Warnf("写入决策日志失败(skipped): %v", err)
+	}
+}
+
+func (o *DecisionLogObserver) logProviderDecision(ctx context.Context, base DecisionLogRecord, out decision.ModelOutput, candidateSymbols []string, trace decision.DecisionTrace) {
 	rec := base
 	rec.ProviderID = out.ProviderID
 	rec.Stage = "provider"
@@ -969,9 +1041,13 @@ func (o *DecisionLogObserver) logProviderDecision(ctx context.Context, base Deci
 	if out.Err != nil {
 		rec.Error = out.Err.Error()
 	}
-	if _, err := o.store.Insert(ctx, rec); err != nil {
+	id, err := o.store.Insert(ctx, rec)
+	if err != nil {
 		logger.Warnf("写入决策日志失败(provider): %v", err)
+		return
 	}
+	o.insertAudit(ctx, id, rec, out, trace)
+	o.insertRationales(ctx, rec)
 }
 
 func (o *DecisionLogObserver) logFinalDecision(ctx context.Context, base DecisionLogRecord, trace decision.DecisionTrace, candidateSymbols []string) {
@@ -999,9 +1075,15 @@ func (o *DecisionLogObserver) logFinalDecision(ctx context.Context, base Decisio
 	if trace.Best.Err != nil {
 		finalRec.Error = trace.Best.Err.Error()
 	}
-	if _, err := o.store.Insert(ctx, finalRec); err != nil {
+	id, err := o.store.Insert(ctx, finalRec)
+	if err != nil {
 		logger.Warnf("写入决策日志失败(final): %v", err)
+		return
 	}
+	best := trace.Best
+	best.Latency = trace.Latency
+	o.insertAudit(ctx, id, finalRec, best, trace)
+	o.insertRationales(ctx, finalRec)
 }
 
 func (o *DecisionLogObserver) logAgentInsights(ctx context.Context, base DecisionLogRecord, insights []decision.AgentInsight, candidateSymbols []string) {
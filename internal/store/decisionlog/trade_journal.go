@@ -0,0 +1,112 @@
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// TradeJournalExtra 为交易日志的补充字段：分段止盈/止损的档位数与已触发档位（组件名如 tp_tiers.tier1），
+// EntryDriftPct 为开仓成交价相对决策参考价的偏移（(fill-ref)/ref，未记录漂移时 HasEntryDrift=false）。
+type TradeJournalExtra struct {
+	TPTiers       int
+	TPTiersHit    int
+	SLTiersHit    int
+	HitTiers      []string
+	EntryDriftPct float64
+	HasEntryDrift bool
+}
+
+// TradeJournalExtras 按 trade_id 读取分段计划的触发情况与开仓成交漂移。
+func (s *DecisionLogStore) TradeJournalExtras(ctx context.Context, tradeIDs []int) (map[int]TradeJournalExtra, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]TradeJournalExtra, len(tradeIDs))
+	const batch = 200
+	for start := 0; start < len(tradeIDs); start += batch {
+		ids := tradeIDs[start:min(start+batch, len(tradeIDs))]
+		args := make([]any, 0, len(ids))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		if err := scanJournalTiers(ctx, db, args, out); err != nil {
+			return nil, err
+		}
+		if err := scanJournalEntryDrift(ctx, db, args, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func scanJournalTiers(ctx context.Context, db *sqlDB, args []any, out map[int]TradeJournalExtra) error {
+	rows, err := db.QueryContext(ctx, `SELECT trade_id, plan_component, COALESCE(state_json, '') FROM strategy_instances
+		WHERE plan_component LIKE '%.tier%' AND trade_id IN (`+placeholders(len(args))+`) ORDER BY id ASC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tradeID int
+		var component, raw string
+		if err := rows.Scan(&tradeID, &component, &raw); err != nil {
+			return err
+		}
+		var state struct {
+			Status string `json:"status"`
+		}
+		if raw != "" {
+			_ = json.Unmarshal([]byte(raw), &state)
+		}
+		status := strings.ToLower(strings.TrimSpace(state.Status))
+		hit := status == "triggered" || status == "done"
+		component = strings.ToLower(strings.TrimSpace(component))
+		ex := out[tradeID]
+		isTP := strings.HasPrefix(component, "tp_")
+		if isTP {
+			ex.TPTiers++
+		}
+		if hit {
+			if isTP {
+				ex.TPTiersHit++
+			} else {
+				ex.SLTiersHit++
+			}
+			ex.HitTiers = append(ex.HitTiers, component)
+		}
+		out[tradeID] = ex
+	}
+	return rows.Err()
+}
+
+// scanJournalEntryDrift 读取开仓成交时写入 trade_operation_log 的 fill_drift_pct（operation=1 即 OperationOpen）。
+func scanJournalEntryDrift(ctx context.Context, db *sqlDB, args []any, out map[int]TradeJournalExtra) error {
+	rows, err := db.QueryContext(ctx, `SELECT freqtrade_id, details FROM trade_operation_log
+		WHERE operation = 1 AND freqtrade_id IN (`+placeholders(len(args))+`) ORDER BY id ASC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tradeID int
+		var raw []byte
+		if err := rows.Scan(&tradeID, &raw); err != nil {
+			return err
+		}
+		var details struct {
+			FillDriftPct *float64 `json:"fill_drift_pct"`
+		}
+		if len(raw) == 0 || json.Unmarshal(raw, &details) != nil || details.FillDriftPct == nil {
+			continue
+		}
+		ex := out[tradeID]
+		if !ex.HasEntryDrift {
+			ex.EntryDriftPct = *details.FillDriftPct
+			ex.HasEntryDrift = true
+		}
+		out[tradeID] = ex
+	}
+	return rows.Err()
+}
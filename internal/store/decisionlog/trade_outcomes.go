@@ -0,0 +1,190 @@
+package decisionlog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"brale/internal/decision"
+	symbolpkg "brale/internal/pkg/symbol"
+	storemodel "brale/internal/store/model"
+)
+
+// TradeOutcome 为已平仓交易关联开仓轮次的结果：Entry 取自 decision_audit 的 final 记录（缺失时回退决策日志），
+// Signals 为开仓时的背离快照；TraceID 为空表示无法关联回决策。ExitPrice 为平仓成交价（freqtrade close_rate）。
+type TradeOutcome struct {
+	TradeID       int
+	Symbol        string
+	Side          string
+	EntryPrice    float64
+	ExitPrice     float64
+	InitialAmount float64
+	FeeUSD        float64
+	PnLUSD        float64
+	PnLRatio      float64
+	OpenedAt      time.Time
+	ClosedAt      time.Time
+	TraceID       string
+	Entry         *decision.Decision
+	Signals       map[string]string
+}
+
+// ListTradeOutcomes 返回平仓时间在 [from, to) 内的交易及其开仓决策与信号。
+func (s *DecisionLogStore) ListTradeOutcomes(ctx context.Context, from, to time.Time) ([]TradeOutcome, error) {
+	db, err := s.handle()
+	if err != nil {
+		return nil, err
+	}
+	fromMs, toMs := timeRangeMillis(from, to)
+	rows, err := db.QueryContext(ctx, `SELECT o.freqtrade_id, o.symbol, o.side, COALESCE(o.price, 0), COALESCE(o.current_price, 0),
+			COALESCE(o.initial_amount, 0), COALESCE(o.fee_usd, 0), COALESCE(o.pnl_usd, 0), COALESCE(o.pnl_ratio, 0),
+			COALESCE(o.start_timestamp, 0), COALESCE(o.end_timestamp, 0), COALESCE(si.trace_id, '')
+		FROM live_orders o
+		LEFT JOIN (SELECT trade_id, MIN(decision_trace_id) AS trace_id FROM strategy_instances
+			WHERE decision_trace_id IS NOT NULL AND decision_trace_id != '' GROUP BY trade_id) si
+			ON si.trade_id = o.freqtrade_id
+		WHERE o.status = ? AND COALESCE(o.end_timestamp, 0) >= ? AND COALESCE(o.end_timestamp, 0) < ?
+		ORDER BY o.end_timestamp ASC`, int(storemodel.LiveOrderStatusClosed), fromMs, toMs)
+	if err != nil {
+		return nil, err
+	}
+	var out []TradeOutcome
+	var traceIDs []string
+	seen := make(map[string]struct{})
+	for rows.Next() {
+		var rec TradeOutcome
+		var startTS, endTS int64
+		if err := rows.Scan(&rec.TradeID, &rec.Symbol, &rec.Side, &rec.EntryPrice, &rec.ExitPrice, &rec.InitialAmount,
+			&rec.FeeUSD, &rec.PnLUSD, &rec.PnLRatio, &startTS, &endTS, &rec.TraceID); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if startTS > 0 {
+			rec.OpenedAt = time.UnixMilli(startTS)
+		}
+		if endTS > 0 {
+			rec.ClosedAt = time.UnixMilli(endTS)
+		}
+		if rec.TraceID != "" {
+			if _, ok := seen[rec.TraceID]; !ok {
+				seen[rec.TraceID] = struct{}{}
+				traceIDs = append(traceIDs, rec.TraceID)
+			}
+		}
+		out = append(out, rec)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if len(traceIDs) == 0 {
+		return out, nil
+	}
+	decisions, err := finalAuditDecisions(ctx, db, traceIDs)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, id := range traceIDs {
+		if _, ok := decisions[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		fallback, err := s.FinalDecisionsByTrace(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for id, ds := range fallback {
+			decisions[id] = ds
+		}
+	}
+	signals, err := entrySignalsByTrace(ctx, db, traceIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		rec := &out[i]
+		if rec.TraceID == "" {
+			continue
+		}
+		sym := symbolpkg.Normalize(rec.Symbol)
+		for _, d := range decisions[rec.TraceID] {
+			if symbolpkg.Normalize(d.Symbol) == sym {
+				d := d
+				rec.Entry = &d
+				break
+			}
+		}
+		rec.Signals = signals[rec.TraceID+"|"+sym]
+	}
+	return out, nil
+}
+
+// finalAuditDecisions 读取各 trace 的 final 审计决策，同一 trace 多条时取最新。
+func finalAuditDecisions(ctx context.Context, db *sqlDB, traceIDs []string) (map[string][]decision.Decision, error) {
+	out := make(map[string][]decision.Decision, len(traceIDs))
+	const batch = 200
+	for start := 0; start < len(traceIDs); start += batch {
+		ids := traceIDs[start:min(start+batch, len(traceIDs))]
+		args := make([]any, 0, len(ids))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		rows, err := db.QueryContext(ctx, `SELECT trace_id, decision_json FROM decision_audit
+			WHERE stage = 'final' AND trace_id IN (`+placeholders(len(ids))+`) ORDER BY id ASC`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var traceID string
+			var payload sql.NullString
+			if err := rows.Scan(&traceID, &payload); err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+			if ds := decodeDecisionArray(payload.String); len(ds) > 0 {
+				out[traceID] = ds
+			}
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// entrySignalsByTrace 以 "trace|SYMBOL" 为键返回开仓信号快照，同键多条时取最新。
+func entrySignalsByTrace(ctx context.Context, db *sqlDB, traceIDs []string) (map[string]map[string]string, error) {
+	out := make(map[string]map[string]string)
+	const batch = 200
+	for start := 0; start < len(traceIDs); start += batch {
+		ids := traceIDs[start:min(start+batch, len(traceIDs))]
+		args := make([]any, 0, len(ids))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		rows, err := db.QueryContext(ctx, `SELECT trace_id, symbol, signals_json FROM entry_signals
+			WHERE trace_id IN (`+placeholders(len(ids))+`) ORDER BY id ASC`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var traceID, sym string
+			var payload sql.NullString
+			if err := rows.Scan(&traceID, &sym, &payload); err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+			var sigs map[string]string
+			if payload.String == "" || json.Unmarshal([]byte(payload.String), &sigs) != nil {
+				continue
+			}
+			out[traceID+"|"+symbolpkg.Normalize(sym)] = sigs
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
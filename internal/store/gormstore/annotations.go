@@ -0,0 +1,123 @@
+package gormstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/database"
+)
+
+// annotationModel 的 tags 以 ",a,b," 形式存储，便于按单个标签 LIKE 查询。
+type annotationModel struct {
+	ID            int64  `gorm:"column:id;primaryKey"`
+	TargetType    string `gorm:"column:target_type;index:idx_annotation_target"`
+	TargetID      string `gorm:"column:target_id;index:idx_annotation_target"`
+	Note          string `gorm:"column:note"`
+	Tags          string `gorm:"column:tags"`
+	CreatedAtUnix int64  `gorm:"column:created_at;index"`
+}
+
+func (annotationModel) TableName() string { return "annotations" }
+
+func (s *GormStore) AddAnnotation(ctx context.Context, rec database.AnnotationRecord) (database.AnnotationRecord, error) {
+	if s == nil || s.db == nil {
+		return rec, fmt.Errorf("gorm store 未初始化")
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	rec.Tags = normalizeTags(rec.Tags)
+	model := annotationModel{
+		TargetType:    rec.TargetType,
+		TargetID:      rec.TargetID,
+		Note:          rec.Note,
+		Tags:          encodeTags(rec.Tags),
+		CreatedAtUnix: rec.CreatedAt.UnixMilli(),
+	}
+	if err := s.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return rec, err
+	}
+	rec.ID = model.ID
+	return rec, nil
+}
+
+func (s *GormStore) ListAnnotations(ctx context.Context, q database.AnnotationQuery) ([]database.AnnotationRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("gorm store 未初始化")
+	}
+	limit := q.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	query := s.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if q.TargetType != "" {
+		query = query.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID != "" {
+		query = query.Where("target_id = ?", q.TargetID)
+	}
+	if tag := strings.ToLower(strings.TrimSpace(q.Tag)); tag != "" {
+		query = query.Where("tags LIKE ?", "%,"+tag+",%")
+	}
+	var models []annotationModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+	out := make([]database.AnnotationRecord, 0, len(models))
+	for _, m := range models {
+		out = append(out, database.AnnotationRecord{
+			ID:         m.ID,
+			TargetType: m.TargetType,
+			TargetID:   m.TargetID,
+			Note:       m.Note,
+			Tags:       decodeTags(m.Tags),
+			CreatedAt:  time.UnixMilli(m.CreatedAtUnix),
+		})
+	}
+	return out, nil
+}
+
+func (s *GormStore) DeleteAnnotation(ctx context.Context, id int64) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("gorm store 未初始化")
+	}
+	res := s.db.WithContext(ctx).Delete(&annotationModel{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("annotation %d 不存在", id)
+	}
+	return nil
+}
+
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(t, ",", " ")))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+func encodeTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "," + strings.Join(tags, ",") + ","
+}
+
+func decodeTags(raw string) []string {
+	raw = strings.Trim(raw, ",")
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
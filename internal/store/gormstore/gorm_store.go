@@ -14,6 +14,7 @@ import (
 	storemodel "brale/internal/store/model"
 
 	"gorm.io/datatypes"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -52,7 +53,20 @@ func NewGormStore(path string) (*GormStore, error) {
 	}
 	// 提高 busy_timeout，减少高并发下的 “database is locked” 告警
 	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(15000)&_pragma=journal_mode(WAL)", path)
-	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+	return openGormStore(sqlite.Open(dsn), 2)
+}
+
+// NewPostgresGormStore 连接 Postgres 并执行同样的 AutoMigrate，供多实例共享同一个库。
+func NewPostgresGormStore(dsn string) (*GormStore, error) {
+	dsn = strings.TrimSpace(dsn)
+	if dsn == "" {
+		return nil, fmt.Errorf("gorm store: postgres dsn 不能为空")
+	}
+	return openGormStore(postgres.Open(dsn), 10)
+}
+
+func openGormStore(dialector gorm.Dialector, maxConns int) (*GormStore, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger:                                   logger.Default.LogMode(logger.Silent),
 		DisableForeignKeyConstraintWhenMigrating: true,
 	})
@@ -66,6 +80,9 @@ func NewGormStore(path string) (*GormStore, error) {
 		&liveOrderModel{},
 		&tradeOperationModel{},
 		&eventLogModel{},
+		&webhookDeliveryModel{},
+		&annotationModel{},
+		&pendingExitModel{},
 	}
 	if err := db.AutoMigrate(models...); err != nil {
 		return nil, err
@@ -75,8 +92,8 @@ func NewGormStore(path string) (*GormStore, error) {
 		return nil, err
 	}
 
-	sqlDB.SetMaxOpenConns(2)
-	sqlDB.SetMaxIdleConns(2)
+	sqlDB.SetMaxOpenConns(maxConns)
+	sqlDB.SetMaxIdleConns(maxConns)
 	return &GormStore{db: db}, nil
 }
 
@@ -102,6 +119,14 @@ func (s *GormStore) SQLDB() (*sql.DB, error) {
 	return s.db.DB()
 }
 
+// Dialect 返回底层数据库方言名（sqlite/postgres）。
+func (s *GormStore) Dialect() string {
+	if s == nil || s.db == nil {
+		return ""
+	}
+	return s.db.Dialector.Name()
+}
+
 func (s *GormStore) GormDB() *gorm.DB {
 	if s == nil {
 		return nil
@@ -112,6 +137,10 @@ func (s *GormStore) GormDB() *gorm.DB {
 var (
 	_ LivePositionStore = (*GormStore)(nil)
 	_ LivePositionStore = (*GormStrategyStore)(nil)
+
+	_ database.WebhookDeliveryLog = (*GormStore)(nil)
+	_ database.AnnotationStore    = (*GormStore)(nil)
+	_ database.PendingExitStore   = (*GormStore)(nil)
 )
 
 func (s *GormStore) InsertStrategyInstances(ctx context.Context, recs []StrategyInstanceRecord) error {
@@ -353,7 +382,7 @@ func (s *GormStore) UpsertLiveOrder(ctx context.Context, rec LiveOrderRecord) er
 		"symbol", "side", "amount", "initial_amount", "stake_amount", "leverage", "position_value",
 		"price", "closed_amount", "pnl_ratio", "pnl_usd", "current_price", "current_profit_ratio",
 		"current_profit_abs", "unrealized_pnl_ratio", "unrealized_pnl_usd", "realized_pnl_ratio",
-		"realized_pnl_usd", "is_simulated", "status", "start_timestamp", "end_timestamp",
+		"realized_pnl_usd", "fee_usd", "funding_usd", "gross_realized_pnl_usd", "is_simulated", "status", "start_timestamp", "end_timestamp",
 		"last_status_sync", "raw_data", "updated_at",
 	}
 	return s.db.WithContext(ctx).
@@ -407,7 +436,7 @@ func (s *GormStore) SavePosition(ctx context.Context, order LiveOrderRecord) err
 				"symbol", "side", "amount", "initial_amount", "stake_amount", "leverage", "position_value",
 				"price", "closed_amount", "pnl_ratio", "pnl_usd", "current_price", "current_profit_ratio",
 				"current_profit_abs", "unrealized_pnl_ratio", "unrealized_pnl_usd", "realized_pnl_ratio",
-				"realized_pnl_usd", "is_simulated", "status", "start_timestamp", "end_timestamp",
+				"realized_pnl_usd", "fee_usd", "funding_usd", "gross_realized_pnl_usd", "is_simulated", "status", "start_timestamp", "end_timestamp",
 				"last_status_sync", "raw_data", "updated_at",
 			}),
 		}).Create(ptrToLiveOrderModel(newLiveOrderModel(order))).Error
@@ -754,6 +783,9 @@ func newLiveOrderModel(rec LiveOrderRecord) liveOrderModel {
 		UnrealizedUSD:     valOrZero(rec.UnrealizedPnLUSD),
 		RealizedRatio:     valOrZero(rec.RealizedPnLRatio),
 		RealizedUSD:       valOrZero(rec.RealizedPnLUSD),
+		FeeUSD:            valOrZero(rec.FeeUSD),
+		FundingUSD:        valOrZero(rec.FundingUSD),
+		GrossRealizedUSD:  valOrZero(rec.GrossRealizedPnLUSD),
 		IsSimulated:       boolPtrToInt(rec.IsSimulated),
 		Status:            rec.Status,
 		StartTimestamp:    timeToMillis(rec.StartTime),
@@ -803,6 +835,9 @@ func liveOrderModelToRecord(m liveOrderModel) LiveOrderRecord {
 	rec.UnrealizedPnLUSD = ptrFloat(m.UnrealizedUSD)
 	rec.RealizedPnLRatio = ptrFloat(m.RealizedRatio)
 	rec.RealizedPnLUSD = ptrFloat(m.RealizedUSD)
+	rec.FeeUSD = ptrFloat(m.FeeUSD)
+	rec.FundingUSD = ptrFloat(m.FundingUSD)
+	rec.GrossRealizedPnLUSD = ptrFloat(m.GrossRealizedUSD)
 	rec.IsSimulated = ptrBool(m.IsSimulated != 0)
 	return rec
 }
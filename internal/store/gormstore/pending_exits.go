@@ -0,0 +1,61 @@
+package gormstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"brale/internal/gateway/database"
+
+	"gorm.io/gorm/clause"
+)
+
+type pendingExitModel struct {
+	TradeID         int   `gorm:"column:trade_id;primaryKey;autoIncrement:false"`
+	RequestedAtUnix int64 `gorm:"column:requested_at"`
+}
+
+func (pendingExitModel) TableName() string { return "pending_exits" }
+
+// SavePendingExit 按 trade_id upsert，重新下发平仓时刷新 requested_at。
+func (s *GormStore) SavePendingExit(ctx context.Context, rec database.PendingExitRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("gorm store 未初始化")
+	}
+	if rec.TradeID <= 0 {
+		return nil
+	}
+	if rec.RequestedAt.IsZero() {
+		rec.RequestedAt = time.Now()
+	}
+	model := pendingExitModel{TradeID: rec.TradeID, RequestedAtUnix: rec.RequestedAt.UnixMilli()}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "trade_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"requested_at"}),
+	}).Create(&model).Error
+}
+
+func (s *GormStore) DeletePendingExit(ctx context.Context, tradeID int) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("gorm store 未初始化")
+	}
+	return s.db.WithContext(ctx).Where("trade_id = ?", tradeID).Delete(&pendingExitModel{}).Error
+}
+
+func (s *GormStore) ListPendingExits(ctx context.Context) ([]database.PendingExitRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("gorm store 未初始化")
+	}
+	var models []pendingExitModel
+	if err := s.db.WithContext(ctx).Order("trade_id ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	out := make([]database.PendingExitRecord, 0, len(models))
+	for _, m := range models {
+		out = append(out, database.PendingExitRecord{
+			TradeID:     m.TradeID,
+			RequestedAt: time.UnixMilli(m.RequestedAtUnix),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package gormstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type retentionTable struct {
+	timeColumn string
+	millis     bool
+}
+
+// 各表时间列单位不同：trade_operation_log/event_log/webhook_deliveries 为毫秒，strategy_change_log 为秒。
+var retentionTables = map[string]retentionTable{
+	"trade_operation_log": {timeColumn: "timestamp", millis: true},
+	"strategy_change_log": {timeColumn: "created_at"},
+	"event_log":           {timeColumn: "created_at", millis: true},
+	"webhook_deliveries":  {timeColumn: "created_at", millis: true},
+}
+
+// ArchiveBefore 分批取出 cutoff 之前的记录交给 sink 归档，sink 成功后才在同一事务内删除。
+// 返回本次删除的记录总数。
+func (s *GormStore) ArchiveBefore(ctx context.Context, table string, cutoff time.Time, batch int, sink func([]map[string]any) error) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("gorm store 未初始化")
+	}
+	spec, ok := retentionTables[table]
+	if !ok {
+		return 0, fmt.Errorf("table %s 不支持归档", table)
+	}
+	if batch <= 0 {
+		batch = 500
+	}
+	bound := cutoff.Unix()
+	if spec.millis {
+		bound = cutoff.UnixMilli()
+	}
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n := 0
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var rows []map[string]any
+			if err := tx.Table(table).
+				Where(fmt.Sprintf("%s < ?", spec.timeColumn), bound).
+				Order("id ASC").
+				Limit(batch).
+				Find(&rows).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			if sink != nil {
+				if err := sink(rows); err != nil {
+					return err
+				}
+			}
+			ids := make([]any, 0, len(rows))
+			for _, row := range rows {
+				ids = append(ids, row["id"])
+			}
+			res := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id IN ?", table), ids)
+			if res.Error != nil {
+				return res.Error
+			}
+			n = int(res.RowsAffected)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < batch {
+			return total, nil
+		}
+	}
+}
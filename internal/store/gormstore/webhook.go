@@ -0,0 +1,95 @@
+package gormstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/database"
+)
+
+type webhookDeliveryModel struct {
+	ID            int64  `gorm:"column:id;primaryKey"`
+	EventID       string `gorm:"column:event_uuid;index"`
+	EventType     string `gorm:"column:event_type;index"`
+	Symbol        string `gorm:"column:symbol"`
+	TradeID       int    `gorm:"column:trade_id"`
+	Endpoint      string `gorm:"column:endpoint;index"`
+	URL           string `gorm:"column:url"`
+	Attempt       int    `gorm:"column:attempt"`
+	StatusCode    int    `gorm:"column:status_code"`
+	Success       bool   `gorm:"column:success"`
+	Error         string `gorm:"column:error"`
+	DurationMs    int64  `gorm:"column:duration_ms"`
+	CreatedAtUnix int64  `gorm:"column:created_at;index"`
+}
+
+func (webhookDeliveryModel) TableName() string { return "webhook_deliveries" }
+
+func (s *GormStore) AppendWebhookDelivery(ctx context.Context, rec database.WebhookDeliveryRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("gorm store 未初始化")
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	model := webhookDeliveryModel{
+		EventID:       rec.EventID,
+		EventType:     rec.EventType,
+		Symbol:        strings.ToUpper(strings.TrimSpace(rec.Symbol)),
+		TradeID:       rec.TradeID,
+		Endpoint:      rec.Endpoint,
+		URL:           rec.URL,
+		Attempt:       rec.Attempt,
+		StatusCode:    rec.StatusCode,
+		Success:       rec.Success,
+		Error:         rec.Error,
+		DurationMs:    rec.DurationMs,
+		CreatedAtUnix: rec.CreatedAt.UnixMilli(),
+	}
+	return s.db.WithContext(ctx).Create(&model).Error
+}
+
+func (s *GormStore) ListWebhookDeliveries(ctx context.Context, q database.WebhookDeliveryQuery) ([]database.WebhookDeliveryRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("gorm store 未初始化")
+	}
+	limit := q.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	query := s.db.WithContext(ctx).Order("id DESC").Limit(limit)
+	if et := strings.TrimSpace(q.EventType); et != "" {
+		query = query.Where("event_type = ?", et)
+	}
+	if ep := strings.TrimSpace(q.Endpoint); ep != "" {
+		query = query.Where("endpoint = ?", ep)
+	}
+	if q.Failed {
+		query = query.Where("success = ?", false)
+	}
+	var models []webhookDeliveryModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+	out := make([]database.WebhookDeliveryRecord, 0, len(models))
+	for _, m := range models {
+		out = append(out, database.WebhookDeliveryRecord{
+			ID:         m.ID,
+			EventID:    m.EventID,
+			EventType:  m.EventType,
+			Symbol:     m.Symbol,
+			TradeID:    m.TradeID,
+			Endpoint:   m.Endpoint,
+			URL:        m.URL,
+			Attempt:    m.Attempt,
+			StatusCode: m.StatusCode,
+			Success:    m.Success,
+			Error:      m.Error,
+			DurationMs: m.DurationMs,
+			CreatedAt:  time.UnixMilli(m.CreatedAtUnix),
+		})
+	}
+	return out, nil
+}
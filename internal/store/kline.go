@@ -3,8 +3,11 @@ package store
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
+	"time"
 
+	"brale/internal/logger"
 	"brale/internal/market"
 )
 
@@ -19,11 +22,16 @@ type SnapshotExporter interface {
 
 type MemoryKlineStore struct {
 	shards []klineShard
+
+	validation CandleValidation
+	refetchSrc market.Source
 }
 
 type klineShard struct {
-	mu   sync.RWMutex
-	data map[string][]market.Candle
+	mu         sync.RWMutex
+	data       map[string][]market.Candle
+	stats      map[string]*KlineIngestStats
+	quarantine map[string]*quarantineState
 }
 
 const defaultShardCount = 32
@@ -40,7 +48,7 @@ func newMemoryKlineStore(shards int) *MemoryKlineStore {
 		shards: make([]klineShard, shards),
 	}
 	for i := range out.shards {
-		out.shards[i] = klineShard{data: make(map[string][]market.Candle)}
+		out.shards[i] = newKlineShard()
 	}
 	return out
 }
@@ -49,13 +57,21 @@ func (s *MemoryKlineStore) shardFor(key string) *klineShard {
 	if len(s.shards) == 0 {
 		s.shards = make([]klineShard, defaultShardCount)
 		for i := range s.shards {
-			s.shards[i] = klineShard{data: make(map[string][]market.Candle)}
+			s.shards[i] = newKlineShard()
 		}
 	}
 	idx := hashKey(key) % uint32(len(s.shards))
 	return &s.shards[idx]
 }
 
+func newKlineShard() klineShard {
+	return klineShard{
+		data:       make(map[string][]market.Candle),
+		stats:      make(map[string]*KlineIngestStats),
+		quarantine: make(map[string]*quarantineState),
+	}
+}
+
 func key(symbol, interval string) string { return symbol + "@" + interval }
 
 func (s *MemoryKlineStore) Put(ctx context.Context, symbol, interval string, ks []market.Candle, max int) error {
@@ -71,24 +87,59 @@ func (s *MemoryKlineStore) Put(ctx context.Context, symbol, interval string, ks
 	k := key(symbol, interval)
 	sh := s.shardFor(k)
 	sh.mu.Lock()
-	defer sh.mu.Unlock()
 	cur := sh.data[k]
+	st := sh.stats[k]
+	if st == nil {
+		st = &KlineIngestStats{}
+		sh.stats[k] = st
+	}
+	if !s.validation.Enabled {
+		for _, candle := range ks {
+			cur = mergeCandle(cur, candle, max, st)
+		}
+		sh.data[k] = trimCandles(cur, max)
+		sh.mu.Unlock()
+		return nil
+	}
+	q := sh.quarantine[k]
+	if q == nil {
+		q = newQuarantineState()
+		sh.quarantine[k] = q
+	}
+	q.max = max
+	now := time.Now()
+	batch := make(map[int64]market.Candle, len(ks))
+	var refetch []int64
 	for _, candle := range ks {
-		n := len(cur)
-		if n > 0 && cur[n-1].OpenTime == candle.OpenTime {
-
-			cur[n-1] = candle
+		if reason := s.validateCandle(cur, candle, batch, q, now); reason != "" {
+			st.Quarantined++
+			st.LastRejectAt = now
+			logger.Warnf("K线校验: 隔离 %s %s open_time=%d: %s", symbol, interval, candle.OpenTime, reason)
+			if s.quarantine(q, candle, reason, now) {
+				refetch = append(refetch, candle.OpenTime)
+			}
 			continue
 		}
-		cur = append(cur, candle)
-	}
-	if len(cur) > max {
-		cur = cur[len(cur)-max:]
+		batch[candle.OpenTime] = candle
+		cur = mergeCandle(cur, candle, max, st)
 	}
+	cur = trimCandles(cur, max)
 	sh.data[k] = cur
+	pruneQuarantine(q, cur)
+	sh.mu.Unlock()
+	if len(refetch) > 0 {
+		go s.refetch(symbol, interval, refetch)
+	}
 	return nil
 }
 
+func trimCandles(cur []market.Candle, max int) []market.Candle {
+	if len(cur) > max {
+		return cur[len(cur)-max:]
+	}
+	return cur
+}
+
 func (s *MemoryKlineStore) Set(ctx context.Context, symbol, interval string, ks []market.Candle) error {
 	if symbol == "" || interval == "" {
 		return errors.New("symbol/interval 不能为空")
@@ -99,7 +150,17 @@ func (s *MemoryKlineStore) Set(ctx context.Context, symbol, interval string, ks
 	defer sh.mu.Unlock()
 	dst := make([]market.Candle, len(ks))
 	copy(dst, ks)
-	sh.data[k] = dst
+	sort.SliceStable(dst, func(i, j int) bool { return dst[i].OpenTime < dst[j].OpenTime })
+	out := dst[:0]
+	for _, c := range dst {
+		// 重复 openTime 保留后出现的一根。
+		if n := len(out); n > 0 && out[n-1].OpenTime == c.OpenTime {
+			out[n-1] = c
+			continue
+		}
+		out = append(out, c)
+	}
+	sh.data[k] = out
 	return nil
 }
 
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"brale/internal/market"
+	"brale/internal/scheduler"
+)
+
+// KlineIngestStats 累计写入路径上的去重/乱序处理次数，用于排查 WS 重连与 REST 回补的重叠。
+type KlineIngestStats struct {
+	Appended           int64     `json:"appended"`
+	Upserted           int64     `json:"upserted"`
+	Backfilled         int64     `json:"backfilled"`
+	RejectedStale      int64     `json:"rejected_stale"`
+	RejectedRegression int64     `json:"rejected_regression"`
+	Quarantined        int64     `json:"quarantined"`
+	LastRejectAt       time.Time `json:"last_reject_at,omitempty"`
+}
+
+// KlineGap 为相邻两根 K 线之间缺失的区间（毫秒时间戳）。
+type KlineGap struct {
+	After   int64 `json:"after_open_time"`
+	Before  int64 `json:"before_open_time"`
+	Missing int   `json:"missing"`
+}
+
+// KlineConsistencyReport 为单个 symbol/interval 缓存的一致性检查结果。
+type KlineConsistencyReport struct {
+	Symbol     string              `json:"symbol"`
+	Interval   string              `json:"interval"`
+	Count      int                 `json:"count"`
+	FirstOpen  int64               `json:"first_open_time,omitempty"`
+	LastOpen   int64               `json:"last_open_time,omitempty"`
+	Duplicates int                 `json:"duplicates"`
+	OutOfOrder int                 `json:"out_of_order"`
+	Misaligned int                 `json:"misaligned"`
+	Gaps       []KlineGap          `json:"gaps,omitempty"`
+	Ingest     KlineIngestStats    `json:"ingest"`
+	Quarantine []QuarantinedCandle `json:"quarantine,omitempty"`
+	OK         bool                `json:"ok"`
+}
+
+// mergeCandle 按 openTime upsert：更新的 openTime 追加；已存在的 openTime 覆盖，
+// 但成交量/笔数回退（同一根 K 线的过期推送）视为倒退拒绝；缺失的旧 K 线按序插入。
+// 早于首根的 K 线在窗口未满 max 时前插（预热向前补历史），窗口已满时裁剪也会丢弃它，计为过期拒绝。
+func mergeCandle(cur []market.Candle, c market.Candle, max int, st *KlineIngestStats) []market.Candle {
+	n := len(cur)
+	if n == 0 || c.OpenTime > cur[n-1].OpenTime {
+		st.Appended++
+		return append(cur, c)
+	}
+	idx := sort.Search(n, func(i int) bool { return cur[i].OpenTime >= c.OpenTime })
+	if idx < n && cur[idx].OpenTime == c.OpenTime {
+		if isRegression(cur[idx], c) {
+			st.RejectedRegression++
+			st.LastRejectAt = time.Now()
+			return cur
+		}
+		cur[idx] = c
+		st.Upserted++
+		return cur
+	}
+	if idx == 0 && n >= max {
+		st.RejectedStale++
+		st.LastRejectAt = time.Now()
+		return cur
+	}
+	cur = append(cur, market.Candle{})
+	copy(cur[idx+1:], cur[idx:])
+	cur[idx] = c
+	st.Backfilled++
+	return cur
+}
+
+// isRegression 判断同一 openTime 的新数据是否比已有数据更旧：累计量只会单调增加。
+func isRegression(prev, next market.Candle) bool {
+	if next.Trades > 0 && prev.Trades > next.Trades {
+		return true
+	}
+	return next.Volume > 0 && prev.Volume > next.Volume
+}
+
+// CheckConsistency 检查缓存中的 K 线；symbol/interval 为空表示不过滤。
+func (s *MemoryKlineStore) CheckConsistency(ctx context.Context, symbol, interval string) []KlineConsistencyReport {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	interval = strings.ToLower(strings.TrimSpace(interval))
+	var out []KlineConsistencyReport
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		for k, cur := range sh.data {
+			sym, iv, _ := strings.Cut(k, "@")
+			if (symbol != "" && !strings.EqualFold(sym, symbol)) || (interval != "" && !strings.EqualFold(iv, interval)) {
+				continue
+			}
+			rep := inspectCandles(sym, iv, cur)
+			if st := sh.stats[k]; st != nil {
+				rep.Ingest = *st
+			}
+			if q := sh.quarantine[k]; q != nil && len(q.bars) > 0 {
+				rep.Quarantine = append([]QuarantinedCandle(nil), q.bars...)
+			}
+			out = append(out, rep)
+		}
+		sh.mu.RUnlock()
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Symbol != out[j].Symbol {
+			return out[i].Symbol < out[j].Symbol
+		}
+		return out[i].Interval < out[j].Interval
+	})
+	return out
+}
+
+func inspectCandles(symbol, interval string, cur []market.Candle) KlineConsistencyReport {
+	rep := KlineConsistencyReport{Symbol: symbol, Interval: interval, Count: len(cur)}
+	if len(cur) == 0 {
+		rep.OK = true
+		return rep
+	}
+	rep.FirstOpen = cur[0].OpenTime
+	rep.LastOpen = cur[len(cur)-1].OpenTime
+	var step int64
+	if dur, ok := scheduler.ParseIntervalDuration(interval); ok {
+		step = dur.Milliseconds()
+	}
+	for i, c := range cur {
+		if step > 0 && c.OpenTime%step != 0 && step <= int64(24*time.Hour/time.Millisecond) {
+			rep.Misaligned++
+		}
+		if i == 0 {
+			continue
+		}
+		diff := c.OpenTime - cur[i-1].OpenTime
+		switch {
+		case diff == 0:
+			rep.Duplicates++
+		case diff < 0:
+			rep.OutOfOrder++
+		case step > 0 && diff > step:
+			rep.Gaps = append(rep.Gaps, KlineGap{After: cur[i-1].OpenTime, Before: c.OpenTime, Missing: int(diff/step) - 1})
+		}
+	}
+	rep.OK = rep.Duplicates == 0 && rep.OutOfOrder == 0 && rep.Misaligned == 0 && len(rep.Gaps) == 0
+	return rep
+}
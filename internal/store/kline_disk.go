@@ -0,0 +1,282 @@
+package store
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/market"
+)
+
+const klineSnapshotExt = ".json.gz"
+
+// KlineDiskCacheConfig 为 K 线快照落盘配置；MaxBars 为每个 symbol/interval 保存的根数，MaxBytes<=0 表示不限制目录大小。
+type KlineDiskCacheConfig struct {
+	Dir      string
+	TTL      time.Duration
+	MaxBars  int
+	MaxBytes int64
+}
+
+// KlineDiskCache 把 MemoryKlineStore 按 symbol/interval 写成 gzip JSON 快照，启动时加载以缩短冷启动回补。
+type KlineDiskCache struct {
+	cfg   KlineDiskCacheConfig
+	store *MemoryKlineStore
+	mu    sync.Mutex
+}
+
+type klineSnapshot struct {
+	Symbol   string          `json:"symbol"`
+	Interval string          `json:"interval"`
+	SavedAt  time.Time       `json:"saved_at"`
+	Candles  []market.Candle `json:"candles"`
+}
+
+func NewKlineDiskCache(cfg KlineDiskCacheConfig, s *MemoryKlineStore) (*KlineDiskCache, error) {
+	if s == nil {
+		return nil, fmt.Errorf("kline store 未初始化")
+	}
+	cfg.Dir = strings.TrimSpace(cfg.Dir)
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("K 线快照目录不能为空")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建 K 线快照目录失败: %w", err)
+	}
+	return &KlineDiskCache{cfg: cfg, store: s}, nil
+}
+
+// Load 读取目录下的快照写入缓存；超过 TTL、无法解析或未通过一致性检查（重复、乱序、缺口、未对齐、价格异常）的快照会被删除。
+// 返回加载的 stream 数与 K 线根数。
+func (c *KlineDiskCache) Load(ctx context.Context) (streams, bars int, err error) {
+	if c == nil {
+		return 0, 0, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries, err := os.ReadDir(c.cfg.Dir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("读取 K 线快照目录失败: %w", err)
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return streams, bars, ctx.Err()
+		}
+		if e.IsDir() || !strings.HasSuffix(e.Name(), klineSnapshotExt) {
+			continue
+		}
+		path := filepath.Join(c.cfg.Dir, e.Name())
+		snap, err := readKlineSnapshot(path)
+		if err != nil {
+			logger.Warnf("[K线快照] 丢弃无法解析的快照 %s: %v", e.Name(), err)
+			_ = os.Remove(path)
+			continue
+		}
+		if c.cfg.TTL > 0 && now.Sub(snap.SavedAt) > c.cfg.TTL {
+			logger.Infof("[K线快照] 丢弃过期快照 %s@%s（保存于 %s）", snap.Symbol, snap.Interval, snap.SavedAt.Format(time.RFC3339))
+			_ = os.Remove(path)
+			continue
+		}
+		if snap.Symbol == "" || snap.Interval == "" || len(snap.Candles) == 0 {
+			continue
+		}
+		candles := snap.Candles
+		if c.cfg.MaxBars > 0 && len(candles) > c.cfg.MaxBars {
+			candles = candles[len(candles)-c.cfg.MaxBars:]
+		}
+		if err := checkSnapshotCandles(snap.Symbol, snap.Interval, candles, snap.SavedAt); err != nil {
+			logger.Warnf("[K线快照] 丢弃未通过一致性检查的快照 %s@%s: %v", snap.Symbol, snap.Interval, err)
+			_ = os.Remove(path)
+			continue
+		}
+		if err := c.store.Set(ctx, snap.Symbol, snap.Interval, candles); err != nil {
+			logger.Warnf("[K线快照] 加载 %s@%s 失败: %v", snap.Symbol, snap.Interval, err)
+			continue
+		}
+		streams++
+		bars += len(candles)
+	}
+	return streams, bars, nil
+}
+
+// checkSnapshotCandles 对快照整段执行与写入路径相同的单根检查（以保存时刻判断是否已收盘）和一致性检查；Set 直接替换缓存，不经过校验。
+func checkSnapshotCandles(symbol, interval string, candles []market.Candle, now time.Time) error {
+	for _, c := range candles {
+		if reason := candleShapeError(c, now); reason != "" {
+			return fmt.Errorf("open_time=%d: %s", c.OpenTime, reason)
+		}
+	}
+	rep := inspectCandles(symbol, interval, candles)
+	if !rep.OK {
+		return fmt.Errorf("duplicates=%d out_of_order=%d misaligned=%d gaps=%d", rep.Duplicates, rep.OutOfOrder, rep.Misaligned, len(rep.Gaps))
+	}
+	return nil
+}
+
+// Save 把当前缓存全部写回磁盘（临时文件 + rename），随后按 MaxBytes 清理最旧的快照。
+func (c *KlineDiskCache) Save(ctx context.Context) (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	saved := 0
+	var firstErr error
+	for _, snap := range c.snapshots(now) {
+		if ctx.Err() != nil {
+			return saved, ctx.Err()
+		}
+		if err := writeKlineSnapshot(c.snapshotPath(snap.Symbol, snap.Interval), snap); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s@%s: %w", snap.Symbol, snap.Interval, err)
+			}
+			continue
+		}
+		saved++
+	}
+	c.enforceSize()
+	return saved, firstErr
+}
+
+// Start 每 every 写回一次快照，直到 ctx 结束；关闭时的最终写回由调用方调用 Save。
+func (c *KlineDiskCache) Start(ctx context.Context, every time.Duration) {
+	if c == nil || every <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if n, err := c.Save(ctx); err != nil {
+				logger.Warnf("[K线快照] 定期写回失败（已写 %d 个）: %v", n, err)
+			}
+		}
+	}()
+}
+
+func (c *KlineDiskCache) snapshots(now time.Time) []klineSnapshot {
+	var out []klineSnapshot
+	s := c.store
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		for k, cur := range sh.data {
+			if len(cur) == 0 {
+				continue
+			}
+			sym, iv, _ := strings.Cut(k, "@")
+			n := len(cur)
+			if c.cfg.MaxBars > 0 && n > c.cfg.MaxBars {
+				n = c.cfg.MaxBars
+			}
+			candles := make([]market.Candle, n)
+			copy(candles, cur[len(cur)-n:])
+			out = append(out, klineSnapshot{Symbol: sym, Interval: iv, SavedAt: now, Candles: candles})
+		}
+		sh.mu.RUnlock()
+	}
+	return out
+}
+
+func (c *KlineDiskCache) snapshotPath(symbol, interval string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(symbol + "@" + interval)
+	return filepath.Join(c.cfg.Dir, name+klineSnapshotExt)
+}
+
+// enforceSize 在目录总大小超过 MaxBytes 时按修改时间从旧到新删除快照。
+func (c *KlineDiskCache) enforceSize() {
+	if c.cfg.MaxBytes <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(c.cfg.Dir)
+	if err != nil {
+		return
+	}
+	type fileInfo struct {
+		path string
+		size int64
+		mod  time.Time
+	}
+	var files []fileInfo
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), klineSnapshotExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, fileInfo{path: filepath.Join(c.cfg.Dir, e.Name()), size: info.Size(), mod: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= c.cfg.MaxBytes {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod.Before(files[j].mod) })
+	removed := 0
+	for _, f := range files {
+		if total <= c.cfg.MaxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+			removed++
+		}
+	}
+	logger.Warnf("[K线快照] 目录超过 %d MB，已删除 %d 个最旧的快照", c.cfg.MaxBytes>>20, removed)
+}
+
+func readKlineSnapshot(path string) (klineSnapshot, error) {
+	var snap klineSnapshot
+	f, err := os.Open(path)
+	if err != nil {
+		return snap, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return snap, err
+	}
+	defer zr.Close()
+	if err := json.NewDecoder(zr).Decode(&snap); err != nil {
+		return snap, err
+	}
+	return snap, nil
+}
+
+func writeKlineSnapshot(path string, snap klineSnapshot) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	err = json.NewEncoder(zw).Encode(snap)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
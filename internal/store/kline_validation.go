@@ -0,0 +1,222 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"brale/internal/logger"
+	"brale/internal/market"
+	"brale/internal/scheduler"
+)
+
+const (
+	maxQuarantinedPerStream = 50
+	minSigmaSamples         = 20
+	refetchCooldown         = time.Minute
+	refetchTimeout          = 10 * time.Second
+	maxRefetchBars          = 1500
+)
+
+// CandleValidation 为写入路径上的 K 线完整性校验配置；MaxSigma<=0 时不检查极端波动。
+type CandleValidation struct {
+	Enabled     bool
+	MaxSigma    float64
+	SigmaWindow int
+	Refetch     bool
+}
+
+// QuarantinedCandle 为未写入缓存的异常 K 线及原因；Refetched 表示已尝试从 REST 重新拉取。
+type QuarantinedCandle struct {
+	Candle    market.Candle `json:"candle"`
+	Reason    string        `json:"reason"`
+	At        time.Time     `json:"at"`
+	Refetched bool          `json:"refetched"`
+}
+
+// quarantineState 为单个 symbol/interval 的隔离记录；confirmed 为 REST 确认过的 open_time，跳过极端波动检查。
+type quarantineState struct {
+	bars      []QuarantinedCandle
+	confirmed map[int64]struct{}
+	attempted map[int64]time.Time
+	max       int
+}
+
+func newQuarantineState() *quarantineState {
+	return &quarantineState{confirmed: make(map[int64]struct{}), attempted: make(map[int64]time.Time)}
+}
+
+// SetValidation 启用写入前校验；src 非空且 Refetch 开启时隔离的 K 线会异步从 REST 重新拉取。需在写入数据前调用。
+func (s *MemoryKlineStore) SetValidation(v CandleValidation, src market.Source) {
+	s.validation = v
+	s.refetchSrc = nil
+	if v.Refetch {
+		s.refetchSrc = src
+	}
+	if v.Enabled {
+		logger.Infof("K线校验已启用 max_sigma=%.1f window=%d refetch=%v", v.MaxSigma, v.SigmaWindow, s.refetchSrc != nil)
+	}
+}
+
+// validateCandle 返回 c 不可写入缓存的原因；batch 为同一次写入中已出现的 K 线，用于识别冲突的重复 open_time。
+func (s *MemoryKlineStore) validateCandle(cur []market.Candle, c market.Candle, batch map[int64]market.Candle, q *quarantineState, now time.Time) string {
+	if reason := candleShapeError(c, now); reason != "" {
+		return reason
+	}
+	if prev, ok := batch[c.OpenTime]; ok && prev != c {
+		return "同批数据重复 open_time"
+	}
+	if s.validation.MaxSigma <= 0 {
+		return ""
+	}
+	if _, ok := q.confirmed[c.OpenTime]; ok {
+		return ""
+	}
+	ret, sigma, ok := barReturnSigma(cur, c, s.validation.SigmaWindow)
+	if ok && sigma > 0 && math.Abs(ret) > s.validation.MaxSigma*sigma {
+		return fmt.Sprintf("单根涨跌 %.2f%% 超过 %.1fσ（σ=%.3f%%）", ret*100, s.validation.MaxSigma, sigma*100)
+	}
+	return ""
+}
+
+// candleShapeError 检查单根 K 线自身的价格与成交量是否合法，不依赖上下文。
+func candleShapeError(c market.Candle, now time.Time) string {
+	if c.Open <= 0 || c.High <= 0 || c.Low <= 0 || c.Close <= 0 {
+		return "价格非正"
+	}
+	if c.Low > c.High {
+		return fmt.Sprintf("low %.8g > high %.8g", c.Low, c.High)
+	}
+	if c.Open > c.High || c.Open < c.Low || c.Close > c.High || c.Close < c.Low {
+		return "开收盘价超出高低区间"
+	}
+	if c.Volume <= 0 && c.CloseTime > 0 && c.CloseTime < now.UnixMilli() {
+		return "已收盘 K 线成交量为 0"
+	}
+	return ""
+}
+
+// barReturnSigma 返回 c 相对前一根收盘的对数收益率，以及其之前 window 根 K 线收益率的标准差；样本不足时 ok=false。
+func barReturnSigma(cur []market.Candle, c market.Candle, window int) (ret, sigma float64, ok bool) {
+	end := len(cur)
+	for end > 0 && cur[end-1].OpenTime >= c.OpenTime {
+		end--
+	}
+	if end == 0 || cur[end-1].Close <= 0 {
+		return 0, 0, false
+	}
+	start := max(end-window-1, 0)
+	var sum, sumSq float64
+	n := 0
+	for i := start + 1; i < end; i++ {
+		prev, next := cur[i-1].Close, cur[i].Close
+		if prev <= 0 || next <= 0 {
+			continue
+		}
+		r := math.Log(next / prev)
+		sum += r
+		sumSq += r * r
+		n++
+	}
+	if n < minSigmaSamples {
+		return 0, 0, false
+	}
+	mean := sum / float64(n)
+	variance := sumSq/float64(n) - mean*mean
+	if variance <= 0 {
+		return 0, 0, false
+	}
+	return math.Log(c.Close / cur[end-1].Close), math.Sqrt(variance), true
+}
+
+// quarantine 记录异常 K 线并返回是否需要从 REST 重新拉取（同一 open_time 冷却期内只拉一次）。
+func (s *MemoryKlineStore) quarantine(q *quarantineState, c market.Candle, reason string, now time.Time) bool {
+	q.bars = append(q.bars, QuarantinedCandle{Candle: c, Reason: reason, At: now})
+	if len(q.bars) > maxQuarantinedPerStream {
+		q.bars = q.bars[len(q.bars)-maxQuarantinedPerStream:]
+	}
+	if s.refetchSrc == nil {
+		return false
+	}
+	if last, ok := q.attempted[c.OpenTime]; ok && now.Sub(last) < refetchCooldown {
+		return false
+	}
+	q.attempted[c.OpenTime] = now
+	q.bars[len(q.bars)-1].Refetched = true
+	return true
+}
+
+// pruneQuarantine 丢弃早于缓存窗口的确认/拉取记录，避免长期运行时无限增长。
+func pruneQuarantine(q *quarantineState, cur []market.Candle) {
+	if len(cur) == 0 {
+		return
+	}
+	oldest := cur[0].OpenTime
+	for ts := range q.confirmed {
+		if ts < oldest {
+			delete(q.confirmed, ts)
+		}
+	}
+	for ts := range q.attempted {
+		if ts < oldest {
+			delete(q.attempted, ts)
+		}
+	}
+}
+
+// refetch 从 REST 拉取覆盖隔离 K 线的最近数据，只把对应 open_time 的 K 线标记为已确认后重新写入（仍做结构校验）。
+func (s *MemoryKlineStore) refetch(symbol, interval string, openTimes []int64) {
+	src := s.refetchSrc
+	dur, ok := scheduler.ParseIntervalDuration(interval)
+	if src == nil || !ok || len(openTimes) == 0 {
+		return
+	}
+	oldest := openTimes[0]
+	for _, ts := range openTimes[1:] {
+		oldest = min(oldest, ts)
+	}
+	limit := int((time.Now().UnixMilli()-oldest)/dur.Milliseconds()) + 2
+	if limit > maxRefetchBars {
+		logger.Warnf("K线校验: %s %s 隔离的 K 线过旧，跳过重新拉取", symbol, interval)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), refetchTimeout)
+	defer cancel()
+	fetched, err := src.FetchHistory(ctx, symbol, interval, limit)
+	if err != nil {
+		logger.Warnf("K线校验: %s %s 重新拉取失败: %v", symbol, interval, err)
+		return
+	}
+	wanted := make(map[int64]struct{}, len(openTimes))
+	for _, ts := range openTimes {
+		wanted[ts] = struct{}{}
+	}
+	var bars []market.Candle
+	for _, c := range fetched {
+		if _, ok := wanted[c.OpenTime]; ok {
+			bars = append(bars, c)
+		}
+	}
+	if len(bars) == 0 {
+		return
+	}
+	k := key(symbol, interval)
+	sh := s.shardFor(k)
+	sh.mu.Lock()
+	q := sh.quarantine[k]
+	if q == nil {
+		q = newQuarantineState()
+		sh.quarantine[k] = q
+	}
+	for _, c := range bars {
+		q.confirmed[c.OpenTime] = struct{}{}
+	}
+	maxBars := max(q.max, len(sh.data[k]))
+	sh.mu.Unlock()
+	if err := s.Put(ctx, symbol, interval, bars, maxBars); err != nil {
+		logger.Warnf("K线校验: %s %s 写回重新拉取的 K 线失败: %v", symbol, interval, err)
+		return
+	}
+	logger.Infof("K线校验: %s %s 已从 REST 重新拉取 %d 根隔离 K 线", symbol, interval, len(bars))
+}
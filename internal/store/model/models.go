@@ -50,6 +50,9 @@ type LiveOrderModel struct {
 	UnrealizedUSD     float64         `gorm:"column:unrealized_pnl_usd"`
 	RealizedRatio     float64         `gorm:"column:realized_pnl_ratio"`
 	RealizedUSD       float64         `gorm:"column:realized_pnl_usd"`
+	FeeUSD            float64         `gorm:"column:fee_usd"`
+	FundingUSD        float64         `gorm:"column:funding_usd"`
+	GrossRealizedUSD  float64         `gorm:"column:gross_realized_pnl_usd"`
 	IsSimulated       int             `gorm:"column:is_simulated"`
 	Status            LiveOrderStatus `gorm:"column:status"`
 	StartTimestamp    int64           `gorm:"column:start_timestamp"`
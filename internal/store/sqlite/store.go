@@ -56,8 +56,8 @@ func newSqliteStore(db *gorm.DB) (*SqliteStore, error) {
 	if err := db.AutoMigrate(models...); err != nil {
 		return nil, err
 	}
-	if sqlDB, err := db.DB(); err == nil {
-
+	// 仅 SQLite 需要限制连接数；共享的 Postgres 连接池由创建方配置。
+	if sqlDB, err := db.DB(); err == nil && db.Dialector.Name() == "sqlite" {
 		sqlDB.SetMaxOpenConns(2)
 		sqlDB.SetMaxIdleConns(2)
 	}
@@ -2,6 +2,7 @@ package exit
 
 import (
 	"context"
+	"time"
 
 	"brale/internal/decision"
 	"brale/internal/gateway/database"
@@ -25,6 +26,12 @@ type PlanHandler interface {
 	OnAdjust(ctx context.Context, inst PlanInstance, params map[string]any) (*PlanEvent, error)
 }
 
+// TimeHandler is optionally implemented by handlers whose exits depend on wall-clock time
+// rather than price. The scheduler calls OnTime from a periodic sweep over open positions.
+type TimeHandler interface {
+	OnTime(ctx context.Context, inst PlanInstance, now time.Time) (*PlanEvent, error)
+}
+
 // StrategyStore persists exit strategy instances.
 // Instances are created on entry fill, updated on trigger, finalized on exit fill.
 type StrategyStore interface {
@@ -78,4 +85,5 @@ const (
 	PlanEventTypeFinalStopLoss   = "final_stop_loss"   // Close position at stop
 	PlanEventTypeFinalTakeProfit = "final_take_profit" // Close position at TP
 	PlanEventTypeAdjust          = "plan_adjust"       // Manual param change
+	PlanEventTypeTimeExit        = "time_exit"         // Close position on holding deadline / session end
 )
@@ -165,6 +165,23 @@ func (h *comboHandler) OnAdjust(ctx context.Context, inst exit.PlanInstance, par
 	return wrapChildEvent(evt, meta.alias, inst.Record.PlanComponent), nil
 }
 
+// OnTime 转发给实现了 exit.TimeHandler 的子 handler（如 time_exit）。
+func (h *comboHandler) OnTime(ctx context.Context, inst exit.PlanInstance, now time.Time) (*exit.PlanEvent, error) {
+	meta, childInst, handler, err := h.childInstance(inst)
+	if err != nil {
+		return nil, err
+	}
+	timed, ok := handler.(exit.TimeHandler)
+	if !ok {
+		return nil, nil
+	}
+	evt, err := timed.OnTime(ctx, childInst, now)
+	if err != nil || evt == nil {
+		return evt, err
+	}
+	return wrapChildEvent(evt, meta.alias, inst.Record.PlanComponent), nil
+}
+
 type childSpec struct {
 	Component string
 	Handler   string
@@ -27,6 +27,7 @@ func RegisterCoreHandlers(reg *exit.HandlerRegistry) {
 	reg.Register(newTierLevelsHandler("tier_stop_loss", "stop_loss"))
 	reg.Register(&trailingStopHandler{})
 	reg.Register(&atrTrailingHandler{base: trailingStopHandler{}})
+	reg.Register(&timeExitHandler{})
 	reg.Register(newComboHandler(reg))
 }
 
@@ -178,11 +179,16 @@ func (h *tierLevelsHandler) OnPrice(ctx context.Context, inst exit.PlanInstance,
 	if !targetHit {
 		return nil, nil
 	}
+	// 人工对账后部分成交的段位只平剩余部分。
+	ratio := state.Ratio
+	if state.ExecutedRatio > 0 && state.RemainingRatio > 0 && state.RemainingRatio < ratio {
+		ratio = state.RemainingRatio
+	}
 	details := map[string]any{
 		"symbol":       strings.ToUpper(strings.TrimSpace(state.Symbol)),
 		"side":         side,
 		"target_price": state.TargetPrice,
-		"ratio":        state.Ratio,
+		"ratio":        ratio,
 		"price":        price,
 		"component":    inst.Record.PlanComponent,
 		"mode":         mode,
@@ -209,6 +215,11 @@ func (h *tierLevelsHandler) OnAdjust(ctx context.Context, inst exit.PlanInstance
 		return nil, fmt.Errorf("%s: 组件 %s 已完成，无法调整", h.id, component)
 	}
 	changes := make(map[string]any)
+	if entry, ok := number(params["entry_price"]); ok && entry > 0 {
+		// 加仓后均价变化，同步到组件以便保本锁定等逻辑使用新均价。
+		state.EntryPrice = entry
+		changes["entry_price"] = entry
+	}
 	if price, ok := number(params["target_price"]); ok && price > 0 {
 		state.TargetPrice = price
 		changes["target_price"] = price
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/database"
+	"brale/internal/strategy/exit"
+)
+
+const (
+	timeExitHandlerID  = "time_exit"
+	timeExitComponent  = "deadline"
+	timeExitMode       = "time"
+	maxHoldHoursLimit  = 24 * 30
+	sessionCloseLayout = "15:04"
+)
+
+// timeExitHandler 在持仓超过 max_hold_hours 或到达每日 session_close_utc 时整仓平出；
+// 截止时间在入场成交时确定，由 PlanScheduler 的定时巡检通过 OnTime 触发，价格变化不影响它。
+type timeExitHandler struct{}
+
+func (h *timeExitHandler) ID() string { return timeExitHandlerID }
+
+func (h *timeExitHandler) Validate(params map[string]any) error {
+	hours, hasHours := number(params["max_hold_hours"])
+	session := strings.TrimSpace(asString(params["session_close_utc"]))
+	if !hasHours && session == "" {
+		return fmt.Errorf("time_exit: 需提供 max_hold_hours 或 session_close_utc")
+	}
+	if hasHours && (hours <= 0 || hours > maxHoldHoursLimit) {
+		return fmt.Errorf("time_exit: max_hold_hours 需位于 (0,%d]", maxHoldHoursLimit)
+	}
+	if session != "" {
+		if _, err := time.Parse(sessionCloseLayout, session); err != nil {
+			return fmt.Errorf("time_exit: session_close_utc 需为 HH:MM 格式: %s", session)
+		}
+	}
+	return nil
+}
+
+func (h *timeExitHandler) Instantiate(ctx context.Context, args exit.InstantiateArgs) ([]exit.PlanInstance, error) {
+	if err := h.Validate(args.PlanSpec); err != nil {
+		return nil, err
+	}
+	side := normalizeSide(args.Side)
+	if side == "" {
+		return nil, fmt.Errorf("time_exit: side 必填")
+	}
+	symbol := resolveSymbol(args)
+	now := time.Now()
+	deadline, reason := timeExitDeadline(args.PlanSpec, now)
+	rootState := exit.TierPlanState{
+		Symbol:         symbol,
+		Side:           side,
+		EntryPrice:     args.EntryPrice,
+		RemainingRatio: 1,
+		LastUpdatedAt:  now.Unix(),
+	}
+	rootPlan := cloneMap(args.PlanSpec)
+	rootPlan["mode"] = timeExitMode
+	root := exit.PlanInstance{
+		Record: database.StrategyInstanceRecord{
+			TradeID:         args.TradeID,
+			PlanID:          args.PlanID,
+			PlanComponent:   "",
+			PlanVersion:     normalizePlanVersion(args.PlanVersion),
+			ParamsJSON:      database.EncodeParams(rootPlan),
+			StateJSON:       exit.EncodeTierPlanState(rootState),
+			Status:          database.StrategyStatusWaiting,
+			DecisionTraceID: strings.TrimSpace(args.DecisionTrace),
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		},
+		Plan:  rootPlan,
+		State: map[string]any{},
+	}
+	compPlan := map[string]any{
+		"deadline": deadline.Unix(),
+		"reason":   reason,
+	}
+	state := exit.TierComponentState{
+		Name:           timeExitComponent,
+		Ratio:          1,
+		Status:         "waiting",
+		Symbol:         symbol,
+		Side:           side,
+		EntryPrice:     args.EntryPrice,
+		RemainingRatio: 1,
+		Mode:           timeExitMode,
+	}
+	comp := exit.PlanInstance{
+		Record: database.StrategyInstanceRecord{
+			TradeID:         args.TradeID,
+			PlanID:          args.PlanID,
+			PlanComponent:   timeExitComponent,
+			PlanVersion:     normalizePlanVersion(args.PlanVersion),
+			ParamsJSON:      database.EncodeParams(compPlan),
+			StateJSON:       exit.EncodeTierComponentState(state),
+			Status:          database.StrategyStatusWaiting,
+			DecisionTraceID: strings.TrimSpace(args.DecisionTrace),
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		},
+		Plan:  compPlan,
+		State: map[string]any{},
+	}
+	return []exit.PlanInstance{root, comp}, nil
+}
+
+// OnPrice 不做任何判断，时间退出只由 OnTime 触发。
+func (h *timeExitHandler) OnPrice(ctx context.Context, inst exit.PlanInstance, price float64) (*exit.PlanEvent, error) {
+	return nil, nil
+}
+
+func (h *timeExitHandler) OnAdjust(ctx context.Context, inst exit.PlanInstance, params map[string]any) (*exit.PlanEvent, error) {
+	return nil, fmt.Errorf("time_exit: 不支持调整，请重新下发退出计划")
+}
+
+func (h *timeExitHandler) OnTime(ctx context.Context, inst exit.PlanInstance, now time.Time) (*exit.PlanEvent, error) {
+	if strings.TrimSpace(inst.Record.PlanComponent) == "" {
+		return nil, nil
+	}
+	deadline, ok := number(inst.Plan["deadline"])
+	if !ok || deadline <= 0 || now.Unix() < int64(deadline) {
+		return nil, nil
+	}
+	state, err := exit.DecodeTierComponentState(inst.Record.StateJSON)
+	if err != nil {
+		return nil, fmt.Errorf("time_exit: 解析组件状态失败: %w", err)
+	}
+	if strings.EqualFold(state.Status, "done") || strings.EqualFold(state.Status, "triggered") || strings.EqualFold(state.Status, "pending") {
+		return nil, nil
+	}
+	reason := strings.TrimSpace(asString(inst.Plan["reason"]))
+	details := map[string]any{
+		"symbol":    strings.ToUpper(strings.TrimSpace(state.Symbol)),
+		"side":      normalizeSide(state.Side),
+		"ratio":     1.0,
+		"deadline":  time.Unix(int64(deadline), 0).UTC().Format(time.RFC3339),
+		"reason":    reason,
+		"component": inst.Record.PlanComponent,
+		"mode":      timeExitMode,
+	}
+	return &exit.PlanEvent{
+		TradeID:       inst.Record.TradeID,
+		PlanID:        inst.Record.PlanID,
+		PlanComponent: inst.Record.PlanComponent,
+		Type:          exit.PlanEventTypeTimeExit,
+		Details:       details,
+	}, nil
+}
+
+// timeExitDeadline 取 entry+max_hold_hours 与入场后下一次 session_close_utc 中较早者。
+func timeExitDeadline(params map[string]any, entry time.Time) (time.Time, string) {
+	var deadline time.Time
+	reason := ""
+	if hours, ok := number(params["max_hold_hours"]); ok && hours > 0 {
+		deadline = entry.Add(time.Duration(hours * float64(time.Hour)))
+		reason = "max_hold"
+	}
+	if session := strings.TrimSpace(asString(params["session_close_utc"])); session != "" {
+		if hm, err := time.Parse(sessionCloseLayout, session); err == nil {
+			utc := entry.UTC()
+			next := time.Date(utc.Year(), utc.Month(), utc.Day(), hm.Hour(), hm.Minute(), 0, 0, time.UTC)
+			if !next.After(utc) {
+				next = next.AddDate(0, 0, 1)
+			}
+			if deadline.IsZero() || next.Before(deadline) {
+				deadline = next
+				reason = "session_flat"
+			}
+		}
+	}
+	return deadline, reason
+}
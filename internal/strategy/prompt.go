@@ -1,25 +1,81 @@
 package strategy
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"brale/internal/logger"
 )
 
+// DefaultPromptWatchInterval 为提示词目录 mtime 轮询的默认周期。
+const DefaultPromptWatchInterval = 5 * time.Second
+
+// PromptFile 为单个提示词文件的内容与版本哈希。
+type PromptFile struct {
+	Name      string    `json:"name"`
+	Content   string    `json:"content,omitempty"`
+	Version   string    `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type promptStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// Manager 缓存提示词目录下的 *.txt 模板；Watch 按 mtime 轮询热重载，Save 写回文件前保留 .bak 备份。
 type Manager struct {
-	dir   string
-	cache map[string]string
+	dir string
+
+	mu        sync.RWMutex
+	cache     map[string]string
+	versions  map[string]string
+	stats     map[string]promptStat
+	listeners []func(changed []string)
+
+	saveMu sync.Mutex
 }
 
-func NewManager(dir string) *Manager { return &Manager{dir: dir, cache: make(map[string]string)} }
+func NewManager(dir string) *Manager {
+	return &Manager{
+		dir:      dir,
+		cache:    make(map[string]string),
+		versions: make(map[string]string),
+		stats:    make(map[string]promptStat),
+	}
+}
 
 func (m *Manager) Load() error {
+	_, err := m.load()
+	return err
+}
+
+func (m *Manager) Reload() error {
+	changed, err := m.load()
+	if err != nil {
+		return err
+	}
+	m.notify(changed)
+	return nil
+}
+
+// load 重新读取整个目录，返回内容发生变化（含新增/删除）的模板名。
+func (m *Manager) load() ([]string, error) {
 	entries, err := os.ReadDir(m.dir)
 	if err != nil {
-		return fmt.Errorf("读取提示词目录失败: %w", err)
+		return nil, fmt.Errorf("读取提示词目录失败: %w", err)
 	}
-	m.cache = make(map[string]string)
+	cache := make(map[string]string)
+	versions := make(map[string]string)
+	stats := make(map[string]promptStat)
 	for _, e := range entries {
 		if e.IsDir() {
 			continue
@@ -30,19 +86,53 @@ func (m *Manager) Load() error {
 		path := filepath.Join(m.dir, e.Name())
 		b, err := os.ReadFile(path)
 		if err != nil {
-			return fmt.Errorf("读取模板失败 %s: %w", path, err)
+			return nil, fmt.Errorf("读取模板失败 %s: %w", path, err)
 		}
 		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
-		m.cache[name] = string(b)
+		cache[name] = string(b)
+		versions[name] = PromptVersion(string(b))
+		if info, err := e.Info(); err == nil {
+			stats[name] = promptStat{modTime: info.ModTime(), size: info.Size()}
+		}
 	}
-	return nil
+	m.mu.Lock()
+	var changed []string
+	for name, ver := range versions {
+		if m.versions[name] != ver {
+			changed = append(changed, name)
+		}
+	}
+	for name := range m.versions {
+		if _, ok := versions[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	m.cache = cache
+	m.versions = versions
+	m.stats = stats
+	m.mu.Unlock()
+	sort.Strings(changed)
+	return changed, nil
 }
 
-func (m *Manager) Reload() error { return m.Load() }
+func (m *Manager) Get(name string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.cache[name]
+	return v, ok
+}
 
-func (m *Manager) Get(name string) (string, bool) { v, ok := m.cache[name]; return v, ok }
+// Version 返回模板当前内容的版本哈希。
+func (m *Manager) Version(name string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.versions[name]
+	return v, ok
+}
 
 func (m *Manager) List() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	out := make(map[string]string, len(m.cache))
 	for k, v := range m.cache {
 		out[k] = v
@@ -50,6 +140,160 @@ func (m *Manager) List() map[string]string {
 	return out
 }
 
+// Files 按名称排序返回全部模板的版本信息（不含正文）。
+func (m *Manager) Files() []PromptFile {
+	m.mu.RLock()
+	out := make([]PromptFile, 0, len(m.versions))
+	for name, ver := range m.versions {
+		out = append(out, PromptFile{Name: name, Version: ver, UpdatedAt: m.stats[name].modTime})
+	}
+	m.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// File 返回单个模板的内容与版本。
+func (m *Manager) File(name string) (PromptFile, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	content, ok := m.cache[name]
+	if !ok {
+		return PromptFile{}, false
+	}
+	return PromptFile{Name: name, Content: content, Version: m.versions[name], UpdatedAt: m.stats[name].modTime}, true
+}
+
+// Subscribe 注册模板变化回调（Watch 检测到变化、Save 或 Reload 后触发），参数为变化的模板名。
+func (m *Manager) Subscribe(fn func(changed []string)) {
+	if fn == nil {
+		return
+	}
+	m.mu.Lock()
+	m.listeners = append(m.listeners, fn)
+	m.mu.Unlock()
+}
+
+func (m *Manager) notify(changed []string) {
+	if len(changed) == 0 {
+		return
+	}
+	m.mu.RLock()
+	listeners := append([]func([]string){}, m.listeners...)
+	m.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(changed)
+	}
+}
+
+// Save 写入模板：先把旧文件复制为 .bak，再通过临时文件原子替换，随后重载并通知订阅者。
+func (m *Manager) Save(name, content string) (PromptFile, error) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".txt")
+	if err := validatePromptName(name); err != nil {
+		return PromptFile{}, err
+	}
+	if strings.TrimSpace(content) == "" {
+		return PromptFile{}, fmt.Errorf("提示词内容不能为空")
+	}
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	path := filepath.Join(m.dir, name+".txt")
+	if raw, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+".bak", raw, 0o644); err != nil {
+			return PromptFile{}, fmt.Errorf("备份提示词失败: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return PromptFile{}, err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		return PromptFile{}, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return PromptFile{}, err
+	}
+	if err := m.Reload(); err != nil {
+		return PromptFile{}, err
+	}
+	file, _ := m.File(name)
+	logger.Infof("提示词 %s 已更新 version=%s", name, file.Version)
+	return file, nil
+}
+
+// Watch 按 interval 轮询目录中 *.txt 的 mtime/大小，发现变化时重载并通知订阅者，直到 ctx 结束。
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	if m == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultPromptWatchInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if !m.modified() {
+				continue
+			}
+			changed, err := m.load()
+			if err != nil {
+				logger.Warnf("提示词热重载失败: %v", err)
+				continue
+			}
+			if len(changed) > 0 {
+				logger.Infof("提示词热重载: %v", changed)
+				m.notify(changed)
+			}
+		}
+	}()
+}
+
+func (m *Manager) modified() bool {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	seen := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".txt") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		seen++
+		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		prev, ok := m.stats[name]
+		if !ok || !prev.modTime.Equal(info.ModTime()) || prev.size != info.Size() {
+			return true
+		}
+	}
+	return seen != len(m.stats)
+}
+
+// PromptVersion 返回提示词内容 sha256 的前 12 位十六进制，作为审计中的版本标识。
+func PromptVersion(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func validatePromptName(name string) error {
+	if name == "" {
+		return fmt.Errorf("提示词名称不能为空")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("提示词名称非法: %s", name)
+	}
+	return nil
+}
+
 func MustWriteSample(dir string) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -54,6 +54,7 @@ type PositionClosedPayload struct {
 	Reason          string    `json:"reason"`
 	PnL             float64   `json:"pnl"`
 	PnLPct          float64   `json:"pnl_pct"`
+	Fee             float64   `json:"fee,omitempty"`
 	ClosedAt        time.Time `json:"closed_at"`
 }
 
@@ -123,6 +123,10 @@ var adminTemplateFuncs = template.FuncMap{
 			return "FINAL_STOP"
 		case database.OperationForceExit:
 			return "FORCE_EXIT"
+		case database.OperationScaleIn:
+			return "SCALE_IN"
+		case database.OperationTimeExit:
+			return "TIME_EXIT"
 		case database.OperationFailed:
 			return "FAILED"
 		default:
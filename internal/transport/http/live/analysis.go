@@ -0,0 +1,220 @@
+package livehttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/indicator"
+	"brale/internal/analysis/screen"
+	"brale/internal/decision"
+	"brale/internal/logger"
+	"brale/internal/pipeline"
+	"brale/internal/pipeline/testkit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxBatchAnalysisSymbols = 50
+	// decisionDryRunTimeout 覆盖多模型调用与 multi-agent 阶段，单模型超时仍由 ai.decision_timeout_seconds 控制。
+	decisionDryRunTimeout = 3 * time.Minute
+)
+
+// BatchAnalyzer 只跑 profile pipeline 与本地指标，不调用 LLM，用于快速筛选。
+type BatchAnalyzer interface {
+	BatchAnalyze(ctx context.Context, req BatchAnalysisRequest) ([]BatchAnalysisResult, error)
+}
+
+// DecisionDryRunner 对单个 symbol 走完整决策链路（含模型调用），只返回结果，不下单、不写决策日志。
+type DecisionDryRunner interface {
+	DecisionDryRun(ctx context.Context, req DecisionDryRunRequest) (DecisionDryRunResult, error)
+}
+
+// ScreeningStatsProvider 暴露 LLM 前置筛选的累计计数。
+type ScreeningStatsProvider interface {
+	ScreeningStats() screen.Stats
+}
+
+// PipelineRunReporter 暴露最近一轮并发管道执行的耗时与失败汇总。
+type PipelineRunReporter interface {
+	PipelineRunReport() (pipeline.RunReport, bool)
+}
+
+// PipelineFixtureRecorder 用本地K线运行 profile pipeline 并录制 testkit fixture，供 middleware 回归测试使用。
+type PipelineFixtureRecorder interface {
+	RecordPipelineFixture(ctx context.Context, symbol, profile string, trim int, full bool) (testkit.Fixture, error)
+}
+
+// IndicatorCacheStatsProvider 暴露指标缓存（按最后收盘 K 线）的命中统计。
+type IndicatorCacheStatsProvider interface {
+	IndicatorCacheStats() (decision.IndicatorCacheStats, bool)
+}
+
+func (r *Router) handleIndicatorCacheStats(c *gin.Context) {
+	provider, ok := r.FreqtradeHandler.(IndicatorCacheStatsProvider)
+	if !ok || provider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "指标缓存统计未启用"})
+		return
+	}
+	stats, ok := provider.IndicatorCacheStats()
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "指标缓存统计未启用"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+func (r *Router) handlePipelineRunReport(c *gin.Context) {
+	reporter, ok := r.FreqtradeHandler.(PipelineRunReporter)
+	if !ok || reporter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "管道运行报告未启用"})
+		return
+	}
+	report, ok := reporter.PipelineRunReport()
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"report": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// handlePipelineFixture 以附件形式返回 fixture JSON，保存到 testdata 后即可用 testkit.MustLoad 回放。
+// 查询参数：symbol（必填）、profile、trim（每周期保留根数）、full=true 时保留特征/提示词/metadata。
+func (r *Router) handlePipelineFixture(c *gin.Context) {
+	recorder, ok := r.FreqtradeHandler.(PipelineFixtureRecorder)
+	if !ok || recorder == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "fixture 录制未启用"})
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Query("symbol")))
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol 不能为空"})
+		return
+	}
+	trim := 0
+	if raw := strings.TrimSpace(c.Query("trim")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "trim 必须为非负整数"})
+			return
+		}
+		trim = n
+	}
+	full, _ := strconv.ParseBool(c.Query("full"))
+	callCtx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	fx, err := recorder.RecordPipelineFixture(callCtx, symbol, strings.TrimSpace(c.Query("profile")), trim, full)
+	if err != nil {
+		logger.Warnf("[api] pipeline fixture failed ip=%s symbol=%s err=%v", c.ClientIP(), symbol, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.ToLower(symbol)
+	if fx.Profile != "" {
+		name += "_" + fx.Profile
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+	c.IndentedJSON(http.StatusOK, fx)
+}
+
+func (r *Router) handleScreeningStats(c *gin.Context) {
+	provider, ok := r.FreqtradeHandler.(ScreeningStatsProvider)
+	if !ok || provider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "前置筛选未启用"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": provider.ScreeningStats()})
+}
+
+// handleIndicatorPresets 列出指标预设及其在各中间件上的参数展开。
+func (r *Router) handleIndicatorPresets(c *gin.Context) {
+	presets := indicator.Presets()
+	items := make([]gin.H, 0, len(presets))
+	for _, p := range presets {
+		middlewares := gin.H{}
+		for _, name := range []string{"ema_trend", "rsi_extreme", "macd_trend"} {
+			middlewares[name] = p.MiddlewareParams(name)
+		}
+		items = append(items, gin.H{"preset": p, "middlewares": middlewares})
+	}
+	c.JSON(http.StatusOK, gin.H{"presets": items, "default": indicator.DefaultPresetName})
+}
+
+func (r *Router) handleBatchAnalysis(c *gin.Context) {
+	analyzer, ok := r.FreqtradeHandler.(BatchAnalyzer)
+	if !ok || analyzer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "批量分析未启用"})
+		return
+	}
+	var req BatchAnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	seen := make(map[string]struct{}, len(req.Symbols))
+	symbols := make([]string, 0, len(req.Symbols))
+	for _, sym := range req.Symbols {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if sym == "" {
+			continue
+		}
+		if _, dup := seen[sym]; dup {
+			continue
+		}
+		seen[sym] = struct{}{}
+		symbols = append(symbols, sym)
+	}
+	if len(symbols) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbols 不能为空"})
+		return
+	}
+	if len(symbols) > maxBatchAnalysisSymbols {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbols 数量超出上限"})
+		return
+	}
+	req.Symbols = symbols
+	req.Profile = strings.TrimSpace(req.Profile)
+	req.Interval = strings.ToLower(strings.TrimSpace(req.Interval))
+	callCtx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	results, err := analyzer.BatchAnalyze(callCtx, req)
+	if err != nil {
+		logger.Warnf("[api] batch analysis failed ip=%s symbols=%d err=%v", c.ClientIP(), len(symbols), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+func (r *Router) handleDecisionDryRun(c *gin.Context) {
+	runner, ok := r.FreqtradeHandler.(DecisionDryRunner)
+	if !ok || runner == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "决策 dry-run 未启用"})
+		return
+	}
+	var req DecisionDryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	req.Profile = strings.TrimSpace(req.Profile)
+	if req.Symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol 不能为空"})
+		return
+	}
+	callCtx, cancel := context.WithTimeout(c.Request.Context(), decisionDryRunTimeout)
+	defer cancel()
+	result, err := runner.DecisionDryRun(callCtx, req)
+	if err != nil {
+		logger.Warnf("[api] decision dry-run failed ip=%s symbol=%s profile=%s err=%v", c.ClientIP(), req.Symbol, req.Profile, err)
+		result.Error = err.Error()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "result": result})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
@@ -0,0 +1,42 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"brale/internal/logger"
+	"brale/internal/store/archive"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArchiveQuerier 查询已归档（已从数据库清理）的操作/事件记录。
+type ArchiveQuerier interface {
+	QueryArchive(ctx context.Context, table string, q archive.Query) ([]map[string]any, error)
+}
+
+func (r *Router) handleArchiveQuery(c *gin.Context) {
+	querier, ok := r.FreqtradeHandler.(ArchiveQuerier)
+	if !ok || querier == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "归档未启用"})
+		return
+	}
+	table := strings.TrimSpace(c.Param("table"))
+	tradeID, _ := strconv.Atoi(c.Query("trade_id"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	rows, err := querier.QueryArchive(c.Request.Context(), table, archive.Query{
+		TradeID: tradeID,
+		Symbol:  c.Query("symbol"),
+		From:    strings.TrimSpace(c.Query("from")),
+		To:      strings.TrimSpace(c.Query("to")),
+		Limit:   limit,
+	})
+	if err != nil {
+		logger.Warnf("[api] archive query failed ip=%s table=%s err=%v", c.ClientIP(), table, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"table": table, "records": rows})
+}
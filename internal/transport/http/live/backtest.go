@@ -0,0 +1,81 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"brale/internal/backtest"
+	"brale/internal/logger"
+	"brale/internal/pkg/trading"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BacktestRequest 指定 profile（或 symbol 所属 profile）在缓存历史 K 线上的回测参数；
+// Execution 为成交模型（入场延迟、点差/冲击滑点、maker/taker 费率），留空时按理想成交 + fee_rate 计算。
+type BacktestRequest struct {
+	Profile       string                 `json:"profile"`
+	Symbol        string                 `json:"symbol"`
+	From          string                 `json:"from"`
+	To            string                 `json:"to"`
+	Entry         backtest.EntryRules    `json:"entry"`
+	Exit          backtest.ExitRules     `json:"exit"`
+	StakeUSD      float64                `json:"stake_usd"`
+	InitialEquity float64                `json:"initial_equity"`
+	FeeRate       float64                `json:"fee_rate"`
+	WarmupBars    int                    `json:"warmup_bars"`
+	Execution     trading.ExecutionModel `json:"execution"`
+	from, to      time.Time
+}
+
+// Range 返回已解析的决策区间；零值表示不限制。
+func (r BacktestRequest) Range() (time.Time, time.Time) { return r.from, r.to }
+
+// BacktestRunner 在历史数据上回放 profile 的中间件与退出计划。
+type BacktestRunner interface {
+	RunBacktest(ctx context.Context, req BacktestRequest) (backtest.Result, error)
+}
+
+func (r *Router) handleBacktest(c *gin.Context) {
+	runner, ok := r.FreqtradeHandler.(BacktestRunner)
+	if !ok || runner == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "回测未启用"})
+		return
+	}
+	var req BacktestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if strings.TrimSpace(req.Profile) == "" && strings.TrimSpace(req.Symbol) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "profile 或 symbol 必填"})
+		return
+	}
+	if raw := strings.TrimSpace(req.From); raw != "" {
+		t, err := parseReportTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+		req.from = t
+	}
+	if raw := strings.TrimSpace(req.To); raw != "" {
+		t, err := parseReportTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+		req.to = t
+	}
+	start := time.Now()
+	res, err := runner.RunBacktest(c.Request.Context(), req)
+	if err != nil {
+		logger.Warnf("[api] backtest failed ip=%s profile=%s symbol=%s err=%v", c.ClientIP(), req.Profile, req.Symbol, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] backtest ip=%s profile=%s symbol=%s trades=%d duration=%s", c.ClientIP(), res.Profile, res.Symbol, len(res.Trades), time.Since(start))
+	c.JSON(http.StatusOK, gin.H{"result": res})
+}
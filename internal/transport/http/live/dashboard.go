@@ -0,0 +1,117 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DashboardProvider 为前端看板提供聚合数据，指标在服务端计算，前端无需重复实现。
+type DashboardProvider interface {
+	DashboardChart(ctx context.Context, symbol, interval string, limit int) (DashboardChart, error)
+	DashboardPositions(ctx context.Context) ([]DashboardPosition, error)
+	DashboardProfiles() []DashboardProfileStatus
+}
+
+func (r *Router) RegisterDashboard(group *gin.RouterGroup) {
+	if group == nil {
+		return
+	}
+	group.GET("/chart", r.handleDashboardChart)
+	group.GET("/positions", r.handleDashboardPositions)
+	group.GET("/decisions", r.handleDashboardDecisions)
+	group.GET("/profiles", r.handleDashboardProfiles)
+}
+
+func (r *Router) dashboardProvider(c *gin.Context) (DashboardProvider, bool) {
+	provider, ok := r.FreqtradeHandler.(DashboardProvider)
+	if !ok || provider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "dashboard 数据源未启用"})
+		return nil, false
+	}
+	return provider, true
+}
+
+func (r *Router) handleDashboardChart(c *gin.Context) {
+	provider, ok := r.dashboardProvider(c)
+	if !ok {
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Query("symbol")))
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol 不能为空"})
+		return
+	}
+	interval := strings.ToLower(strings.TrimSpace(c.DefaultQuery("interval", "1h")))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "300"))
+	if limit <= 0 {
+		limit = 300
+	}
+	if limit > 1500 {
+		limit = 1500
+	}
+	callCtx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	chart, err := provider.DashboardChart(callCtx, symbol, interval, limit)
+	if err != nil {
+		logger.Warnf("[api] dashboard chart failed ip=%s symbol=%s interval=%s err=%v", c.ClientIP(), symbol, interval, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"chart": chart})
+}
+
+func (r *Router) handleDashboardPositions(c *gin.Context) {
+	provider, ok := r.dashboardProvider(c)
+	if !ok {
+		return
+	}
+	callCtx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	positions, err := provider.DashboardPositions(callCtx)
+	if err != nil {
+		logger.Errorf("[api] dashboard positions failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"positions": positions})
+}
+
+func (r *Router) handleDashboardDecisions(c *gin.Context) {
+	if r.Logs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "实时日志未启用"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	query := database.LiveDecisionQuery{
+		Limit:   clampPageSize(limit),
+		Stage:   c.DefaultQuery("stage", "final"),
+		Symbol:  strings.ToUpper(strings.TrimSpace(c.Query("symbol"))),
+		Symbols: c.QueryArray("symbols"),
+	}
+	logs, err := r.fetchLiveDecisions(c.Request.Context(), query)
+	if err != nil {
+		logger.Errorf("[api] dashboard decisions failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"decisions": logs,
+		"traces":    database.BuildLiveDecisionTraces(logs),
+	})
+}
+
+func (r *Router) handleDashboardProfiles(c *gin.Context) {
+	provider, ok := r.dashboardProvider(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"profiles": provider.DashboardProfiles()})
+}
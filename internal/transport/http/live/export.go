@@ -0,0 +1,98 @@
+package livehttp
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MarketExportRequest 描述一次行情数据导出；From/To 为零值时不限制该端。
+// Kind：candles（OHLCV）、indicators（逐根指标序列）、snapshot（区间末尾的指标快照，按 JSON 路径展开）。
+type MarketExportRequest struct {
+	Symbol   string
+	Interval string
+	Kind     string
+	From     time.Time
+	To       time.Time
+}
+
+// MarketExportTable 为导出结果的表格形式，Rows 每行与 Columns 一一对应。
+type MarketExportTable struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// MarketExporter 导出缓存中的 K 线、指标与快照，供离线分析（pandas 等）使用。
+type MarketExporter interface {
+	ExportMarketData(ctx context.Context, req MarketExportRequest) (MarketExportTable, error)
+}
+
+// handleMarketExport 支持 ?symbol=&interval=&kind=&from=&to=&format=csv，以附件形式返回 CSV。
+// parquet 需要额外的列存编码依赖，当前仅支持 csv。
+func (r *Router) handleMarketExport(c *gin.Context) {
+	exporter, ok := r.FreqtradeHandler.(MarketExporter)
+	if !ok || exporter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "数据导出未启用"})
+		return
+	}
+	req := MarketExportRequest{
+		Symbol:   strings.ToUpper(strings.TrimSpace(c.Query("symbol"))),
+		Interval: strings.ToLower(strings.TrimSpace(c.DefaultQuery("interval", "1h"))),
+		Kind:     strings.ToLower(strings.TrimSpace(c.DefaultQuery("kind", "candles"))),
+	}
+	if req.Symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol 不能为空"})
+		return
+	}
+	switch format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "csv"))); format {
+	case "csv":
+	case "parquet":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "暂不支持 parquet，请使用 format=csv"})
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("未知导出格式: %s", format)})
+		return
+	}
+	for _, bound := range []struct {
+		key string
+		dst *time.Time
+	}{{"from", &req.From}, {"to", &req.To}} {
+		raw := strings.TrimSpace(c.Query(bound.key))
+		if raw == "" {
+			continue
+		}
+		t, err := parseReportTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + bound.key})
+			return
+		}
+		*bound.dst = t
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from 必须早于 to"})
+		return
+	}
+	table, err := exporter.ExportMarketData(c.Request.Context(), req)
+	if err != nil {
+		logger.Warnf("[api] market export failed ip=%s symbol=%s interval=%s kind=%s err=%v", c.ClientIP(), req.Symbol, req.Interval, req.Kind, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filename := fmt.Sprintf("%s_%s_%s.csv", req.Symbol, req.Interval, req.Kind)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(table.Columns)
+	_ = w.WriteAll(table.Rows)
+	if err := w.Error(); err != nil {
+		logger.Warnf("[api] market export write failed ip=%s err=%v", c.ClientIP(), err)
+	}
+}
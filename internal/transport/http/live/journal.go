@@ -0,0 +1,95 @@
+package livehttp
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/attribution"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JournalQuery 为交易日志导出的过滤条件：平仓时间 [From, To)，Profile/Symbol 为空表示不过滤。
+type JournalQuery struct {
+	From    time.Time
+	To      time.Time
+	Profile string
+	Symbol  string
+}
+
+// TradeJournaler 返回已平仓交易的日志（开平仓价、止盈档位、盈亏、滑点），按平仓时间升序。
+type TradeJournaler interface {
+	TradeJournal(ctx context.Context, q JournalQuery) ([]attribution.JournalEntry, error)
+}
+
+var journalColumns = []string{
+	"trade_id", "profile", "symbol", "side", "opened_at", "closed_at", "entry_price", "exit_price", "amount",
+	"tp_tiers", "tp_tiers_hit", "sl_tiers_hit", "hit_tiers", "pnl_usd", "pnl_ratio", "fee_usd", "entry_slippage_pct",
+}
+
+// handleJournalExport 支持 ?from=&to=（RFC3339 或 YYYY-MM-DD，默认最近 30 天）与 ?profile=&symbol= 过滤，以附件形式返回 CSV。
+func (r *Router) handleJournalExport(c *gin.Context) {
+	journaler, ok := r.FreqtradeHandler.(TradeJournaler)
+	if !ok || journaler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "交易日志未启用"})
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	q := JournalQuery{
+		From:    from,
+		To:      to,
+		Profile: strings.TrimSpace(c.Query("profile")),
+		Symbol:  strings.TrimSpace(c.Query("symbol")),
+	}
+	entries, err := journaler.TradeJournal(c.Request.Context(), q)
+	if err != nil {
+		logger.Errorf("[api] journal export failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	filename := fmt.Sprintf("journal_%s_%s", from.Format("20060102"), to.Format("20060102"))
+	if q.Profile != "" {
+		filename += "_" + q.Profile
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(journalColumns)
+	for _, e := range entries {
+		_ = w.Write(journalRow(e))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logger.Warnf("[api] journal export write failed ip=%s err=%v", c.ClientIP(), err)
+	}
+}
+
+func journalRow(e attribution.JournalEntry) []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	ts := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	slippage := ""
+	if e.HasSlippage {
+		slippage = f(e.EntrySlippagePct)
+	}
+	return []string{
+		strconv.Itoa(e.TradeID), e.Profile, e.Symbol, e.Side, ts(e.OpenedAt), ts(e.ClosedAt),
+		f(e.EntryPrice), f(e.ExitPrice), f(e.Amount),
+		strconv.Itoa(e.TPTiers), strconv.Itoa(e.TPTiersHit), strconv.Itoa(e.SLTiersHit), strings.Join(e.HitTiers, ";"),
+		f(e.PnLUSD), f(e.PnLRatio), f(e.FeeUSD), slippage,
+	}
+}
@@ -0,0 +1,47 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"brale/internal/logger"
+	"brale/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KlineConsistencyChecker 报告 K 线缓存中的重复、乱序、缺口与写入路径的拒绝计数。
+type KlineConsistencyChecker interface {
+	KlineConsistency(ctx context.Context, symbol, interval string) ([]store.KlineConsistencyReport, error)
+}
+
+// handleKlineConsistency 支持 ?symbol=&interval= 过滤；only_anomalies=true 时仅返回有异常的条目。
+func (r *Router) handleKlineConsistency(c *gin.Context) {
+	checker, ok := r.FreqtradeHandler.(KlineConsistencyChecker)
+	if !ok || checker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "K线一致性检查未启用"})
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Query("symbol")))
+	interval := strings.ToLower(strings.TrimSpace(c.Query("interval")))
+	reports, err := checker.KlineConsistency(c.Request.Context(), symbol, interval)
+	if err != nil {
+		logger.Warnf("[api] kline consistency failed ip=%s symbol=%s interval=%s err=%v", c.ClientIP(), symbol, interval, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	anomalies := 0
+	filtered := reports[:0]
+	onlyAnomalies := strings.EqualFold(c.Query("only_anomalies"), "true")
+	for _, rep := range reports {
+		if !rep.OK {
+			anomalies++
+		}
+		if onlyAnomalies && rep.OK {
+			continue
+		}
+		filtered = append(filtered, rep)
+	}
+	c.JSON(http.StatusOK, gin.H{"reports": filtered, "total": len(reports), "anomalies": anomalies})
+}
@@ -0,0 +1,171 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnnotationManager 管理交易/profile 上的人工备注与标签。
+type AnnotationManager interface {
+	AddAnnotation(ctx context.Context, rec database.AnnotationRecord) (database.AnnotationRecord, error)
+	ListAnnotations(ctx context.Context, q database.AnnotationQuery) ([]database.AnnotationRecord, error)
+	DeleteAnnotation(ctx context.Context, id int64) error
+}
+
+type annotationRequest struct {
+	Note string   `json:"note"`
+	Tags []string `json:"tags"`
+}
+
+func (r *Router) annotationManager(c *gin.Context) (AnnotationManager, bool) {
+	mgr, ok := r.FreqtradeHandler.(AnnotationManager)
+	if !ok || mgr == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "备注存储未启用"})
+		return nil, false
+	}
+	return mgr, true
+}
+
+// annotationTarget 从路由参数解析备注对象：/freqtrade/positions/:id/notes 或 /profiles/:name/notes。
+func annotationTarget(c *gin.Context) (string, string, bool) {
+	if name := strings.TrimSpace(c.Param("name")); name != "" {
+		return database.AnnotationTargetProfile, name, true
+	}
+	tradeID, _ := strconv.Atoi(c.Param("id"))
+	if tradeID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid trade_id"})
+		return "", "", false
+	}
+	return database.AnnotationTargetTrade, strconv.Itoa(tradeID), true
+}
+
+func (r *Router) handleAddAnnotation(c *gin.Context) {
+	mgr, ok := r.annotationManager(c)
+	if !ok {
+		return
+	}
+	targetType, targetID, ok := annotationTarget(c)
+	if !ok {
+		return
+	}
+	var req annotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Note == "" && len(req.Tags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note 或 tags 不能同时为空"})
+		return
+	}
+	rec, err := mgr.AddAnnotation(c.Request.Context(), database.AnnotationRecord{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Note:       req.Note,
+		Tags:       req.Tags,
+	})
+	if err != nil {
+		logger.Errorf("[api] add annotation failed ip=%s target=%s/%s err=%v", c.ClientIP(), targetType, targetID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] annotation added ip=%s target=%s/%s tags=%v", c.ClientIP(), targetType, targetID, rec.Tags)
+	c.JSON(http.StatusOK, gin.H{"note": rec})
+}
+
+func (r *Router) handleListTargetAnnotations(c *gin.Context) {
+	mgr, ok := r.annotationManager(c)
+	if !ok {
+		return
+	}
+	targetType, targetID, ok := annotationTarget(c)
+	if !ok {
+		return
+	}
+	r.respondAnnotations(c, mgr, database.AnnotationQuery{TargetType: targetType, TargetID: targetID, Tag: c.Query("tag")})
+}
+
+// handleListAnnotations 支持按 target/tag 跨对象检索，如 /notes?tag=news。
+func (r *Router) handleListAnnotations(c *gin.Context) {
+	mgr, ok := r.annotationManager(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	r.respondAnnotations(c, mgr, database.AnnotationQuery{
+		TargetType: strings.ToLower(strings.TrimSpace(c.Query("target"))),
+		TargetID:   strings.TrimSpace(c.Query("id")),
+		Tag:        c.Query("tag"),
+		Limit:      limit,
+	})
+}
+
+func (r *Router) respondAnnotations(c *gin.Context, mgr AnnotationManager, q database.AnnotationQuery) {
+	notes, err := mgr.ListAnnotations(c.Request.Context(), q)
+	if err != nil {
+		logger.Errorf("[api] list annotations failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"notes": notes})
+}
+
+func (r *Router) handleDeleteAnnotation(c *gin.Context) {
+	mgr, ok := r.annotationManager(c)
+	if !ok {
+		return
+	}
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := mgr.DeleteAnnotation(c.Request.Context(), id); err != nil {
+		logger.Warnf("[api] delete annotation failed ip=%s id=%d err=%v", c.ClientIP(), id, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "id": id})
+}
+
+// tradeNotes 返回交易的人工备注，备注存储未启用或查询失败时返回空。
+func (r *Router) tradeNotes(ctx context.Context, tradeID int) []database.AnnotationRecord {
+	mgr, ok := r.FreqtradeHandler.(AnnotationManager)
+	if !ok || mgr == nil {
+		return nil
+	}
+	notes, err := mgr.ListAnnotations(ctx, database.AnnotationQuery{
+		TargetType: database.AnnotationTargetTrade,
+		TargetID:   strconv.Itoa(tradeID),
+	})
+	if err != nil {
+		logger.Warnf("[api] load trade notes failed trade_id=%d err=%v", tradeID, err)
+		return nil
+	}
+	return notes
+}
+
+// profileNotes 返回各 profile 的人工备注（按 profile 名分组）。
+func (r *Router) profileNotes(ctx context.Context) map[string][]database.AnnotationRecord {
+	mgr, ok := r.FreqtradeHandler.(AnnotationManager)
+	if !ok || mgr == nil {
+		return nil
+	}
+	notes, err := mgr.ListAnnotations(ctx, database.AnnotationQuery{TargetType: database.AnnotationTargetProfile, Limit: 1000})
+	if err != nil {
+		logger.Warnf("[api] load profile notes failed err=%v", err)
+		return nil
+	}
+	out := make(map[string][]database.AnnotationRecord)
+	for _, n := range notes {
+		out[n.TargetID] = append(out[n.TargetID], n)
+	}
+	return out
+}
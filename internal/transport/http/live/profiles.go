@@ -0,0 +1,120 @@
+package livehttp
+
+import (
+	"net/http"
+	"strings"
+
+	"brale/internal/agent/health"
+	"brale/internal/config/loader"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileAdmin 管理 profiles.yaml：删除为软删除，可在保留期内恢复。
+type ProfileAdmin interface {
+	ListProfiles(includeDeleted bool) ([]loader.ProfileEntry, error)
+	DeleteProfile(name string) error
+	RestoreProfile(name string) error
+}
+
+// ProfileTierRatioEditor 修改 profile 的多/空分段止盈止损默认比例（写回 profiles.yaml 并热重载）。
+type ProfileTierRatioEditor interface {
+	SetProfileTierRatios(name string, cfg loader.TierRatioConfig) error
+}
+
+// ProfileHealthProvider 暴露各 profile 的决策健康度与停滞告警。
+type ProfileHealthProvider interface {
+	ProfileHealth() []health.Status
+}
+
+func (r *Router) profileAdmin(c *gin.Context) (ProfileAdmin, bool) {
+	admin, ok := r.FreqtradeHandler.(ProfileAdmin)
+	if !ok || admin == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "profile 管理未启用"})
+		return nil, false
+	}
+	return admin, true
+}
+
+func (r *Router) handleListProfiles(c *gin.Context) {
+	admin, ok := r.profileAdmin(c)
+	if !ok {
+		return
+	}
+	includeDeleted := strings.EqualFold(c.Query("include_deleted"), "true")
+	entries, err := admin.ListProfiles(includeDeleted)
+	if err != nil {
+		logger.Errorf("[api] list profiles failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"profiles": entries, "notes": r.profileNotes(c.Request.Context())})
+}
+
+func (r *Router) handleDeleteProfile(c *gin.Context) {
+	admin, ok := r.profileAdmin(c)
+	if !ok {
+		return
+	}
+	name := strings.TrimSpace(c.Param("name"))
+	if err := admin.DeleteProfile(name); err != nil {
+		logger.Warnf("[api] delete profile failed ip=%s name=%s err=%v", c.ClientIP(), name, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] profile soft-deleted ip=%s name=%s", c.ClientIP(), name)
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "name": name})
+}
+
+func (r *Router) handleRestoreProfile(c *gin.Context) {
+	admin, ok := r.profileAdmin(c)
+	if !ok {
+		return
+	}
+	name := strings.TrimSpace(c.Param("name"))
+	if err := admin.RestoreProfile(name); err != nil {
+		logger.Warnf("[api] restore profile failed ip=%s name=%s err=%v", c.ClientIP(), name, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] profile restored ip=%s name=%s", c.ClientIP(), name)
+	c.JSON(http.StatusOK, gin.H{"status": "restored", "name": name})
+}
+
+func (r *Router) handleSetProfileTierRatios(c *gin.Context) {
+	editor, ok := r.FreqtradeHandler.(ProfileTierRatioEditor)
+	if !ok || editor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "profile 管理未启用"})
+		return
+	}
+	name := strings.TrimSpace(c.Param("name"))
+	var req loader.TierRatioConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := editor.SetProfileTierRatios(name, req); err != nil {
+		logger.Warnf("[api] set profile tier ratios failed ip=%s name=%s err=%v", c.ClientIP(), name, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] profile tier ratios updated ip=%s name=%s long=%v short=%v", c.ClientIP(), name, req.Long, req.Short)
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "name": name, "tier_ratios": req})
+}
+
+func (r *Router) handleProfileHealth(c *gin.Context) {
+	provider, ok := r.FreqtradeHandler.(ProfileHealthProvider)
+	if !ok || provider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "profile 健康度未启用"})
+		return
+	}
+	statuses := provider.ProfileHealth()
+	stale := 0
+	for _, st := range statuses {
+		if st.Stale {
+			stale++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"profiles": statuses, "stale": stale})
+}
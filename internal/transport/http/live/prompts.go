@@ -0,0 +1,78 @@
+package livehttp
+
+import (
+	"net/http"
+	"strings"
+
+	"brale/internal/logger"
+	"brale/internal/strategy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromptEditor 读写提示词目录下的模板；写入前备份为 .bak，引用它的 profile 随即热更新。
+type PromptEditor interface {
+	ListPrompts() ([]strategy.PromptFile, error)
+	GetPrompt(name string) (strategy.PromptFile, error)
+	SavePrompt(name, content string) (strategy.PromptFile, error)
+}
+
+type promptUpdateRequest struct {
+	Content string `json:"content"`
+}
+
+func (r *Router) promptEditor(c *gin.Context) (PromptEditor, bool) {
+	editor, ok := r.FreqtradeHandler.(PromptEditor)
+	if !ok || editor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "提示词管理未启用"})
+		return nil, false
+	}
+	return editor, true
+}
+
+func (r *Router) handleListPrompts(c *gin.Context) {
+	editor, ok := r.promptEditor(c)
+	if !ok {
+		return
+	}
+	files, err := editor.ListPrompts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"prompts": files})
+}
+
+func (r *Router) handleGetPrompt(c *gin.Context) {
+	editor, ok := r.promptEditor(c)
+	if !ok {
+		return
+	}
+	file, err := editor.GetPrompt(strings.TrimSpace(c.Param("name")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+func (r *Router) handleSavePrompt(c *gin.Context) {
+	editor, ok := r.promptEditor(c)
+	if !ok {
+		return
+	}
+	name := strings.TrimSpace(c.Param("name"))
+	var req promptUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	file, err := editor.SavePrompt(name, req.Content)
+	if err != nil {
+		logger.Warnf("[api] save prompt failed ip=%s name=%s err=%v", c.ClientIP(), name, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] prompt updated ip=%s name=%s version=%s", c.ClientIP(), file.Name, file.Version)
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "prompt": file})
+}
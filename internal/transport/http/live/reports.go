@@ -0,0 +1,150 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"brale/internal/analysis/attribution"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttributionReporter 按开仓时的背离信号对已平仓交易做绩效归因。
+type AttributionReporter interface {
+	DivergenceAttribution(ctx context.Context, since time.Time) (attribution.Report, error)
+}
+
+// handleDivergenceAttribution 支持 ?days=N 限定平仓时间窗口，默认统计全部历史。
+func (r *Router) handleDivergenceAttribution(c *gin.Context) {
+	reporter, ok := r.FreqtradeHandler.(AttributionReporter)
+	if !ok || reporter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "绩效归因未启用"})
+		return
+	}
+	var since time.Time
+	if raw := strings.TrimSpace(c.Query("days")); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+			return
+		}
+		since = time.Now().AddDate(0, 0, -days)
+	}
+	report, err := reporter.DivergenceAttribution(c.Request.Context(), since)
+	if err != nil {
+		logger.Errorf("[api] divergence attribution failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// ProfileComparer 按 profile 对比区间内的决策与已实现交易结果。
+type ProfileComparer interface {
+	ProfileComparison(ctx context.Context, from, to time.Time) ([]attribution.ProfileStats, error)
+}
+
+// handleProfileComparison 支持 ?from=&to=（RFC3339 或 YYYY-MM-DD），默认统计最近 30 天。
+func (r *Router) handleProfileComparison(c *gin.Context) {
+	comparer, ok := r.FreqtradeHandler.(ProfileComparer)
+	if !ok || comparer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "profile 对比未启用"})
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	stats, err := comparer.ProfileComparison(c.Request.Context(), from, to)
+	if err != nil {
+		logger.Errorf("[api] profile comparison failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "profiles": stats})
+}
+
+// StatsQuery 为 /api/stats 的过滤条件：平仓时间 [From, To)，Profile/Symbol 为空表示不过滤。
+type StatsQuery struct {
+	From    time.Time
+	To      time.Time
+	Profile string
+	Symbol  string
+}
+
+// StatsReporter 汇总已平仓交易的策略 KPI（命中率、平均 R、持仓时长、按背离信号的盈亏）。
+type StatsReporter interface {
+	TradeKPIs(ctx context.Context, q StatsQuery) (attribution.KPIReport, error)
+}
+
+// RegisterStats 挂载 /api/stats。
+func (r *Router) RegisterStats(group *gin.RouterGroup) {
+	if group == nil {
+		return
+	}
+	group.GET("/stats", r.handleStats)
+}
+
+// handleStats 支持 ?from=&to=（RFC3339 或 YYYY-MM-DD，默认最近 30 天）与 ?profile=&symbol= 过滤。
+func (r *Router) handleStats(c *gin.Context) {
+	reporter, ok := r.FreqtradeHandler.(StatsReporter)
+	if !ok || reporter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "策略统计未启用"})
+		return
+	}
+	from, to, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+	q := StatsQuery{
+		From:    from,
+		To:      to,
+		Profile: strings.TrimSpace(c.Query("profile")),
+		Symbol:  strings.TrimSpace(c.Query("symbol")),
+	}
+	report, err := reporter.TradeKPIs(c.Request.Context(), q)
+	if err != nil {
+		logger.Errorf("[api] stats failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "profile": q.Profile, "symbol": q.Symbol, "stats": report})
+}
+
+// parseReportRange 解析 ?from=&to=，默认 to 为当前时间、from 为 to 前 30 天；参数无效时已写入 400 响应。
+func parseReportRange(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now()
+	if raw := strings.TrimSpace(c.Query("to")); raw != "" {
+		t, err := parseReportTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -30)
+	if raw := strings.TrimSpace(c.Query("from")); raw != "" {
+		t, err := parseReportTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from 必须早于 to"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func parseReportTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", raw, time.Local)
+}
@@ -0,0 +1,28 @@
+package livehttp
+
+import (
+	"net/http"
+
+	"brale/internal/agent/risk"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CorrelationProvider 提供相关性闸门使用的 symbol 收益率相关矩阵。
+type CorrelationProvider interface {
+	CorrelationMatrix() (risk.CorrelationMatrix, bool)
+}
+
+func (r *Router) handleCorrelationMatrix(c *gin.Context) {
+	provider, ok := r.FreqtradeHandler.(CorrelationProvider)
+	if !ok || provider == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "相关性闸门未启用"})
+		return
+	}
+	matrix, enabled := provider.CorrelationMatrix()
+	if !enabled {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "相关性闸门未启用"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"correlation": matrix})
+}
@@ -42,21 +42,62 @@ func (r *Router) Register(group *gin.RouterGroup) {
 	}
 	group.GET("/decisions", r.handleLiveDecisions)
 	group.GET("/decisions/:id", r.handleDecisionByID)
+	group.GET("/decisions/:id/audit", r.handleDecisionAudit)
 	group.GET("/traces", r.handleLiveDecisions)
 	group.GET("/logs", r.handleLiveLogs)
 	group.GET("/plans/changes", r.handlePlanChanges)
 	group.GET("/plans/instances", r.handlePlanInstances)
+	group.GET("/indicators/presets", r.handleIndicatorPresets)
 	if r.FreqtradeHandler != nil {
+		// rw 上的路由会调用执行器/LLM 或写入状态，standby 实例拒绝访问。
+		rw := group.Group("", r.standbyGuard)
 		group.POST("/freqtrade/webhook", r.handleFreqtradeWebhook)
 		group.GET("/freqtrade/positions", r.handleFreqtradePositions)
 		group.GET("/freqtrade/positions/:id", r.handleFreqtradePositionDetail)
 		group.POST("/freqtrade/positions/:id/refresh", r.handleFreqtradePositionRefresh)
-		group.POST("/freqtrade/close", r.handleFreqtradeQuickClose)
+		rw.POST("/freqtrade/close", r.handleFreqtradeQuickClose)
 
-		group.POST("/freqtrade/manual-open", r.handleFreqtradeManualOpen)
+		rw.POST("/freqtrade/manual-open", r.handleFreqtradeManualOpen)
 		group.GET("/freqtrade/price", r.handleFreqtradePriceQuote)
 		group.GET("/freqtrade/events", r.handleFreqtradeEvents)
-		group.POST("/plans/adjust", r.handlePlanAdjust)
+		rw.POST("/plans/adjust", r.handlePlanAdjust)
+		rw.POST("/freqtrade/positions/:id/tiers/complete", r.handleTierCompletion)
+		rw.PUT("/freqtrade/positions/:id/tiers", r.handleTierEdit)
+		group.POST("/analysis/batch", r.handleBatchAnalysis)
+		rw.POST("/analysis/dry-run", r.handleDecisionDryRun)
+		group.GET("/screening/stats", r.handleScreeningStats)
+		group.GET("/pipeline/runs/latest", r.handlePipelineRunReport)
+		group.GET("/pipeline/fixture", r.handlePipelineFixture)
+		group.GET("/indicators/cache/stats", r.handleIndicatorCacheStats)
+		group.GET("/reports/divergence-attribution", r.handleDivergenceAttribution)
+		group.GET("/reports/profiles/compare", r.handleProfileComparison)
+		group.GET("/reports/journal/export", r.handleJournalExport)
+		group.POST("/backtest", r.handleBacktest)
+		group.GET("/market/klines/consistency", r.handleKlineConsistency)
+		group.GET("/market/export", r.handleMarketExport)
+		group.GET("/archive/:table", r.handleArchiveQuery)
+		group.GET("/webhooks/deliveries", r.handleWebhookDeliveries)
+		group.GET("/profiles", r.handleListProfiles)
+		group.GET("/profiles/health", r.handleProfileHealth)
+		group.GET("/ws", r.handleStream)
+		rw.DELETE("/profiles/:name", r.handleDeleteProfile)
+		rw.POST("/profiles/:name/restore", r.handleRestoreProfile)
+		rw.PUT("/profiles/:name/tier-ratios", r.handleSetProfileTierRatios)
+		group.GET("/profiles/:name/notes", r.handleListTargetAnnotations)
+		rw.POST("/profiles/:name/notes", r.handleAddAnnotation)
+		group.GET("/prompts", r.handleListPrompts)
+		group.GET("/prompts/:name", r.handleGetPrompt)
+		rw.PUT("/prompts/:name", r.handleSavePrompt)
+		group.GET("/freqtrade/positions/:id/notes", r.handleListTargetAnnotations)
+		rw.POST("/freqtrade/positions/:id/notes", r.handleAddAnnotation)
+		group.GET("/notes", r.handleListAnnotations)
+		rw.DELETE("/notes/:id", r.handleDeleteAnnotation)
+		group.GET("/symbols/gate", r.handleSymbolGateStatus)
+		group.GET("/risk/correlation", r.handleCorrelationMatrix)
+		rw.POST("/symbols/blacklist", r.handleBlacklistSymbol)
+		rw.DELETE("/symbols/blacklist/:symbol", r.handleUnblacklistSymbol)
+		group.GET("/runtime/mode", r.handleRunMode)
+		group.POST("/runtime/promote", r.handlePromote)
 	}
 }
 
@@ -255,6 +296,40 @@ func (r *Router) handleDecisionByID(c *gin.Context) {
 	})
 }
 
+// handleDecisionAudit 返回某条决策日志所属轮次的审计记录（提示词、原始输出、解析结果、快照哈希与耗时）及结构化理由。
+func (r *Router) handleDecisionAudit(c *gin.Context) {
+	if r.Logs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "实时日志未启用"})
+		return
+	}
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	if id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid decision id"})
+		return
+	}
+	audits, err := r.Logs.ListDecisionAudit(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "decision not found"})
+			return
+		}
+		logger.Errorf("[api] decision audit failed ip=%s id=%d err=%v", c.ClientIP(), id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if audits == nil {
+		audits = []database.DecisionAuditRecord{}
+	}
+	rationales, err := r.Logs.ListDecisionRationales(c.Request.Context(), id)
+	if err != nil {
+		logger.Warnf("[api] decision rationale failed id=%d err=%v", id, err)
+	}
+	if rationales == nil {
+		rationales = []database.DecisionRationaleRecord{}
+	}
+	c.JSON(http.StatusOK, gin.H{"decision_id": id, "audits": audits, "rationales": rationales})
+}
+
 func (r *Router) handleFreqtradeWebhook(c *gin.Context) {
 	if r.FreqtradeHandler == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "未配置 freqtrade 处理器"})
@@ -357,6 +432,7 @@ func (r *Router) handleFreqtradePositionDetail(c *gin.Context) {
 	type apiPositionWithPlans struct {
 		exchange.APIPosition
 		Plans []database.StrategyInstanceRecord `json:"plans,omitempty"`
+		Notes []database.AnnotationRecord       `json:"notes,omitempty"`
 	}
 	type positionGetter interface {
 		GetFreqtradePosition(context.Context, int) (*exchange.APIPosition, error)
@@ -386,7 +462,7 @@ func (r *Router) handleFreqtradePositionDetail(c *gin.Context) {
 		logger.Debugf("[api] freqtrade position detail ip=%s trade_id=%d symbol=%s side=%s",
 			c.ClientIP(), tradeID, strings.ToUpper(strings.TrimSpace(pos.Symbol)), strings.ToLower(strings.TrimSpace(pos.Side)))
 		c.JSON(http.StatusOK, gin.H{
-			"position": apiPositionWithPlans{APIPosition: *pos, Plans: plans},
+			"position": apiPositionWithPlans{APIPosition: *pos, Plans: plans, Notes: r.tradeNotes(c.Request.Context(), tradeID)},
 		})
 		return
 	}
@@ -434,7 +510,7 @@ func (r *Router) handleFreqtradePositionDetail(c *gin.Context) {
 	logger.Debugf("[api] freqtrade position detail ip=%s trade_id=%d symbol=%s side=%s",
 		c.ClientIP(), tradeID, strings.ToUpper(strings.TrimSpace(target.Symbol)), strings.ToLower(strings.TrimSpace(target.Side)))
 	c.JSON(http.StatusOK, gin.H{
-		"position": apiPositionWithPlans{APIPosition: *target, Plans: plans},
+		"position": apiPositionWithPlans{APIPosition: *target, Plans: plans, Notes: r.tradeNotes(c.Request.Context(), tradeID)},
 	})
 }
 
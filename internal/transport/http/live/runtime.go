@@ -0,0 +1,76 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunModeStatus 为实例当前运行模式（active/standby）。
+type RunModeStatus struct {
+	Mode       string    `json:"mode"`
+	Since      time.Time `json:"since"`
+	PromotedBy string    `json:"promoted_by,omitempty"`
+}
+
+// RunModeController 由支持热备的服务实现；standby 时写接口一律拒绝。
+type RunModeController interface {
+	RunMode() RunModeStatus
+	Promote(ctx context.Context, operator string) (RunModeStatus, error)
+}
+
+type promoteRequest struct {
+	Operator string `json:"operator"`
+}
+
+func (r *Router) handleRunMode(c *gin.Context) {
+	ctrl, ok := r.FreqtradeHandler.(RunModeController)
+	if !ok || ctrl == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "运行模式控制未启用"})
+		return
+	}
+	c.JSON(http.StatusOK, ctrl.RunMode())
+}
+
+func (r *Router) handlePromote(c *gin.Context) {
+	ctrl, ok := r.FreqtradeHandler.(RunModeController)
+	if !ok || ctrl == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "运行模式控制未启用"})
+		return
+	}
+	var req promoteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+			return
+		}
+	}
+	operator := strings.TrimSpace(req.Operator)
+	if operator == "" {
+		operator = c.ClientIP()
+	}
+	status, err := ctrl.Promote(c.Request.Context(), operator)
+	if err != nil {
+		logger.Errorf("[api] promote failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] promote ip=%s operator=%s mode=%s", c.ClientIP(), operator, status.Mode)
+	c.JSON(http.StatusOK, status)
+}
+
+// standbyGuard 挂在会调用执行器/LLM 或写入状态的路由上，standby 实例只提供只读接口。
+func (r *Router) standbyGuard(c *gin.Context) {
+	if ctrl, ok := r.FreqtradeHandler.(RunModeController); ok && ctrl != nil {
+		if ctrl.RunMode().Mode == "standby" {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "standby 实例只读，请先 promote 为 active"})
+			return
+		}
+	}
+	c.Next()
+}
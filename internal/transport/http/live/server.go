@@ -60,6 +60,8 @@ func NewServer(cfg ServerConfig) (*Server, error) {
 	})
 	liveRouter := NewRouter(cfg.Logs, cfg.FreqtradeHandler, cfg.LogPaths)
 	liveRouter.Register(router.Group("/api/live"))
+	liveRouter.RegisterDashboard(router.Group("/api/v1/dashboard"))
+	liveRouter.RegisterStats(router.Group("/api"))
 
 	return &Server{addr: cfg.Addr, router: router}, nil
 }
@@ -0,0 +1,208 @@
+package livehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	streamPriceInterval    = time.Second
+	streamPositionInterval = 3 * time.Second
+	streamPingInterval     = 30 * time.Second
+	streamWriteTimeout     = 10 * time.Second
+	streamPongWait         = 70 * time.Second
+)
+
+// PriceTick 为 symbol 的最新成交价与毫秒时间戳。
+type PriceTick struct {
+	Price float64 `json:"price"`
+	TS    int64   `json:"ts"`
+}
+
+// LiveStreamSource 为 /ws 推送提供持仓（含 tier 状态、剩余比例、待成交阶段）与各 symbol 最新价。
+type LiveStreamSource interface {
+	DashboardPositions(ctx context.Context) ([]DashboardPosition, error)
+	LastPrices() map[string]PriceTick
+}
+
+// StreamMessage 为 /ws 下发的消息：type=positions 时为全量持仓快照，type=prices 时只含变化的价格。
+type StreamMessage struct {
+	Type      string               `json:"type"`
+	TS        int64                `json:"ts"`
+	Positions []DashboardPosition  `json:"positions,omitempty"`
+	Prices    map[string]PriceTick `json:"prices,omitempty"`
+}
+
+var streamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// handleStream 以轮询 + 差分的方式推送：价格每秒检查一次只发变化，持仓每 3 秒检查一次内容变化时发全量。
+// 可选 query: symbols=BTCUSDT,ETHUSDT 过滤价格与持仓。
+func (r *Router) handleStream(c *gin.Context) {
+	src, ok := r.FreqtradeHandler.(LiveStreamSource)
+	if !ok || src == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "实时推送未启用"})
+		return
+	}
+	filter := parseStreamSymbols(c.Query("symbols"))
+	conn, err := streamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Warnf("[ws] upgrade failed ip=%s err=%v", c.ClientIP(), err)
+		return
+	}
+	defer conn.Close()
+	logger.Infof("[ws] client connected ip=%s symbols=%d", c.ClientIP(), len(filter))
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go streamReadLoop(conn, cancel)
+
+	s := &streamSession{conn: conn, src: src, filter: filter, lastPrices: make(map[string]PriceTick)}
+	if err := s.pushPositions(ctx); err != nil {
+		return
+	}
+	if err := s.pushPrices(); err != nil {
+		return
+	}
+	priceTicker := time.NewTicker(streamPriceInterval)
+	defer priceTicker.Stop()
+	posTicker := time.NewTicker(streamPositionInterval)
+	defer posTicker.Stop()
+	pingTicker := time.NewTicker(streamPingInterval)
+	defer pingTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Infof("[ws] client disconnected ip=%s", c.ClientIP())
+			return
+		case <-priceTicker.C:
+			err = s.pushPrices()
+		case <-posTicker.C:
+			err = s.pushPositions(ctx)
+		case <-pingTicker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+		}
+		if err != nil {
+			logger.Debugf("[ws] write failed ip=%s err=%v", c.ClientIP(), err)
+			return
+		}
+	}
+}
+
+// streamReadLoop 只处理控制帧与关闭；客户端消息忽略。
+func streamReadLoop(conn *websocket.Conn, cancel context.CancelFunc) {
+	defer cancel()
+	_ = conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+type streamSession struct {
+	conn        *websocket.Conn
+	src         LiveStreamSource
+	filter      map[string]struct{}
+	lastPrices  map[string]PriceTick
+	positionSig []byte
+}
+
+func (s *streamSession) pushPrices() error {
+	changed := make(map[string]PriceTick)
+	for sym, tick := range s.src.LastPrices() {
+		if !s.match(sym) {
+			continue
+		}
+		if prev, ok := s.lastPrices[sym]; ok && prev == tick {
+			continue
+		}
+		s.lastPrices[sym] = tick
+		changed[sym] = tick
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return s.write(StreamMessage{Type: "prices", TS: time.Now().UnixMilli(), Prices: changed})
+}
+
+func (s *streamSession) pushPositions(ctx context.Context) error {
+	positions, err := s.src.DashboardPositions(ctx)
+	if err != nil {
+		logger.Debugf("[ws] load positions failed: %v", err)
+		return nil
+	}
+	filtered := make([]DashboardPosition, 0, len(positions))
+	for _, pos := range positions {
+		if s.match(pos.Symbol) {
+			filtered = append(filtered, pos)
+		}
+	}
+	sig := positionSignature(filtered)
+	if s.positionSig != nil && bytes.Equal(sig, s.positionSig) {
+		return nil
+	}
+	s.positionSig = sig
+	return s.write(StreamMessage{Type: "positions", TS: time.Now().UnixMilli(), Positions: filtered})
+}
+
+// positionSignature 忽略随时间自增的持仓时长与随价格跳动的现价/浮盈，只在结构性变化（tier、剩余比例、待成交等）时推送；
+// 现价由 prices 消息提供。
+func positionSignature(positions []DashboardPosition) []byte {
+	trimmed := make([]DashboardPosition, len(positions))
+	for i, pos := range positions {
+		pos.HoldingMs = 0
+		pos.CurrentPrice = 0
+		pos.PnLRatio, pos.PnLUSD = 0, 0
+		pos.UnrealizedPnLRatio, pos.UnrealizedPnLUSD = 0, 0
+		trimmed[i] = pos
+	}
+	raw, _ := json.Marshal(trimmed)
+	return raw
+}
+
+func (s *streamSession) match(symbol string) bool {
+	if len(s.filter) == 0 {
+		return true
+	}
+	_, ok := s.filter[normalizeStreamSymbol(symbol)]
+	return ok
+}
+
+func (s *streamSession) write(msg StreamMessage) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return s.conn.WriteJSON(msg)
+}
+
+func parseStreamSymbols(raw string) map[string]struct{} {
+	out := make(map[string]struct{})
+	for _, part := range strings.Split(raw, ",") {
+		if sym := normalizeStreamSymbol(part); sym != "" {
+			out[sym] = struct{}{}
+		}
+	}
+	return out
+}
+
+// normalizeStreamSymbol 统一 BTC/USDT、BTC/USDT:USDT、btcusdt 等写法。
+func normalizeStreamSymbol(sym string) string {
+	sym = strings.ToUpper(strings.TrimSpace(sym))
+	if idx := strings.Index(sym, ":"); idx >= 0 {
+		sym = sym[:idx]
+	}
+	return strings.NewReplacer("/", "", "-", "", "_", "").Replace(sym)
+}
@@ -0,0 +1,79 @@
+package livehttp
+
+import (
+	"net/http"
+	"strings"
+
+	"brale/internal/agent/gate"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SymbolGateController 管理分析前的交易对闸门：手动黑名单与连续亏损冷却。
+type SymbolGateController interface {
+	BlacklistSymbol(symbol, reason string) (gate.Entry, error)
+	UnblacklistSymbol(symbol string) bool
+	SymbolGateStatus() gate.Status
+}
+
+type blacklistRequest struct {
+	Symbol string `json:"symbol"`
+	Reason string `json:"reason"`
+}
+
+func (r *Router) symbolGate(c *gin.Context) (SymbolGateController, bool) {
+	ctrl, ok := r.FreqtradeHandler.(SymbolGateController)
+	if !ok || ctrl == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "交易对闸门未启用"})
+		return nil, false
+	}
+	return ctrl, true
+}
+
+func (r *Router) handleSymbolGateStatus(c *gin.Context) {
+	ctrl, ok := r.symbolGate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, ctrl.SymbolGateStatus())
+}
+
+func (r *Router) handleBlacklistSymbol(c *gin.Context) {
+	ctrl, ok := r.symbolGate(c)
+	if !ok {
+		return
+	}
+	var req blacklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol 不能为空"})
+		return
+	}
+	entry, err := ctrl.BlacklistSymbol(symbol, req.Reason)
+	if err != nil {
+		logger.Warnf("[api] blacklist symbol failed ip=%s symbol=%s err=%v", c.ClientIP(), symbol, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] symbol blacklisted ip=%s symbol=%s reason=%s", c.ClientIP(), entry.Symbol, entry.Reason)
+	c.JSON(http.StatusOK, gin.H{"status": "blacklisted", "entry": entry})
+}
+
+func (r *Router) handleUnblacklistSymbol(c *gin.Context) {
+	ctrl, ok := r.symbolGate(c)
+	if !ok {
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+	if !ctrl.UnblacklistSymbol(symbol) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "symbol 不在黑名单中"})
+		return
+	}
+	logger.Infof("[api] symbol unblacklisted ip=%s symbol=%s", c.ClientIP(), symbol)
+	c.JSON(http.StatusOK, gin.H{"status": "removed", "symbol": symbol})
+}
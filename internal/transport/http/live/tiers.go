@@ -0,0 +1,129 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"brale/internal/agent/interfaces"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TierCompletionRequest 人工标记 tier 完成；components 为空时按 freqtrade 回报的剩余仓位自动对账。
+// remaining_ratio 可覆盖交易所回报（剩余仓位 / 初始仓位），reason 写入变更日志。
+type TierCompletionRequest struct {
+	PlanID         string   `json:"plan_id"`
+	Components     []string `json:"components"`
+	RemainingRatio *float64 `json:"remaining_ratio"`
+	Reason         string   `json:"reason"`
+	Operator       string   `json:"operator"`
+}
+
+// TierCompletionChange 为单个组件对账后的状态。
+type TierCompletionChange struct {
+	Component      string  `json:"component"`
+	Status         string  `json:"status"`
+	ExecutedRatio  float64 `json:"executed_ratio"`
+	RemainingRatio float64 `json:"remaining_ratio"`
+}
+
+// TierCompletionResult 返回对账依据（交易所回报的仓位）与各组件变更。
+type TierCompletionResult struct {
+	TradeID        int                    `json:"trade_id"`
+	Amount         float64                `json:"amount"`
+	InitialAmount  float64                `json:"initial_amount"`
+	RemainingRatio float64                `json:"remaining_ratio"`
+	Overridden     bool                   `json:"overridden,omitempty"`
+	Changes        []TierCompletionChange `json:"changes"`
+}
+
+// TierCompleter 在 tier 成交发生于 brale 之外时，人工修正本地 tier 状态。
+type TierCompleter interface {
+	CompleteTiers(ctx context.Context, tradeID int, req TierCompletionRequest) (TierCompletionResult, error)
+}
+
+func (r *Router) handleTierCompletion(c *gin.Context) {
+	completer, ok := r.FreqtradeHandler.(TierCompleter)
+	if !ok || completer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "plan scheduler 未启用"})
+		return
+	}
+	tradeID, _ := strconv.Atoi(c.Param("id"))
+	if tradeID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid trade_id"})
+		return
+	}
+	var req TierCompletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	if req.RemainingRatio != nil && (*req.RemainingRatio < 0 || *req.RemainingRatio > 1) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "remaining_ratio 需在 [0,1]"})
+		return
+	}
+	if strings.TrimSpace(req.Operator) == "" {
+		req.Operator = c.ClientIP()
+	}
+	res, err := completer.CompleteTiers(c.Request.Context(), tradeID, req)
+	if err != nil {
+		logger.Warnf("[api] tier completion failed ip=%s trade_id=%d plan=%s err=%v", c.ClientIP(), tradeID, req.PlanID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Infof("[api] tier completion ip=%s trade_id=%d plan=%s components=%v changes=%d", c.ClientIP(), tradeID, req.PlanID, req.Components, len(res.Changes))
+	c.JSON(http.StatusOK, gin.H{"result": res})
+}
+
+// TierEditRequest 人工修改持仓的 tier 价位与比例；levels 按组件名（如 tp_tiers.tier1、sl_tiers.tier1）指定，
+// 字段为 0 表示保持不变。plan_id 为空时自动选择该交易唯一的 tier plan。
+type TierEditRequest struct {
+	PlanID   string                     `json:"plan_id"`
+	Levels   []interfaces.TierEditLevel `json:"levels"`
+	Reason   string                     `json:"reason"`
+	Operator string                     `json:"operator"`
+}
+
+// TierEditor 校验并写入 tier 修改，同步刷新监控中的价位。
+type TierEditor interface {
+	EditTiers(ctx context.Context, tradeID int, req TierEditRequest) ([]interfaces.TierEditChange, error)
+}
+
+func (r *Router) handleTierEdit(c *gin.Context) {
+	editor, ok := r.FreqtradeHandler.(TierEditor)
+	if !ok || editor == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "plan scheduler 未启用"})
+		return
+	}
+	tradeID, _ := strconv.Atoi(c.Param("id"))
+	if tradeID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid trade_id"})
+		return
+	}
+	var req TierEditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "detail": err.Error()})
+		return
+	}
+	if len(req.Levels) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "levels 不能为空"})
+		return
+	}
+	if strings.TrimSpace(req.Operator) == "" {
+		req.Operator = c.ClientIP()
+	}
+	changes, err := editor.EditTiers(c.Request.Context(), tradeID, req)
+	if err != nil {
+		logger.Warnf("[api] tier edit failed ip=%s trade_id=%d plan=%s err=%v", c.ClientIP(), tradeID, req.PlanID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if changes == nil {
+		changes = []interfaces.TierEditChange{}
+	}
+	logger.Infof("[api] tier edit ip=%s trade_id=%d plan=%s changes=%d", c.ClientIP(), tradeID, req.PlanID, len(changes))
+	c.JSON(http.StatusOK, gin.H{"trade_id": tradeID, "changes": changes})
+}
@@ -1,5 +1,12 @@
 package livehttp
 
+import (
+	"brale/internal/analysis/screen"
+	"brale/internal/decision"
+	"brale/internal/gateway/exchange"
+	"brale/internal/market"
+)
+
 type SymbolDetail struct {
 	Profile      string   `json:"profile"`
 	Middlewares  []string `json:"middlewares,omitempty"`
@@ -9,3 +16,107 @@ type SymbolDetail struct {
 	SystemPrompt string   `json:"system_prompt,omitempty"`
 	UserPrompt   string   `json:"user_prompt,omitempty"`
 }
+
+type DashboardPoint struct {
+	Time  int64   `json:"time"`
+	Value float64 `json:"value"`
+}
+
+type DashboardOverlay struct {
+	Key    string           `json:"key"`
+	Label  string           `json:"label"`
+	Pane   string           `json:"pane"`
+	Latest float64          `json:"latest"`
+	State  string           `json:"state,omitempty"`
+	Points []DashboardPoint `json:"points"`
+}
+
+// DashboardDivergence 为一条背离标注：From 为前一个枢轴、To 为当前枢轴，看跌标在高点、看涨标在低点。
+type DashboardDivergence struct {
+	Indicator string  `json:"indicator"`
+	Direction string  `json:"direction"`
+	FromTime  int64   `json:"from_time"`
+	FromPrice float64 `json:"from_price"`
+	ToTime    int64   `json:"to_time"`
+	ToPrice   float64 `json:"to_price"`
+}
+
+type DashboardChart struct {
+	Symbol      string                `json:"symbol"`
+	Interval    string                `json:"interval"`
+	Candles     []market.Candle       `json:"candles"`
+	Overlays    []DashboardOverlay    `json:"overlays,omitempty"`
+	Divergences []DashboardDivergence `json:"divergences,omitempty"`
+	Warnings    []string              `json:"warnings,omitempty"`
+	UpdatedAt   int64                 `json:"updated_at"`
+}
+
+type DashboardTierLine struct {
+	PlanID    string  `json:"plan_id"`
+	Component string  `json:"component"`
+	Mode      string  `json:"mode,omitempty"`
+	Price     float64 `json:"price"`
+	Ratio     float64 `json:"ratio,omitempty"`
+	Status    string  `json:"status,omitempty"`
+}
+
+type DashboardPosition struct {
+	exchange.APIPosition
+	TierLines []DashboardTierLine `json:"tier_lines,omitempty"`
+	// PendingStage 非空表示正在等待成交回报：opening / closing。
+	PendingStage string `json:"pending_stage,omitempty"`
+}
+
+type DashboardProfileStatus struct {
+	Name          string   `json:"name"`
+	Default       bool     `json:"default"`
+	Targets       []string `json:"targets,omitempty"`
+	Intervals     []string `json:"intervals,omitempty"`
+	Middlewares   []string `json:"middlewares,omitempty"`
+	ExitCombos    []string `json:"exit_combos,omitempty"`
+	AgentEnabled  bool     `json:"agent_enabled"`
+	AnalysisSlice int      `json:"analysis_slice"`
+	IndicatorBars int      `json:"indicator_bars"`
+}
+
+type BatchAnalysisRequest struct {
+	Symbols  []string `json:"symbols"`
+	Profile  string   `json:"profile,omitempty"`
+	Interval string   `json:"interval,omitempty"`
+}
+
+type BatchAnalysisResult struct {
+	Symbol   string `json:"symbol"`
+	Profile  string `json:"profile,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	screen.Summary
+	Composite *screen.Composite `json:"composite,omitempty"`
+	Warnings  []string          `json:"warnings,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type DecisionDryRunRequest struct {
+	Symbol  string `json:"symbol"`
+	Profile string `json:"profile,omitempty"`
+}
+
+type DecisionDryRunOutput struct {
+	Provider  string `json:"provider"`
+	Raw       string `json:"raw,omitempty"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+type DecisionDryRunResult struct {
+	Symbol       string                 `json:"symbol"`
+	Profile      string                 `json:"profile"`
+	Features     []string               `json:"features,omitempty"`
+	Warnings     []string               `json:"warnings,omitempty"`
+	SystemPrompt string                 `json:"system_prompt,omitempty"`
+	UserPrompt   string                 `json:"user_prompt,omitempty"`
+	Outputs      []DecisionDryRunOutput `json:"outputs,omitempty"`
+	Decisions    []decision.Decision    `json:"decisions"`
+	RawOutput    string                 `json:"raw_output,omitempty"`
+	MetaSummary  string                 `json:"meta_summary,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
@@ -0,0 +1,40 @@
+package livehttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"brale/internal/gateway/database"
+	"brale/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookDeliveryLister 查询对外 webhook 的投递记录。
+type WebhookDeliveryLister interface {
+	ListWebhookDeliveries(ctx context.Context, q database.WebhookDeliveryQuery) ([]database.WebhookDeliveryRecord, error)
+}
+
+func (r *Router) handleWebhookDeliveries(c *gin.Context) {
+	lister, ok := r.FreqtradeHandler.(WebhookDeliveryLister)
+	if !ok || lister == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "webhook 未启用"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	failed, _ := strconv.ParseBool(c.DefaultQuery("failed", "false"))
+	records, err := lister.ListWebhookDeliveries(c.Request.Context(), database.WebhookDeliveryQuery{
+		EventType: strings.TrimSpace(c.Query("event")),
+		Endpoint:  strings.TrimSpace(c.Query("endpoint")),
+		Failed:    failed,
+		Limit:     limit,
+	})
+	if err != nil {
+		logger.Warnf("[api] webhook deliveries failed ip=%s err=%v", c.ClientIP(), err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": records})
+}
@@ -0,0 +1,339 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	brcfg "brale/internal/config"
+	"brale/internal/gateway/exchange"
+	"brale/internal/gateway/notifier"
+	"brale/internal/logger"
+	livehttp "brale/internal/transport/http/live"
+)
+
+const (
+	pollTimeout    = 30 * time.Second
+	minPollBackoff = 2 * time.Second
+	maxPollBackoff = time.Minute
+	standbyRecheck = 15 * time.Second
+	commandTimeout = 20 * time.Second
+)
+
+// Backend 为命令所需的交易/配置操作，由 LiveService 实现（内部委托 freqtrade Manager 与 profile Manager）。
+type Backend interface {
+	RunMode() livehttp.RunModeStatus
+	ListFreqtradePositions(ctx context.Context, opts exchange.PositionListOptions) (exchange.PositionListResult, error)
+	GetFreqtradePosition(ctx context.Context, tradeID int) (*exchange.APIPosition, error)
+	CloseFreqtradePosition(ctx context.Context, tradeID int, symbol, side string, closeRatio float64) error
+	RefreshAccountBalance(ctx context.Context) (exchange.Balance, error)
+	PauseSymbol(symbol string)
+	ResumeSymbols(symbols ...string) []string
+	PausedSymbols() map[string]time.Time
+	SwitchProfile(symbol, name string) error
+	ProfileTargets() map[string][]string
+}
+
+// Bot 以长轮询接收 Telegram 命令，只响应白名单内的 chat；standby 实例不拉取更新，避免与 active 实例争抢。
+// 配置了 allowed_user_ids 时发送者也必须在名单内（群组可用）；未配置时只接受私聊，群组里任何成员的命令都会被忽略。
+type Bot struct {
+	client  *notifier.Telegram
+	backend Backend
+	allowed map[int64]struct{}
+	users   map[int64]struct{}
+	offset  int64
+}
+
+func NewBot(client *notifier.Telegram, backend Backend, allowedChatIDs, allowedUserIDs []string) (*Bot, error) {
+	if client == nil || backend == nil {
+		return nil, fmt.Errorf("telegram bot 缺少 client 或 backend")
+	}
+	allowed, err := parseIDs(allowedChatIDs)
+	if err != nil {
+		return nil, fmt.Errorf("telegram allowed_chat_ids 无效: %w", err)
+	}
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("telegram 命令需要至少一个允许的 chat id")
+	}
+	users, err := parseIDs(allowedUserIDs)
+	if err != nil {
+		return nil, fmt.Errorf("telegram allowed_user_ids 无效: %w", err)
+	}
+	return &Bot{client: client, backend: backend, allowed: allowed, users: users}, nil
+}
+
+func parseIDs(raws []string) (map[int64]struct{}, error) {
+	out := make(map[int64]struct{}, len(raws))
+	for _, raw := range raws {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s", raw)
+		}
+		out[id] = struct{}{}
+	}
+	return out, nil
+}
+
+// authorize 返回拒绝原因，空串表示放行：chat 须在白名单内；有用户名单时按发送者校验，否则只接受私聊。
+func (b *Bot) authorize(msg *notifier.TelegramMessage) string {
+	if _, ok := b.allowed[msg.Chat.ID]; !ok {
+		return "未授权 chat"
+	}
+	if len(b.users) == 0 {
+		if msg.Chat.Type != "private" {
+			return "未配置 allowed_user_ids 时只接受私聊命令"
+		}
+		return ""
+	}
+	if msg.From == nil {
+		return "缺少发送者"
+	}
+	if _, ok := b.users[msg.From.ID]; !ok {
+		return fmt.Sprintf("未授权用户 %d", msg.From.ID)
+	}
+	return ""
+}
+
+// Run 持续拉取并处理命令直到 ctx 结束；网络错误按指数退避重试。
+func (b *Bot) Run(ctx context.Context) error {
+	logger.Infof("Telegram 命令监听已启动，允许 chat 数=%d 用户数=%d", len(b.allowed), len(b.users))
+	backoff := minPollBackoff
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if b.backend.RunMode().Mode == brcfg.AppModeStandby {
+			if !sleepCtx(ctx, standbyRecheck) {
+				return nil
+			}
+			continue
+		}
+		updates, err := b.client.GetUpdates(ctx, b.offset, pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			logger.Warnf("Telegram getUpdates 失败，%s 后重试: %v", backoff, err)
+			if !sleepCtx(ctx, backoff) {
+				return nil
+			}
+			backoff = min(backoff*2, maxPollBackoff)
+			continue
+		}
+		backoff = minPollBackoff
+		for _, upd := range updates {
+			if upd.UpdateID >= b.offset {
+				b.offset = upd.UpdateID + 1
+			}
+			if upd.Message == nil {
+				continue
+			}
+			b.handleMessage(ctx, upd.Message)
+		}
+	}
+}
+
+func (b *Bot) handleMessage(ctx context.Context, msg *notifier.TelegramMessage) {
+	text := strings.TrimSpace(msg.Text)
+	if !strings.HasPrefix(text, "/") {
+		return
+	}
+	if reason := b.authorize(msg); reason != "" {
+		logger.Warnf("Telegram 命令 chat=%d 已忽略（%s）: %s", msg.Chat.ID, reason, text)
+		return
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	reply := b.dispatch(cmdCtx, text)
+	logger.Infof("Telegram 命令 chat=%d: %s", msg.Chat.ID, text)
+	if reply == "" {
+		return
+	}
+	if err := b.client.SendTextTo(strconv.FormatInt(msg.Chat.ID, 10), reply); err != nil {
+		logger.Warnf("Telegram 命令回复失败: %v", err)
+	}
+}
+
+func (b *Bot) dispatch(ctx context.Context, text string) string {
+	fields := strings.Fields(text)
+	// 群组中命令可能带 @botname 后缀。
+	cmd := strings.ToLower(strings.SplitN(fields[0], "@", 2)[0])
+	args := fields[1:]
+	switch cmd {
+	case "/positions":
+		return b.cmdPositions(ctx)
+	case "/close":
+		return b.cmdClose(ctx, args)
+	case "/pause":
+		return b.cmdPause(args)
+	case "/resume":
+		return b.cmdResume(args)
+	case "/balance":
+		return b.cmdBalance(ctx)
+	case "/profile":
+		return b.cmdProfile(args)
+	case "/help", "/start":
+		return helpText
+	default:
+		return "未知命令，发送 /help 查看可用命令"
+	}
+}
+
+const helpText = `可用命令：
+/positions — 当前持仓
+/close <tradeID> [ratio] — 平仓，ratio 为 0~1，缺省全部
+/pause <symbol> — 暂停该交易对的新开仓
+/resume [symbol] — 恢复开仓，缺省恢复全部
+/balance — 账户余额
+/profile — 各 profile 负责的交易对
+/profile switch <symbol> <profile|auto> — 运行时切换交易对的 profile，auto 恢复按配置匹配`
+
+func (b *Bot) cmdPositions(ctx context.Context) string {
+	res, err := b.backend.ListFreqtradePositions(ctx, exchange.PositionListOptions{Page: 1, PageSize: 50, Status: "active"})
+	if err != nil {
+		return fmt.Sprintf("查询持仓失败: %v", err)
+	}
+	if len(res.Positions) == 0 {
+		return "当前无持仓"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "持仓 %d 个：\n", len(res.Positions))
+	for _, p := range res.Positions {
+		fmt.Fprintf(&sb, "#%d %s %s 入场 %.6g 现价 %.6g 浮盈 %.2f USD (%.2f%%) 剩余 %.0f%%\n",
+			p.TradeID, p.Symbol, strings.ToUpper(p.Side), p.EntryPrice, p.CurrentPrice,
+			p.UnrealizedPnLUSD, p.UnrealizedPnLRatio*100, p.RemainingRatio*100)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func (b *Bot) cmdClose(ctx context.Context, args []string) string {
+	if reply, ok := b.rejectStandby(); !ok {
+		return reply
+	}
+	if len(args) == 0 {
+		return "用法: /close <tradeID> [ratio]"
+	}
+	tradeID, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil || tradeID <= 0 {
+		return "tradeID 无效"
+	}
+	ratio := 1.0
+	if len(args) > 1 {
+		ratio, err = strconv.ParseFloat(args[1], 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			return "ratio 需在 (0, 1] 之间"
+		}
+	}
+	pos, err := b.backend.GetFreqtradePosition(ctx, tradeID)
+	if err != nil || pos == nil {
+		return fmt.Sprintf("未找到持仓 #%d", tradeID)
+	}
+	if err := b.backend.CloseFreqtradePosition(ctx, tradeID, pos.Symbol, pos.Side, ratio); err != nil {
+		return fmt.Sprintf("平仓 #%d 失败: %v", tradeID, err)
+	}
+	return fmt.Sprintf("已提交平仓 #%d %s %s 比例 %.0f%%", tradeID, pos.Symbol, strings.ToUpper(pos.Side), ratio*100)
+}
+
+func (b *Bot) cmdPause(args []string) string {
+	if reply, ok := b.rejectStandby(); !ok {
+		return reply
+	}
+	if len(args) == 0 {
+		return "用法: /pause <symbol>"
+	}
+	sym := strings.ToUpper(args[0])
+	b.backend.PauseSymbol(sym)
+	return fmt.Sprintf("已暂停 %s 的新开仓（平仓与退出计划照常）", sym)
+}
+
+func (b *Bot) cmdResume(args []string) string {
+	if reply, ok := b.rejectStandby(); !ok {
+		return reply
+	}
+	resumed := b.backend.ResumeSymbols(args...)
+	if len(resumed) == 0 {
+		if paused := b.backend.PausedSymbols(); len(paused) > 0 {
+			return fmt.Sprintf("未恢复任何交易对，当前暂停: %s", strings.Join(sortedKeys(paused), ", "))
+		}
+		return "当前没有暂停的交易对"
+	}
+	return fmt.Sprintf("已恢复开仓: %s", strings.Join(resumed, ", "))
+}
+
+func (b *Bot) cmdBalance(ctx context.Context) string {
+	bal, err := b.backend.RefreshAccountBalance(ctx)
+	if err != nil {
+		return fmt.Sprintf("查询余额失败: %v", err)
+	}
+	currency := bal.StakeCurrency
+	if currency == "" {
+		currency = "USDT"
+	}
+	return fmt.Sprintf("余额 %.2f %s\n可用 %.2f / 占用 %.2f", bal.Total, currency, bal.Available, bal.Used)
+}
+
+func (b *Bot) cmdProfile(args []string) string {
+	if len(args) == 0 {
+		targets := b.backend.ProfileTargets()
+		if len(targets) == 0 {
+			return "未加载任何 profile"
+		}
+		var sb strings.Builder
+		for _, name := range sortedKeys(targets) {
+			fmt.Fprintf(&sb, "%s: %s\n", name, strings.Join(targets[name], ", "))
+		}
+		return strings.TrimSpace(sb.String())
+	}
+	if !strings.EqualFold(args[0], "switch") || len(args) != 3 {
+		return "用法: /profile switch <symbol> <profile|auto>"
+	}
+	if reply, ok := b.rejectStandby(); !ok {
+		return reply
+	}
+	sym, name := strings.ToUpper(args[1]), args[2]
+	if strings.EqualFold(name, "auto") {
+		name = ""
+	}
+	if err := b.backend.SwitchProfile(sym, name); err != nil {
+		return fmt.Sprintf("切换失败: %v", err)
+	}
+	if name == "" {
+		return fmt.Sprintf("%s 已恢复按配置匹配 profile", sym)
+	}
+	return fmt.Sprintf("%s 已切换到 profile %s（重启后失效）", sym, name)
+}
+
+// rejectStandby 与 HTTP 的 standbyGuard 一致：standby 实例拒绝写操作。
+func (b *Bot) rejectStandby() (string, bool) {
+	if b.backend.RunMode().Mode == brcfg.AppModeStandby {
+		return "当前实例为 standby，拒绝写操作", false
+	}
+	return "", true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}